@@ -11,13 +11,25 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/luponetn/paycore/internal/accounts"
 	"github.com/luponetn/paycore/internal/auth"
 	"github.com/luponetn/paycore/internal/config"
 	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/deposit"
+	"github.com/luponetn/paycore/internal/fees"
+	"github.com/luponetn/paycore/internal/funding"
+	"github.com/luponetn/paycore/internal/fx"
+	"github.com/luponetn/paycore/internal/interest"
+	"github.com/luponetn/paycore/internal/payout"
+	"github.com/luponetn/paycore/internal/schedule"
 	"github.com/luponetn/paycore/internal/store"
+	"github.com/luponetn/paycore/internal/stream"
 	"github.com/luponetn/paycore/internal/tasks"
 	"github.com/luponetn/paycore/internal/transfer"
 	"github.com/luponetn/paycore/internal/wallet"
+	"github.com/luponetn/paycore/internal/webhook"
+	"github.com/luponetn/paycore/pkg/utils"
+	"github.com/redis/go-redis/v9"
 )
 
 type Application struct {
@@ -62,20 +74,84 @@ func main() {
 	taskClient := tasks.NewTaskClient(cfg.RedisAddr)
 	defer taskClient.Close()
 
+	//access token keys: shared secret or asymmetric keys from disk
+	accessKeys := utils.NewHMACKeySet(cfg.JWTAccessSecret)
+	if cfg.JWTAlgorithm != utils.AlgHS256 {
+		accessKeys, err = utils.LoadKeySet(cfg.JWTAlgorithm, cfg.JWTKeysDir, cfg.JWTActiveKID)
+		if err != nil {
+			slog.Error("failed to load jwt keys", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	//redis client for login throttling; the same instance asynq uses
+	redisClient := redis.NewClient(&redis.Options{Addr: cfg.RedisAddr})
+	defer redisClient.Close()
+
 	//register service
-	authSvc := auth.NewService(postgresStore, taskClient, cfg)
-	transferSvc := transfer.NewService(postgresStore)
-	walletSvc := wallet.NewService(postgresStore)
+	authSvc := auth.NewService(postgresStore, taskClient, cfg, accessKeys, auth.NewRedisLoginGuard(redisClient, cfg))
+	transferSvc := transfer.NewService(postgresStore, taskClient, cfg)
+	walletSvc := wallet.NewService(postgresStore, transferSvc, cfg)
+	scheduleSvc := schedule.NewService(postgresStore, transferSvc)
+	depositSvc := deposit.NewService(postgresStore, cfg)
+	interestSvc := interest.NewService(postgresStore, cfg)
+	accountsSvc := accounts.NewService(postgresStore)
+	feesSvc := fees.NewService(postgresStore)
+	fxSvc := fx.NewService(postgresStore, cfg)
+	webhookSvc := webhook.NewService(postgresStore, taskClient, cfg)
+
+	//funding provider; the simulator is the only one so far
+	fundingSim := funding.NewSimulator(cfg.FundingCallbackSecret, cfg.FundingSimulatorCallbackURL, cfg.FundingCallbackTolerance)
+	fundingSvc := funding.NewService(postgresStore, fundingSim)
+
+	//payout provider; the worker submits and settles payouts, the API only resolves accounts
+	payoutSvc := payout.NewService(postgresStore, payout.NewSimulator(cfg.PayoutSimulatorLatency, cfg.PayoutSimulatorFailureRate), transferSvc, cfg)
+
+	//wallet stream hub fed by outbox notifications; stopping it ends open streams before shutdown
+	streamHub := stream.NewHub(postgresStore)
+	streamCtx, stopStreams := context.WithCancel(context.Background())
+	defer stopStreams()
+	go streamHub.Listen(streamCtx, dbConn)
+
+	//seed exchange rates from disk when a rates file is configured
+	if cfg.FXRatesFile != "" {
+		loaded, err := fxSvc.LoadRatesFile(context.Background(), cfg.FXRatesFile)
+		if err != nil {
+			slog.Error("failed to load fx rates file", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("loaded fx rates", "count", loaded, "file", cfg.FXRatesFile)
+	}
 
 	//register handler
 	authHandler := auth.NewHandler(authSvc)
 	transferHandler := transfer.NewHandler(transferSvc)
 	walletHandler := wallet.NewHandler(walletSvc)
-
-	//register routes
-	auth.RegisterRoutes(router, authHandler)
-	transfer.RegisterRoutes(router, transferHandler, cfg.JWTAccessSecret)
-	wallet.RegisterRoutes(router, walletHandler, cfg.JWTAccessSecret)
+	scheduleHandler := schedule.NewHandler(scheduleSvc)
+	depositHandler := deposit.NewHandler(depositSvc)
+	interestHandler := interest.NewHandler(interestSvc)
+	accountsHandler := accounts.NewHandler(accountsSvc)
+	feesHandler := fees.NewHandler(feesSvc)
+	fxHandler := fx.NewHandler(fxSvc)
+	webhookHandler := webhook.NewHandler(webhookSvc)
+	streamHandler := stream.NewHandler(streamHub)
+	fundingHandler := funding.NewHandler(fundingSvc, fundingSim)
+	payoutHandler := payout.NewHandler(payoutSvc)
+
+	//register routes; authSvc tells the auth middleware which sessions are still signed in
+	auth.RegisterRoutes(router, authHandler, accessKeys, authSvc)
+	transfer.RegisterRoutes(router, transferHandler, accessKeys, authSvc)
+	wallet.RegisterRoutes(router, walletHandler, accessKeys, authSvc)
+	schedule.RegisterRoutes(router, scheduleHandler, accessKeys, authSvc)
+	deposit.RegisterRoutes(router, depositHandler, accessKeys, authSvc)
+	interest.RegisterRoutes(router, interestHandler, accessKeys, authSvc, cfg.AdminAPIKey)
+	accounts.RegisterRoutes(router, accountsHandler, cfg.AdminAPIKey)
+	fees.RegisterRoutes(router, feesHandler, cfg.AdminAPIKey)
+	fx.RegisterRoutes(router, fxHandler, accessKeys, authSvc, cfg.AdminAPIKey)
+	webhook.RegisterRoutes(router, webhookHandler, accessKeys, authSvc)
+	stream.RegisterRoutes(router, streamHandler, accessKeys, authSvc)
+	funding.RegisterRoutes(router, fundingHandler, accessKeys, authSvc, cfg.AdminAPIKey)
+	payout.RegisterRoutes(router, payoutHandler, accessKeys, authSvc)
 
 	srv := &http.Server{
 		Addr:    ":" + cfg.Port,
@@ -94,6 +170,7 @@ func main() {
 	<-quit
 
 	slog.Info("Shutting down server...")
+	stopStreams()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/luponetn/paycore/internal/config"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/deposit"
+	"github.com/luponetn/paycore/internal/interest"
+	"github.com/luponetn/paycore/internal/mailer"
+	"github.com/luponetn/paycore/internal/outbox"
+	"github.com/luponetn/paycore/internal/payout"
+	"github.com/luponetn/paycore/internal/schedule"
+	"github.com/luponetn/paycore/internal/store"
+	"github.com/luponetn/paycore/internal/tasks"
+	"github.com/luponetn/paycore/internal/transfer"
+	"github.com/luponetn/paycore/internal/webhook"
+)
+
+func main() {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	slog.SetDefault(logger)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		slog.Error("failed to load config", "error", err)
+		os.Exit(1)
+	}
+
+	//setup database connection
+	dbConn, err := db.ConnDb(cfg)
+	if err != nil {
+		slog.Error("failed to connect to database", "error", err)
+		os.Exit(1)
+	}
+	defer dbConn.Close()
+
+	slog.Info("database connection established successfully")
+
+	postgresStore := store.NewPostgresStore(dbConn, db.New(dbConn))
+
+	//handlers may enqueue follow-up tasks
+	taskClient := tasks.NewTaskClient(cfg.RedisAddr)
+	defer taskClient.Close()
+
+	//register service
+	transferSvc := transfer.NewService(postgresStore, taskClient, cfg)
+	scheduleSvc := schedule.NewService(postgresStore, transferSvc)
+	depositSvc := deposit.NewService(postgresStore, cfg)
+	interestSvc := interest.NewService(postgresStore, cfg)
+	webhookSvc := webhook.NewService(postgresStore, taskClient, cfg)
+	relay := outbox.NewRelay(postgresStore, outbox.NewAsynqSink(taskClient, tasks.QueueDefault))
+
+	//payout provider; the simulator is the only one so far
+	payoutSvc := payout.NewService(postgresStore, payout.NewSimulator(cfg.PayoutSimulatorLatency, cfg.PayoutSimulatorFailureRate), transferSvc, cfg)
+
+	mail, err := mailer.New(cfg)
+	if err != nil {
+		slog.Error("failed to set up mailer", "error", err)
+		os.Exit(1)
+	}
+
+	redisOpt := asynq.RedisClientOpt{Addr: cfg.RedisAddr}
+
+	srv := asynq.NewServer(redisOpt, asynq.Config{
+		Concurrency:     cfg.WorkerConcurrency,
+		Queues:          cfg.WorkerQueues,
+		StrictPriority:  cfg.WorkerStrictPriority,
+		ShutdownTimeout: cfg.WorkerShutdownTimeout,
+	})
+
+	scheduler := asynq.NewScheduler(redisOpt, &asynq.SchedulerOpts{Location: time.UTC})
+	if err := RegisterPeriodicJobs(scheduler); err != nil {
+		slog.Error("failed to register periodic jobs", "error", err)
+		os.Exit(1)
+	}
+
+	mux := NewMux(Services{Transfer: transferSvc, Schedule: scheduleSvc, Deposit: depositSvc, Interest: interestSvc, Outbox: relay, Webhook: webhookSvc, Payout: payoutSvc}, mail, taskClient)
+
+	if err := srv.Start(mux); err != nil {
+		slog.Error("failed to start worker", "error", err)
+		os.Exit(1)
+	}
+
+	if err := scheduler.Start(); err != nil {
+		slog.Error("failed to start scheduler", "error", err)
+		srv.Shutdown()
+		os.Exit(1)
+	}
+
+	slog.Info("Worker started", "concurrency", cfg.WorkerConcurrency, "queues", cfg.WorkerQueues)
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	slog.Info("Shutting down worker...")
+
+	//stop enqueuing periodic work first, then let in-flight tasks finish within ShutdownTimeout
+	scheduler.Shutdown()
+	srv.Shutdown()
+
+	slog.Info("Worker exiting")
+}
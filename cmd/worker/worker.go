@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/luponetn/paycore/internal/deposit"
+	"github.com/luponetn/paycore/internal/interest"
+	"github.com/luponetn/paycore/internal/mailer"
+	"github.com/luponetn/paycore/internal/outbox"
+	"github.com/luponetn/paycore/internal/payout"
+	"github.com/luponetn/paycore/internal/schedule"
+	"github.com/luponetn/paycore/internal/tasks"
+	"github.com/luponetn/paycore/internal/transfer"
+	"github.com/luponetn/paycore/internal/webhook"
+)
+
+type Services struct {
+	Transfer transfer.Service
+	Schedule schedule.Service
+	Deposit  deposit.Service
+	Interest interest.Service
+	Outbox   *outbox.Relay
+	Webhook  webhook.Service
+	Payout   payout.Service
+}
+
+// periodicJob is a task the scheduler enqueues on a cron spec.
+type periodicJob struct {
+	cronspec string
+	newTask  func() (*asynq.Task, error)
+	opts     []asynq.Option
+}
+
+// NewMux registers a handler for every task type the API or the scheduler can enqueue.
+func NewMux(svcs Services, m mailer.Mailer, client *asynq.Client) *asynq.ServeMux {
+	mux := asynq.NewServeMux()
+	mux.Use(loggingMiddleware)
+
+	mux.Handle(tasks.TypeSendOTPEmail, tasks.HandleSendOTPEmailTask(m))
+	mux.Handle(tasks.TypeSendPasswordResetEmail, tasks.HandleSendPasswordResetEmailTask(m))
+	mux.Handle(tasks.TypeSendTransferReceiptEmail, tasks.HandleSendTransferReceiptEmailTask(m))
+	mux.Handle(tasks.TypeSendSecurityAlertEmail, tasks.HandleSendSecurityAlertEmailTask(m))
+	mux.Handle(tasks.TypeReleaseExpiredHolds, tasks.HandleReleaseExpiredHoldsTask(svcs.Transfer))
+	mux.Handle(tasks.TypeDispatchScheduledTransfers, tasks.HandleDispatchScheduledTransfersTask(svcs.Schedule, client))
+	mux.Handle(tasks.TypeExecuteScheduledTransfer, tasks.HandleExecuteScheduledTransferTask(svcs.Schedule))
+	mux.Handle(tasks.TypeAccrueDepositInterest, tasks.HandleAccrueDepositInterestTask(svcs.Deposit))
+	mux.Handle(tasks.TypeAccrueSavingsInterest, tasks.HandleAccrueSavingsInterestTask(svcs.Interest))
+	mux.Handle(tasks.TypeRelayOutboxEvents, tasks.HandleRelayOutboxEventsTask(svcs.Outbox))
+	mux.Handle(tasks.TypeDomainEventPrefix, tasks.HandleDomainEventTask(svcs.Webhook))
+	mux.Handle(tasks.TypeDeliverWebhook, tasks.HandleDeliverWebhookTask(svcs.Webhook))
+	mux.Handle(tasks.TypeDispatchPayouts, tasks.HandleDispatchPayoutsTask(svcs.Payout, client))
+	mux.Handle(tasks.TypeProcessPayout, tasks.HandleProcessPayoutTask(svcs.Payout))
+
+	return mux
+}
+
+func periodicJobs() []periodicJob {
+	return []periodicJob{
+		{
+			cronspec: "@every 1m",
+			newTask: func() (*asynq.Task, error) {
+				return tasks.NewReleaseExpiredHoldsTask(tasks.ReleaseExpiredHoldsPayload{BatchSize: 100})
+			},
+			opts: []asynq.Option{asynq.Queue(tasks.QueueLow), asynq.Unique(time.Minute)},
+		},
+		{
+			cronspec: "@every 1m",
+			newTask: func() (*asynq.Task, error) {
+				return tasks.NewDispatchScheduledTransfersTask(tasks.DispatchScheduledTransfersPayload{BatchSize: 500})
+			},
+			opts: []asynq.Option{asynq.Queue(tasks.QueueDefault), asynq.Unique(time.Minute)},
+		},
+		{
+			// accrual is per calendar day; running hourly catches up quickly after an outage
+			cronspec: "@every 1h",
+			newTask: func() (*asynq.Task, error) {
+				return tasks.NewAccrueDepositInterestTask(tasks.AccrueDepositInterestPayload{BatchSize: 500})
+			},
+			opts: []asynq.Option{asynq.Queue(tasks.QueueDefault), asynq.Unique(time.Hour)},
+		},
+		{
+			// each run records one batch of yesterday's accruals; a short interval drains a large
+			// book soon after midnight, and idle runs are a single query
+			cronspec: "@every 5m",
+			newTask: func() (*asynq.Task, error) {
+				return tasks.NewAccrueSavingsInterestTask(tasks.AccrueSavingsInterestPayload{BatchSize: 500})
+			},
+			opts: []asynq.Option{asynq.Queue(tasks.QueueDefault), asynq.Unique(5 * time.Minute)},
+		},
+		{
+			// events should reach consumers within seconds of their transaction committing
+			cronspec: "@every 10s",
+			newTask: func() (*asynq.Task, error) {
+				return tasks.NewRelayOutboxEventsTask(tasks.RelayOutboxEventsPayload{BatchSize: 100})
+			},
+			opts: []asynq.Option{asynq.Queue(tasks.QueueDefault), asynq.Unique(10 * time.Second)},
+		},
+		{
+			// picks up new payouts and payouts due a status check; each run is one indexed query
+			cronspec: "@every 10s",
+			newTask: func() (*asynq.Task, error) {
+				return tasks.NewDispatchPayoutsTask(tasks.DispatchPayoutsPayload{BatchSize: 100})
+			},
+			opts: []asynq.Option{asynq.Queue(tasks.QueueCritical), asynq.Unique(10 * time.Second)},
+		},
+	}
+}
+
+// RegisterPeriodicJobs adds every periodic job to the scheduler.
+func RegisterPeriodicJobs(scheduler *asynq.Scheduler) error {
+	for _, job := range periodicJobs() {
+		task, err := job.newTask()
+		if err != nil {
+			return err
+		}
+
+		entryID, err := scheduler.Register(job.cronspec, task, job.opts...)
+		if err != nil {
+			return err
+		}
+		slog.Info("registered periodic job", "type", task.Type(), "cronspec", job.cronspec, "entry_id", entryID)
+	}
+	return nil
+}
+
+func loggingMiddleware(next asynq.Handler) asynq.Handler {
+	return asynq.HandlerFunc(func(ctx context.Context, t *asynq.Task) error {
+		start := time.Now()
+		err := next.ProcessTask(ctx, t)
+		if err != nil {
+			slog.Error("task failed", "type", t.Type(), "duration", time.Since(start), "error", err)
+			return err
+		}
+		slog.Info("task processed", "type", t.Type(), "duration", time.Since(start))
+		return nil
+	})
+}
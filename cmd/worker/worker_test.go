@@ -0,0 +1,90 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hibiken/asynq"
+	"github.com/luponetn/paycore/internal/tasks"
+	"github.com/stretchr/testify/require"
+)
+
+// taskTypes returns every Type* constant declared in internal/tasks, read from the source so a
+// newly added type is covered without touching this test.
+func taskTypes(t *testing.T) map[string]string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join("..", "..", "internal", "tasks", "*.go"))
+	require.NoError(t, err)
+
+	types := make(map[string]string)
+	fset := token.NewFileSet()
+	for _, path := range files {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		require.NoError(t, err)
+
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				value := spec.(*ast.ValueSpec)
+				for i, name := range value.Names {
+					if !strings.HasPrefix(name.Name, "Type") || i >= len(value.Values) {
+						continue
+					}
+					lit, ok := value.Values[i].(*ast.BasicLit)
+					require.True(t, ok, "%s is not a string literal", name.Name)
+					types[name.Name], err = strconv.Unquote(lit.Value)
+					require.NoError(t, err)
+				}
+			}
+		}
+	}
+	require.NotEmpty(t, types)
+	return types
+}
+
+// requireRoute checks that the mux sends taskType to the handler registered for pattern.
+func requireRoute(t *testing.T, mux *asynq.ServeMux, taskType, pattern string) {
+	t.Helper()
+	_, matched := mux.Handler(asynq.NewTask(taskType, nil))
+	require.Equal(t, pattern, matched, "task type %q", taskType)
+}
+
+func TestNewMux_HandlesEveryTaskType(t *testing.T) {
+	mux := NewMux(Services{}, nil, nil)
+
+	requireRoute(t, mux, "task:no_such_task", "")
+
+	for _, taskType := range taskTypes(t) {
+		if taskType == tasks.TypeDomainEventPrefix {
+			// relayed events carry the event name after the prefix
+			requireRoute(t, mux, taskType+"transfer.completed", taskType)
+			continue
+		}
+		requireRoute(t, mux, taskType, taskType)
+	}
+}
+
+func TestPeriodicJobs_HaveHandlers(t *testing.T) {
+	mux := NewMux(Services{}, nil, nil)
+
+	for _, job := range periodicJobs() {
+		task, err := job.newTask()
+		require.NoError(t, err)
+		requireRoute(t, mux, task.Type(), task.Type())
+	}
+
+	// registering only parses the cron specs; nothing is sent to Redis until Start
+	scheduler := asynq.NewScheduler(asynq.RedisClientOpt{Addr: "localhost:0"}, nil)
+	require.NoError(t, RegisterPeriodicJobs(scheduler))
+}
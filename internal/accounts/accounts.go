@@ -0,0 +1,47 @@
+package accounts
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/pkg/utils"
+)
+
+// Chart of accounts codes. Each system code has one system wallet per currency; user wallets
+// all roll up to CustomerWallets.
+const (
+	CustomerWallets = "customer_wallets"
+	FeesRevenue     = "fees_revenue"
+	InterestExpense = "interest_expense"
+	Settlement      = "settlement"
+	Suspense        = "suspense"
+	FXPosition      = "fx_position"
+)
+
+// SystemWallet returns the id of the system wallet for code and currency, creating it on first
+// use. qtx should be the caller's transaction so the creation commits or rolls back with it.
+func SystemWallet(ctx context.Context, qtx db.Querier, code string, currency string) (uuid.UUID, error) {
+	params := db.GetSystemWalletParams{SystemCode: pgtype.Text{String: code, Valid: true}, Currency: currency}
+
+	wallet, err := qtx.GetSystemWallet(ctx, params)
+	if err == nil {
+		return wallet.ID, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, &utils.RetryableError{Err: err}
+	}
+
+	// ON CONFLICT DO NOTHING returns no row when another transaction created it first.
+	wallet, err = qtx.CreateSystemWallet(ctx, db.CreateSystemWalletParams{Currency: currency, SystemCode: params.SystemCode})
+	if errors.Is(err, pgx.ErrNoRows) {
+		wallet, err = qtx.GetSystemWallet(ctx, params)
+	}
+	if err != nil {
+		return uuid.Nil, &utils.RetryableError{Err: err}
+	}
+	return wallet.ID, nil
+}
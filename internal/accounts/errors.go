@@ -0,0 +1,7 @@
+package accounts
+
+import "errors"
+
+var (
+	ErrInvalidAsOf = errors.New("as_of must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+)
@@ -0,0 +1,69 @@
+package accounts
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	svc Service
+}
+
+func NewHandler(svc Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+func (h *Handler) HandleChartOfAccounts(c *gin.Context) {
+	accounts, err := h.svc.ChartOfAccounts(c.Request.Context())
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"message": "failed to fetch chart of accounts",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "chart of accounts fetched successfully",
+		"data":    accounts,
+	})
+}
+
+// HandleTrialBalance takes ?as_of= as an RFC 3339 timestamp, or a date meaning the end of that
+// UTC day; it defaults to now.
+func (h *Handler) HandleTrialBalance(c *gin.Context) {
+	asOf, err := parseAsOf(c.Query("as_of"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.svc.TrialBalance(c.Request.Context(), asOf)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"message": "failed to build trial balance",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "trial balance built successfully",
+		"data":    report,
+	})
+}
+
+func parseAsOf(raw string) (time.Time, error) {
+	if raw == "" {
+		return time.Now(), nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	if d, err := time.Parse(time.DateOnly, raw); err == nil {
+		return d.AddDate(0, 0, 1), nil
+	}
+	return time.Time{}, ErrInvalidAsOf
+}
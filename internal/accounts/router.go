@@ -0,0 +1,19 @@
+package accounts
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/luponetn/paycore/internal/middleware"
+)
+
+func RegisterRoutes(r *gin.Engine, h *Handler, adminKey string) {
+	accountsGroup := r.Group("/admin/accounts")
+
+	//use middlewares
+	accountsGroup.Use(middleware.AdminKeyMiddleware(adminKey))
+
+	//implement routes
+	{
+		accountsGroup.GET("", h.HandleChartOfAccounts)
+		accountsGroup.GET("/trial-balance", h.HandleTrialBalance)
+	}
+}
@@ -0,0 +1,118 @@
+package accounts
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/store"
+	"github.com/luponetn/paycore/pkg/utils"
+)
+
+type Service interface {
+	ChartOfAccounts(ctx context.Context) ([]AccountResponse, error)
+	TrialBalance(ctx context.Context, asOf time.Time) (TrialBalance, error)
+}
+
+type Svc struct {
+	store store.Store
+}
+
+func NewService(store store.Store) Service {
+	return &Svc{store: store}
+}
+
+// ChartOfAccounts lists every account with the system wallets opened under it so far.
+func (s *Svc) ChartOfAccounts(ctx context.Context) ([]AccountResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 8*time.Second)
+	defer cancel()
+
+	chart, err := s.store.Queries().GetChartOfAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	wallets, err := s.store.Queries().GetSystemWallets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byCode := make(map[string][]SystemWalletResponse)
+	for _, w := range wallets {
+		byCode[w.SystemCode.String] = append(byCode[w.SystemCode.String], SystemWalletResponse{
+			WalletID: w.ID,
+			Currency: w.Currency,
+			Balance:  utils.NumericToDecimal(w.Balance),
+		})
+	}
+
+	accounts := make([]AccountResponse, 0, len(chart))
+	for _, account := range chart {
+		accounts = append(accounts, AccountResponse{
+			Code:          account.Code,
+			Name:          account.Name,
+			AccountType:   account.AccountType,
+			NormalBalance: account.NormalBalance,
+			Description:   account.Description.String,
+			Wallets:       byCode[account.Code],
+		})
+	}
+	return accounts, nil
+}
+
+// TrialBalance totals ledger entries posted before asOf by account and currency. Every
+// transaction posts equal debits and credits, so each currency's totals must agree; Balanced
+// reports whether they do.
+func (s *Svc) TrialBalance(ctx context.Context, asOf time.Time) (TrialBalance, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	chart, err := s.store.Queries().GetChartOfAccounts(ctx)
+	if err != nil {
+		return TrialBalance{}, err
+	}
+	byCode := make(map[string]db.ChartOfAccount, len(chart))
+	for _, account := range chart {
+		byCode[account.Code] = account
+	}
+
+	rows, err := s.store.Queries().GetTrialBalance(ctx, pgtype.Timestamptz{Time: asOf, Valid: true})
+	if err != nil {
+		return TrialBalance{}, err
+	}
+
+	report := TrialBalance{
+		AsOf:     asOf,
+		Lines:    make([]TrialBalanceLine, 0, len(rows)),
+		Totals:   make(map[string]CurrencyTotals),
+		Balanced: true,
+	}
+	for _, row := range rows {
+		account := byCode[row.AccountCode]
+		line := TrialBalanceLine{
+			AccountCode: row.AccountCode,
+			Name:        account.Name,
+			AccountType: account.AccountType,
+			Currency:    row.Currency,
+			Debits:      utils.NumericToDecimal(row.Debits),
+			Credits:     utils.NumericToDecimal(row.Credits),
+		}
+		line.Balance = line.Credits.Sub(line.Debits)
+		if account.NormalBalance == db.LedgerEntryTypeDebit {
+			line.Balance = line.Balance.Neg()
+		}
+		report.Lines = append(report.Lines, line)
+
+		totals := report.Totals[row.Currency]
+		totals.Debits = totals.Debits.Add(line.Debits)
+		totals.Credits = totals.Credits.Add(line.Credits)
+		report.Totals[row.Currency] = totals
+	}
+
+	for currency, totals := range report.Totals {
+		totals.Balanced = totals.Debits.Equal(totals.Credits)
+		report.Totals[currency] = totals
+		report.Balanced = report.Balanced && totals.Balanced
+	}
+	return report, nil
+}
@@ -0,0 +1,78 @@
+package accounts_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/luponetn/paycore/internal/accounts"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/ledger"
+	"github.com/luponetn/paycore/internal/store"
+	"github.com/luponetn/paycore/pkg/utils"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrialBalance(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := accounts.NewService(f)
+	ctx := context.Background()
+
+	payer := f.AddFakeFundedWallet(uuid.New(), db.WalletTypeEnumSavings, "NGN", "100")
+	payee := f.AddFakeFundedWallet(uuid.New(), db.WalletTypeEnumSavings, "NGN", "0")
+
+	// A payment of 40 with a fee of 5 charged to the payer, landing on the fees revenue account.
+	_, err := ledger.Post(ctx, f.Queries(), ledger.Journal{
+		TransactionType: db.TransactionTypeEnumTransfer,
+		IdempotencyKey:  uuid.New().String(),
+		Postings: []ledger.Posting{
+			ledger.Debit(payer, decimal.NewFromInt(45), "NGN"),
+			ledger.Credit(payee, decimal.NewFromInt(40), "NGN"),
+			ledger.CreditAccount(accounts.FeesRevenue, decimal.NewFromInt(5), "NGN"),
+		},
+	})
+	require.NoError(t, err)
+
+	feesID, err := accounts.SystemWallet(ctx, f.Queries(), accounts.FeesRevenue, "NGN")
+	require.NoError(t, err)
+	again, err := accounts.SystemWallet(ctx, f.Queries(), accounts.FeesRevenue, "NGN")
+	require.NoError(t, err)
+	require.Equal(t, feesID, again)
+
+	fee := utils.DecimalToNumeric(decimal.NewFromInt(5))
+	report, err := svc.TrialBalance(ctx, time.Now().Add(time.Second))
+	require.NoError(t, err)
+	require.True(t, report.Balanced)
+	require.Equal(t, "45", report.Totals["NGN"].Debits.String())
+	require.Equal(t, "45", report.Totals["NGN"].Credits.String())
+
+	balances := make(map[string]string)
+	for _, line := range report.Lines {
+		balances[line.AccountCode] = line.Balance.String()
+	}
+	require.Equal(t, map[string]string{accounts.CustomerWallets: "-5", accounts.FeesRevenue: "5"}, balances)
+
+	// Entries after as_of are left out.
+	before, err := svc.TrialBalance(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Empty(t, before.Lines)
+
+	// A one-sided posting shows up as an imbalance.
+	_, err = f.CreateLedger(ctx, db.CreateLedgerParams{WalletID: payee, TransactionID: uuid.New(), Amount: fee, EntryType: db.LedgerEntryTypeCredit, Currency: "NGN"})
+	require.NoError(t, err)
+	report, err = svc.TrialBalance(ctx, time.Now().Add(time.Second))
+	require.NoError(t, err)
+	require.False(t, report.Balanced)
+	require.False(t, report.Totals["NGN"].Balanced)
+
+	chart, err := svc.ChartOfAccounts(ctx)
+	require.NoError(t, err)
+	for _, account := range chart {
+		if account.Code == accounts.FeesRevenue {
+			require.Len(t, account.Wallets, 1)
+			require.Equal(t, feesID, account.Wallets[0].WalletID)
+		}
+	}
+}
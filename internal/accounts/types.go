@@ -0,0 +1,49 @@
+package accounts
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/shopspring/decimal"
+)
+
+type SystemWalletResponse struct {
+	WalletID uuid.UUID       `json:"wallet_id"`
+	Currency string          `json:"currency"`
+	Balance  decimal.Decimal `json:"balance"`
+}
+
+type AccountResponse struct {
+	Code          string                 `json:"code"`
+	Name          string                 `json:"name"`
+	AccountType   db.AccountTypeEnum     `json:"account_type"`
+	NormalBalance db.LedgerEntryType     `json:"normal_balance"`
+	Description   string                 `json:"description,omitempty"`
+	Wallets       []SystemWalletResponse `json:"wallets,omitempty"`
+}
+
+// TrialBalanceLine totals one account's ledger entries in one currency. Balance is debits less
+// credits for debit-normal accounts and the reverse for credit-normal ones.
+type TrialBalanceLine struct {
+	AccountCode string             `json:"account_code"`
+	Name        string             `json:"name"`
+	AccountType db.AccountTypeEnum `json:"account_type"`
+	Currency    string             `json:"currency"`
+	Debits      decimal.Decimal    `json:"debits"`
+	Credits     decimal.Decimal    `json:"credits"`
+	Balance     decimal.Decimal    `json:"balance"`
+}
+
+type CurrencyTotals struct {
+	Debits   decimal.Decimal `json:"debits"`
+	Credits  decimal.Decimal `json:"credits"`
+	Balanced bool            `json:"balanced"`
+}
+
+type TrialBalance struct {
+	AsOf     time.Time                 `json:"as_of"`
+	Lines    []TrialBalanceLine        `json:"lines"`
+	Totals   map[string]CurrencyTotals `json:"totals"`
+	Balanced bool                      `json:"balanced"`
+}
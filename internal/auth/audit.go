@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/luponetn/paycore/internal/db"
+)
+
+const (
+	AuditAccountLocked   = "account_locked"
+	AuditAccountUnlocked = "account_unlocked"
+	AuditLoginIPBlocked  = "login_ip_blocked"
+)
+
+// recordAudit appends a security event. It is best effort: a failed insert is logged and
+// never fails the request that triggered it.
+func (s *Svc) recordAudit(ctx context.Context, userID pgtype.UUID, event string, meta SessionMeta, details map[string]any) {
+	metadata, err := json.Marshal(details)
+	if err != nil {
+		slog.Error("failed to encode audit metadata", "event", event, "error", err)
+		metadata = []byte("{}")
+	}
+
+	if _, err := s.store.Queries().CreateAuditEvent(ctx, db.CreateAuditEventParams{
+		UserID:    userID,
+		Event:     event,
+		IpAddress: meta.IPAddress,
+		UserAgent: meta.UserAgent,
+		Metadata:  metadata,
+	}); err != nil {
+		slog.Error("failed to record audit event", "event", event, "error", err)
+	}
+}
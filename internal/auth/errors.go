@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"errors"
+
+	"github.com/luponetn/paycore/internal/otp"
+)
+
+var (
+	ErrUserNotFound             = errors.New("user not found")
+	ErrEmailAlreadyVerified     = errors.New("email is already verified")
+	ErrOTPInvalid               = otp.ErrInvalid
+	ErrOTPExpired               = otp.ErrExpired
+	ErrOTPAttemptsExceeded      = otp.ErrAttemptsExceeded
+	ErrOTPCooldown              = errors.New("please wait before requesting another code")
+	ErrInvalidRefreshToken      = errors.New("invalid refresh token")
+	ErrRefreshTokenReused       = errors.New("refresh token has already been used")
+	ErrSessionRevoked           = errors.New("session has been revoked")
+	ErrInvalidSession           = errors.New("token is not bound to a session")
+	ErrInvalidCredentials       = errors.New("invalid email or password")
+	ErrPasswordUnchanged        = errors.New("new password must be different from the current password")
+	ErrResetCredentialsRequired = errors.New("either a reset token or an email and code are required")
+	ErrAccountLocked            = errors.New("account is temporarily locked after too many failed sign-in attempts")
+	ErrTooManyLoginAttempts     = errors.New("too many failed sign-in attempts from this address")
+	ErrLoginThrottled           = errors.New("too many failed sign-in attempts, slow down")
+	ErrMFAAlreadyEnabled        = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnrolled           = errors.New("start two-factor enrollment first")
+	ErrMFANotEnabled            = errors.New("two-factor authentication is not enabled")
+	ErrMFACodeRequired          = errors.New("an authenticator code or recovery code is required")
+	ErrInvalidMFACode           = errors.New("invalid two-factor code")
+	ErrInvalidMFAToken          = errors.New("invalid or expired mfa token")
+	ErrUnsupportedCurrency      = errors.New("wallets cannot be opened in this currency")
+)
@@ -1,10 +1,14 @@
 package auth
 
 import (
+	"errors"
 	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type Handler struct {
@@ -25,6 +29,10 @@ func (h *Handler) SignUp(c *gin.Context) {
 
 	user, err := h.svc.SignUp(c.Request.Context(), req)
 	if err != nil {
+		if errors.Is(err, ErrUnsupportedCurrency) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create user"})
 		slog.Error("failed to create user", "error", err)
 		return
@@ -44,10 +52,36 @@ func (h *Handler) Login(c *gin.Context) {
 		return
 	}
 
-	loginResponse, err := h.svc.Login(c.Request.Context(), req)
+	loginResponse, err := h.svc.Login(c.Request.Context(), req, sessionMeta(c, req.Device))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to login user"})
 		slog.Error("failed to login user", "error", err)
+		abortLoginError(c, err)
+		return
+	}
+
+	message := "user logged in successfully"
+	if loginResponse.MFARequired {
+		message = "two-factor authentication required"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"data":    loginResponse,
+	})
+}
+
+func (h *Handler) VerifyMFA(c *gin.Context) {
+	var req VerifyMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		slog.Error("failed to bind json", "error", err)
+		return
+	}
+
+	loginResponse, err := h.svc.VerifyMFA(c.Request.Context(), req, sessionMeta(c, req.Device))
+	if err != nil {
+		slog.Error("failed to verify mfa", "error", err)
+		abortLoginError(c, err)
 		return
 	}
 
@@ -57,6 +91,27 @@ func (h *Handler) Login(c *gin.Context) {
 	})
 }
 
+// abortLoginError maps sign-in failures: lockouts and throttling carry Retry-After.
+func abortLoginError(c *gin.Context, err error) {
+	var blocked *LoginBlockedError
+	if errors.As(err, &blocked) {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(blocked.RetryAfter.Seconds()))))
+		status := http.StatusTooManyRequests
+		if errors.Is(err, ErrAccountLocked) {
+			status = http.StatusLocked
+		}
+		c.AbortWithStatusJSON(status, gin.H{"error": blocked.Err.Error()})
+		return
+	}
+
+	status := otpErrorStatus(err)
+	if status == http.StatusInternalServerError {
+		c.AbortWithStatusJSON(status, gin.H{"error": "failed to login user"})
+		return
+	}
+	c.AbortWithStatusJSON(status, gin.H{"error": err.Error()})
+}
+
 func (h *Handler) Refresh(c *gin.Context) {
 	var req RefreshRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -64,14 +119,24 @@ func (h *Handler) Refresh(c *gin.Context) {
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
 			"message": "invalid request",
 		})
+		return
 	}
 
-	newTokens, err := h.svc.Refresh(c.Request.Context(), req)
+	newTokens, err := h.svc.Refresh(c.Request.Context(), req, sessionMeta(c, req.Device))
 	if err != nil {
 		slog.Error("unable to generate refresh token response", "error", err)
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-			"message": "Something went wrong with the server",
-		})
+		switch {
+		case errors.Is(err, ErrInvalidRefreshToken), errors.Is(err, ErrRefreshTokenReused), errors.Is(err, ErrSessionRevoked):
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"message": "session expired, please log in again",
+				"error":   err.Error(),
+			})
+		default:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"message": "Something went wrong with the server",
+			})
+		}
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -80,3 +145,390 @@ func (h *Handler) Refresh(c *gin.Context) {
 		"refresh_token": newTokens.RefreshToken,
 	})
 }
+
+func (h *Handler) Logout(c *gin.Context) {
+	userID, sessionID, ok := authSession(c)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Logout(c.Request.Context(), userID, sessionID); err != nil {
+		slog.Error("failed to logout", "error", err)
+		status := http.StatusInternalServerError
+		if errors.Is(err, ErrInvalidSession) {
+			status = http.StatusBadRequest
+		}
+		c.AbortWithStatusJSON(status, gin.H{
+			"message": "failed to logout",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "logged out successfully"})
+}
+
+func (h *Handler) LogoutAll(c *gin.Context) {
+	userID, _, ok := authSession(c)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.LogoutAll(c.Request.Context(), userID); err != nil {
+		slog.Error("failed to logout all sessions", "error", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"message": "failed to logout all sessions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "logged out of all sessions successfully"})
+}
+
+func (h *Handler) ListSessions(c *gin.Context) {
+	userID, sessionID, ok := authSession(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.svc.ListSessions(c.Request.Context(), userID, sessionID)
+	if err != nil {
+		slog.Error("failed to list sessions", "error", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"message": "failed to fetch sessions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "sessions fetched successfully",
+		"data":    sessions,
+	})
+}
+
+// JWKS serves the access token verification keys; responses are cacheable so verifiers
+// are not hitting this on every request.
+func (h *Handler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.svc.JWKS())
+}
+
+func (h *Handler) VerifyEmail(c *gin.Context) {
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		slog.Error("failed to bind json", "error", err)
+		return
+	}
+
+	user, err := h.svc.VerifyEmail(c.Request.Context(), req)
+	if err != nil {
+		slog.Error("failed to verify email", "error", err)
+		c.AbortWithStatusJSON(otpErrorStatus(err), gin.H{
+			"message": "failed to verify email",
+			"error":   otpErrorMessage(err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "email verified successfully",
+		"data":    user,
+	})
+}
+
+func (h *Handler) ResendOTP(c *gin.Context) {
+	var req ResendOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		slog.Error("failed to bind json", "error", err)
+		return
+	}
+
+	if err := h.svc.ResendOTP(c.Request.Context(), req); err != nil {
+		slog.Error("failed to resend otp", "error", err)
+		c.AbortWithStatusJSON(otpErrorStatus(err), gin.H{
+			"message": "failed to resend otp",
+			"error":   otpErrorMessage(err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "if the account exists and is unverified, a new otp has been sent to the email",
+	})
+}
+
+func (h *Handler) ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		slog.Error("failed to bind json", "error", err)
+		return
+	}
+
+	if err := h.svc.ForgotPassword(c.Request.Context(), req); err != nil {
+		slog.Error("failed to start password reset", "error", err)
+		c.AbortWithStatusJSON(otpErrorStatus(err), gin.H{
+			"message": "failed to start password reset",
+			"error":   otpErrorMessage(err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "if the account exists, password reset instructions have been sent to the email",
+	})
+}
+
+func (h *Handler) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		slog.Error("failed to bind json", "error", err)
+		return
+	}
+
+	if err := h.svc.ResetPassword(c.Request.Context(), req, sessionMeta(c, "")); err != nil {
+		slog.Error("failed to reset password", "error", err)
+		c.AbortWithStatusJSON(otpErrorStatus(err), gin.H{
+			"message": "failed to reset password",
+			"error":   otpErrorMessage(err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "password reset successfully, please log in again"})
+}
+
+func (h *Handler) ChangePassword(c *gin.Context) {
+	userID, _, ok := authSession(c)
+	if !ok {
+		return
+	}
+
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		slog.Error("failed to bind json", "error", err)
+		return
+	}
+
+	if err := h.svc.ChangePassword(c.Request.Context(), userID, req, sessionMeta(c, "")); err != nil {
+		slog.Error("failed to change password", "error", err)
+		c.AbortWithStatusJSON(otpErrorStatus(err), gin.H{
+			"message": "failed to change password",
+			"error":   otpErrorMessage(err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "password changed successfully, please log in again"})
+}
+
+func (h *Handler) RequestUnlock(c *gin.Context) {
+	var req RequestUnlockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		slog.Error("failed to bind json", "error", err)
+		return
+	}
+
+	if err := h.svc.RequestUnlock(c.Request.Context(), req); err != nil {
+		slog.Error("failed to send unlock code", "error", err)
+		c.AbortWithStatusJSON(otpErrorStatus(err), gin.H{
+			"message": "failed to send unlock code",
+			"error":   otpErrorMessage(err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "if the account is locked, an unlock code has been sent to the email",
+	})
+}
+
+func (h *Handler) UnlockAccount(c *gin.Context) {
+	var req UnlockAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		slog.Error("failed to bind json", "error", err)
+		return
+	}
+
+	if err := h.svc.UnlockAccount(c.Request.Context(), req, sessionMeta(c, "")); err != nil {
+		slog.Error("failed to unlock account", "error", err)
+		c.AbortWithStatusJSON(otpErrorStatus(err), gin.H{
+			"message": "failed to unlock account",
+			"error":   otpErrorMessage(err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "account unlocked, you can log in again"})
+}
+
+func (h *Handler) MFAStatus(c *gin.Context) {
+	userID, _, ok := authSession(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.svc.MFAStatus(c.Request.Context(), userID)
+	if err != nil {
+		slog.Error("failed to get mfa status", "error", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to get mfa status"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": status})
+}
+
+func (h *Handler) EnrollTOTP(c *gin.Context) {
+	userID, _, ok := authSession(c)
+	if !ok {
+		return
+	}
+
+	enrollment, err := h.svc.EnrollTOTP(c.Request.Context(), userID)
+	if err != nil {
+		slog.Error("failed to enroll totp", "error", err)
+		c.AbortWithStatusJSON(otpErrorStatus(err), gin.H{
+			"message": "failed to start two-factor enrollment",
+			"error":   otpErrorMessage(err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "scan the code with your authenticator app, then confirm with a code from the app",
+		"data":    enrollment,
+	})
+}
+
+func (h *Handler) ConfirmTOTP(c *gin.Context) {
+	userID, _, ok := authSession(c)
+	if !ok {
+		return
+	}
+
+	var req ConfirmTOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		slog.Error("failed to bind json", "error", err)
+		return
+	}
+
+	codes, err := h.svc.ConfirmTOTP(c.Request.Context(), userID, req, sessionMeta(c, ""))
+	if err != nil {
+		slog.Error("failed to confirm totp", "error", err)
+		c.AbortWithStatusJSON(otpErrorStatus(err), gin.H{
+			"message": "failed to enable two-factor authentication",
+			"error":   otpErrorMessage(err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "two-factor authentication enabled, store the recovery codes somewhere safe",
+		"data":    codes,
+	})
+}
+
+func (h *Handler) DisableTOTP(c *gin.Context) {
+	userID, _, ok := authSession(c)
+	if !ok {
+		return
+	}
+
+	var req MFAReauthRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		slog.Error("failed to bind json", "error", err)
+		return
+	}
+
+	if err := h.svc.DisableTOTP(c.Request.Context(), userID, req, sessionMeta(c, "")); err != nil {
+		slog.Error("failed to disable totp", "error", err)
+		abortLoginError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "two-factor authentication disabled"})
+}
+
+func (h *Handler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, _, ok := authSession(c)
+	if !ok {
+		return
+	}
+
+	var req MFAReauthRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		slog.Error("failed to bind json", "error", err)
+		return
+	}
+
+	codes, err := h.svc.RegenerateRecoveryCodes(c.Request.Context(), userID, req, sessionMeta(c, ""))
+	if err != nil {
+		slog.Error("failed to regenerate recovery codes", "error", err)
+		abortLoginError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "new recovery codes generated, the old ones no longer work",
+		"data":    codes,
+	})
+}
+
+func otpErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrOTPInvalid), errors.Is(err, ErrOTPExpired), errors.Is(err, ErrPasswordUnchanged),
+		errors.Is(err, ErrResetCredentialsRequired), errors.Is(err, ErrMFANotEnrolled), errors.Is(err, ErrMFANotEnabled),
+		errors.Is(err, ErrMFACodeRequired):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrInvalidCredentials), errors.Is(err, ErrInvalidMFACode), errors.Is(err, ErrInvalidMFAToken):
+		return http.StatusUnauthorized
+	case errors.Is(err, ErrOTPAttemptsExceeded), errors.Is(err, ErrOTPCooldown):
+		return http.StatusTooManyRequests
+	case errors.Is(err, ErrEmailAlreadyVerified), errors.Is(err, ErrMFAAlreadyEnabled):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// otpErrorMessage hides internal errors from the client while passing domain errors through.
+func otpErrorMessage(err error) string {
+	if otpErrorStatus(err) == http.StatusInternalServerError {
+		return "something went wrong"
+	}
+	return err.Error()
+}
+
+// authSession reads the user and session ids set by the auth middleware.
+func authSession(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userIdVal, exists := c.Get("user_id")
+	if !exists {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	userID, ok := userIdVal.(uuid.UUID)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "invalid user id type"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	sessionID, _ := c.Get("session_id")
+	sid, _ := sessionID.(uuid.UUID)
+	return userID, sid, true
+}
+
+func sessionMeta(c *gin.Context, device string) SessionMeta {
+	return SessionMeta{
+		Device:    device,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
@@ -0,0 +1,181 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/luponetn/paycore/internal/config"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// failures before progressive delays kick in, and the cap on the delay
+	loginDelayAfter = 2
+	loginDelayMax   = 30 * time.Second
+)
+
+// LoginBlockedError is returned when a login attempt is refused before the password is checked.
+// RetryAfter tells the client when it may try again.
+type LoginBlockedError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *LoginBlockedError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", e.Err, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LoginBlockedError) Unwrap() error {
+	return e.Err
+}
+
+// LoginFailure reports which limits a failed attempt just tripped. Each flag is only set by
+// the attempt that crossed the limit, so callers can audit every lockout exactly once.
+type LoginFailure struct {
+	Failures      int64
+	AccountLocked bool
+	IPBlocked     bool
+}
+
+// LoginGuard tracks failed sign-ins per account and per client IP.
+type LoginGuard interface {
+	// Check returns a *LoginBlockedError when the account is locked, the IP is blocked or the
+	// account is still inside its progressive delay.
+	Check(ctx context.Context, email string, ip string) error
+	RecordFailure(ctx context.Context, email string, ip string) (LoginFailure, error)
+	RecordSuccess(ctx context.Context, email string) error
+	Locked(ctx context.Context, email string) (bool, error)
+	Unlock(ctx context.Context, email string) error
+}
+
+type RedisLoginGuard struct {
+	rdb         redis.UniversalClient
+	maxAttempts int64
+	ipMax       int64
+	window      time.Duration
+	lockout     time.Duration
+}
+
+// NewRedisLoginGuard keeps the counters in Redis so every API instance shares them. Counters
+// expire LoginAttemptWindow after the most recent failure.
+func NewRedisLoginGuard(rdb redis.UniversalClient, cfg *config.Config) *RedisLoginGuard {
+	return &RedisLoginGuard{
+		rdb:         rdb,
+		maxAttempts: int64(cfg.LoginMaxAttempts),
+		ipMax:       int64(cfg.LoginIPMaxAttempts),
+		window:      cfg.LoginAttemptWindow,
+		lockout:     cfg.LoginLockoutDuration,
+	}
+}
+
+func (g *RedisLoginGuard) Check(ctx context.Context, email string, ip string) error {
+	email = normalizeEmail(email)
+
+	var lockTTL, delayTTL, ipTTL *redis.DurationCmd
+	var ipFailures *redis.StringCmd
+	_, err := g.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		lockTTL = pipe.PTTL(ctx, lockKey(email))
+		delayTTL = pipe.PTTL(ctx, delayKey(email))
+		ipFailures = pipe.Get(ctx, ipFailuresKey(ip))
+		ipTTL = pipe.PTTL(ctx, ipFailuresKey(ip))
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return err
+	}
+
+	if ttl := lockTTL.Val(); ttl > 0 {
+		return &LoginBlockedError{Err: ErrAccountLocked, RetryAfter: ttl}
+	}
+
+	if n, err := ipFailures.Int64(); err == nil && n >= g.ipMax {
+		return &LoginBlockedError{Err: ErrTooManyLoginAttempts, RetryAfter: max(ipTTL.Val(), time.Second)}
+	}
+
+	if ttl := delayTTL.Val(); ttl > 0 {
+		return &LoginBlockedError{Err: ErrLoginThrottled, RetryAfter: ttl}
+	}
+	return nil
+}
+
+func (g *RedisLoginGuard) RecordFailure(ctx context.Context, email string, ip string) (LoginFailure, error) {
+	email = normalizeEmail(email)
+
+	var accountCount, ipCount *redis.IntCmd
+	_, err := g.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		accountCount = pipe.Incr(ctx, accountFailuresKey(email))
+		pipe.PExpire(ctx, accountFailuresKey(email), g.window)
+		ipCount = pipe.Incr(ctx, ipFailuresKey(ip))
+		pipe.PExpire(ctx, ipFailuresKey(ip), g.window)
+		return nil
+	})
+	if err != nil {
+		return LoginFailure{}, err
+	}
+
+	failure := LoginFailure{
+		Failures:  accountCount.Val(),
+		IPBlocked: ipCount.Val() == g.ipMax,
+	}
+
+	if failure.Failures >= g.maxAttempts {
+		// SETNX so concurrent failures racing past the limit lock (and audit) only once
+		locked, err := g.rdb.SetNX(ctx, lockKey(email), failure.Failures, g.lockout).Result()
+		if err != nil {
+			return failure, err
+		}
+		failure.AccountLocked = locked
+		if err := g.rdb.Del(ctx, accountFailuresKey(email), delayKey(email)).Err(); err != nil {
+			return failure, err
+		}
+		return failure, nil
+	}
+
+	if delay := loginDelay(failure.Failures); delay > 0 {
+		if err := g.rdb.Set(ctx, delayKey(email), failure.Failures, delay).Err(); err != nil {
+			return failure, err
+		}
+	}
+	return failure, nil
+}
+
+// RecordSuccess clears the account's counters. The IP counter is left alone so one valid
+// account cannot be used to reset a credential-stuffing run from the same address.
+func (g *RedisLoginGuard) RecordSuccess(ctx context.Context, email string) error {
+	email = normalizeEmail(email)
+	return g.rdb.Del(ctx, accountFailuresKey(email), delayKey(email)).Err()
+}
+
+func (g *RedisLoginGuard) Locked(ctx context.Context, email string) (bool, error) {
+	n, err := g.rdb.Exists(ctx, lockKey(normalizeEmail(email))).Result()
+	return n > 0, err
+}
+
+func (g *RedisLoginGuard) Unlock(ctx context.Context, email string) error {
+	email = normalizeEmail(email)
+	return g.rdb.Del(ctx, lockKey(email), accountFailuresKey(email), delayKey(email)).Err()
+}
+
+// loginDelay is how long an account must wait before its next attempt after failures
+// consecutive failures: nothing for the first few, then doubling from one second.
+func loginDelay(failures int64) time.Duration {
+	if failures < loginDelayAfter {
+		return 0
+	}
+	shift := failures - loginDelayAfter
+	if shift >= 5 {
+		return loginDelayMax
+	}
+	return min(time.Second<<shift, loginDelayMax)
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func accountFailuresKey(email string) string { return "login:failures:account:" + email }
+func ipFailuresKey(ip string) string         { return "login:failures:ip:" + ip }
+func lockKey(email string) string            { return "login:lock:" + email }
+func delayKey(email string) string           { return "login:delay:" + email }
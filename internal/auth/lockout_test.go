@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginDelay(t *testing.T) {
+	cases := []struct {
+		failures int64
+		want     time.Duration
+	}{
+		{0, 0},
+		{1, 0},
+		{2, time.Second},
+		{3, 2 * time.Second},
+		{4, 4 * time.Second},
+		{6, 16 * time.Second},
+		{7, loginDelayMax},
+		{64, loginDelayMax},
+	}
+
+	for _, tc := range cases {
+		require.Equal(t, tc.want, loginDelay(tc.failures), "failures=%d", tc.failures)
+	}
+}
+
+func TestLoginBlockedErrorUnwraps(t *testing.T) {
+	err := error(&LoginBlockedError{Err: ErrAccountLocked, RetryAfter: 90 * time.Second})
+
+	require.ErrorIs(t, err, ErrAccountLocked)
+	require.Contains(t, err.Error(), "retry after 1m30s")
+}
@@ -0,0 +1,452 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/pkg/utils"
+)
+
+const (
+	// mfaTokenType marks the short-lived challenge token Login returns when a second factor is due.
+	mfaTokenType      = "mfa"
+	recoveryCodeCount = 10
+)
+
+// EnrollTOTP starts (or restarts) TOTP enrollment with a fresh secret. The factor stays
+// pending, and is not asked for at login, until ConfirmTOTP proves the app was set up.
+func (s *Svc) EnrollTOTP(ctx context.Context, userID uuid.UUID) (TOTPEnrollmentResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	return utils.Retry(3, 100, func() (TOTPEnrollmentResponse, error) {
+		tx, err := s.store.Begin(ctx)
+		if err != nil {
+			return TOTPEnrollmentResponse{}, &utils.RetryableError{Err: err}
+		}
+
+		defer func() {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback enroll totp tx", "error", rbErr)
+			}
+		}()
+
+		qtx := s.store.WithTx(tx)
+
+		user, err := qtx.GetUserByID(ctx, userID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return TOTPEnrollmentResponse{}, ErrUserNotFound
+			}
+			return TOTPEnrollmentResponse{}, &utils.RetryableError{Err: err}
+		}
+
+		factor, err := qtx.GetTOTPFactorForUpdate(ctx, userID)
+		if err == nil && factor.ConfirmedAt.Valid {
+			return TOTPEnrollmentResponse{}, ErrMFAAlreadyEnabled
+		} else if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return TOTPEnrollmentResponse{}, &utils.RetryableError{Err: err}
+		}
+
+		secret, err := utils.GenerateTOTPSecret()
+		if err != nil {
+			return TOTPEnrollmentResponse{}, err
+		}
+
+		sealed, err := utils.SealSecret(s.cfg.MFAEncryptionKey, []byte(secret))
+		if err != nil {
+			return TOTPEnrollmentResponse{}, err
+		}
+
+		if _, err := qtx.UpsertPendingTOTPFactor(ctx, db.UpsertPendingTOTPFactorParams{UserID: userID, Secret: sealed}); err != nil {
+			return TOTPEnrollmentResponse{}, &utils.RetryableError{Err: err}
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return TOTPEnrollmentResponse{}, &utils.RetryableError{Err: err}
+		}
+
+		return TOTPEnrollmentResponse{
+			Secret: secret,
+			URI:    utils.TOTPURI(s.cfg.MFAIssuer, user.Email, secret),
+		}, nil
+	})
+}
+
+// ConfirmTOTP activates a pending factor with a code from the app and returns the recovery
+// codes. This is the only time the plain recovery codes are available.
+func (s *Svc) ConfirmTOTP(ctx context.Context, userID uuid.UUID, req ConfirmTOTPRequest, meta SessionMeta) (RecoveryCodesResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var user db.User
+	codes, err := utils.Retry(3, 100, func() ([]string, error) {
+		tx, err := s.store.Begin(ctx)
+		if err != nil {
+			return nil, &utils.RetryableError{Err: err}
+		}
+
+		defer func() {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback confirm totp tx", "error", rbErr)
+			}
+		}()
+
+		qtx := s.store.WithTx(tx)
+
+		user, err = qtx.GetUserByID(ctx, userID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, ErrUserNotFound
+			}
+			return nil, &utils.RetryableError{Err: err}
+		}
+
+		factor, err := qtx.GetTOTPFactorForUpdate(ctx, userID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, ErrMFANotEnrolled
+			}
+			return nil, &utils.RetryableError{Err: err}
+		}
+		if factor.ConfirmedAt.Valid {
+			return nil, ErrMFAAlreadyEnabled
+		}
+
+		step, err := s.checkTOTP(factor, req.Code)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := qtx.ConfirmTOTPFactor(ctx, db.ConfirmTOTPFactorParams{UserID: userID, LastUsedStep: step}); err != nil {
+			return nil, &utils.RetryableError{Err: err}
+		}
+
+		codes, err := replaceRecoveryCodes(ctx, qtx, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return nil, &utils.RetryableError{Err: err}
+		}
+		return codes, nil
+	})
+	if err != nil {
+		return RecoveryCodesResponse{}, err
+	}
+
+	s.enqueueSecurityAlert(ctx, user, "Two-factor authentication was turned on for your account.", meta)
+	return RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// VerifyMFA completes a two-step login: it exchanges the challenge token from Login plus a
+// TOTP or recovery code for the access/refresh pair. Wrong codes count as failed logins.
+func (s *Svc) VerifyMFA(ctx context.Context, req VerifyMFARequest, meta SessionMeta) (LoginResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	claims, err := utils.VerifyTokenWithKeys(req.MFAToken, s.refreshKeys)
+	if err != nil || claims.TokenType != mfaTokenType {
+		return LoginResponse{}, ErrInvalidMFAToken
+	}
+
+	user, err := utils.Retry(3, 100, func() (db.User, error) {
+		user, err := s.store.Queries().GetUserByID(ctx, claims.UserID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return db.User{}, ErrInvalidMFAToken
+			}
+			return db.User{}, &utils.RetryableError{Err: err}
+		}
+		return user, nil
+	})
+	if err != nil {
+		return LoginResponse{}, err
+	}
+
+	if err := s.loginGuard.Check(ctx, user.Email, meta.IPAddress); err != nil {
+		return LoginResponse{}, err
+	}
+
+	usedRecovery, err := utils.Retry(3, 100, func() (bool, error) {
+		tx, err := s.store.Begin(ctx)
+		if err != nil {
+			return false, &utils.RetryableError{Err: err}
+		}
+
+		defer func() {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback verify mfa tx", "error", rbErr)
+			}
+		}()
+
+		usedRecovery, err := s.verifySecondFactor(ctx, s.store.WithTx(tx), user.ID, req.Code, req.RecoveryCode)
+		if err != nil {
+			return false, err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return false, &utils.RetryableError{Err: err}
+		}
+		return usedRecovery, nil
+	})
+	if errors.Is(err, ErrInvalidMFACode) {
+		if failErr := s.loginFailed(ctx, user.Email, user, meta); !errors.Is(failErr, ErrInvalidCredentials) {
+			return LoginResponse{}, failErr
+		}
+		return LoginResponse{}, ErrInvalidMFACode
+	}
+	if err != nil {
+		return LoginResponse{}, err
+	}
+
+	if err := s.loginGuard.RecordSuccess(ctx, user.Email); err != nil {
+		slog.Error("failed to reset login failures", "user_id", user.ID, "error", err)
+	}
+
+	if usedRecovery {
+		s.enqueueSecurityAlert(ctx, user, "A recovery code was used to sign in to your account. Generate new codes if you are running low.", meta)
+	}
+
+	return utils.Retry(3, 100, func() (LoginResponse, error) {
+		accessToken, refreshToken, err := s.startSession(ctx, s.store.Queries(), user, uuid.New(), pgtype.UUID{}, time.Now(), meta)
+		if err != nil {
+			return LoginResponse{}, err
+		}
+
+		return LoginResponse{
+			User:         toUserResponse(user),
+			AccessToken:  accessToken,
+			RefreshToken: refreshToken,
+		}, nil
+	})
+}
+
+// DisableTOTP removes the factor and its recovery codes after re-authenticating the user with
+// their password and a current second factor.
+func (s *Svc) DisableTOTP(ctx context.Context, userID uuid.UUID, req MFAReauthRequest, meta SessionMeta) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	user, err := s.withMFAReauth(ctx, userID, req, meta, "disable totp", func(qtx db.Querier) error {
+		if err := qtx.DeleteTOTPFactor(ctx, userID); err != nil {
+			return &utils.RetryableError{Err: err}
+		}
+		if err := qtx.DeleteRecoveryCodes(ctx, userID); err != nil {
+			return &utils.RetryableError{Err: err}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.enqueueSecurityAlert(ctx, user, "Two-factor authentication was turned off for your account.", meta)
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces every recovery code, used or not, after re-authentication.
+func (s *Svc) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, req MFAReauthRequest, meta SessionMeta) (RecoveryCodesResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var codes []string
+	user, err := s.withMFAReauth(ctx, userID, req, meta, "regenerate recovery codes", func(qtx db.Querier) error {
+		var err error
+		codes, err = replaceRecoveryCodes(ctx, qtx, userID)
+		return err
+	})
+	if err != nil {
+		return RecoveryCodesResponse{}, err
+	}
+
+	s.enqueueSecurityAlert(ctx, user, "New two-factor recovery codes were generated; the old codes no longer work.", meta)
+	return RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// MFAStatus reports whether TOTP is on and how many recovery codes are left.
+func (s *Svc) MFAStatus(ctx context.Context, userID uuid.UUID) (MFAStatusResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return utils.Retry(3, 100, func() (MFAStatusResponse, error) {
+		factor, err := s.store.Queries().GetTOTPFactor(ctx, userID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return MFAStatusResponse{}, nil
+			}
+			return MFAStatusResponse{}, &utils.RetryableError{Err: err}
+		}
+
+		remaining, err := s.store.Queries().CountUnusedRecoveryCodes(ctx, userID)
+		if err != nil {
+			return MFAStatusResponse{}, &utils.RetryableError{Err: err}
+		}
+
+		return MFAStatusResponse{
+			TOTPEnabled:            factor.ConfirmedAt.Valid,
+			TOTPEnabledAt:          factor.ConfirmedAt,
+			RecoveryCodesRemaining: remaining,
+		}, nil
+	})
+}
+
+// mfaRequired reports whether the user has a confirmed second factor.
+func (s *Svc) mfaRequired(ctx context.Context, userID uuid.UUID) (bool, error) {
+	factor, err := s.store.Queries().GetTOTPFactor(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, &utils.RetryableError{Err: err}
+	}
+	return factor.ConfirmedAt.Valid, nil
+}
+
+// withMFAReauth runs fn in a transaction after checking the password and a second factor.
+// Failed checks go through the login guard, so a stolen access token cannot be used to
+// brute-force either of them.
+func (s *Svc) withMFAReauth(ctx context.Context, userID uuid.UUID, req MFAReauthRequest, meta SessionMeta, op string, fn func(qtx db.Querier) error) (db.User, error) {
+	user, err := utils.Retry(3, 100, func() (db.User, error) {
+		user, err := s.store.Queries().GetUserByID(ctx, userID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return db.User{}, ErrUserNotFound
+			}
+			return db.User{}, &utils.RetryableError{Err: err}
+		}
+		return user, nil
+	})
+	if err != nil {
+		return db.User{}, err
+	}
+
+	if err := s.loginGuard.Check(ctx, user.Email, meta.IPAddress); err != nil {
+		return db.User{}, err
+	}
+
+	_, err = utils.Retry(3, 100, func() (struct{}, error) {
+		tx, err := s.store.Begin(ctx)
+		if err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+
+		defer func() {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback "+op+" tx", "error", rbErr)
+			}
+		}()
+
+		qtx := s.store.WithTx(tx)
+
+		if err := utils.CheckPassword(req.Password, user.Passwordhash); err != nil {
+			return struct{}{}, ErrInvalidCredentials
+		}
+
+		if _, err := s.verifySecondFactor(ctx, qtx, userID, req.Code, req.RecoveryCode); err != nil {
+			return struct{}{}, err
+		}
+
+		if err := fn(qtx); err != nil {
+			return struct{}{}, err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+		return struct{}{}, nil
+	})
+	if errors.Is(err, ErrInvalidCredentials) || errors.Is(err, ErrInvalidMFACode) {
+		if failErr := s.loginFailed(ctx, user.Email, user, meta); !errors.Is(failErr, ErrInvalidCredentials) {
+			return db.User{}, failErr
+		}
+		return db.User{}, err
+	}
+	if err != nil {
+		return db.User{}, err
+	}
+
+	if err := s.loginGuard.RecordSuccess(ctx, user.Email); err != nil {
+		slog.Error("failed to reset login failures", "user_id", user.ID, "error", err)
+	}
+	return user, nil
+}
+
+// verifySecondFactor accepts either a TOTP code or an unused recovery code and burns it.
+// It reports whether a recovery code was used.
+func (s *Svc) verifySecondFactor(ctx context.Context, qtx db.Querier, userID uuid.UUID, code string, recoveryCode string) (bool, error) {
+	factor, err := qtx.GetTOTPFactorForUpdate(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, ErrMFANotEnabled
+		}
+		return false, &utils.RetryableError{Err: err}
+	}
+	if !factor.ConfirmedAt.Valid {
+		return false, ErrMFANotEnabled
+	}
+
+	switch {
+	case code != "":
+		step, err := s.checkTOTP(factor, code)
+		if err != nil {
+			return false, err
+		}
+		if err := qtx.UpdateTOTPLastUsedStep(ctx, db.UpdateTOTPLastUsedStepParams{UserID: userID, LastUsedStep: step}); err != nil {
+			return false, &utils.RetryableError{Err: err}
+		}
+		return false, nil
+	case recoveryCode != "":
+		used, err := qtx.UseRecoveryCode(ctx, db.UseRecoveryCodeParams{UserID: userID, CodeHash: utils.HashRecoveryCode(recoveryCode)})
+		if err != nil {
+			return false, &utils.RetryableError{Err: err}
+		}
+		if used == 0 {
+			return false, ErrInvalidMFACode
+		}
+		return true, nil
+	default:
+		return false, ErrMFACodeRequired
+	}
+}
+
+// checkTOTP validates code against the factor's secret and returns the matched time step.
+func (s *Svc) checkTOTP(factor db.TotpFactor, code string) (int64, error) {
+	secret, err := utils.OpenSecret(s.cfg.MFAEncryptionKey, factor.Secret)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decrypt totp secret: %w", err)
+	}
+
+	step, ok := utils.ValidateTOTP(string(secret), code, time.Now(), factor.LastUsedStep)
+	if !ok {
+		return 0, ErrInvalidMFACode
+	}
+	return step, nil
+}
+
+// replaceRecoveryCodes deletes the user's recovery codes and stores a fresh set, hashed.
+func replaceRecoveryCodes(ctx context.Context, qtx db.Querier, userID uuid.UUID) ([]string, error) {
+	codes, err := utils.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := qtx.DeleteRecoveryCodes(ctx, userID); err != nil {
+		return nil, &utils.RetryableError{Err: err}
+	}
+
+	for _, code := range codes {
+		if err := qtx.CreateRecoveryCode(ctx, db.CreateRecoveryCodeParams{UserID: userID, CodeHash: utils.HashRecoveryCode(code)}); err != nil {
+			return nil, &utils.RetryableError{Err: err}
+		}
+	}
+	return codes, nil
+}
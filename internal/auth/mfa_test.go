@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/luponetn/paycore/internal/store"
+	"github.com/luponetn/paycore/pkg/utils"
+	"github.com/stretchr/testify/require"
+)
+
+// enableTOTP enrolls and confirms a factor for the user and returns its secret, the step the
+// confirmation used and the recovery codes.
+func enableTOTP(t *testing.T, svc *Svc, userID uuid.UUID) (string, int64, []string) {
+	t.Helper()
+	ctx := context.Background()
+
+	enrollment, err := svc.EnrollTOTP(ctx, userID)
+	require.NoError(t, err)
+	require.Contains(t, enrollment.URI, "otpauth://totp/")
+
+	_, err = svc.ConfirmTOTP(ctx, userID, ConfirmTOTPRequest{Code: "000000"}, SessionMeta{})
+	require.ErrorIs(t, err, ErrInvalidMFACode)
+
+	step := utils.TOTPStep(time.Now())
+	code, err := utils.TOTPCode(enrollment.Secret, step)
+	require.NoError(t, err)
+	recovery, err := svc.ConfirmTOTP(ctx, userID, ConfirmTOTPRequest{Code: code}, SessionMeta{})
+	require.NoError(t, err)
+	require.Len(t, recovery.RecoveryCodes, recoveryCodeCount)
+
+	return enrollment.Secret, step, recovery.RecoveryCodes
+}
+
+func TestTOTP_EnrollConfirmAndLogin(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := newTestService(f, newFakeLoginGuard(5))
+	ctx := context.Background()
+	user := addTestUser(t, f)
+
+	// A pending factor is not asked for at login.
+	_, err := svc.EnrollTOTP(ctx, user.ID)
+	require.NoError(t, err)
+	signedIn, err := svc.Login(ctx, LoginRequest{Email: user.Email, Password: testPassword}, SessionMeta{})
+	require.NoError(t, err)
+	require.False(t, signedIn.MFARequired)
+	require.NotEmpty(t, signedIn.RefreshToken)
+
+	secret, step, _ := enableTOTP(t, svc, user.ID)
+
+	_, err = svc.EnrollTOTP(ctx, user.ID)
+	require.ErrorIs(t, err, ErrMFAAlreadyEnabled)
+
+	status, err := svc.MFAStatus(ctx, user.ID)
+	require.NoError(t, err)
+	require.True(t, status.TOTPEnabled)
+	require.EqualValues(t, recoveryCodeCount, status.RecoveryCodesRemaining)
+
+	// With the factor on, Login only hands out an mfa challenge token.
+	resp, err := svc.Login(ctx, LoginRequest{Email: user.Email, Password: testPassword}, SessionMeta{})
+	require.NoError(t, err)
+	require.True(t, resp.MFARequired)
+	require.Empty(t, resp.AccessToken)
+	require.Empty(t, resp.RefreshToken)
+
+	claims, err := utils.VerifyTokenWithKeys(resp.MFAToken, svc.refreshKeys)
+	require.NoError(t, err)
+	require.Equal(t, mfaTokenType, claims.TokenType)
+	require.Equal(t, user.ID, claims.UserID)
+
+	// A refresh token is signed with the same keys but is not a challenge token.
+	_, err = svc.VerifyMFA(ctx, VerifyMFARequest{MFAToken: signedIn.RefreshToken, Code: "000000"}, SessionMeta{})
+	require.ErrorIs(t, err, ErrInvalidMFAToken)
+
+	next, err := utils.TOTPCode(secret, step+1)
+	require.NoError(t, err)
+	verified, err := svc.VerifyMFA(ctx, VerifyMFARequest{MFAToken: resp.MFAToken, Code: next}, SessionMeta{})
+	require.NoError(t, err)
+	require.NotEmpty(t, verified.AccessToken)
+
+	access, err := utils.VerifyTokenWithKeys(verified.AccessToken, svc.accessKeys)
+	require.NoError(t, err)
+	require.Equal(t, "access", access.TokenType)
+}
+
+func TestVerifyMFA_CodesWorkOnce(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := newTestService(f, newFakeLoginGuard(10))
+	ctx := context.Background()
+	user := addTestUser(t, f)
+	secret, step, recoveryCodes := enableTOTP(t, svc, user.ID)
+
+	resp, err := svc.Login(ctx, LoginRequest{Email: user.Email, Password: testPassword}, SessionMeta{})
+	require.NoError(t, err)
+
+	// The code that confirmed the factor cannot sign in.
+	confirmed, err := utils.TOTPCode(secret, step)
+	require.NoError(t, err)
+	_, err = svc.VerifyMFA(ctx, VerifyMFARequest{MFAToken: resp.MFAToken, Code: confirmed}, SessionMeta{})
+	require.ErrorIs(t, err, ErrInvalidMFACode)
+
+	next, err := utils.TOTPCode(secret, step+1)
+	require.NoError(t, err)
+	_, err = svc.VerifyMFA(ctx, VerifyMFARequest{MFAToken: resp.MFAToken, Code: next}, SessionMeta{})
+	require.NoError(t, err)
+	_, err = svc.VerifyMFA(ctx, VerifyMFARequest{MFAToken: resp.MFAToken, Code: next}, SessionMeta{})
+	require.ErrorIs(t, err, ErrInvalidMFACode)
+
+	factor, err := f.GetTOTPFactor(ctx, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, step+1, factor.LastUsedStep)
+
+	// Recovery codes are single use too.
+	_, err = svc.VerifyMFA(ctx, VerifyMFARequest{MFAToken: resp.MFAToken, RecoveryCode: recoveryCodes[0]}, SessionMeta{})
+	require.NoError(t, err)
+	_, err = svc.VerifyMFA(ctx, VerifyMFARequest{MFAToken: resp.MFAToken, RecoveryCode: recoveryCodes[0]}, SessionMeta{})
+	require.ErrorIs(t, err, ErrInvalidMFACode)
+
+	status, err := svc.MFAStatus(ctx, user.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, recoveryCodeCount-1, status.RecoveryCodesRemaining)
+
+	_, err = svc.VerifyMFA(ctx, VerifyMFARequest{MFAToken: resp.MFAToken}, SessionMeta{})
+	require.ErrorIs(t, err, ErrMFACodeRequired)
+}
+
+func TestVerifyMFA_FailuresCountAsFailedLogins(t *testing.T) {
+	f := store.NewFakeStore()
+	guard := newFakeLoginGuard(3)
+	svc := newTestService(f, guard)
+	ctx := context.Background()
+	user := addTestUser(t, f)
+	secret, step, _ := enableTOTP(t, svc, user.ID)
+
+	resp, err := svc.Login(ctx, LoginRequest{Email: user.Email, Password: testPassword}, SessionMeta{IPAddress: "10.0.0.1"})
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		_, err = svc.VerifyMFA(ctx, VerifyMFARequest{MFAToken: resp.MFAToken, Code: "000000"}, SessionMeta{})
+		require.ErrorIs(t, err, ErrInvalidMFACode)
+	}
+
+	// The third failure locks the account and is audited.
+	_, err = svc.VerifyMFA(ctx, VerifyMFARequest{MFAToken: resp.MFAToken, RecoveryCode: "aaaaa-bbbbb"}, SessionMeta{})
+	var blocked *LoginBlockedError
+	require.ErrorAs(t, err, &blocked)
+	require.ErrorIs(t, err, ErrAccountLocked)
+
+	events := f.AuditEvents()
+	require.Len(t, events, 1)
+	require.Equal(t, AuditAccountLocked, events[0].Event)
+
+	// Once locked, even a good code is turned away.
+	next, err := utils.TOTPCode(secret, step+1)
+	require.NoError(t, err)
+	_, err = svc.VerifyMFA(ctx, VerifyMFARequest{MFAToken: resp.MFAToken, Code: next}, SessionMeta{})
+	require.ErrorIs(t, err, ErrAccountLocked)
+
+	require.NoError(t, guard.Unlock(ctx, user.Email))
+	_, err = svc.VerifyMFA(ctx, VerifyMFARequest{MFAToken: resp.MFAToken, Code: next}, SessionMeta{})
+	require.NoError(t, err)
+}
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/otp"
+	"github.com/luponetn/paycore/internal/tasks"
+)
+
+const (
+	PurposeEmailVerification = "email_verification"
+	PurposePasswordReset     = "password_reset"
+
+	otpTTL         = otp.TTL
+	otpResendAfter = otp.ResendAfter
+)
+
+// issueOTP stores a fresh code for the purpose and returns the plain code to email after commit.
+// Codes for auth flows are not bound to a reference.
+func issueOTP(ctx context.Context, qtx db.Querier, userID uuid.UUID, purpose string) (string, error) {
+	_, code, err := otp.Issue(ctx, qtx, userID, purpose, "")
+	return code, err
+}
+
+// issueOTPRecord is issueOTP for callers that also need the stored row, e.g. to sign its id into a link.
+func issueOTPRecord(ctx context.Context, qtx db.Querier, userID uuid.UUID, purpose string) (db.Otp, string, error) {
+	return otp.Issue(ctx, qtx, userID, purpose, "")
+}
+
+// consumeOTP checks and burns the outstanding code for the purpose; see otp.Consume.
+func consumeOTP(ctx context.Context, qtx db.Querier, userID uuid.UUID, purpose string, code string) error {
+	return otp.Consume(ctx, qtx, userID, purpose, "", code)
+}
+
+// enqueueOTPEmail hands the code to the worker. Failures are logged rather than returned:
+// the OTP is already stored and the user can ask for it to be resent.
+func (s *Svc) enqueueOTPEmail(ctx context.Context, user db.User, code string, purpose string) {
+	task, err := tasks.NewSendOTPEmailTask(tasks.SendOTPEmailPayload{
+		UserID:   user.ID.String(),
+		Email:    user.Email,
+		FullName: user.FullName,
+		OTP:      code,
+		Purpose:  purpose,
+
+		ExpiresInMinutes: int(otpTTL.Minutes()),
+	})
+	if err != nil {
+		return
+	}
+
+	if _, err := s.taskClient.EnqueueContext(ctx, task, asynq.Queue(tasks.QueueCritical), asynq.MaxRetry(5)); err != nil {
+		slog.Error("failed to enqueue otp email", "user_id", user.ID, "purpose", purpose, "error", err)
+	}
+}
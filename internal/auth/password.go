@@ -0,0 +1,305 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/tasks"
+	"github.com/luponetn/paycore/pkg/utils"
+)
+
+// ForgotPassword emails a reset code, or a signed reset link when PasswordResetMode is "link".
+// Unknown emails and requests inside the resend cooldown succeed silently so the endpoint
+// cannot be used to probe for accounts.
+func (s *Svc) ForgotPassword(ctx context.Context, req ForgotPasswordRequest) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var user db.User
+	var otp db.Otp
+	code, err := utils.Retry(3, 100, func() (string, error) {
+		tx, err := s.store.Begin(ctx)
+		if err != nil {
+			return "", &utils.RetryableError{Err: err}
+		}
+
+		defer func() {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback forgot password tx", "error", rbErr)
+			}
+		}()
+
+		qtx := s.store.WithTx(tx)
+
+		user, err = qtx.GetUserByEmail(ctx, req.Email)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return "", ErrUserNotFound
+			}
+			return "", &utils.RetryableError{Err: err}
+		}
+
+		latest, err := qtx.GetLatestOTPByPurpose(ctx, db.GetLatestOTPByPurposeParams{UserID: user.ID, Purpose: PurposePasswordReset})
+		if err == nil && time.Since(latest.CreatedAt.Time) < otpResendAfter {
+			return "", ErrOTPCooldown
+		} else if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return "", &utils.RetryableError{Err: err}
+		}
+
+		var code string
+		otp, code, err = issueOTPRecord(ctx, qtx, user.ID, PurposePasswordReset)
+		if err != nil {
+			return "", &utils.RetryableError{Err: err}
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return "", &utils.RetryableError{Err: err}
+		}
+		return code, nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) || errors.Is(err, ErrOTPCooldown) {
+			slog.Info("password reset not sent", "reason", err)
+			return nil
+		}
+		return err
+	}
+
+	payload := tasks.SendPasswordResetEmailPayload{
+		UserID:           user.ID.String(),
+		Email:            user.Email,
+		FullName:         user.FullName,
+		ExpiresInMinutes: int(otpTTL.Minutes()),
+	}
+
+	if s.cfg.PasswordResetMode == "link" {
+		// The link carries a token signed by us whose jti is the otp row, which keeps it single-use.
+		token, err := utils.GenerateSessionToken(user.ID, user.Username, s.refreshKeys, PurposePasswordReset, uuid.Nil, otp.ID)
+		if err != nil {
+			return err
+		}
+		link, err := url.Parse(s.cfg.PasswordResetURL)
+		if err != nil {
+			return err
+		}
+		query := link.Query()
+		query.Set("token", token)
+		link.RawQuery = query.Encode()
+		payload.Link = link.String()
+	} else {
+		payload.Code = code
+	}
+
+	task, err := tasks.NewSendPasswordResetEmailTask(payload)
+	if err != nil {
+		return err
+	}
+	if _, err := s.taskClient.EnqueueContext(ctx, task, asynq.Queue(tasks.QueueCritical), asynq.MaxRetry(5)); err != nil {
+		slog.Error("failed to enqueue password reset email", "user_id", user.ID, "error", err)
+	}
+	return nil
+}
+
+// ResetPassword sets a new password using either the emailed code (with the email) or the
+// signed link token, then signs the user out everywhere.
+func (s *Svc) ResetPassword(ctx context.Context, req ResetPasswordRequest, meta SessionMeta) error {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	var linkClaims *utils.MyClaims
+	if req.Token != "" {
+		linkClaims, err = utils.VerifyTokenWithKeys(req.Token, s.refreshKeys)
+		if err != nil {
+			return ErrOTPInvalid
+		}
+	} else if req.Email == "" || req.Code == "" {
+		return ErrResetCredentialsRequired
+	}
+
+	user, err := utils.Retry(3, 100, func() (db.User, error) {
+		tx, err := s.store.Begin(ctx)
+		if err != nil {
+			return db.User{}, &utils.RetryableError{Err: err}
+		}
+
+		defer func() {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback reset password tx", "error", rbErr)
+			}
+		}()
+
+		qtx := s.store.WithTx(tx)
+
+		var user db.User
+		if linkClaims != nil {
+			user, err = consumeResetLink(ctx, qtx, linkClaims)
+			if err != nil {
+				return db.User{}, err
+			}
+		} else {
+			user, err = qtx.GetUserByEmail(ctx, req.Email)
+			if err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return db.User{}, ErrOTPInvalid
+				}
+				return db.User{}, &utils.RetryableError{Err: err}
+			}
+
+			if otpErr := consumeOTP(ctx, qtx, user.ID, PurposePasswordReset, req.Code); otpErr != nil {
+				if utils.IsRetryableError(otpErr) {
+					return db.User{}, otpErr
+				}
+				// keep the attempt count even though the reset failed
+				if err := tx.Commit(ctx); err != nil {
+					return db.User{}, &utils.RetryableError{Err: err}
+				}
+				return db.User{}, otpErr
+			}
+		}
+
+		if err := s.setPassword(ctx, qtx, user.ID, hashedPassword, "password reset"); err != nil {
+			return db.User{}, err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return db.User{}, &utils.RetryableError{Err: err}
+		}
+		return user, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// a successful reset proves ownership of the email, so it also lifts any login lockout
+	if err := s.loginGuard.Unlock(ctx, user.Email); err != nil {
+		slog.Error("failed to clear login lockout after password reset", "user_id", user.ID, "error", err)
+	}
+
+	s.enqueueSecurityAlert(ctx, user, "Your password was reset and you were signed out of every device.", meta)
+	return nil
+}
+
+// ChangePassword replaces the password of a signed-in user after checking the current one,
+// then revokes every session so other devices have to sign in again.
+func (s *Svc) ChangePassword(ctx context.Context, userID uuid.UUID, req ChangePasswordRequest, meta SessionMeta) error {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	if req.CurrentPassword == req.NewPassword {
+		return ErrPasswordUnchanged
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	user, err := utils.Retry(3, 100, func() (db.User, error) {
+		tx, err := s.store.Begin(ctx)
+		if err != nil {
+			return db.User{}, &utils.RetryableError{Err: err}
+		}
+
+		defer func() {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback change password tx", "error", rbErr)
+			}
+		}()
+
+		qtx := s.store.WithTx(tx)
+
+		user, err := qtx.GetUserByID(ctx, userID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return db.User{}, ErrUserNotFound
+			}
+			return db.User{}, &utils.RetryableError{Err: err}
+		}
+
+		if err := utils.CheckPassword(req.CurrentPassword, user.Passwordhash); err != nil {
+			return db.User{}, ErrInvalidCredentials
+		}
+
+		if err := s.setPassword(ctx, qtx, user.ID, hashedPassword, "password change"); err != nil {
+			return db.User{}, err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return db.User{}, &utils.RetryableError{Err: err}
+		}
+		return user, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.enqueueSecurityAlert(ctx, user, "Your password was changed and you were signed out of every device.", meta)
+	return nil
+}
+
+func (s *Svc) setPassword(ctx context.Context, qtx db.Querier, userID uuid.UUID, hashedPassword string, reason string) error {
+	if _, err := qtx.UpdateUser(ctx, db.UpdateUserParams{
+		PasswordHash: pgtype.Text{String: hashedPassword, Valid: true},
+		ID:           userID,
+	}); err != nil {
+		return &utils.RetryableError{Err: err}
+	}
+
+	if err := qtx.RevokeUserRefreshSessions(ctx, db.RevokeUserRefreshSessionsParams{
+		UserID:        userID,
+		RevokedReason: pgtype.Text{String: reason, Valid: true},
+	}); err != nil {
+		return &utils.RetryableError{Err: err}
+	}
+	return nil
+}
+
+// consumeResetLink burns the otp row a reset link points at.
+func consumeResetLink(ctx context.Context, qtx db.Querier, claims *utils.MyClaims) (db.User, error) {
+	if claims.TokenType != PurposePasswordReset {
+		return db.User{}, ErrOTPInvalid
+	}
+
+	otpID, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return db.User{}, ErrOTPInvalid
+	}
+
+	otp, err := qtx.GetOTPByIdForUpdate(ctx, otpID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.User{}, ErrOTPInvalid
+		}
+		return db.User{}, &utils.RetryableError{Err: err}
+	}
+
+	if otp.Purpose != PurposePasswordReset || otp.UserID != claims.UserID || otp.Used.Bool {
+		return db.User{}, ErrOTPInvalid
+	}
+	if otp.ExpiresAt.Time.Before(time.Now()) {
+		return db.User{}, ErrOTPExpired
+	}
+
+	if err := qtx.MarkOTPUsed(ctx, otp.ID); err != nil {
+		return db.User{}, &utils.RetryableError{Err: err}
+	}
+
+	user, err := qtx.GetUserByID(ctx, otp.UserID)
+	if err != nil {
+		return db.User{}, &utils.RetryableError{Err: err}
+	}
+	return user, nil
+}
@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/luponetn/paycore/internal/store"
+	"github.com/luponetn/paycore/pkg/utils"
+	"github.com/stretchr/testify/require"
+)
+
+const newTestPassword = "staple-battery-horse"
+
+func TestResetPassword_CodeWorksOnceBeforeExpiry(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := newTestService(f, newFakeLoginGuard(5))
+	ctx := context.Background()
+	user := addTestUser(t, f)
+
+	signedIn, err := svc.Login(ctx, LoginRequest{Email: user.Email, Password: testPassword}, SessionMeta{})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.ForgotPassword(ctx, ForgotPasswordRequest{Email: user.Email}))
+	code := lastEmailedCode(t, svc)
+
+	// An expired code is refused even though it was never used.
+	f.AgeFakeOTPs(user.ID, otpTTL)
+	err = svc.ResetPassword(ctx, ResetPasswordRequest{Email: user.Email, Code: code, NewPassword: newTestPassword}, SessionMeta{})
+	require.ErrorIs(t, err, ErrOTPExpired)
+
+	require.NoError(t, svc.ForgotPassword(ctx, ForgotPasswordRequest{Email: user.Email}))
+	code = lastEmailedCode(t, svc)
+
+	// Asking again inside the cooldown looks like success but sends nothing.
+	sent := emailsSent(svc)
+	require.NoError(t, svc.ForgotPassword(ctx, ForgotPasswordRequest{Email: user.Email}))
+	require.Equal(t, sent, emailsSent(svc))
+
+	req := ResetPasswordRequest{Email: user.Email, Code: code, NewPassword: newTestPassword}
+	require.NoError(t, svc.ResetPassword(ctx, req, SessionMeta{}))
+	require.ErrorIs(t, svc.ResetPassword(ctx, req, SessionMeta{}), ErrOTPInvalid)
+
+	// The reset signs every device out, including access tokens that have not expired yet.
+	requireSessionActive(t, svc, signedIn.AccessToken, false)
+	_, err = svc.Refresh(ctx, RefreshRequest{RefreshToken: signedIn.RefreshToken}, SessionMeta{})
+	require.ErrorIs(t, err, ErrSessionRevoked)
+
+	_, err = svc.Login(ctx, LoginRequest{Email: user.Email, Password: testPassword}, SessionMeta{})
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+	_, err = svc.Login(ctx, LoginRequest{Email: user.Email, Password: newTestPassword}, SessionMeta{})
+	require.NoError(t, err)
+}
+
+func TestResetPassword_LinkWorksOnce(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := newTestService(f, newFakeLoginGuard(5))
+	svc.cfg.PasswordResetMode = "link"
+	svc.cfg.PasswordResetURL = "https://app.example.com/reset"
+	ctx := context.Background()
+	user := addTestUser(t, f)
+
+	require.NoError(t, svc.ForgotPassword(ctx, ForgotPasswordRequest{Email: user.Email}))
+	link, err := url.Parse(lastEmailedCode(t, svc))
+	require.NoError(t, err)
+	token := link.Query().Get("token")
+	require.NotEmpty(t, token)
+
+	req := ResetPasswordRequest{Token: token, NewPassword: newTestPassword}
+	require.NoError(t, svc.ResetPassword(ctx, req, SessionMeta{}))
+	require.ErrorIs(t, svc.ResetPassword(ctx, req, SessionMeta{}), ErrOTPInvalid)
+
+	// A link that has run out is refused too.
+	f.AgeFakeOTPs(user.ID, otpResendAfter)
+	require.NoError(t, svc.ForgotPassword(ctx, ForgotPasswordRequest{Email: user.Email}))
+	link, err = url.Parse(lastEmailedCode(t, svc))
+	require.NoError(t, err)
+	f.AgeFakeOTPs(user.ID, otpTTL)
+	err = svc.ResetPassword(ctx, ResetPasswordRequest{Token: link.Query().Get("token"), NewPassword: testPassword}, SessionMeta{})
+	require.ErrorIs(t, err, ErrOTPExpired)
+}
+
+func TestChangePassword_EndsEarlierAccessTokens(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := newTestService(f, newFakeLoginGuard(5))
+	ctx := context.Background()
+	user := addTestUser(t, f)
+
+	signedIn, err := svc.Login(ctx, LoginRequest{Email: user.Email, Password: testPassword}, SessionMeta{})
+	require.NoError(t, err)
+
+	err = svc.ChangePassword(ctx, user.ID, ChangePasswordRequest{CurrentPassword: "wrong-password", NewPassword: newTestPassword}, SessionMeta{})
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+	requireSessionActive(t, svc, signedIn.AccessToken, true)
+
+	require.NoError(t, svc.ChangePassword(ctx, user.ID, ChangePasswordRequest{CurrentPassword: testPassword, NewPassword: newTestPassword}, SessionMeta{}))
+	requireSessionActive(t, svc, signedIn.AccessToken, false)
+
+	changed, err := f.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	require.True(t, changed.PasswordChangedAt.Valid)
+
+	// A token from a sign-in after the change works, even within the same second...
+	again, err := svc.Login(ctx, LoginRequest{Email: user.Email, Password: newTestPassword}, SessionMeta{})
+	require.NoError(t, err)
+	requireSessionActive(t, svc, again.AccessToken, true)
+
+	// ...but one issued before it does not, even on a session that is still signed in.
+	claims, err := utils.VerifyTokenWithKeys(again.AccessToken, svc.accessKeys)
+	require.NoError(t, err)
+	claims.IssuedAt.Time = changed.PasswordChangedAt.Time.Add(-time.Minute)
+	active, err := svc.IsSessionActive(ctx, claims)
+	require.NoError(t, err)
+	require.False(t, active)
+}
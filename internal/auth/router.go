@@ -1,11 +1,39 @@
 package auth
 
-import "github.com/gin-gonic/gin"
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/luponetn/paycore/internal/middleware"
+	"github.com/luponetn/paycore/pkg/utils"
+)
 
-func RegisterRoutes(r *gin.Engine, h *Handler) {
+func RegisterRoutes(r *gin.Engine, h *Handler, keys *utils.KeySet, sessions middleware.SessionChecker) {
 	auth := r.Group("/auth")
     {	
 	  auth.POST("/signup", h.SignUp)
 	  auth.POST("/login", h.Login)
+	  auth.POST("/verify", h.VerifyEmail)
+	  auth.POST("/resend-otp", h.ResendOTP)
+	  auth.POST("/refresh", h.Refresh)
+	  auth.POST("/forgot-password", h.ForgotPassword)
+	  auth.POST("/reset-password", h.ResetPassword)
+	  auth.POST("/unlock/request", h.RequestUnlock)
+	  auth.POST("/unlock", h.UnlockAccount)
+	  auth.POST("/mfa/verify", h.VerifyMFA)
+	}
+
+	r.GET("/.well-known/jwks.json", h.JWKS)
+
+	authenticated := r.Group("/auth")
+	authenticated.Use(middleware.AuthMiddleware(keys, sessions))
+	{
+		authenticated.POST("/logout", h.Logout)
+		authenticated.POST("/logout-all", h.LogoutAll)
+		authenticated.GET("/sessions", h.ListSessions)
+		authenticated.POST("/change-password", h.ChangePassword)
+		authenticated.GET("/mfa", h.MFAStatus)
+		authenticated.POST("/mfa/totp/enroll", h.EnrollTOTP)
+		authenticated.POST("/mfa/totp/confirm", h.ConfirmTOTP)
+		authenticated.POST("/mfa/totp/disable", h.DisableTOTP)
+		authenticated.POST("/mfa/recovery-codes", h.RegenerateRecoveryCodes)
 	}
 }
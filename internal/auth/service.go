@@ -2,36 +2,72 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"log/slog"
+	"strings"
 	"time"
 
-	// "time"
-
-	// "github.com/jackc/pgx/v5/pgtype"
-
-	// "github.com/google/uuid"
+	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/luponetn/paycore/internal/config"
 	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/outbox"
 	"github.com/luponetn/paycore/internal/store"
 	"github.com/luponetn/paycore/pkg/utils"
 )
 
+// TaskEnqueuer is the part of *asynq.Client the service needs.
+type TaskEnqueuer interface {
+	EnqueueContext(ctx context.Context, task *asynq.Task, opts ...asynq.Option) (*asynq.TaskInfo, error)
+}
+
 type Svc struct {
-	store      store.Store
-	cfg        *config.Config
-	taskClient *asynq.Client
+	store       store.Store
+	cfg         *config.Config
+	taskClient  TaskEnqueuer
+	accessKeys  *utils.KeySet
+	refreshKeys *utils.KeySet
+	loginGuard  LoginGuard
 }
 
 type Service interface {
 	SignUp(ctx context.Context, req SignUpRequest) (UserResponse, error)
-	Login(ctx context.Context, req LoginRequest) (LoginResponse, error)
-	Refresh(ctx context.Context, req RefreshRequest) (RefreshResponse, error)
-	//CreateOTP(ctx context.Context, userID uuid.UUID) (OTPResponse, error)
+	Login(ctx context.Context, req LoginRequest, meta SessionMeta) (LoginResponse, error)
+	Refresh(ctx context.Context, req RefreshRequest, meta SessionMeta) (RefreshResponse, error)
+	Logout(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) error
+	LogoutAll(ctx context.Context, userID uuid.UUID) error
+	ListSessions(ctx context.Context, userID uuid.UUID, currentSessionID uuid.UUID) ([]SessionResponse, error)
+	IsSessionActive(ctx context.Context, claims *utils.MyClaims) (bool, error)
+	VerifyEmail(ctx context.Context, req VerifyEmailRequest) (UserResponse, error)
+	ResendOTP(ctx context.Context, req ResendOTPRequest) error
+	ForgotPassword(ctx context.Context, req ForgotPasswordRequest) error
+	ResetPassword(ctx context.Context, req ResetPasswordRequest, meta SessionMeta) error
+	ChangePassword(ctx context.Context, userID uuid.UUID, req ChangePasswordRequest, meta SessionMeta) error
+	RequestUnlock(ctx context.Context, req RequestUnlockRequest) error
+	UnlockAccount(ctx context.Context, req UnlockAccountRequest, meta SessionMeta) error
+	VerifyMFA(ctx context.Context, req VerifyMFARequest, meta SessionMeta) (LoginResponse, error)
+	EnrollTOTP(ctx context.Context, userID uuid.UUID) (TOTPEnrollmentResponse, error)
+	ConfirmTOTP(ctx context.Context, userID uuid.UUID, req ConfirmTOTPRequest, meta SessionMeta) (RecoveryCodesResponse, error)
+	DisableTOTP(ctx context.Context, userID uuid.UUID, req MFAReauthRequest, meta SessionMeta) error
+	RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, req MFAReauthRequest, meta SessionMeta) (RecoveryCodesResponse, error)
+	MFAStatus(ctx context.Context, userID uuid.UUID) (MFAStatusResponse, error)
+	JWKS() utils.JWKS
 }
 
-func NewService(store store.Store, taskClient *asynq.Client, cfg *config.Config) Service {
-	return &Svc{store: store, cfg: cfg, taskClient: taskClient}
+// NewService wires the auth service. accessKeys signs access tokens and may be asymmetric so
+// other services can verify them; refresh tokens are only ever read back by this service, so
+// they stay HS256 with JWTRefreshSecret. loginGuard throttles failed sign-ins.
+func NewService(store store.Store, taskClient TaskEnqueuer, cfg *config.Config, accessKeys *utils.KeySet, loginGuard LoginGuard) Service {
+	return &Svc{
+		store:       store,
+		cfg:         cfg,
+		taskClient:  taskClient,
+		accessKeys:  accessKeys,
+		refreshKeys: utils.NewHMACKeySet(cfg.JWTRefreshSecret),
+		loginGuard:  loginGuard,
+	}
 }
 
 // SignUp handles the business logic for user registration
@@ -40,11 +76,20 @@ func (s *Svc) SignUp(ctx context.Context, req SignUpRequest) (UserResponse, erro
 	ctx, cancel := context.WithTimeout(ctx, 20*time.Second)
 	defer cancel()
 
-	return utils.Retry(3, 100, func() (UserResponse, error) {
+	currency := s.cfg.DefaultCurrency
+	if req.Currency != "" {
+		currency = strings.ToUpper(req.Currency)
+		if !s.cfg.SupportsCurrency(currency) {
+			return UserResponse{}, ErrUnsupportedCurrency
+		}
+	}
+
+	var code string
+	user, err := utils.Retry(3, 100, func() (db.User, error) {
 		//  Hash the password
 		hashedPassword, err := utils.HashPassword(req.Password)
 		if err != nil {
-			return UserResponse{}, err
+			return db.User{}, err
 		}
 
 		// Generate unique username
@@ -68,7 +113,7 @@ func (s *Svc) SignUp(ctx context.Context, req SignUpRequest) (UserResponse, erro
 		tx, err := s.store.Begin(ctx)
 		if err != nil {
 			slog.Error("failed to begin transaction in signup", "error", err)
-			return UserResponse{}, &utils.RetryableError{Err: err}
+			return db.User{}, &utils.RetryableError{Err: err}
 		}
 
 		defer func() {
@@ -82,7 +127,7 @@ func (s *Svc) SignUp(ctx context.Context, req SignUpRequest) (UserResponse, erro
 		user, err := qtx.CreateUser(ctx, arg)
 		if err != nil {
 			slog.Error("failed to create user in signup", "error", err)
-			return UserResponse{}, &utils.RetryableError{Err: err}
+			return db.User{}, &utils.RetryableError{Err: err}
 		}
 
 		//TODO: create wallet for the user
@@ -92,151 +137,252 @@ func (s *Svc) SignUp(ctx context.Context, req SignUpRequest) (UserResponse, erro
 			db.WalletTypeEnumMisc,
 		}
 
+		if err := outbox.Record(ctx, qtx, outbox.AggregateUser, user.ID, outbox.EventUserRegistered, outbox.UserRegisteredPayload{
+			UserID:       user.ID,
+			Email:        user.Email,
+			Username:     user.Username,
+			Currency:     currency,
+			RegisteredAt: user.CreatedAt.Time,
+		}); err != nil {
+			slog.Error("failed to record user registered event", "error", err)
+			return db.User{}, &utils.RetryableError{Err: err}
+		}
+
 		for _, value := range walletTypes {
-			_, err := qtx.CreateWallet(ctx, db.CreateWalletParams{
+			wallet, err := qtx.CreateWallet(ctx, db.CreateWalletParams{
 				UserID:     utils.ToPgUUID(user.ID),
 				WalletType: db.WalletTypeEnum(value),
-				Currency:   "NGN",
+				Currency:   currency,
 			})
 
 			if err != nil {
 				slog.Error("could not create wallets for user", "error", err)
-				return UserResponse{}, &utils.RetryableError{Err: err}
+				return db.User{}, &utils.RetryableError{Err: err}
 			}
+
+			if err := outbox.RecordWalletCreated(ctx, qtx, wallet); err != nil {
+				slog.Error("failed to record wallet created event", "error", err)
+				return db.User{}, &utils.RetryableError{Err: err}
+			}
+		}
+
+		code, err = issueOTP(ctx, qtx, user.ID, PurposeEmailVerification)
+		if err != nil {
+			slog.Error("failed to create verification otp in signup", "error", err)
+			return db.User{}, &utils.RetryableError{Err: err}
 		}
 
 		if err := tx.Commit(ctx); err != nil {
 			slog.Error("failed to commit signup transaction", "error", err)
-			return UserResponse{}, &utils.RetryableError{Err: err}
+			return db.User{}, &utils.RetryableError{Err: err}
 		}
 
-		return UserResponse{
-			ID:          user.ID,
-			FullName:    user.FullName,
-			PhoneNumber: user.PhoneNumber,
-			Email:       user.Email,
-			Username:    user.Username,
-			AccountNo:   user.AccountNo,
-			Nationality: user.Nationality,
-			CreatedAt:   user.CreatedAt,
-			UpdatedAt:   user.UpdatedAt,
-		}, nil
+		return user, nil
 	})
+	if err != nil {
+		return UserResponse{}, err
+	}
+
+	s.enqueueOTPEmail(ctx, user, code, PurposeEmailVerification)
+
+	return toUserResponse(user), nil
 }
 
 // login handles the business logic for user login
-func (s *Svc) Login(ctx context.Context, req LoginRequest) (LoginResponse, error) {
+func (s *Svc) Login(ctx context.Context, req LoginRequest, meta SessionMeta) (LoginResponse, error) {
 	// Single timeout for entire operation (all retries + backoff + actual work)
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	return utils.Retry(3, 100, func() (LoginResponse, error) {
+	if err := s.loginGuard.Check(ctx, req.Email, meta.IPAddress); err != nil {
+		return LoginResponse{}, err
+	}
+
+	user, err := utils.Retry(3, 100, func() (db.User, error) {
 		user, err := s.store.Queries().GetUserByEmail(ctx, req.Email)
 		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return db.User{}, ErrInvalidCredentials
+			}
 			slog.Error("failed to get user by email", "error", err)
-			return LoginResponse{}, &utils.RetryableError{Err: err}
-		}
-
-		if err := utils.CheckPassword(req.Password, user.Passwordhash); err != nil {
-			slog.Error("failed to check password", "error", err)
-			return LoginResponse{}, err // Not retryable - password mismatch is application logic
+			return db.User{}, &utils.RetryableError{Err: err}
 		}
+		return user, nil
+	})
+	if err == nil && utils.CheckPassword(req.Password, user.Passwordhash) != nil {
+		err = ErrInvalidCredentials
+	}
+	if errors.Is(err, ErrInvalidCredentials) {
+		// unknown emails count against the address too, so both cases look the same to a caller
+		return LoginResponse{}, s.loginFailed(ctx, req.Email, user, meta)
+	}
+	if err != nil {
+		return LoginResponse{}, err
+	}
+
+	mfaRequired, err := utils.Retry(3, 100, func() (bool, error) {
+		return s.mfaRequired(ctx, user.ID)
+	})
+	if err != nil {
+		return LoginResponse{}, err
+	}
 
-		accessToken, err := utils.GenerateToken(user.ID, user.Username, s.cfg.JWTAccessSecret, "access")
+	if mfaRequired {
+		// the failure counters are only cleared once the second factor is verified too
+		mfaToken, err := utils.GenerateSessionToken(user.ID, user.Username, s.refreshKeys, mfaTokenType, uuid.Nil, uuid.Nil)
 		if err != nil {
 			return LoginResponse{}, err
 		}
+		return LoginResponse{User: toUserResponse(user), MFARequired: true, MFAToken: mfaToken}, nil
+	}
+
+	if err := s.loginGuard.RecordSuccess(ctx, req.Email); err != nil {
+		slog.Error("failed to reset login failures", "user_id", user.ID, "error", err)
+	}
 
-		refreshToken, err := utils.GenerateToken(user.ID, user.Username, s.cfg.JWTRefreshSecret, "refresh")
+	return utils.Retry(3, 100, func() (LoginResponse, error) {
+		accessToken, refreshToken, err := s.startSession(ctx, s.store.Queries(), user, uuid.New(), pgtype.UUID{}, time.Now(), meta)
 		if err != nil {
 			return LoginResponse{}, err
 		}
 
 		return LoginResponse{
-			User: UserResponse{
-				ID:          user.ID,
-				FullName:    user.FullName,
-				PhoneNumber: user.PhoneNumber,
-				Email:       user.Email,
-				Username:    user.Username,
-				AccountNo:   user.AccountNo,
-				Nationality: user.Nationality,
-				CreatedAt:   user.CreatedAt,
-				UpdatedAt:   user.UpdatedAt,
-			},
+			User:         toUserResponse(user),
 			AccessToken:  accessToken,
 			RefreshToken: refreshToken,
 		}, nil
 	})
 }
 
-// refres token endpoint
-func (s *Svc) Refresh(ctx context.Context, req RefreshRequest) (RefreshResponse, error) {
-	// Single timeout for entire operation (all retries + backoff + actual work)
+// VerifyEmail consumes the email verification code sent at sign-up and marks the email as verified.
+func (s *Svc) VerifyEmail(ctx context.Context, req VerifyEmailRequest) (UserResponse, error) {
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	return utils.Retry(3, 100, func() (RefreshResponse, error) {
-		claims, err := utils.VerifyToken(req.RefreshToken, s.cfg.JWTRefreshSecret)
+	return utils.Retry(3, 100, func() (UserResponse, error) {
+		tx, err := s.store.Begin(ctx)
 		if err != nil {
-			slog.Error("could not verify refresh token for user", "error", err)
-			return RefreshResponse{}, err // Not retryable - token verification is deterministic
+			return UserResponse{}, &utils.RetryableError{Err: err}
 		}
 
-		newAccessToken, accessErr := utils.GenerateToken(claims.UserID, claims.Username, s.cfg.JWTAccessSecret, "access")
-		if accessErr != nil {
-			slog.Error("could not generate new access token for refresh service", "error", accessErr)
-			return RefreshResponse{}, accessErr
+		defer func() {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback verify email tx", "error", rbErr)
+			}
+		}()
+
+		qtx := s.store.WithTx(tx)
+
+		user, err := qtx.GetUserByEmail(ctx, req.Email)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return UserResponse{}, ErrOTPInvalid
+			}
+			return UserResponse{}, &utils.RetryableError{Err: err}
 		}
-		newRefreshToken, refreshErr := utils.GenerateToken(claims.UserID, claims.Username, s.cfg.JWTRefreshSecret, "refresh")
-		if refreshErr != nil {
-			slog.Error("could not generate new refresh token for refresh service", "error", refreshErr)
-			return RefreshResponse{}, refreshErr
+
+		if user.EmailVerifiedAt.Valid {
+			return UserResponse{}, ErrEmailAlreadyVerified
 		}
 
-		return RefreshResponse{
-			AccessToken:  newAccessToken,
-			RefreshToken: newRefreshToken,
-		}, nil
+		if otpErr := consumeOTP(ctx, qtx, user.ID, PurposeEmailVerification, req.Code); otpErr != nil {
+			if utils.IsRetryableError(otpErr) {
+				return UserResponse{}, otpErr
+			}
+			// keep the attempt count even though verification failed
+			if err := tx.Commit(ctx); err != nil {
+				return UserResponse{}, &utils.RetryableError{Err: err}
+			}
+			return UserResponse{}, otpErr
+		}
+
+		verified, err := qtx.MarkUserEmailVerified(ctx, user.ID)
+		if err != nil {
+			return UserResponse{}, &utils.RetryableError{Err: err}
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return UserResponse{}, &utils.RetryableError{Err: err}
+		}
+
+		return toUserResponse(verified), nil
 	})
 }
 
-// createOtp handles the creation of otp in the database
-// func (s *Svc) CreateOTP(ctx context.Context, userID uuid.UUID) (OTPResponse, error) {
-// 	otp, err := utils.GenerateOTP()
-// 	if err != nil {
-// 		slog.Error("failed to generate otp", "error", err)
-// 		return OTPResponse{}, err
-// 	}
-
-// 	expiresAt := time.Now().Add(time.Minute * 10)
-// 	payload := db.CreateOTPParams{
-// 		UserID:  userID,
-// 		Code:    otp,
-// 		Purpose: "verification",
-// 		ExpiresAt: pgtype.Timestamptz{
-// 			Time:  expiresAt,
-// 			Valid: true,
-// 		},
-// 		Used: pgtype.Bool{
-// 			Bool:  false,
-// 			Valid: true,
-// 		},
-// 	}
-
-// 	otpRecord, err := s.queries.CreateOTP(ctx, payload)
-// 	if err != nil {
-// 		slog.Error("failed to create otp", "error", err)
-// 		return OTPResponse{}, err
-// 	}
-
-// 	return OTPResponse{
-// 		ID:        otpRecord.ID,
-// 		UserID:    otpRecord.UserID,
-// 		Code:      otpRecord.Code,
-// 		Purpose:   otpRecord.Purpose,
-// 		ExpiresAt: otpRecord.ExpiresAt,
-// 		Used:      otpRecord.Used,
-// 	}, nil
-
-// }
+// ResendOTP replaces the outstanding verification code with a fresh one. Unknown or already
+// verified emails and resends inside the cooldown are accepted silently, so the endpoint cannot
+// be used to probe for accounts.
+func (s *Svc) ResendOTP(ctx context.Context, req ResendOTPRequest) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var user db.User
+	code, err := utils.Retry(3, 100, func() (string, error) {
+		tx, err := s.store.Begin(ctx)
+		if err != nil {
+			return "", &utils.RetryableError{Err: err}
+		}
+
+		defer func() {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback resend otp tx", "error", rbErr)
+			}
+		}()
+
+		qtx := s.store.WithTx(tx)
+
+		user, err = qtx.GetUserByEmail(ctx, req.Email)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return "", ErrUserNotFound
+			}
+			return "", &utils.RetryableError{Err: err}
+		}
+
+		if user.EmailVerifiedAt.Valid {
+			return "", ErrEmailAlreadyVerified
+		}
+
+		latest, err := qtx.GetLatestOTPByPurpose(ctx, db.GetLatestOTPByPurposeParams{UserID: user.ID, Purpose: PurposeEmailVerification})
+		if err == nil && time.Since(latest.CreatedAt.Time) < otpResendAfter {
+			return "", ErrOTPCooldown
+		} else if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return "", &utils.RetryableError{Err: err}
+		}
+
+		code, err := issueOTP(ctx, qtx, user.ID, PurposeEmailVerification)
+		if err != nil {
+			return "", &utils.RetryableError{Err: err}
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return "", &utils.RetryableError{Err: err}
+		}
+		return code, nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) || errors.Is(err, ErrEmailAlreadyVerified) || errors.Is(err, ErrOTPCooldown) {
+			slog.Info("verification code not resent", "reason", err)
+			return nil
+		}
+		return err
+	}
+
+	s.enqueueOTPEmail(ctx, user, code, PurposeEmailVerification)
+	return nil
+}
+
+func toUserResponse(user db.User) UserResponse {
+	return UserResponse{
+		ID:              user.ID,
+		FullName:        user.FullName,
+		PhoneNumber:     user.PhoneNumber,
+		Email:           user.Email,
+		Username:        user.Username,
+		AccountNo:       user.AccountNo,
+		Nationality:     user.Nationality,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
+	}
+}
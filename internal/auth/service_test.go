@@ -1,3 +1,215 @@
 package auth
 
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/luponetn/paycore/internal/config"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/otp"
+	"github.com/luponetn/paycore/internal/store"
+	"github.com/luponetn/paycore/pkg/utils"
+	"github.com/stretchr/testify/require"
+)
+
+const testPassword = "correct-horse-battery"
+
+// fakeEnqueuer records tasks instead of sending them to Redis.
+type fakeEnqueuer struct {
+	mu    sync.Mutex
+	tasks []*asynq.Task
+}
+
+func (e *fakeEnqueuer) EnqueueContext(ctx context.Context, task *asynq.Task, opts ...asynq.Option) (*asynq.TaskInfo, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.tasks = append(e.tasks, task)
+	return &asynq.TaskInfo{}, nil
+}
+
+// lastEmailedCode returns the code (or reset link) in the most recent email task.
+func lastEmailedCode(t *testing.T, svc *Svc) string {
+	t.Helper()
+	e := svc.taskClient.(*fakeEnqueuer)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	require.NotEmpty(t, e.tasks)
+
+	var payload struct {
+		OTP  string `json:"otp"`
+		Code string `json:"code"`
+		Link string `json:"link"`
+	}
+	require.NoError(t, json.Unmarshal(e.tasks[len(e.tasks)-1].Payload(), &payload))
+	for _, code := range []string{payload.OTP, payload.Code, payload.Link} {
+		if code != "" {
+			return code
+		}
+	}
+	t.Fatal("last task carries no code")
+	return ""
+}
+
+// emailsSent counts the email tasks the service has enqueued.
+func emailsSent(svc *Svc) int {
+	e := svc.taskClient.(*fakeEnqueuer)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.tasks)
+}
+
+// wrongCode returns a six-digit code that is not code.
+func wrongCode(code string) string {
+	if code == "000000" {
+		return "111111"
+	}
+	return "000000"
+}
+
+// fakeLoginGuard is an in-memory LoginGuard that locks an account after maxAttempts failures.
+type fakeLoginGuard struct {
+	mu          sync.Mutex
+	maxAttempts int64
+	failures    map[string]int64
+	locked      map[string]bool
+}
+
+func newFakeLoginGuard(maxAttempts int64) *fakeLoginGuard {
+	return &fakeLoginGuard{maxAttempts: maxAttempts, failures: make(map[string]int64), locked: make(map[string]bool)}
+}
+
+func (g *fakeLoginGuard) Check(ctx context.Context, email string, ip string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.locked[email] {
+		return &LoginBlockedError{Err: ErrAccountLocked, RetryAfter: time.Minute}
+	}
+	return nil
+}
+
+func (g *fakeLoginGuard) RecordFailure(ctx context.Context, email string, ip string) (LoginFailure, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.failures[email]++
+	failure := LoginFailure{Failures: g.failures[email]}
+	if failure.Failures == g.maxAttempts {
+		g.locked[email] = true
+		failure.AccountLocked = true
+	}
+	return failure, nil
+}
+
+func (g *fakeLoginGuard) RecordSuccess(ctx context.Context, email string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.failures, email)
+	return nil
+}
+
+func (g *fakeLoginGuard) Locked(ctx context.Context, email string) (bool, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.locked[email], nil
+}
+
+func (g *fakeLoginGuard) Unlock(ctx context.Context, email string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.locked, email)
+	delete(g.failures, email)
+	return nil
+}
+
+// newTestService builds the service with HS256 keys, a fixed MFA key and the given guard.
+func newTestService(f *store.FakeStore, guard LoginGuard) *Svc {
+	cfg := &config.Config{
+		JWTRefreshSecret:     "test-refresh-secret",
+		MFAEncryptionKey:     make([]byte, 32),
+		MFAIssuer:            "Paycore",
+		LoginLockoutDuration: time.Minute,
+	}
+	return NewService(f, &fakeEnqueuer{}, cfg, utils.NewHMACKeySet("test-access-secret"), guard).(*Svc)
+}
+
+// addTestUser stores a verified user who signs in with testPassword.
+func addTestUser(t *testing.T, f *store.FakeStore) db.User {
+	t.Helper()
+	hash, err := utils.HashPassword(testPassword)
+	require.NoError(t, err)
+
+	user := db.User{
+		ID:              uuid.New(),
+		FullName:        "Ada Okafor",
+		Email:           uuid.NewString() + "@example.com",
+		Username:        "ada",
+		Passwordhash:    hash,
+		EmailVerifiedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	}
+	f.AddFakeUser(user)
+	return user
+}
+
+func TestVerifyEmail_CodesExpireAndLimitAttempts(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := newTestService(f, newFakeLoginGuard(5))
+	ctx := context.Background()
+	user := addTestUser(t, f)
+	user.EmailVerifiedAt = pgtype.Timestamptz{}
+	f.AddFakeUser(user)
+
+	// Unknown emails look the same as known ones.
+	require.NoError(t, svc.ResendOTP(ctx, ResendOTPRequest{Email: "nobody@example.com"}))
+
+	require.NoError(t, svc.ResendOTP(ctx, ResendOTPRequest{Email: user.Email}))
+	code := lastEmailedCode(t, svc)
+
+	// A resend inside the cooldown looks like success but sends nothing.
+	sent := emailsSent(svc)
+	require.NoError(t, svc.ResendOTP(ctx, ResendOTPRequest{Email: user.Email}))
+	require.Equal(t, sent, emailsSent(svc))
+
+	// Every wrong guess counts; the last allowed one burns the code.
+	for i := 1; i < otp.MaxAttempts; i++ {
+		_, err := svc.VerifyEmail(ctx, VerifyEmailRequest{Email: user.Email, Code: wrongCode(code)})
+		require.ErrorIs(t, err, ErrOTPInvalid)
+	}
+	_, err := svc.VerifyEmail(ctx, VerifyEmailRequest{Email: user.Email, Code: wrongCode(code)})
+	require.ErrorIs(t, err, ErrOTPAttemptsExceeded)
+	_, err = svc.VerifyEmail(ctx, VerifyEmailRequest{Email: user.Email, Code: code})
+	require.ErrorIs(t, err, ErrOTPInvalid)
+
+	// A resent code replaces the old one and stops working once it expires.
+	f.AgeFakeOTPs(user.ID, otpResendAfter)
+	require.NoError(t, svc.ResendOTP(ctx, ResendOTPRequest{Email: user.Email}))
+	code = lastEmailedCode(t, svc)
+	f.AgeFakeOTPs(user.ID, otpTTL)
+	_, err = svc.VerifyEmail(ctx, VerifyEmailRequest{Email: user.Email, Code: code})
+	require.ErrorIs(t, err, ErrOTPExpired)
+
+	require.NoError(t, svc.ResendOTP(ctx, ResendOTPRequest{Email: user.Email}))
+	stale := code
+	code = lastEmailedCode(t, svc)
+	if stale != code {
+		_, err = svc.VerifyEmail(ctx, VerifyEmailRequest{Email: user.Email, Code: stale})
+		require.ErrorIs(t, err, ErrOTPInvalid)
+	}
+
+	verified, err := svc.VerifyEmail(ctx, VerifyEmailRequest{Email: user.Email, Code: code})
+	require.NoError(t, err)
+	require.True(t, verified.EmailVerifiedAt.Valid)
+
+	_, err = svc.VerifyEmail(ctx, VerifyEmailRequest{Email: user.Email, Code: code})
+	require.ErrorIs(t, err, ErrEmailAlreadyVerified)
+
+	// So does a resend to a verified email.
+	f.AgeFakeOTPs(user.ID, otpResendAfter)
+	sent = emailsSent(svc)
+	require.NoError(t, svc.ResendOTP(ctx, ResendOTPRequest{Email: user.Email}))
+	require.Equal(t, sent, emailsSent(svc))
+}
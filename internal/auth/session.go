@@ -0,0 +1,266 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/tasks"
+	"github.com/luponetn/paycore/pkg/utils"
+)
+
+const refreshTokenTTL = 7 * 24 * time.Hour
+
+// startSession persists a refresh session and issues the token pair for it. Every refresh
+// token gets its own row (its jti); rotations of one login share familyID, which is also
+// the "sid" carried by access tokens.
+func (s *Svc) startSession(ctx context.Context, qtx db.Querier, user db.User, familyID uuid.UUID, parentID pgtype.UUID, signedInAt time.Time, meta SessionMeta) (string, string, error) {
+	tokenID := uuid.New()
+
+	_, err := qtx.CreateRefreshSession(ctx, db.CreateRefreshSessionParams{
+		ID:         tokenID,
+		UserID:     user.ID,
+		FamilyID:   familyID,
+		ParentID:   parentID,
+		Device:     meta.Device,
+		IpAddress:  meta.IPAddress,
+		UserAgent:  meta.UserAgent,
+		SignedInAt: pgtype.Timestamptz{Time: signedInAt, Valid: true},
+		ExpiresAt:  pgtype.Timestamptz{Time: time.Now().Add(refreshTokenTTL), Valid: true},
+	})
+	if err != nil {
+		return "", "", &utils.RetryableError{Err: err}
+	}
+
+	accessToken, err := utils.GenerateSessionToken(user.ID, user.Username, s.accessKeys, "access", familyID, uuid.Nil)
+	if err != nil {
+		return "", "", err
+	}
+
+	refreshToken, err := utils.GenerateSessionToken(user.ID, user.Username, s.refreshKeys, "refresh", familyID, tokenID)
+	if err != nil {
+		return "", "", err
+	}
+
+	return accessToken, refreshToken, nil
+}
+
+// Refresh rotates a refresh token: the presented token is retired and a new pair is issued in
+// the same family. Presenting a token that was already rotated means it leaked, so the whole
+// family is revoked and the owner is alerted.
+func (s *Svc) Refresh(ctx context.Context, req RefreshRequest, meta SessionMeta) (RefreshResponse, error) {
+	// Single timeout for entire operation (all retries + backoff + actual work)
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	claims, err := utils.VerifyTokenWithKeys(req.RefreshToken, s.refreshKeys)
+	if err != nil {
+		slog.Error("could not verify refresh token for user", "error", err)
+		return RefreshResponse{}, ErrInvalidRefreshToken
+	}
+
+	tokenID, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return RefreshResponse{}, ErrInvalidRefreshToken
+	}
+
+	var reusedBy db.User
+	resp, err := utils.Retry(3, 100, func() (RefreshResponse, error) {
+		tx, err := s.store.Begin(ctx)
+		if err != nil {
+			return RefreshResponse{}, &utils.RetryableError{Err: err}
+		}
+
+		defer func() {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback refresh tx", "error", rbErr)
+			}
+		}()
+
+		qtx := s.store.WithTx(tx)
+
+		session, err := qtx.GetRefreshSessionForUpdate(ctx, tokenID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return RefreshResponse{}, ErrInvalidRefreshToken
+			}
+			return RefreshResponse{}, &utils.RetryableError{Err: err}
+		}
+
+		if session.UserID != claims.UserID {
+			return RefreshResponse{}, ErrInvalidRefreshToken
+		}
+
+		if session.RevokedAt.Valid {
+			return RefreshResponse{}, ErrSessionRevoked
+		}
+
+		user, err := qtx.GetUserByID(ctx, session.UserID)
+		if err != nil {
+			return RefreshResponse{}, &utils.RetryableError{Err: err}
+		}
+
+		if session.RotatedAt.Valid {
+			if err := qtx.RevokeRefreshSessionFamily(ctx, db.RevokeRefreshSessionFamilyParams{
+				FamilyID:      session.FamilyID,
+				RevokedReason: pgtype.Text{String: "refresh token reuse", Valid: true},
+			}); err != nil {
+				return RefreshResponse{}, &utils.RetryableError{Err: err}
+			}
+			if err := tx.Commit(ctx); err != nil {
+				return RefreshResponse{}, &utils.RetryableError{Err: err}
+			}
+			reusedBy = user
+			return RefreshResponse{}, ErrRefreshTokenReused
+		}
+
+		if err := qtx.RotateRefreshSession(ctx, session.ID); err != nil {
+			return RefreshResponse{}, &utils.RetryableError{Err: err}
+		}
+
+		if meta.Device == "" {
+			meta.Device = session.Device
+		}
+
+		accessToken, refreshToken, err := s.startSession(ctx, qtx, user, session.FamilyID, utils.ToPgUUID(session.ID), session.SignedInAt.Time, meta)
+		if err != nil {
+			return RefreshResponse{}, err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return RefreshResponse{}, &utils.RetryableError{Err: err}
+		}
+
+		return RefreshResponse{
+			AccessToken:  accessToken,
+			RefreshToken: refreshToken,
+		}, nil
+	})
+
+	if errors.Is(err, ErrRefreshTokenReused) {
+		slog.Warn("refresh token reuse detected, session family revoked", "user_id", reusedBy.ID, "ip", meta.IPAddress)
+		s.enqueueSecurityAlert(ctx, reusedBy, "A sign-in token for your account was reused, so we signed that session out on every device.", meta)
+	}
+	return resp, err
+}
+
+// Logout revokes the session the access token belongs to.
+func (s *Svc) Logout(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if sessionID == uuid.Nil {
+		return ErrInvalidSession
+	}
+
+	// sid comes from a token we signed together with userID, so it always belongs to the caller
+	_, err := utils.Retry(3, 100, func() (struct{}, error) {
+		if err := s.store.Queries().RevokeRefreshSessionFamily(ctx, db.RevokeRefreshSessionFamilyParams{
+			FamilyID:      sessionID,
+			RevokedReason: pgtype.Text{String: "logout", Valid: true},
+		}); err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+		return struct{}{}, nil
+	})
+	return err
+}
+
+// LogoutAll revokes every session of the user, on every device.
+func (s *Svc) LogoutAll(ctx context.Context, userID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := utils.Retry(3, 100, func() (struct{}, error) {
+		if err := s.store.Queries().RevokeUserRefreshSessions(ctx, db.RevokeUserRefreshSessionsParams{
+			UserID:        userID,
+			RevokedReason: pgtype.Text{String: "logout all", Valid: true},
+		}); err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+		return struct{}{}, nil
+	})
+	return err
+}
+
+// IsSessionActive backs the auth middleware: an access token only works while its session has
+// not been signed out or revoked, and only if it was issued after the last password change, so
+// logout and password resets take effect before the token expires.
+func (s *Svc) IsSessionActive(ctx context.Context, claims *utils.MyClaims) (bool, error) {
+	if claims.IssuedAt == nil {
+		return false, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	// iat has whole seconds; the query truncates password_changed_at to match, so a sign-in in
+	// the same second as a password change still counts as after it
+	return s.store.Queries().IsSessionFamilyActive(ctx, db.IsSessionFamilyActiveParams{
+		FamilyID: claims.SessionID,
+		UserID:   claims.UserID,
+		IssuedAt: pgtype.Timestamptz{Time: claims.IssuedAt.Time, Valid: true},
+	})
+}
+
+// ListSessions returns the user's signed-in sessions, one per token family.
+func (s *Svc) ListSessions(ctx context.Context, userID uuid.UUID, currentSessionID uuid.UUID) ([]SessionResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 8*time.Second)
+	defer cancel()
+
+	sessions, err := utils.Retry(3, 100, func() ([]db.RefreshSession, error) {
+		sessions, err := s.store.Queries().GetActiveRefreshSessionsByUserId(ctx, userID)
+		if err != nil {
+			return nil, &utils.RetryableError{Err: err}
+		}
+		return sessions, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		resp = append(resp, SessionResponse{
+			ID:         session.FamilyID,
+			Device:     session.Device,
+			IPAddress:  session.IpAddress,
+			UserAgent:  session.UserAgent,
+			SignedInAt: session.SignedInAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.FamilyID == currentSessionID,
+		})
+	}
+	return resp, nil
+}
+
+func (s *Svc) enqueueSecurityAlert(ctx context.Context, user db.User, event string, meta SessionMeta) {
+	task, err := tasks.NewSendSecurityAlertEmailTask(tasks.SendSecurityAlertEmailPayload{
+		UserID:     user.ID.String(),
+		Email:      user.Email,
+		FullName:   user.FullName,
+		Event:      event,
+		IPAddress:  meta.IPAddress,
+		UserAgent:  meta.UserAgent,
+		OccurredAt: time.Now(),
+	})
+	if err != nil {
+		return
+	}
+
+	if _, err := s.taskClient.EnqueueContext(ctx, task, asynq.Queue(tasks.QueueCritical), asynq.MaxRetry(5)); err != nil {
+		slog.Error("failed to enqueue security alert", "user_id", user.ID, "error", err)
+	}
+}
+
+// JWKS returns the public keys other services use to verify access tokens.
+func (s *Svc) JWKS() utils.JWKS {
+	return s.accessKeys.JWKS()
+}
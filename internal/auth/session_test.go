@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/luponetn/paycore/internal/store"
+	"github.com/luponetn/paycore/pkg/utils"
+	"github.com/stretchr/testify/require"
+)
+
+// requireSessionActive checks an access token the way the auth middleware does.
+func requireSessionActive(t *testing.T, svc *Svc, accessToken string, want bool) {
+	t.Helper()
+	claims, err := utils.VerifyTokenWithKeys(accessToken, svc.accessKeys)
+	require.NoError(t, err)
+	active, err := svc.IsSessionActive(context.Background(), claims)
+	require.NoError(t, err)
+	require.Equal(t, want, active)
+}
+
+func TestRefresh_RotatesAndRevokesFamilyOnReuse(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := newTestService(f, newFakeLoginGuard(5))
+	ctx := context.Background()
+	user := addTestUser(t, f)
+
+	login, err := svc.Login(ctx, LoginRequest{Email: user.Email, Password: testPassword}, SessionMeta{Device: "phone"})
+	require.NoError(t, err)
+	other, err := svc.Login(ctx, LoginRequest{Email: user.Email, Password: testPassword}, SessionMeta{Device: "laptop"})
+	require.NoError(t, err)
+
+	rotated, err := svc.Refresh(ctx, RefreshRequest{RefreshToken: login.RefreshToken}, SessionMeta{})
+	require.NoError(t, err)
+	require.NotEqual(t, login.RefreshToken, rotated.RefreshToken)
+
+	first, err := utils.VerifyTokenWithKeys(login.AccessToken, svc.accessKeys)
+	require.NoError(t, err)
+	second, err := utils.VerifyTokenWithKeys(rotated.AccessToken, svc.accessKeys)
+	require.NoError(t, err)
+	require.Equal(t, first.SessionID, second.SessionID)
+	requireSessionActive(t, svc, rotated.AccessToken, true)
+
+	sessions, err := svc.ListSessions(ctx, user.ID, second.SessionID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+
+	// Presenting the retired token again signs the whole family out.
+	_, err = svc.Refresh(ctx, RefreshRequest{RefreshToken: login.RefreshToken}, SessionMeta{})
+	require.ErrorIs(t, err, ErrRefreshTokenReused)
+
+	_, err = svc.Refresh(ctx, RefreshRequest{RefreshToken: rotated.RefreshToken}, SessionMeta{})
+	require.ErrorIs(t, err, ErrSessionRevoked)
+	requireSessionActive(t, svc, login.AccessToken, false)
+	requireSessionActive(t, svc, rotated.AccessToken, false)
+
+	// Other sign-ins are left alone.
+	requireSessionActive(t, svc, other.AccessToken, true)
+
+	// An access token cannot stand in for a refresh token.
+	_, err = svc.Refresh(ctx, RefreshRequest{RefreshToken: other.AccessToken}, SessionMeta{})
+	require.ErrorIs(t, err, ErrInvalidRefreshToken)
+}
+
+func TestLogout_EndsAccessTokens(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := newTestService(f, newFakeLoginGuard(5))
+	ctx := context.Background()
+	user := addTestUser(t, f)
+
+	phone, err := svc.Login(ctx, LoginRequest{Email: user.Email, Password: testPassword}, SessionMeta{})
+	require.NoError(t, err)
+	laptop, err := svc.Login(ctx, LoginRequest{Email: user.Email, Password: testPassword}, SessionMeta{})
+	require.NoError(t, err)
+
+	claims, err := utils.VerifyTokenWithKeys(phone.AccessToken, svc.accessKeys)
+	require.NoError(t, err)
+	require.NoError(t, svc.Logout(ctx, user.ID, claims.SessionID))
+	requireSessionActive(t, svc, phone.AccessToken, false)
+	requireSessionActive(t, svc, laptop.AccessToken, true)
+
+	require.NoError(t, svc.LogoutAll(ctx, user.ID))
+	requireSessionActive(t, svc, laptop.AccessToken, false)
+	_, err = svc.Refresh(ctx, RefreshRequest{RefreshToken: laptop.RefreshToken}, SessionMeta{})
+	require.ErrorIs(t, err, ErrSessionRevoked)
+}
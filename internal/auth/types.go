@@ -12,29 +12,54 @@ type SignUpRequest struct {
 	Password    string `json:"password" binding:"required,min=8"`
 	Nationality string `json:"nationality" binding:"required"`
 	CountryCode string `json:"country_code" binding:"required"`
+	Currency    string `json:"currency" binding:"omitempty,len=3"` // currency of the first wallets; defaults to DEFAULT_CURRENCY
 }
 
 type LoginRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=8"`
+	Device   string `json:"device" binding:"max=100"`
+}
+
+// SessionMeta describes the client a session was created or refreshed from.
+type SessionMeta struct {
+	Device    string
+	IPAddress string
+	UserAgent string
+}
+
+type SessionResponse struct {
+	ID         uuid.UUID          `json:"id"`
+	Device     string             `json:"device"`
+	IPAddress  string             `json:"ip_address"`
+	UserAgent  string             `json:"user_agent"`
+	SignedInAt pgtype.Timestamptz `json:"signed_in_at"`
+	LastUsedAt pgtype.Timestamptz `json:"last_used_at"`
+	ExpiresAt  pgtype.Timestamptz `json:"expires_at"`
+	Current    bool               `json:"current"`
 }
 
 type UserResponse struct {
-	ID          uuid.UUID          `json:"id"`
-	FullName    string             `json:"full_name"`
-	PhoneNumber string             `json:"phone_number"`
-	Email       string             `json:"email"`
-	Username    string             `json:"username"`
-	AccountNo   string             `json:"account_no"`
-	Nationality string             `json:"nationality"`
-	CreatedAt   pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+	ID              uuid.UUID          `json:"id"`
+	FullName        string             `json:"full_name"`
+	PhoneNumber     string             `json:"phone_number"`
+	Email           string             `json:"email"`
+	Username        string             `json:"username"`
+	AccountNo       string             `json:"account_no"`
+	Nationality     string             `json:"nationality"`
+	EmailVerifiedAt pgtype.Timestamptz `json:"email_verified_at"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
 }
 
+// LoginResponse carries either the token pair or, for accounts with a second factor,
+// MFARequired and the challenge token to send to /auth/mfa/verify.
 type LoginResponse struct {
 	User         UserResponse `json:"user"`
-	AccessToken  string       `json:"access_token"`
-	RefreshToken string       `json:"refresh_token"`
+	AccessToken  string       `json:"access_token,omitempty"`
+	RefreshToken string       `json:"refresh_token,omitempty"`
+	MFARequired  bool         `json:"mfa_required,omitempty"`
+	MFAToken     string       `json:"mfa_token,omitempty"`
 }
 
 type CreateOTPRequest struct {
@@ -51,10 +76,80 @@ type OTPResponse struct {
 }
 
 type RefreshRequest struct {
-	RefreshToken string `json:"refresh_token"`
+	RefreshToken string `json:"refresh_token" binding:"required"`
+	Device       string `json:"device" binding:"max=100"`
 }
 
 type RefreshResponse struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
 }
+
+type VerifyEmailRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Code  string `json:"code" binding:"required,len=6,numeric"`
+}
+
+type ResendOTPRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest takes either Token (from the reset link) or Email and Code.
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	Email       string `json:"email" binding:"omitempty,email"`
+	Code        string `json:"code" binding:"omitempty,len=6,numeric"`
+	NewPassword string `json:"new_password" binding:"required,min=8"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=8"`
+}
+
+type RequestUnlockRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type UnlockAccountRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Code  string `json:"code" binding:"required,len=6,numeric"`
+}
+
+// VerifyMFARequest takes exactly one of Code (from the authenticator app) or RecoveryCode.
+type VerifyMFARequest struct {
+	MFAToken     string `json:"mfa_token" binding:"required"`
+	Code         string `json:"code" binding:"omitempty,len=6,numeric"`
+	RecoveryCode string `json:"recovery_code" binding:"omitempty,max=20"`
+	Device       string `json:"device" binding:"max=100"`
+}
+
+type ConfirmTOTPRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+// MFAReauthRequest re-authenticates sensitive MFA changes with the password and a second factor.
+type MFAReauthRequest struct {
+	Password     string `json:"password" binding:"required"`
+	Code         string `json:"code" binding:"omitempty,len=6,numeric"`
+	RecoveryCode string `json:"recovery_code" binding:"omitempty,max=20"`
+}
+
+type TOTPEnrollmentResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type MFAStatusResponse struct {
+	TOTPEnabled            bool               `json:"totp_enabled"`
+	TOTPEnabledAt          pgtype.Timestamptz `json:"totp_enabled_at"`
+	RecoveryCodesRemaining int64              `json:"recovery_codes_remaining"`
+}
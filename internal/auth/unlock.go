@@ -0,0 +1,182 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/pkg/utils"
+)
+
+const PurposeAccountUnlock = "account_unlock"
+
+// loginFailed records a bad email or password and returns the error for the caller: the
+// generic ErrInvalidCredentials, or a *LoginBlockedError when this attempt locked the account.
+// user is the zero value when the email is unknown.
+func (s *Svc) loginFailed(ctx context.Context, email string, user db.User, meta SessionMeta) error {
+	failure, err := s.loginGuard.RecordFailure(ctx, email, meta.IPAddress)
+	if err != nil {
+		slog.Error("failed to record login failure", "error", err)
+		return ErrInvalidCredentials
+	}
+
+	var userID pgtype.UUID
+	if user.ID != uuid.Nil {
+		userID = utils.ToPgUUID(user.ID)
+	}
+
+	if failure.IPBlocked {
+		s.recordAudit(ctx, userID, AuditLoginIPBlocked, meta, map[string]any{"email": normalizeEmail(email)})
+	}
+
+	if !failure.AccountLocked {
+		return ErrInvalidCredentials
+	}
+
+	s.recordAudit(ctx, userID, AuditAccountLocked, meta, map[string]any{
+		"email":    normalizeEmail(email),
+		"failures": failure.Failures,
+		"duration": s.cfg.LoginLockoutDuration.String(),
+	})
+
+	if userID.Valid {
+		s.enqueueSecurityAlert(ctx, user, "Your account was locked after too many failed sign-in attempts. Use the code we sent you to unlock it, or wait for the lock to expire.", meta)
+		if err := s.sendUnlockOTP(ctx, user); err != nil {
+			slog.Error("failed to issue unlock otp", "user_id", user.ID, "error", err)
+		}
+	}
+
+	return &LoginBlockedError{Err: ErrAccountLocked, RetryAfter: s.cfg.LoginLockoutDuration}
+}
+
+// RequestUnlock emails a fresh unlock code for a locked account. Unknown or unlocked accounts
+// succeed silently so the endpoint does not reveal either.
+func (s *Svc) RequestUnlock(ctx context.Context, req RequestUnlockRequest) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	locked, err := s.loginGuard.Locked(ctx, req.Email)
+	if err != nil {
+		return err
+	}
+	if !locked {
+		return nil
+	}
+
+	user, err := utils.Retry(3, 100, func() (db.User, error) {
+		user, err := s.store.Queries().GetUserByEmail(ctx, req.Email)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return db.User{}, ErrUserNotFound
+			}
+			return db.User{}, &utils.RetryableError{Err: err}
+		}
+
+		latest, err := s.store.Queries().GetLatestOTPByPurpose(ctx, db.GetLatestOTPByPurposeParams{UserID: user.ID, Purpose: PurposeAccountUnlock})
+		if err == nil && time.Since(latest.CreatedAt.Time) < otpResendAfter {
+			return db.User{}, ErrOTPCooldown
+		} else if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return db.User{}, &utils.RetryableError{Err: err}
+		}
+		return user, nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	return s.sendUnlockOTP(ctx, user)
+}
+
+// UnlockAccount lifts a lockout early once the user proves they own the email address.
+func (s *Svc) UnlockAccount(ctx context.Context, req UnlockAccountRequest, meta SessionMeta) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	user, err := utils.Retry(3, 100, func() (db.User, error) {
+		tx, err := s.store.Begin(ctx)
+		if err != nil {
+			return db.User{}, &utils.RetryableError{Err: err}
+		}
+
+		defer func() {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback unlock account tx", "error", rbErr)
+			}
+		}()
+
+		qtx := s.store.WithTx(tx)
+
+		user, err := qtx.GetUserByEmail(ctx, req.Email)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return db.User{}, ErrOTPInvalid
+			}
+			return db.User{}, &utils.RetryableError{Err: err}
+		}
+
+		if otpErr := consumeOTP(ctx, qtx, user.ID, PurposeAccountUnlock, req.Code); otpErr != nil {
+			if utils.IsRetryableError(otpErr) {
+				return db.User{}, otpErr
+			}
+			// keep the attempt count even though the unlock failed
+			if err := tx.Commit(ctx); err != nil {
+				return db.User{}, &utils.RetryableError{Err: err}
+			}
+			return db.User{}, otpErr
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return db.User{}, &utils.RetryableError{Err: err}
+		}
+		return user, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.loginGuard.Unlock(ctx, user.Email); err != nil {
+		return err
+	}
+
+	s.recordAudit(ctx, utils.ToPgUUID(user.ID), AuditAccountUnlocked, meta, map[string]any{"method": "otp"})
+	return nil
+}
+
+func (s *Svc) sendUnlockOTP(ctx context.Context, user db.User) error {
+	code, err := utils.Retry(3, 100, func() (string, error) {
+		tx, err := s.store.Begin(ctx)
+		if err != nil {
+			return "", &utils.RetryableError{Err: err}
+		}
+
+		defer func() {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback unlock otp tx", "error", rbErr)
+			}
+		}()
+
+		code, err := issueOTP(ctx, s.store.WithTx(tx), user.ID, PurposeAccountUnlock)
+		if err != nil {
+			return "", &utils.RetryableError{Err: err}
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return "", &utils.RetryableError{Err: err}
+		}
+		return code, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.enqueueOTPEmail(ctx, user, code, PurposeAccountUnlock)
+	return nil
+}
@@ -1,10 +1,16 @@
 package config
 
 import (
+	"encoding/base64"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/luponetn/paycore/pkg/money"
+	"github.com/shopspring/decimal"
 )
 
 type Config struct {
@@ -13,6 +19,112 @@ type Config struct {
 	JWTAccessSecret  string
 	JWTRefreshSecret string
 	RedisAddr        string
+
+	// JWTAlgorithm selects how access tokens are signed: HS256 with JWTAccessSecret, or
+	// RS256/EdDSA with the <kid>.pem keys in JWTKeysDir, signing with JWTActiveKID.
+	JWTAlgorithm string
+	JWTKeysDir   string
+	JWTActiveKID string
+
+	// PasswordResetMode is "otp" (emailed code) or "link" (emailed signed link to PasswordResetURL).
+	PasswordResetMode string
+	PasswordResetURL  string
+
+	// MFAEncryptionKey (32 bytes, base64) encrypts TOTP secrets at rest; MFAIssuer is the
+	// account label shown in authenticator apps.
+	MFAEncryptionKey []byte
+	MFAIssuer        string
+
+	// Login throttling. An account is locked for LoginLockoutDuration after LoginMaxAttempts
+	// failures within LoginAttemptWindow; an IP is blocked for the rest of the window after
+	// LoginIPMaxAttempts failures.
+	LoginMaxAttempts     int
+	LoginIPMaxAttempts   int
+	LoginAttemptWindow   time.Duration
+	LoginLockoutDuration time.Duration
+
+	// Step-up rules: transfers matching any enabled rule need an emailed OTP on top of the PIN.
+	// A zero threshold or window disables that rule.
+	StepUpAmountThreshold decimal.Decimal
+	StepUpNewBeneficiary  bool
+	StepUpNewDeviceWindow time.Duration
+
+	// Fixed deposits. FixedDepositRates maps each offered tenor in days to its annual rate;
+	// FixedDepositBreakPenalty is the share of accrued interest forfeited on an early break.
+	FixedDepositRates        map[int32]decimal.Decimal
+	FixedDepositBreakPenalty decimal.Decimal
+
+	// Savings interest. Each tier's rate applies to the slice of the balance from its floor up
+	// to the next tier's floor; SavingsInterestDayCount is "ACT/365" or "30/360".
+	SavingsInterestTiers    []InterestTier
+	SavingsInterestDayCount string
+
+	// Currencies. Wallets may be opened in any of SupportedCurrencies; SignUp opens them in
+	// DefaultCurrency unless the user asks for another.
+	SupportedCurrencies []string
+	DefaultCurrency     string
+
+	// FX. Stored rates are mid-market; quotes apply FXSpread against the user and hold for
+	// FXQuoteTTL, and a rate older than FXRateMaxAge is not quoted. FXRatesFile, when set, is
+	// loaded into the rates table at startup.
+	FXRatesFile  string
+	FXSpread     decimal.Decimal
+	FXQuoteTTL   time.Duration
+	FXRateMaxAge time.Duration
+
+	// Webhooks. A delivery is attempted up to WebhookMaxAttempts times, backing off from
+	// WebhookRetryBase; an endpoint is disabled after WebhookDisableAfter deliveries in a row
+	// fail. A rotated secret keeps signing for WebhookSecretOverlap. Plain http URLs are only
+	// accepted with WebhookAllowHTTP, and URLs on loopback, private or link-local addresses only
+	// with WebhookAllowPrivateNetworks; both are meant for local development.
+	WebhookTimeout              time.Duration
+	WebhookMaxAttempts          int
+	WebhookRetryBase            time.Duration
+	WebhookDisableAfter         int
+	WebhookSecretOverlap        time.Duration
+	WebhookAllowHTTP            bool
+	WebhookAllowPrivateNetworks bool
+
+	// Funding. FundingProvider issues virtual accounts and sends payment callbacks, signed with
+	// FundingCallbackSecret; callbacks older than FundingCallbackTolerance are rejected. The
+	// "simulator" provider posts its callbacks to FundingSimulatorCallbackURL.
+	FundingProvider             string
+	FundingCallbackSecret       string
+	FundingCallbackTolerance    time.Duration
+	FundingSimulatorCallbackURL string
+
+	// Payouts. PayoutProvider sends withdrawals to bank accounts; a payout it has accepted is
+	// polled for its outcome every PayoutPollInterval, backing off as checks go unanswered. The
+	// "simulator" bank settles after PayoutSimulatorLatency and fails PayoutSimulatorFailureRate
+	// of payouts.
+	PayoutProvider             string
+	PayoutPollInterval         time.Duration
+	PayoutSimulatorLatency     time.Duration
+	PayoutSimulatorFailureRate float64
+
+	// AdminAPIKey guards the operator endpoints (sent as X-Admin-Key); empty disables them.
+	AdminAPIKey string
+
+	// Worker settings; all optional.
+	WorkerConcurrency     int
+	WorkerQueues          map[string]int
+	WorkerStrictPriority  bool
+	WorkerShutdownTimeout time.Duration
+
+	// Mail settings; MailDriver is "file" (Maildir sink) or "smtp".
+	MailDriver   string
+	MailFrom     string
+	MailSinkDir  string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+}
+
+// InterestTier is one band of a tiered savings rate table.
+type InterestTier struct {
+	Floor decimal.Decimal
+	Rate  decimal.Decimal
 }
 
 func LoadConfig() (*Config, error) {
@@ -31,9 +143,25 @@ func LoadConfig() (*Config, error) {
 		return nil, err
 	}
 
-	cfg.JWTAccessSecret, err = getEnv("JWT_ACCESS_SECRET")
-	if err != nil {
-		return nil, err
+	cfg.JWTAlgorithm = getEnvDefault("JWT_ALGORITHM", "HS256")
+	switch cfg.JWTAlgorithm {
+	case "HS256":
+		cfg.JWTAccessSecret, err = getEnv("JWT_ACCESS_SECRET")
+		if err != nil {
+			return nil, err
+		}
+	case "RS256", "EdDSA":
+		cfg.JWTKeysDir, err = getEnv("JWT_KEYS_DIR")
+		if err != nil {
+			return nil, err
+		}
+
+		cfg.JWTActiveKID, err = getEnv("JWT_ACTIVE_KID")
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported JWT_ALGORITHM %q", cfg.JWTAlgorithm)
 	}
 
 	cfg.JWTRefreshSecret, err = getEnv("JWT_REFRESH_SECRET")
@@ -46,9 +174,236 @@ func LoadConfig() (*Config, error) {
 		return nil, err
 	}
 
+	cfg.PasswordResetMode = getEnvDefault("PASSWORD_RESET_MODE", "otp")
+	switch cfg.PasswordResetMode {
+	case "otp":
+	case "link":
+		cfg.PasswordResetURL, err = getEnv("PASSWORD_RESET_URL")
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported PASSWORD_RESET_MODE %q", cfg.PasswordResetMode)
+	}
+
+	mfaKey, err := getEnv("MFA_ENCRYPTION_KEY")
+	if err != nil {
+		return nil, err
+	}
+	cfg.MFAEncryptionKey, err = base64.StdEncoding.DecodeString(mfaKey)
+	if err != nil || len(cfg.MFAEncryptionKey) != 32 {
+		return nil, fmt.Errorf("MFA_ENCRYPTION_KEY must be 32 bytes, base64 encoded")
+	}
+	cfg.MFAIssuer = getEnvDefault("MFA_ISSUER", "Paycore")
+
+	cfg.LoginMaxAttempts, err = strconv.Atoi(getEnvDefault("LOGIN_MAX_ATTEMPTS", "5"))
+	if err != nil || cfg.LoginMaxAttempts < 1 {
+		return nil, fmt.Errorf("invalid LOGIN_MAX_ATTEMPTS")
+	}
+
+	cfg.LoginIPMaxAttempts, err = strconv.Atoi(getEnvDefault("LOGIN_IP_MAX_ATTEMPTS", "50"))
+	if err != nil || cfg.LoginIPMaxAttempts < 1 {
+		return nil, fmt.Errorf("invalid LOGIN_IP_MAX_ATTEMPTS")
+	}
+
+	cfg.LoginAttemptWindow, err = time.ParseDuration(getEnvDefault("LOGIN_ATTEMPT_WINDOW", "15m"))
+	if err != nil || cfg.LoginAttemptWindow <= 0 {
+		return nil, fmt.Errorf("invalid LOGIN_ATTEMPT_WINDOW")
+	}
+
+	cfg.LoginLockoutDuration, err = time.ParseDuration(getEnvDefault("LOGIN_LOCKOUT_DURATION", "30m"))
+	if err != nil || cfg.LoginLockoutDuration <= 0 {
+		return nil, fmt.Errorf("invalid LOGIN_LOCKOUT_DURATION")
+	}
+
+	cfg.StepUpAmountThreshold, err = decimal.NewFromString(getEnvDefault("STEPUP_AMOUNT_THRESHOLD", "100000"))
+	if err != nil || cfg.StepUpAmountThreshold.IsNegative() {
+		return nil, fmt.Errorf("invalid STEPUP_AMOUNT_THRESHOLD")
+	}
+
+	cfg.StepUpNewBeneficiary, err = strconv.ParseBool(getEnvDefault("STEPUP_NEW_BENEFICIARY", "true"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid STEPUP_NEW_BENEFICIARY")
+	}
+
+	cfg.StepUpNewDeviceWindow, err = time.ParseDuration(getEnvDefault("STEPUP_NEW_DEVICE_WINDOW", "24h"))
+	if err != nil || cfg.StepUpNewDeviceWindow < 0 {
+		return nil, fmt.Errorf("invalid STEPUP_NEW_DEVICE_WINDOW")
+	}
+
+	cfg.FixedDepositRates, err = parseDepositRates(getEnvDefault("FIXED_DEPOSIT_RATES", "30=0.08,90=0.10,180=0.12,365=0.14"))
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.FixedDepositBreakPenalty, err = decimal.NewFromString(getEnvDefault("FIXED_DEPOSIT_BREAK_PENALTY", "0.5"))
+	if err != nil || cfg.FixedDepositBreakPenalty.IsNegative() || cfg.FixedDepositBreakPenalty.GreaterThan(decimal.NewFromInt(1)) {
+		return nil, fmt.Errorf("invalid FIXED_DEPOSIT_BREAK_PENALTY")
+	}
+
+	cfg.SavingsInterestTiers, err = parseInterestTiers(getEnvDefault("SAVINGS_INTEREST_TIERS", "0=0.03,100000=0.05,1000000=0.07"))
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.SavingsInterestDayCount = getEnvDefault("SAVINGS_INTEREST_DAY_COUNT", "ACT/365")
+	if cfg.SavingsInterestDayCount != "ACT/365" && cfg.SavingsInterestDayCount != "30/360" {
+		return nil, fmt.Errorf("unsupported SAVINGS_INTEREST_DAY_COUNT %q", cfg.SavingsInterestDayCount)
+	}
+
+	cfg.SupportedCurrencies, err = parseCurrencies(getEnvDefault("SUPPORTED_CURRENCIES", "NGN,USD,GBP,EUR"))
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.DefaultCurrency = getEnvDefault("DEFAULT_CURRENCY", "NGN")
+	if !cfg.SupportsCurrency(cfg.DefaultCurrency) {
+		return nil, fmt.Errorf("DEFAULT_CURRENCY %q is not in SUPPORTED_CURRENCIES", cfg.DefaultCurrency)
+	}
+
+	cfg.FXRatesFile = os.Getenv("FX_RATES_FILE")
+
+	cfg.FXSpread, err = decimal.NewFromString(getEnvDefault("FX_SPREAD", "0.01"))
+	if err != nil || cfg.FXSpread.IsNegative() || !cfg.FXSpread.LessThan(decimal.NewFromInt(1)) {
+		return nil, fmt.Errorf("invalid FX_SPREAD")
+	}
+
+	cfg.FXQuoteTTL, err = time.ParseDuration(getEnvDefault("FX_QUOTE_TTL", "60s"))
+	if err != nil || cfg.FXQuoteTTL <= 0 {
+		return nil, fmt.Errorf("invalid FX_QUOTE_TTL")
+	}
+
+	cfg.FXRateMaxAge, err = time.ParseDuration(getEnvDefault("FX_RATE_MAX_AGE", "24h"))
+	if err != nil || cfg.FXRateMaxAge <= 0 {
+		return nil, fmt.Errorf("invalid FX_RATE_MAX_AGE")
+	}
+
+	cfg.WebhookTimeout, err = time.ParseDuration(getEnvDefault("WEBHOOK_TIMEOUT", "10s"))
+	if err != nil || cfg.WebhookTimeout <= 0 {
+		return nil, fmt.Errorf("invalid WEBHOOK_TIMEOUT")
+	}
+
+	cfg.WebhookMaxAttempts, err = strconv.Atoi(getEnvDefault("WEBHOOK_MAX_ATTEMPTS", "8"))
+	if err != nil || cfg.WebhookMaxAttempts < 1 {
+		return nil, fmt.Errorf("invalid WEBHOOK_MAX_ATTEMPTS")
+	}
+
+	cfg.WebhookRetryBase, err = time.ParseDuration(getEnvDefault("WEBHOOK_RETRY_BASE", "30s"))
+	if err != nil || cfg.WebhookRetryBase <= 0 {
+		return nil, fmt.Errorf("invalid WEBHOOK_RETRY_BASE")
+	}
+
+	cfg.WebhookDisableAfter, err = strconv.Atoi(getEnvDefault("WEBHOOK_DISABLE_AFTER", "5"))
+	if err != nil || cfg.WebhookDisableAfter < 1 {
+		return nil, fmt.Errorf("invalid WEBHOOK_DISABLE_AFTER")
+	}
+
+	cfg.WebhookSecretOverlap, err = time.ParseDuration(getEnvDefault("WEBHOOK_SECRET_OVERLAP", "24h"))
+	if err != nil || cfg.WebhookSecretOverlap < 0 {
+		return nil, fmt.Errorf("invalid WEBHOOK_SECRET_OVERLAP")
+	}
+
+	cfg.WebhookAllowHTTP, err = strconv.ParseBool(getEnvDefault("WEBHOOK_ALLOW_HTTP", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid WEBHOOK_ALLOW_HTTP")
+	}
+
+	cfg.WebhookAllowPrivateNetworks, err = strconv.ParseBool(getEnvDefault("WEBHOOK_ALLOW_PRIVATE_NETWORKS", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid WEBHOOK_ALLOW_PRIVATE_NETWORKS")
+	}
+
+	cfg.FundingProvider = getEnvDefault("FUNDING_PROVIDER", "simulator")
+	if cfg.FundingProvider != "simulator" {
+		return nil, fmt.Errorf("invalid FUNDING_PROVIDER")
+	}
+
+	cfg.FundingCallbackSecret, err = getEnv("FUNDING_CALLBACK_SECRET")
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.FundingCallbackTolerance, err = time.ParseDuration(getEnvDefault("FUNDING_CALLBACK_TOLERANCE", "5m"))
+	if err != nil || cfg.FundingCallbackTolerance <= 0 {
+		return nil, fmt.Errorf("invalid FUNDING_CALLBACK_TOLERANCE")
+	}
+
+	cfg.FundingSimulatorCallbackURL = getEnvDefault("FUNDING_SIMULATOR_CALLBACK_URL", "http://localhost:"+cfg.Port+"/funding/callbacks/simulator")
+
+	cfg.PayoutProvider = getEnvDefault("PAYOUT_PROVIDER", "simulator")
+	if cfg.PayoutProvider != "simulator" {
+		return nil, fmt.Errorf("invalid PAYOUT_PROVIDER")
+	}
+
+	cfg.PayoutPollInterval, err = time.ParseDuration(getEnvDefault("PAYOUT_POLL_INTERVAL", "10s"))
+	if err != nil || cfg.PayoutPollInterval <= 0 {
+		return nil, fmt.Errorf("invalid PAYOUT_POLL_INTERVAL")
+	}
+
+	cfg.PayoutSimulatorLatency, err = time.ParseDuration(getEnvDefault("PAYOUT_SIMULATOR_LATENCY", "5s"))
+	if err != nil || cfg.PayoutSimulatorLatency < 0 {
+		return nil, fmt.Errorf("invalid PAYOUT_SIMULATOR_LATENCY")
+	}
+
+	cfg.PayoutSimulatorFailureRate, err = strconv.ParseFloat(getEnvDefault("PAYOUT_SIMULATOR_FAILURE_RATE", "0.1"), 64)
+	if err != nil || cfg.PayoutSimulatorFailureRate < 0 || cfg.PayoutSimulatorFailureRate > 1 {
+		return nil, fmt.Errorf("invalid PAYOUT_SIMULATOR_FAILURE_RATE")
+	}
+
+	cfg.AdminAPIKey = os.Getenv("ADMIN_API_KEY")
+
+	cfg.WorkerConcurrency, err = strconv.Atoi(getEnvDefault("WORKER_CONCURRENCY", "10"))
+	if err != nil || cfg.WorkerConcurrency < 1 {
+		return nil, fmt.Errorf("invalid WORKER_CONCURRENCY")
+	}
+
+	cfg.WorkerQueues, err = parseQueues(getEnvDefault("WORKER_QUEUES", "critical=6,default=3,low=1"))
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.WorkerStrictPriority, err = strconv.ParseBool(getEnvDefault("WORKER_STRICT_PRIORITY", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid WORKER_STRICT_PRIORITY")
+	}
+
+	cfg.WorkerShutdownTimeout, err = time.ParseDuration(getEnvDefault("WORKER_SHUTDOWN_TIMEOUT", "30s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid WORKER_SHUTDOWN_TIMEOUT")
+	}
+
+	cfg.MailDriver = getEnvDefault("MAIL_DRIVER", "file")
+	cfg.MailFrom = getEnvDefault("MAIL_FROM", "Paycore <no-reply@paycore.local>")
+	cfg.MailSinkDir = getEnvDefault("MAIL_SINK_DIR", "./tmp/mail")
+
+	if cfg.MailDriver == "smtp" {
+		cfg.SMTPHost, err = getEnv("SMTP_HOST")
+		if err != nil {
+			return nil, err
+		}
+
+		cfg.SMTPPort, err = strconv.Atoi(getEnvDefault("SMTP_PORT", "587"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid SMTP_PORT")
+		}
+
+		cfg.SMTPUsername = os.Getenv("SMTP_USERNAME")
+		cfg.SMTPPassword = os.Getenv("SMTP_PASSWORD")
+	}
+
 	return &cfg, nil
 }
 
+// SupportsCurrency reports whether wallets may be opened in code.
+func (c *Config) SupportsCurrency(code string) bool {
+	for _, supported := range c.SupportedCurrencies {
+		if supported == code {
+			return true
+		}
+	}
+	return false
+}
+
 func getEnv(key string) (string, error) {
 	envStr := os.Getenv(key)
 	if envStr == "" {
@@ -56,3 +411,92 @@ func getEnv(key string) (string, error) {
 	}
 	return envStr, nil
 }
+
+func getEnvDefault(key, fallback string) string {
+	if envStr := os.Getenv(key); envStr != "" {
+		return envStr
+	}
+	return fallback
+}
+
+// parseQueues reads queue priorities in the form "critical=6,default=3,low=1".
+func parseQueues(raw string) (map[string]int, error) {
+	queues := make(map[string]int)
+	for _, entry := range strings.Split(raw, ",") {
+		name, weight, found := strings.Cut(strings.TrimSpace(entry), "=")
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid WORKER_QUEUES entry %q", entry)
+		}
+		priority, err := strconv.Atoi(weight)
+		if err != nil || priority < 1 {
+			return nil, fmt.Errorf("invalid WORKER_QUEUES priority for %q", name)
+		}
+		queues[name] = priority
+	}
+	return queues, nil
+}
+
+// parseCurrencies reads a list of ISO 4217 currency codes in the form "NGN,USD". Only codes
+// pkg/money knows the minor units of are accepted.
+func parseCurrencies(raw string) ([]string, error) {
+	var codes []string
+	for _, entry := range strings.Split(raw, ",") {
+		code := strings.ToUpper(strings.TrimSpace(entry))
+		if !money.IsKnown(code) {
+			return nil, fmt.Errorf("invalid SUPPORTED_CURRENCIES entry %q", entry)
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// parseDepositRates reads fixed deposit rates in the form "30=0.08,90=0.10" (tenor days = annual rate).
+func parseDepositRates(raw string) (map[int32]decimal.Decimal, error) {
+	rates := make(map[int32]decimal.Decimal)
+	for _, entry := range strings.Split(raw, ",") {
+		tenor, rate, found := strings.Cut(strings.TrimSpace(entry), "=")
+		if !found {
+			return nil, fmt.Errorf("invalid FIXED_DEPOSIT_RATES entry %q", entry)
+		}
+		days, err := strconv.ParseInt(tenor, 10, 32)
+		if err != nil || days < 1 {
+			return nil, fmt.Errorf("invalid FIXED_DEPOSIT_RATES tenor %q", tenor)
+		}
+		annual, err := decimal.NewFromString(rate)
+		if err != nil || annual.IsNegative() {
+			return nil, fmt.Errorf("invalid FIXED_DEPOSIT_RATES rate for %q", tenor)
+		}
+		rates[int32(days)] = annual
+	}
+	return rates, nil
+}
+
+// parseInterestTiers reads a rate table in the form "0=0.03,100000=0.05" (balance floor = annual
+// rate). Floors must start at zero and increase.
+func parseInterestTiers(raw string) ([]InterestTier, error) {
+	var tiers []InterestTier
+	for _, entry := range strings.Split(raw, ",") {
+		floor, rate, found := strings.Cut(strings.TrimSpace(entry), "=")
+		if !found {
+			return nil, fmt.Errorf("invalid SAVINGS_INTEREST_TIERS entry %q", entry)
+		}
+		tier := InterestTier{}
+		var err error
+		tier.Floor, err = decimal.NewFromString(floor)
+		if err != nil || tier.Floor.IsNegative() {
+			return nil, fmt.Errorf("invalid SAVINGS_INTEREST_TIERS floor %q", floor)
+		}
+		tier.Rate, err = decimal.NewFromString(rate)
+		if err != nil || tier.Rate.IsNegative() {
+			return nil, fmt.Errorf("invalid SAVINGS_INTEREST_TIERS rate for %q", floor)
+		}
+		if len(tiers) == 0 && !tier.Floor.IsZero() {
+			return nil, fmt.Errorf("SAVINGS_INTEREST_TIERS must start at 0")
+		}
+		if len(tiers) > 0 && !tier.Floor.GreaterThan(tiers[len(tiers)-1].Floor) {
+			return nil, fmt.Errorf("SAVINGS_INTEREST_TIERS floors must increase")
+		}
+		tiers = append(tiers, tier)
+	}
+	return tiers, nil
+}
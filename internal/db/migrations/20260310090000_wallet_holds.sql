@@ -0,0 +1,39 @@
+-- +goose Up
+
+-- balance stays the ledger (posted) balance; available_balance is what can
+-- still be spent once active holds have been taken out of it.
+ALTER TABLE wallets
+ADD COLUMN available_balance NUMERIC(18,2) NOT NULL DEFAULT 0;
+
+UPDATE wallets SET available_balance = balance;
+
+CREATE TYPE hold_status_enum AS ENUM (
+    'active',
+    'captured',
+    'voided',
+    'expired'
+);
+
+CREATE TABLE IF NOT EXISTS wallet_holds (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    wallet_id UUID NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    receiver_wallet_id UUID NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    amount NUMERIC(18,2) NOT NULL,
+    captured_amount NUMERIC(18,2) NOT NULL DEFAULT 0,
+    currency VARCHAR(6) NOT NULL,
+    status hold_status_enum NOT NULL DEFAULT 'active',
+    description TEXT,
+    idempotency_key VARCHAR(255) NOT NULL UNIQUE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_holds_status_expires_at ON wallet_holds (status, expires_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS wallet_holds;
+DROP TYPE IF EXISTS hold_status_enum;
+
+ALTER TABLE wallets
+DROP COLUMN IF EXISTS available_balance;
@@ -0,0 +1,15 @@
+-- +goose Up
+ALTER TYPE transaction_type_enum ADD VALUE IF NOT EXISTS 'reversal';
+ALTER TYPE transaction_type_enum ADD VALUE IF NOT EXISTS 'refund';
+
+-- Compensating transactions (reversals and refunds) point back at the transfer they undo.
+ALTER TABLE transactions
+ADD COLUMN parent_transaction_id UUID REFERENCES transactions(id);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_parent_transaction_id ON transactions (parent_transaction_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_transactions_parent_transaction_id;
+
+ALTER TABLE transactions
+DROP COLUMN IF EXISTS parent_transaction_id;
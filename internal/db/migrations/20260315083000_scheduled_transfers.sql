@@ -0,0 +1,73 @@
+-- +goose Up
+CREATE TYPE schedule_frequency_enum AS ENUM (
+    'daily',
+    'weekly',
+    'monthly'
+);
+
+CREATE TYPE schedule_status_enum AS ENUM (
+    'active',
+    'paused',
+    'completed',
+    'cancelled'
+);
+
+CREATE TYPE schedule_failure_policy_enum AS ENUM (
+    'retry',
+    'skip'
+);
+
+CREATE TYPE schedule_run_status_enum AS ENUM (
+    'succeeded',
+    'failed',
+    'skipped'
+);
+
+CREATE TABLE IF NOT EXISTS scheduled_transfers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    sender_wallet_id UUID NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    receiver_wallet_id UUID NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    amount NUMERIC(18,2) NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    description TEXT,
+    frequency schedule_frequency_enum NOT NULL,
+    start_at TIMESTAMPTZ NOT NULL,
+    end_at TIMESTAMPTZ,
+    max_runs INTEGER,
+    failure_policy schedule_failure_policy_enum NOT NULL DEFAULT 'retry',
+    max_retries INTEGER NOT NULL DEFAULT 3,
+    status schedule_status_enum NOT NULL DEFAULT 'active',
+    next_occurrence INTEGER NOT NULL DEFAULT 1, -- 1-based index of the occurrence due at next_run_at
+    next_run_at TIMESTAMPTZ NOT NULL,
+    retry_count INTEGER NOT NULL DEFAULT 0,    -- failed attempts of the current occurrence
+    runs_count INTEGER NOT NULL DEFAULT 0,     -- occurrences that produced a transfer
+    last_run_at TIMESTAMPTZ,
+    last_error TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_transfers_due ON scheduled_transfers (status, next_run_at);
+CREATE INDEX IF NOT EXISTS idx_scheduled_transfers_user_id ON scheduled_transfers (user_id);
+
+CREATE TABLE IF NOT EXISTS scheduled_transfer_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    schedule_id UUID NOT NULL REFERENCES scheduled_transfers(id) ON DELETE CASCADE,
+    occurrence INTEGER NOT NULL,
+    attempt INTEGER NOT NULL,
+    status schedule_run_status_enum NOT NULL,
+    transaction_id UUID REFERENCES transactions(id),
+    error TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_transfer_runs_schedule_id ON scheduled_transfer_runs (schedule_id, created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS scheduled_transfer_runs;
+DROP TABLE IF EXISTS scheduled_transfers;
+DROP TYPE IF EXISTS schedule_run_status_enum;
+DROP TYPE IF EXISTS schedule_failure_policy_enum;
+DROP TYPE IF EXISTS schedule_status_enum;
+DROP TYPE IF EXISTS schedule_frequency_enum;
@@ -0,0 +1,17 @@
+-- +goose Up
+ALTER TABLE users
+ADD COLUMN email_verified_at TIMESTAMPTZ;
+
+ALTER TABLE otps
+ADD COLUMN attempts INT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_otps_user_purpose ON otps(user_id, purpose, created_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_otps_user_purpose;
+
+ALTER TABLE otps
+DROP COLUMN attempts;
+
+ALTER TABLE users
+DROP COLUMN email_verified_at;
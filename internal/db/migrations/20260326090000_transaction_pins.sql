@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS transaction_pins (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    pin_hash TEXT NOT NULL,
+    failed_attempts INT NOT NULL DEFAULT 0,
+    locked_until TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- step-up codes are bound to the transfer they approve
+ALTER TABLE otps ADD COLUMN reference TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE otps DROP COLUMN IF EXISTS reference;
+DROP TABLE IF EXISTS transaction_pins;
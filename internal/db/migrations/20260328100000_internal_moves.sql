@@ -0,0 +1,10 @@
+-- +goose Up
+ALTER TYPE transaction_type_enum ADD VALUE IF NOT EXISTS 'internal_move';
+
+-- A fixed wallet cannot be debited while matures_at is in the future.
+ALTER TABLE wallets
+ADD COLUMN matures_at TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE wallets
+DROP COLUMN IF EXISTS matures_at;
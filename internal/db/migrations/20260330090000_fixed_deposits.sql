@@ -0,0 +1,62 @@
+-- +goose Up
+ALTER TYPE wallet_type_enum ADD VALUE IF NOT EXISTS 'system';
+ALTER TYPE transaction_type_enum ADD VALUE IF NOT EXISTS 'fixed_deposit';
+ALTER TYPE transaction_type_enum ADD VALUE IF NOT EXISTS 'interest';
+ALTER TYPE transaction_type_enum ADD VALUE IF NOT EXISTS 'penalty';
+
+-- System wallets belong to the platform rather than a user, are found by code and currency,
+-- and may run a negative balance (an expense account grows more negative as it pays out).
+ALTER TABLE wallets
+ADD COLUMN system_code VARCHAR(64);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_wallets_system_code_currency ON wallets (system_code, currency) WHERE system_code IS NOT NULL;
+
+CREATE TYPE deposit_status_enum AS ENUM (
+    'active',
+    'paid_out',
+    'rolled_over',
+    'broken'
+);
+
+CREATE TYPE deposit_maturity_action_enum AS ENUM (
+    'payout',
+    'rollover'
+);
+
+-- Deposit funds sit in the user's fixed wallet: counted in its balance but not in its
+-- available_balance until the deposit is paid out or broken.
+CREATE TABLE IF NOT EXISTS fixed_deposits (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    wallet_id UUID NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    payout_wallet_id UUID NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    parent_deposit_id UUID REFERENCES fixed_deposits(id),
+    principal NUMERIC(18,2) NOT NULL,
+    accrued_interest NUMERIC(18,2) NOT NULL DEFAULT 0,
+    annual_rate NUMERIC(9,6) NOT NULL,
+    tenor_days INTEGER NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    maturity_action deposit_maturity_action_enum NOT NULL DEFAULT 'payout',
+    status deposit_status_enum NOT NULL DEFAULT 'active',
+    start_date DATE NOT NULL,
+    maturity_date DATE NOT NULL,
+    accrued_through DATE NOT NULL,
+    penalty NUMERIC(18,2) NOT NULL DEFAULT 0,
+    closed_at TIMESTAMPTZ,
+    idempotency_key VARCHAR(255) NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_fixed_deposits_due ON fixed_deposits (status, accrued_through);
+CREATE INDEX IF NOT EXISTS idx_fixed_deposits_user_id ON fixed_deposits (user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS fixed_deposits;
+DROP TYPE IF EXISTS deposit_maturity_action_enum;
+DROP TYPE IF EXISTS deposit_status_enum;
+
+DROP INDEX IF EXISTS idx_wallets_system_code_currency;
+
+ALTER TABLE wallets
+DROP COLUMN IF EXISTS system_code;
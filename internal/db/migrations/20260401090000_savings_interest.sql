@@ -0,0 +1,22 @@
+-- +goose Up
+-- One row per savings wallet per day. amount keeps sub-cent precision; the month's rows are
+-- summed and posted to the wallet as a single interest transaction when capitalised.
+CREATE TABLE IF NOT EXISTS interest_accruals (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    wallet_id UUID NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    accrual_date DATE NOT NULL,
+    balance NUMERIC(18,2) NOT NULL,
+    annual_rate NUMERIC(9,6) NOT NULL,
+    day_count VARCHAR(16) NOT NULL,
+    amount NUMERIC(18,6) NOT NULL,
+    currency VARCHAR(6) NOT NULL,
+    transaction_id UUID REFERENCES transactions(id),
+    capitalised_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (wallet_id, accrual_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_interest_accruals_uncapitalised ON interest_accruals (wallet_id, accrual_date) WHERE capitalised_at IS NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS interest_accruals;
@@ -0,0 +1,38 @@
+-- +goose Up
+CREATE TYPE account_type_enum AS ENUM (
+    'asset',
+    'liability',
+    'equity',
+    'revenue',
+    'expense'
+);
+
+-- Every system wallet is a per-currency sub-account of a chart entry; user wallets roll up to
+-- customer_wallets. normal_balance is the side on which the account's balance grows.
+CREATE TABLE IF NOT EXISTS chart_of_accounts (
+    code VARCHAR(64) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    account_type account_type_enum NOT NULL,
+    normal_balance ledger_entry_type NOT NULL,
+    description TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+INSERT INTO chart_of_accounts (code, name, account_type, normal_balance, description) VALUES
+    ('customer_wallets', 'Customer wallets', 'liability', 'credit', 'Balances held for users across all of their wallets'),
+    ('fees_revenue', 'Fees revenue', 'revenue', 'credit', 'Fees charged on transactions'),
+    ('interest_expense', 'Interest expense', 'expense', 'debit', 'Interest paid on savings and fixed deposits, net of forfeited interest'),
+    ('settlement', 'Settlement', 'asset', 'debit', 'Funds held with banks and funding partners'),
+    ('suspense', 'Suspense', 'liability', 'credit', 'Funds in flight awaiting settlement or investigation'),
+    ('fx_position', 'FX position', 'asset', 'debit', 'Currency bought and sold when converting between wallets')
+ON CONFLICT (code) DO NOTHING;
+
+ALTER TABLE wallets
+ADD CONSTRAINT fk_wallets_system_code FOREIGN KEY (system_code) REFERENCES chart_of_accounts(code);
+
+-- +goose Down
+ALTER TABLE wallets
+DROP CONSTRAINT IF EXISTS fk_wallets_system_code;
+
+DROP TABLE IF EXISTS chart_of_accounts;
+DROP TYPE IF EXISTS account_type_enum;
@@ -0,0 +1,56 @@
+-- +goose Up
+-- KYC verification raises a user's tier; higher tiers can be given cheaper fee schedules.
+ALTER TABLE users
+ADD COLUMN kyc_tier SMALLINT NOT NULL DEFAULT 1 CHECK (kyc_tier BETWEEN 1 AND 3);
+
+CREATE TYPE fee_type_enum AS ENUM (
+    'flat',
+    'percentage',
+    'tiered'
+);
+
+-- A schedule prices one transaction type in one currency, optionally for one KYC tier only; a
+-- schedule without a tier applies to every tier that has no schedule of its own. Schedules are
+-- never edited: a new one replaces the active one, so a transaction's fee can always be
+-- explained from the schedule it points at.
+CREATE TABLE IF NOT EXISTS fee_schedules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    transaction_type transaction_type_enum NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    kyc_tier SMALLINT CHECK (kyc_tier BETWEEN 1 AND 3),
+    fee_type fee_type_enum NOT NULL,
+    flat_amount NUMERIC(18,2) NOT NULL DEFAULT 0,
+    rate NUMERIC(9,6) NOT NULL DEFAULT 0,
+    min_fee NUMERIC(18,2),
+    max_fee NUMERIC(18,2),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    deactivated_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_fee_schedules_active ON fee_schedules (transaction_type, currency, COALESCE(kyc_tier, 0)) WHERE active;
+
+-- Bands of a tiered schedule: the band with the highest floor not above the amount applies.
+CREATE TABLE IF NOT EXISTS fee_schedule_tiers (
+    schedule_id UUID NOT NULL REFERENCES fee_schedules(id) ON DELETE CASCADE,
+    floor NUMERIC(18,2) NOT NULL,
+    flat_amount NUMERIC(18,2) NOT NULL DEFAULT 0,
+    rate NUMERIC(9,6) NOT NULL DEFAULT 0,
+    PRIMARY KEY (schedule_id, floor)
+);
+
+ALTER TABLE transactions
+ADD COLUMN fee NUMERIC(18,2) NOT NULL DEFAULT 0,
+ADD COLUMN fee_schedule_id UUID REFERENCES fee_schedules(id);
+
+-- +goose Down
+ALTER TABLE transactions
+DROP COLUMN IF EXISTS fee_schedule_id,
+DROP COLUMN IF EXISTS fee;
+
+DROP TABLE IF EXISTS fee_schedule_tiers;
+DROP TABLE IF EXISTS fee_schedules;
+DROP TYPE IF EXISTS fee_type_enum;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS kyc_tier;
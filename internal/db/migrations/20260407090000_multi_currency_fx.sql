@@ -0,0 +1,46 @@
+-- +goose Up
+ALTER TYPE transaction_type_enum ADD VALUE IF NOT EXISTS 'fx_conversion';
+
+-- A user holds at most one wallet of each type per currency.
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS wallets_user_id_wallet_type_unique;
+ALTER TABLE wallets ADD CONSTRAINT wallets_user_id_wallet_type_currency_unique UNIQUE (user_id, wallet_type, currency);
+
+-- Mid-market rates: one unit of base_currency buys rate units of quote_currency. Rates are
+-- appended, never updated; the newest row for a pair is the current rate.
+CREATE TABLE IF NOT EXISTS fx_rates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    base_currency VARCHAR(3) NOT NULL,
+    quote_currency VARCHAR(3) NOT NULL,
+    rate NUMERIC(20,10) NOT NULL CHECK (rate > 0),
+    source VARCHAR(32) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (base_currency <> quote_currency)
+);
+
+CREATE INDEX IF NOT EXISTS idx_fx_rates_pair ON fx_rates (base_currency, quote_currency, created_at DESC);
+
+-- A quote fixes the rate for one conversion between two of the user's wallets until it expires.
+-- transaction_id is set when the quote is converted, which uses it up.
+CREATE TABLE IF NOT EXISTS fx_quotes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    from_wallet_id UUID NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    to_wallet_id UUID NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    from_currency VARCHAR(3) NOT NULL,
+    to_currency VARCHAR(3) NOT NULL,
+    sell_amount NUMERIC(18,2) NOT NULL,
+    buy_amount NUMERIC(18,2) NOT NULL,
+    mid_rate NUMERIC(20,10) NOT NULL,
+    rate NUMERIC(20,10) NOT NULL,
+    spread NUMERIC(9,6) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    transaction_id UUID REFERENCES transactions(id),
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS fx_quotes;
+DROP TABLE IF EXISTS fx_rates;
+
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS wallets_user_id_wallet_type_currency_unique;
+ALTER TABLE wallets ADD CONSTRAINT wallets_user_id_wallet_type_unique UNIQUE (user_id, wallet_type);
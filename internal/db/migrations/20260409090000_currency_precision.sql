@@ -0,0 +1,87 @@
+-- +goose Up
+-- Money columns were NUMERIC(18,2), which rounds three-decimal currencies and pads zero-decimal
+-- ones. They are now unconstrained NUMERIC: pkg/money holds every amount at exactly its
+-- currency's minor units, so the stored scale matches the currency.
+ALTER TABLE wallets
+ALTER COLUMN balance TYPE NUMERIC,
+ALTER COLUMN available_balance TYPE NUMERIC;
+
+ALTER TABLE transactions
+ALTER COLUMN amount TYPE NUMERIC,
+ALTER COLUMN fee TYPE NUMERIC;
+
+ALTER TABLE ledgers
+ALTER COLUMN amount TYPE NUMERIC,
+ALTER COLUMN balance_before TYPE NUMERIC,
+ALTER COLUMN balance_after TYPE NUMERIC;
+
+ALTER TABLE wallet_holds
+ALTER COLUMN amount TYPE NUMERIC,
+ALTER COLUMN captured_amount TYPE NUMERIC;
+
+ALTER TABLE scheduled_transfers
+ALTER COLUMN amount TYPE NUMERIC;
+
+ALTER TABLE fixed_deposits
+ALTER COLUMN principal TYPE NUMERIC,
+ALTER COLUMN accrued_interest TYPE NUMERIC,
+ALTER COLUMN penalty TYPE NUMERIC;
+
+ALTER TABLE interest_accruals
+ALTER COLUMN balance TYPE NUMERIC;
+
+ALTER TABLE fee_schedules
+ALTER COLUMN flat_amount TYPE NUMERIC,
+ALTER COLUMN min_fee TYPE NUMERIC,
+ALTER COLUMN max_fee TYPE NUMERIC;
+
+ALTER TABLE fee_schedule_tiers
+ALTER COLUMN floor TYPE NUMERIC,
+ALTER COLUMN flat_amount TYPE NUMERIC;
+
+ALTER TABLE fx_quotes
+ALTER COLUMN sell_amount TYPE NUMERIC,
+ALTER COLUMN buy_amount TYPE NUMERIC;
+
+-- +goose Down
+-- Going back rounds any three-decimal amounts to two places.
+ALTER TABLE fx_quotes
+ALTER COLUMN sell_amount TYPE NUMERIC(18,2),
+ALTER COLUMN buy_amount TYPE NUMERIC(18,2);
+
+ALTER TABLE fee_schedule_tiers
+ALTER COLUMN floor TYPE NUMERIC(18,2),
+ALTER COLUMN flat_amount TYPE NUMERIC(18,2);
+
+ALTER TABLE fee_schedules
+ALTER COLUMN flat_amount TYPE NUMERIC(18,2),
+ALTER COLUMN min_fee TYPE NUMERIC(18,2),
+ALTER COLUMN max_fee TYPE NUMERIC(18,2);
+
+ALTER TABLE interest_accruals
+ALTER COLUMN balance TYPE NUMERIC(18,2);
+
+ALTER TABLE fixed_deposits
+ALTER COLUMN principal TYPE NUMERIC(18,2),
+ALTER COLUMN accrued_interest TYPE NUMERIC(18,2),
+ALTER COLUMN penalty TYPE NUMERIC(18,2);
+
+ALTER TABLE scheduled_transfers
+ALTER COLUMN amount TYPE NUMERIC(18,2);
+
+ALTER TABLE wallet_holds
+ALTER COLUMN amount TYPE NUMERIC(18,2),
+ALTER COLUMN captured_amount TYPE NUMERIC(18,2);
+
+ALTER TABLE ledgers
+ALTER COLUMN amount TYPE NUMERIC(18,2),
+ALTER COLUMN balance_before TYPE NUMERIC(18,2),
+ALTER COLUMN balance_after TYPE NUMERIC(18,2);
+
+ALTER TABLE transactions
+ALTER COLUMN amount TYPE NUMERIC(18,2),
+ALTER COLUMN fee TYPE NUMERIC(18,2);
+
+ALTER TABLE wallets
+ALTER COLUMN balance TYPE NUMERIC(18,2),
+ALTER COLUMN available_balance TYPE NUMERIC(18,2);
@@ -0,0 +1,24 @@
+-- +goose Up
+-- Domain events written in the same database transaction as the change they describe, then
+-- published by the relay. id doubles as the dedup id consumers see; sequence orders events.
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    sequence BIGSERIAL NOT NULL UNIQUE,
+    aggregate_type VARCHAR(32) NOT NULL,
+    aggregate_id UUID NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    published_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- the relay only ever scans unpublished events, per aggregate in sequence order
+CREATE INDEX IF NOT EXISTS idx_outbox_events_pending
+ON outbox_events (aggregate_type, aggregate_id, sequence)
+WHERE published_at IS NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS outbox_events;
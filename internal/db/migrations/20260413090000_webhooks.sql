@@ -0,0 +1,78 @@
+-- +goose Up
+CREATE TYPE webhook_endpoint_status_enum AS ENUM (
+    'active',
+    'disabled'
+);
+
+CREATE TYPE webhook_delivery_status_enum AS ENUM (
+    'pending',
+    'succeeded',
+    'failed'
+);
+
+-- Endpoints receive the domain events listed in event_types ('*' for all) that concern their
+-- owner. Deliveries are signed with secret; after a rotation previous_secret keeps signing
+-- alongside it until previous_secret_expires_at.
+CREATE TABLE IF NOT EXISTS webhook_endpoints (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    description TEXT,
+    event_types TEXT[] NOT NULL,
+    secret VARCHAR(128) NOT NULL,
+    previous_secret VARCHAR(128),
+    previous_secret_expires_at TIMESTAMPTZ,
+    status webhook_endpoint_status_enum NOT NULL DEFAULT 'active',
+    consecutive_failures INT NOT NULL DEFAULT 0,
+    disabled_reason TEXT,
+    disabled_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_endpoints_user_id ON webhook_endpoints (user_id);
+
+-- One delivery per endpoint and event; a manual redelivery is a new row pointing at the original.
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    endpoint_id UUID NOT NULL REFERENCES webhook_endpoints(id) ON DELETE CASCADE,
+    event_id UUID NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    payload JSONB NOT NULL,
+    redelivery_of UUID REFERENCES webhook_deliveries(id) ON DELETE SET NULL,
+    status webhook_delivery_status_enum NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    response_code INT,
+    last_error TEXT,
+    next_attempt_at TIMESTAMPTZ,
+    delivered_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_deliveries_endpoint_event
+ON webhook_deliveries (endpoint_id, event_id)
+WHERE redelivery_of IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_endpoint_created ON webhook_deliveries (endpoint_id, created_at DESC);
+
+-- Every HTTP attempt of a delivery, with what the receiver answered.
+CREATE TABLE IF NOT EXISTS webhook_delivery_attempts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    delivery_id UUID NOT NULL REFERENCES webhook_deliveries(id) ON DELETE CASCADE,
+    attempt INT NOT NULL,
+    response_code INT,
+    response_body TEXT,
+    error TEXT,
+    duration_ms INT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery_attempts_delivery ON webhook_delivery_attempts (delivery_id, attempt);
+
+-- +goose Down
+DROP TABLE IF EXISTS webhook_delivery_attempts;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_endpoints;
+DROP TYPE IF EXISTS webhook_delivery_status_enum;
+DROP TYPE IF EXISTS webhook_endpoint_status_enum;
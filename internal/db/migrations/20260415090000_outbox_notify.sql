@@ -0,0 +1,22 @@
+-- +goose Up
+-- Announce wallet and transaction events on the outbox_events channel as they commit, so API
+-- instances can push them to streaming clients. The payload is the event's sequence; listeners
+-- read the row itself, which keeps notifications under the payload size limit.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION notify_outbox_event() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('outbox_events', NEW.sequence::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER outbox_events_notify
+AFTER INSERT ON outbox_events
+FOR EACH ROW
+WHEN (NEW.aggregate_type IN ('wallet', 'transaction'))
+EXECUTE FUNCTION notify_outbox_event();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS outbox_events_notify ON outbox_events;
+DROP FUNCTION IF EXISTS notify_outbox_event();
@@ -0,0 +1,56 @@
+-- +goose Up
+ALTER TYPE transaction_type_enum ADD VALUE IF NOT EXISTS 'deposit';
+
+CREATE TYPE funding_deposit_status_enum AS ENUM (
+    'pending',
+    'settled',
+    'failed'
+);
+
+-- A virtual account is a bank account number a funding provider issues for one wallet; money
+-- paid into it is credited to that wallet.
+CREATE TABLE IF NOT EXISTS virtual_accounts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    wallet_id UUID NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    provider VARCHAR(32) NOT NULL,
+    account_number VARCHAR(20) NOT NULL,
+    account_name VARCHAR(128) NOT NULL,
+    bank_name VARCHAR(128) NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (provider, account_number),
+    UNIQUE (wallet_id, provider)
+);
+
+CREATE INDEX IF NOT EXISTS idx_virtual_accounts_user_id ON virtual_accounts (user_id);
+
+-- One row per inbound payment, keyed by the provider's reference so repeated callbacks about
+-- the same payment update it rather than crediting the wallet again. transaction_id is the
+-- ledger transaction that settled it.
+CREATE TABLE IF NOT EXISTS funding_deposits (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    provider VARCHAR(32) NOT NULL,
+    provider_reference VARCHAR(128) NOT NULL,
+    virtual_account_id UUID NOT NULL REFERENCES virtual_accounts(id) ON DELETE CASCADE,
+    wallet_id UUID NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    amount NUMERIC NOT NULL CHECK (amount > 0),
+    currency VARCHAR(3) NOT NULL,
+    status funding_deposit_status_enum NOT NULL DEFAULT 'pending',
+    sender_name VARCHAR(128),
+    sender_account_number VARCHAR(20),
+    sender_bank VARCHAR(128),
+    transaction_id UUID REFERENCES transactions(id),
+    failure_reason TEXT,
+    settled_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (provider, provider_reference)
+);
+
+CREATE INDEX IF NOT EXISTS idx_funding_deposits_wallet_id ON funding_deposits (wallet_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS funding_deposits;
+DROP TABLE IF EXISTS virtual_accounts;
+DROP TYPE IF EXISTS funding_deposit_status_enum;
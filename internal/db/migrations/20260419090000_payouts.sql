@@ -0,0 +1,69 @@
+-- +goose Up
+ALTER TYPE transaction_type_enum ADD VALUE IF NOT EXISTS 'withdrawal';
+
+CREATE TYPE payout_status_enum AS ENUM (
+    'initiated',
+    'processing',
+    'succeeded',
+    'failed',
+    'reversed'
+);
+
+-- Bank accounts a user has saved to pay out to. account_name is what the bank returned on
+-- name enquiry, not what the user typed.
+CREATE TABLE IF NOT EXISTS bank_destinations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    bank_code VARCHAR(16) NOT NULL,
+    bank_name VARCHAR(128) NOT NULL,
+    account_number VARCHAR(20) NOT NULL,
+    account_name VARCHAR(128) NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    nickname VARCHAR(64),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, bank_code, account_number)
+);
+
+-- A payout moves money from a wallet to a bank account:
+--   initiated  - the amount and fee are held on the wallet
+--   processing - the provider accepted it; the hold is captured into the suspense account
+--   succeeded  - the provider paid it; suspense is cleared against settlement
+--   failed     - the provider turned it down before any money left; the hold is released
+--   reversed   - the provider failed it after capture; the wallet is refunded from suspense
+-- The bank details are copied from the destination so deleting it does not rewrite history.
+CREATE TABLE IF NOT EXISTS payouts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    wallet_id UUID NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    destination_id UUID REFERENCES bank_destinations(id) ON DELETE SET NULL,
+    bank_code VARCHAR(16) NOT NULL,
+    account_number VARCHAR(20) NOT NULL,
+    account_name VARCHAR(128) NOT NULL,
+    amount NUMERIC NOT NULL CHECK (amount > 0),
+    fee NUMERIC NOT NULL DEFAULT 0,
+    fee_schedule_id UUID REFERENCES fee_schedules(id),
+    currency VARCHAR(3) NOT NULL,
+    narration VARCHAR(128),
+    status payout_status_enum NOT NULL DEFAULT 'initiated',
+    hold_id UUID NOT NULL REFERENCES wallet_holds(id),
+    provider VARCHAR(32) NOT NULL,
+    provider_reference VARCHAR(128),
+    debit_transaction_id UUID REFERENCES transactions(id),
+    settlement_transaction_id UUID REFERENCES transactions(id),
+    reversal_transaction_id UUID REFERENCES transactions(id),
+    failure_reason TEXT,
+    checks INT NOT NULL DEFAULT 0,
+    next_check_at TIMESTAMPTZ,
+    idempotency_key VARCHAR(255) NOT NULL UNIQUE,
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_payouts_user_id ON payouts (user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_payouts_open ON payouts (status, next_check_at) WHERE status IN ('initiated', 'processing');
+
+-- +goose Down
+DROP TABLE IF EXISTS payouts;
+DROP TABLE IF EXISTS bank_destinations;
+DROP TYPE IF EXISTS payout_status_enum;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Access tokens issued before this moment stop working, even if their session survived.
+ALTER TABLE users
+ADD COLUMN password_changed_at TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE users
+DROP COLUMN password_changed_at;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- review - the provider accepted the payout but the wallet could not be debited for it; the
+--          provider reference is kept so operations can reconcile it against the bank.
+ALTER TYPE payout_status_enum ADD VALUE IF NOT EXISTS 'review';
+
+-- Payout holds are only ever captured or released by the payout service, never by the hold
+-- expiry job, which looks them up through this index.
+CREATE INDEX IF NOT EXISTS idx_payouts_hold_id ON payouts (hold_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_payouts_hold_id;
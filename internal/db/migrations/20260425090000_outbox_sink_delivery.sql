@@ -0,0 +1,7 @@
+-- +goose Up
+-- Sinks that have accepted an event the relay has not finished with, so a retry only goes to
+-- the sinks that failed.
+ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS published_sinks TEXT[] NOT NULL DEFAULT '{}';
+
+-- +goose Down
+ALTER TABLE outbox_events DROP COLUMN IF EXISTS published_sinks;
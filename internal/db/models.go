@@ -12,6 +12,267 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type AccountTypeEnum string
+
+const (
+	AccountTypeEnumAsset     AccountTypeEnum = "asset"
+	AccountTypeEnumLiability AccountTypeEnum = "liability"
+	AccountTypeEnumEquity    AccountTypeEnum = "equity"
+	AccountTypeEnumRevenue   AccountTypeEnum = "revenue"
+	AccountTypeEnumExpense   AccountTypeEnum = "expense"
+)
+
+func (e *AccountTypeEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = AccountTypeEnum(s)
+	case string:
+		*e = AccountTypeEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for AccountTypeEnum: %T", src)
+	}
+	return nil
+}
+
+type NullAccountTypeEnum struct {
+	AccountTypeEnum AccountTypeEnum `json:"account_type_enum"`
+	Valid           bool            `json:"valid"` // Valid is true if AccountTypeEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullAccountTypeEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.AccountTypeEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.AccountTypeEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullAccountTypeEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.AccountTypeEnum), nil
+}
+
+type DepositMaturityActionEnum string
+
+const (
+	DepositMaturityActionEnumPayout   DepositMaturityActionEnum = "payout"
+	DepositMaturityActionEnumRollover DepositMaturityActionEnum = "rollover"
+)
+
+func (e *DepositMaturityActionEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = DepositMaturityActionEnum(s)
+	case string:
+		*e = DepositMaturityActionEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for DepositMaturityActionEnum: %T", src)
+	}
+	return nil
+}
+
+type NullDepositMaturityActionEnum struct {
+	DepositMaturityActionEnum DepositMaturityActionEnum `json:"deposit_maturity_action_enum"`
+	Valid                     bool                      `json:"valid"` // Valid is true if DepositMaturityActionEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullDepositMaturityActionEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.DepositMaturityActionEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.DepositMaturityActionEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullDepositMaturityActionEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.DepositMaturityActionEnum), nil
+}
+
+type DepositStatusEnum string
+
+const (
+	DepositStatusEnumActive     DepositStatusEnum = "active"
+	DepositStatusEnumPaidOut    DepositStatusEnum = "paid_out"
+	DepositStatusEnumRolledOver DepositStatusEnum = "rolled_over"
+	DepositStatusEnumBroken     DepositStatusEnum = "broken"
+)
+
+func (e *DepositStatusEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = DepositStatusEnum(s)
+	case string:
+		*e = DepositStatusEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for DepositStatusEnum: %T", src)
+	}
+	return nil
+}
+
+type NullDepositStatusEnum struct {
+	DepositStatusEnum DepositStatusEnum `json:"deposit_status_enum"`
+	Valid             bool              `json:"valid"` // Valid is true if DepositStatusEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullDepositStatusEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.DepositStatusEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.DepositStatusEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullDepositStatusEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.DepositStatusEnum), nil
+}
+
+type FeeTypeEnum string
+
+const (
+	FeeTypeEnumFlat       FeeTypeEnum = "flat"
+	FeeTypeEnumPercentage FeeTypeEnum = "percentage"
+	FeeTypeEnumTiered     FeeTypeEnum = "tiered"
+)
+
+func (e *FeeTypeEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = FeeTypeEnum(s)
+	case string:
+		*e = FeeTypeEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for FeeTypeEnum: %T", src)
+	}
+	return nil
+}
+
+type NullFeeTypeEnum struct {
+	FeeTypeEnum FeeTypeEnum `json:"fee_type_enum"`
+	Valid       bool        `json:"valid"` // Valid is true if FeeTypeEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullFeeTypeEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.FeeTypeEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.FeeTypeEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullFeeTypeEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.FeeTypeEnum), nil
+}
+
+type FundingDepositStatusEnum string
+
+const (
+	FundingDepositStatusEnumPending FundingDepositStatusEnum = "pending"
+	FundingDepositStatusEnumSettled FundingDepositStatusEnum = "settled"
+	FundingDepositStatusEnumFailed  FundingDepositStatusEnum = "failed"
+)
+
+func (e *FundingDepositStatusEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = FundingDepositStatusEnum(s)
+	case string:
+		*e = FundingDepositStatusEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for FundingDepositStatusEnum: %T", src)
+	}
+	return nil
+}
+
+type NullFundingDepositStatusEnum struct {
+	FundingDepositStatusEnum FundingDepositStatusEnum `json:"funding_deposit_status_enum"`
+	Valid                    bool                     `json:"valid"` // Valid is true if FundingDepositStatusEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullFundingDepositStatusEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.FundingDepositStatusEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.FundingDepositStatusEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullFundingDepositStatusEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.FundingDepositStatusEnum), nil
+}
+
+type HoldStatusEnum string
+
+const (
+	HoldStatusEnumActive   HoldStatusEnum = "active"
+	HoldStatusEnumCaptured HoldStatusEnum = "captured"
+	HoldStatusEnumVoided   HoldStatusEnum = "voided"
+	HoldStatusEnumExpired  HoldStatusEnum = "expired"
+)
+
+func (e *HoldStatusEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = HoldStatusEnum(s)
+	case string:
+		*e = HoldStatusEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for HoldStatusEnum: %T", src)
+	}
+	return nil
+}
+
+type NullHoldStatusEnum struct {
+	HoldStatusEnum HoldStatusEnum `json:"hold_status_enum"`
+	Valid          bool           `json:"valid"` // Valid is true if HoldStatusEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullHoldStatusEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.HoldStatusEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.HoldStatusEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullHoldStatusEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.HoldStatusEnum), nil
+}
+
 type LedgerEntryType string
 
 const (
@@ -54,6 +315,224 @@ func (ns NullLedgerEntryType) Value() (driver.Value, error) {
 	return string(ns.LedgerEntryType), nil
 }
 
+type PayoutStatusEnum string
+
+const (
+	PayoutStatusEnumInitiated  PayoutStatusEnum = "initiated"
+	PayoutStatusEnumProcessing PayoutStatusEnum = "processing"
+	PayoutStatusEnumSucceeded  PayoutStatusEnum = "succeeded"
+	PayoutStatusEnumFailed     PayoutStatusEnum = "failed"
+	PayoutStatusEnumReversed   PayoutStatusEnum = "reversed"
+	PayoutStatusEnumReview     PayoutStatusEnum = "review"
+)
+
+func (e *PayoutStatusEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = PayoutStatusEnum(s)
+	case string:
+		*e = PayoutStatusEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for PayoutStatusEnum: %T", src)
+	}
+	return nil
+}
+
+type NullPayoutStatusEnum struct {
+	PayoutStatusEnum PayoutStatusEnum `json:"payout_status_enum"`
+	Valid            bool             `json:"valid"` // Valid is true if PayoutStatusEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullPayoutStatusEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.PayoutStatusEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.PayoutStatusEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullPayoutStatusEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.PayoutStatusEnum), nil
+}
+
+type ScheduleFailurePolicyEnum string
+
+const (
+	ScheduleFailurePolicyEnumRetry ScheduleFailurePolicyEnum = "retry"
+	ScheduleFailurePolicyEnumSkip  ScheduleFailurePolicyEnum = "skip"
+)
+
+func (e *ScheduleFailurePolicyEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ScheduleFailurePolicyEnum(s)
+	case string:
+		*e = ScheduleFailurePolicyEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ScheduleFailurePolicyEnum: %T", src)
+	}
+	return nil
+}
+
+type NullScheduleFailurePolicyEnum struct {
+	ScheduleFailurePolicyEnum ScheduleFailurePolicyEnum `json:"schedule_failure_policy_enum"`
+	Valid                     bool                      `json:"valid"` // Valid is true if ScheduleFailurePolicyEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullScheduleFailurePolicyEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.ScheduleFailurePolicyEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ScheduleFailurePolicyEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullScheduleFailurePolicyEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ScheduleFailurePolicyEnum), nil
+}
+
+type ScheduleFrequencyEnum string
+
+const (
+	ScheduleFrequencyEnumDaily   ScheduleFrequencyEnum = "daily"
+	ScheduleFrequencyEnumWeekly  ScheduleFrequencyEnum = "weekly"
+	ScheduleFrequencyEnumMonthly ScheduleFrequencyEnum = "monthly"
+)
+
+func (e *ScheduleFrequencyEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ScheduleFrequencyEnum(s)
+	case string:
+		*e = ScheduleFrequencyEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ScheduleFrequencyEnum: %T", src)
+	}
+	return nil
+}
+
+type NullScheduleFrequencyEnum struct {
+	ScheduleFrequencyEnum ScheduleFrequencyEnum `json:"schedule_frequency_enum"`
+	Valid                 bool                  `json:"valid"` // Valid is true if ScheduleFrequencyEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullScheduleFrequencyEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.ScheduleFrequencyEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ScheduleFrequencyEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullScheduleFrequencyEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ScheduleFrequencyEnum), nil
+}
+
+type ScheduleRunStatusEnum string
+
+const (
+	ScheduleRunStatusEnumSucceeded ScheduleRunStatusEnum = "succeeded"
+	ScheduleRunStatusEnumFailed    ScheduleRunStatusEnum = "failed"
+	ScheduleRunStatusEnumSkipped   ScheduleRunStatusEnum = "skipped"
+)
+
+func (e *ScheduleRunStatusEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ScheduleRunStatusEnum(s)
+	case string:
+		*e = ScheduleRunStatusEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ScheduleRunStatusEnum: %T", src)
+	}
+	return nil
+}
+
+type NullScheduleRunStatusEnum struct {
+	ScheduleRunStatusEnum ScheduleRunStatusEnum `json:"schedule_run_status_enum"`
+	Valid                 bool                  `json:"valid"` // Valid is true if ScheduleRunStatusEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullScheduleRunStatusEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.ScheduleRunStatusEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ScheduleRunStatusEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullScheduleRunStatusEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ScheduleRunStatusEnum), nil
+}
+
+type ScheduleStatusEnum string
+
+const (
+	ScheduleStatusEnumActive    ScheduleStatusEnum = "active"
+	ScheduleStatusEnumPaused    ScheduleStatusEnum = "paused"
+	ScheduleStatusEnumCompleted ScheduleStatusEnum = "completed"
+	ScheduleStatusEnumCancelled ScheduleStatusEnum = "cancelled"
+)
+
+func (e *ScheduleStatusEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ScheduleStatusEnum(s)
+	case string:
+		*e = ScheduleStatusEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ScheduleStatusEnum: %T", src)
+	}
+	return nil
+}
+
+type NullScheduleStatusEnum struct {
+	ScheduleStatusEnum ScheduleStatusEnum `json:"schedule_status_enum"`
+	Valid              bool               `json:"valid"` // Valid is true if ScheduleStatusEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullScheduleStatusEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.ScheduleStatusEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ScheduleStatusEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullScheduleStatusEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ScheduleStatusEnum), nil
+}
+
 type TransactionStatusEnum string
 
 const (
@@ -100,9 +579,18 @@ func (ns NullTransactionStatusEnum) Value() (driver.Value, error) {
 type TransactionTypeEnum string
 
 const (
-	TransactionTypeEnumCredit   TransactionTypeEnum = "credit"
-	TransactionTypeEnumDebit    TransactionTypeEnum = "debit"
-	TransactionTypeEnumTransfer TransactionTypeEnum = "transfer"
+	TransactionTypeEnumCredit       TransactionTypeEnum = "credit"
+	TransactionTypeEnumDebit        TransactionTypeEnum = "debit"
+	TransactionTypeEnumTransfer     TransactionTypeEnum = "transfer"
+	TransactionTypeEnumReversal     TransactionTypeEnum = "reversal"
+	TransactionTypeEnumRefund       TransactionTypeEnum = "refund"
+	TransactionTypeEnumInternalMove TransactionTypeEnum = "internal_move"
+	TransactionTypeEnumFixedDeposit TransactionTypeEnum = "fixed_deposit"
+	TransactionTypeEnumInterest     TransactionTypeEnum = "interest"
+	TransactionTypeEnumPenalty      TransactionTypeEnum = "penalty"
+	TransactionTypeEnumFxConversion TransactionTypeEnum = "fx_conversion"
+	TransactionTypeEnumDeposit      TransactionTypeEnum = "deposit"
+	TransactionTypeEnumWithdrawal   TransactionTypeEnum = "withdrawal"
 )
 
 func (e *TransactionTypeEnum) Scan(src interface{}) error {
@@ -146,6 +634,7 @@ const (
 	WalletTypeEnumSavings WalletTypeEnum = "savings"
 	WalletTypeEnumFixed   WalletTypeEnum = "fixed"
 	WalletTypeEnumMisc    WalletTypeEnum = "misc"
+	WalletTypeEnumSystem  WalletTypeEnum = "system"
 )
 
 func (e *WalletTypeEnum) Scan(src interface{}) error {
@@ -183,6 +672,226 @@ func (ns NullWalletTypeEnum) Value() (driver.Value, error) {
 	return string(ns.WalletTypeEnum), nil
 }
 
+type WebhookDeliveryStatusEnum string
+
+const (
+	WebhookDeliveryStatusEnumPending   WebhookDeliveryStatusEnum = "pending"
+	WebhookDeliveryStatusEnumSucceeded WebhookDeliveryStatusEnum = "succeeded"
+	WebhookDeliveryStatusEnumFailed    WebhookDeliveryStatusEnum = "failed"
+)
+
+func (e *WebhookDeliveryStatusEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = WebhookDeliveryStatusEnum(s)
+	case string:
+		*e = WebhookDeliveryStatusEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for WebhookDeliveryStatusEnum: %T", src)
+	}
+	return nil
+}
+
+type NullWebhookDeliveryStatusEnum struct {
+	WebhookDeliveryStatusEnum WebhookDeliveryStatusEnum `json:"webhook_delivery_status_enum"`
+	Valid                     bool                      `json:"valid"` // Valid is true if WebhookDeliveryStatusEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullWebhookDeliveryStatusEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.WebhookDeliveryStatusEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.WebhookDeliveryStatusEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullWebhookDeliveryStatusEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.WebhookDeliveryStatusEnum), nil
+}
+
+type WebhookEndpointStatusEnum string
+
+const (
+	WebhookEndpointStatusEnumActive   WebhookEndpointStatusEnum = "active"
+	WebhookEndpointStatusEnumDisabled WebhookEndpointStatusEnum = "disabled"
+)
+
+func (e *WebhookEndpointStatusEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = WebhookEndpointStatusEnum(s)
+	case string:
+		*e = WebhookEndpointStatusEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for WebhookEndpointStatusEnum: %T", src)
+	}
+	return nil
+}
+
+type NullWebhookEndpointStatusEnum struct {
+	WebhookEndpointStatusEnum WebhookEndpointStatusEnum `json:"webhook_endpoint_status_enum"`
+	Valid                     bool                      `json:"valid"` // Valid is true if WebhookEndpointStatusEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullWebhookEndpointStatusEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.WebhookEndpointStatusEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.WebhookEndpointStatusEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullWebhookEndpointStatusEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.WebhookEndpointStatusEnum), nil
+}
+
+type AuditEvent struct {
+	ID        uuid.UUID          `json:"id"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	Event     string             `json:"event"`
+	IpAddress string             `json:"ip_address"`
+	UserAgent string             `json:"user_agent"`
+	Metadata  []byte             `json:"metadata"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type BankDestination struct {
+	ID            uuid.UUID          `json:"id"`
+	UserID        uuid.UUID          `json:"user_id"`
+	BankCode      string             `json:"bank_code"`
+	BankName      string             `json:"bank_name"`
+	AccountNumber string             `json:"account_number"`
+	AccountName   string             `json:"account_name"`
+	Currency      string             `json:"currency"`
+	Nickname      pgtype.Text        `json:"nickname"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+}
+
+type ChartOfAccount struct {
+	Code          string             `json:"code"`
+	Name          string             `json:"name"`
+	AccountType   AccountTypeEnum    `json:"account_type"`
+	NormalBalance LedgerEntryType    `json:"normal_balance"`
+	Description   pgtype.Text        `json:"description"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+}
+
+type FeeSchedule struct {
+	ID              uuid.UUID           `json:"id"`
+	TransactionType TransactionTypeEnum `json:"transaction_type"`
+	Currency        string              `json:"currency"`
+	KycTier         pgtype.Int2         `json:"kyc_tier"`
+	FeeType         FeeTypeEnum         `json:"fee_type"`
+	FlatAmount      pgtype.Numeric      `json:"flat_amount"`
+	Rate            pgtype.Numeric      `json:"rate"`
+	MinFee          pgtype.Numeric      `json:"min_fee"`
+	MaxFee          pgtype.Numeric      `json:"max_fee"`
+	Active          bool                `json:"active"`
+	CreatedAt       pgtype.Timestamptz  `json:"created_at"`
+	DeactivatedAt   pgtype.Timestamptz  `json:"deactivated_at"`
+}
+
+type FeeScheduleTier struct {
+	ScheduleID uuid.UUID      `json:"schedule_id"`
+	Floor      pgtype.Numeric `json:"floor"`
+	FlatAmount pgtype.Numeric `json:"flat_amount"`
+	Rate       pgtype.Numeric `json:"rate"`
+}
+
+type FixedDeposit struct {
+	ID              uuid.UUID                 `json:"id"`
+	UserID          uuid.UUID                 `json:"user_id"`
+	WalletID        uuid.UUID                 `json:"wallet_id"`
+	PayoutWalletID  uuid.UUID                 `json:"payout_wallet_id"`
+	ParentDepositID pgtype.UUID               `json:"parent_deposit_id"`
+	Principal       pgtype.Numeric            `json:"principal"`
+	AccruedInterest pgtype.Numeric            `json:"accrued_interest"`
+	AnnualRate      pgtype.Numeric            `json:"annual_rate"`
+	TenorDays       int32                     `json:"tenor_days"`
+	Currency        string                    `json:"currency"`
+	MaturityAction  DepositMaturityActionEnum `json:"maturity_action"`
+	Status          DepositStatusEnum         `json:"status"`
+	StartDate       pgtype.Date               `json:"start_date"`
+	MaturityDate    pgtype.Date               `json:"maturity_date"`
+	AccruedThrough  pgtype.Date               `json:"accrued_through"`
+	Penalty         pgtype.Numeric            `json:"penalty"`
+	ClosedAt        pgtype.Timestamptz        `json:"closed_at"`
+	IdempotencyKey  string                    `json:"idempotency_key"`
+	CreatedAt       pgtype.Timestamptz        `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz        `json:"updated_at"`
+}
+
+type FundingDeposit struct {
+	ID                  uuid.UUID                `json:"id"`
+	Provider            string                   `json:"provider"`
+	ProviderReference   string                   `json:"provider_reference"`
+	VirtualAccountID    uuid.UUID                `json:"virtual_account_id"`
+	WalletID            uuid.UUID                `json:"wallet_id"`
+	Amount              pgtype.Numeric           `json:"amount"`
+	Currency            string                   `json:"currency"`
+	Status              FundingDepositStatusEnum `json:"status"`
+	SenderName          pgtype.Text              `json:"sender_name"`
+	SenderAccountNumber pgtype.Text              `json:"sender_account_number"`
+	SenderBank          pgtype.Text              `json:"sender_bank"`
+	TransactionID       pgtype.UUID              `json:"transaction_id"`
+	FailureReason       pgtype.Text              `json:"failure_reason"`
+	SettledAt           pgtype.Timestamptz       `json:"settled_at"`
+	CreatedAt           pgtype.Timestamptz       `json:"created_at"`
+	UpdatedAt           pgtype.Timestamptz       `json:"updated_at"`
+}
+
+type FxQuote struct {
+	ID            uuid.UUID          `json:"id"`
+	UserID        uuid.UUID          `json:"user_id"`
+	FromWalletID  uuid.UUID          `json:"from_wallet_id"`
+	ToWalletID    uuid.UUID          `json:"to_wallet_id"`
+	FromCurrency  string             `json:"from_currency"`
+	ToCurrency    string             `json:"to_currency"`
+	SellAmount    pgtype.Numeric     `json:"sell_amount"`
+	BuyAmount     pgtype.Numeric     `json:"buy_amount"`
+	MidRate       pgtype.Numeric     `json:"mid_rate"`
+	Rate          pgtype.Numeric     `json:"rate"`
+	Spread        pgtype.Numeric     `json:"spread"`
+	ExpiresAt     pgtype.Timestamptz `json:"expires_at"`
+	TransactionID pgtype.UUID        `json:"transaction_id"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+}
+
+type FxRate struct {
+	ID            uuid.UUID          `json:"id"`
+	BaseCurrency  string             `json:"base_currency"`
+	QuoteCurrency string             `json:"quote_currency"`
+	Rate          pgtype.Numeric     `json:"rate"`
+	Source        string             `json:"source"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+}
+
+type InterestAccrual struct {
+	ID            uuid.UUID          `json:"id"`
+	WalletID      uuid.UUID          `json:"wallet_id"`
+	AccrualDate   pgtype.Date        `json:"accrual_date"`
+	Balance       pgtype.Numeric     `json:"balance"`
+	AnnualRate    pgtype.Numeric     `json:"annual_rate"`
+	DayCount      string             `json:"day_count"`
+	Amount        pgtype.Numeric     `json:"amount"`
+	Currency      string             `json:"currency"`
+	TransactionID pgtype.UUID        `json:"transaction_id"`
+	CapitalisedAt pgtype.Timestamptz `json:"capitalised_at"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+}
+
 type Ledger struct {
 	ID            uuid.UUID          `json:"id"`
 	WalletID      uuid.UUID          `json:"wallet_id"`
@@ -195,6 +904,14 @@ type Ledger struct {
 	CreatedAt     pgtype.Timestamptz `json:"created_at"`
 }
 
+type MfaRecoveryCode struct {
+	ID        uuid.UUID          `json:"id"`
+	UserID    uuid.UUID          `json:"user_id"`
+	CodeHash  string             `json:"code_hash"`
+	UsedAt    pgtype.Timestamptz `json:"used_at"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
 type Otp struct {
 	ID        uuid.UUID          `json:"id"`
 	UserID    uuid.UUID          `json:"user_id"`
@@ -203,42 +920,240 @@ type Otp struct {
 	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
 	Used      pgtype.Bool        `json:"used"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	Attempts  int32              `json:"attempts"`
+	Reference string             `json:"reference"`
+}
+
+type OutboxEvent struct {
+	ID             uuid.UUID          `json:"id"`
+	Sequence       int64              `json:"sequence"`
+	AggregateType  string             `json:"aggregate_type"`
+	AggregateID    uuid.UUID          `json:"aggregate_id"`
+	EventType      string             `json:"event_type"`
+	Payload        []byte             `json:"payload"`
+	Attempts       int32              `json:"attempts"`
+	LastError      pgtype.Text        `json:"last_error"`
+	NextAttemptAt  pgtype.Timestamptz `json:"next_attempt_at"`
+	PublishedAt    pgtype.Timestamptz `json:"published_at"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	PublishedSinks []string           `json:"published_sinks"`
+}
+
+type Payout struct {
+	ID                      uuid.UUID          `json:"id"`
+	UserID                  uuid.UUID          `json:"user_id"`
+	WalletID                uuid.UUID          `json:"wallet_id"`
+	DestinationID           pgtype.UUID        `json:"destination_id"`
+	BankCode                string             `json:"bank_code"`
+	AccountNumber           string             `json:"account_number"`
+	AccountName             string             `json:"account_name"`
+	Amount                  pgtype.Numeric     `json:"amount"`
+	Fee                     pgtype.Numeric     `json:"fee"`
+	FeeScheduleID           pgtype.UUID        `json:"fee_schedule_id"`
+	Currency                string             `json:"currency"`
+	Narration               pgtype.Text        `json:"narration"`
+	Status                  PayoutStatusEnum   `json:"status"`
+	HoldID                  uuid.UUID          `json:"hold_id"`
+	Provider                string             `json:"provider"`
+	ProviderReference       pgtype.Text        `json:"provider_reference"`
+	DebitTransactionID      pgtype.UUID        `json:"debit_transaction_id"`
+	SettlementTransactionID pgtype.UUID        `json:"settlement_transaction_id"`
+	ReversalTransactionID   pgtype.UUID        `json:"reversal_transaction_id"`
+	FailureReason           pgtype.Text        `json:"failure_reason"`
+	Checks                  int32              `json:"checks"`
+	NextCheckAt             pgtype.Timestamptz `json:"next_check_at"`
+	IdempotencyKey          string             `json:"idempotency_key"`
+	CompletedAt             pgtype.Timestamptz `json:"completed_at"`
+	CreatedAt               pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt               pgtype.Timestamptz `json:"updated_at"`
+}
+
+type RefreshSession struct {
+	ID            uuid.UUID          `json:"id"`
+	UserID        uuid.UUID          `json:"user_id"`
+	FamilyID      uuid.UUID          `json:"family_id"`
+	ParentID      pgtype.UUID        `json:"parent_id"`
+	Device        string             `json:"device"`
+	IpAddress     string             `json:"ip_address"`
+	UserAgent     string             `json:"user_agent"`
+	SignedInAt    pgtype.Timestamptz `json:"signed_in_at"`
+	ExpiresAt     pgtype.Timestamptz `json:"expires_at"`
+	LastUsedAt    pgtype.Timestamptz `json:"last_used_at"`
+	RotatedAt     pgtype.Timestamptz `json:"rotated_at"`
+	RevokedAt     pgtype.Timestamptz `json:"revoked_at"`
+	RevokedReason pgtype.Text        `json:"revoked_reason"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+}
+
+type ScheduledTransfer struct {
+	ID               uuid.UUID                 `json:"id"`
+	UserID           uuid.UUID                 `json:"user_id"`
+	SenderWalletID   uuid.UUID                 `json:"sender_wallet_id"`
+	ReceiverWalletID uuid.UUID                 `json:"receiver_wallet_id"`
+	Amount           pgtype.Numeric            `json:"amount"`
+	Currency         string                    `json:"currency"`
+	Description      pgtype.Text               `json:"description"`
+	Frequency        ScheduleFrequencyEnum     `json:"frequency"`
+	StartAt          pgtype.Timestamptz        `json:"start_at"`
+	EndAt            pgtype.Timestamptz        `json:"end_at"`
+	MaxRuns          pgtype.Int4               `json:"max_runs"`
+	FailurePolicy    ScheduleFailurePolicyEnum `json:"failure_policy"`
+	MaxRetries       int32                     `json:"max_retries"`
+	Status           ScheduleStatusEnum        `json:"status"`
+	NextOccurrence   int32                     `json:"next_occurrence"`
+	NextRunAt        pgtype.Timestamptz        `json:"next_run_at"`
+	RetryCount       int32                     `json:"retry_count"`
+	RunsCount        int32                     `json:"runs_count"`
+	LastRunAt        pgtype.Timestamptz        `json:"last_run_at"`
+	LastError        pgtype.Text               `json:"last_error"`
+	CreatedAt        pgtype.Timestamptz        `json:"created_at"`
+	UpdatedAt        pgtype.Timestamptz        `json:"updated_at"`
+}
+
+type ScheduledTransferRun struct {
+	ID            uuid.UUID             `json:"id"`
+	ScheduleID    uuid.UUID             `json:"schedule_id"`
+	Occurrence    int32                 `json:"occurrence"`
+	Attempt       int32                 `json:"attempt"`
+	Status        ScheduleRunStatusEnum `json:"status"`
+	TransactionID pgtype.UUID           `json:"transaction_id"`
+	Error         pgtype.Text           `json:"error"`
+	CreatedAt     pgtype.Timestamptz    `json:"created_at"`
+}
+
+type TotpFactor struct {
+	UserID       uuid.UUID          `json:"user_id"`
+	Secret       []byte             `json:"secret"`
+	ConfirmedAt  pgtype.Timestamptz `json:"confirmed_at"`
+	LastUsedStep int64              `json:"last_used_step"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
 }
 
 type Transaction struct {
-	ID               uuid.UUID             `json:"id"`
-	SenderWalletID   pgtype.UUID           `json:"sender_wallet_id"`
-	ReceiverWalletID pgtype.UUID           `json:"receiver_wallet_id"`
-	TransactionType  TransactionTypeEnum   `json:"transaction_type"`
-	Amount           pgtype.Numeric        `json:"amount"`
-	Description      pgtype.Text           `json:"description"`
-	Status           TransactionStatusEnum `json:"status"`
-	Currency         string                `json:"currency"`
-	IdempotencyKey   string                `json:"idempotency_key"`
-	CreatedAt        pgtype.Timestamptz    `json:"created_at"`
-	UpdatedAt        pgtype.Timestamptz    `json:"updated_at"`
+	ID                  uuid.UUID             `json:"id"`
+	SenderWalletID      pgtype.UUID           `json:"sender_wallet_id"`
+	ReceiverWalletID    pgtype.UUID           `json:"receiver_wallet_id"`
+	TransactionType     TransactionTypeEnum   `json:"transaction_type"`
+	Amount              pgtype.Numeric        `json:"amount"`
+	Description         pgtype.Text           `json:"description"`
+	Status              TransactionStatusEnum `json:"status"`
+	Currency            string                `json:"currency"`
+	IdempotencyKey      string                `json:"idempotency_key"`
+	CreatedAt           pgtype.Timestamptz    `json:"created_at"`
+	UpdatedAt           pgtype.Timestamptz    `json:"updated_at"`
+	ParentTransactionID pgtype.UUID           `json:"parent_transaction_id"`
+	Fee                 pgtype.Numeric        `json:"fee"`
+	FeeScheduleID       pgtype.UUID           `json:"fee_schedule_id"`
+}
+
+type TransactionPin struct {
+	UserID         uuid.UUID          `json:"user_id"`
+	PinHash        string             `json:"pin_hash"`
+	FailedAttempts int32              `json:"failed_attempts"`
+	LockedUntil    pgtype.Timestamptz `json:"locked_until"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
 }
 
 type User struct {
+	ID                uuid.UUID          `json:"id"`
+	FullName          string             `json:"full_name"`
+	PhoneNumber       string             `json:"phone_number"`
+	Email             string             `json:"email"`
+	Passwordhash      string             `json:"passwordhash"`
+	Username          string             `json:"username"`
+	AccountNo         string             `json:"account_no"`
+	Nationality       string             `json:"nationality"`
+	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt         pgtype.Timestamptz `json:"updated_at"`
+	CountryCode       string             `json:"country_code"`
+	EmailVerifiedAt   pgtype.Timestamptz `json:"email_verified_at"`
+	KycTier           int16              `json:"kyc_tier"`
+	PasswordChangedAt pgtype.Timestamptz `json:"password_changed_at"`
+}
+
+type VirtualAccount struct {
+	ID            uuid.UUID          `json:"id"`
+	UserID        uuid.UUID          `json:"user_id"`
+	WalletID      uuid.UUID          `json:"wallet_id"`
+	Provider      string             `json:"provider"`
+	AccountNumber string             `json:"account_number"`
+	AccountName   string             `json:"account_name"`
+	BankName      string             `json:"bank_name"`
+	Currency      string             `json:"currency"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+}
+
+type Wallet struct {
+	ID               uuid.UUID          `json:"id"`
+	UserID           pgtype.UUID        `json:"user_id"`
+	Balance          pgtype.Numeric     `json:"balance"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
+	WalletType       WalletTypeEnum     `json:"wallet_type"`
+	Currency         string             `json:"currency"`
+	AvailableBalance pgtype.Numeric     `json:"available_balance"`
+	MaturesAt        pgtype.Timestamptz `json:"matures_at"`
+	SystemCode       pgtype.Text        `json:"system_code"`
+}
+
+type WalletHold struct {
+	ID               uuid.UUID          `json:"id"`
+	WalletID         uuid.UUID          `json:"wallet_id"`
+	ReceiverWalletID uuid.UUID          `json:"receiver_wallet_id"`
+	Amount           pgtype.Numeric     `json:"amount"`
+	CapturedAmount   pgtype.Numeric     `json:"captured_amount"`
+	Currency         string             `json:"currency"`
+	Status           HoldStatusEnum     `json:"status"`
+	Description      pgtype.Text        `json:"description"`
+	IdempotencyKey   string             `json:"idempotency_key"`
+	ExpiresAt        pgtype.Timestamptz `json:"expires_at"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
+}
+
+type WebhookDelivery struct {
+	ID            uuid.UUID                 `json:"id"`
+	EndpointID    uuid.UUID                 `json:"endpoint_id"`
+	EventID       uuid.UUID                 `json:"event_id"`
+	EventType     string                    `json:"event_type"`
+	Payload       []byte                    `json:"payload"`
+	RedeliveryOf  pgtype.UUID               `json:"redelivery_of"`
+	Status        WebhookDeliveryStatusEnum `json:"status"`
+	Attempts      int32                     `json:"attempts"`
+	ResponseCode  pgtype.Int4               `json:"response_code"`
+	LastError     pgtype.Text               `json:"last_error"`
+	NextAttemptAt pgtype.Timestamptz        `json:"next_attempt_at"`
+	DeliveredAt   pgtype.Timestamptz        `json:"delivered_at"`
+	CreatedAt     pgtype.Timestamptz        `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz        `json:"updated_at"`
+}
+
+type WebhookDeliveryAttempt struct {
 	ID           uuid.UUID          `json:"id"`
-	FullName     string             `json:"full_name"`
-	PhoneNumber  string             `json:"phone_number"`
-	Email        string             `json:"email"`
-	Passwordhash string             `json:"passwordhash"`
-	Username     string             `json:"username"`
-	AccountNo    string             `json:"account_no"`
-	Nationality  string             `json:"nationality"`
+	DeliveryID   uuid.UUID          `json:"delivery_id"`
+	Attempt      int32              `json:"attempt"`
+	ResponseCode pgtype.Int4        `json:"response_code"`
+	ResponseBody pgtype.Text        `json:"response_body"`
+	Error        pgtype.Text        `json:"error"`
+	DurationMs   int32              `json:"duration_ms"`
 	CreatedAt    pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
-	CountryCode  string             `json:"country_code"`
 }
 
-type Wallet struct {
-	ID         uuid.UUID          `json:"id"`
-	UserID     pgtype.UUID        `json:"user_id"`
-	Balance    pgtype.Numeric     `json:"balance"`
-	CreatedAt  pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
-	WalletType WalletTypeEnum     `json:"wallet_type"`
-	Currency   string             `json:"currency"`
+type WebhookEndpoint struct {
+	ID                      uuid.UUID                 `json:"id"`
+	UserID                  uuid.UUID                 `json:"user_id"`
+	Url                     string                    `json:"url"`
+	Description             pgtype.Text               `json:"description"`
+	EventTypes              []string                  `json:"event_types"`
+	Secret                  string                    `json:"secret"`
+	PreviousSecret          pgtype.Text               `json:"previous_secret"`
+	PreviousSecretExpiresAt pgtype.Timestamptz        `json:"previous_secret_expires_at"`
+	Status                  WebhookEndpointStatusEnum `json:"status"`
+	ConsecutiveFailures     int32                     `json:"consecutive_failures"`
+	DisabledReason          pgtype.Text               `json:"disabled_reason"`
+	DisabledAt              pgtype.Timestamptz        `json:"disabled_at"`
+	CreatedAt               pgtype.Timestamptz        `json:"created_at"`
+	UpdatedAt               pgtype.Timestamptz        `json:"updated_at"`
 }
@@ -18,14 +18,16 @@ INSERT INTO otps (
     code,
     purpose,
     expires_at,
-    used
+    used,
+    reference
 ) VALUES (
     $1,
     $2,
     $3,
     $4,
-    $5
-) RETURNING id, user_id, code, purpose, expires_at, used, created_at
+    $5,
+    $6
+) RETURNING id, user_id, code, purpose, expires_at, used, created_at, attempts, reference
 `
 
 type CreateOTPParams struct {
@@ -34,6 +36,7 @@ type CreateOTPParams struct {
 	Purpose   string             `json:"purpose"`
 	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
 	Used      pgtype.Bool        `json:"used"`
+	Reference string             `json:"reference"`
 }
 
 func (q *Queries) CreateOTP(ctx context.Context, arg CreateOTPParams) (Otp, error) {
@@ -43,6 +46,7 @@ func (q *Queries) CreateOTP(ctx context.Context, arg CreateOTPParams) (Otp, erro
 		arg.Purpose,
 		arg.ExpiresAt,
 		arg.Used,
+		arg.Reference,
 	)
 	var i Otp
 	err := row.Scan(
@@ -53,6 +57,141 @@ func (q *Queries) CreateOTP(ctx context.Context, arg CreateOTPParams) (Otp, erro
 		&i.ExpiresAt,
 		&i.Used,
 		&i.CreatedAt,
+		&i.Attempts,
+		&i.Reference,
 	)
 	return i, err
 }
+
+const getLatestOTPByPurpose = `-- name: GetLatestOTPByPurpose :one
+SELECT id, user_id, code, purpose, expires_at, used, created_at, attempts, reference FROM otps
+WHERE user_id = $1 AND purpose = $2
+ORDER BY created_at DESC
+LIMIT 1
+`
+
+type GetLatestOTPByPurposeParams struct {
+	UserID  uuid.UUID `json:"user_id"`
+	Purpose string    `json:"purpose"`
+}
+
+func (q *Queries) GetLatestOTPByPurpose(ctx context.Context, arg GetLatestOTPByPurposeParams) (Otp, error) {
+	row := q.db.QueryRow(ctx, getLatestOTPByPurpose, arg.UserID, arg.Purpose)
+	var i Otp
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Code,
+		&i.Purpose,
+		&i.ExpiresAt,
+		&i.Used,
+		&i.CreatedAt,
+		&i.Attempts,
+		&i.Reference,
+	)
+	return i, err
+}
+
+const getLatestOTPForUpdate = `-- name: GetLatestOTPForUpdate :one
+SELECT id, user_id, code, purpose, expires_at, used, created_at, attempts, reference FROM otps
+WHERE user_id = $1 AND purpose = $2 AND used = false
+ORDER BY created_at DESC
+LIMIT 1
+FOR UPDATE
+`
+
+type GetLatestOTPForUpdateParams struct {
+	UserID  uuid.UUID `json:"user_id"`
+	Purpose string    `json:"purpose"`
+}
+
+func (q *Queries) GetLatestOTPForUpdate(ctx context.Context, arg GetLatestOTPForUpdateParams) (Otp, error) {
+	row := q.db.QueryRow(ctx, getLatestOTPForUpdate, arg.UserID, arg.Purpose)
+	var i Otp
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Code,
+		&i.Purpose,
+		&i.ExpiresAt,
+		&i.Used,
+		&i.CreatedAt,
+		&i.Attempts,
+		&i.Reference,
+	)
+	return i, err
+}
+
+const getOTPByIdForUpdate = `-- name: GetOTPByIdForUpdate :one
+SELECT id, user_id, code, purpose, expires_at, used, created_at, attempts, reference FROM otps
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetOTPByIdForUpdate(ctx context.Context, id uuid.UUID) (Otp, error) {
+	row := q.db.QueryRow(ctx, getOTPByIdForUpdate, id)
+	var i Otp
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Code,
+		&i.Purpose,
+		&i.ExpiresAt,
+		&i.Used,
+		&i.CreatedAt,
+		&i.Attempts,
+		&i.Reference,
+	)
+	return i, err
+}
+
+const incrementOTPAttempts = `-- name: IncrementOTPAttempts :one
+UPDATE otps
+SET attempts = attempts + 1
+WHERE id = $1
+RETURNING id, user_id, code, purpose, expires_at, used, created_at, attempts, reference
+`
+
+func (q *Queries) IncrementOTPAttempts(ctx context.Context, id uuid.UUID) (Otp, error) {
+	row := q.db.QueryRow(ctx, incrementOTPAttempts, id)
+	var i Otp
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Code,
+		&i.Purpose,
+		&i.ExpiresAt,
+		&i.Used,
+		&i.CreatedAt,
+		&i.Attempts,
+		&i.Reference,
+	)
+	return i, err
+}
+
+const invalidateOTPs = `-- name: InvalidateOTPs :exec
+UPDATE otps
+SET used = true
+WHERE user_id = $1 AND purpose = $2 AND used = false
+`
+
+type InvalidateOTPsParams struct {
+	UserID  uuid.UUID `json:"user_id"`
+	Purpose string    `json:"purpose"`
+}
+
+func (q *Queries) InvalidateOTPs(ctx context.Context, arg InvalidateOTPsParams) error {
+	_, err := q.db.Exec(ctx, invalidateOTPs, arg.UserID, arg.Purpose)
+	return err
+}
+
+const markOTPUsed = `-- name: MarkOTPUsed :exec
+UPDATE otps
+SET used = true
+WHERE id = $1
+`
+
+func (q *Queries) MarkOTPUsed(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, markOTPUsed, id)
+	return err
+}
@@ -12,28 +12,172 @@ import (
 )
 
 type Querier interface {
+	AdvanceScheduledTransfer(ctx context.Context, arg AdvanceScheduledTransferParams) (ScheduledTransfer, error)
+	ClaimRelayableOutboxEvents(ctx context.Context, arg ClaimRelayableOutboxEventsParams) ([]OutboxEvent, error)
+	CloseFixedDeposit(ctx context.Context, arg CloseFixedDepositParams) (FixedDeposit, error)
+	ConfirmTOTPFactor(ctx context.Context, arg ConfirmTOTPFactorParams) (TotpFactor, error)
+	CountCompletedTransfersToWallet(ctx context.Context, arg CountCompletedTransfersToWalletParams) (int64, error)
+	CountUnusedRecoveryCodes(ctx context.Context, userID uuid.UUID) (int64, error)
+	CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) (AuditEvent, error)
+	CreateBankDestination(ctx context.Context, arg CreateBankDestinationParams) (BankDestination, error)
+	CreateFeeSchedule(ctx context.Context, arg CreateFeeScheduleParams) (FeeSchedule, error)
+	CreateFeeScheduleTier(ctx context.Context, arg CreateFeeScheduleTierParams) error
+	CreateFixedDeposit(ctx context.Context, arg CreateFixedDepositParams) (FixedDeposit, error)
+	CreateFundingDeposit(ctx context.Context, arg CreateFundingDepositParams) (FundingDeposit, error)
+	CreateFxQuote(ctx context.Context, arg CreateFxQuoteParams) (FxQuote, error)
+	CreateFxRate(ctx context.Context, arg CreateFxRateParams) (FxRate, error)
+	CreateInterestAccrual(ctx context.Context, arg CreateInterestAccrualParams) (InterestAccrual, error)
 	CreateLedger(ctx context.Context, arg CreateLedgerParams) (Ledger, error)
 	CreateOTP(ctx context.Context, arg CreateOTPParams) (Otp, error)
+	CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) (OutboxEvent, error)
+	CreatePayout(ctx context.Context, arg CreatePayoutParams) (Payout, error)
+	CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) error
+	CreateRefreshSession(ctx context.Context, arg CreateRefreshSessionParams) (RefreshSession, error)
+	CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error)
+	CreateScheduledTransferRun(ctx context.Context, arg CreateScheduledTransferRunParams) (ScheduledTransferRun, error)
+	CreateSystemWallet(ctx context.Context, arg CreateSystemWalletParams) (Wallet, error)
 	CreateTransaction(ctx context.Context, arg CreateTransactionParams) (Transaction, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateVirtualAccount(ctx context.Context, arg CreateVirtualAccountParams) (VirtualAccount, error)
 	CreateWallet(ctx context.Context, arg CreateWalletParams) (Wallet, error)
+	CreateWalletHold(ctx context.Context, arg CreateWalletHoldParams) (WalletHold, error)
+	CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) (WebhookDelivery, error)
+	CreateWebhookDeliveryAttempt(ctx context.Context, arg CreateWebhookDeliveryAttemptParams) (WebhookDeliveryAttempt, error)
+	CreateWebhookEndpoint(ctx context.Context, arg CreateWebhookEndpointParams) (WebhookEndpoint, error)
+	DeactivateActiveFeeSchedule(ctx context.Context, arg DeactivateActiveFeeScheduleParams) error
+	DeactivateFeeSchedule(ctx context.Context, id uuid.UUID) (FeeSchedule, error)
+	DeleteBankDestination(ctx context.Context, id uuid.UUID) error
+	DeleteRecoveryCodes(ctx context.Context, userID uuid.UUID) error
+	DeleteTOTPFactor(ctx context.Context, userID uuid.UUID) error
 	DeleteUser(ctx context.Context, id uuid.UUID) error
+	DeleteWebhookEndpoint(ctx context.Context, id uuid.UUID) error
+	DisableWebhookEndpoint(ctx context.Context, arg DisableWebhookEndpointParams) (WebhookEndpoint, error)
+	EnableWebhookEndpoint(ctx context.Context, id uuid.UUID) (WebhookEndpoint, error)
+	FailFundingDeposit(ctx context.Context, arg FailFundingDepositParams) (FundingDeposit, error)
+	FailWebhookDelivery(ctx context.Context, arg FailWebhookDeliveryParams) error
+	FindFeeSchedule(ctx context.Context, arg FindFeeScheduleParams) (FeeSchedule, error)
+	GetActiveFeeSchedules(ctx context.Context) ([]FeeSchedule, error)
+	GetActiveRefreshSessionsByUserId(ctx context.Context, userID uuid.UUID) ([]RefreshSession, error)
+	GetActiveWebhookEndpointsForEvent(ctx context.Context, arg GetActiveWebhookEndpointsForEventParams) ([]WebhookEndpoint, error)
+	GetBankDestinationById(ctx context.Context, id uuid.UUID) (BankDestination, error)
+	GetBankDestinationsByUserId(ctx context.Context, userID uuid.UUID) ([]BankDestination, error)
+	GetChartOfAccounts(ctx context.Context) ([]ChartOfAccount, error)
+	GetCompensatedAmountByParentId(ctx context.Context, parentTransactionID pgtype.UUID) (pgtype.Numeric, error)
+	GetDuePayoutIds(ctx context.Context, arg GetDuePayoutIdsParams) ([]uuid.UUID, error)
+	GetDueScheduledTransferIds(ctx context.Context, limit int32) ([]uuid.UUID, error)
+	GetExpiredActiveHolds(ctx context.Context, limit int32) ([]WalletHold, error)
+	GetFeeScheduleById(ctx context.Context, id uuid.UUID) (FeeSchedule, error)
+	GetFeeScheduleTiers(ctx context.Context, scheduleID uuid.UUID) ([]FeeScheduleTier, error)
+	GetFixedDepositById(ctx context.Context, id uuid.UUID) (FixedDeposit, error)
+	GetFixedDepositByIdempotencyKey(ctx context.Context, idempotencyKey string) (FixedDeposit, error)
+	GetFixedDepositForUpdate(ctx context.Context, id uuid.UUID) (FixedDeposit, error)
+	GetFixedDepositIdsDueForAccrual(ctx context.Context, arg GetFixedDepositIdsDueForAccrualParams) ([]uuid.UUID, error)
+	GetFixedDepositsByUserId(ctx context.Context, userID uuid.UUID) ([]FixedDeposit, error)
+	GetFundingDepositById(ctx context.Context, id uuid.UUID) (FundingDeposit, error)
+	GetFundingDepositForUpdate(ctx context.Context, arg GetFundingDepositForUpdateParams) (FundingDeposit, error)
+	GetFundingDepositsByUserId(ctx context.Context, arg GetFundingDepositsByUserIdParams) ([]FundingDeposit, error)
+	GetFxQuoteForUpdate(ctx context.Context, id uuid.UUID) (FxQuote, error)
+	GetInterestAccrualsByDate(ctx context.Context, accrualDate pgtype.Date) ([]InterestAccrual, error)
+	GetInterestAccrualsByWalletId(ctx context.Context, arg GetInterestAccrualsByWalletIdParams) ([]InterestAccrual, error)
+	GetLatestFxRate(ctx context.Context, arg GetLatestFxRateParams) (FxRate, error)
+	GetLatestFxRates(ctx context.Context) ([]FxRate, error)
+	GetLatestOTPByPurpose(ctx context.Context, arg GetLatestOTPByPurposeParams) (Otp, error)
+	GetLatestOTPForUpdate(ctx context.Context, arg GetLatestOTPForUpdateParams) (Otp, error)
+	GetLatestOutboxSequence(ctx context.Context) (int64, error)
+	GetOTPByIdForUpdate(ctx context.Context, id uuid.UUID) (Otp, error)
+	GetOutboxEventBySequence(ctx context.Context, sequence int64) (OutboxEvent, error)
+	GetPayoutById(ctx context.Context, id uuid.UUID) (Payout, error)
+	GetPayoutByIdempotencyKey(ctx context.Context, idempotencyKey string) (Payout, error)
+	GetPayoutForUpdate(ctx context.Context, id uuid.UUID) (Payout, error)
+	GetPayoutsByUserId(ctx context.Context, arg GetPayoutsByUserIdParams) ([]Payout, error)
 	GetPendingTransactionsByWalletId(ctx context.Context, senderWalletID pgtype.UUID) ([]Transaction, error)
+	GetRefreshSessionForUpdate(ctx context.Context, id uuid.UUID) (RefreshSession, error)
+	GetSavingsWalletIdsDueForAccrual(ctx context.Context, arg GetSavingsWalletIdsDueForAccrualParams) ([]uuid.UUID, error)
+	GetScheduledTransferById(ctx context.Context, id uuid.UUID) (ScheduledTransfer, error)
+	GetScheduledTransferForUpdate(ctx context.Context, id uuid.UUID) (ScheduledTransfer, error)
+	GetScheduledTransferRuns(ctx context.Context, arg GetScheduledTransferRunsParams) ([]ScheduledTransferRun, error)
+	GetScheduledTransfersByUserId(ctx context.Context, userID uuid.UUID) ([]ScheduledTransfer, error)
+	GetSessionFamilySignedInAt(ctx context.Context, familyID uuid.UUID) (pgtype.Timestamptz, error)
+	GetSystemWallet(ctx context.Context, arg GetSystemWalletParams) (Wallet, error)
+	GetSystemWallets(ctx context.Context) ([]Wallet, error)
+	GetTOTPFactor(ctx context.Context, userID uuid.UUID) (TotpFactor, error)
+	GetTOTPFactorForUpdate(ctx context.Context, userID uuid.UUID) (TotpFactor, error)
 	GetTransactionById(ctx context.Context, id uuid.UUID) (Transaction, error)
+	GetTransactionByIdForUpdate(ctx context.Context, id uuid.UUID) (Transaction, error)
 	GetTransactionByIdempotencyKey(ctx context.Context, idempotencyKey string) (Transaction, error)
+	GetTransactionPin(ctx context.Context, userID uuid.UUID) (TransactionPin, error)
+	GetTransactionPinForUpdate(ctx context.Context, userID uuid.UUID) (TransactionPin, error)
+	GetTransactionsByParentId(ctx context.Context, parentTransactionID pgtype.UUID) ([]Transaction, error)
 	GetTransactionsByWalletId(ctx context.Context, arg GetTransactionsByWalletIdParams) ([]Transaction, error)
+	GetTrialBalance(ctx context.Context, asOf pgtype.Timestamptz) ([]GetTrialBalanceRow, error)
 	GetUserBalance(ctx context.Context, walletID uuid.UUID) (interface{}, error)
 	GetUserByAccountNo(ctx context.Context, accountNo string) (User, error)
 	GetUserByEmail(ctx context.Context, email string) (User, error)
 	GetUserByID(ctx context.Context, id uuid.UUID) (User, error)
 	GetUserByUsername(ctx context.Context, username string) (User, error)
+	GetVirtualAccountByNumber(ctx context.Context, arg GetVirtualAccountByNumberParams) (VirtualAccount, error)
+	GetVirtualAccountByWallet(ctx context.Context, arg GetVirtualAccountByWalletParams) (VirtualAccount, error)
+	GetVirtualAccountsByUserId(ctx context.Context, userID uuid.UUID) ([]VirtualAccount, error)
 	GetWalletByAccountNo(ctx context.Context, accountNo string) (GetWalletByAccountNoRow, error)
+	GetWalletByAccountNoAndCurrency(ctx context.Context, arg GetWalletByAccountNoAndCurrencyParams) (GetWalletByAccountNoAndCurrencyRow, error)
 	GetWalletById(ctx context.Context, id uuid.UUID) (Wallet, error)
+	GetWalletByUserIdAndType(ctx context.Context, arg GetWalletByUserIdAndTypeParams) (Wallet, error)
+	GetWalletHoldById(ctx context.Context, id uuid.UUID) (WalletHold, error)
+	GetWalletHoldByIdempotencyKey(ctx context.Context, idempotencyKey string) (WalletHold, error)
+	GetWalletHoldForUpdate(ctx context.Context, id uuid.UUID) (WalletHold, error)
+	GetWalletIdsWithUncapitalisedInterest(ctx context.Context, arg GetWalletIdsWithUncapitalisedInterestParams) ([]uuid.UUID, error)
+	GetWalletStreamEvents(ctx context.Context, arg GetWalletStreamEventsParams) ([]OutboxEvent, error)
 	GetWalletsAndLockByWalletIds(ctx context.Context, arg GetWalletsAndLockByWalletIdsParams) ([]GetWalletsAndLockByWalletIdsRow, error)
 	GetWalletsByUserId(ctx context.Context, userID pgtype.UUID) ([]Wallet, error)
+	GetWalletsForUpdate(ctx context.Context, ids []uuid.UUID) ([]GetWalletsForUpdateRow, error)
+	GetWebhookDeliveriesByEndpointId(ctx context.Context, arg GetWebhookDeliveriesByEndpointIdParams) ([]WebhookDelivery, error)
+	GetWebhookDeliveryAttemptsByDeliveryId(ctx context.Context, deliveryID uuid.UUID) ([]WebhookDeliveryAttempt, error)
+	GetWebhookDeliveryByEvent(ctx context.Context, arg GetWebhookDeliveryByEventParams) (WebhookDelivery, error)
+	GetWebhookDeliveryById(ctx context.Context, id uuid.UUID) (WebhookDelivery, error)
+	GetWebhookDeliveryForUpdate(ctx context.Context, id uuid.UUID) (WebhookDelivery, error)
+	GetWebhookEndpointById(ctx context.Context, id uuid.UUID) (WebhookEndpoint, error)
+	GetWebhookEndpointsByUserId(ctx context.Context, userID uuid.UUID) ([]WebhookEndpoint, error)
+	IncrementOTPAttempts(ctx context.Context, id uuid.UUID) (Otp, error)
+	InvalidateOTPs(ctx context.Context, arg InvalidateOTPsParams) error
+	IsSessionFamilyActive(ctx context.Context, arg IsSessionFamilyActiveParams) (bool, error)
+	ListSavingsWalletsForInterest(ctx context.Context, arg ListSavingsWalletsForInterestParams) ([]ListSavingsWalletsForInterestRow, error)
+	MarkFxQuoteConverted(ctx context.Context, arg MarkFxQuoteConvertedParams) error
+	MarkInterestCapitalised(ctx context.Context, arg MarkInterestCapitalisedParams) error
+	MarkOTPUsed(ctx context.Context, id uuid.UUID) error
+	MarkOutboxEventFailed(ctx context.Context, arg MarkOutboxEventFailedParams) error
+	MarkOutboxEventPublished(ctx context.Context, id uuid.UUID) error
+	MarkPayoutFailed(ctx context.Context, arg MarkPayoutFailedParams) (Payout, error)
+	MarkPayoutForReview(ctx context.Context, arg MarkPayoutForReviewParams) (Payout, error)
+	MarkPayoutProcessing(ctx context.Context, arg MarkPayoutProcessingParams) (Payout, error)
+	MarkPayoutReversed(ctx context.Context, arg MarkPayoutReversedParams) (Payout, error)
+	MarkPayoutSucceeded(ctx context.Context, arg MarkPayoutSucceededParams) (Payout, error)
+	MarkUserEmailVerified(ctx context.Context, id uuid.UUID) (User, error)
+	RecordWebhookEndpointFailure(ctx context.Context, id uuid.UUID) (WebhookEndpoint, error)
+	RecordWebhookEndpointSuccess(ctx context.Context, id uuid.UUID) error
+	RefreshWalletMaturity(ctx context.Context, walletID uuid.UUID) error
+	RevokeRefreshSessionFamily(ctx context.Context, arg RevokeRefreshSessionFamilyParams) error
+	RevokeUserRefreshSessions(ctx context.Context, arg RevokeUserRefreshSessionsParams) error
+	RotateRefreshSession(ctx context.Context, id uuid.UUID) error
+	RotateWebhookEndpointSecret(ctx context.Context, arg RotateWebhookEndpointSecretParams) (WebhookEndpoint, error)
+	SchedulePayoutCheck(ctx context.Context, arg SchedulePayoutCheckParams) (Payout, error)
+	SettleFundingDeposit(ctx context.Context, arg SettleFundingDepositParams) (FundingDeposit, error)
+	SumUncapitalisedInterest(ctx context.Context, arg SumUncapitalisedInterestParams) (pgtype.Numeric, error)
+	UpdateFixedDepositAccrual(ctx context.Context, arg UpdateFixedDepositAccrualParams) error
+	UpdateScheduledTransfer(ctx context.Context, arg UpdateScheduledTransferParams) (ScheduledTransfer, error)
+	UpdateTOTPLastUsedStep(ctx context.Context, arg UpdateTOTPLastUsedStepParams) error
+	UpdateTransactionPinAttempts(ctx context.Context, arg UpdateTransactionPinAttemptsParams) error
 	UpdateTransactionStatus(ctx context.Context, arg UpdateTransactionStatusParams) error
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
+	UpdateWalletAvailableBalance(ctx context.Context, arg UpdateWalletAvailableBalanceParams) error
 	UpdateWalletBalance(ctx context.Context, arg UpdateWalletBalanceParams) error
+	UpdateWalletHoldCapture(ctx context.Context, arg UpdateWalletHoldCaptureParams) (WalletHold, error)
+	UpdateWalletHoldExpiry(ctx context.Context, arg UpdateWalletHoldExpiryParams) (WalletHold, error)
+	UpdateWalletHoldStatus(ctx context.Context, arg UpdateWalletHoldStatusParams) (WalletHold, error)
+	UpdateWebhookDeliveryResult(ctx context.Context, arg UpdateWebhookDeliveryResultParams) (WebhookDelivery, error)
+	UpdateWebhookEndpoint(ctx context.Context, arg UpdateWebhookEndpointParams) (WebhookEndpoint, error)
+	UpsertPendingTOTPFactor(ctx context.Context, arg UpsertPendingTOTPFactorParams) (TotpFactor, error)
+	UpsertTransactionPin(ctx context.Context, arg UpsertTransactionPinParams) (TransactionPin, error)
+	UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,17 @@
+-- name: GetChartOfAccounts :many
+SELECT * FROM chart_of_accounts ORDER BY code;
+
+-- name: GetSystemWallets :many
+SELECT * FROM wallets WHERE system_code IS NOT NULL ORDER BY system_code, currency;
+
+-- name: GetTrialBalance :many
+SELECT
+    COALESCE(wallets.system_code, 'customer_wallets')::text AS account_code,
+    ledgers.currency,
+    COALESCE(SUM(ledgers.amount) FILTER (WHERE ledgers.entry_type = 'debit'), 0)::numeric AS debits,
+    COALESCE(SUM(ledgers.amount) FILTER (WHERE ledgers.entry_type = 'credit'), 0)::numeric AS credits
+FROM ledgers
+JOIN wallets ON wallets.id = ledgers.wallet_id
+WHERE ledgers.created_at < sqlc.arg(as_of)::timestamptz
+GROUP BY 1, 2
+ORDER BY 1, 2;
@@ -0,0 +1,11 @@
+-- name: CreateAuditEvent :one
+INSERT INTO audit_events (
+    user_id,
+    event,
+    ip_address,
+    user_agent,
+    metadata
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+RETURNING *;
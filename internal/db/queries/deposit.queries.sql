@@ -0,0 +1,48 @@
+-- name: CreateFixedDeposit :one
+INSERT INTO fixed_deposits (
+    user_id,
+    wallet_id,
+    payout_wallet_id,
+    parent_deposit_id,
+    principal,
+    annual_rate,
+    tenor_days,
+    currency,
+    maturity_action,
+    start_date,
+    maturity_date,
+    accrued_through,
+    idempotency_key
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+)
+RETURNING *;
+
+-- name: GetFixedDepositById :one
+SELECT * FROM fixed_deposits WHERE id = $1;
+
+-- name: GetFixedDepositForUpdate :one
+SELECT * FROM fixed_deposits WHERE id = $1 FOR UPDATE;
+
+-- name: GetFixedDepositByIdempotencyKey :one
+SELECT * FROM fixed_deposits WHERE idempotency_key = $1;
+
+-- name: GetFixedDepositsByUserId :many
+SELECT * FROM fixed_deposits WHERE user_id = $1 ORDER BY created_at DESC;
+
+-- name: GetFixedDepositIdsDueForAccrual :many
+SELECT id FROM fixed_deposits
+WHERE status = 'active' AND accrued_through < sqlc.arg(as_of)::date
+ORDER BY accrued_through
+LIMIT sqlc.arg(batch_size);
+
+-- name: UpdateFixedDepositAccrual :exec
+UPDATE fixed_deposits
+SET accrued_interest = $1, accrued_through = $2, updated_at = NOW()
+WHERE id = $3;
+
+-- name: CloseFixedDeposit :one
+UPDATE fixed_deposits
+SET status = $1, penalty = $2, closed_at = NOW(), updated_at = NOW()
+WHERE id = $3
+RETURNING *;
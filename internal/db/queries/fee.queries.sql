@@ -0,0 +1,37 @@
+-- name: CreateFeeSchedule :one
+INSERT INTO fee_schedules (transaction_type, currency, kyc_tier, fee_type, flat_amount, rate, min_fee, max_fee)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: CreateFeeScheduleTier :exec
+INSERT INTO fee_schedule_tiers (schedule_id, floor, flat_amount, rate) VALUES ($1, $2, $3, $4);
+
+-- name: GetFeeScheduleById :one
+SELECT * FROM fee_schedules WHERE id = $1;
+
+-- name: GetFeeScheduleTiers :many
+SELECT * FROM fee_schedule_tiers WHERE schedule_id = $1 ORDER BY floor;
+
+-- name: GetActiveFeeSchedules :many
+SELECT * FROM fee_schedules WHERE active ORDER BY transaction_type, currency, kyc_tier NULLS FIRST;
+
+-- name: FindFeeSchedule :one
+SELECT * FROM fee_schedules
+WHERE active
+  AND transaction_type = sqlc.arg(transaction_type)
+  AND currency = sqlc.arg(currency)
+  AND (kyc_tier = sqlc.arg(kyc_tier)::smallint OR kyc_tier IS NULL)
+ORDER BY kyc_tier NULLS LAST
+LIMIT 1;
+
+-- name: DeactivateFeeSchedule :one
+UPDATE fee_schedules SET active = FALSE, deactivated_at = NOW()
+WHERE id = $1 AND active
+RETURNING *;
+
+-- name: DeactivateActiveFeeSchedule :exec
+UPDATE fee_schedules SET active = FALSE, deactivated_at = NOW()
+WHERE active
+  AND transaction_type = sqlc.arg(transaction_type)
+  AND currency = sqlc.arg(currency)
+  AND COALESCE(kyc_tier, 0) = sqlc.arg(kyc_tier)::smallint;
@@ -0,0 +1,45 @@
+-- name: CreateVirtualAccount :one
+INSERT INTO virtual_accounts (user_id, wallet_id, provider, account_number, account_name, bank_name, currency)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetVirtualAccountByWallet :one
+SELECT * FROM virtual_accounts WHERE wallet_id = $1 AND provider = $2;
+
+-- name: GetVirtualAccountByNumber :one
+SELECT * FROM virtual_accounts WHERE provider = $1 AND account_number = $2;
+
+-- name: GetVirtualAccountsByUserId :many
+SELECT * FROM virtual_accounts WHERE user_id = $1 ORDER BY created_at;
+
+-- name: CreateFundingDeposit :one
+INSERT INTO funding_deposits (
+    provider, provider_reference, virtual_account_id, wallet_id, amount, currency,
+    sender_name, sender_account_number, sender_bank
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT (provider, provider_reference) DO NOTHING
+RETURNING *;
+
+-- name: GetFundingDepositForUpdate :one
+SELECT * FROM funding_deposits WHERE provider = $1 AND provider_reference = $2 FOR UPDATE;
+
+-- name: GetFundingDepositById :one
+SELECT * FROM funding_deposits WHERE id = $1;
+
+-- name: GetFundingDepositsByUserId :many
+SELECT * FROM funding_deposits
+WHERE virtual_account_id IN (SELECT id FROM virtual_accounts WHERE user_id = sqlc.arg(user_id)::uuid)
+ORDER BY created_at DESC
+LIMIT sqlc.arg(batch_size);
+
+-- name: SettleFundingDeposit :one
+UPDATE funding_deposits
+SET status = 'settled', transaction_id = $2, settled_at = NOW(), updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: FailFundingDeposit :one
+UPDATE funding_deposits
+SET status = 'failed', failure_reason = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
@@ -0,0 +1,39 @@
+-- name: CreateFxRate :one
+INSERT INTO fx_rates (base_currency, quote_currency, rate, source) VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetLatestFxRate :one
+SELECT * FROM fx_rates
+WHERE base_currency = $1 AND quote_currency = $2
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: GetLatestFxRates :many
+SELECT * FROM fx_rates
+WHERE id IN (
+    SELECT DISTINCT ON (base_currency, quote_currency) id FROM fx_rates
+    ORDER BY base_currency, quote_currency, created_at DESC
+)
+ORDER BY base_currency, quote_currency;
+
+-- name: CreateFxQuote :one
+INSERT INTO fx_quotes (
+    user_id,
+    from_wallet_id,
+    to_wallet_id,
+    from_currency,
+    to_currency,
+    sell_amount,
+    buy_amount,
+    mid_rate,
+    rate,
+    spread,
+    expires_at
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING *;
+
+-- name: GetFxQuoteForUpdate :one
+SELECT * FROM fx_quotes WHERE id = $1 FOR UPDATE;
+
+-- name: MarkFxQuoteConverted :exec
+UPDATE fx_quotes SET transaction_id = $1 WHERE id = $2;
@@ -0,0 +1,39 @@
+-- name: CreateWalletHold :one
+INSERT INTO wallet_holds (wallet_id, receiver_wallet_id, amount, currency, description, idempotency_key, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetWalletHoldForUpdate :one
+SELECT * FROM wallet_holds WHERE id = $1 FOR UPDATE;
+
+-- name: GetWalletHoldByIdempotencyKey :one
+SELECT * FROM wallet_holds WHERE idempotency_key = $1;
+
+-- name: UpdateWalletHoldCapture :one
+UPDATE wallet_holds
+SET captured_amount = $1, status = $2, updated_at = NOW()
+WHERE id = $3
+RETURNING *;
+
+-- name: UpdateWalletHoldStatus :one
+UPDATE wallet_holds
+SET status = $1, updated_at = NOW()
+WHERE id = $2
+RETURNING *;
+
+-- name: UpdateWalletHoldExpiry :one
+UPDATE wallet_holds
+SET expires_at = $1, updated_at = NOW()
+WHERE id = $2
+RETURNING *;
+
+-- name: GetExpiredActiveHolds :many
+SELECT * FROM wallet_holds
+WHERE status = 'active' AND expires_at <= NOW()
+AND NOT EXISTS (SELECT 1 FROM payouts WHERE payouts.hold_id = wallet_holds.id)
+ORDER BY expires_at
+LIMIT $1
+FOR UPDATE SKIP LOCKED;
+
+-- name: GetWalletHoldById :one
+SELECT * FROM wallet_holds WHERE id = $1;
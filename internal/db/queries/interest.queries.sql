@@ -0,0 +1,58 @@
+-- name: GetSavingsWalletIdsDueForAccrual :many
+SELECT id FROM wallets
+WHERE wallet_type = 'savings'
+  AND balance > 0
+  AND created_at < sqlc.arg(created_before)::timestamptz
+  AND NOT EXISTS (
+      SELECT 1 FROM interest_accruals
+      WHERE interest_accruals.wallet_id = wallets.id
+        AND interest_accruals.accrual_date = sqlc.arg(accrual_date)::date
+  )
+ORDER BY id
+LIMIT sqlc.arg(batch_size);
+
+-- name: ListSavingsWalletsForInterest :many
+SELECT id, balance, currency FROM wallets
+WHERE wallet_type = 'savings' AND balance > 0 AND id > sqlc.arg(after_id)::uuid
+ORDER BY id
+LIMIT sqlc.arg(batch_size);
+
+-- name: CreateInterestAccrual :one
+INSERT INTO interest_accruals (
+    wallet_id,
+    accrual_date,
+    balance,
+    annual_rate,
+    day_count,
+    amount,
+    currency
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+)
+ON CONFLICT (wallet_id, accrual_date) DO NOTHING
+RETURNING *;
+
+-- name: GetInterestAccrualsByDate :many
+SELECT * FROM interest_accruals WHERE accrual_date = $1;
+
+-- name: GetInterestAccrualsByWalletId :many
+SELECT * FROM interest_accruals
+WHERE wallet_id = $1
+ORDER BY accrual_date DESC
+LIMIT $2;
+
+-- name: GetWalletIdsWithUncapitalisedInterest :many
+SELECT wallet_id FROM interest_accruals
+WHERE capitalised_at IS NULL AND accrual_date < sqlc.arg(before)::date
+GROUP BY wallet_id
+ORDER BY wallet_id
+LIMIT sqlc.arg(batch_size);
+
+-- name: SumUncapitalisedInterest :one
+SELECT COALESCE(SUM(amount), 0)::numeric AS total FROM interest_accruals
+WHERE wallet_id = sqlc.arg(wallet_id) AND capitalised_at IS NULL AND accrual_date < sqlc.arg(before)::date;
+
+-- name: MarkInterestCapitalised :exec
+UPDATE interest_accruals
+SET transaction_id = sqlc.narg(transaction_id), capitalised_at = NOW()
+WHERE wallet_id = sqlc.arg(wallet_id) AND capitalised_at IS NULL AND accrual_date < sqlc.arg(before)::date;
@@ -0,0 +1,61 @@
+-- name: UpsertPendingTOTPFactor :one
+INSERT INTO totp_factors (
+    user_id,
+    secret
+) VALUES (
+    $1, $2
+)
+ON CONFLICT (user_id) DO UPDATE
+SET secret = EXCLUDED.secret,
+    confirmed_at = NULL,
+    last_used_step = 0,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: GetTOTPFactor :one
+SELECT * FROM totp_factors
+WHERE user_id = $1;
+
+-- name: GetTOTPFactorForUpdate :one
+SELECT * FROM totp_factors
+WHERE user_id = $1
+FOR UPDATE;
+
+-- name: ConfirmTOTPFactor :one
+UPDATE totp_factors
+SET confirmed_at = NOW(),
+    last_used_step = $2,
+    updated_at = NOW()
+WHERE user_id = $1
+RETURNING *;
+
+-- name: UpdateTOTPLastUsedStep :exec
+UPDATE totp_factors
+SET last_used_step = $2,
+    updated_at = NOW()
+WHERE user_id = $1;
+
+-- name: DeleteTOTPFactor :exec
+DELETE FROM totp_factors
+WHERE user_id = $1;
+
+-- name: CreateRecoveryCode :exec
+INSERT INTO mfa_recovery_codes (
+    user_id,
+    code_hash
+) VALUES (
+    $1, $2
+);
+
+-- name: DeleteRecoveryCodes :exec
+DELETE FROM mfa_recovery_codes
+WHERE user_id = $1;
+
+-- name: UseRecoveryCode :execrows
+UPDATE mfa_recovery_codes
+SET used_at = NOW()
+WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL;
+
+-- name: CountUnusedRecoveryCodes :one
+SELECT COUNT(*) FROM mfa_recovery_codes
+WHERE user_id = $1 AND used_at IS NULL;
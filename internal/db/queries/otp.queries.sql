@@ -4,11 +4,47 @@ INSERT INTO otps (
     code,
     purpose,
     expires_at,
-    used
+    used,
+    reference
 ) VALUES (
     $1,
     $2,
     $3,
     $4,
-    $5
-) RETURNING *;
\ No newline at end of file
+    $5,
+    $6
+) RETURNING *;
+
+-- name: GetLatestOTPForUpdate :one
+SELECT * FROM otps
+WHERE user_id = $1 AND purpose = $2 AND used = false
+ORDER BY created_at DESC
+LIMIT 1
+FOR UPDATE;
+
+-- name: GetLatestOTPByPurpose :one
+SELECT * FROM otps
+WHERE user_id = $1 AND purpose = $2
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: IncrementOTPAttempts :one
+UPDATE otps
+SET attempts = attempts + 1
+WHERE id = $1
+RETURNING *;
+
+-- name: MarkOTPUsed :exec
+UPDATE otps
+SET used = true
+WHERE id = $1;
+
+-- name: InvalidateOTPs :exec
+UPDATE otps
+SET used = true
+WHERE user_id = $1 AND purpose = $2 AND used = false;
+
+-- name: GetOTPByIdForUpdate :one
+SELECT * FROM otps
+WHERE id = $1
+FOR UPDATE;
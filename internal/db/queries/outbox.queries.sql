@@ -0,0 +1,46 @@
+-- name: CreateOutboxEvent :one
+INSERT INTO outbox_events (aggregate_type, aggregate_id, event_type, payload) VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: ClaimRelayableOutboxEvents :many
+UPDATE outbox_events SET next_attempt_at = sqlc.arg(claimed_until)::timestamptz
+WHERE id IN (
+    SELECT id FROM outbox_events
+    WHERE id IN (
+        SELECT DISTINCT ON (aggregate_type, aggregate_id) id FROM outbox_events
+        WHERE published_at IS NULL
+        ORDER BY aggregate_type, aggregate_id, sequence
+    )
+    AND next_attempt_at <= NOW()
+    ORDER BY sequence
+    LIMIT sqlc.arg(batch_size)::int
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: MarkOutboxEventPublished :exec
+UPDATE outbox_events SET published_at = NOW(), last_error = NULL WHERE id = $1;
+
+-- name: MarkOutboxEventFailed :exec
+UPDATE outbox_events
+SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3, published_sinks = $4
+WHERE id = $1;
+
+-- name: GetOutboxEventBySequence :one
+SELECT * FROM outbox_events WHERE sequence = $1;
+
+-- name: GetWalletStreamEvents :many
+SELECT * FROM outbox_events
+WHERE (sequence > sqlc.arg(after_sequence) OR (sequence < sqlc.arg(after_sequence) AND created_at >= sqlc.arg(since)))
+AND (
+    (aggregate_type = 'wallet' AND aggregate_id = ANY(sqlc.arg(wallet_ids)::uuid[]))
+    OR (aggregate_type = 'transaction' AND EXISTS (
+        SELECT 1 FROM jsonb_array_elements(payload->'legs') AS leg
+        WHERE (leg->>'wallet_id')::uuid = ANY(sqlc.arg(wallet_ids)::uuid[])
+    ))
+)
+ORDER BY sequence
+LIMIT sqlc.arg(batch_size);
+
+-- name: GetLatestOutboxSequence :one
+SELECT COALESCE(MAX(sequence), 0)::bigint FROM outbox_events;
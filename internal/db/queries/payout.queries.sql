@@ -0,0 +1,75 @@
+-- name: CreateBankDestination :one
+INSERT INTO bank_destinations (user_id, bank_code, bank_name, account_number, account_name, currency, nickname)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetBankDestinationById :one
+SELECT * FROM bank_destinations WHERE id = $1;
+
+-- name: GetBankDestinationsByUserId :many
+SELECT * FROM bank_destinations WHERE user_id = $1 ORDER BY created_at;
+
+-- name: DeleteBankDestination :exec
+DELETE FROM bank_destinations WHERE id = $1;
+
+-- name: CreatePayout :one
+INSERT INTO payouts (
+    user_id, wallet_id, destination_id, bank_code, account_number, account_name,
+    amount, fee, fee_schedule_id, currency, narration, hold_id, provider, idempotency_key
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+RETURNING *;
+
+-- name: GetPayoutById :one
+SELECT * FROM payouts WHERE id = $1;
+
+-- name: GetPayoutForUpdate :one
+SELECT * FROM payouts WHERE id = $1 FOR UPDATE;
+
+-- name: GetPayoutByIdempotencyKey :one
+SELECT * FROM payouts WHERE idempotency_key = $1;
+
+-- name: GetPayoutsByUserId :many
+SELECT * FROM payouts WHERE user_id = $1 ORDER BY created_at DESC LIMIT $2;
+
+-- name: GetDuePayoutIds :many
+SELECT id FROM payouts
+WHERE status = 'initiated'
+OR (status = 'processing' AND next_check_at <= sqlc.arg(now)::timestamptz)
+ORDER BY created_at
+LIMIT sqlc.arg(batch_size);
+
+-- name: MarkPayoutProcessing :one
+UPDATE payouts
+SET status = 'processing', provider_reference = $2, debit_transaction_id = $3, next_check_at = $4, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: SchedulePayoutCheck :one
+UPDATE payouts
+SET checks = checks + 1, next_check_at = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: MarkPayoutSucceeded :one
+UPDATE payouts
+SET status = 'succeeded', settlement_transaction_id = $2, next_check_at = NULL, completed_at = NOW(), updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: MarkPayoutFailed :one
+UPDATE payouts
+SET status = 'failed', failure_reason = $2, next_check_at = NULL, completed_at = NOW(), updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: MarkPayoutReversed :one
+UPDATE payouts
+SET status = 'reversed', reversal_transaction_id = $2, failure_reason = $3, next_check_at = NULL, completed_at = NOW(), updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: MarkPayoutForReview :one
+UPDATE payouts
+SET status = 'review', provider_reference = $2, failure_reason = $3, next_check_at = NULL, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
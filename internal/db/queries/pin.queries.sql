@@ -0,0 +1,29 @@
+-- name: UpsertTransactionPin :one
+INSERT INTO transaction_pins (
+    user_id,
+    pin_hash
+) VALUES (
+    $1, $2
+)
+ON CONFLICT (user_id) DO UPDATE
+SET pin_hash = EXCLUDED.pin_hash,
+    failed_attempts = 0,
+    locked_until = NULL,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: GetTransactionPin :one
+SELECT * FROM transaction_pins
+WHERE user_id = $1;
+
+-- name: GetTransactionPinForUpdate :one
+SELECT * FROM transaction_pins
+WHERE user_id = $1
+FOR UPDATE;
+
+-- name: UpdateTransactionPinAttempts :exec
+UPDATE transaction_pins
+SET failed_attempts = $2,
+    locked_until = $3,
+    updated_at = NOW()
+WHERE user_id = $1;
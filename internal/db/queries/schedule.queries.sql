@@ -0,0 +1,74 @@
+-- name: CreateScheduledTransfer :one
+INSERT INTO scheduled_transfers (
+    user_id,
+    sender_wallet_id,
+    receiver_wallet_id,
+    amount,
+    currency,
+    description,
+    frequency,
+    start_at,
+    end_at,
+    max_runs,
+    failure_policy,
+    max_retries,
+    next_run_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+)
+RETURNING *;
+
+-- name: GetScheduledTransferById :one
+SELECT * FROM scheduled_transfers WHERE id = $1;
+
+-- name: GetScheduledTransferForUpdate :one
+SELECT * FROM scheduled_transfers WHERE id = $1 FOR UPDATE;
+
+-- name: GetScheduledTransfersByUserId :many
+SELECT * FROM scheduled_transfers WHERE user_id = $1 ORDER BY created_at DESC;
+
+-- name: UpdateScheduledTransfer :one
+UPDATE scheduled_transfers
+SET
+    amount = COALESCE(sqlc.narg('amount'), amount),
+    description = COALESCE(sqlc.narg('description'), description),
+    end_at = COALESCE(sqlc.narg('end_at'), end_at),
+    max_runs = COALESCE(sqlc.narg('max_runs'), max_runs),
+    failure_policy = COALESCE(sqlc.narg('failure_policy'), failure_policy),
+    max_retries = COALESCE(sqlc.narg('max_retries'), max_retries),
+    status = COALESCE(sqlc.narg('status'), status),
+    next_run_at = COALESCE(sqlc.narg('next_run_at'), next_run_at),
+    updated_at = NOW()
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: AdvanceScheduledTransfer :one
+UPDATE scheduled_transfers
+SET
+    next_occurrence = $1,
+    next_run_at = $2,
+    retry_count = $3,
+    runs_count = $4,
+    status = $5,
+    last_error = $6,
+    last_run_at = NOW(),
+    updated_at = NOW()
+WHERE id = $7
+RETURNING *;
+
+-- name: GetDueScheduledTransferIds :many
+SELECT id FROM scheduled_transfers
+WHERE status = 'active' AND next_run_at <= NOW()
+ORDER BY next_run_at
+LIMIT $1;
+
+-- name: CreateScheduledTransferRun :one
+INSERT INTO scheduled_transfer_runs (schedule_id, occurrence, attempt, status, transaction_id, error)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetScheduledTransferRuns :many
+SELECT * FROM scheduled_transfer_runs
+WHERE schedule_id = $1
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
@@ -0,0 +1,59 @@
+-- name: CreateRefreshSession :one
+INSERT INTO refresh_sessions (
+    id,
+    user_id,
+    family_id,
+    parent_id,
+    device,
+    ip_address,
+    user_agent,
+    signed_in_at,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+)
+RETURNING *;
+
+-- name: GetRefreshSessionForUpdate :one
+SELECT * FROM refresh_sessions
+WHERE id = $1
+FOR UPDATE;
+
+-- name: RotateRefreshSession :exec
+UPDATE refresh_sessions
+SET rotated_at = NOW(), last_used_at = NOW()
+WHERE id = $1;
+
+-- name: RevokeRefreshSessionFamily :exec
+UPDATE refresh_sessions
+SET revoked_at = NOW(), revoked_reason = $2
+WHERE family_id = $1 AND revoked_at IS NULL;
+
+-- name: RevokeUserRefreshSessions :exec
+UPDATE refresh_sessions
+SET revoked_at = NOW(), revoked_reason = $2
+WHERE user_id = $1 AND revoked_at IS NULL;
+
+-- name: GetActiveRefreshSessionsByUserId :many
+SELECT * FROM refresh_sessions
+WHERE user_id = $1
+  AND revoked_at IS NULL
+  AND rotated_at IS NULL
+  AND expires_at > NOW()
+ORDER BY last_used_at DESC;
+
+-- name: GetSessionFamilySignedInAt :one
+SELECT signed_in_at FROM refresh_sessions
+WHERE family_id = $1
+ORDER BY created_at ASC
+LIMIT 1;
+
+-- name: IsSessionFamilyActive :one
+SELECT EXISTS (
+    SELECT 1 FROM refresh_sessions rs
+    JOIN users u ON u.id = rs.user_id
+    WHERE rs.family_id = sqlc.arg(family_id)::uuid
+      AND rs.user_id = sqlc.arg(user_id)::uuid
+      AND rs.revoked_at IS NULL
+      AND (u.password_changed_at IS NULL OR date_trunc('second', u.password_changed_at) <= sqlc.arg(issued_at)::timestamptz)
+) AS active;
@@ -1,5 +1,5 @@
 -- name: CreateTransaction :one
-INSERT INTO transactions (sender_wallet_id, receiver_wallet_id, transaction_type, amount, description, status, currency,idempotency_key) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING *;
+INSERT INTO transactions (sender_wallet_id, receiver_wallet_id, transaction_type, amount, description, status, currency,idempotency_key, parent_transaction_id, fee, fee_schedule_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING *;
 
 -- name: GetTransactionById :one
 SELECT * FROM transactions WHERE id = $1;
@@ -14,4 +14,21 @@ UPDATE transactions SET status = $1 WHERE id = $2;
 SELECT * FROM transactions WHERE (sender_wallet_id = $1 OR receiver_wallet_id = $1) AND status = 'pending' ORDER BY created_at DESC;
 
 -- name: GetTransactionByIdempotencyKey :one
-SELECT * FROM transactions WHERE idempotency_key = $1;
\ No newline at end of file
+SELECT * FROM transactions WHERE idempotency_key = $1;
+
+-- name: GetTransactionByIdForUpdate :one
+SELECT * FROM transactions WHERE id = $1 FOR UPDATE;
+
+-- name: GetCompensatedAmountByParentId :one
+SELECT COALESCE(SUM(amount), 0)::numeric AS compensated_amount
+FROM transactions
+WHERE parent_transaction_id = $1 AND status = 'completed';
+
+-- name: GetTransactionsByParentId :many
+SELECT * FROM transactions WHERE parent_transaction_id = $1 ORDER BY created_at;
+
+-- name: CountCompletedTransfersToWallet :one
+SELECT COUNT(*) FROM transactions
+WHERE receiver_wallet_id = sqlc.arg(receiver_wallet_id)
+  AND status = 'completed'
+  AND sender_wallet_id IN (SELECT id FROM wallets WHERE user_id = sqlc.arg(user_id));
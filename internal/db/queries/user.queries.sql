@@ -32,6 +32,7 @@ SET
     phone_number = COALESCE(sqlc.narg('phone_number'), phone_number),
     email = COALESCE(sqlc.narg('email'), email),
     passwordHash = COALESCE(sqlc.narg('password_hash'), passwordHash),
+    password_changed_at = CASE WHEN sqlc.narg('password_hash')::text IS NULL THEN password_changed_at ELSE NOW() END,
     username = COALESCE(sqlc.narg('username'), username),
     account_no = COALESCE(sqlc.narg('account_no'), account_no),
     nationality = COALESCE(sqlc.narg('nationality'), nationality),
@@ -46,3 +47,9 @@ WHERE id = $1;
 -- name: GetUserByAccountNo :one
 SELECT * FROM users
 WHERE account_no = $1 LIMIT 1;
+
+-- name: MarkUserEmailVerified :one
+UPDATE users
+SET email_verified_at = NOW(), updated_at = NOW()
+WHERE id = $1
+RETURNING *;
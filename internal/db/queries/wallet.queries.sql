@@ -10,7 +10,7 @@ RETURNING *;
 SELECT * FROM wallets WHERE id = $1 FOR UPDATE;
 
 -- name: GetWalletsAndLockByWalletIds :many
-SELECT id, user_id, balance, currency
+SELECT id, user_id, balance, available_balance, currency, wallet_type, matures_at
 FROM wallets
 WHERE id IN (sqlc.arg(id)::uuid, sqlc.arg(id_2)::uuid)
 ORDER BY id
@@ -18,9 +18,23 @@ FOR UPDATE;
 
 -- name: UpdateWalletBalance :exec
 UPDATE wallets
-SET balance = $1
+SET balance = $1, available_balance = $2, updated_at = NOW()
+WHERE id = $3;
+
+-- name: UpdateWalletAvailableBalance :exec
+UPDATE wallets
+SET available_balance = $1, updated_at = NOW()
 WHERE id = $2;
 
+-- name: RefreshWalletMaturity :exec
+UPDATE wallets
+SET matures_at = (
+    SELECT MAX(maturity_date)::timestamptz
+    FROM fixed_deposits
+    WHERE wallet_id = sqlc.arg(wallet_id)::uuid AND status = 'active'
+), updated_at = NOW()
+WHERE id = sqlc.arg(wallet_id)::uuid;
+
 -- name: GetWalletsByUserId :many
 SELECT * FROM wallets WHERE user_id = $1 ORDER BY created_at;
 
@@ -36,4 +50,41 @@ SELECT
 FROM wallets w
 JOIN users u ON w.user_id = u.id
 WHERE u.account_no = $1
-LIMIT 1;
\ No newline at end of file
+ORDER BY (w.wallet_type = 'savings') DESC, w.created_at
+LIMIT 1;
+
+-- name: GetWalletByAccountNoAndCurrency :one
+SELECT
+    w.id as wallet_id,
+    w.user_id,
+    w.balance,
+    w.currency,
+    u.full_name,
+    u.email,
+    u.account_no
+FROM wallets w
+JOIN users u ON w.user_id = u.id
+WHERE u.account_no = sqlc.arg(account_no) AND w.currency = sqlc.arg(currency)
+ORDER BY (w.wallet_type = 'savings') DESC, w.created_at
+LIMIT 1;
+
+-- name: GetWalletByUserIdAndType :one
+SELECT * FROM wallets WHERE user_id = $1 AND wallet_type = $2 AND currency = $3;
+
+-- name: GetSystemWallet :one
+SELECT * FROM wallets WHERE system_code = $1 AND currency = $2;
+
+-- name: CreateSystemWallet :one
+INSERT INTO wallets (
+    wallet_type,
+    currency,
+    system_code
+) VALUES ('system', sqlc.arg(currency), sqlc.arg(system_code))
+ON CONFLICT (system_code, currency) WHERE system_code IS NOT NULL DO NOTHING
+RETURNING *;
+
+-- name: GetWalletsForUpdate :many
+SELECT id, user_id, balance, available_balance, currency, wallet_type, system_code FROM wallets
+WHERE id = ANY(sqlc.arg(ids)::uuid[])
+ORDER BY id
+FOR UPDATE;
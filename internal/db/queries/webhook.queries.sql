@@ -0,0 +1,101 @@
+-- name: CreateWebhookEndpoint :one
+INSERT INTO webhook_endpoints (user_id, url, description, event_types, secret) VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetWebhookEndpointById :one
+SELECT * FROM webhook_endpoints WHERE id = $1;
+
+-- name: GetWebhookEndpointsByUserId :many
+SELECT * FROM webhook_endpoints WHERE user_id = $1 ORDER BY created_at;
+
+-- name: GetActiveWebhookEndpointsForEvent :many
+SELECT * FROM webhook_endpoints
+WHERE user_id = ANY(sqlc.arg(user_ids)::uuid[])
+AND status = 'active'
+AND (sqlc.arg(event_type)::text = ANY(event_types) OR '*' = ANY(event_types))
+ORDER BY created_at;
+
+-- name: UpdateWebhookEndpoint :one
+UPDATE webhook_endpoints
+SET
+    url = COALESCE(sqlc.narg('url'), url),
+    description = COALESCE(sqlc.narg('description'), description),
+    event_types = COALESCE(sqlc.narg('event_types'), event_types),
+    updated_at = NOW()
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: EnableWebhookEndpoint :one
+UPDATE webhook_endpoints
+SET status = 'active', consecutive_failures = 0, disabled_reason = NULL, disabled_at = NULL, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: DisableWebhookEndpoint :one
+UPDATE webhook_endpoints
+SET status = 'disabled', disabled_reason = $2, disabled_at = NOW(), updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: RotateWebhookEndpointSecret :one
+UPDATE webhook_endpoints
+SET previous_secret = secret, secret = $2, previous_secret_expires_at = $3, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: RecordWebhookEndpointSuccess :exec
+UPDATE webhook_endpoints SET consecutive_failures = 0 WHERE id = $1;
+
+-- name: RecordWebhookEndpointFailure :one
+UPDATE webhook_endpoints
+SET consecutive_failures = consecutive_failures + 1, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteWebhookEndpoint :exec
+DELETE FROM webhook_endpoints WHERE id = $1;
+
+-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries (endpoint_id, event_id, event_type, payload, redelivery_of) VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (endpoint_id, event_id) WHERE redelivery_of IS NULL DO NOTHING
+RETURNING *;
+
+-- name: GetWebhookDeliveryById :one
+SELECT * FROM webhook_deliveries WHERE id = $1;
+
+-- name: GetWebhookDeliveryForUpdate :one
+SELECT * FROM webhook_deliveries WHERE id = $1 FOR UPDATE;
+
+-- name: GetWebhookDeliveryByEvent :one
+SELECT * FROM webhook_deliveries WHERE endpoint_id = $1 AND event_id = $2 AND redelivery_of IS NULL;
+
+-- name: GetWebhookDeliveriesByEndpointId :many
+SELECT * FROM webhook_deliveries
+WHERE endpoint_id = $1
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: UpdateWebhookDeliveryResult :one
+UPDATE webhook_deliveries
+SET
+    status = $2,
+    attempts = attempts + 1,
+    response_code = $3,
+    last_error = $4,
+    next_attempt_at = $5,
+    delivered_at = $6,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: FailWebhookDelivery :exec
+UPDATE webhook_deliveries
+SET status = 'failed', last_error = $2, next_attempt_at = NULL, updated_at = NOW()
+WHERE id = $1;
+
+-- name: CreateWebhookDeliveryAttempt :one
+INSERT INTO webhook_delivery_attempts (delivery_id, attempt, response_code, response_body, error, duration_ms) VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetWebhookDeliveryAttemptsByDeliveryId :many
+SELECT * FROM webhook_delivery_attempts WHERE delivery_id = $1 ORDER BY attempt;
@@ -12,19 +12,41 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countCompletedTransfersToWallet = `-- name: CountCompletedTransfersToWallet :one
+SELECT COUNT(*) FROM transactions
+WHERE receiver_wallet_id = $1
+  AND status = 'completed'
+  AND sender_wallet_id IN (SELECT id FROM wallets WHERE user_id = $2)
+`
+
+type CountCompletedTransfersToWalletParams struct {
+	ReceiverWalletID pgtype.UUID `json:"receiver_wallet_id"`
+	UserID           pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) CountCompletedTransfersToWallet(ctx context.Context, arg CountCompletedTransfersToWalletParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countCompletedTransfersToWallet, arg.ReceiverWalletID, arg.UserID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createTransaction = `-- name: CreateTransaction :one
-INSERT INTO transactions (sender_wallet_id, receiver_wallet_id, transaction_type, amount, description, status, currency,idempotency_key) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, sender_wallet_id, receiver_wallet_id, transaction_type, amount, description, status, currency, idempotency_key, created_at, updated_at
+INSERT INTO transactions (sender_wallet_id, receiver_wallet_id, transaction_type, amount, description, status, currency,idempotency_key, parent_transaction_id, fee, fee_schedule_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id, sender_wallet_id, receiver_wallet_id, transaction_type, amount, description, status, currency, idempotency_key, created_at, updated_at, parent_transaction_id, fee, fee_schedule_id
 `
 
 type CreateTransactionParams struct {
-	SenderWalletID   pgtype.UUID           `json:"sender_wallet_id"`
-	ReceiverWalletID pgtype.UUID           `json:"receiver_wallet_id"`
-	TransactionType  TransactionTypeEnum   `json:"transaction_type"`
-	Amount           pgtype.Numeric        `json:"amount"`
-	Description      pgtype.Text           `json:"description"`
-	Status           TransactionStatusEnum `json:"status"`
-	Currency         string                `json:"currency"`
-	IdempotencyKey   string                `json:"idempotency_key"`
+	SenderWalletID      pgtype.UUID           `json:"sender_wallet_id"`
+	ReceiverWalletID    pgtype.UUID           `json:"receiver_wallet_id"`
+	TransactionType     TransactionTypeEnum   `json:"transaction_type"`
+	Amount              pgtype.Numeric        `json:"amount"`
+	Description         pgtype.Text           `json:"description"`
+	Status              TransactionStatusEnum `json:"status"`
+	Currency            string                `json:"currency"`
+	IdempotencyKey      string                `json:"idempotency_key"`
+	ParentTransactionID pgtype.UUID           `json:"parent_transaction_id"`
+	Fee                 pgtype.Numeric        `json:"fee"`
+	FeeScheduleID       pgtype.UUID           `json:"fee_schedule_id"`
 }
 
 func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionParams) (Transaction, error) {
@@ -37,6 +59,9 @@ func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionPa
 		arg.Status,
 		arg.Currency,
 		arg.IdempotencyKey,
+		arg.ParentTransactionID,
+		arg.Fee,
+		arg.FeeScheduleID,
 	)
 	var i Transaction
 	err := row.Scan(
@@ -51,12 +76,28 @@ func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionPa
 		&i.IdempotencyKey,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ParentTransactionID,
+		&i.Fee,
+		&i.FeeScheduleID,
 	)
 	return i, err
 }
 
+const getCompensatedAmountByParentId = `-- name: GetCompensatedAmountByParentId :one
+SELECT COALESCE(SUM(amount), 0)::numeric AS compensated_amount
+FROM transactions
+WHERE parent_transaction_id = $1 AND status = 'completed'
+`
+
+func (q *Queries) GetCompensatedAmountByParentId(ctx context.Context, parentTransactionID pgtype.UUID) (pgtype.Numeric, error) {
+	row := q.db.QueryRow(ctx, getCompensatedAmountByParentId, parentTransactionID)
+	var compensatedAmount pgtype.Numeric
+	err := row.Scan(&compensatedAmount)
+	return compensatedAmount, err
+}
+
 const getPendingTransactionsByWalletId = `-- name: GetPendingTransactionsByWalletId :many
-SELECT id, sender_wallet_id, receiver_wallet_id, transaction_type, amount, description, status, currency, idempotency_key, created_at, updated_at FROM transactions WHERE (sender_wallet_id = $1 OR receiver_wallet_id = $1) AND status = 'pending' ORDER BY created_at DESC
+SELECT id, sender_wallet_id, receiver_wallet_id, transaction_type, amount, description, status, currency, idempotency_key, created_at, updated_at, parent_transaction_id, fee, fee_schedule_id FROM transactions WHERE (sender_wallet_id = $1 OR receiver_wallet_id = $1) AND status = 'pending' ORDER BY created_at DESC
 `
 
 func (q *Queries) GetPendingTransactionsByWalletId(ctx context.Context, senderWalletID pgtype.UUID) ([]Transaction, error) {
@@ -80,6 +121,9 @@ func (q *Queries) GetPendingTransactionsByWalletId(ctx context.Context, senderWa
 			&i.IdempotencyKey,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ParentTransactionID,
+			&i.Fee,
+			&i.FeeScheduleID,
 		); err != nil {
 			return nil, err
 		}
@@ -92,7 +136,7 @@ func (q *Queries) GetPendingTransactionsByWalletId(ctx context.Context, senderWa
 }
 
 const getTransactionById = `-- name: GetTransactionById :one
-SELECT id, sender_wallet_id, receiver_wallet_id, transaction_type, amount, description, status, currency, idempotency_key, created_at, updated_at FROM transactions WHERE id = $1
+SELECT id, sender_wallet_id, receiver_wallet_id, transaction_type, amount, description, status, currency, idempotency_key, created_at, updated_at, parent_transaction_id, fee, fee_schedule_id FROM transactions WHERE id = $1
 `
 
 func (q *Queries) GetTransactionById(ctx context.Context, id uuid.UUID) (Transaction, error) {
@@ -110,12 +154,41 @@ func (q *Queries) GetTransactionById(ctx context.Context, id uuid.UUID) (Transac
 		&i.IdempotencyKey,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ParentTransactionID,
+		&i.Fee,
+		&i.FeeScheduleID,
+	)
+	return i, err
+}
+
+const getTransactionByIdForUpdate = `-- name: GetTransactionByIdForUpdate :one
+SELECT id, sender_wallet_id, receiver_wallet_id, transaction_type, amount, description, status, currency, idempotency_key, created_at, updated_at, parent_transaction_id, fee, fee_schedule_id FROM transactions WHERE id = $1 FOR UPDATE
+`
+
+func (q *Queries) GetTransactionByIdForUpdate(ctx context.Context, id uuid.UUID) (Transaction, error) {
+	row := q.db.QueryRow(ctx, getTransactionByIdForUpdate, id)
+	var i Transaction
+	err := row.Scan(
+		&i.ID,
+		&i.SenderWalletID,
+		&i.ReceiverWalletID,
+		&i.TransactionType,
+		&i.Amount,
+		&i.Description,
+		&i.Status,
+		&i.Currency,
+		&i.IdempotencyKey,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ParentTransactionID,
+		&i.Fee,
+		&i.FeeScheduleID,
 	)
 	return i, err
 }
 
 const getTransactionByIdempotencyKey = `-- name: GetTransactionByIdempotencyKey :one
-SELECT id, sender_wallet_id, receiver_wallet_id, transaction_type, amount, description, status, currency, idempotency_key, created_at, updated_at FROM transactions WHERE idempotency_key = $1
+SELECT id, sender_wallet_id, receiver_wallet_id, transaction_type, amount, description, status, currency, idempotency_key, created_at, updated_at, parent_transaction_id, fee, fee_schedule_id FROM transactions WHERE idempotency_key = $1
 `
 
 func (q *Queries) GetTransactionByIdempotencyKey(ctx context.Context, idempotencyKey string) (Transaction, error) {
@@ -133,12 +206,54 @@ func (q *Queries) GetTransactionByIdempotencyKey(ctx context.Context, idempotenc
 		&i.IdempotencyKey,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ParentTransactionID,
+		&i.Fee,
+		&i.FeeScheduleID,
 	)
 	return i, err
 }
 
+const getTransactionsByParentId = `-- name: GetTransactionsByParentId :many
+SELECT id, sender_wallet_id, receiver_wallet_id, transaction_type, amount, description, status, currency, idempotency_key, created_at, updated_at, parent_transaction_id, fee, fee_schedule_id FROM transactions WHERE parent_transaction_id = $1 ORDER BY created_at
+`
+
+func (q *Queries) GetTransactionsByParentId(ctx context.Context, parentTransactionID pgtype.UUID) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, getTransactionsByParentId, parentTransactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.SenderWalletID,
+			&i.ReceiverWalletID,
+			&i.TransactionType,
+			&i.Amount,
+			&i.Description,
+			&i.Status,
+			&i.Currency,
+			&i.IdempotencyKey,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ParentTransactionID,
+			&i.Fee,
+			&i.FeeScheduleID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getTransactionsByWalletId = `-- name: GetTransactionsByWalletId :many
-SELECT id, sender_wallet_id, receiver_wallet_id, transaction_type, amount, description, status, currency, idempotency_key, created_at, updated_at FROM transactions WHERE sender_wallet_id = $1 OR receiver_wallet_id = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3
+SELECT id, sender_wallet_id, receiver_wallet_id, transaction_type, amount, description, status, currency, idempotency_key, created_at, updated_at, parent_transaction_id, fee, fee_schedule_id FROM transactions WHERE sender_wallet_id = $1 OR receiver_wallet_id = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3
 `
 
 type GetTransactionsByWalletIdParams struct {
@@ -168,6 +283,9 @@ func (q *Queries) GetTransactionsByWalletId(ctx context.Context, arg GetTransact
 			&i.IdempotencyKey,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ParentTransactionID,
+			&i.Fee,
+			&i.FeeScheduleID,
 		); err != nil {
 			return nil, err
 		}
@@ -25,7 +25,7 @@ INSERT INTO users (
 ) VALUES (
     $1, $2, $3, $4, $5, $6, $7, $8
 )
-RETURNING id, full_name, phone_number, email, passwordhash, username, account_no, nationality, created_at, updated_at, country_code
+RETURNING id, full_name, phone_number, email, passwordhash, username, account_no, nationality, created_at, updated_at, country_code, email_verified_at, kyc_tier, password_changed_at
 `
 
 type CreateUserParams struct {
@@ -63,6 +63,9 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.CountryCode,
+		&i.EmailVerifiedAt,
+		&i.KycTier,
+		&i.PasswordChangedAt,
 	)
 	return i, err
 }
@@ -78,7 +81,7 @@ func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) error {
 }
 
 const getUserByAccountNo = `-- name: GetUserByAccountNo :one
-SELECT id, full_name, phone_number, email, passwordhash, username, account_no, nationality, created_at, updated_at, country_code FROM users
+SELECT id, full_name, phone_number, email, passwordhash, username, account_no, nationality, created_at, updated_at, country_code, email_verified_at, kyc_tier, password_changed_at FROM users
 WHERE account_no = $1 LIMIT 1
 `
 
@@ -97,12 +100,15 @@ func (q *Queries) GetUserByAccountNo(ctx context.Context, accountNo string) (Use
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.CountryCode,
+		&i.EmailVerifiedAt,
+		&i.KycTier,
+		&i.PasswordChangedAt,
 	)
 	return i, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, full_name, phone_number, email, passwordhash, username, account_no, nationality, created_at, updated_at, country_code FROM users
+SELECT id, full_name, phone_number, email, passwordhash, username, account_no, nationality, created_at, updated_at, country_code, email_verified_at, kyc_tier, password_changed_at FROM users
 WHERE email = $1 LIMIT 1
 `
 
@@ -121,12 +127,15 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.CountryCode,
+		&i.EmailVerifiedAt,
+		&i.KycTier,
+		&i.PasswordChangedAt,
 	)
 	return i, err
 }
 
 const getUserByID = `-- name: GetUserByID :one
-SELECT id, full_name, phone_number, email, passwordhash, username, account_no, nationality, created_at, updated_at, country_code FROM users
+SELECT id, full_name, phone_number, email, passwordhash, username, account_no, nationality, created_at, updated_at, country_code, email_verified_at, kyc_tier, password_changed_at FROM users
 WHERE id = $1 LIMIT 1
 `
 
@@ -145,12 +154,15 @@ func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.CountryCode,
+		&i.EmailVerifiedAt,
+		&i.KycTier,
+		&i.PasswordChangedAt,
 	)
 	return i, err
 }
 
 const getUserByUsername = `-- name: GetUserByUsername :one
-SELECT id, full_name, phone_number, email, passwordhash, username, account_no, nationality, created_at, updated_at, country_code FROM users
+SELECT id, full_name, phone_number, email, passwordhash, username, account_no, nationality, created_at, updated_at, country_code, email_verified_at, kyc_tier, password_changed_at FROM users
 WHERE username = $1 LIMIT 1
 `
 
@@ -169,6 +181,38 @@ func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.CountryCode,
+		&i.EmailVerifiedAt,
+		&i.KycTier,
+		&i.PasswordChangedAt,
+	)
+	return i, err
+}
+
+const markUserEmailVerified = `-- name: MarkUserEmailVerified :one
+UPDATE users
+SET email_verified_at = NOW(), updated_at = NOW()
+WHERE id = $1
+RETURNING id, full_name, phone_number, email, passwordhash, username, account_no, nationality, created_at, updated_at, country_code, email_verified_at, kyc_tier, password_changed_at
+`
+
+func (q *Queries) MarkUserEmailVerified(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRow(ctx, markUserEmailVerified, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.FullName,
+		&i.PhoneNumber,
+		&i.Email,
+		&i.Passwordhash,
+		&i.Username,
+		&i.AccountNo,
+		&i.Nationality,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CountryCode,
+		&i.EmailVerifiedAt,
+		&i.KycTier,
+		&i.PasswordChangedAt,
 	)
 	return i, err
 }
@@ -180,13 +224,14 @@ SET
     phone_number = COALESCE($2, phone_number),
     email = COALESCE($3, email),
     passwordHash = COALESCE($4, passwordHash),
+    password_changed_at = CASE WHEN $4::text IS NULL THEN password_changed_at ELSE NOW() END,
     username = COALESCE($5, username),
     account_no = COALESCE($6, account_no),
     nationality = COALESCE($7, nationality),
     country_code = COALESCE($8, country_code),
     updated_at = NOW()
 WHERE id = $9
-RETURNING id, full_name, phone_number, email, passwordhash, username, account_no, nationality, created_at, updated_at, country_code
+RETURNING id, full_name, phone_number, email, passwordhash, username, account_no, nationality, created_at, updated_at, country_code, email_verified_at, kyc_tier, password_changed_at
 `
 
 type UpdateUserParams struct {
@@ -226,6 +271,9 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.CountryCode,
+		&i.EmailVerifiedAt,
+		&i.KycTier,
+		&i.PasswordChangedAt,
 	)
 	return i, err
 }
@@ -12,13 +12,46 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const createSystemWallet = `-- name: CreateSystemWallet :one
+INSERT INTO wallets (
+    wallet_type,
+    currency,
+    system_code
+) VALUES ('system', $1, $2)
+ON CONFLICT (system_code, currency) WHERE system_code IS NOT NULL DO NOTHING
+RETURNING id, user_id, balance, created_at, updated_at, wallet_type, currency, available_balance, matures_at, system_code
+`
+
+type CreateSystemWalletParams struct {
+	Currency   string      `json:"currency"`
+	SystemCode pgtype.Text `json:"system_code"`
+}
+
+func (q *Queries) CreateSystemWallet(ctx context.Context, arg CreateSystemWalletParams) (Wallet, error) {
+	row := q.db.QueryRow(ctx, createSystemWallet, arg.Currency, arg.SystemCode)
+	var i Wallet
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Balance,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.WalletType,
+		&i.Currency,
+		&i.AvailableBalance,
+		&i.MaturesAt,
+		&i.SystemCode,
+	)
+	return i, err
+}
+
 const createWallet = `-- name: CreateWallet :one
 INSERT INTO wallets (
     user_id,
     wallet_type,
     currency
 ) VALUES ($1,$2,$3)
-RETURNING id, user_id, balance, created_at, updated_at, wallet_type, currency
+RETURNING id, user_id, balance, created_at, updated_at, wallet_type, currency, available_balance, matures_at, system_code
 `
 
 type CreateWalletParams struct {
@@ -38,6 +71,36 @@ func (q *Queries) CreateWallet(ctx context.Context, arg CreateWalletParams) (Wal
 		&i.UpdatedAt,
 		&i.WalletType,
 		&i.Currency,
+		&i.AvailableBalance,
+		&i.MaturesAt,
+		&i.SystemCode,
+	)
+	return i, err
+}
+
+const getSystemWallet = `-- name: GetSystemWallet :one
+SELECT id, user_id, balance, created_at, updated_at, wallet_type, currency, available_balance, matures_at, system_code FROM wallets WHERE system_code = $1 AND currency = $2
+`
+
+type GetSystemWalletParams struct {
+	SystemCode pgtype.Text `json:"system_code"`
+	Currency   string      `json:"currency"`
+}
+
+func (q *Queries) GetSystemWallet(ctx context.Context, arg GetSystemWalletParams) (Wallet, error) {
+	row := q.db.QueryRow(ctx, getSystemWallet, arg.SystemCode, arg.Currency)
+	var i Wallet
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Balance,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.WalletType,
+		&i.Currency,
+		&i.AvailableBalance,
+		&i.MaturesAt,
+		&i.SystemCode,
 	)
 	return i, err
 }
@@ -54,6 +117,7 @@ SELECT
 FROM wallets w
 JOIN users u ON w.user_id = u.id
 WHERE u.account_no = $1
+ORDER BY (w.wallet_type = 'savings') DESC, w.created_at
 LIMIT 1
 `
 
@@ -82,8 +146,54 @@ func (q *Queries) GetWalletByAccountNo(ctx context.Context, accountNo string) (G
 	return i, err
 }
 
+const getWalletByAccountNoAndCurrency = `-- name: GetWalletByAccountNoAndCurrency :one
+SELECT
+    w.id as wallet_id,
+    w.user_id,
+    w.balance,
+    w.currency,
+    u.full_name,
+    u.email,
+    u.account_no
+FROM wallets w
+JOIN users u ON w.user_id = u.id
+WHERE u.account_no = $1 AND w.currency = $2
+ORDER BY (w.wallet_type = 'savings') DESC, w.created_at
+LIMIT 1
+`
+
+type GetWalletByAccountNoAndCurrencyParams struct {
+	AccountNo string `json:"account_no"`
+	Currency  string `json:"currency"`
+}
+
+type GetWalletByAccountNoAndCurrencyRow struct {
+	WalletID  uuid.UUID      `json:"wallet_id"`
+	UserID    pgtype.UUID    `json:"user_id"`
+	Balance   pgtype.Numeric `json:"balance"`
+	Currency  string         `json:"currency"`
+	FullName  string         `json:"full_name"`
+	Email     string         `json:"email"`
+	AccountNo string         `json:"account_no"`
+}
+
+func (q *Queries) GetWalletByAccountNoAndCurrency(ctx context.Context, arg GetWalletByAccountNoAndCurrencyParams) (GetWalletByAccountNoAndCurrencyRow, error) {
+	row := q.db.QueryRow(ctx, getWalletByAccountNoAndCurrency, arg.AccountNo, arg.Currency)
+	var i GetWalletByAccountNoAndCurrencyRow
+	err := row.Scan(
+		&i.WalletID,
+		&i.UserID,
+		&i.Balance,
+		&i.Currency,
+		&i.FullName,
+		&i.Email,
+		&i.AccountNo,
+	)
+	return i, err
+}
+
 const getWalletById = `-- name: GetWalletById :one
-SELECT id, user_id, balance, created_at, updated_at, wallet_type, currency FROM wallets WHERE id = $1 FOR UPDATE
+SELECT id, user_id, balance, created_at, updated_at, wallet_type, currency, available_balance, matures_at, system_code FROM wallets WHERE id = $1 FOR UPDATE
 `
 
 func (q *Queries) GetWalletById(ctx context.Context, id uuid.UUID) (Wallet, error) {
@@ -97,12 +207,43 @@ func (q *Queries) GetWalletById(ctx context.Context, id uuid.UUID) (Wallet, erro
 		&i.UpdatedAt,
 		&i.WalletType,
 		&i.Currency,
+		&i.AvailableBalance,
+		&i.MaturesAt,
+		&i.SystemCode,
+	)
+	return i, err
+}
+
+const getWalletByUserIdAndType = `-- name: GetWalletByUserIdAndType :one
+SELECT id, user_id, balance, created_at, updated_at, wallet_type, currency, available_balance, matures_at, system_code FROM wallets WHERE user_id = $1 AND wallet_type = $2 AND currency = $3
+`
+
+type GetWalletByUserIdAndTypeParams struct {
+	UserID     pgtype.UUID    `json:"user_id"`
+	WalletType WalletTypeEnum `json:"wallet_type"`
+	Currency   string         `json:"currency"`
+}
+
+func (q *Queries) GetWalletByUserIdAndType(ctx context.Context, arg GetWalletByUserIdAndTypeParams) (Wallet, error) {
+	row := q.db.QueryRow(ctx, getWalletByUserIdAndType, arg.UserID, arg.WalletType, arg.Currency)
+	var i Wallet
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Balance,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.WalletType,
+		&i.Currency,
+		&i.AvailableBalance,
+		&i.MaturesAt,
+		&i.SystemCode,
 	)
 	return i, err
 }
 
 const getWalletsAndLockByWalletIds = `-- name: GetWalletsAndLockByWalletIds :many
-SELECT id, user_id, balance, currency
+SELECT id, user_id, balance, available_balance, currency, wallet_type, matures_at
 FROM wallets
 WHERE id IN ($1::uuid, $2::uuid)
 ORDER BY id
@@ -115,10 +256,13 @@ type GetWalletsAndLockByWalletIdsParams struct {
 }
 
 type GetWalletsAndLockByWalletIdsRow struct {
-	ID       uuid.UUID      `json:"id"`
-	UserID   pgtype.UUID    `json:"user_id"`
-	Balance  pgtype.Numeric `json:"balance"`
-	Currency string         `json:"currency"`
+	ID               uuid.UUID          `json:"id"`
+	UserID           pgtype.UUID        `json:"user_id"`
+	Balance          pgtype.Numeric     `json:"balance"`
+	AvailableBalance pgtype.Numeric     `json:"available_balance"`
+	Currency         string             `json:"currency"`
+	WalletType       WalletTypeEnum     `json:"wallet_type"`
+	MaturesAt        pgtype.Timestamptz `json:"matures_at"`
 }
 
 func (q *Queries) GetWalletsAndLockByWalletIds(ctx context.Context, arg GetWalletsAndLockByWalletIdsParams) ([]GetWalletsAndLockByWalletIdsRow, error) {
@@ -134,7 +278,10 @@ func (q *Queries) GetWalletsAndLockByWalletIds(ctx context.Context, arg GetWalle
 			&i.ID,
 			&i.UserID,
 			&i.Balance,
+			&i.AvailableBalance,
 			&i.Currency,
+			&i.WalletType,
+			&i.MaturesAt,
 		); err != nil {
 			return nil, err
 		}
@@ -147,7 +294,7 @@ func (q *Queries) GetWalletsAndLockByWalletIds(ctx context.Context, arg GetWalle
 }
 
 const getWalletsByUserId = `-- name: GetWalletsByUserId :many
-SELECT id, user_id, balance, created_at, updated_at, wallet_type, currency FROM wallets WHERE user_id = $1 ORDER BY created_at
+SELECT id, user_id, balance, created_at, updated_at, wallet_type, currency, available_balance, matures_at, system_code FROM wallets WHERE user_id = $1 ORDER BY created_at
 `
 
 func (q *Queries) GetWalletsByUserId(ctx context.Context, userID pgtype.UUID) ([]Wallet, error) {
@@ -167,6 +314,9 @@ func (q *Queries) GetWalletsByUserId(ctx context.Context, userID pgtype.UUID) ([
 			&i.UpdatedAt,
 			&i.WalletType,
 			&i.Currency,
+			&i.AvailableBalance,
+			&i.MaturesAt,
+			&i.SystemCode,
 		); err != nil {
 			return nil, err
 		}
@@ -178,18 +328,95 @@ func (q *Queries) GetWalletsByUserId(ctx context.Context, userID pgtype.UUID) ([
 	return items, nil
 }
 
-const updateWalletBalance = `-- name: UpdateWalletBalance :exec
+const getWalletsForUpdate = `-- name: GetWalletsForUpdate :many
+SELECT id, user_id, balance, available_balance, currency, wallet_type, system_code FROM wallets
+WHERE id = ANY($1::uuid[])
+ORDER BY id
+FOR UPDATE
+`
+
+type GetWalletsForUpdateRow struct {
+	ID               uuid.UUID      `json:"id"`
+	UserID           pgtype.UUID    `json:"user_id"`
+	Balance          pgtype.Numeric `json:"balance"`
+	AvailableBalance pgtype.Numeric `json:"available_balance"`
+	Currency         string         `json:"currency"`
+	WalletType       WalletTypeEnum `json:"wallet_type"`
+	SystemCode       pgtype.Text    `json:"system_code"`
+}
+
+func (q *Queries) GetWalletsForUpdate(ctx context.Context, ids []uuid.UUID) ([]GetWalletsForUpdateRow, error) {
+	rows, err := q.db.Query(ctx, getWalletsForUpdate, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetWalletsForUpdateRow
+	for rows.Next() {
+		var i GetWalletsForUpdateRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Balance,
+			&i.AvailableBalance,
+			&i.Currency,
+			&i.WalletType,
+			&i.SystemCode,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const refreshWalletMaturity = `-- name: RefreshWalletMaturity :exec
+UPDATE wallets
+SET matures_at = (
+    SELECT MAX(maturity_date)::timestamptz
+    FROM fixed_deposits
+    WHERE wallet_id = $1::uuid AND status = 'active'
+), updated_at = NOW()
+WHERE id = $1::uuid
+`
+
+func (q *Queries) RefreshWalletMaturity(ctx context.Context, walletID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, refreshWalletMaturity, walletID)
+	return err
+}
+
+const updateWalletAvailableBalance = `-- name: UpdateWalletAvailableBalance :exec
 UPDATE wallets
-SET balance = $1
+SET available_balance = $1, updated_at = NOW()
 WHERE id = $2
 `
 
+type UpdateWalletAvailableBalanceParams struct {
+	AvailableBalance pgtype.Numeric `json:"available_balance"`
+	ID               uuid.UUID      `json:"id"`
+}
+
+func (q *Queries) UpdateWalletAvailableBalance(ctx context.Context, arg UpdateWalletAvailableBalanceParams) error {
+	_, err := q.db.Exec(ctx, updateWalletAvailableBalance, arg.AvailableBalance, arg.ID)
+	return err
+}
+
+const updateWalletBalance = `-- name: UpdateWalletBalance :exec
+UPDATE wallets
+SET balance = $1, available_balance = $2, updated_at = NOW()
+WHERE id = $3
+`
+
 type UpdateWalletBalanceParams struct {
-	Balance pgtype.Numeric `json:"balance"`
-	ID      uuid.UUID      `json:"id"`
+	Balance          pgtype.Numeric `json:"balance"`
+	AvailableBalance pgtype.Numeric `json:"available_balance"`
+	ID               uuid.UUID      `json:"id"`
 }
 
 func (q *Queries) UpdateWalletBalance(ctx context.Context, arg UpdateWalletBalanceParams) error {
-	_, err := q.db.Exec(ctx, updateWalletBalance, arg.Balance, arg.ID)
+	_, err := q.db.Exec(ctx, updateWalletBalance, arg.Balance, arg.AvailableBalance, arg.ID)
 	return err
 }
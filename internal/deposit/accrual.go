@@ -0,0 +1,179 @@
+package deposit
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/luponetn/paycore/internal/accounts"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/ledger"
+	"github.com/luponetn/paycore/internal/transfer"
+	"github.com/luponetn/paycore/pkg/money"
+	"github.com/luponetn/paycore/pkg/utils"
+	"github.com/shopspring/decimal"
+)
+
+const daysPerYear = 365
+
+// accrue brings the deposit's posted interest up to date as of day (capped at maturity). The
+// interest owed is recomputed from the start date each time and truncated to the minor unit, so
+// daily postings never drift from the simple-interest total; only the difference is posted.
+func (s *Svc) accrue(ctx context.Context, qtx db.Querier, deposit db.FixedDeposit, day time.Time) (db.FixedDeposit, error) {
+	if deposit.MaturityDate.Time.Before(day) {
+		day = deposit.MaturityDate.Time
+	}
+	if !day.After(deposit.AccruedThrough.Time) {
+		return deposit, nil
+	}
+
+	owed := interestFor(utils.NumericToDecimal(deposit.Principal), utils.NumericToDecimal(deposit.AnnualRate), daysBetween(deposit.StartDate.Time, day), deposit.Currency)
+	delta := owed.Sub(utils.NumericToDecimal(deposit.AccruedInterest))
+
+	if delta.IsPositive() {
+		if _, err := ledger.Post(ctx, qtx, ledger.Journal{
+			TransactionType: db.TransactionTypeEnumInterest,
+			Description:     "fixed deposit interest",
+			IdempotencyKey:  "fixed_deposit:" + deposit.ID.String() + ":interest:" + day.Format(time.DateOnly),
+			Postings: []ledger.Posting{
+				ledger.DebitAccount(accounts.InterestExpense, delta, deposit.Currency),
+				ledger.Credit(deposit.WalletID, delta, deposit.Currency).AsLocked(),
+			},
+		}); err != nil {
+			return db.FixedDeposit{}, err
+		}
+		deposit.AccruedInterest = utils.DecimalToNumeric(owed)
+	}
+
+	deposit.AccruedThrough = pgtype.Date{Time: day, Valid: true}
+	if err := qtx.UpdateFixedDepositAccrual(ctx, db.UpdateFixedDepositAccrualParams{
+		AccruedInterest: deposit.AccruedInterest,
+		AccruedThrough:  deposit.AccruedThrough,
+		ID:              deposit.ID,
+	}); err != nil {
+		return db.FixedDeposit{}, &utils.RetryableError{Err: err}
+	}
+	return deposit, nil
+}
+
+// settle closes the deposit: penalty goes back to the interest expense account and the rest of
+// the principal and interest is released from the fixed wallet to the payout wallet. The fixed
+// wallet stays locked only while another deposit in it is still running.
+func (s *Svc) settle(ctx context.Context, qtx db.Querier, deposit db.FixedDeposit, status db.DepositStatusEnum, penalty decimal.Decimal) (db.FixedDeposit, error) {
+	total := utils.NumericToDecimal(deposit.Principal).Add(utils.NumericToDecimal(deposit.AccruedInterest))
+
+	if penalty.IsPositive() {
+		if _, err := ledger.Post(ctx, qtx, ledger.Journal{
+			TransactionType: db.TransactionTypeEnumPenalty,
+			Description:     "fixed deposit early break penalty",
+			IdempotencyKey:  "fixed_deposit:" + deposit.ID.String() + ":penalty",
+			Postings: []ledger.Posting{
+				ledger.Debit(deposit.WalletID, penalty, deposit.Currency).AsLocked(),
+				ledger.CreditAccount(accounts.InterestExpense, penalty, deposit.Currency),
+			},
+		}); err != nil {
+			return db.FixedDeposit{}, err
+		}
+		total = total.Sub(penalty)
+	}
+
+	if _, err := ledger.Post(ctx, qtx, ledger.Journal{
+		TransactionType: db.TransactionTypeEnumFixedDeposit,
+		Description:     "fixed deposit payout",
+		IdempotencyKey:  "fixed_deposit:" + deposit.ID.String() + ":payout",
+		Postings: []ledger.Posting{
+			ledger.Debit(deposit.WalletID, total, deposit.Currency).AsLocked(),
+			ledger.Credit(deposit.PayoutWalletID, total, deposit.Currency),
+		},
+	}); err != nil {
+		return db.FixedDeposit{}, err
+	}
+
+	closed, err := qtx.CloseFixedDeposit(ctx, db.CloseFixedDepositParams{
+		Status:  status,
+		Penalty: utils.DecimalToNumeric(penalty),
+		ID:      deposit.ID,
+	})
+	if err != nil {
+		return db.FixedDeposit{}, &utils.RetryableError{Err: err}
+	}
+
+	if err := qtx.RefreshWalletMaturity(ctx, deposit.WalletID); err != nil {
+		return db.FixedDeposit{}, &utils.RetryableError{Err: err}
+	}
+	return closed, nil
+}
+
+// rollover reinvests principal and interest for another term at the rate now offered for the
+// tenor, or the old rate if the tenor has been withdrawn. The funds stay locked in the fixed
+// wallet, so no money moves.
+func (s *Svc) rollover(ctx context.Context, qtx db.Querier, deposit db.FixedDeposit) (db.FixedDeposit, error) {
+	if _, err := qtx.CloseFixedDeposit(ctx, db.CloseFixedDepositParams{
+		Status:  db.DepositStatusEnumRolledOver,
+		Penalty: utils.DecimalToNumeric(decimal.Zero),
+		ID:      deposit.ID,
+	}); err != nil {
+		return db.FixedDeposit{}, &utils.RetryableError{Err: err}
+	}
+
+	rate := deposit.AnnualRate
+	if offered, ok := s.cfg.FixedDepositRates[deposit.TenorDays]; ok {
+		rate = utils.DecimalToNumeric(offered)
+	}
+
+	start := deposit.MaturityDate.Time
+	renewed, err := qtx.CreateFixedDeposit(ctx, db.CreateFixedDepositParams{
+		UserID:          deposit.UserID,
+		WalletID:        deposit.WalletID,
+		PayoutWalletID:  deposit.PayoutWalletID,
+		ParentDepositID: utils.ToPgUUID(deposit.ID),
+		Principal:       utils.DecimalToNumeric(utils.NumericToDecimal(deposit.Principal).Add(utils.NumericToDecimal(deposit.AccruedInterest))),
+		AnnualRate:      rate,
+		TenorDays:       deposit.TenorDays,
+		Currency:        deposit.Currency,
+		MaturityAction:  deposit.MaturityAction,
+		StartDate:       pgtype.Date{Time: start, Valid: true},
+		MaturityDate:    pgtype.Date{Time: start.AddDate(0, 0, int(deposit.TenorDays)), Valid: true},
+		AccruedThrough:  pgtype.Date{Time: start, Valid: true},
+		IdempotencyKey:  "fixed_deposit:" + deposit.ID.String() + ":rollover",
+	})
+	if err != nil {
+		return db.FixedDeposit{}, &utils.RetryableError{Err: err}
+	}
+
+	if err := qtx.RefreshWalletMaturity(ctx, deposit.WalletID); err != nil {
+		return db.FixedDeposit{}, &utils.RetryableError{Err: err}
+	}
+	return renewed, nil
+}
+
+// lockWallets locks both wallets in id order and returns them in argument order.
+func lockWallets(ctx context.Context, qtx db.Querier, first, second uuid.UUID) (db.GetWalletsAndLockByWalletIdsRow, db.GetWalletsAndLockByWalletIdsRow, error) {
+	wallets, err := qtx.GetWalletsAndLockByWalletIds(ctx, db.GetWalletsAndLockByWalletIdsParams{ID: first, ID2: second})
+	if err != nil {
+		return db.GetWalletsAndLockByWalletIdsRow{}, db.GetWalletsAndLockByWalletIdsRow{}, &utils.RetryableError{Err: err}
+	}
+	if len(wallets) != 2 {
+		return db.GetWalletsAndLockByWalletIdsRow{}, db.GetWalletsAndLockByWalletIdsRow{}, transfer.ErrWalletNotFound
+	}
+	if wallets[0].ID == first {
+		return wallets[0], wallets[1], nil
+	}
+	return wallets[1], wallets[0], nil
+}
+
+// interestFor is simple interest on an ACT/365 basis, truncated to the currency's minor unit.
+func interestFor(principal decimal.Decimal, annualRate decimal.Decimal, days int, currency string) decimal.Decimal {
+	return principal.Mul(annualRate).Mul(decimal.NewFromInt(int64(days))).Div(decimal.NewFromInt(daysPerYear)).Truncate(money.MinorUnitsOf(currency))
+}
+
+func daysBetween(from, to time.Time) int {
+	return int(today(to).Sub(today(from)).Hours() / 24)
+}
+
+// today truncates t to its UTC calendar date, the unit deposits accrue in.
+func today(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,12 @@
+package deposit
+
+import "errors"
+
+var (
+	ErrDepositNotFound = errors.New("fixed deposit not found")
+	ErrInvalidAmount   = errors.New("amount must be greater than 0")
+	ErrTenorNotOffered = errors.New("no fixed deposit rate is offered for this tenor")
+	ErrNoFixedWallet   = errors.New("user has no fixed wallet in this currency")
+	ErrSameWallet      = errors.New("a deposit cannot be funded from the fixed wallet itself")
+	ErrDepositClosed   = errors.New("fixed deposit is no longer active")
+)
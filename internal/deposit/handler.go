@@ -0,0 +1,158 @@
+package deposit
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/luponetn/paycore/internal/transfer"
+	"github.com/luponetn/paycore/pkg/money"
+)
+
+type Handler struct {
+	svc Service
+}
+
+func NewHandler(svc Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+func (h *Handler) HandleListRates(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"message": "fixed deposit rates fetched successfully",
+		"data":    h.svc.Rates(),
+	})
+}
+
+func (h *Handler) HandleCreateDeposit(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	var req CreateDepositRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	deposit, err := h.svc.CreateDeposit(c.Request.Context(), userID, req)
+	if err != nil {
+		c.AbortWithStatusJSON(depositErrorStatus(err), gin.H{
+			"message": "failed to create fixed deposit",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "fixed deposit created successfully",
+		"data":    deposit,
+	})
+}
+
+func (h *Handler) HandleListDeposits(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	deposits, err := h.svc.ListDeposits(c.Request.Context(), userID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"message": "failed to fetch fixed deposits",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "fixed deposits fetched successfully",
+		"data":    deposits,
+	})
+}
+
+func (h *Handler) HandleGetDeposit(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	depositID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid deposit id"})
+		return
+	}
+
+	deposit, err := h.svc.GetDeposit(c.Request.Context(), userID, depositID)
+	if err != nil {
+		c.AbortWithStatusJSON(depositErrorStatus(err), gin.H{
+			"message": "failed to fetch fixed deposit",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "fixed deposit fetched successfully",
+		"data":    deposit,
+	})
+}
+
+func (h *Handler) HandleBreakDeposit(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	depositID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid deposit id"})
+		return
+	}
+
+	deposit, err := h.svc.BreakDeposit(c.Request.Context(), userID, depositID)
+	if err != nil {
+		c.AbortWithStatusJSON(depositErrorStatus(err), gin.H{
+			"message": "failed to break fixed deposit",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "fixed deposit closed successfully",
+		"data":    deposit,
+	})
+}
+
+func authUserID(c *gin.Context) (uuid.UUID, bool) {
+	userIdVal, exists := c.Get("user_id")
+	if !exists {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return uuid.Nil, false
+	}
+	userID, ok := userIdVal.(uuid.UUID)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "invalid user id type"})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+func depositErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrDepositClosed), errors.Is(err, transfer.ErrInsufficientFunds):
+		return http.StatusConflict
+	case errors.Is(err, ErrInvalidAmount), errors.Is(err, money.ErrTooPrecise), errors.Is(err, money.ErrUnknownCurrency),
+		errors.Is(err, ErrTenorNotOffered), errors.Is(err, ErrSameWallet), errors.Is(err, transfer.ErrCurrencyMismatch):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrDepositNotFound), errors.Is(err, ErrNoFixedWallet), errors.Is(err, transfer.ErrWalletNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, transfer.ErrUnauthorizedWallet):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,23 @@
+package deposit
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/luponetn/paycore/internal/middleware"
+	"github.com/luponetn/paycore/pkg/utils"
+)
+
+func RegisterRoutes(r *gin.Engine, h *Handler, keys *utils.KeySet, sessions middleware.SessionChecker) {
+	depositGroup := r.Group("/deposits")
+
+	//use middlewares
+	depositGroup.Use(middleware.AuthMiddleware(keys, sessions))
+
+	//implement routes
+	{
+		depositGroup.GET("/rates", h.HandleListRates)
+		depositGroup.POST("", h.HandleCreateDeposit)
+		depositGroup.GET("", h.HandleListDeposits)
+		depositGroup.GET("/:id", h.HandleGetDeposit)
+		depositGroup.POST("/:id/break", h.HandleBreakDeposit)
+	}
+}
@@ -0,0 +1,338 @@
+package deposit
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/luponetn/paycore/internal/config"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/ledger"
+	"github.com/luponetn/paycore/internal/store"
+	"github.com/luponetn/paycore/internal/transfer"
+	"github.com/luponetn/paycore/pkg/money"
+	"github.com/luponetn/paycore/pkg/utils"
+	"github.com/shopspring/decimal"
+)
+
+type Service interface {
+	Rates() []RateResponse
+	CreateDeposit(ctx context.Context, userID uuid.UUID, req CreateDepositRequest) (db.FixedDeposit, error)
+	ListDeposits(ctx context.Context, userID uuid.UUID) ([]db.FixedDeposit, error)
+	GetDeposit(ctx context.Context, userID uuid.UUID, depositID uuid.UUID) (db.FixedDeposit, error)
+	BreakDeposit(ctx context.Context, userID uuid.UUID, depositID uuid.UUID) (db.FixedDeposit, error)
+	DueDepositIDs(ctx context.Context, asOf time.Time, limit int32) ([]uuid.UUID, error)
+	AccrueDeposit(ctx context.Context, depositID uuid.UUID, asOf time.Time) error
+}
+
+type Svc struct {
+	store store.Store
+	cfg   *config.Config
+}
+
+// NewService wires the fixed deposit service; cfg carries the offered rates and the break penalty.
+func NewService(store store.Store, cfg *config.Config) Service {
+	return &Svc{store: store, cfg: cfg}
+}
+
+// Rates lists the offered tenors, shortest first.
+func (s *Svc) Rates() []RateResponse {
+	rates := make([]RateResponse, 0, len(s.cfg.FixedDepositRates))
+	for tenor, rate := range s.cfg.FixedDepositRates {
+		rates = append(rates, RateResponse{TenorDays: tenor, AnnualRate: rate})
+	}
+	sort.Slice(rates, func(i, j int) bool { return rates[i].TenorDays < rates[j].TenorDays })
+	return rates
+}
+
+// CreateDeposit moves the principal from the source wallet into the user's fixed wallet and
+// opens a deposit at the rate currently offered for the tenor. The principal counts towards the
+// fixed wallet's balance but not its available balance until the deposit is paid out or broken,
+// and the wallet's matures_at moves out to the deposit's maturity so nothing else moved into it
+// can be debited while the term runs.
+func (s *Svc) CreateDeposit(ctx context.Context, userID uuid.UUID, req CreateDepositRequest) (db.FixedDeposit, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	rate, ok := s.cfg.FixedDepositRates[req.TenorDays]
+	if !ok {
+		return db.FixedDeposit{}, ErrTenorNotOffered
+	}
+
+	sourceID, err := uuid.Parse(req.SourceWalletID)
+	if err != nil {
+		return db.FixedDeposit{}, transfer.ErrWalletNotFound
+	}
+
+	maturityAction := db.DepositMaturityActionEnumPayout
+	if req.MaturityAction != "" {
+		maturityAction = db.DepositMaturityActionEnum(req.MaturityAction)
+	}
+
+	if existing, err := s.store.Queries().GetFixedDepositByIdempotencyKey(ctx, req.IdempotencyKey); err == nil {
+		if existing.UserID != userID {
+			return db.FixedDeposit{}, ErrDepositNotFound
+		}
+		return existing, nil
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return db.FixedDeposit{}, err
+	}
+
+	sourceWallet, err := s.store.Queries().GetWalletById(ctx, sourceID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.FixedDeposit{}, transfer.ErrWalletNotFound
+		}
+		return db.FixedDeposit{}, err
+	}
+
+	parsed, err := money.Parse(req.Amount, sourceWallet.Currency)
+	if errors.Is(err, money.ErrInvalidAmount) || (err == nil && !parsed.IsPositive()) {
+		return db.FixedDeposit{}, ErrInvalidAmount
+	}
+	if err != nil {
+		return db.FixedDeposit{}, err
+	}
+	amount := parsed.Amount()
+
+	// the deposit sits in the user's fixed wallet in the source wallet's currency
+	fixed, err := s.store.Queries().GetWalletByUserIdAndType(ctx, db.GetWalletByUserIdAndTypeParams{
+		UserID:     utils.ToPgUUID(userID),
+		WalletType: db.WalletTypeEnumFixed,
+		Currency:   sourceWallet.Currency,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.FixedDeposit{}, ErrNoFixedWallet
+		}
+		return db.FixedDeposit{}, err
+	}
+
+	if fixed.ID == sourceID {
+		return db.FixedDeposit{}, ErrSameWallet
+	}
+
+	return utils.Retry(3, 100, func() (db.FixedDeposit, error) {
+		tx, err := s.store.Begin(ctx)
+		if err != nil {
+			return db.FixedDeposit{}, &utils.RetryableError{Err: err}
+		}
+
+		defer func() {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback create deposit tx", "error", rbErr)
+			}
+		}()
+
+		qtx := s.store.WithTx(tx)
+
+		source, fixedWallet, err := lockWallets(ctx, qtx, sourceID, fixed.ID)
+		if err != nil {
+			return db.FixedDeposit{}, err
+		}
+
+		if !source.UserID.Valid || uuid.UUID(source.UserID.Bytes) != userID {
+			return db.FixedDeposit{}, transfer.ErrUnauthorizedWallet
+		}
+
+		if source.Currency != fixedWallet.Currency {
+			return db.FixedDeposit{}, transfer.ErrCurrencyMismatch
+		}
+
+		if utils.NumericToDecimal(source.AvailableBalance).LessThan(amount) {
+			return db.FixedDeposit{}, transfer.ErrInsufficientFunds
+		}
+
+		start := today(time.Now())
+		deposit, err := qtx.CreateFixedDeposit(ctx, db.CreateFixedDepositParams{
+			UserID:         userID,
+			WalletID:       fixedWallet.ID,
+			PayoutWalletID: source.ID,
+			Principal:      utils.DecimalToNumeric(amount),
+			AnnualRate:     utils.DecimalToNumeric(rate),
+			TenorDays:      req.TenorDays,
+			Currency:       fixedWallet.Currency,
+			MaturityAction: maturityAction,
+			StartDate:      pgtype.Date{Time: start, Valid: true},
+			MaturityDate:   pgtype.Date{Time: start.AddDate(0, 0, int(req.TenorDays)), Valid: true},
+			AccruedThrough: pgtype.Date{Time: start, Valid: true},
+			IdempotencyKey: req.IdempotencyKey,
+		})
+		if err != nil {
+			if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
+				existing, getErr := qtx.GetFixedDepositByIdempotencyKey(ctx, req.IdempotencyKey)
+				if getErr != nil {
+					return db.FixedDeposit{}, &utils.RetryableError{Err: getErr}
+				}
+				return existing, nil
+			}
+			return db.FixedDeposit{}, &utils.RetryableError{Err: err}
+		}
+
+		if _, err := ledger.Post(ctx, qtx, ledger.Journal{
+			TransactionType: db.TransactionTypeEnumFixedDeposit,
+			Description:     "fixed deposit funding",
+			IdempotencyKey:  "fixed_deposit:" + deposit.ID.String() + ":fund",
+			Postings: []ledger.Posting{
+				ledger.Debit(source.ID, amount, source.Currency),
+				ledger.Credit(fixedWallet.ID, amount, fixedWallet.Currency).AsLocked(),
+			},
+		}); err != nil {
+			return db.FixedDeposit{}, err
+		}
+
+		// Until the term ends nothing can be moved back out of the fixed wallet.
+		if err := qtx.RefreshWalletMaturity(ctx, fixedWallet.ID); err != nil {
+			return db.FixedDeposit{}, &utils.RetryableError{Err: err}
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return db.FixedDeposit{}, &utils.RetryableError{Err: err}
+		}
+		return deposit, nil
+	})
+}
+
+func (s *Svc) ListDeposits(ctx context.Context, userID uuid.UUID) ([]db.FixedDeposit, error) {
+	ctx, cancel := context.WithTimeout(ctx, 8*time.Second)
+	defer cancel()
+
+	return utils.Retry(3, 100, func() ([]db.FixedDeposit, error) {
+		deposits, err := s.store.Queries().GetFixedDepositsByUserId(ctx, userID)
+		if err != nil {
+			return nil, &utils.RetryableError{Err: err}
+		}
+		return deposits, nil
+	})
+}
+
+func (s *Svc) GetDeposit(ctx context.Context, userID uuid.UUID, depositID uuid.UUID) (db.FixedDeposit, error) {
+	ctx, cancel := context.WithTimeout(ctx, 8*time.Second)
+	defer cancel()
+
+	deposit, err := s.store.Queries().GetFixedDepositById(ctx, depositID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.FixedDeposit{}, ErrDepositNotFound
+		}
+		return db.FixedDeposit{}, err
+	}
+
+	// Someone else's deposit looks exactly like a missing one.
+	if deposit.UserID != userID {
+		return db.FixedDeposit{}, ErrDepositNotFound
+	}
+	return deposit, nil
+}
+
+// BreakDeposit closes an active deposit before maturity. Interest is accrued up to today, the
+// configured share of it is forfeited back to the interest expense account, and the rest is
+// paid out with the principal. A deposit that has already reached maturity is paid out in full.
+func (s *Svc) BreakDeposit(ctx context.Context, userID uuid.UUID, depositID uuid.UUID) (db.FixedDeposit, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	return s.withLockedDeposit(ctx, depositID, func(qtx db.Querier, deposit db.FixedDeposit) (db.FixedDeposit, error) {
+		if deposit.UserID != userID {
+			return db.FixedDeposit{}, ErrDepositNotFound
+		}
+		if deposit.Status != db.DepositStatusEnumActive {
+			return db.FixedDeposit{}, ErrDepositClosed
+		}
+
+		now := today(time.Now())
+		deposit, err := s.accrue(ctx, qtx, deposit, now)
+		if err != nil {
+			return db.FixedDeposit{}, err
+		}
+
+		if !now.Before(deposit.MaturityDate.Time) {
+			return s.settle(ctx, qtx, deposit, db.DepositStatusEnumPaidOut, decimal.Zero)
+		}
+
+		penalty := utils.NumericToDecimal(deposit.AccruedInterest).Mul(s.cfg.FixedDepositBreakPenalty).Truncate(money.MinorUnitsOf(deposit.Currency))
+		return s.settle(ctx, qtx, deposit, db.DepositStatusEnumBroken, penalty)
+	})
+}
+
+// DueDepositIDs lists active deposits with interest not yet accrued up to asOf.
+func (s *Svc) DueDepositIDs(ctx context.Context, asOf time.Time, limit int32) ([]uuid.UUID, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	return s.store.Queries().GetFixedDepositIdsDueForAccrual(ctx, db.GetFixedDepositIdsDueForAccrualParams{
+		AsOf:      pgtype.Date{Time: today(asOf), Valid: true},
+		BatchSize: limit,
+	})
+}
+
+// AccrueDeposit posts the interest a deposit has earned up to asOf and, once it has reached
+// maturity, pays it out or rolls it over. Running it twice for the same day is a no-op.
+func (s *Svc) AccrueDeposit(ctx context.Context, depositID uuid.UUID, asOf time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	_, err := s.withLockedDeposit(ctx, depositID, func(qtx db.Querier, deposit db.FixedDeposit) (db.FixedDeposit, error) {
+		if deposit.Status != db.DepositStatusEnumActive {
+			return deposit, nil
+		}
+
+		day := today(asOf)
+		deposit, err := s.accrue(ctx, qtx, deposit, day)
+		if err != nil {
+			return db.FixedDeposit{}, err
+		}
+
+		if day.Before(deposit.MaturityDate.Time) {
+			return deposit, nil
+		}
+
+		if deposit.MaturityAction == db.DepositMaturityActionEnumRollover {
+			return s.rollover(ctx, qtx, deposit)
+		}
+		return s.settle(ctx, qtx, deposit, db.DepositStatusEnumPaidOut, decimal.Zero)
+	})
+	return err
+}
+
+// withLockedDeposit runs fn inside a transaction holding the deposit row lock.
+func (s *Svc) withLockedDeposit(ctx context.Context, depositID uuid.UUID, fn func(qtx db.Querier, deposit db.FixedDeposit) (db.FixedDeposit, error)) (db.FixedDeposit, error) {
+	return utils.Retry(3, 100, func() (db.FixedDeposit, error) {
+		tx, err := s.store.Begin(ctx)
+		if err != nil {
+			return db.FixedDeposit{}, &utils.RetryableError{Err: err}
+		}
+
+		defer func() {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback deposit tx", "error", rbErr)
+			}
+		}()
+
+		qtx := s.store.WithTx(tx)
+
+		deposit, err := qtx.GetFixedDepositForUpdate(ctx, depositID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return db.FixedDeposit{}, ErrDepositNotFound
+			}
+			return db.FixedDeposit{}, &utils.RetryableError{Err: err}
+		}
+
+		updated, err := fn(qtx, deposit)
+		if err != nil {
+			return db.FixedDeposit{}, err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return db.FixedDeposit{}, &utils.RetryableError{Err: err}
+		}
+		return updated, nil
+	})
+}
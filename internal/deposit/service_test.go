@@ -0,0 +1,198 @@
+package deposit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/luponetn/paycore/internal/config"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/store"
+	"github.com/luponetn/paycore/pkg/money"
+	"github.com/luponetn/paycore/pkg/utils"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(f *store.FakeStore) Service {
+	return NewService(f, &config.Config{
+		FixedDepositRates:        map[int32]decimal.Decimal{30: decimal.RequireFromString("0.10")},
+		FixedDepositBreakPenalty: decimal.RequireFromString("0.5"),
+	})
+}
+
+// setupDepositWallets gives a new user a savings wallet holding balance and an empty fixed wallet.
+func setupDepositWallets(f *store.FakeStore, balance string) (uuid.UUID, uuid.UUID, uuid.UUID) {
+	userID := uuid.New()
+	savings := f.AddFakeFundedWallet(userID, db.WalletTypeEnumSavings, "NGN", balance)
+	fixed := f.AddFakeFundedWallet(userID, db.WalletTypeEnumFixed, "NGN", "0")
+	return userID, savings, fixed
+}
+
+func TestCreateDeposit_LocksPrincipalInFixedWallet(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := newTestService(f)
+	ctx := context.Background()
+	userID, savings, fixed := setupDepositWallets(f, "1000")
+
+	req := CreateDepositRequest{
+		SourceWalletID: savings.String(),
+		Amount:         "730.00",
+		TenorDays:      30,
+		IdempotencyKey: uuid.New().String(),
+	}
+	deposit, err := svc.CreateDeposit(ctx, userID, req)
+	require.NoError(t, err)
+	require.Equal(t, db.DepositStatusEnumActive, deposit.Status)
+	require.Equal(t, db.DepositMaturityActionEnumPayout, deposit.MaturityAction)
+
+	// Replaying the request returns the same deposit without moving funds again.
+	again, err := svc.CreateDeposit(ctx, userID, req)
+	require.NoError(t, err)
+	require.Equal(t, deposit.ID, again.ID)
+
+	balance, available := f.FakeBalances(savings)
+	require.Equal(t, "270.00", balance)
+	require.Equal(t, "270.00", available)
+
+	balance, available = f.FakeBalances(fixed)
+	require.Equal(t, "730.00", balance)
+	require.Equal(t, "0.00", available)
+
+	_, fixedWallet, err := lockWallets(ctx, f.Queries(), savings, fixed)
+	require.NoError(t, err)
+	require.True(t, fixedWallet.MaturesAt.Valid)
+	require.True(t, fixedWallet.MaturesAt.Time.Equal(deposit.MaturityDate.Time))
+
+	req.TenorDays = 45
+	req.IdempotencyKey = uuid.New().String()
+	_, err = svc.CreateDeposit(ctx, userID, req)
+	require.ErrorIs(t, err, ErrTenorNotOffered)
+
+	// Amounts are held to the currency's minor unit like every other money movement.
+	req.TenorDays = 30
+	req.Amount = "10.005"
+	_, err = svc.CreateDeposit(ctx, userID, req)
+	require.ErrorIs(t, err, money.ErrTooPrecise)
+
+	req.Amount = "-5"
+	_, err = svc.CreateDeposit(ctx, userID, req)
+	require.ErrorIs(t, err, ErrInvalidAmount)
+}
+
+func TestAccrueDeposit_PaysOutAtMaturity(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := newTestService(f)
+	ctx := context.Background()
+	userID, savings, fixed := setupDepositWallets(f, "730")
+
+	deposit, err := svc.CreateDeposit(ctx, userID, CreateDepositRequest{
+		SourceWalletID: savings.String(),
+		Amount:         "730.00",
+		TenorDays:      30,
+		IdempotencyKey: uuid.New().String(),
+	})
+	require.NoError(t, err)
+
+	// 730 at 10% earns 0.20 a day.
+	start := deposit.StartDate.Time
+	require.NoError(t, svc.AccrueDeposit(ctx, deposit.ID, start.AddDate(0, 0, 10)))
+	require.NoError(t, svc.AccrueDeposit(ctx, deposit.ID, start.AddDate(0, 0, 10)))
+
+	got, err := svc.GetDeposit(ctx, userID, deposit.ID)
+	require.NoError(t, err)
+	require.Equal(t, "2.00", utils.NumericToDecimal(got.AccruedInterest).StringFixed(2))
+
+	balance, available := f.FakeBalances(fixed)
+	require.Equal(t, "732.00", balance)
+	require.Equal(t, "0.00", available)
+
+	ids, err := svc.DueDepositIDs(ctx, start.AddDate(0, 0, 40), 10)
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{deposit.ID}, ids)
+
+	// Accrual stops at the maturity date even if the job runs late.
+	require.NoError(t, svc.AccrueDeposit(ctx, deposit.ID, start.AddDate(0, 0, 40)))
+
+	got, err = svc.GetDeposit(ctx, userID, deposit.ID)
+	require.NoError(t, err)
+	require.Equal(t, db.DepositStatusEnumPaidOut, got.Status)
+	require.Equal(t, "6.00", utils.NumericToDecimal(got.AccruedInterest).StringFixed(2))
+
+	balance, available = f.FakeBalances(savings)
+	require.Equal(t, "736.00", balance)
+	require.Equal(t, "736.00", available)
+
+	balance, _ = f.FakeBalances(fixed)
+	require.Equal(t, "0.00", balance)
+}
+
+func TestAccrueDeposit_RollsOverPrincipalAndInterest(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := newTestService(f)
+	ctx := context.Background()
+	userID, savings, fixed := setupDepositWallets(f, "730")
+
+	deposit, err := svc.CreateDeposit(ctx, userID, CreateDepositRequest{
+		SourceWalletID: savings.String(),
+		Amount:         "730.00",
+		TenorDays:      30,
+		MaturityAction: "rollover",
+		IdempotencyKey: uuid.New().String(),
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.AccrueDeposit(ctx, deposit.ID, deposit.MaturityDate.Time))
+
+	deposits, err := svc.ListDeposits(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, deposits, 2)
+
+	var renewed db.FixedDeposit
+	for _, d := range deposits {
+		if d.ID == deposit.ID {
+			require.Equal(t, db.DepositStatusEnumRolledOver, d.Status)
+		} else {
+			renewed = d
+		}
+	}
+	require.Equal(t, db.DepositStatusEnumActive, renewed.Status)
+	require.Equal(t, "736.00", utils.NumericToDecimal(renewed.Principal).StringFixed(2))
+	require.Equal(t, pgtype.UUID{Bytes: deposit.ID, Valid: true}, renewed.ParentDepositID)
+
+	balance, available := f.FakeBalances(fixed)
+	require.Equal(t, "736.00", balance)
+	require.Equal(t, "0.00", available)
+}
+
+func TestBreakDeposit_ForfeitsPenaltyShareOfInterest(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := newTestService(f)
+	ctx := context.Background()
+	userID, savings, _ := setupDepositWallets(f, "730")
+
+	deposit, err := svc.CreateDeposit(ctx, userID, CreateDepositRequest{
+		SourceWalletID: savings.String(),
+		Amount:         "730.00",
+		TenorDays:      30,
+		IdempotencyKey: uuid.New().String(),
+	})
+	require.NoError(t, err)
+	require.NoError(t, svc.AccrueDeposit(ctx, deposit.ID, time.Now().AddDate(0, 0, 5)))
+
+	_, err = svc.BreakDeposit(ctx, uuid.New(), deposit.ID)
+	require.ErrorIs(t, err, ErrDepositNotFound)
+
+	broken, err := svc.BreakDeposit(ctx, userID, deposit.ID)
+	require.NoError(t, err)
+	require.Equal(t, db.DepositStatusEnumBroken, broken.Status)
+	require.Equal(t, "0.50", utils.NumericToDecimal(broken.Penalty).StringFixed(2))
+
+	balance, _ := f.FakeBalances(savings)
+	require.Equal(t, "730.50", balance)
+
+	_, err = svc.BreakDeposit(ctx, userID, deposit.ID)
+	require.ErrorIs(t, err, ErrDepositClosed)
+}
@@ -0,0 +1,16 @@
+package deposit
+
+import "github.com/shopspring/decimal"
+
+type CreateDepositRequest struct {
+	SourceWalletID string `json:"source_wallet_id" binding:"required,uuid"` // funds the deposit and receives the payout
+	Amount         string `json:"amount" binding:"required"`
+	TenorDays      int32  `json:"tenor_days" binding:"required,min=1"`
+	MaturityAction string `json:"maturity_action" binding:"omitempty,oneof=payout rollover"`
+	IdempotencyKey string `json:"idempotency_key" binding:"required"`
+}
+
+type RateResponse struct {
+	TenorDays  int32           `json:"tenor_days"`
+	AnnualRate decimal.Decimal `json:"annual_rate"`
+}
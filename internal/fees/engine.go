@@ -0,0 +1,67 @@
+package fees
+
+import (
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/pkg/money"
+	"github.com/luponetn/paycore/pkg/utils"
+	"github.com/shopspring/decimal"
+)
+
+// Calculate prices amount under the schedule: a flat fee, a percentage of the amount, or the
+// flat part and percentage of the band the amount falls in, then held within the min and max.
+// The percentage part is rounded to the currency's minor unit.
+func Calculate(schedule Schedule, amount decimal.Decimal, currency string) Breakdown {
+	var flat, rate decimal.Decimal
+	switch schedule.FeeType {
+	case db.FeeTypeEnumFlat:
+		flat = utils.NumericToDecimal(schedule.FlatAmount)
+	case db.FeeTypeEnumPercentage:
+		rate = utils.NumericToDecimal(schedule.Rate)
+	case db.FeeTypeEnumTiered:
+		if band, ok := bandFor(schedule.Tiers, amount); ok {
+			flat = utils.NumericToDecimal(band.FlatAmount)
+			rate = utils.NumericToDecimal(band.Rate)
+		}
+	}
+
+	percentage := amount.Mul(rate).Round(money.MinorUnitsOf(currency))
+	raw := flat.Add(percentage)
+	fee := raw
+	if schedule.MinFee.Valid {
+		fee = decimal.Max(fee, utils.NumericToDecimal(schedule.MinFee))
+	}
+	if schedule.MaxFee.Valid {
+		fee = decimal.Min(fee, utils.NumericToDecimal(schedule.MaxFee))
+	}
+
+	id := schedule.ID
+	return Breakdown{
+		ScheduleID:    &id,
+		FeeType:       string(schedule.FeeType),
+		Amount:        amount,
+		FlatFee:       flat,
+		PercentageFee: percentage,
+		Adjustment:    fee.Sub(raw),
+		Fee:           fee,
+		Total:         amount.Add(fee),
+		Currency:      currency,
+	}
+}
+
+// NoFee is the breakdown of a transaction no schedule applies to.
+func NoFee(amount decimal.Decimal, currency string) Breakdown {
+	return Breakdown{Amount: amount, Total: amount, Currency: currency}
+}
+
+// bandFor picks the band with the highest floor not above amount; tiers are sorted by floor.
+func bandFor(tiers []db.FeeScheduleTier, amount decimal.Decimal) (db.FeeScheduleTier, bool) {
+	var band db.FeeScheduleTier
+	found := false
+	for _, tier := range tiers {
+		if utils.NumericToDecimal(tier.Floor).GreaterThan(amount) {
+			break
+		}
+		band, found = tier, true
+	}
+	return band, found
+}
@@ -0,0 +1,61 @@
+package fees
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/pkg/utils"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func numeric(s string) pgtype.Numeric {
+	return utils.DecimalToNumeric(decimal.RequireFromString(s))
+}
+
+func feeFor(schedule Schedule, amount string) string {
+	return Calculate(schedule, decimal.RequireFromString(amount), "NGN").Fee.StringFixed(2)
+}
+
+func TestCalculate(t *testing.T) {
+	flat := Schedule{FeeSchedule: db.FeeSchedule{ID: uuid.New(), FeeType: db.FeeTypeEnumFlat, FlatAmount: numeric("10")}}
+	require.Equal(t, "10.00", feeFor(flat, "1"))
+	require.Equal(t, "10.00", feeFor(flat, "1000000"))
+
+	// 1.5%, at least 5 and at most 100
+	percentage := Schedule{FeeSchedule: db.FeeSchedule{
+		ID:      uuid.New(),
+		FeeType: db.FeeTypeEnumPercentage,
+		Rate:    numeric("0.015"),
+		MinFee:  numeric("5"),
+		MaxFee:  numeric("100"),
+	}}
+	require.Equal(t, "15.00", feeFor(percentage, "1000"))
+	require.Equal(t, "5.00", feeFor(percentage, "100"))
+	require.Equal(t, "100.00", feeFor(percentage, "50000"))
+
+	b := Calculate(percentage, decimal.RequireFromString("100"), "NGN")
+	require.Equal(t, "1.50", b.PercentageFee.StringFixed(2))
+	require.Equal(t, "3.50", b.Adjustment.StringFixed(2))
+	require.Equal(t, "105.00", b.Total.StringFixed(2))
+	require.Equal(t, percentage.ID, *b.ScheduleID)
+
+	tiered := Schedule{
+		FeeSchedule: db.FeeSchedule{ID: uuid.New(), FeeType: db.FeeTypeEnumTiered},
+		Tiers: []db.FeeScheduleTier{
+			{Floor: numeric("0"), FlatAmount: numeric("10"), Rate: numeric("0")},
+			{Floor: numeric("5000"), FlatAmount: numeric("25"), Rate: numeric("0")},
+			{Floor: numeric("50000"), FlatAmount: numeric("0"), Rate: numeric("0.001")},
+		},
+	}
+	require.Equal(t, "10.00", feeFor(tiered, "4999.99"))
+	require.Equal(t, "25.00", feeFor(tiered, "5000"))
+	require.Equal(t, "80.00", feeFor(tiered, "80000"))
+
+	free := NoFee(decimal.RequireFromString("20"), "NGN")
+	require.Nil(t, free.ScheduleID)
+	require.True(t, free.Fee.IsZero())
+	require.Equal(t, "20", free.Total.String())
+}
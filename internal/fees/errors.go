@@ -0,0 +1,8 @@
+package fees
+
+import "errors"
+
+var (
+	ErrInvalidSchedule  = errors.New("invalid fee schedule")
+	ErrScheduleNotFound = errors.New("fee schedule not found")
+)
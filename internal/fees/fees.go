@@ -0,0 +1,70 @@
+package fees
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/pkg/utils"
+	"github.com/shopspring/decimal"
+)
+
+// Quote prices a transaction of txType for a user on kycTier. A schedule for the user's own tier
+// wins over one for every tier; with neither, the transaction is free. q may be the caller's
+// transaction.
+func Quote(ctx context.Context, q db.Querier, txType db.TransactionTypeEnum, currency string, kycTier int16, amount decimal.Decimal) (Breakdown, error) {
+	row, err := q.FindFeeSchedule(ctx, db.FindFeeScheduleParams{
+		TransactionType: txType,
+		Currency:        currency,
+		KycTier:         kycTier,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return NoFee(amount, currency), nil
+		}
+		return Breakdown{}, err
+	}
+
+	schedule, err := withTiers(ctx, q, row)
+	if err != nil {
+		return Breakdown{}, err
+	}
+	return Calculate(schedule, amount, currency), nil
+}
+
+// Explain rebuilds the breakdown of a posted transaction from the schedule it was charged
+// under. Schedules are never edited, so the result matches the fee that was charged.
+func Explain(ctx context.Context, q db.Querier, txn db.Transaction) (Breakdown, error) {
+	amount := utils.NumericToDecimal(txn.Amount)
+	if !txn.FeeScheduleID.Valid {
+		return NoFee(amount, txn.Currency), nil
+	}
+
+	row, err := q.GetFeeScheduleById(ctx, txn.FeeScheduleID.Bytes)
+	if err != nil {
+		return Breakdown{}, err
+	}
+
+	schedule, err := withTiers(ctx, q, row)
+	if err != nil {
+		return Breakdown{}, err
+	}
+	return Calculate(schedule, amount, txn.Currency), nil
+}
+
+func withTiers(ctx context.Context, q db.Querier, row db.FeeSchedule) (Schedule, error) {
+	schedule := Schedule{FeeSchedule: row, Tiers: []db.FeeScheduleTier{}}
+	if row.FeeType != db.FeeTypeEnumTiered {
+		return schedule, nil
+	}
+
+	tiers, err := q.GetFeeScheduleTiers(ctx, row.ID)
+	if err != nil {
+		return Schedule{}, err
+	}
+	if tiers != nil {
+		schedule.Tiers = tiers
+	}
+	return schedule, nil
+}
@@ -0,0 +1,85 @@
+package fees
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type Handler struct {
+	svc Service
+}
+
+func NewHandler(svc Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+func (h *Handler) HandleListSchedules(c *gin.Context) {
+	schedules, err := h.svc.Schedules(c.Request.Context())
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"message": "failed to fetch fee schedules",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "fee schedules fetched successfully",
+		"data":    schedules,
+	})
+}
+
+func (h *Handler) HandleCreateSchedule(c *gin.Context) {
+	var req CreateScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	schedule, err := h.svc.CreateSchedule(c.Request.Context(), req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, ErrInvalidSchedule) {
+			status = http.StatusBadRequest
+		}
+		c.AbortWithStatusJSON(status, gin.H{
+			"message": "failed to create fee schedule",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "fee schedule created successfully",
+		"data":    schedule,
+	})
+}
+
+func (h *Handler) HandleDeactivateSchedule(c *gin.Context) {
+	scheduleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid fee schedule id"})
+		return
+	}
+
+	schedule, err := h.svc.DeactivateSchedule(c.Request.Context(), scheduleID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, ErrScheduleNotFound) {
+			status = http.StatusNotFound
+		}
+		c.AbortWithStatusJSON(status, gin.H{
+			"message": "failed to deactivate fee schedule",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "fee schedule deactivated successfully",
+		"data":    schedule,
+	})
+}
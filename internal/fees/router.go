@@ -0,0 +1,20 @@
+package fees
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/luponetn/paycore/internal/middleware"
+)
+
+func RegisterRoutes(r *gin.Engine, h *Handler, adminKey string) {
+	feesGroup := r.Group("/admin/fees")
+
+	//use middlewares
+	feesGroup.Use(middleware.AdminKeyMiddleware(adminKey))
+
+	//implement routes
+	{
+		feesGroup.GET("", h.HandleListSchedules)
+		feesGroup.POST("", h.HandleCreateSchedule)
+		feesGroup.DELETE("/:id", h.HandleDeactivateSchedule)
+	}
+}
@@ -0,0 +1,264 @@
+package fees
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/store"
+	"github.com/luponetn/paycore/pkg/money"
+	"github.com/luponetn/paycore/pkg/utils"
+	"github.com/shopspring/decimal"
+)
+
+type Service interface {
+	Schedules(ctx context.Context) ([]Schedule, error)
+	CreateSchedule(ctx context.Context, req CreateScheduleRequest) (Schedule, error)
+	DeactivateSchedule(ctx context.Context, scheduleID uuid.UUID) (db.FeeSchedule, error)
+}
+
+type Svc struct {
+	store store.Store
+}
+
+func NewService(store store.Store) Service {
+	return &Svc{store: store}
+}
+
+// Schedules lists the active schedules.
+func (s *Svc) Schedules(ctx context.Context) ([]Schedule, error) {
+	ctx, cancel := context.WithTimeout(ctx, 8*time.Second)
+	defer cancel()
+
+	rows, err := s.store.Queries().GetActiveFeeSchedules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules := make([]Schedule, 0, len(rows))
+	for _, row := range rows {
+		schedule, err := withTiers(ctx, s.store.Queries(), row)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules, nil
+}
+
+// CreateSchedule adds a schedule, deactivating the one it replaces: the active schedule for the
+// same transaction type, currency and tier.
+func (s *Svc) CreateSchedule(ctx context.Context, req CreateScheduleRequest) (Schedule, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	params, tiers, err := parseSchedule(req)
+	if err != nil {
+		return Schedule{}, err
+	}
+
+	return utils.Retry(3, 100, func() (Schedule, error) {
+		tx, err := s.store.Begin(ctx)
+		if err != nil {
+			return Schedule{}, &utils.RetryableError{Err: err}
+		}
+
+		defer func() {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback create fee schedule tx", "error", rbErr)
+			}
+		}()
+
+		qtx := s.store.WithTx(tx)
+
+		if err := qtx.DeactivateActiveFeeSchedule(ctx, db.DeactivateActiveFeeScheduleParams{
+			TransactionType: params.TransactionType,
+			Currency:        params.Currency,
+			KycTier:         params.KycTier.Int16,
+		}); err != nil {
+			return Schedule{}, &utils.RetryableError{Err: err}
+		}
+
+		// a concurrent create for the same key fails the unique index and is retried
+		row, err := qtx.CreateFeeSchedule(ctx, params)
+		if err != nil {
+			return Schedule{}, &utils.RetryableError{Err: err}
+		}
+
+		for i := range tiers {
+			tiers[i].ScheduleID = row.ID
+			if err := qtx.CreateFeeScheduleTier(ctx, tiers[i]); err != nil {
+				return Schedule{}, &utils.RetryableError{Err: err}
+			}
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return Schedule{}, &utils.RetryableError{Err: err}
+		}
+
+		schedule := Schedule{FeeSchedule: row, Tiers: []db.FeeScheduleTier{}}
+		for _, tier := range tiers {
+			schedule.Tiers = append(schedule.Tiers, db.FeeScheduleTier(tier))
+		}
+		return schedule, nil
+	})
+}
+
+// DeactivateSchedule retires a schedule; the transaction type it priced becomes free again
+// unless a schedule for every tier still applies.
+func (s *Svc) DeactivateSchedule(ctx context.Context, scheduleID uuid.UUID) (db.FeeSchedule, error) {
+	ctx, cancel := context.WithTimeout(ctx, 8*time.Second)
+	defer cancel()
+
+	schedule, err := s.store.Queries().DeactivateFeeSchedule(ctx, scheduleID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.FeeSchedule{}, ErrScheduleNotFound
+		}
+		return db.FeeSchedule{}, err
+	}
+	return schedule, nil
+}
+
+// parseSchedule validates the request and converts it to query params; tiers come back sorted by
+// floor without their schedule id.
+func parseSchedule(req CreateScheduleRequest) (db.CreateFeeScheduleParams, []db.CreateFeeScheduleTierParams, error) {
+	flat, err := parseAmount(req.FlatAmount, req.Currency, "flat_amount")
+	if err != nil {
+		return db.CreateFeeScheduleParams{}, nil, err
+	}
+	rate, err := parseRate(req.Rate, "rate")
+	if err != nil {
+		return db.CreateFeeScheduleParams{}, nil, err
+	}
+	minFee, err := parseCap(req.MinFee, req.Currency, "min_fee")
+	if err != nil {
+		return db.CreateFeeScheduleParams{}, nil, err
+	}
+	maxFee, err := parseCap(req.MaxFee, req.Currency, "max_fee")
+	if err != nil {
+		return db.CreateFeeScheduleParams{}, nil, err
+	}
+	if minFee.Valid && maxFee.Valid && utils.NumericToDecimal(minFee).GreaterThan(utils.NumericToDecimal(maxFee)) {
+		return db.CreateFeeScheduleParams{}, nil, fmt.Errorf("%w: min_fee is above max_fee", ErrInvalidSchedule)
+	}
+
+	feeType := db.FeeTypeEnum(req.FeeType)
+	switch feeType {
+	case db.FeeTypeEnumFlat:
+		if !flat.IsPositive() {
+			return db.CreateFeeScheduleParams{}, nil, fmt.Errorf("%w: a flat schedule needs a flat_amount", ErrInvalidSchedule)
+		}
+	case db.FeeTypeEnumPercentage:
+		if !rate.IsPositive() {
+			return db.CreateFeeScheduleParams{}, nil, fmt.Errorf("%w: a percentage schedule needs a rate", ErrInvalidSchedule)
+		}
+	}
+
+	var tiers []db.CreateFeeScheduleTierParams
+	if feeType == db.FeeTypeEnumTiered {
+		tiers, err = parseTiers(req.Tiers, req.Currency)
+		if err != nil {
+			return db.CreateFeeScheduleParams{}, nil, err
+		}
+	} else if len(req.Tiers) > 0 {
+		return db.CreateFeeScheduleParams{}, nil, fmt.Errorf("%w: only a tiered schedule has tiers", ErrInvalidSchedule)
+	}
+
+	params := db.CreateFeeScheduleParams{
+		TransactionType: db.TransactionTypeEnum(req.TransactionType),
+		Currency:        req.Currency,
+		FeeType:         feeType,
+		FlatAmount:      utils.DecimalToNumeric(flat),
+		Rate:            utils.DecimalToNumeric(rate),
+		MinFee:          minFee,
+		MaxFee:          maxFee,
+	}
+	if req.KycTier != nil {
+		params.KycTier = pgtype.Int2{Int16: *req.KycTier, Valid: true}
+	}
+	return params, tiers, nil
+}
+
+func parseTiers(reqs []TierRequest, currency string) ([]db.CreateFeeScheduleTierParams, error) {
+	if len(reqs) == 0 {
+		return nil, fmt.Errorf("%w: a tiered schedule needs tiers", ErrInvalidSchedule)
+	}
+
+	tiers := make([]db.CreateFeeScheduleTierParams, 0, len(reqs))
+	for _, req := range reqs {
+		floor, err := parseAmount(req.Floor, currency, "floor")
+		if err != nil {
+			return nil, err
+		}
+		flat, err := parseAmount(req.FlatAmount, currency, "tier flat_amount")
+		if err != nil {
+			return nil, err
+		}
+		rate, err := parseRate(req.Rate, "tier rate")
+		if err != nil {
+			return nil, err
+		}
+		tiers = append(tiers, db.CreateFeeScheduleTierParams{
+			Floor:      utils.DecimalToNumeric(floor),
+			FlatAmount: utils.DecimalToNumeric(flat),
+			Rate:       utils.DecimalToNumeric(rate),
+		})
+	}
+
+	floor := func(i int) decimal.Decimal { return utils.NumericToDecimal(tiers[i].Floor) }
+	sort.Slice(tiers, func(i, k int) bool { return floor(i).LessThan(floor(k)) })
+	if !floor(0).IsZero() {
+		return nil, fmt.Errorf("%w: the lowest tier must start at 0", ErrInvalidSchedule)
+	}
+	for i := 1; i < len(tiers); i++ {
+		if floor(i).Equal(floor(i - 1)) {
+			return nil, fmt.Errorf("%w: two tiers share the floor %s", ErrInvalidSchedule, floor(i))
+		}
+	}
+	return tiers, nil
+}
+
+// parseAmount reads an optional non-negative amount of the schedule's currency; empty means zero.
+func parseAmount(raw string, currency string, field string) (decimal.Decimal, error) {
+	if raw == "" {
+		return decimal.Zero, nil
+	}
+	m, err := money.Parse(raw, currency)
+	if err != nil || m.IsNegative() {
+		return decimal.Zero, fmt.Errorf("%w: %s must be a non-negative %s amount", ErrInvalidSchedule, field, strings.ToUpper(currency))
+	}
+	return m.Amount(), nil
+}
+
+// parseRate reads an optional rate between 0 and 1; empty means zero.
+func parseRate(raw string, field string) (decimal.Decimal, error) {
+	if raw == "" {
+		return decimal.Zero, nil
+	}
+	d, err := decimal.NewFromString(raw)
+	if err != nil || d.IsNegative() || d.GreaterThan(decimal.NewFromInt(1)) {
+		return decimal.Zero, fmt.Errorf("%w: %s must be a fraction between 0 and 1", ErrInvalidSchedule, field)
+	}
+	return d, nil
+}
+
+// parseCap reads an optional min or max fee; empty means no cap.
+func parseCap(raw string, currency string, field string) (pgtype.Numeric, error) {
+	if raw == "" {
+		return pgtype.Numeric{}, nil
+	}
+	d, err := parseAmount(raw, currency, field)
+	if err != nil {
+		return pgtype.Numeric{}, err
+	}
+	return utils.DecimalToNumeric(d), nil
+}
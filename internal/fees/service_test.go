@@ -0,0 +1,95 @@
+package fees
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/store"
+	"github.com/luponetn/paycore/pkg/utils"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateSchedule_ReplacesAndPrefersOwnTier(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := NewService(f)
+	ctx := context.Background()
+	amount := decimal.NewFromInt(1000)
+
+	quote, err := Quote(ctx, f.Queries(), db.TransactionTypeEnumTransfer, "NGN", 2, amount)
+	require.NoError(t, err)
+	require.Nil(t, quote.ScheduleID)
+
+	_, err = svc.CreateSchedule(ctx, CreateScheduleRequest{
+		TransactionType: "transfer",
+		Currency:        "NGN",
+		FeeType:         "flat",
+		FlatAmount:      "10",
+	})
+	require.NoError(t, err)
+
+	replacement, err := svc.CreateSchedule(ctx, CreateScheduleRequest{
+		TransactionType: "transfer",
+		Currency:        "NGN",
+		FeeType:         "tiered",
+		Tiers: []TierRequest{
+			{Floor: "500", FlatAmount: "20"},
+			{Floor: "0", FlatAmount: "5"},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, replacement.Tiers, 2)
+	require.Equal(t, "0", utils.NumericToDecimal(replacement.Tiers[0].Floor).String())
+
+	tier := int16(2)
+	own, err := svc.CreateSchedule(ctx, CreateScheduleRequest{
+		TransactionType: "transfer",
+		Currency:        "NGN",
+		KycTier:         &tier,
+		FeeType:         "percentage",
+		Rate:            "0.001",
+	})
+	require.NoError(t, err)
+	require.Equal(t, pgtype.Int2{Int16: 2, Valid: true}, own.KycTier)
+
+	schedules, err := svc.Schedules(ctx)
+	require.NoError(t, err)
+	require.Len(t, schedules, 2)
+
+	quote, err = Quote(ctx, f.Queries(), db.TransactionTypeEnumTransfer, "NGN", 1, amount)
+	require.NoError(t, err)
+	require.Equal(t, replacement.ID, *quote.ScheduleID)
+	require.Equal(t, "20.00", quote.Fee.StringFixed(2))
+
+	quote, err = Quote(ctx, f.Queries(), db.TransactionTypeEnumTransfer, "NGN", 2, amount)
+	require.NoError(t, err)
+	require.Equal(t, own.ID, *quote.ScheduleID)
+	require.Equal(t, "1.00", quote.Fee.StringFixed(2))
+
+	_, err = svc.DeactivateSchedule(ctx, own.ID)
+	require.NoError(t, err)
+	_, err = svc.DeactivateSchedule(ctx, own.ID)
+	require.ErrorIs(t, err, ErrScheduleNotFound)
+}
+
+func TestCreateSchedule_Validates(t *testing.T) {
+	svc := NewService(store.NewFakeStore())
+	ctx := context.Background()
+
+	invalid := []CreateScheduleRequest{
+		{TransactionType: "transfer", Currency: "NGN", FeeType: "flat"},
+		{TransactionType: "transfer", Currency: "NGN", FeeType: "percentage", Rate: "1.5"},
+		{TransactionType: "transfer", Currency: "NGN", FeeType: "flat", FlatAmount: "10", MinFee: "20", MaxFee: "5"},
+		{TransactionType: "transfer", Currency: "NGN", FeeType: "flat", FlatAmount: "10.001"},
+		{TransactionType: "transfer", Currency: "NGN", FeeType: "tiered"},
+		{TransactionType: "transfer", Currency: "NGN", FeeType: "tiered", Tiers: []TierRequest{{Floor: "100", FlatAmount: "5"}}},
+		{TransactionType: "transfer", Currency: "NGN", FeeType: "tiered", Tiers: []TierRequest{{Floor: "0"}, {Floor: "0.00"}}},
+		{TransactionType: "transfer", Currency: "NGN", FeeType: "flat", FlatAmount: "10", Tiers: []TierRequest{{Floor: "0"}}},
+	}
+	for _, req := range invalid {
+		_, err := svc.CreateSchedule(ctx, req)
+		require.ErrorIs(t, err, ErrInvalidSchedule, "%+v", req)
+	}
+}
@@ -0,0 +1,45 @@
+package fees
+
+import (
+	"github.com/google/uuid"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/shopspring/decimal"
+)
+
+// Schedule is a fee schedule together with its bands, which only tiered schedules have.
+type Schedule struct {
+	db.FeeSchedule
+	Tiers []db.FeeScheduleTier `json:"tiers"`
+}
+
+// Breakdown explains the fee on one transaction. Total is what the sender is debited: the
+// amount plus the fee. Adjustment is what the min or max cap added to or took off the fee.
+type Breakdown struct {
+	ScheduleID    *uuid.UUID      `json:"schedule_id"`
+	FeeType       string          `json:"fee_type,omitempty"`
+	Amount        decimal.Decimal `json:"amount"`
+	FlatFee       decimal.Decimal `json:"flat_fee"`
+	PercentageFee decimal.Decimal `json:"percentage_fee"`
+	Adjustment    decimal.Decimal `json:"adjustment"`
+	Fee           decimal.Decimal `json:"fee"`
+	Total         decimal.Decimal `json:"total"`
+	Currency      string          `json:"currency"`
+}
+
+type TierRequest struct {
+	Floor      string `json:"floor" binding:"required"`
+	FlatAmount string `json:"flat_amount"`
+	Rate       string `json:"rate"` // fraction of the amount, e.g. 0.015 for 1.5%
+}
+
+type CreateScheduleRequest struct {
+	TransactionType string        `json:"transaction_type" binding:"required,oneof=transfer deposit withdrawal internal_move"`
+	Currency        string        `json:"currency" binding:"required,len=3"`
+	KycTier         *int16        `json:"kyc_tier" binding:"omitempty,min=1,max=3"` // omit to apply to every tier
+	FeeType         string        `json:"fee_type" binding:"required,oneof=flat percentage tiered"`
+	FlatAmount      string        `json:"flat_amount"`
+	Rate            string        `json:"rate"`
+	MinFee          string        `json:"min_fee"`
+	MaxFee          string        `json:"max_fee"`
+	Tiers           []TierRequest `json:"tiers" binding:"omitempty,dive"`
+}
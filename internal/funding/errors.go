@@ -0,0 +1,17 @@
+package funding
+
+import "errors"
+
+var (
+	ErrVirtualAccountNotFound = errors.New("virtual account not found")
+	ErrDepositNotFound        = errors.New("deposit not found")
+	ErrWalletNotEligible      = errors.New("virtual accounts cannot be issued for fixed wallets")
+	ErrUnknownProvider        = errors.New("unknown funding provider")
+	ErrInvalidSignature       = errors.New("callback signature is missing, stale or does not match")
+	ErrInvalidCallback        = errors.New("callback is missing a reference, account number, amount or status")
+	ErrCurrencyMismatch       = errors.New("payment currency does not match the virtual account")
+	ErrCallbackMismatch       = errors.New("callback disagrees with an earlier callback for the same reference")
+	ErrInvalidTransition      = errors.New("deposit has already reached a different final status")
+	ErrPaymentNotFound        = errors.New("simulated payment not found")
+	ErrPaymentNotPending      = errors.New("simulated payment is no longer pending")
+)
@@ -0,0 +1,246 @@
+package funding
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/luponetn/paycore/internal/transfer"
+	"github.com/luponetn/paycore/pkg/money"
+)
+
+// maxCallbackBody caps how much of a provider callback is read.
+const maxCallbackBody = 64 << 10
+
+type Handler struct {
+	svc Service
+	// sim drives the simulator's admin endpoints; nil when a real provider is configured.
+	sim *Simulator
+}
+
+func NewHandler(svc Service, sim *Simulator) *Handler {
+	return &Handler{svc: svc, sim: sim}
+}
+
+func (h *Handler) HandleCreateVirtualAccount(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	var req CreateVirtualAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	account, err := h.svc.CreateVirtualAccount(c.Request.Context(), userID, req)
+	if err != nil {
+		c.AbortWithStatusJSON(fundingErrorStatus(err), gin.H{
+			"message": "failed to create virtual account",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "virtual account ready, transfers into it credit the wallet",
+		"data":    account,
+	})
+}
+
+func (h *Handler) HandleListVirtualAccounts(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	accounts, err := h.svc.ListVirtualAccounts(c.Request.Context(), userID)
+	if err != nil {
+		c.AbortWithStatusJSON(fundingErrorStatus(err), gin.H{
+			"message": "failed to fetch virtual accounts",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "virtual accounts fetched successfully",
+		"data":    accounts,
+	})
+}
+
+func (h *Handler) HandleListDeposits(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	var query ListDepositsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid query parameters", "details": err.Error()})
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = 20
+	}
+
+	deposits, err := h.svc.ListDeposits(c.Request.Context(), userID, query.Limit)
+	if err != nil {
+		c.AbortWithStatusJSON(fundingErrorStatus(err), gin.H{
+			"message": "failed to fetch deposits",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "deposits fetched successfully",
+		"data":    deposits,
+	})
+}
+
+func (h *Handler) HandleGetDeposit(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	depositID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid deposit id"})
+		return
+	}
+
+	deposit, err := h.svc.GetDeposit(c.Request.Context(), userID, depositID)
+	if err != nil {
+		c.AbortWithStatusJSON(fundingErrorStatus(err), gin.H{
+			"message": "failed to fetch deposit",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "deposit fetched successfully",
+		"data":    deposit,
+	})
+}
+
+// HandleCallback receives a provider's payment callbacks. The signature covers the raw body, so
+// it is read as sent rather than bound.
+func (h *Handler) HandleCallback(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxCallbackBody))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "failed to read callback body"})
+		return
+	}
+
+	deposit, err := h.svc.HandleCallback(c.Request.Context(), c.Param("provider"), c.Request.Header, body)
+	if err != nil {
+		c.AbortWithStatusJSON(fundingErrorStatus(err), gin.H{
+			"message": "failed to process callback",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "callback processed",
+		"data":    gin.H{"id": deposit.ID, "status": deposit.Status},
+	})
+}
+
+func (h *Handler) HandleSimulatePayment(c *gin.Context) {
+	var req SimulatePaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	callback, err := h.sim.Pay(c.Request.Context(), req)
+	h.respondSimulated(c, callback, err)
+}
+
+func (h *Handler) HandleSimulateSettle(c *gin.Context) {
+	callback, err := h.sim.Settle(c.Request.Context(), c.Param("reference"))
+	h.respondSimulated(c, callback, err)
+}
+
+func (h *Handler) HandleSimulateFail(c *gin.Context) {
+	var req FailPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	callback, err := h.sim.Fail(c.Request.Context(), c.Param("reference"), req.Reason)
+	h.respondSimulated(c, callback, err)
+}
+
+func (h *Handler) HandleSimulateResend(c *gin.Context) {
+	callback, err := h.sim.Resend(c.Request.Context(), c.Param("reference"))
+	h.respondSimulated(c, callback, err)
+}
+
+// respondSimulated reports a simulator step. The payment has advanced even when its callback
+// could not be delivered, so that is reported alongside it rather than as a failure.
+func (h *Handler) respondSimulated(c *gin.Context, callback Callback, err error) {
+	switch {
+	case errors.Is(err, ErrPaymentNotFound):
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrPaymentNotPending):
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, money.ErrInvalidAmount), errors.Is(err, money.ErrTooPrecise), errors.Is(err, money.ErrUnknownCurrency):
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case err != nil && callback.Reference == "":
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusAccepted, gin.H{
+			"message":        "payment updated but the callback was not delivered, resend it",
+			"callback_error": err.Error(),
+			"data":           callback,
+		})
+	default:
+		c.JSON(http.StatusOK, gin.H{
+			"message": "payment updated and callback delivered",
+			"data":    callback,
+		})
+	}
+}
+
+func authUserID(c *gin.Context) (uuid.UUID, bool) {
+	userIdVal, exists := c.Get("user_id")
+	if !exists {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return uuid.Nil, false
+	}
+	userID, ok := userIdVal.(uuid.UUID)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "invalid user id type"})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+func fundingErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrInvalidCallback):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrInvalidSignature):
+		return http.StatusUnauthorized
+	case errors.Is(err, transfer.ErrUnauthorizedWallet):
+		return http.StatusForbidden
+	case errors.Is(err, transfer.ErrWalletNotFound), errors.Is(err, ErrVirtualAccountNotFound),
+		errors.Is(err, ErrDepositNotFound), errors.Is(err, ErrUnknownProvider):
+		return http.StatusNotFound
+	case errors.Is(err, ErrCallbackMismatch), errors.Is(err, ErrInvalidTransition):
+		return http.StatusConflict
+	case errors.Is(err, ErrWalletNotEligible), errors.Is(err, ErrCurrencyMismatch):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
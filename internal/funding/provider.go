@@ -0,0 +1,69 @@
+package funding
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/luponetn/paycore/pkg/money"
+)
+
+// Provider is a funding partner: it issues account numbers that pay into wallets and calls
+// back as payments into them progress.
+type Provider interface {
+	Name() string
+	// CreateVirtualAccount asks the provider for an account number paying into a wallet.
+	CreateVirtualAccount(ctx context.Context, req VirtualAccountRequest) (VirtualAccountDetails, error)
+	// ParseCallback authenticates a payment callback and decodes it; it returns
+	// ErrInvalidSignature for one the provider did not send.
+	ParseCallback(header http.Header, body []byte) (Callback, error)
+}
+
+type VirtualAccountRequest struct {
+	WalletID    uuid.UUID
+	AccountName string
+	Currency    string
+}
+
+type VirtualAccountDetails struct {
+	AccountNumber string
+	AccountName   string
+	BankName      string
+}
+
+// CallbackStatus is where a payment stands at the provider. A payment is pending until the
+// provider has the funds, then settles or fails; both are final.
+type CallbackStatus string
+
+const (
+	CallbackPending CallbackStatus = "pending"
+	CallbackSettled CallbackStatus = "settled"
+	CallbackFailed  CallbackStatus = "failed"
+)
+
+// Callback is a provider's report on one payment into a virtual account. Providers may send
+// the same report more than once; Reference identifies the payment across reports.
+type Callback struct {
+	Reference           string         `json:"reference"`
+	AccountNumber       string         `json:"account_number"`
+	Amount              money.Money    `json:"amount"`
+	Status              CallbackStatus `json:"status"`
+	SenderName          string         `json:"sender_name,omitempty"`
+	SenderAccountNumber string         `json:"sender_account_number,omitempty"`
+	SenderBank          string         `json:"sender_bank,omitempty"`
+	Reason              string         `json:"reason,omitempty"`
+	OccurredAt          time.Time      `json:"occurred_at"`
+}
+
+func (c Callback) validate() error {
+	if c.Reference == "" || c.AccountNumber == "" || !c.Amount.IsPositive() {
+		return ErrInvalidCallback
+	}
+	switch c.Status {
+	case CallbackPending, CallbackSettled, CallbackFailed:
+		return nil
+	default:
+		return ErrInvalidCallback
+	}
+}
@@ -0,0 +1,36 @@
+package funding
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/luponetn/paycore/internal/middleware"
+	"github.com/luponetn/paycore/pkg/utils"
+)
+
+func RegisterRoutes(r *gin.Engine, h *Handler, keys *utils.KeySet, sessions middleware.SessionChecker, adminKey string) {
+	fundingGroup := r.Group("/funding")
+
+	//use middlewares
+	fundingGroup.Use(middleware.AuthMiddleware(keys, sessions))
+
+	//implement routes
+	{
+		fundingGroup.POST("/virtual-accounts", h.HandleCreateVirtualAccount)
+		fundingGroup.GET("/virtual-accounts", h.HandleListVirtualAccounts)
+		fundingGroup.GET("/deposits", h.HandleListDeposits)
+		fundingGroup.GET("/deposits/:id", h.HandleGetDeposit)
+	}
+
+	// providers authenticate callbacks by signature, not with a user token
+	r.POST("/funding/callbacks/:provider", h.HandleCallback)
+
+	if h.sim != nil {
+		simulatorGroup := r.Group("/admin/funding/simulator")
+		simulatorGroup.Use(middleware.AdminKeyMiddleware(adminKey))
+		{
+			simulatorGroup.POST("/payments", h.HandleSimulatePayment)
+			simulatorGroup.POST("/payments/:reference/settle", h.HandleSimulateSettle)
+			simulatorGroup.POST("/payments/:reference/fail", h.HandleSimulateFail)
+			simulatorGroup.POST("/payments/:reference/resend", h.HandleSimulateResend)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package funding
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/luponetn/paycore/internal/accounts"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/ledger"
+	"github.com/luponetn/paycore/internal/store"
+	"github.com/luponetn/paycore/internal/transfer"
+	"github.com/luponetn/paycore/pkg/money"
+	"github.com/luponetn/paycore/pkg/utils"
+)
+
+type Service interface {
+	CreateVirtualAccount(ctx context.Context, userID uuid.UUID, req CreateVirtualAccountRequest) (db.VirtualAccount, error)
+	ListVirtualAccounts(ctx context.Context, userID uuid.UUID) ([]db.VirtualAccount, error)
+	ListDeposits(ctx context.Context, userID uuid.UUID, limit int32) ([]db.FundingDeposit, error)
+	GetDeposit(ctx context.Context, userID uuid.UUID, depositID uuid.UUID) (db.FundingDeposit, error)
+	HandleCallback(ctx context.Context, provider string, header http.Header, body []byte) (db.FundingDeposit, error)
+}
+
+type Svc struct {
+	store    store.Store
+	provider Provider
+}
+
+// NewService wires inbound funding through provider, which issues virtual accounts and whose
+// callbacks credit them.
+func NewService(store store.Store, provider Provider) Service {
+	return &Svc{store: store, provider: provider}
+}
+
+// CreateVirtualAccount issues a virtual account for one of the user's wallets, or returns the
+// one it already has.
+func (s *Svc) CreateVirtualAccount(ctx context.Context, userID uuid.UUID, req CreateVirtualAccountRequest) (db.VirtualAccount, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	walletID, err := uuid.Parse(req.WalletID)
+	if err != nil {
+		return db.VirtualAccount{}, transfer.ErrWalletNotFound
+	}
+	wallet, err := s.store.Queries().GetWalletById(ctx, walletID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.VirtualAccount{}, transfer.ErrWalletNotFound
+		}
+		return db.VirtualAccount{}, err
+	}
+	if !wallet.UserID.Valid || uuid.UUID(wallet.UserID.Bytes) != userID {
+		return db.VirtualAccount{}, transfer.ErrUnauthorizedWallet
+	}
+	if wallet.WalletType == db.WalletTypeEnumFixed || wallet.WalletType == db.WalletTypeEnumSystem {
+		return db.VirtualAccount{}, ErrWalletNotEligible
+	}
+
+	lookup := db.GetVirtualAccountByWalletParams{WalletID: wallet.ID, Provider: s.provider.Name()}
+	existing, err := s.store.Queries().GetVirtualAccountByWallet(ctx, lookup)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return db.VirtualAccount{}, err
+	}
+
+	user, err := s.store.Queries().GetUserByID(ctx, userID)
+	if err != nil {
+		return db.VirtualAccount{}, err
+	}
+
+	// a unique violation is either a concurrent request for the same wallet, which won, or an
+	// account number the provider handed out twice, which is worth asking again for
+	return utils.Retry(3, 100, func() (db.VirtualAccount, error) {
+		details, err := s.provider.CreateVirtualAccount(ctx, VirtualAccountRequest{
+			WalletID:    wallet.ID,
+			AccountName: user.FullName,
+			Currency:    wallet.Currency,
+		})
+		if err != nil {
+			return db.VirtualAccount{}, err
+		}
+
+		account, err := s.store.Queries().CreateVirtualAccount(ctx, db.CreateVirtualAccountParams{
+			UserID:        userID,
+			WalletID:      wallet.ID,
+			Provider:      s.provider.Name(),
+			AccountNumber: details.AccountNumber,
+			AccountName:   details.AccountName,
+			BankName:      details.BankName,
+			Currency:      wallet.Currency,
+		})
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			if existing, err := s.store.Queries().GetVirtualAccountByWallet(ctx, lookup); err == nil {
+				return existing, nil
+			}
+			return db.VirtualAccount{}, &utils.RetryableError{Err: err}
+		}
+		return account, err
+	})
+}
+
+func (s *Svc) ListVirtualAccounts(ctx context.Context, userID uuid.UUID) ([]db.VirtualAccount, error) {
+	ctx, cancel := context.WithTimeout(ctx, 8*time.Second)
+	defer cancel()
+
+	accounts, err := s.store.Queries().GetVirtualAccountsByUserId(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if accounts == nil {
+		accounts = []db.VirtualAccount{}
+	}
+	return accounts, nil
+}
+
+// ListDeposits lists the most recent payments into the user's virtual accounts.
+func (s *Svc) ListDeposits(ctx context.Context, userID uuid.UUID, limit int32) ([]db.FundingDeposit, error) {
+	ctx, cancel := context.WithTimeout(ctx, 8*time.Second)
+	defer cancel()
+
+	deposits, err := s.store.Queries().GetFundingDepositsByUserId(ctx, db.GetFundingDepositsByUserIdParams{
+		UserID:    userID,
+		BatchSize: limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if deposits == nil {
+		deposits = []db.FundingDeposit{}
+	}
+	return deposits, nil
+}
+
+func (s *Svc) GetDeposit(ctx context.Context, userID uuid.UUID, depositID uuid.UUID) (db.FundingDeposit, error) {
+	ctx, cancel := context.WithTimeout(ctx, 8*time.Second)
+	defer cancel()
+
+	deposit, err := s.store.Queries().GetFundingDepositById(ctx, depositID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.FundingDeposit{}, ErrDepositNotFound
+		}
+		return db.FundingDeposit{}, err
+	}
+
+	wallet, err := s.store.Queries().GetWalletById(ctx, deposit.WalletID)
+	if err != nil {
+		return db.FundingDeposit{}, err
+	}
+	// someone else's deposit reads as missing rather than forbidden
+	if !wallet.UserID.Valid || uuid.UUID(wallet.UserID.Bytes) != userID {
+		return db.FundingDeposit{}, ErrDepositNotFound
+	}
+	return deposit, nil
+}
+
+// HandleCallback applies a provider's report on a payment into a virtual account. The first
+// report about a reference records the deposit; a settled report credits the wallet from the
+// settlement account exactly once, however many times it arrives. Reports that repeat the
+// current status, or arrive late with an earlier one, change nothing.
+func (s *Svc) HandleCallback(ctx context.Context, provider string, header http.Header, body []byte) (db.FundingDeposit, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	if provider != s.provider.Name() {
+		return db.FundingDeposit{}, ErrUnknownProvider
+	}
+	callback, err := s.provider.ParseCallback(header, body)
+	if err != nil {
+		return db.FundingDeposit{}, err
+	}
+
+	account, err := s.store.Queries().GetVirtualAccountByNumber(ctx, db.GetVirtualAccountByNumberParams{
+		Provider:      provider,
+		AccountNumber: callback.AccountNumber,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.FundingDeposit{}, ErrVirtualAccountNotFound
+		}
+		return db.FundingDeposit{}, err
+	}
+	if callback.Amount.Code() != account.Currency {
+		return db.FundingDeposit{}, ErrCurrencyMismatch
+	}
+
+	return utils.Retry(3, 100, func() (db.FundingDeposit, error) {
+		tx, err := s.store.Begin(ctx)
+		if err != nil {
+			return db.FundingDeposit{}, &utils.RetryableError{Err: err}
+		}
+
+		defer func() {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback funding callback tx", "error", rbErr)
+			}
+		}()
+
+		qtx := s.store.WithTx(tx)
+
+		deposit, err := qtx.GetFundingDepositForUpdate(ctx, db.GetFundingDepositForUpdateParams{
+			Provider:          provider,
+			ProviderReference: callback.Reference,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			deposit, err = qtx.CreateFundingDeposit(ctx, db.CreateFundingDepositParams{
+				Provider:            provider,
+				ProviderReference:   callback.Reference,
+				VirtualAccountID:    account.ID,
+				WalletID:            account.WalletID,
+				Amount:              callback.Amount.Numeric(),
+				Currency:            callback.Amount.Code(),
+				SenderName:          optionalText(callback.SenderName),
+				SenderAccountNumber: optionalText(callback.SenderAccountNumber),
+				SenderBank:          optionalText(callback.SenderBank),
+			})
+			// ON CONFLICT DO NOTHING returns no row when a concurrent callback recorded it
+			// first; the retry locks that row instead
+		}
+		if err != nil {
+			return db.FundingDeposit{}, &utils.RetryableError{Err: err}
+		}
+
+		if err := sameDeposit(deposit, account, callback); err != nil {
+			return db.FundingDeposit{}, err
+		}
+
+		switch {
+		case callback.Status == CallbackPending, deposit.Status == db.FundingDepositStatusEnum(callback.Status):
+			// nothing new
+		case deposit.Status != db.FundingDepositStatusEnumPending:
+			return db.FundingDeposit{}, ErrInvalidTransition
+		case callback.Status == CallbackSettled:
+			created, err := ledger.Post(ctx, qtx, ledger.Journal{
+				TransactionType:  db.TransactionTypeEnumDeposit,
+				Description:      depositDescription(callback),
+				IdempotencyKey:   "funding:" + provider + ":" + callback.Reference,
+				ReceiverWalletID: deposit.WalletID,
+				Postings: []ledger.Posting{
+					ledger.DebitAccount(accounts.Settlement, callback.Amount.Amount(), deposit.Currency),
+					ledger.Credit(deposit.WalletID, callback.Amount.Amount(), deposit.Currency),
+				},
+			})
+			if err != nil {
+				return db.FundingDeposit{}, err
+			}
+			deposit, err = qtx.SettleFundingDeposit(ctx, db.SettleFundingDepositParams{
+				ID:            deposit.ID,
+				TransactionID: utils.ToPgUUID(created.ID),
+			})
+			if err != nil {
+				return db.FundingDeposit{}, &utils.RetryableError{Err: err}
+			}
+		case callback.Status == CallbackFailed:
+			deposit, err = qtx.FailFundingDeposit(ctx, db.FailFundingDepositParams{
+				ID:            deposit.ID,
+				FailureReason: optionalText(callback.Reason),
+			})
+			if err != nil {
+				return db.FundingDeposit{}, &utils.RetryableError{Err: err}
+			}
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return db.FundingDeposit{}, &utils.RetryableError{Err: err}
+		}
+		return deposit, nil
+	})
+}
+
+// sameDeposit checks a callback describes the payment already recorded under its reference.
+func sameDeposit(deposit db.FundingDeposit, account db.VirtualAccount, callback Callback) error {
+	recorded, err := money.FromNumeric(deposit.Amount, deposit.Currency)
+	if err != nil {
+		return err
+	}
+	cmp, err := recorded.Cmp(callback.Amount)
+	if err != nil || cmp != 0 || deposit.VirtualAccountID != account.ID {
+		return ErrCallbackMismatch
+	}
+	return nil
+}
+
+func depositDescription(callback Callback) string {
+	if callback.SenderName == "" {
+		return "bank deposit"
+	}
+	return "bank deposit from " + callback.SenderName
+}
+
+func optionalText(s string) pgtype.Text {
+	return pgtype.Text{String: s, Valid: s != ""}
+}
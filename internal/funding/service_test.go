@@ -0,0 +1,174 @@
+package funding
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/luponetn/paycore/internal/accounts"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/store"
+	"github.com/luponetn/paycore/pkg/money"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "sim_secret"
+
+// setupFunding starts a callback receiver backed by a fresh service and points a simulator at it.
+func setupFunding(t *testing.T) (*store.FakeStore, Service, *Simulator) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	f := store.NewFakeStore()
+
+	router := gin.New()
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+
+	sim := NewSimulator(testSecret, srv.URL+"/funding/callbacks/"+SimulatorName, time.Minute)
+	svc := NewService(f, sim)
+	router.POST("/funding/callbacks/:provider", NewHandler(svc, sim).HandleCallback)
+	return f, svc, sim
+}
+
+// addWallet gives a new named user an empty savings wallet in currency.
+func addWallet(f *store.FakeStore, currency string) (uuid.UUID, uuid.UUID) {
+	user := db.User{ID: uuid.New(), FullName: "Ada Obi"}
+	f.AddFakeUser(user)
+	return user.ID, f.AddFakeFundedWallet(user.ID, db.WalletTypeEnumSavings, currency, "0")
+}
+
+// signed builds the headers the simulator would send with body.
+func signed(sim *Simulator, body []byte) http.Header {
+	timestamp := time.Now().Unix()
+	header := http.Header{}
+	header.Set(HeaderSimulatorTimestamp, strconv.FormatInt(timestamp, 10))
+	header.Set(HeaderSimulatorSignature, sim.sign(timestamp, body))
+	return header
+}
+
+func TestHandleCallback_CreditsWalletOnceWhenSettled(t *testing.T) {
+	f, svc, sim := setupFunding(t)
+	ctx := context.Background()
+	userID, walletID := addWallet(f, "NGN")
+
+	account, err := svc.CreateVirtualAccount(ctx, userID, CreateVirtualAccountRequest{WalletID: walletID.String()})
+	require.NoError(t, err)
+	require.Len(t, account.AccountNumber, 10)
+	require.Equal(t, "Ada Obi", account.AccountName)
+
+	again, err := svc.CreateVirtualAccount(ctx, userID, CreateVirtualAccountRequest{WalletID: walletID.String()})
+	require.NoError(t, err)
+	require.Equal(t, account.ID, again.ID)
+
+	payment, err := sim.Pay(ctx, SimulatePaymentRequest{
+		AccountNumber: account.AccountNumber,
+		Amount:        "2500.00",
+		Currency:      "NGN",
+		SenderName:    "Chidi Eze",
+	})
+	require.NoError(t, err)
+
+	// A pending payment is recorded but not credited, however often it is reported.
+	_, err = sim.Resend(ctx, payment.Reference)
+	require.NoError(t, err)
+	deposits, err := svc.ListDeposits(ctx, userID, 10)
+	require.NoError(t, err)
+	require.Len(t, deposits, 1)
+	require.Equal(t, db.FundingDepositStatusEnumPending, deposits[0].Status)
+	require.Equal(t, "0.00", f.FakeBalance(walletID))
+
+	_, err = sim.Settle(ctx, payment.Reference)
+	require.NoError(t, err)
+	_, err = sim.Resend(ctx, payment.Reference)
+	require.NoError(t, err)
+
+	deposit, err := svc.GetDeposit(ctx, userID, deposits[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, db.FundingDepositStatusEnumSettled, deposit.Status)
+	require.True(t, deposit.TransactionID.Valid)
+	require.Equal(t, "2500.00", f.FakeBalance(walletID))
+
+	settlement, err := accounts.SystemWallet(ctx, f.Queries(), accounts.Settlement, "NGN")
+	require.NoError(t, err)
+	require.Equal(t, "-2500.00", f.FakeBalance(settlement))
+
+	_, err = sim.Fail(ctx, payment.Reference, "too late")
+	require.ErrorIs(t, err, ErrPaymentNotPending)
+
+	_, err = svc.GetDeposit(ctx, uuid.New(), deposit.ID)
+	require.ErrorIs(t, err, ErrDepositNotFound)
+}
+
+func TestHandleCallback_RejectsUntrustedOrInconsistentCallbacks(t *testing.T) {
+	f, svc, sim := setupFunding(t)
+	ctx := context.Background()
+	userID, walletID := addWallet(f, "NGN")
+
+	account, err := svc.CreateVirtualAccount(ctx, userID, CreateVirtualAccountRequest{WalletID: walletID.String()})
+	require.NoError(t, err)
+
+	report := func(callback Callback) error {
+		body, err := json.Marshal(callback)
+		require.NoError(t, err)
+		_, err = svc.HandleCallback(ctx, SimulatorName, signed(sim, body), body)
+		return err
+	}
+	naira := func(amount string) money.Money {
+		m, err := money.Parse(amount, "NGN")
+		require.NoError(t, err)
+		return m
+	}
+
+	callback := Callback{Reference: "REF-1", AccountNumber: account.AccountNumber, Amount: naira("100"), Status: CallbackPending}
+	body, err := json.Marshal(callback)
+	require.NoError(t, err)
+
+	_, err = svc.HandleCallback(ctx, SimulatorName, http.Header{}, body)
+	require.ErrorIs(t, err, ErrInvalidSignature)
+
+	forged := NewSimulator("other_secret", "", time.Minute)
+	_, err = svc.HandleCallback(ctx, SimulatorName, signed(forged, body), body)
+	require.ErrorIs(t, err, ErrInvalidSignature)
+
+	_, err = svc.HandleCallback(ctx, "acme", signed(sim, body), body)
+	require.ErrorIs(t, err, ErrUnknownProvider)
+
+	unknown := callback
+	unknown.AccountNumber = "0000000000"
+	require.ErrorIs(t, report(unknown), ErrVirtualAccountNotFound)
+
+	dollars := callback
+	dollars.Amount, err = money.Parse("100", "USD")
+	require.NoError(t, err)
+	require.ErrorIs(t, report(dollars), ErrCurrencyMismatch)
+
+	require.NoError(t, report(callback))
+
+	changed := callback
+	changed.Amount = naira("1000")
+	changed.Status = CallbackSettled
+	require.ErrorIs(t, report(changed), ErrCallbackMismatch)
+
+	failed := callback
+	failed.Status = CallbackFailed
+	failed.Reason = "sender bank reversed the payment"
+	require.NoError(t, report(failed))
+
+	settled := callback
+	settled.Status = CallbackSettled
+	require.ErrorIs(t, report(settled), ErrInvalidTransition)
+
+	// A late pending report leaves the failed deposit alone.
+	require.NoError(t, report(callback))
+	deposits, err := svc.ListDeposits(ctx, userID, 10)
+	require.NoError(t, err)
+	require.Len(t, deposits, 1)
+	require.Equal(t, db.FundingDepositStatusEnumFailed, deposits[0].Status)
+	require.Equal(t, "0.00", f.FakeBalance(walletID))
+}
@@ -0,0 +1,212 @@
+package funding
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/luponetn/paycore/pkg/money"
+)
+
+// SimulatorName is the provider name of the local simulator, used in callback URLs.
+const SimulatorName = "simulator"
+
+// Headers the simulator signs its callbacks with.
+const (
+	HeaderSimulatorTimestamp = "X-Simulator-Timestamp"
+	HeaderSimulatorSignature = "X-Simulator-Signature"
+)
+
+// simulatorBank is the bank name shown on simulated virtual accounts.
+const simulatorBank = "Paycore Sandbox Bank"
+
+// Simulator stands in for a funding provider in development and tests. It hands out random
+// account numbers and, when told a payment arrived, posts signed callbacks the way a real
+// provider would. Payments live in memory and do not survive a restart.
+type Simulator struct {
+	secret      string
+	callbackURL string
+	tolerance   time.Duration
+	client      *http.Client
+
+	mu       sync.Mutex
+	payments map[string]Callback
+}
+
+func NewSimulator(secret string, callbackURL string, tolerance time.Duration) *Simulator {
+	return &Simulator{
+		secret:      secret,
+		callbackURL: callbackURL,
+		tolerance:   tolerance,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		payments:    make(map[string]Callback),
+	}
+}
+
+func (s *Simulator) Name() string {
+	return SimulatorName
+}
+
+// CreateVirtualAccount issues a random ten-digit account number starting with 99.
+func (s *Simulator) CreateVirtualAccount(ctx context.Context, req VirtualAccountRequest) (VirtualAccountDetails, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(100_000_000))
+	if err != nil {
+		return VirtualAccountDetails{}, err
+	}
+	return VirtualAccountDetails{
+		AccountNumber: fmt.Sprintf("99%08d", n.Int64()),
+		AccountName:   req.AccountName,
+		BankName:      simulatorBank,
+	}, nil
+}
+
+// ParseCallback checks the timestamp is within tolerance and the signature is the hex
+// HMAC-SHA256 of "<timestamp>.<body>" under the shared secret.
+func (s *Simulator) ParseCallback(header http.Header, body []byte) (Callback, error) {
+	timestamp, err := strconv.ParseInt(header.Get(HeaderSimulatorTimestamp), 10, 64)
+	if err != nil {
+		return Callback{}, ErrInvalidSignature
+	}
+	if age := time.Since(time.Unix(timestamp, 0)); age > s.tolerance || age < -s.tolerance {
+		return Callback{}, ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(header.Get(HeaderSimulatorSignature)), []byte(s.sign(timestamp, body))) {
+		return Callback{}, ErrInvalidSignature
+	}
+
+	var callback Callback
+	if err := json.Unmarshal(body, &callback); err != nil {
+		return Callback{}, fmt.Errorf("%w: %v", ErrInvalidCallback, err)
+	}
+	if err := callback.validate(); err != nil {
+		return Callback{}, err
+	}
+	return callback, nil
+}
+
+func (s *Simulator) sign(timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(s.secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Pay simulates a bank transfer into a virtual account: the payment is recorded as pending
+// and its pending callback sent.
+func (s *Simulator) Pay(ctx context.Context, req SimulatePaymentRequest) (Callback, error) {
+	amount, err := money.Parse(req.Amount, req.Currency)
+	if err != nil {
+		return Callback{}, err
+	}
+	if !amount.IsPositive() {
+		return Callback{}, money.ErrInvalidAmount
+	}
+
+	callback := Callback{
+		Reference:           "SIM-" + uuid.NewString(),
+		AccountNumber:       req.AccountNumber,
+		Amount:              amount,
+		Status:              CallbackPending,
+		SenderName:          req.SenderName,
+		SenderAccountNumber: req.SenderAccountNumber,
+		SenderBank:          req.SenderBank,
+	}
+	return s.advance(ctx, callback)
+}
+
+// Settle moves a pending payment to settled and sends its callback.
+func (s *Simulator) Settle(ctx context.Context, reference string) (Callback, error) {
+	callback, err := s.pending(reference)
+	if err != nil {
+		return Callback{}, err
+	}
+	callback.Status = CallbackSettled
+	return s.advance(ctx, callback)
+}
+
+// Fail moves a pending payment to failed and sends its callback.
+func (s *Simulator) Fail(ctx context.Context, reference string, reason string) (Callback, error) {
+	callback, err := s.pending(reference)
+	if err != nil {
+		return Callback{}, err
+	}
+	callback.Status = CallbackFailed
+	callback.Reason = reason
+	return s.advance(ctx, callback)
+}
+
+// Resend sends a payment's latest callback again, as providers do when unsure it arrived.
+func (s *Simulator) Resend(ctx context.Context, reference string) (Callback, error) {
+	s.mu.Lock()
+	callback, ok := s.payments[reference]
+	s.mu.Unlock()
+	if !ok {
+		return Callback{}, ErrPaymentNotFound
+	}
+	return callback, s.send(ctx, callback)
+}
+
+func (s *Simulator) pending(reference string) (Callback, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	callback, ok := s.payments[reference]
+	if !ok {
+		return Callback{}, ErrPaymentNotFound
+	}
+	if callback.Status != CallbackPending {
+		return Callback{}, ErrPaymentNotPending
+	}
+	return callback, nil
+}
+
+// advance records the payment's new status and sends it. The status sticks even if sending
+// fails, as at a real provider; Resend retries it.
+func (s *Simulator) advance(ctx context.Context, callback Callback) (Callback, error) {
+	callback.OccurredAt = time.Now().UTC()
+
+	s.mu.Lock()
+	s.payments[callback.Reference] = callback
+	s.mu.Unlock()
+
+	return callback, s.send(ctx, callback)
+}
+
+func (s *Simulator) send(ctx context.Context, callback Callback) error {
+	body, err := json.Marshal(callback)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.callbackURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderSimulatorTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSimulatorSignature, s.sign(timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("callback rejected with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package funding
+
+type CreateVirtualAccountRequest struct {
+	WalletID string `json:"wallet_id" binding:"required,uuid"`
+}
+
+type ListDepositsQuery struct {
+	Limit int32 `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// SimulatePaymentRequest describes a bank transfer into a virtual account for the simulator.
+type SimulatePaymentRequest struct {
+	AccountNumber       string `json:"account_number" binding:"required"`
+	Amount              string `json:"amount" binding:"required"`
+	Currency            string `json:"currency" binding:"required,len=3"`
+	SenderName          string `json:"sender_name"`
+	SenderAccountNumber string `json:"sender_account_number"`
+	SenderBank          string `json:"sender_bank"`
+}
+
+type FailPaymentRequest struct {
+	Reason string `json:"reason"`
+}
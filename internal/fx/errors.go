@@ -0,0 +1,13 @@
+package fx
+
+import "errors"
+
+var (
+	ErrInvalidRate       = errors.New("rates need two different three-letter currencies and a positive rate")
+	ErrRateUnavailable   = errors.New("no current exchange rate for this currency pair")
+	ErrSameCurrency      = errors.New("both wallets hold the same currency, use a move instead")
+	ErrWalletNotEligible = errors.New("fixed wallets cannot be converted from or into")
+	ErrQuoteNotFound     = errors.New("quote not found")
+	ErrQuoteExpired      = errors.New("quote has expired, request a new one")
+	ErrQuoteUsed         = errors.New("quote has already been converted")
+)
@@ -0,0 +1,142 @@
+package fx
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/luponetn/paycore/internal/transfer"
+)
+
+type Handler struct {
+	svc Service
+}
+
+func NewHandler(svc Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+func (h *Handler) HandleRates(c *gin.Context) {
+	rates, err := h.svc.Rates(c.Request.Context())
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"message": "failed to fetch exchange rates",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "exchange rates fetched successfully",
+		"data":    rates,
+	})
+}
+
+func (h *Handler) HandleSetRates(c *gin.Context) {
+	var req SetRatesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	rates, err := h.svc.SetRates(c.Request.Context(), req.Rates, SourceAdmin)
+	if err != nil {
+		c.AbortWithStatusJSON(fxErrorStatus(err), gin.H{
+			"message": "failed to set exchange rates",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "exchange rates set successfully",
+		"data":    rates,
+	})
+}
+
+func (h *Handler) HandleCreateQuote(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	var req QuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	quote, err := h.svc.CreateQuote(c.Request.Context(), userID, req)
+	if err != nil {
+		c.AbortWithStatusJSON(fxErrorStatus(err), gin.H{
+			"message": "failed to create quote",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "quote created successfully",
+		"data":    quote,
+	})
+}
+
+func (h *Handler) HandleConvert(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	var req ConvertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	conversion, err := h.svc.Convert(c.Request.Context(), userID, req)
+	if err != nil {
+		c.AbortWithStatusJSON(fxErrorStatus(err), gin.H{
+			"message": "failed to convert",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "conversion completed successfully",
+		"data":    conversion,
+	})
+}
+
+func authUserID(c *gin.Context) (uuid.UUID, bool) {
+	userIdVal, exists := c.Get("user_id")
+	if !exists {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return uuid.Nil, false
+	}
+	userID, ok := userIdVal.(uuid.UUID)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "invalid user id type"})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+func fxErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrInvalidRate), errors.Is(err, ErrSameCurrency), errors.Is(err, ErrWalletNotEligible),
+		transfer.IsInvalidAmount(err), errors.Is(err, transfer.ErrInsufficientFunds):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrQuoteNotFound), errors.Is(err, transfer.ErrWalletNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, transfer.ErrUnauthorizedWallet):
+		return http.StatusForbidden
+	case errors.Is(err, ErrQuoteExpired), errors.Is(err, ErrQuoteUsed), errors.Is(err, transfer.ErrIdempotencyKeyReused):
+		return http.StatusConflict
+	case errors.Is(err, ErrRateUnavailable):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
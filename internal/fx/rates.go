@@ -0,0 +1,57 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/pkg/utils"
+	"github.com/shopspring/decimal"
+)
+
+// ratePlaces is the precision rates are stored and inverted at.
+const ratePlaces = 10
+
+// parseRate validates a rate request and normalises its currency codes.
+func parseRate(req RateRequest) (db.CreateFxRateParams, error) {
+	base := strings.ToUpper(req.BaseCurrency)
+	quote := strings.ToUpper(req.QuoteCurrency)
+	rate, err := decimal.NewFromString(req.Rate)
+	if err != nil || !rate.IsPositive() || len(base) != 3 || len(quote) != 3 || base == quote {
+		return db.CreateFxRateParams{}, ErrInvalidRate
+	}
+	return db.CreateFxRateParams{
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+		Rate:          utils.DecimalToNumeric(rate.Round(ratePlaces)),
+	}, nil
+}
+
+// midRate finds how many units of to one unit of from buys, inverting the rate stored for the
+// opposite direction when there is none for this one. Rates older than maxAge are not used.
+func midRate(ctx context.Context, q db.Querier, from string, to string, maxAge time.Duration) (decimal.Decimal, error) {
+	cutoff := time.Now().Add(-maxAge)
+
+	direct, err := q.GetLatestFxRate(ctx, db.GetLatestFxRateParams{BaseCurrency: from, QuoteCurrency: to})
+	if err == nil && direct.CreatedAt.Time.After(cutoff) {
+		return utils.NumericToDecimal(direct.Rate), nil
+	}
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return decimal.Zero, err
+	}
+
+	inverse, err := q.GetLatestFxRate(ctx, db.GetLatestFxRateParams{BaseCurrency: to, QuoteCurrency: from})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return decimal.Zero, ErrRateUnavailable
+		}
+		return decimal.Zero, err
+	}
+	if !inverse.CreatedAt.Time.After(cutoff) {
+		return decimal.Zero, ErrRateUnavailable
+	}
+	return decimal.NewFromInt(1).DivRound(utils.NumericToDecimal(inverse.Rate), ratePlaces), nil
+}
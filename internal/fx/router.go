@@ -0,0 +1,27 @@
+package fx
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/luponetn/paycore/internal/middleware"
+	"github.com/luponetn/paycore/pkg/utils"
+)
+
+func RegisterRoutes(r *gin.Engine, h *Handler, keys *utils.KeySet, sessions middleware.SessionChecker, adminKey string) {
+	fxGroup := r.Group("/fx")
+
+	//use middlewares
+	fxGroup.Use(middleware.AuthMiddleware(keys, sessions))
+
+	//implement routes
+	{
+		fxGroup.GET("/rates", h.HandleRates)
+		fxGroup.POST("/quotes", h.HandleCreateQuote)
+		fxGroup.POST("/convert", h.HandleConvert)
+	}
+
+	adminGroup := r.Group("/admin/fx")
+	adminGroup.Use(middleware.AdminKeyMiddleware(adminKey))
+	{
+		adminGroup.POST("/rates", h.HandleSetRates)
+	}
+}
@@ -0,0 +1,311 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/luponetn/paycore/internal/accounts"
+	"github.com/luponetn/paycore/internal/config"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/ledger"
+	"github.com/luponetn/paycore/internal/store"
+	"github.com/luponetn/paycore/internal/transfer"
+	"github.com/luponetn/paycore/pkg/money"
+	"github.com/luponetn/paycore/pkg/utils"
+	"github.com/shopspring/decimal"
+)
+
+// Rate sources recorded on fx_rates rows.
+const (
+	SourceFile  = "file"
+	SourceAdmin = "admin"
+)
+
+type Service interface {
+	Rates(ctx context.Context) ([]db.FxRate, error)
+	SetRates(ctx context.Context, reqs []RateRequest, source string) ([]db.FxRate, error)
+	LoadRatesFile(ctx context.Context, path string) (int, error)
+	CreateQuote(ctx context.Context, userID uuid.UUID, req QuoteRequest) (db.FxQuote, error)
+	Convert(ctx context.Context, userID uuid.UUID, req ConvertRequest) (ConversionResponse, error)
+}
+
+type Svc struct {
+	store store.Store
+	cfg   *config.Config
+}
+
+// NewService wires the FX service; cfg carries the spread, quote lifetime and rate max age.
+func NewService(store store.Store, cfg *config.Config) Service {
+	return &Svc{store: store, cfg: cfg}
+}
+
+// Rates lists the newest rate stored for each currency pair.
+func (s *Svc) Rates(ctx context.Context) ([]db.FxRate, error) {
+	ctx, cancel := context.WithTimeout(ctx, 8*time.Second)
+	defer cancel()
+
+	rates, err := s.store.Queries().GetLatestFxRates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if rates == nil {
+		rates = []db.FxRate{}
+	}
+	return rates, nil
+}
+
+// SetRates records a batch of rates. Every rate is validated before any is written, and the
+// batch is written in one transaction.
+func (s *Svc) SetRates(ctx context.Context, reqs []RateRequest, source string) ([]db.FxRate, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	params := make([]db.CreateFxRateParams, 0, len(reqs))
+	for _, req := range reqs {
+		p, err := parseRate(req)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s/%s", err, req.BaseCurrency, req.QuoteCurrency)
+		}
+		p.Source = source
+		params = append(params, p)
+	}
+
+	return utils.Retry(3, 100, func() ([]db.FxRate, error) {
+		tx, err := s.store.Begin(ctx)
+		if err != nil {
+			return nil, &utils.RetryableError{Err: err}
+		}
+
+		defer func() {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback set fx rates tx", "error", rbErr)
+			}
+		}()
+
+		qtx := s.store.WithTx(tx)
+
+		rates := make([]db.FxRate, 0, len(params))
+		for _, p := range params {
+			rate, err := qtx.CreateFxRate(ctx, p)
+			if err != nil {
+				return nil, &utils.RetryableError{Err: err}
+			}
+			rates = append(rates, rate)
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return nil, &utils.RetryableError{Err: err}
+		}
+		return rates, nil
+	})
+}
+
+// LoadRatesFile records the rates in a JSON file holding an array of rate requests.
+func (s *Svc) LoadRatesFile(ctx context.Context, path string) (int, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	var reqs []RateRequest
+	if err := json.Unmarshal(raw, &reqs); err != nil {
+		return 0, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	rates, err := s.SetRates(ctx, reqs, SourceFile)
+	if err != nil {
+		return 0, err
+	}
+	return len(rates), nil
+}
+
+// CreateQuote prices selling an amount from one of the user's wallets into another of theirs in a
+// different currency. The user gets the mid rate less the spread, and the bought amount is rounded
+// down to the target currency's minor unit.
+func (s *Svc) CreateQuote(ctx context.Context, userID uuid.UUID, req QuoteRequest) (db.FxQuote, error) {
+	ctx, cancel := context.WithTimeout(ctx, 8*time.Second)
+	defer cancel()
+
+	from, err := s.ownWallet(ctx, userID, req.FromWalletID)
+	if err != nil {
+		return db.FxQuote{}, err
+	}
+	to, err := s.ownWallet(ctx, userID, req.ToWalletID)
+	if err != nil {
+		return db.FxQuote{}, err
+	}
+	if from.Currency == to.Currency {
+		return db.FxQuote{}, ErrSameCurrency
+	}
+
+	sell, err := transfer.ParseAmount(req.Amount, from.Currency)
+	if err != nil {
+		return db.FxQuote{}, err
+	}
+
+	mid, err := midRate(ctx, s.store.Queries(), from.Currency, to.Currency, s.cfg.FXRateMaxAge)
+	if err != nil {
+		return db.FxQuote{}, err
+	}
+	rate := mid.Mul(decimal.NewFromInt(1).Sub(s.cfg.FXSpread)).Round(ratePlaces)
+	buy, err := money.Truncated(sell.Amount().Mul(rate), to.Currency)
+	if err != nil {
+		return db.FxQuote{}, err
+	}
+	if !buy.IsPositive() {
+		return db.FxQuote{}, transfer.ErrInvalidAmount
+	}
+
+	return s.store.Queries().CreateFxQuote(ctx, db.CreateFxQuoteParams{
+		UserID:       userID,
+		FromWalletID: from.ID,
+		ToWalletID:   to.ID,
+		FromCurrency: from.Currency,
+		ToCurrency:   to.Currency,
+		SellAmount:   sell.Numeric(),
+		BuyAmount:    buy.Numeric(),
+		MidRate:      utils.DecimalToNumeric(mid),
+		Rate:         utils.DecimalToNumeric(rate),
+		Spread:       utils.DecimalToNumeric(s.cfg.FXSpread),
+		ExpiresAt:    pgtype.Timestamptz{Time: time.Now().Add(s.cfg.FXQuoteTTL), Valid: true},
+	})
+}
+
+// Convert carries out a quote: the sold amount goes from the source wallet into the FX position
+// account in that currency, and the bought amount comes out of the FX position account in the
+// other currency into the target wallet, all under one fx_conversion transaction. Replaying the
+// idempotency key of a completed conversion returns it; the key is scoped to the user and quote.
+func (s *Svc) Convert(ctx context.Context, userID uuid.UUID, req ConvertRequest) (ConversionResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	quoteID, err := uuid.Parse(req.QuoteID)
+	if err != nil {
+		return ConversionResponse{}, ErrQuoteNotFound
+	}
+
+	return utils.Retry(3, 100, func() (ConversionResponse, error) {
+		tx, err := s.store.Begin(ctx)
+		if err != nil {
+			return ConversionResponse{}, &utils.RetryableError{Err: err}
+		}
+
+		defer func() {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback fx conversion tx", "error", rbErr)
+			}
+		}()
+
+		qtx := s.store.WithTx(tx)
+
+		// locking the quote serialises conversions of it
+		quote, err := qtx.GetFxQuoteForUpdate(ctx, quoteID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ConversionResponse{}, ErrQuoteNotFound
+			}
+			return ConversionResponse{}, &utils.RetryableError{Err: err}
+		}
+		if quote.UserID != userID {
+			return ConversionResponse{}, ErrQuoteNotFound
+		}
+
+		key := conversionKey(userID, quote.ID, req.IdempotencyKey)
+		if quote.TransactionID.Valid {
+			existing, err := qtx.GetTransactionByIdempotencyKey(ctx, key)
+			if err == nil && existing.ID == uuid.UUID(quote.TransactionID.Bytes) {
+				return ConversionResponse{Transaction: existing, Quote: quote}, nil
+			}
+			if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+				return ConversionResponse{}, &utils.RetryableError{Err: err}
+			}
+			return ConversionResponse{}, ErrQuoteUsed
+		}
+
+		if !quote.ExpiresAt.Time.After(time.Now()) {
+			return ConversionResponse{}, ErrQuoteExpired
+		}
+
+		sell, err := money.FromNumeric(quote.SellAmount, quote.FromCurrency)
+		if err != nil {
+			return ConversionResponse{}, err
+		}
+		buy, err := money.FromNumeric(quote.BuyAmount, quote.ToCurrency)
+		if err != nil {
+			return ConversionResponse{}, err
+		}
+
+		created, err := ledger.Post(ctx, qtx, ledger.Journal{
+			TransactionType:  db.TransactionTypeEnumFxConversion,
+			Description:      fmt.Sprintf("convert %s to %s", sell, buy),
+			IdempotencyKey:   key,
+			SenderWalletID:   quote.FromWalletID,
+			ReceiverWalletID: quote.ToWalletID,
+			Amount:           sell.Amount(),
+			Currency:         sell.Code(),
+			Postings: []ledger.Posting{
+				ledger.Debit(quote.FromWalletID, sell.Amount(), sell.Code()),
+				ledger.CreditAccount(accounts.FXPosition, sell.Amount(), sell.Code()),
+				ledger.DebitAccount(accounts.FXPosition, buy.Amount(), buy.Code()),
+				ledger.Credit(quote.ToWalletID, buy.Amount(), buy.Code()),
+			},
+		})
+		if err != nil {
+			return ConversionResponse{}, err
+		}
+		// only this quote's conversion may be recorded against it
+		if created.TransactionType != db.TransactionTypeEnumFxConversion || created.SenderWalletID != utils.ToPgUUID(quote.FromWalletID) ||
+			created.ReceiverWalletID != utils.ToPgUUID(quote.ToWalletID) {
+			return ConversionResponse{}, transfer.ErrIdempotencyKeyReused
+		}
+
+		quote.TransactionID = utils.ToPgUUID(created.ID)
+		if err := qtx.MarkFxQuoteConverted(ctx, db.MarkFxQuoteConvertedParams{
+			TransactionID: quote.TransactionID,
+			ID:            quote.ID,
+		}); err != nil {
+			return ConversionResponse{}, &utils.RetryableError{Err: err}
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return ConversionResponse{}, &utils.RetryableError{Err: err}
+		}
+		return ConversionResponse{Transaction: created, Quote: quote}, nil
+	})
+}
+
+// conversionKey namespaces a client's idempotency key to the user and the quote it converts.
+func conversionKey(userID, quoteID uuid.UUID, key string) string {
+	return "fx:" + userID.String() + ":" + quoteID.String() + ":" + key
+}
+
+// ownWallet loads one of the user's wallets that may take part in a conversion.
+func (s *Svc) ownWallet(ctx context.Context, userID uuid.UUID, rawID string) (db.Wallet, error) {
+	walletID, err := uuid.Parse(rawID)
+	if err != nil {
+		return db.Wallet{}, transfer.ErrWalletNotFound
+	}
+
+	wallet, err := s.store.Queries().GetWalletById(ctx, walletID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.Wallet{}, transfer.ErrWalletNotFound
+		}
+		return db.Wallet{}, err
+	}
+	if !wallet.UserID.Valid || uuid.UUID(wallet.UserID.Bytes) != userID {
+		return db.Wallet{}, transfer.ErrUnauthorizedWallet
+	}
+	if wallet.WalletType == db.WalletTypeEnumFixed {
+		return db.Wallet{}, ErrWalletNotEligible
+	}
+	return wallet, nil
+}
@@ -0,0 +1,168 @@
+package fx
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/luponetn/paycore/internal/accounts"
+	"github.com/luponetn/paycore/internal/config"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/ledger"
+	"github.com/luponetn/paycore/internal/store"
+	"github.com/luponetn/paycore/pkg/utils"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(f *store.FakeStore, quoteTTL time.Duration) Service {
+	return NewService(f, &config.Config{
+		FXSpread:     decimal.RequireFromString("0.01"),
+		FXQuoteTTL:   quoteTTL,
+		FXRateMaxAge: time.Hour,
+	})
+}
+
+func TestConvert_MovesFundsThroughFXPosition(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := newTestService(f, time.Minute)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	dollars := f.AddFakeFundedWallet(userID, db.WalletTypeEnumSavings, "USD", "100")
+	naira := f.AddFakeFundedWallet(userID, db.WalletTypeEnumMisc, "NGN", "0")
+
+	_, err := svc.SetRates(ctx, []RateRequest{{BaseCurrency: "usd", QuoteCurrency: "ngn", Rate: "1500"}}, SourceAdmin)
+	require.NoError(t, err)
+
+	quote, err := svc.CreateQuote(ctx, userID, QuoteRequest{
+		FromWalletID: dollars.String(),
+		ToWalletID:   naira.String(),
+		Amount:       "10",
+	})
+	require.NoError(t, err)
+	// 1500 less the 1% spread.
+	require.Equal(t, "1485.00", utils.NumericToDecimal(quote.Rate).StringFixed(2))
+	require.Equal(t, "14850.00", utils.NumericToDecimal(quote.BuyAmount).StringFixed(2))
+
+	req := ConvertRequest{QuoteID: quote.ID.String(), IdempotencyKey: uuid.New().String()}
+	conversion, err := svc.Convert(ctx, userID, req)
+	require.NoError(t, err)
+	require.Equal(t, db.TransactionTypeEnumFxConversion, conversion.Transaction.TransactionType)
+
+	// Replaying the key returns the same conversion; a new key cannot reuse the quote.
+	again, err := svc.Convert(ctx, userID, req)
+	require.NoError(t, err)
+	require.Equal(t, conversion.Transaction.ID, again.Transaction.ID)
+
+	_, err = svc.Convert(ctx, userID, ConvertRequest{QuoteID: quote.ID.String(), IdempotencyKey: uuid.New().String()})
+	require.ErrorIs(t, err, ErrQuoteUsed)
+
+	require.Equal(t, "90.00", f.FakeBalance(dollars))
+	require.Equal(t, "14850.00", f.FakeBalance(naira))
+
+	positionUSD, err := accounts.SystemWallet(ctx, f.Queries(), accounts.FXPosition, "USD")
+	require.NoError(t, err)
+	positionNGN, err := accounts.SystemWallet(ctx, f.Queries(), accounts.FXPosition, "NGN")
+	require.NoError(t, err)
+	require.Equal(t, "10.00", f.FakeBalance(positionUSD))
+	require.Equal(t, "-14850.00", f.FakeBalance(positionNGN))
+}
+
+func TestCreateQuote_InvertsRateAndRejectsBadPairs(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := newTestService(f, time.Minute)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	naira := f.AddFakeFundedWallet(userID, db.WalletTypeEnumSavings, "NGN", "20000")
+	dollars := f.AddFakeFundedWallet(userID, db.WalletTypeEnumMisc, "USD", "0")
+	pounds := f.AddFakeFundedWallet(userID, db.WalletTypeEnumMisc, "GBP", "0")
+	fixed := f.AddFakeFundedWallet(userID, db.WalletTypeEnumFixed, "USD", "0")
+	otherNaira := f.AddFakeFundedWallet(userID, db.WalletTypeEnumMisc, "NGN", "0")
+
+	_, err := svc.SetRates(ctx, []RateRequest{{BaseCurrency: "USD", QuoteCurrency: "NGN", Rate: "1600"}}, SourceAdmin)
+	require.NoError(t, err)
+
+	// Only USD/NGN is stored, so NGN/USD is its inverse.
+	quote, err := svc.CreateQuote(ctx, userID, QuoteRequest{FromWalletID: naira.String(), ToWalletID: dollars.String(), Amount: "16000"})
+	require.NoError(t, err)
+	require.Equal(t, "0.000625", utils.NumericToDecimal(quote.MidRate).String())
+	require.Equal(t, "9.90", utils.NumericToDecimal(quote.BuyAmount).StringFixed(2))
+
+	_, err = svc.CreateQuote(ctx, userID, QuoteRequest{FromWalletID: naira.String(), ToWalletID: pounds.String(), Amount: "100"})
+	require.ErrorIs(t, err, ErrRateUnavailable)
+
+	_, err = svc.CreateQuote(ctx, userID, QuoteRequest{FromWalletID: naira.String(), ToWalletID: otherNaira.String(), Amount: "100"})
+	require.ErrorIs(t, err, ErrSameCurrency)
+
+	_, err = svc.CreateQuote(ctx, userID, QuoteRequest{FromWalletID: naira.String(), ToWalletID: fixed.String(), Amount: "100"})
+	require.ErrorIs(t, err, ErrWalletNotEligible)
+
+	_, err = svc.SetRates(ctx, []RateRequest{{BaseCurrency: "USD", QuoteCurrency: "USD", Rate: "1"}}, SourceAdmin)
+	require.ErrorIs(t, err, ErrInvalidRate)
+}
+
+func TestConvert_RejectsExpiredQuote(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := newTestService(f, -time.Second)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	dollars := f.AddFakeFundedWallet(userID, db.WalletTypeEnumSavings, "USD", "100")
+	naira := f.AddFakeFundedWallet(userID, db.WalletTypeEnumMisc, "NGN", "0")
+
+	_, err := svc.SetRates(ctx, []RateRequest{{BaseCurrency: "USD", QuoteCurrency: "NGN", Rate: "1500"}}, SourceAdmin)
+	require.NoError(t, err)
+
+	quote, err := svc.CreateQuote(ctx, userID, QuoteRequest{FromWalletID: dollars.String(), ToWalletID: naira.String(), Amount: "10"})
+	require.NoError(t, err)
+
+	_, err = svc.Convert(ctx, uuid.New(), ConvertRequest{QuoteID: quote.ID.String(), IdempotencyKey: uuid.New().String()})
+	require.ErrorIs(t, err, ErrQuoteNotFound)
+
+	_, err = svc.Convert(ctx, userID, ConvertRequest{QuoteID: quote.ID.String(), IdempotencyKey: uuid.New().String()})
+	require.ErrorIs(t, err, ErrQuoteExpired)
+	require.Equal(t, "100.00", f.FakeBalance(dollars))
+}
+
+func TestConvert_ScopesKeyToUserAndQuote(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := newTestService(f, time.Minute)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	dollars := f.AddFakeFundedWallet(userID, db.WalletTypeEnumSavings, "USD", "100")
+	naira := f.AddFakeFundedWallet(userID, db.WalletTypeEnumMisc, "NGN", "0")
+
+	_, err := svc.SetRates(ctx, []RateRequest{{BaseCurrency: "USD", QuoteCurrency: "NGN", Rate: "1500"}}, SourceAdmin)
+	require.NoError(t, err)
+
+	// Someone else's transaction already holds the key the client sends.
+	key := uuid.New().String()
+	payer := f.AddFakeFundedWallet(uuid.New(), db.WalletTypeEnumSavings, "USD", "10")
+	_, err = ledger.Post(ctx, f.Queries(), ledger.Journal{
+		TransactionType: db.TransactionTypeEnumTransfer,
+		IdempotencyKey:  key,
+		Postings:        []ledger.Posting{ledger.Debit(payer, decimal.NewFromInt(10), "USD"), ledger.Credit(dollars, decimal.NewFromInt(10), "USD")},
+	})
+	require.NoError(t, err)
+
+	// Two quotes converted under that same key each get their own conversion.
+	var ids []uuid.UUID
+	for range 2 {
+		quote, err := svc.CreateQuote(ctx, userID, QuoteRequest{FromWalletID: dollars.String(), ToWalletID: naira.String(), Amount: "10"})
+		require.NoError(t, err)
+
+		conversion, err := svc.Convert(ctx, userID, ConvertRequest{QuoteID: quote.ID.String(), IdempotencyKey: key})
+		require.NoError(t, err)
+		require.Equal(t, db.TransactionTypeEnumFxConversion, conversion.Transaction.TransactionType)
+		require.Equal(t, conversion.Transaction.ID, uuid.UUID(conversion.Quote.TransactionID.Bytes))
+		ids = append(ids, conversion.Transaction.ID)
+	}
+	require.NotEqual(t, ids[0], ids[1])
+
+	require.Equal(t, "90.00", f.FakeBalance(dollars))
+	require.Equal(t, "29700.00", f.FakeBalance(naira))
+}
@@ -0,0 +1,32 @@
+package fx
+
+import "github.com/luponetn/paycore/internal/db"
+
+// RateRequest sets a mid-market rate: one unit of BaseCurrency buys Rate units of QuoteCurrency.
+// The rates file holds a JSON array of these.
+type RateRequest struct {
+	BaseCurrency  string `json:"base_currency" binding:"required,len=3"`
+	QuoteCurrency string `json:"quote_currency" binding:"required,len=3"`
+	Rate          string `json:"rate" binding:"required"`
+}
+
+type SetRatesRequest struct {
+	Rates []RateRequest `json:"rates" binding:"required,min=1,dive"`
+}
+
+// QuoteRequest asks what Amount of the source wallet's currency would buy in the target wallet's.
+type QuoteRequest struct {
+	FromWalletID string `json:"from_wallet_id" binding:"required,uuid"`
+	ToWalletID   string `json:"to_wallet_id" binding:"required,uuid"`
+	Amount       string `json:"amount" binding:"required"`
+}
+
+type ConvertRequest struct {
+	QuoteID        string `json:"quote_id" binding:"required,uuid"`
+	IdempotencyKey string `json:"idempotency_key" binding:"required"`
+}
+
+type ConversionResponse struct {
+	Transaction db.Transaction `json:"transaction"`
+	Quote       db.FxQuote     `json:"quote"`
+}
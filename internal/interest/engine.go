@@ -0,0 +1,79 @@
+package interest
+
+import (
+	"time"
+
+	"github.com/luponetn/paycore/internal/config"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	DayCountACT365 = "ACT/365"
+	DayCount30360  = "30/360"
+)
+
+// accrualPlaces is the precision daily accruals are kept at; only the monthly total is
+// truncated to the currency's minor unit when it is capitalised.
+const accrualPlaces = 6
+
+// annualInterest is a year's interest on balance, each tier's rate applying to the slice of
+// the balance between its floor and the next tier's floor.
+func annualInterest(balance decimal.Decimal, tiers []config.InterestTier) decimal.Decimal {
+	total := decimal.Zero
+	for i, tier := range tiers {
+		if !balance.GreaterThan(tier.Floor) {
+			break
+		}
+		top := balance
+		if i+1 < len(tiers) && tiers[i+1].Floor.LessThan(balance) {
+			top = tiers[i+1].Floor
+		}
+		total = total.Add(top.Sub(tier.Floor).Mul(tier.Rate))
+	}
+	return total
+}
+
+// dailyAccrual is the interest balance earns for day under the day-count convention, together
+// with the blended annual rate across tiers.
+func dailyAccrual(balance decimal.Decimal, tiers []config.InterestTier, dayCount string, day time.Time) (decimal.Decimal, decimal.Decimal) {
+	annual := annualInterest(balance, tiers)
+
+	rate := decimal.Zero
+	if balance.IsPositive() {
+		rate = annual.Div(balance).Round(6)
+	}
+
+	days, basis := 1, 365
+	if dayCount == DayCount30360 {
+		days, basis = days360(day, day.AddDate(0, 0, 1)), 360
+	}
+	amount := annual.Mul(decimal.NewFromInt(int64(days))).Div(decimal.NewFromInt(int64(basis))).Round(accrualPlaces)
+	return amount, rate
+}
+
+// days360 counts the days between two dates on a 30/360 bond basis. Every month counts as 30
+// days: one day of a 31-day month earns nothing and the last day of February makes up the
+// difference.
+func days360(from, to time.Time) int {
+	y1, m1, d1 := from.Date()
+	y2, m2, d2 := to.Date()
+	if d1 == 31 {
+		d1 = 30
+	}
+	if d2 == 31 && d1 == 30 {
+		d2 = 30
+	}
+	return (y2-y1)*360 + (int(m2)-int(m1))*30 + (d2 - d1)
+}
+
+// today truncates t to its UTC calendar date, the unit interest accrues in.
+func today(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// monthStart is the first day of t's month; accruals dated before it are due for capitalisation.
+func monthStart(t time.Time) time.Time {
+	y, m, _ := t.UTC().Date()
+	return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+}
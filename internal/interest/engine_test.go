@@ -0,0 +1,42 @@
+package interest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/luponetn/paycore/internal/config"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+var testTiers = []config.InterestTier{
+	{Floor: decimal.Zero, Rate: decimal.RequireFromString("0.0365")},
+	{Floor: decimal.NewFromInt(100000), Rate: decimal.RequireFromString("0.073")},
+}
+
+func TestAnnualInterest_AppliesEachTierToItsBand(t *testing.T) {
+	require.Equal(t, "3650", annualInterest(decimal.NewFromInt(100000), testTiers).String())
+
+	// 100,000 at 3.65% plus the 50,000 above the floor at 7.3%
+	require.Equal(t, "7300", annualInterest(decimal.NewFromInt(150000), testTiers).String())
+
+	require.True(t, annualInterest(decimal.Zero, testTiers).IsZero())
+}
+
+func TestDailyAccrual_DayCountConventions(t *testing.T) {
+	balance := decimal.NewFromInt(100000)
+
+	amount, rate := dailyAccrual(balance, testTiers, DayCountACT365, time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC))
+	require.Equal(t, "10", amount.String())
+	require.Equal(t, "0.0365", rate.String())
+
+	// Under 30/360 every month earns exactly 30 days, whatever its length.
+	for _, month := range []time.Month{time.January, time.February, time.April} {
+		total := decimal.Zero
+		for day := time.Date(2028, month, 1, 0, 0, 0, 0, time.UTC); day.Month() == month; day = day.AddDate(0, 0, 1) {
+			amount, _ := dailyAccrual(balance, testTiers, DayCount30360, day)
+			total = total.Add(amount)
+		}
+		require.Equal(t, "304.17", total.StringFixed(2), month.String())
+	}
+}
@@ -0,0 +1,8 @@
+package interest
+
+import "errors"
+
+var (
+	ErrInvalidReportDate = errors.New("report date must be a past date in YYYY-MM-DD format")
+	ErrNotSavingsWallet  = errors.New("interest is only paid on savings wallets")
+)
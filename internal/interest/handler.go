@@ -0,0 +1,99 @@
+package interest
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/luponetn/paycore/internal/transfer"
+)
+
+type Handler struct {
+	svc Service
+}
+
+func NewHandler(svc Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+// HandleReport is the finance dry run: ?date=YYYY-MM-DD, defaulting to yesterday.
+func (h *Handler) HandleReport(c *gin.Context) {
+	day := time.Now().UTC().AddDate(0, 0, -1)
+	if raw := c.Query("date"); raw != "" {
+		parsed, err := time.Parse(time.DateOnly, raw)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": ErrInvalidReportDate.Error()})
+			return
+		}
+		day = parsed
+	}
+
+	report, err := h.svc.Report(c.Request.Context(), day)
+	if err != nil {
+		c.AbortWithStatusJSON(interestErrorStatus(err), gin.H{
+			"message": "failed to build interest report",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "interest report built successfully",
+		"data":    report,
+	})
+}
+
+func (h *Handler) HandleWalletAccruals(c *gin.Context) {
+	userIdVal, exists := c.Get("user_id")
+	if !exists {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	userID, ok := userIdVal.(uuid.UUID)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "invalid user id type"})
+		return
+	}
+
+	walletID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid wallet id"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "31"))
+	if err != nil || limit < 1 || limit > 366 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 366"})
+		return
+	}
+
+	accruals, err := h.svc.WalletAccruals(c.Request.Context(), userID, walletID, int32(limit))
+	if err != nil {
+		c.AbortWithStatusJSON(interestErrorStatus(err), gin.H{
+			"message": "failed to fetch interest accruals",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "interest accruals fetched successfully",
+		"data":    accruals,
+	})
+}
+
+func interestErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrInvalidReportDate), errors.Is(err, ErrNotSavingsWallet):
+		return http.StatusBadRequest
+	case errors.Is(err, transfer.ErrWalletNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, transfer.ErrUnauthorizedWallet):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
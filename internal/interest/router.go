@@ -0,0 +1,25 @@
+package interest
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/luponetn/paycore/internal/middleware"
+	"github.com/luponetn/paycore/pkg/utils"
+)
+
+func RegisterRoutes(r *gin.Engine, h *Handler, keys *utils.KeySet, sessions middleware.SessionChecker, adminKey string) {
+	interestGroup := r.Group("/interest")
+
+	//use middlewares
+	interestGroup.Use(middleware.AuthMiddleware(keys, sessions))
+
+	//implement routes
+	{
+		interestGroup.GET("/wallets/:id", h.HandleWalletAccruals)
+	}
+
+	adminGroup := r.Group("/admin/interest")
+	adminGroup.Use(middleware.AdminKeyMiddleware(adminKey))
+	{
+		adminGroup.GET("/report", h.HandleReport)
+	}
+}
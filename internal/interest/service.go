@@ -0,0 +1,278 @@
+package interest
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/luponetn/paycore/internal/accounts"
+	"github.com/luponetn/paycore/internal/config"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/ledger"
+	"github.com/luponetn/paycore/internal/store"
+	"github.com/luponetn/paycore/internal/transfer"
+	"github.com/luponetn/paycore/pkg/money"
+	"github.com/luponetn/paycore/pkg/utils"
+	"github.com/shopspring/decimal"
+)
+
+type Service interface {
+	DueWalletIDs(ctx context.Context, day time.Time, limit int32) ([]uuid.UUID, error)
+	AccrueWallet(ctx context.Context, walletID uuid.UUID, day time.Time) error
+	CapitalisationDue(ctx context.Context, asOf time.Time, limit int32) ([]uuid.UUID, error)
+	CapitaliseWallet(ctx context.Context, walletID uuid.UUID, asOf time.Time) error
+	Report(ctx context.Context, day time.Time) (Report, error)
+	WalletAccruals(ctx context.Context, userID uuid.UUID, walletID uuid.UUID, limit int32) ([]db.InterestAccrual, error)
+}
+
+type Svc struct {
+	store store.Store
+	cfg   *config.Config
+}
+
+// NewService wires the savings interest engine; cfg carries the rate tiers and day-count convention.
+func NewService(store store.Store, cfg *config.Config) Service {
+	return &Svc{store: store, cfg: cfg}
+}
+
+// reportPageSize is how many wallets the report reads per query.
+const reportPageSize = 500
+
+// DueWalletIDs lists savings wallets with a balance that have no accrual recorded for day.
+func (s *Svc) DueWalletIDs(ctx context.Context, day time.Time, limit int32) ([]uuid.UUID, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	day = today(day)
+	return s.store.Queries().GetSavingsWalletIdsDueForAccrual(ctx, db.GetSavingsWalletIdsDueForAccrualParams{
+		CreatedBefore: pgtype.Timestamptz{Time: day.AddDate(0, 0, 1), Valid: true},
+		AccrualDate:   pgtype.Date{Time: day, Valid: true},
+		BatchSize:     limit,
+	})
+}
+
+// AccrueWallet records the interest the wallet's current balance earns for day. The accrual
+// job runs just after midnight UTC, so the current balance is the previous day's closing one.
+// Recording the same day twice is a no-op.
+func (s *Svc) AccrueWallet(ctx context.Context, walletID uuid.UUID, day time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	wallet, err := s.store.Queries().GetWalletById(ctx, walletID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return transfer.ErrWalletNotFound
+		}
+		return err
+	}
+	if wallet.WalletType != db.WalletTypeEnumSavings {
+		return ErrNotSavingsWallet
+	}
+
+	balance := utils.NumericToDecimal(wallet.Balance)
+	if !balance.IsPositive() {
+		return nil
+	}
+
+	day = today(day)
+	amount, rate := dailyAccrual(balance, s.cfg.SavingsInterestTiers, s.cfg.SavingsInterestDayCount, day)
+
+	_, err = s.store.Queries().CreateInterestAccrual(ctx, db.CreateInterestAccrualParams{
+		WalletID:    wallet.ID,
+		AccrualDate: pgtype.Date{Time: day, Valid: true},
+		Balance:     wallet.Balance,
+		AnnualRate:  utils.DecimalToNumeric(rate),
+		DayCount:    s.cfg.SavingsInterestDayCount,
+		Amount:      utils.DecimalToNumeric(amount),
+		Currency:    wallet.Currency,
+	})
+	// ON CONFLICT DO NOTHING returns no row when the day was already recorded.
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+	return nil
+}
+
+// CapitalisationDue lists wallets holding accruals from months before asOf's month.
+func (s *Svc) CapitalisationDue(ctx context.Context, asOf time.Time, limit int32) ([]uuid.UUID, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	return s.store.Queries().GetWalletIdsWithUncapitalisedInterest(ctx, db.GetWalletIdsWithUncapitalisedInterestParams{
+		Before:    pgtype.Date{Time: monthStart(asOf), Valid: true},
+		BatchSize: limit,
+	})
+}
+
+// CapitaliseWallet pays the wallet's accruals from months before asOf's month into its balance
+// as one interest transaction from the interest expense account. The total is truncated to the
+// currency's minor unit; the remainder is not carried over.
+func (s *Svc) CapitaliseWallet(ctx context.Context, walletID uuid.UUID, asOf time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	wallet, err := s.store.Queries().GetWalletById(ctx, walletID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return transfer.ErrWalletNotFound
+		}
+		return err
+	}
+
+	before := monthStart(asOf)
+	period := before.AddDate(0, 0, -1).Format("2006-01")
+
+	_, err = utils.Retry(3, 100, func() (struct{}, error) {
+		tx, err := s.store.Begin(ctx)
+		if err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+
+		defer func() {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback capitalise interest tx", "error", rbErr)
+			}
+		}()
+
+		qtx := s.store.WithTx(tx)
+
+		// locking the wallet first serialises capitalisation runs for the same wallet
+		if _, err := qtx.GetWalletsForUpdate(ctx, []uuid.UUID{wallet.ID}); err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+
+		total, err := qtx.SumUncapitalisedInterest(ctx, db.SumUncapitalisedInterestParams{
+			WalletID: wallet.ID,
+			Before:   pgtype.Date{Time: before, Valid: true},
+		})
+		if err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+
+		var transactionID pgtype.UUID
+		if amount := utils.NumericToDecimal(total).Truncate(money.MinorUnitsOf(wallet.Currency)); amount.IsPositive() {
+			created, err := ledger.Post(ctx, qtx, ledger.Journal{
+				TransactionType: db.TransactionTypeEnumInterest,
+				Description:     "savings interest",
+				IdempotencyKey:  "savings_interest:" + wallet.ID.String() + ":" + period,
+				Postings: []ledger.Posting{
+					ledger.DebitAccount(accounts.InterestExpense, amount, wallet.Currency),
+					ledger.Credit(wallet.ID, amount, wallet.Currency),
+				},
+			})
+			if err != nil {
+				return struct{}{}, err
+			}
+			transactionID = utils.ToPgUUID(created.ID)
+		}
+
+		if err := qtx.MarkInterestCapitalised(ctx, db.MarkInterestCapitalisedParams{
+			TransactionID: transactionID,
+			WalletID:      wallet.ID,
+			Before:        pgtype.Date{Time: before, Valid: true},
+		}); err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+		return struct{}{}, nil
+	})
+	return err
+}
+
+// Report works out the accrual every savings wallet would receive for day at its current
+// balance without recording anything, alongside what has already been recorded for that day.
+func (s *Svc) Report(ctx context.Context, day time.Time) (Report, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	day = today(day)
+	if day.After(today(time.Now())) {
+		return Report{}, ErrInvalidReportDate
+	}
+
+	recorded, err := s.store.Queries().GetInterestAccrualsByDate(ctx, pgtype.Date{Time: day, Valid: true})
+	if err != nil {
+		return Report{}, err
+	}
+	posted := make(map[uuid.UUID]decimal.Decimal, len(recorded))
+	for _, accrual := range recorded {
+		posted[accrual.WalletID] = utils.NumericToDecimal(accrual.Amount)
+	}
+
+	report := Report{
+		Date:     day.Format(time.DateOnly),
+		DayCount: s.cfg.SavingsInterestDayCount,
+		Lines:    []ReportLine{},
+		Totals:   make(map[string]decimal.Decimal),
+	}
+
+	after := uuid.Nil
+	for {
+		wallets, err := s.store.Queries().ListSavingsWalletsForInterest(ctx, db.ListSavingsWalletsForInterestParams{
+			AfterID:   after,
+			BatchSize: reportPageSize,
+		})
+		if err != nil {
+			return Report{}, err
+		}
+
+		for _, wallet := range wallets {
+			balance := utils.NumericToDecimal(wallet.Balance)
+			amount, rate := dailyAccrual(balance, s.cfg.SavingsInterestTiers, s.cfg.SavingsInterestDayCount, day)
+
+			line := ReportLine{
+				WalletID:   wallet.ID,
+				Currency:   wallet.Currency,
+				Balance:    balance,
+				AnnualRate: rate,
+				Amount:     amount,
+			}
+			if p, ok := posted[wallet.ID]; ok {
+				line.Posted = &p
+			}
+			report.Lines = append(report.Lines, line)
+			report.Totals[wallet.Currency] = report.Totals[wallet.Currency].Add(amount)
+		}
+
+		if len(wallets) < reportPageSize {
+			return report, nil
+		}
+		after = wallets[len(wallets)-1].ID
+	}
+}
+
+// WalletAccruals lists the most recent daily accruals on one of the user's wallets.
+func (s *Svc) WalletAccruals(ctx context.Context, userID uuid.UUID, walletID uuid.UUID, limit int32) ([]db.InterestAccrual, error) {
+	ctx, cancel := context.WithTimeout(ctx, 8*time.Second)
+	defer cancel()
+
+	wallet, err := s.store.Queries().GetWalletById(ctx, walletID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, transfer.ErrWalletNotFound
+		}
+		return nil, err
+	}
+	if !wallet.UserID.Valid || uuid.UUID(wallet.UserID.Bytes) != userID {
+		return nil, transfer.ErrUnauthorizedWallet
+	}
+
+	accruals, err := s.store.Queries().GetInterestAccrualsByWalletId(ctx, db.GetInterestAccrualsByWalletIdParams{
+		WalletID: walletID,
+		Limit:    limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if accruals == nil {
+		accruals = []db.InterestAccrual{}
+	}
+	return accruals, nil
+}
@@ -0,0 +1,89 @@
+package interest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/luponetn/paycore/internal/config"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccrueAndCapitalise(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := NewService(f, &config.Config{SavingsInterestTiers: testTiers, SavingsInterestDayCount: DayCountACT365})
+	ctx := context.Background()
+
+	userID := uuid.New()
+	savings := f.AddFakeFundedWallet(userID, db.WalletTypeEnumSavings, "NGN", "100000")
+	misc := f.AddFakeFundedWallet(userID, db.WalletTypeEnumMisc, "NGN", "100000")
+
+	// 100,000 at 3.65% earns 10 a day; the last three days of March are accrued, twice over.
+	for day := 29; day <= 31; day++ {
+		date := time.Date(2026, 3, day, 0, 0, 0, 0, time.UTC)
+		for range 2 {
+			ids, err := svc.DueWalletIDs(ctx, date, 10)
+			require.NoError(t, err)
+			for _, id := range ids {
+				require.NoError(t, svc.AccrueWallet(ctx, id, date))
+			}
+		}
+	}
+	require.ErrorIs(t, svc.AccrueWallet(ctx, misc, time.Now()), ErrNotSavingsWallet)
+
+	accruals, err := svc.WalletAccruals(ctx, userID, savings, 31)
+	require.NoError(t, err)
+	require.Len(t, accruals, 3)
+
+	// Nothing is capitalised until the month has ended.
+	due, err := svc.CapitalisationDue(ctx, time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC), 10)
+	require.NoError(t, err)
+	require.Empty(t, due)
+
+	april := time.Date(2026, 4, 1, 0, 5, 0, 0, time.UTC)
+	due, err = svc.CapitalisationDue(ctx, april, 10)
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{savings}, due)
+	require.NoError(t, svc.CapitaliseWallet(ctx, savings, april))
+	require.NoError(t, svc.CapitaliseWallet(ctx, savings, april))
+
+	balance, available := f.FakeBalances(savings)
+	require.Equal(t, "100030.00", balance)
+	require.Equal(t, "100030.00", available)
+
+	due, err = svc.CapitalisationDue(ctx, april, 10)
+	require.NoError(t, err)
+	require.Empty(t, due)
+}
+
+func TestReport_PreviewsWithoutRecording(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := NewService(f, &config.Config{SavingsInterestTiers: testTiers, SavingsInterestDayCount: DayCountACT365})
+	ctx := context.Background()
+
+	first := f.AddFakeFundedWallet(uuid.New(), db.WalletTypeEnumSavings, "NGN", "100000")
+	f.AddFakeFundedWallet(uuid.New(), db.WalletTypeEnumSavings, "NGN", "150000")
+
+	day := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, svc.AccrueWallet(ctx, first, day))
+
+	report, err := svc.Report(ctx, day)
+	require.NoError(t, err)
+	require.Len(t, report.Lines, 2)
+	require.Equal(t, "30", report.Totals["NGN"].String())
+
+	for _, line := range report.Lines {
+		if line.WalletID == first {
+			require.NotNil(t, line.Posted)
+			require.True(t, line.Posted.Equal(line.Amount))
+		} else {
+			require.Nil(t, line.Posted)
+		}
+	}
+
+	_, err = svc.Report(ctx, time.Now().AddDate(0, 0, 2))
+	require.ErrorIs(t, err, ErrInvalidReportDate)
+}
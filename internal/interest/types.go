@@ -0,0 +1,24 @@
+package interest
+
+import (
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// ReportLine is the accrual one wallet would receive for the report date. Posted is the amount
+// already recorded for that date, if the accrual job has run.
+type ReportLine struct {
+	WalletID   uuid.UUID        `json:"wallet_id"`
+	Currency   string           `json:"currency"`
+	Balance    decimal.Decimal  `json:"balance"`
+	AnnualRate decimal.Decimal  `json:"annual_rate"`
+	Amount     decimal.Decimal  `json:"amount"`
+	Posted     *decimal.Decimal `json:"posted,omitempty"`
+}
+
+type Report struct {
+	Date     string                     `json:"date"`
+	DayCount string                     `json:"day_count"`
+	Lines    []ReportLine               `json:"lines"`
+	Totals   map[string]decimal.Decimal `json:"totals"`
+}
@@ -0,0 +1,12 @@
+package ledger
+
+import "errors"
+
+var (
+	ErrUnbalanced           = errors.New("journal debits and credits do not balance")
+	ErrInvalidPosting       = errors.New("journal posting is invalid")
+	ErrWalletNotFound       = errors.New("wallet not found")
+	ErrCurrencyMismatch     = errors.New("wallet currencies must be the same")
+	ErrInsufficientFunds    = errors.New("insufficient funds")
+	ErrIdempotencyKeyReused = errors.New("idempotency key was already used for a different transaction")
+)
@@ -0,0 +1,77 @@
+package ledger
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/outbox"
+	"github.com/luponetn/paycore/pkg/money"
+	"github.com/luponetn/paycore/pkg/utils"
+)
+
+// recordEvents writes the transaction's completion event and a balance event for every customer
+// wallet it touched into the outbox, inside the journal's transaction.
+func recordEvents(ctx context.Context, qtx db.Querier, created db.Transaction, postings []Posting, amounts []money.Money, wallets map[uuid.UUID]*walletState) error {
+	amount, err := money.FromNumeric(created.Amount, created.Currency)
+	if err != nil {
+		return err
+	}
+	fee, err := money.FromNumeric(created.Fee, created.Currency)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	completed := outbox.TransactionCompletedPayload{
+		TransactionID:       created.ID,
+		TransactionType:     created.TransactionType,
+		SenderWalletID:      optionalID(created.SenderWalletID),
+		ReceiverWalletID:    optionalID(created.ReceiverWalletID),
+		ParentTransactionID: optionalID(created.ParentTransactionID),
+		Amount:              amount,
+		Fee:                 fee,
+		Description:         created.Description.String,
+		Status:              created.Status,
+		Legs:                make([]outbox.TransactionLeg, len(postings)),
+		CompletedAt:         now,
+	}
+	for i, p := range postings {
+		completed.Legs[i] = outbox.TransactionLeg{WalletID: p.WalletID, EntryType: p.EntryType, Amount: amounts[i]}
+	}
+	if err := outbox.Record(ctx, qtx, outbox.AggregateTransaction, created.ID, outbox.TransactionCompleted(created.TransactionType), completed); err != nil {
+		return &utils.RetryableError{Err: err}
+	}
+
+	// walk the postings rather than the map so the events come out in a stable order
+	seen := make(map[uuid.UUID]bool, len(wallets))
+	for _, p := range postings {
+		w := wallets[p.WalletID]
+		if seen[p.WalletID] || w.row.WalletType == db.WalletTypeEnumSystem || !w.row.UserID.Valid {
+			continue
+		}
+		seen[p.WalletID] = true
+
+		if err := outbox.Record(ctx, qtx, outbox.AggregateWallet, p.WalletID, outbox.EventWalletBalanceChanged, outbox.WalletBalanceChangedPayload{
+			WalletID:         p.WalletID,
+			UserID:           w.row.UserID.Bytes,
+			TransactionID:    created.ID,
+			Balance:          w.balance,
+			AvailableBalance: w.available,
+			ChangedAt:        now,
+		}); err != nil {
+			return &utils.RetryableError{Err: err}
+		}
+	}
+	return nil
+}
+
+func optionalID(id pgtype.UUID) *uuid.UUID {
+	if !id.Valid {
+		return nil
+	}
+	v := uuid.UUID(id.Bytes)
+	return &v
+}
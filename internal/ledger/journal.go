@@ -0,0 +1,307 @@
+package ledger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/luponetn/paycore/internal/accounts"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/pkg/money"
+	"github.com/luponetn/paycore/pkg/utils"
+	"github.com/shopspring/decimal"
+)
+
+// walletState tracks one locked wallet's balances as the journal's legs are applied.
+type walletState struct {
+	row       db.GetWalletsForUpdateRow
+	balance   money.Money
+	available money.Money
+}
+
+// Post writes the journal inside the caller's transaction: it locks every wallet involved in
+// id order, checks that debits equal credits in each currency, and writes the transactions row,
+// one ledger row per posting, the new wallet balances and the matching outbox events. A journal whose idempotency key is
+// already used returns the existing transaction without posting anything, provided that
+// transaction is the one the journal describes; otherwise it fails with ErrIdempotencyKeyReused.
+//
+// A debit may not take a user wallet's available balance (or, for a locked leg, its ledger
+// balance) below zero; system wallets may run negative.
+func Post(ctx context.Context, qtx db.Querier, j Journal) (db.Transaction, error) {
+	amounts, err := validate(j)
+	if err != nil {
+		return db.Transaction{}, err
+	}
+
+	postings := make([]Posting, len(j.Postings))
+	for i, p := range j.Postings {
+		// carry every amount at its currency's scale into the ledger rows
+		p.Amount = amounts[i].Amount()
+		if p.Account != "" {
+			walletID, err := accounts.SystemWallet(ctx, qtx, p.Account, p.Currency)
+			if err != nil {
+				return db.Transaction{}, err
+			}
+			p.WalletID = walletID
+		}
+		postings[i] = p
+	}
+
+	wallets, err := lockWallets(ctx, qtx, postings)
+	if err != nil {
+		return db.Transaction{}, err
+	}
+
+	params := summarise(j, postings)
+	if existing, err := qtx.GetTransactionByIdempotencyKey(ctx, j.IdempotencyKey); err == nil {
+		if !sameTransaction(existing, params) {
+			return db.Transaction{}, ErrIdempotencyKeyReused
+		}
+		return existing, nil
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return db.Transaction{}, &utils.RetryableError{Err: err}
+	}
+
+	for i, p := range postings {
+		w := wallets[p.WalletID]
+		if w.row.Currency != p.Currency {
+			return db.Transaction{}, ErrCurrencyMismatch
+		}
+		delta := amounts[i]
+		if p.EntryType == db.LedgerEntryTypeDebit {
+			delta = delta.Neg()
+		}
+		if w.balance, err = w.balance.Add(delta); err != nil {
+			return db.Transaction{}, ErrCurrencyMismatch
+		}
+		if !p.Locked {
+			if w.available, err = w.available.Add(delta); err != nil {
+				return db.Transaction{}, ErrCurrencyMismatch
+			}
+		}
+	}
+
+	for _, w := range wallets {
+		if w.row.WalletType == db.WalletTypeEnumSystem {
+			continue
+		}
+		if (w.available.IsNegative() && w.available.Amount().LessThan(utils.NumericToDecimal(w.row.AvailableBalance))) ||
+			(w.balance.IsNegative() && w.balance.Amount().LessThan(utils.NumericToDecimal(w.row.Balance))) {
+			return db.Transaction{}, ErrInsufficientFunds
+		}
+	}
+
+	created, err := qtx.CreateTransaction(ctx, params)
+	if err != nil {
+		return db.Transaction{}, &utils.RetryableError{Err: err}
+	}
+
+	// ledger rows carry each wallet's running balance through the journal
+	running := make(map[uuid.UUID]decimal.Decimal, len(wallets))
+	for id, w := range wallets {
+		running[id] = utils.NumericToDecimal(w.row.Balance)
+	}
+	for _, p := range postings {
+		before := running[p.WalletID]
+		after := before.Add(p.Amount)
+		if p.EntryType == db.LedgerEntryTypeDebit {
+			after = before.Sub(p.Amount)
+		}
+		running[p.WalletID] = after
+
+		if _, err := qtx.CreateLedger(ctx, db.CreateLedgerParams{
+			WalletID:      p.WalletID,
+			TransactionID: created.ID,
+			Amount:        utils.DecimalToNumeric(p.Amount),
+			EntryType:     p.EntryType,
+			Currency:      p.Currency,
+			BalanceBefore: utils.DecimalToNumeric(before),
+			BalanceAfter:  utils.DecimalToNumeric(after),
+		}); err != nil {
+			return db.Transaction{}, &utils.RetryableError{Err: err}
+		}
+	}
+
+	for id, w := range wallets {
+		if err := qtx.UpdateWalletBalance(ctx, db.UpdateWalletBalanceParams{
+			Balance:          w.balance.Numeric(),
+			AvailableBalance: w.available.Numeric(),
+			ID:               id,
+		}); err != nil {
+			return db.Transaction{}, &utils.RetryableError{Err: err}
+		}
+	}
+
+	if err := qtx.UpdateTransactionStatus(ctx, db.UpdateTransactionStatusParams{
+		Status: db.TransactionStatusEnumCompleted,
+		ID:     created.ID,
+	}); err != nil {
+		return db.Transaction{}, &utils.RetryableError{Err: err}
+	}
+
+	created.Status = db.TransactionStatusEnumCompleted
+	if err := recordEvents(ctx, qtx, created, postings, amounts, wallets); err != nil {
+		return db.Transaction{}, err
+	}
+	return created, nil
+}
+
+// validate checks the journal's shape before anything is locked and returns each posting's
+// amount as money, which rejects amounts finer than the currency's minor unit.
+func validate(j Journal) ([]money.Money, error) {
+	if j.IdempotencyKey == "" || len(j.Postings) < 2 {
+		return nil, ErrInvalidPosting
+	}
+
+	// debits less credits per currency; every currency must come to zero
+	amounts := make([]money.Money, len(j.Postings))
+	net := make(map[string]money.Money)
+	for i, p := range j.Postings {
+		if (p.WalletID == uuid.Nil) == (p.Account == "") || !p.Amount.IsPositive() {
+			return nil, ErrInvalidPosting
+		}
+		amount, err := money.New(p.Amount, p.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidPosting, err)
+		}
+		amounts[i] = amount
+
+		diff, ok := net[amount.Code()]
+		if !ok {
+			diff = amount.Currency().Zero()
+		}
+		switch p.EntryType {
+		case db.LedgerEntryTypeDebit:
+			diff, err = diff.Add(amount)
+		case db.LedgerEntryTypeCredit:
+			diff, err = diff.Sub(amount)
+		default:
+			return nil, ErrInvalidPosting
+		}
+		if err != nil {
+			return nil, err
+		}
+		net[amount.Code()] = diff
+	}
+
+	for _, diff := range net {
+		if !diff.IsZero() {
+			return nil, fmt.Errorf("%w: %s is off by %s", ErrUnbalanced, diff.Code(), diff.Amount().Abs().StringFixed(diff.Currency().MinorUnits))
+		}
+	}
+	return amounts, nil
+}
+
+// LockWallets locks the given wallets in id order. A caller that reads some wallets before
+// posting a journal that also touches others (such as a system account) locks the whole set
+// here first, so no lock is taken out of order when Post locks the journal's wallets again.
+func LockWallets(ctx context.Context, qtx db.Querier, ids ...uuid.UUID) error {
+	_, err := lockRows(ctx, qtx, ids)
+	return err
+}
+
+// lockWallets locks every wallet the postings touch, in id order so concurrent journals over
+// overlapping wallets cannot deadlock.
+func lockWallets(ctx context.Context, qtx db.Querier, postings []Posting) (map[uuid.UUID]*walletState, error) {
+	ids := make([]uuid.UUID, len(postings))
+	for i, p := range postings {
+		ids[i] = p.WalletID
+	}
+	rows, err := lockRows(ctx, qtx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	wallets := make(map[uuid.UUID]*walletState, len(rows))
+	for _, row := range rows {
+		balance, err := money.FromNumeric(row.Balance, row.Currency)
+		if err != nil {
+			return nil, err
+		}
+		available, err := money.FromNumeric(row.AvailableBalance, row.Currency)
+		if err != nil {
+			return nil, err
+		}
+		wallets[row.ID] = &walletState{row: row, balance: balance, available: available}
+	}
+	return wallets, nil
+}
+
+// lockRows locks the distinct wallets in ids, sorted by id.
+func lockRows(ctx context.Context, qtx db.Querier, ids []uuid.UUID) ([]db.GetWalletsForUpdateRow, error) {
+	seen := make(map[uuid.UUID]bool)
+	var sorted []uuid.UUID
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			sorted = append(sorted, id)
+		}
+	}
+	sort.Slice(sorted, func(i, k int) bool { return bytes.Compare(sorted[i][:], sorted[k][:]) < 0 })
+
+	rows, err := qtx.GetWalletsForUpdate(ctx, sorted)
+	if err != nil {
+		return nil, &utils.RetryableError{Err: err}
+	}
+	if len(rows) != len(sorted) {
+		return nil, ErrWalletNotFound
+	}
+	return rows, nil
+}
+
+// summarise builds the transactions row, filling the headline fields the journal leaves unset.
+func summarise(j Journal, postings []Posting) db.CreateTransactionParams {
+	sender, receiver, amount, currency := j.SenderWalletID, j.ReceiverWalletID, j.Amount, j.Currency
+	var firstDebit, firstCredit *Posting
+	for i := range postings {
+		if postings[i].EntryType == db.LedgerEntryTypeDebit && firstDebit == nil {
+			firstDebit = &postings[i]
+		}
+		if postings[i].EntryType == db.LedgerEntryTypeCredit && firstCredit == nil {
+			firstCredit = &postings[i]
+		}
+	}
+	if sender == uuid.Nil {
+		sender = firstDebit.WalletID
+	}
+	if receiver == uuid.Nil {
+		receiver = firstCredit.WalletID
+	}
+	if amount.IsZero() {
+		amount = firstDebit.Amount
+	}
+	if currency == "" {
+		currency = firstDebit.Currency
+	}
+
+	return db.CreateTransactionParams{
+		SenderWalletID:      pgtype.UUID{Bytes: sender, Valid: sender != uuid.Nil},
+		ReceiverWalletID:    pgtype.UUID{Bytes: receiver, Valid: receiver != uuid.Nil},
+		TransactionType:     j.TransactionType,
+		Amount:              utils.DecimalToNumeric(amount),
+		Description:         pgtype.Text{String: j.Description, Valid: j.Description != ""},
+		Status:              db.TransactionStatusEnumPending,
+		Currency:            currency,
+		IdempotencyKey:      j.IdempotencyKey,
+		ParentTransactionID: pgtype.UUID{Bytes: j.ParentTransactionID, Valid: j.ParentTransactionID != uuid.Nil},
+		Fee:                 utils.DecimalToNumeric(j.Fee),
+		FeeScheduleID:       pgtype.UUID{Bytes: j.FeeScheduleID, Valid: j.FeeScheduleID != uuid.Nil},
+	}
+}
+
+// sameTransaction reports whether an existing transactions row is the one params would write.
+// Idempotency keys are unique across all transactions, so a replay must not hand back a row
+// posted for another wallet, parent or amount under the same key.
+func sameTransaction(existing db.Transaction, params db.CreateTransactionParams) bool {
+	return existing.SenderWalletID == params.SenderWalletID &&
+		existing.ReceiverWalletID == params.ReceiverWalletID &&
+		existing.TransactionType == params.TransactionType &&
+		existing.ParentTransactionID == params.ParentTransactionID &&
+		existing.Currency == params.Currency &&
+		utils.NumericToDecimal(existing.Amount).Equal(utils.NumericToDecimal(params.Amount))
+}
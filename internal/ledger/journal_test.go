@@ -0,0 +1,162 @@
+package ledger
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/luponetn/paycore/internal/accounts"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/outbox"
+	"github.com/luponetn/paycore/internal/store"
+	"github.com/luponetn/paycore/pkg/money"
+	"github.com/luponetn/paycore/pkg/utils"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func amount(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+func TestPost_SplitsFeeToSystemAccount(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := NewService(f)
+	ctx := context.Background()
+
+	payer := f.AddFakeFundedWallet(uuid.New(), db.WalletTypeEnumSavings, "NGN", "100")
+	payee := f.AddFakeFundedWallet(uuid.New(), db.WalletTypeEnumSavings, "NGN", "0")
+
+	journal := Journal{
+		TransactionType:  db.TransactionTypeEnumTransfer,
+		IdempotencyKey:   uuid.New().String(),
+		SenderWalletID:   payer,
+		ReceiverWalletID: payee,
+		Amount:           amount("40"),
+		Postings: []Posting{
+			Debit(payer, amount("41.50"), "NGN"),
+			Credit(payee, amount("40"), "NGN"),
+			CreditAccount(accounts.FeesRevenue, amount("1.50"), "NGN"),
+		},
+	}
+	created, err := svc.Post(ctx, journal)
+	require.NoError(t, err)
+	require.Equal(t, db.TransactionStatusEnumCompleted, created.Status)
+	require.Equal(t, "40.00", utils.NumericToDecimal(created.Amount).StringFixed(2))
+	require.Equal(t, "NGN", created.Currency)
+
+	// Replaying the journal returns the first transaction without posting again.
+	again, err := svc.Post(ctx, journal)
+	require.NoError(t, err)
+	require.Equal(t, created.ID, again.ID)
+
+	feesID, err := accounts.SystemWallet(ctx, f.Queries(), accounts.FeesRevenue, "NGN")
+	require.NoError(t, err)
+
+	require.Equal(t, "58.50", f.FakeBalance(payer))
+	require.Equal(t, "40.00", f.FakeBalance(payee))
+	require.Equal(t, "1.50", f.FakeBalance(feesID))
+
+	// One completion event and a balance event per customer wallet, none for the replay.
+	events := f.OutboxEvents()
+	require.Len(t, events, 3)
+	require.Equal(t, "transfer.completed", events[0].EventType)
+	require.Equal(t, created.ID, events[0].AggregateID)
+	require.Equal(t, outbox.EventWalletBalanceChanged, events[1].EventType)
+	require.Equal(t, payer, events[1].AggregateID)
+	require.Equal(t, outbox.EventWalletBalanceChanged, events[2].EventType)
+	require.Equal(t, payee, events[2].AggregateID)
+
+	var changed outbox.WalletBalanceChangedPayload
+	require.NoError(t, json.Unmarshal(events[1].Payload, &changed))
+	require.Equal(t, "58.50 NGN", changed.Balance.String())
+}
+
+func TestPost_RejectsKeyReusedForAnotherJournal(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := NewService(f)
+	ctx := context.Background()
+
+	payer := f.AddFakeFundedWallet(uuid.New(), db.WalletTypeEnumSavings, "NGN", "100")
+	payee := f.AddFakeFundedWallet(uuid.New(), db.WalletTypeEnumSavings, "NGN", "0")
+	other := f.AddFakeFundedWallet(uuid.New(), db.WalletTypeEnumSavings, "NGN", "100")
+
+	key := uuid.New().String()
+	post := func(from uuid.UUID, value string) (db.Transaction, error) {
+		return svc.Post(ctx, Journal{
+			TransactionType: db.TransactionTypeEnumTransfer,
+			IdempotencyKey:  key,
+			Postings:        []Posting{Debit(from, amount(value), "NGN"), Credit(payee, amount(value), "NGN")},
+		})
+	}
+
+	created, err := post(payer, "10")
+	require.NoError(t, err)
+
+	// Another wallet's journal under the same key must not get the first transaction back.
+	_, err = post(other, "10")
+	require.ErrorIs(t, err, ErrIdempotencyKeyReused)
+
+	// Nor may the same wallet change the amount.
+	_, err = post(payer, "20")
+	require.ErrorIs(t, err, ErrIdempotencyKeyReused)
+
+	again, err := post(payer, "10")
+	require.NoError(t, err)
+	require.Equal(t, created.ID, again.ID)
+
+	require.Equal(t, "90.00", f.FakeBalance(payer))
+	require.Equal(t, "100.00", f.FakeBalance(other))
+	require.Equal(t, "10.00", f.FakeBalance(payee))
+}
+
+func TestPost_RejectsInvalidJournals(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := NewService(f)
+	ctx := context.Background()
+
+	payer := f.AddFakeFundedWallet(uuid.New(), db.WalletTypeEnumSavings, "NGN", "10")
+	payee := f.AddFakeFundedWallet(uuid.New(), db.WalletTypeEnumSavings, "NGN", "0")
+	dollars := f.AddFakeFundedWallet(uuid.New(), db.WalletTypeEnumSavings, "USD", "0")
+
+	post := func(postings ...Posting) error {
+		_, err := svc.Post(ctx, Journal{
+			TransactionType: db.TransactionTypeEnumTransfer,
+			IdempotencyKey:  uuid.New().String(),
+			Postings:        postings,
+		})
+		return err
+	}
+
+	err := post(Debit(payer, amount("5"), "NGN"), Credit(payee, amount("4"), "NGN"))
+	require.ErrorIs(t, err, ErrUnbalanced)
+
+	err = post(Debit(payer, amount("5"), "NGN"))
+	require.ErrorIs(t, err, ErrInvalidPosting)
+
+	err = post(Debit(payer, amount("0"), "NGN"), Credit(payee, amount("0"), "NGN"))
+	require.ErrorIs(t, err, ErrInvalidPosting)
+
+	err = post(Debit(payer, amount("0.005"), "NGN"), Credit(payee, amount("0.005"), "NGN"))
+	require.ErrorIs(t, err, ErrInvalidPosting)
+	require.ErrorIs(t, err, money.ErrTooPrecise)
+
+	err = post(Debit(payer, amount("5"), "NGN"), Credit(dollars, amount("5"), "NGN"))
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	err = post(Debit(payer, amount("5"), "NGN"), Credit(uuid.New(), amount("5"), "NGN"))
+	require.ErrorIs(t, err, ErrWalletNotFound)
+
+	err = post(Debit(payer, amount("15"), "NGN"), Credit(payee, amount("15"), "NGN"))
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// Nothing moved.
+	require.Equal(t, "10.00", f.FakeBalance(payer))
+	require.Equal(t, "0.00", f.FakeBalance(payee))
+
+	// System accounts may go negative.
+	err = post(DebitAccount(accounts.InterestExpense, amount("3"), "NGN"), Credit(payee, amount("3"), "NGN"))
+	require.NoError(t, err)
+	require.Equal(t, "3.00", f.FakeBalance(payee))
+}
@@ -0,0 +1,55 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/store"
+	"github.com/luponetn/paycore/pkg/utils"
+)
+
+type Service interface {
+	Post(ctx context.Context, j Journal) (db.Transaction, error)
+}
+
+type Svc struct {
+	store store.Store
+}
+
+func NewService(store store.Store) Service {
+	return &Svc{store: store}
+}
+
+// Post writes the journal in a transaction of its own. Callers that must post alongside other
+// writes use the package-level Post with their own transaction instead.
+func (s *Svc) Post(ctx context.Context, j Journal) (db.Transaction, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	return utils.Retry(3, 100, func() (db.Transaction, error) {
+		tx, err := s.store.Begin(ctx)
+		if err != nil {
+			return db.Transaction{}, &utils.RetryableError{Err: err}
+		}
+
+		defer func() {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback journal tx", "error", rbErr)
+			}
+		}()
+
+		created, err := Post(ctx, s.store.WithTx(tx), j)
+		if err != nil {
+			return db.Transaction{}, err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return db.Transaction{}, &utils.RetryableError{Err: err}
+		}
+		return created, nil
+	})
+}
@@ -0,0 +1,65 @@
+package ledger
+
+import (
+	"github.com/google/uuid"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/shopspring/decimal"
+)
+
+// Posting is one leg of a journal. It names either a wallet or a chart-of-accounts system code,
+// which posts to that account's wallet in the posting's currency.
+type Posting struct {
+	WalletID  uuid.UUID
+	Account   string
+	EntryType db.LedgerEntryType
+	Amount    decimal.Decimal
+	Currency  string
+	// Locked legs move the ledger balance only. They carry funds kept out of the available
+	// balance: a captured hold leaving the sender, or deposit funds entering or leaving a fixed wallet.
+	Locked bool
+}
+
+// Journal is a balanced set of postings written under a single transactions row. The row's
+// sender, receiver and amount default to the first debit leg, the first credit leg and the
+// first debit's amount; set them when the headline differs, e.g. a transfer with a fee leg.
+type Journal struct {
+	TransactionType  db.TransactionTypeEnum
+	Description      string
+	IdempotencyKey   string
+	SenderWalletID   uuid.UUID
+	ReceiverWalletID uuid.UUID
+	Amount           decimal.Decimal
+	Currency         string
+	// Fee is the part of the sender's debit charged as a fee, under the schedule FeeScheduleID.
+	Fee           decimal.Decimal
+	FeeScheduleID uuid.UUID
+	// ParentTransactionID links a reversal or refund to the transaction it compensates.
+	ParentTransactionID uuid.UUID
+	Postings            []Posting
+}
+
+// Debit takes amount out of a wallet.
+func Debit(walletID uuid.UUID, amount decimal.Decimal, currency string) Posting {
+	return Posting{WalletID: walletID, EntryType: db.LedgerEntryTypeDebit, Amount: amount, Currency: currency}
+}
+
+// Credit pays amount into a wallet.
+func Credit(walletID uuid.UUID, amount decimal.Decimal, currency string) Posting {
+	return Posting{WalletID: walletID, EntryType: db.LedgerEntryTypeCredit, Amount: amount, Currency: currency}
+}
+
+// DebitAccount takes amount out of the system account's wallet for currency.
+func DebitAccount(code string, amount decimal.Decimal, currency string) Posting {
+	return Posting{Account: code, EntryType: db.LedgerEntryTypeDebit, Amount: amount, Currency: currency}
+}
+
+// CreditAccount pays amount into the system account's wallet for currency.
+func CreditAccount(code string, amount decimal.Decimal, currency string) Posting {
+	return Posting{Account: code, EntryType: db.LedgerEntryTypeCredit, Amount: amount, Currency: currency}
+}
+
+// AsLocked marks the posting as moving locked funds.
+func (p Posting) AsLocked() Posting {
+	p.Locked = true
+	return p
+}
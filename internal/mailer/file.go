@@ -0,0 +1,44 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileMailer writes every message into a Maildir (tmp/new/cur) instead of sending it,
+// so development and tests can read delivered mail with any Maildir-aware client or plain cat.
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+func NewFileMailer(dir string, from string) (*FileMailer, error) {
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return nil, err
+		}
+	}
+	return &FileMailer{dir: dir, from: from}, nil
+}
+
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	raw, err := buildMIME(m.from, msg)
+	if err != nil {
+		return err
+	}
+
+	// Maildir delivery: write under tmp/ then rename into new/ so readers never see partial files.
+	name := fmt.Sprintf("%d.%s.paycore.eml", time.Now().UnixNano(), randomHex(6))
+	tmpPath := filepath.Join(m.dir, "tmp", name)
+	if err := os.WriteFile(tmpPath, raw, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, filepath.Join(m.dir, "new", name))
+}
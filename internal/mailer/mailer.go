@@ -0,0 +1,46 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/luponetn/paycore/internal/config"
+)
+
+// ErrPermanent marks delivery failures that will not succeed on retry, such as a rejected
+// recipient or a broken template. Anything else is assumed to be transient.
+var ErrPermanent = errors.New("permanent mail delivery failure")
+
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New builds the Mailer selected by cfg.MailDriver.
+func New(cfg *config.Config) (Mailer, error) {
+	switch cfg.MailDriver {
+	case "smtp":
+		return NewSMTPMailer(SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.MailFrom,
+		}), nil
+	case "file":
+		return NewFileMailer(cfg.MailSinkDir, cfg.MailFrom)
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", cfg.MailDriver)
+	}
+}
+
+func permanent(err error) error {
+	return fmt.Errorf("%w: %w", ErrPermanent, err)
+}
@@ -0,0 +1,64 @@
+package mailer
+
+import (
+	"context"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderTemplates(t *testing.T) {
+	cases := map[string]any{
+		TemplateOTP:           OTPData{Name: "Ada", Code: "123456", Action: "verify your email address", ExpiresInMinutes: 10},
+		TemplatePasswordReset: PasswordResetData{Name: "Ada", Code: "654321", ExpiresInMinutes: 15},
+		TemplateTransferReceipt: TransferReceiptData{
+			Name: "Ada", Direction: "debit", Amount: "20.00", Currency: "NGN",
+			Counterparty: "1234567890", TransactionID: "tx-1", OccurredAt: time.Now(),
+		},
+		TemplateSecurityAlert: SecurityAlertData{Name: "Ada", Event: "Your password was changed", IPAddress: "10.0.0.1", OccurredAt: time.Now()},
+	}
+
+	for name, data := range cases {
+		msg, err := Render(name, "ada@example.com", data)
+		require.NoError(t, err, name)
+		require.Equal(t, subjects[name], msg.Subject)
+		require.Contains(t, msg.Text, "Ada")
+		require.Contains(t, msg.HTML, "<html>")
+	}
+
+	_, err := Render("missing", "ada@example.com", nil)
+	require.ErrorIs(t, err, ErrPermanent)
+}
+
+func TestFileMailerWritesMaildir(t *testing.T) {
+	dir := t.TempDir()
+	m, err := NewFileMailer(dir, "Paycore <no-reply@paycore.local>")
+	require.NoError(t, err)
+
+	msg, err := Render(TemplateOTP, "ada@example.com", OTPData{Name: "Ada", Code: "123456", Action: "verify your email address", ExpiresInMinutes: 10})
+	require.NoError(t, err)
+	require.NoError(t, m.Send(context.Background(), msg))
+
+	entries, err := os.ReadDir(filepath.Join(dir, "new"))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	raw, err := os.ReadFile(filepath.Join(dir, "new", entries[0].Name()))
+	require.NoError(t, err)
+	require.True(t, strings.Contains(string(raw), "To: ada@example.com"))
+	require.True(t, strings.Contains(string(raw), "multipart/alternative"))
+
+	// bad recipients are never worth retrying
+	err = m.Send(context.Background(), Message{To: "not-an-address", Subject: "x", Text: "x"})
+	require.ErrorIs(t, err, ErrPermanent)
+}
+
+func TestClassifySMTPError(t *testing.T) {
+	require.ErrorIs(t, classifySMTPError(&textproto.Error{Code: 550, Msg: "mailbox unavailable"}), ErrPermanent)
+	require.NotErrorIs(t, classifySMTPError(&textproto.Error{Code: 451, Msg: "try again later"}), ErrPermanent)
+}
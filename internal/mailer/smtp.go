@@ -0,0 +1,101 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+)
+
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+type SMTPMailer struct {
+	cfg SMTPConfig
+}
+
+func NewSMTPMailer(cfg SMTPConfig) *SMTPMailer {
+	return &SMTPMailer{cfg: cfg}
+}
+
+// Send delivers msg over SMTP, upgrading to TLS when the server offers STARTTLS.
+// 5xx replies are permanent; connection problems and 4xx replies are left retryable.
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	raw, err := buildMIME(m.cfg.From, msg)
+	if err != nil {
+		return err
+	}
+
+	from, err := mail.ParseAddress(m.cfg.From)
+	if err != nil {
+		return permanent(fmt.Errorf("invalid sender %q: %w", m.cfg.From, err))
+	}
+	to, _ := mail.ParseAddress(msg.To)
+
+	addr := net.JoinHostPort(m.cfg.Host, strconv.Itoa(m.cfg.Port))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, m.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: m.cfg.Host}); err != nil {
+			return err
+		}
+	}
+
+	if m.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)); err != nil {
+			return classifySMTPError(err)
+		}
+	}
+
+	if err := client.Mail(from.Address); err != nil {
+		return classifySMTPError(err)
+	}
+	if err := client.Rcpt(to.Address); err != nil {
+		return classifySMTPError(err)
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return classifySMTPError(err)
+	}
+	if _, err := w.Write(raw); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return classifySMTPError(err)
+	}
+
+	return client.Quit()
+}
+
+func classifySMTPError(err error) error {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) && protoErr.Code >= 500 {
+		return permanent(err)
+	}
+	return err
+}
@@ -0,0 +1,102 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+	"time"
+)
+
+//go:embed templates/*
+var templateFS embed.FS
+
+// Template names, one per message type.
+const (
+	TemplateOTP             = "otp"
+	TemplatePasswordReset   = "password_reset"
+	TemplateTransferReceipt = "transfer_receipt"
+	TemplateSecurityAlert   = "security_alert"
+)
+
+var subjects = map[string]string{
+	TemplateOTP:             "Your Paycore verification code",
+	TemplatePasswordReset:   "Reset your Paycore password",
+	TemplateTransferReceipt: "Your Paycore transfer receipt",
+	TemplateSecurityAlert:   "Security alert on your Paycore account",
+}
+
+type OTPData struct {
+	Name             string
+	Code             string
+	Action           string // completes "Use the code below to ..."
+	ExpiresInMinutes int
+}
+
+type PasswordResetData struct {
+	Name             string
+	Code             string
+	Link             string
+	ExpiresInMinutes int
+}
+
+type TransferReceiptData struct {
+	Name          string
+	Direction     string // "debit" or "credit"
+	Amount        string
+	Currency      string
+	Counterparty  string
+	Description   string
+	TransactionID string
+	OccurredAt    time.Time
+}
+
+type SecurityAlertData struct {
+	Name       string
+	Event      string
+	IPAddress  string
+	UserAgent  string
+	OccurredAt time.Time
+}
+
+type compiledTemplate struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+var templates = mustCompileTemplates()
+
+func mustCompileTemplates() map[string]compiledTemplate {
+	compiled := make(map[string]compiledTemplate, len(subjects))
+	for name := range subjects {
+		html := htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/layout.html", "templates/"+name+".html"))
+		text := texttemplate.Must(texttemplate.ParseFS(templateFS, "templates/"+name+".txt"))
+		compiled[name] = compiledTemplate{html: html, text: text}
+	}
+	return compiled
+}
+
+// Render builds a Message for the named template. Unknown templates and execution errors
+// are permanent: retrying cannot fix them.
+func Render(name string, to string, data any) (Message, error) {
+	tmpl, ok := templates[name]
+	if !ok {
+		return Message{}, permanent(fmt.Errorf("unknown template %q", name))
+	}
+
+	var html, text bytes.Buffer
+	if err := tmpl.html.ExecuteTemplate(&html, "layout", data); err != nil {
+		return Message{}, permanent(err)
+	}
+	if err := tmpl.text.Execute(&text, data); err != nil {
+		return Message{}, permanent(err)
+	}
+
+	return Message{
+		To:      to,
+		Subject: subjects[name],
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
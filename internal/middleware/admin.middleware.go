@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminKeyMiddleware admits requests carrying the operator key in X-Admin-Key. With no key
+// configured every request is refused, so operator endpoints stay closed by default.
+func AdminKeyMiddleware(key string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if key == "" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "admin api is disabled"})
+			c.Abort()
+			return
+		}
+
+		provided := c.GetHeader("X-Admin-Key")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(key)) != 1 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid admin key"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
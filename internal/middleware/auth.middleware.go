@@ -1,15 +1,25 @@
 package middleware
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/luponetn/paycore/pkg/utils"
 )
 
-func AuthMiddleware(secret string) gin.HandlerFunc {
+// SessionChecker tells whether a validly signed access token still speaks for a live session:
+// not signed out, not revoked after refresh token reuse, not issued before a password change.
+type SessionChecker interface {
+	IsSessionActive(ctx context.Context, claims *utils.MyClaims) (bool, error)
+}
+
+// AuthMiddleware admits requests with an unexpired access token whose session is still active.
+// Refresh, MFA challenge and password reset tokens are never accepted here.
+func AuthMiddleware(keys *utils.KeySet, sessions SessionChecker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -28,7 +38,7 @@ func AuthMiddleware(secret string) gin.HandlerFunc {
 
 		tokenString := parts[1]
 
-		claims, err := utils.VerifyToken(tokenString, secret)
+		claims, err := utils.VerifyTokenWithKeys(tokenString, keys)
 		if err != nil {
 			slog.Error("JWT verification failed", "error", err, "token_snippet", tokenString[:10]+"...")
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization token", "details": err.Error()})
@@ -36,8 +46,28 @@ func AuthMiddleware(secret string) gin.HandlerFunc {
 			return
 		}
 
+		if claims.TokenType != "access" || claims.SessionID == uuid.Nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization token"})
+			c.Abort()
+			return
+		}
+
+		active, err := sessions.IsSessionActive(c.Request.Context(), claims)
+		if err != nil {
+			slog.Error("failed to check session", "error", err, "session_id", claims.SessionID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "could not verify session"})
+			c.Abort()
+			return
+		}
+		if !active {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "session has ended, sign in again"})
+			c.Abort()
+			return
+		}
+
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
+		c.Set("session_id", claims.SessionID)
 		c.Next()
 	}
 }
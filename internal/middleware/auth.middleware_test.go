@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/luponetn/paycore/pkg/utils"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSessions treats every session as signed in except the revoked ones.
+type fakeSessions struct {
+	revoked map[uuid.UUID]bool
+}
+
+func (f fakeSessions) IsSessionActive(ctx context.Context, claims *utils.MyClaims) (bool, error) {
+	return !f.revoked[claims.SessionID], nil
+}
+
+func TestAuthMiddleware_OnlyAccessTokensOfLiveSessions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	keys := utils.NewHMACKeySet("test-secret")
+	userID, live, revoked := uuid.New(), uuid.New(), uuid.New()
+
+	r := gin.New()
+	r.GET("/me", AuthMiddleware(keys, fakeSessions{revoked: map[uuid.UUID]bool{revoked: true}}), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	status := func(tokenType string, sessionID uuid.UUID) int {
+		token, err := utils.GenerateSessionToken(userID, "ada", keys, tokenType, sessionID, uuid.New())
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	require.Equal(t, http.StatusOK, status("access", live))
+	require.Equal(t, http.StatusUnauthorized, status("access", revoked))
+	require.Equal(t, http.StatusUnauthorized, status("access", uuid.Nil))
+	require.Equal(t, http.StatusUnauthorized, status("refresh", live))
+	require.Equal(t, http.StatusUnauthorized, status("mfa", live))
+}
@@ -0,0 +1,104 @@
+// Package otp issues and checks the emailed one-time codes stored in the otps table. Codes are
+// scoped by purpose and, optionally, by a reference that ties a code to the one operation it
+// approves, such as a specific transfer.
+package otp
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/pkg/utils"
+)
+
+const (
+	TTL         = 10 * time.Minute
+	MaxAttempts = 5
+	ResendAfter = time.Minute
+)
+
+var (
+	ErrInvalid          = errors.New("invalid otp code")
+	ErrExpired          = errors.New("otp code has expired")
+	ErrAttemptsExceeded = errors.New("too many incorrect attempts, request a new code")
+)
+
+// Issue invalidates any outstanding code for the purpose and stores a new one. Only the
+// bcrypt hash is persisted; the plain code is returned so the caller can email it after commit.
+func Issue(ctx context.Context, qtx db.Querier, userID uuid.UUID, purpose string, reference string) (db.Otp, string, error) {
+	code, err := utils.GenerateOTP()
+	if err != nil {
+		return db.Otp{}, "", err
+	}
+
+	hashedCode, err := utils.HashPassword(code)
+	if err != nil {
+		return db.Otp{}, "", err
+	}
+
+	if err := qtx.InvalidateOTPs(ctx, db.InvalidateOTPsParams{UserID: userID, Purpose: purpose}); err != nil {
+		return db.Otp{}, "", err
+	}
+
+	otp, err := qtx.CreateOTP(ctx, db.CreateOTPParams{
+		UserID:    userID,
+		Code:      hashedCode,
+		Purpose:   purpose,
+		ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(TTL), Valid: true},
+		Used:      pgtype.Bool{Bool: false, Valid: true},
+		Reference: reference,
+	})
+	if err != nil {
+		return db.Otp{}, "", err
+	}
+	return otp, code, nil
+}
+
+// Consume checks code against the latest outstanding OTP for the purpose and marks it used
+// on success. The code must have been issued for the same reference. A wrong code still
+// counts an attempt, so the caller must commit even when an error is returned; after
+// MaxAttempts the code is burned and a new one must be requested.
+func Consume(ctx context.Context, qtx db.Querier, userID uuid.UUID, purpose string, reference string, code string) error {
+	otp, err := qtx.GetLatestOTPForUpdate(ctx, db.GetLatestOTPForUpdateParams{UserID: userID, Purpose: purpose})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrInvalid
+		}
+		return &utils.RetryableError{Err: err}
+	}
+
+	if otp.Reference != reference {
+		return ErrInvalid
+	}
+
+	if otp.ExpiresAt.Time.Before(time.Now()) {
+		return ErrExpired
+	}
+
+	if otp.Attempts >= MaxAttempts {
+		return ErrAttemptsExceeded
+	}
+
+	if err := utils.CheckPassword(code, otp.Code); err != nil {
+		updated, err := qtx.IncrementOTPAttempts(ctx, otp.ID)
+		if err != nil {
+			return &utils.RetryableError{Err: err}
+		}
+		if updated.Attempts >= MaxAttempts {
+			if err := qtx.MarkOTPUsed(ctx, otp.ID); err != nil {
+				return &utils.RetryableError{Err: err}
+			}
+			return ErrAttemptsExceeded
+		}
+		return ErrInvalid
+	}
+
+	if err := qtx.MarkOTPUsed(ctx, otp.ID); err != nil {
+		return &utils.RetryableError{Err: err}
+	}
+	return nil
+}
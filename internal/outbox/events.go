@@ -0,0 +1,100 @@
+package outbox
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/pkg/money"
+)
+
+// Aggregate types. Events about the same aggregate are published in the order they were recorded.
+const (
+	AggregateUser        = "user"
+	AggregateWallet      = "wallet"
+	AggregateTransaction = "transaction"
+	AggregatePayout      = "payout"
+)
+
+const (
+	EventUserRegistered       = "user.registered"
+	EventWalletCreated        = "wallet.created"
+	EventWalletBalanceChanged = "wallet.balance_changed"
+
+	EventPayoutInitiated  = "payout.initiated"
+	EventPayoutProcessing = "payout.processing"
+	EventPayoutSucceeded  = "payout.succeeded"
+	EventPayoutFailed     = "payout.failed"
+	EventPayoutReversed   = "payout.reversed"
+	EventPayoutReview     = "payout.review"
+)
+
+// TransactionCompleted names the event recorded when a transaction of txType posts, e.g.
+// transfer.completed, refund.completed or fx_conversion.completed.
+func TransactionCompleted(txType db.TransactionTypeEnum) string {
+	return string(txType) + ".completed"
+}
+
+type UserRegisteredPayload struct {
+	UserID       uuid.UUID `json:"user_id"`
+	Email        string    `json:"email"`
+	Username     string    `json:"username"`
+	Currency     string    `json:"currency"`
+	RegisteredAt time.Time `json:"registered_at"`
+}
+
+type WalletCreatedPayload struct {
+	WalletID   uuid.UUID         `json:"wallet_id"`
+	UserID     uuid.UUID         `json:"user_id"`
+	WalletType db.WalletTypeEnum `json:"wallet_type"`
+	Currency   string            `json:"currency"`
+	CreatedAt  time.Time         `json:"created_at"`
+}
+
+// TransactionLeg is one ledger posting of a completed transaction.
+type TransactionLeg struct {
+	WalletID  uuid.UUID          `json:"wallet_id"`
+	EntryType db.LedgerEntryType `json:"entry_type"`
+	Amount    money.Money        `json:"amount"`
+}
+
+type TransactionCompletedPayload struct {
+	TransactionID       uuid.UUID                `json:"transaction_id"`
+	TransactionType     db.TransactionTypeEnum   `json:"transaction_type"`
+	SenderWalletID      *uuid.UUID               `json:"sender_wallet_id,omitempty"`
+	ReceiverWalletID    *uuid.UUID               `json:"receiver_wallet_id,omitempty"`
+	ParentTransactionID *uuid.UUID               `json:"parent_transaction_id,omitempty"`
+	Amount              money.Money              `json:"amount"`
+	Fee                 money.Money              `json:"fee"`
+	Description         string                   `json:"description,omitempty"`
+	Status              db.TransactionStatusEnum `json:"status"`
+	Legs                []TransactionLeg         `json:"legs"`
+	CompletedAt         time.Time                `json:"completed_at"`
+}
+
+// WalletBalanceChangedPayload carries a customer wallet's balances after a transaction posted
+// to it. System wallets do not get one.
+type WalletBalanceChangedPayload struct {
+	WalletID         uuid.UUID   `json:"wallet_id"`
+	UserID           uuid.UUID   `json:"user_id"`
+	TransactionID    uuid.UUID   `json:"transaction_id"`
+	Balance          money.Money `json:"balance"`
+	AvailableBalance money.Money `json:"available_balance"`
+	ChangedAt        time.Time   `json:"changed_at"`
+}
+
+// PayoutPayload carries a payout after it moved to Status; every payout.* event uses it.
+type PayoutPayload struct {
+	PayoutID          uuid.UUID           `json:"payout_id"`
+	UserID            uuid.UUID           `json:"user_id"`
+	WalletID          uuid.UUID           `json:"wallet_id"`
+	BankCode          string              `json:"bank_code"`
+	AccountNumber     string              `json:"account_number"`
+	AccountName       string              `json:"account_name"`
+	Amount            money.Money         `json:"amount"`
+	Fee               money.Money         `json:"fee"`
+	Status            db.PayoutStatusEnum `json:"status"`
+	ProviderReference string              `json:"provider_reference,omitempty"`
+	FailureReason     string              `json:"failure_reason,omitempty"`
+	ChangedAt         time.Time           `json:"changed_at"`
+}
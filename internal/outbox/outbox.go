@@ -0,0 +1,94 @@
+// Package outbox records domain events in the same database transaction as the change they
+// describe and relays them to sinks afterwards, so an event is published if and only if its
+// change committed. Delivery is at least once: consumers deduplicate on the event id.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/pkg/money"
+)
+
+// Event is what sinks receive: the payload with the fields consumers need to order and
+// deduplicate it.
+type Event struct {
+	ID            uuid.UUID       `json:"id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   uuid.UUID       `json:"aggregate_id"`
+	Sequence      int64           `json:"sequence"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// Record writes an event into the outbox through q, which must be the querier of the
+// transaction making the change.
+func Record(ctx context.Context, q db.Querier, aggregateType string, aggregateID uuid.UUID, eventType string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal %s payload: %w", eventType, err)
+	}
+
+	_, err = q.CreateOutboxEvent(ctx, db.CreateOutboxEventParams{
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		EventType:     eventType,
+		Payload:       data,
+	})
+	return err
+}
+
+// FromRow builds the envelope sinks and streams receive for a stored event.
+func FromRow(row db.OutboxEvent) Event {
+	return Event{
+		ID:            row.ID,
+		Type:          row.EventType,
+		AggregateType: row.AggregateType,
+		AggregateID:   row.AggregateID,
+		Sequence:      row.Sequence,
+		OccurredAt:    row.CreatedAt.Time,
+		Data:          row.Payload,
+	}
+}
+
+// RecordWalletCreated records wallet.created for a wallet just inserted through q.
+func RecordWalletCreated(ctx context.Context, q db.Querier, w db.Wallet) error {
+	return Record(ctx, q, AggregateWallet, w.ID, EventWalletCreated, WalletCreatedPayload{
+		WalletID:   w.ID,
+		UserID:     w.UserID.Bytes,
+		WalletType: w.WalletType,
+		Currency:   w.Currency,
+		CreatedAt:  w.CreatedAt.Time,
+	})
+}
+
+// RecordPayout records the payout.* event for the status a payout just moved to through q.
+func RecordPayout(ctx context.Context, q db.Querier, p db.Payout) error {
+	amount, err := money.FromNumeric(p.Amount, p.Currency)
+	if err != nil {
+		return err
+	}
+	fee, err := money.FromNumeric(p.Fee, p.Currency)
+	if err != nil {
+		return err
+	}
+	return Record(ctx, q, AggregatePayout, p.ID, "payout."+string(p.Status), PayoutPayload{
+		PayoutID:          p.ID,
+		UserID:            p.UserID,
+		WalletID:          p.WalletID,
+		BankCode:          p.BankCode,
+		AccountNumber:     p.AccountNumber,
+		AccountName:       p.AccountName,
+		Amount:            amount,
+		Fee:               fee,
+		Status:            p.Status,
+		ProviderReference: p.ProviderReference.String,
+		FailureReason:     p.FailureReason.String,
+		ChangedAt:         p.UpdatedAt.Time,
+	})
+}
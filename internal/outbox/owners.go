@@ -0,0 +1,52 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/luponetn/paycore/internal/db"
+)
+
+// Owners lists the users an event concerns: the user it names, or the owners of the
+// customer wallets on its legs. System wallets have no owner.
+func Owners(ctx context.Context, q db.Querier, event Event) ([]uuid.UUID, error) {
+	var data struct {
+		UserID *uuid.UUID `json:"user_id"`
+		Legs   []struct {
+			WalletID uuid.UUID `json:"wallet_id"`
+		} `json:"legs"`
+	}
+	if err := json.Unmarshal(event.Data, &data); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[uuid.UUID]bool)
+	var owners []uuid.UUID
+	if data.UserID != nil {
+		seen[*data.UserID] = true
+		owners = append(owners, *data.UserID)
+	}
+
+	checked := make(map[uuid.UUID]bool)
+	for _, leg := range data.Legs {
+		if checked[leg.WalletID] {
+			continue
+		}
+		checked[leg.WalletID] = true
+
+		wallet, err := q.GetWalletById(ctx, leg.WalletID)
+		if err != nil {
+			return nil, err
+		}
+		if wallet.WalletType == db.WalletTypeEnumSystem || !wallet.UserID.Valid {
+			continue
+		}
+		owner := uuid.UUID(wallet.UserID.Bytes)
+		if !seen[owner] {
+			seen[owner] = true
+			owners = append(owners, owner)
+		}
+	}
+	return owners, nil
+}
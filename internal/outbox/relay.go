@@ -0,0 +1,116 @@
+package outbox
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/store"
+)
+
+// maxBackoff caps the wait before a failing event is tried again.
+const maxBackoff = 5 * time.Minute
+
+// relayTimeout bounds one RelayBatch, and claimTTL how long its claims keep other relays away.
+// A claim outlasts the batch, so it only runs out if the relay that made it has gone.
+const (
+	relayTimeout = 30 * time.Second
+	claimTTL     = 2 * relayTimeout
+)
+
+// Sink is somewhere events are published to. Delivery to each sink is at least once: the relay
+// records which sinks accepted an event and retries only the others, but if it stops after a
+// sink accepts and before that is recorded, the event goes to that sink again. Publish must
+// therefore be safe to repeat for the same event.
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, event Event) error
+}
+
+type Relay struct {
+	store store.Store
+	sinks []Sink
+}
+
+// NewRelay builds a relay that publishes every outbox event to each of sinks.
+func NewRelay(store store.Store, sinks ...Sink) *Relay {
+	return &Relay{store: store, sinks: sinks}
+}
+
+// RelayBatch publishes up to limit pending events and returns how many were published. Only
+// the oldest pending event of each aggregate is picked, so an aggregate's events go out in the
+// order they were recorded and a failing event holds back the ones after it. Failed events are
+// retried with exponential backoff. Events are claimed up front rather than locked, so no
+// transaction is open while sinks are called and concurrent relays work on different events.
+func (r *Relay) RelayBatch(ctx context.Context, limit int32) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, relayTimeout)
+	defer cancel()
+
+	rows, err := r.store.Queries().ClaimRelayableOutboxEvents(ctx, db.ClaimRelayableOutboxEventsParams{
+		ClaimedUntil: pgtype.Timestamptz{Time: time.Now().Add(claimTTL), Valid: true},
+		BatchSize:    limit,
+	})
+	if err != nil {
+		return 0, err
+	}
+	// the claim's RETURNING does not keep sequence order
+	slices.SortFunc(rows, func(a, b db.OutboxEvent) int {
+		return cmp.Compare(a.Sequence, b.Sequence)
+	})
+
+	published := 0
+	for _, row := range rows {
+		event := FromRow(row)
+		accepted, err := r.publish(ctx, event, row.PublishedSinks)
+		if err != nil {
+			slog.Warn("failed to publish outbox event", "event_id", event.ID, "type", event.Type, "attempts", row.Attempts+1, "error", err)
+			if err := r.store.Queries().MarkOutboxEventFailed(ctx, db.MarkOutboxEventFailedParams{
+				ID:             row.ID,
+				LastError:      pgtype.Text{String: err.Error(), Valid: true},
+				NextAttemptAt:  pgtype.Timestamptz{Time: time.Now().Add(backoff(row.Attempts)), Valid: true},
+				PublishedSinks: accepted,
+			}); err != nil {
+				return published, err
+			}
+			continue
+		}
+
+		if err := r.store.Queries().MarkOutboxEventPublished(ctx, row.ID); err != nil {
+			return published, err
+		}
+		published++
+	}
+	return published, nil
+}
+
+// publish hands the event to every sink not in accepted and returns the names of the sinks
+// that now have it. A failing sink does not stop the others.
+func (r *Relay) publish(ctx context.Context, event Event, accepted []string) ([]string, error) {
+	accepted = append(make([]string, 0, len(r.sinks)), accepted...)
+	var errs []error
+	for _, sink := range r.sinks {
+		if slices.Contains(accepted, sink.Name()) {
+			continue
+		}
+		if err := sink.Publish(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sink.Name(), err))
+			continue
+		}
+		accepted = append(accepted, sink.Name())
+	}
+	return accepted, errors.Join(errs...)
+}
+
+// backoff is how long to wait after an event's attempts-th failure: 1s, 2s, 4s... up to maxBackoff.
+func backoff(attempts int32) time.Duration {
+	if attempts >= 9 {
+		return maxBackoff
+	}
+	return min(time.Second<<attempts, maxBackoff)
+}
@@ -0,0 +1,184 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSink struct {
+	name      string
+	fail      map[uuid.UUID]bool
+	published []string
+	onPublish func()
+}
+
+func (s *fakeSink) Name() string {
+	if s.name == "" {
+		return "fake"
+	}
+	return s.name
+}
+
+func (s *fakeSink) Publish(ctx context.Context, event Event) error {
+	if s.onPublish != nil {
+		s.onPublish()
+	}
+	if s.fail[event.ID] {
+		delete(s.fail, event.ID)
+		return errors.New("sink unavailable")
+	}
+	s.published = append(s.published, event.Type)
+	return nil
+}
+
+type fakeEnqueuer struct {
+	tasks map[string]*asynq.Task
+}
+
+func (e *fakeEnqueuer) EnqueueContext(ctx context.Context, task *asynq.Task, opts ...asynq.Option) (*asynq.TaskInfo, error) {
+	var id string
+	for _, opt := range opts {
+		if opt.Type() == asynq.TaskIDOpt {
+			id = opt.Value().(string)
+		}
+	}
+	if _, ok := e.tasks[id]; ok {
+		return nil, asynq.ErrTaskIDConflict
+	}
+	e.tasks[id] = task
+	return &asynq.TaskInfo{ID: id}, nil
+}
+
+func TestRelayBatch_KeepsAggregateOrderAcrossFailures(t *testing.T) {
+	f := store.NewFakeStore()
+	ctx := context.Background()
+
+	first, second := uuid.New(), uuid.New()
+	require.NoError(t, Record(ctx, f, AggregateWallet, first, "a.1", nil))
+	require.NoError(t, Record(ctx, f, AggregateWallet, first, "a.2", nil))
+	require.NoError(t, Record(ctx, f, AggregateWallet, second, "b.1", nil))
+
+	head := f.OutboxEvents()[0]
+	sink := &fakeSink{fail: map[uuid.UUID]bool{head.ID: true}}
+	relay := NewRelay(f, sink)
+
+	// a.1 fails, which holds a.2 back; b.1 is unaffected.
+	published, err := relay.RelayBatch(ctx, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, published)
+	require.Equal(t, []string{"b.1"}, sink.published)
+
+	failed := f.OutboxEvents()[0]
+	require.EqualValues(t, 1, failed.Attempts)
+	require.Equal(t, "fake: sink unavailable", failed.LastError.String)
+	require.True(t, failed.NextAttemptAt.Time.After(time.Now()))
+
+	// Nothing is due until the backoff passes.
+	published, err = relay.RelayBatch(ctx, 10)
+	require.NoError(t, err)
+	require.Zero(t, published)
+
+	require.NoError(t, f.MarkOutboxEventFailed(ctx, db.MarkOutboxEventFailedParams{
+		ID:            head.ID,
+		LastError:     failed.LastError,
+		NextAttemptAt: pgtype.Timestamptz{Time: time.Now().Add(-time.Second), Valid: true},
+	}))
+
+	for range 2 {
+		_, err = relay.RelayBatch(ctx, 10)
+		require.NoError(t, err)
+	}
+	require.Equal(t, []string{"b.1", "a.1", "a.2"}, sink.published)
+
+	for _, event := range f.OutboxEvents() {
+		require.True(t, event.PublishedAt.Valid)
+	}
+}
+
+func TestRelayBatch_RetriesOnlySinksThatFailed(t *testing.T) {
+	f := store.NewFakeStore()
+	ctx := context.Background()
+
+	require.NoError(t, Record(ctx, f, AggregateWallet, uuid.New(), "a.1", nil))
+	event := f.OutboxEvents()[0]
+
+	healthy := &fakeSink{name: "healthy"}
+	flaky := &fakeSink{name: "flaky", fail: map[uuid.UUID]bool{event.ID: true}}
+	relay := NewRelay(f, flaky, healthy)
+
+	// The flaky sink failing first does not keep the event from the healthy one.
+	published, err := relay.RelayBatch(ctx, 10)
+	require.NoError(t, err)
+	require.Zero(t, published)
+	require.Empty(t, flaky.published)
+	require.Equal(t, []string{"a.1"}, healthy.published)
+
+	failed := f.OutboxEvents()[0]
+	require.Equal(t, []string{"healthy"}, failed.PublishedSinks)
+	require.Equal(t, "flaky: sink unavailable", failed.LastError.String)
+
+	require.NoError(t, f.MarkOutboxEventFailed(ctx, db.MarkOutboxEventFailedParams{
+		ID:             event.ID,
+		LastError:      failed.LastError,
+		NextAttemptAt:  pgtype.Timestamptz{Time: time.Now().Add(-time.Second), Valid: true},
+		PublishedSinks: failed.PublishedSinks,
+	}))
+
+	published, err = relay.RelayBatch(ctx, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, published)
+	require.Equal(t, []string{"a.1"}, flaky.published)
+	require.Equal(t, []string{"a.1"}, healthy.published)
+	require.True(t, f.OutboxEvents()[0].PublishedAt.Valid)
+}
+
+func TestRelayBatch_ClaimsEventsBeforePublishing(t *testing.T) {
+	f := store.NewFakeStore()
+	ctx := context.Background()
+
+	require.NoError(t, Record(ctx, f, AggregateWallet, uuid.New(), "a.1", nil))
+
+	// While the first relay is calling its sink, a second one finds nothing to do.
+	other := NewRelay(f, &fakeSink{})
+	var concurrent int
+	sink := &fakeSink{onPublish: func() {
+		n, err := other.RelayBatch(ctx, 10)
+		require.NoError(t, err)
+		concurrent += n
+	}}
+
+	published, err := NewRelay(f, sink).RelayBatch(ctx, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, published)
+	require.Zero(t, concurrent)
+	require.Equal(t, []string{"a.1"}, sink.published)
+}
+
+func TestAsynqSink_EnqueuesEachEventOnce(t *testing.T) {
+	enqueuer := &fakeEnqueuer{tasks: map[string]*asynq.Task{}}
+	sink := NewAsynqSink(enqueuer, "default")
+	ctx := context.Background()
+
+	event := Event{ID: uuid.New(), Type: EventWalletCreated, AggregateType: AggregateWallet, AggregateID: uuid.New()}
+	require.NoError(t, sink.Publish(ctx, event))
+	require.NoError(t, sink.Publish(ctx, event))
+
+	require.Len(t, enqueuer.tasks, 1)
+	require.Equal(t, "event:wallet.created", enqueuer.tasks[event.ID.String()].Type())
+}
+
+func TestBackoff_DoublesUpToCap(t *testing.T) {
+	require.Equal(t, time.Second, backoff(0))
+	require.Equal(t, 8*time.Second, backoff(3))
+	require.Equal(t, maxBackoff, backoff(9))
+	require.Equal(t, maxBackoff, backoff(40))
+}
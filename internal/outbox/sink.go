@@ -0,0 +1,49 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/luponetn/paycore/internal/tasks"
+)
+
+// TaskEnqueuer is the part of *asynq.Client the asynq sink needs.
+type TaskEnqueuer interface {
+	EnqueueContext(ctx context.Context, task *asynq.Task, opts ...asynq.Option) (*asynq.TaskInfo, error)
+}
+
+// AsynqSink hands each event to the worker as a task of type "event:<event type>". The event
+// id is the task id, so an event relayed twice while its task is still retained is enqueued once.
+type AsynqSink struct {
+	client TaskEnqueuer
+	queue  string
+}
+
+func NewAsynqSink(client TaskEnqueuer, queue string) *AsynqSink {
+	return &AsynqSink{client: client, queue: queue}
+}
+
+func (s *AsynqSink) Name() string {
+	return "asynq"
+}
+
+func (s *AsynqSink) Publish(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	task := asynq.NewTask(tasks.TypeDomainEventPrefix+event.Type, payload)
+	_, err = s.client.EnqueueContext(ctx, task,
+		asynq.TaskID(event.ID.String()),
+		asynq.Queue(s.queue),
+		asynq.Retention(24*time.Hour),
+	)
+	if errors.Is(err, asynq.ErrTaskIDConflict) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,15 @@
+package payout
+
+import "errors"
+
+var (
+	ErrUnknownBank          = errors.New("bank is not supported for payouts")
+	ErrInvalidAccountNumber = errors.New("account number must be 10 digits")
+	ErrAccountNotFound      = errors.New("account number could not be resolved at the bank")
+	ErrDestinationNotFound  = errors.New("bank destination not found")
+	ErrDestinationExists    = errors.New("bank destination is already saved")
+	ErrPayoutNotFound       = errors.New("payout not found")
+	ErrWalletNotEligible    = errors.New("payouts cannot be made from fixed wallets")
+	ErrCurrencyMismatch     = errors.New("destination currency does not match the wallet")
+	ErrPayoutRejected       = errors.New("provider rejected the payout")
+)
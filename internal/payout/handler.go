@@ -0,0 +1,250 @@
+package payout
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/luponetn/paycore/internal/transfer"
+)
+
+type Handler struct {
+	svc Service
+}
+
+func NewHandler(svc Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+func (h *Handler) HandleListBanks(c *gin.Context) {
+	banks, err := h.svc.Banks(c.Request.Context())
+	if err != nil {
+		c.AbortWithStatusJSON(payoutErrorStatus(err), gin.H{
+			"message": "failed to fetch banks",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "banks fetched successfully",
+		"data":    banks,
+	})
+}
+
+func (h *Handler) HandleResolveAccount(c *gin.Context) {
+	var req ResolveAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	details, err := h.svc.ResolveAccount(c.Request.Context(), req)
+	if err != nil {
+		c.AbortWithStatusJSON(payoutErrorStatus(err), gin.H{
+			"message": "failed to resolve account",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "account resolved successfully",
+		"data":    details,
+	})
+}
+
+func (h *Handler) HandleCreateDestination(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	var req CreateDestinationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	destination, err := h.svc.CreateDestination(c.Request.Context(), userID, req)
+	if err != nil {
+		c.AbortWithStatusJSON(payoutErrorStatus(err), gin.H{
+			"message": "failed to save bank destination",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "bank destination saved successfully",
+		"data":    destination,
+	})
+}
+
+func (h *Handler) HandleListDestinations(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	destinations, err := h.svc.ListDestinations(c.Request.Context(), userID)
+	if err != nil {
+		c.AbortWithStatusJSON(payoutErrorStatus(err), gin.H{
+			"message": "failed to fetch bank destinations",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "bank destinations fetched successfully",
+		"data":    destinations,
+	})
+}
+
+func (h *Handler) HandleDeleteDestination(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	destinationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid destination id"})
+		return
+	}
+
+	if err := h.svc.DeleteDestination(c.Request.Context(), userID, destinationID); err != nil {
+		c.AbortWithStatusJSON(payoutErrorStatus(err), gin.H{
+			"message": "failed to delete bank destination",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "bank destination deleted successfully",
+	})
+}
+
+func (h *Handler) HandleCreatePayout(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	var req CreatePayoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	payout, err := h.svc.CreatePayout(c.Request.Context(), userID, req)
+	if err != nil {
+		c.AbortWithStatusJSON(payoutErrorStatus(err), gin.H{
+			"message": "failed to create payout",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "payout initiated, funds are held until the bank confirms it",
+		"data":    payout,
+	})
+}
+
+func (h *Handler) HandleListPayouts(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	var query ListPayoutsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid query parameters", "details": err.Error()})
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = 20
+	}
+
+	payouts, err := h.svc.ListPayouts(c.Request.Context(), userID, query.Limit)
+	if err != nil {
+		c.AbortWithStatusJSON(payoutErrorStatus(err), gin.H{
+			"message": "failed to fetch payouts",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "payouts fetched successfully",
+		"data":    payouts,
+	})
+}
+
+func (h *Handler) HandleGetPayout(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	payoutID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid payout id"})
+		return
+	}
+
+	payout, err := h.svc.GetPayout(c.Request.Context(), userID, payoutID)
+	if err != nil {
+		c.AbortWithStatusJSON(payoutErrorStatus(err), gin.H{
+			"message": "failed to fetch payout",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "payout fetched successfully",
+		"data":    payout,
+	})
+}
+
+func authUserID(c *gin.Context) (uuid.UUID, bool) {
+	userIdVal, exists := c.Get("user_id")
+	if !exists {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return uuid.Nil, false
+	}
+	userID, ok := userIdVal.(uuid.UUID)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "invalid user id type"})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+func payoutErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrInvalidAccountNumber), transfer.IsInvalidAmount(err):
+		return http.StatusBadRequest
+	case errors.Is(err, transfer.ErrInvalidPIN):
+		return http.StatusUnauthorized
+	case errors.Is(err, transfer.ErrUnauthorizedWallet), errors.Is(err, transfer.ErrEmailNotVerified),
+		errors.Is(err, transfer.ErrPINNotSet):
+		return http.StatusForbidden
+	case errors.Is(err, transfer.ErrWalletNotFound), errors.Is(err, ErrDestinationNotFound),
+		errors.Is(err, ErrPayoutNotFound), errors.Is(err, ErrUnknownBank):
+		return http.StatusNotFound
+	case errors.Is(err, ErrDestinationExists), errors.Is(err, transfer.ErrInsufficientFunds):
+		return http.StatusConflict
+	case errors.Is(err, ErrAccountNotFound), errors.Is(err, ErrWalletNotEligible), errors.Is(err, ErrCurrencyMismatch):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, transfer.ErrPINLocked):
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
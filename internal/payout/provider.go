@@ -0,0 +1,62 @@
+package payout
+
+import (
+	"context"
+
+	"github.com/luponetn/paycore/pkg/money"
+)
+
+// Provider is a payout partner: it resolves bank accounts and sends money to them.
+type Provider interface {
+	Name() string
+	// Banks lists the banks the provider can pay out to.
+	Banks(ctx context.Context) ([]Bank, error)
+	// NameEnquiry looks up the holder of an account; it returns ErrUnknownBank,
+	// ErrInvalidAccountNumber or ErrAccountNotFound for an account that cannot be paid.
+	NameEnquiry(ctx context.Context, bankCode string, accountNumber string) (AccountDetails, error)
+	// Submit hands a payout to the provider and returns its reference for it. An error wrapping
+	// ErrPayoutRejected means the payout was turned down and no money left; any other error
+	// leaves it unknown, and the same Reference is submitted again later. Providers must treat a
+	// repeated Reference as the payout they already have.
+	Submit(ctx context.Context, req PayoutRequest) (string, error)
+	// Status reports where an accepted payout stands.
+	Status(ctx context.Context, providerReference string) (StatusResult, error)
+}
+
+type Bank struct {
+	Code     string `json:"code"`
+	Name     string `json:"name"`
+	Currency string `json:"currency"`
+}
+
+type AccountDetails struct {
+	BankCode      string `json:"bank_code"`
+	BankName      string `json:"bank_name"`
+	AccountNumber string `json:"account_number"`
+	AccountName   string `json:"account_name"`
+}
+
+type PayoutRequest struct {
+	// Reference is ours, the payout id; it identifies the payout across submissions.
+	Reference     string
+	BankCode      string
+	AccountNumber string
+	AccountName   string
+	Amount        money.Money
+	Narration     string
+}
+
+// ProviderStatus is where an accepted payout stands at the provider. A payout is pending until
+// the receiving bank confirms or refuses it; both outcomes are final.
+type ProviderStatus string
+
+const (
+	ProviderPending   ProviderStatus = "pending"
+	ProviderSucceeded ProviderStatus = "succeeded"
+	ProviderFailed    ProviderStatus = "failed"
+)
+
+type StatusResult struct {
+	Status ProviderStatus
+	Reason string
+}
@@ -0,0 +1,26 @@
+package payout
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/luponetn/paycore/internal/middleware"
+	"github.com/luponetn/paycore/pkg/utils"
+)
+
+func RegisterRoutes(r *gin.Engine, h *Handler, keys *utils.KeySet, sessions middleware.SessionChecker) {
+	payoutGroup := r.Group("/payouts")
+
+	//use middlewares
+	payoutGroup.Use(middleware.AuthMiddleware(keys, sessions))
+
+	//implement routes
+	{
+		payoutGroup.GET("/banks", h.HandleListBanks)
+		payoutGroup.POST("/resolve-account", h.HandleResolveAccount)
+		payoutGroup.POST("/destinations", h.HandleCreateDestination)
+		payoutGroup.GET("/destinations", h.HandleListDestinations)
+		payoutGroup.DELETE("/destinations/:id", h.HandleDeleteDestination)
+		payoutGroup.POST("", h.HandleCreatePayout)
+		payoutGroup.GET("", h.HandleListPayouts)
+		payoutGroup.GET("/:id", h.HandleGetPayout)
+	}
+}
@@ -0,0 +1,834 @@
+package payout
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/luponetn/paycore/internal/accounts"
+	"github.com/luponetn/paycore/internal/config"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/fees"
+	"github.com/luponetn/paycore/internal/ledger"
+	"github.com/luponetn/paycore/internal/outbox"
+	"github.com/luponetn/paycore/internal/store"
+	"github.com/luponetn/paycore/internal/transfer"
+	"github.com/luponetn/paycore/pkg/money"
+	"github.com/luponetn/paycore/pkg/utils"
+)
+
+type Service interface {
+	Banks(ctx context.Context) ([]Bank, error)
+	ResolveAccount(ctx context.Context, req ResolveAccountRequest) (AccountDetails, error)
+	CreateDestination(ctx context.Context, userID uuid.UUID, req CreateDestinationRequest) (db.BankDestination, error)
+	ListDestinations(ctx context.Context, userID uuid.UUID) ([]db.BankDestination, error)
+	DeleteDestination(ctx context.Context, userID uuid.UUID, destinationID uuid.UUID) error
+	CreatePayout(ctx context.Context, userID uuid.UUID, req CreatePayoutRequest) (db.Payout, error)
+	ListPayouts(ctx context.Context, userID uuid.UUID, limit int32) ([]db.Payout, error)
+	GetPayout(ctx context.Context, userID uuid.UUID, payoutID uuid.UUID) (db.Payout, error)
+	DuePayoutIDs(ctx context.Context, limit int32) ([]uuid.UUID, error)
+	ProcessPayout(ctx context.Context, payoutID uuid.UUID) error
+}
+
+type Svc struct {
+	store       store.Store
+	provider    Provider
+	transferSvc transfer.Service
+	cfg         *config.Config
+}
+
+// NewService wires outbound payouts through provider; transferSvc checks the transaction PIN
+// and cfg carries the provider polling interval.
+func NewService(store store.Store, provider Provider, transferSvc transfer.Service, cfg *config.Config) Service {
+	return &Svc{store: store, provider: provider, transferSvc: transferSvc, cfg: cfg}
+}
+
+const (
+	// payoutHoldTTL only fills in the hold's expiry: the hold expiry job skips payout holds,
+	// which this service always captures or releases itself.
+	payoutHoldTTL = 30 * 24 * time.Hour
+	// maxCheckInterval caps the backoff between status checks on a payout the provider sits on.
+	maxCheckInterval = 30 * time.Minute
+)
+
+func (s *Svc) Banks(ctx context.Context) ([]Bank, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	return s.provider.Banks(ctx)
+}
+
+// ResolveAccount asks the provider who holds an account, so the user can confirm the name
+// before saving it.
+func (s *Svc) ResolveAccount(ctx context.Context, req ResolveAccountRequest) (AccountDetails, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	return s.provider.NameEnquiry(ctx, req.BankCode, req.AccountNumber)
+}
+
+// CreateDestination saves a bank account the user can pay out to, under the name the bank
+// returned for it.
+func (s *Svc) CreateDestination(ctx context.Context, userID uuid.UUID, req CreateDestinationRequest) (db.BankDestination, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	bank, err := s.bank(ctx, req.BankCode)
+	if err != nil {
+		return db.BankDestination{}, err
+	}
+
+	details, err := s.provider.NameEnquiry(ctx, req.BankCode, req.AccountNumber)
+	if err != nil {
+		return db.BankDestination{}, err
+	}
+
+	destination, err := s.store.Queries().CreateBankDestination(ctx, db.CreateBankDestinationParams{
+		UserID:        userID,
+		BankCode:      bank.Code,
+		BankName:      bank.Name,
+		AccountNumber: details.AccountNumber,
+		AccountName:   details.AccountName,
+		Currency:      bank.Currency,
+		Nickname:      pgtype.Text{String: req.Nickname, Valid: req.Nickname != ""},
+	})
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return db.BankDestination{}, ErrDestinationExists
+	}
+	return destination, err
+}
+
+func (s *Svc) ListDestinations(ctx context.Context, userID uuid.UUID) ([]db.BankDestination, error) {
+	ctx, cancel := context.WithTimeout(ctx, 8*time.Second)
+	defer cancel()
+
+	destinations, err := s.store.Queries().GetBankDestinationsByUserId(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if destinations == nil {
+		destinations = []db.BankDestination{}
+	}
+	return destinations, nil
+}
+
+// DeleteDestination forgets a saved bank account. Payouts already made to it keep their copy
+// of the bank details.
+func (s *Svc) DeleteDestination(ctx context.Context, userID uuid.UUID, destinationID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, 8*time.Second)
+	defer cancel()
+
+	if _, err := s.destination(ctx, userID, destinationID); err != nil {
+		return err
+	}
+	return s.store.Queries().DeleteBankDestination(ctx, destinationID)
+}
+
+// CreatePayout starts a payout to one of the user's saved bank accounts. The amount plus the
+// withdrawal fee is held on the wallet straight away; the worker then hands the payout to the
+// provider. Replaying the request returns the payout it started.
+func (s *Svc) CreatePayout(ctx context.Context, userID uuid.UUID, req CreatePayoutRequest) (db.Payout, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	walletID, err := uuid.Parse(req.WalletID)
+	if err != nil {
+		return db.Payout{}, transfer.ErrWalletNotFound
+	}
+	destinationID, err := uuid.Parse(req.DestinationID)
+	if err != nil {
+		return db.Payout{}, ErrDestinationNotFound
+	}
+
+	user, err := s.store.Queries().GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.Payout{}, transfer.ErrUnauthorizedWallet
+		}
+		return db.Payout{}, err
+	}
+	if !user.EmailVerifiedAt.Valid {
+		return db.Payout{}, transfer.ErrEmailNotVerified
+	}
+
+	if err := s.transferSvc.VerifyPIN(ctx, userID, req.PIN); err != nil {
+		return db.Payout{}, err
+	}
+
+	destination, err := s.destination(ctx, userID, destinationID)
+	if err != nil {
+		return db.Payout{}, err
+	}
+
+	amount, err := transfer.ParseAmount(req.Amount, destination.Currency)
+	if err != nil {
+		return db.Payout{}, err
+	}
+
+	// idempotency keys are the client's, so they are scoped to the user
+	key := userID.String() + ":" + req.IdempotencyKey
+
+	return utils.Retry(3, 100, func() (db.Payout, error) {
+		tx, err := s.store.Begin(ctx)
+		if err != nil {
+			return db.Payout{}, &utils.RetryableError{Err: err}
+		}
+
+		defer func() {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback create payout tx", "error", rbErr)
+			}
+		}()
+
+		qtx := s.store.WithTx(tx)
+
+		wallets, err := qtx.GetWalletsForUpdate(ctx, []uuid.UUID{walletID})
+		if err != nil {
+			return db.Payout{}, &utils.RetryableError{Err: err}
+		}
+		if len(wallets) != 1 {
+			return db.Payout{}, transfer.ErrWalletNotFound
+		}
+		wallet := wallets[0]
+
+		if !wallet.UserID.Valid || uuid.UUID(wallet.UserID.Bytes) != userID {
+			return db.Payout{}, transfer.ErrUnauthorizedWallet
+		}
+		if wallet.WalletType == db.WalletTypeEnumFixed {
+			return db.Payout{}, ErrWalletNotEligible
+		}
+		if wallet.Currency != destination.Currency {
+			return db.Payout{}, ErrCurrencyMismatch
+		}
+
+		// The wallet lock serialises payouts from it, so this lookup is race free.
+		if existing, err := qtx.GetPayoutByIdempotencyKey(ctx, key); err == nil {
+			return existing, nil
+		} else if !errors.Is(err, pgx.ErrNoRows) {
+			return db.Payout{}, &utils.RetryableError{Err: err}
+		}
+
+		quote, err := fees.Quote(ctx, qtx, db.TransactionTypeEnumWithdrawal, wallet.Currency, user.KycTier, amount.Amount())
+		if err != nil {
+			return db.Payout{}, &utils.RetryableError{Err: err}
+		}
+		total, err := money.New(quote.Total, wallet.Currency)
+		if err != nil {
+			return db.Payout{}, err
+		}
+
+		available, err := money.FromNumeric(wallet.AvailableBalance, wallet.Currency)
+		if err != nil {
+			return db.Payout{}, err
+		}
+		remaining, err := available.Sub(total)
+		if err != nil {
+			return db.Payout{}, ErrCurrencyMismatch
+		}
+		if remaining.IsNegative() {
+			return db.Payout{}, transfer.ErrInsufficientFunds
+		}
+
+		suspenseID, err := accounts.SystemWallet(ctx, qtx, accounts.Suspense, wallet.Currency)
+		if err != nil {
+			return db.Payout{}, &utils.RetryableError{Err: err}
+		}
+
+		// the hold is payable to suspense, which no user owns, so only this service can settle it
+		hold, err := qtx.CreateWalletHold(ctx, db.CreateWalletHoldParams{
+			WalletID:         wallet.ID,
+			ReceiverWalletID: suspenseID,
+			Amount:           total.Numeric(),
+			Currency:         wallet.Currency,
+			Description:      pgtype.Text{String: "payout to " + destination.AccountName, Valid: true},
+			IdempotencyKey:   "payout:" + key,
+			ExpiresAt:        pgtype.Timestamptz{Time: time.Now().Add(payoutHoldTTL), Valid: true},
+		})
+		if err != nil {
+			return db.Payout{}, &utils.RetryableError{Err: err}
+		}
+
+		if err := qtx.UpdateWalletAvailableBalance(ctx, db.UpdateWalletAvailableBalanceParams{
+			AvailableBalance: remaining.Numeric(),
+			ID:               wallet.ID,
+		}); err != nil {
+			return db.Payout{}, &utils.RetryableError{Err: err}
+		}
+
+		var feeScheduleID pgtype.UUID
+		if quote.ScheduleID != nil {
+			feeScheduleID = utils.ToPgUUID(*quote.ScheduleID)
+		}
+
+		payout, err := qtx.CreatePayout(ctx, db.CreatePayoutParams{
+			UserID:         userID,
+			WalletID:       wallet.ID,
+			DestinationID:  utils.ToPgUUID(destination.ID),
+			BankCode:       destination.BankCode,
+			AccountNumber:  destination.AccountNumber,
+			AccountName:    destination.AccountName,
+			Amount:         amount.Numeric(),
+			Fee:            utils.DecimalToNumeric(quote.Fee),
+			FeeScheduleID:  feeScheduleID,
+			Currency:       wallet.Currency,
+			Narration:      pgtype.Text{String: req.Narration, Valid: req.Narration != ""},
+			HoldID:         hold.ID,
+			Provider:       s.provider.Name(),
+			IdempotencyKey: key,
+		})
+		if err != nil {
+			return db.Payout{}, &utils.RetryableError{Err: err}
+		}
+
+		if err := outbox.RecordPayout(ctx, qtx, payout); err != nil {
+			return db.Payout{}, &utils.RetryableError{Err: err}
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return db.Payout{}, &utils.RetryableError{Err: err}
+		}
+		return payout, nil
+	})
+}
+
+// ListPayouts lists the user's most recent payouts.
+func (s *Svc) ListPayouts(ctx context.Context, userID uuid.UUID, limit int32) ([]db.Payout, error) {
+	ctx, cancel := context.WithTimeout(ctx, 8*time.Second)
+	defer cancel()
+
+	payouts, err := s.store.Queries().GetPayoutsByUserId(ctx, db.GetPayoutsByUserIdParams{
+		UserID: userID,
+		Limit:  limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if payouts == nil {
+		payouts = []db.Payout{}
+	}
+	return payouts, nil
+}
+
+func (s *Svc) GetPayout(ctx context.Context, userID uuid.UUID, payoutID uuid.UUID) (db.Payout, error) {
+	ctx, cancel := context.WithTimeout(ctx, 8*time.Second)
+	defer cancel()
+
+	payout, err := s.store.Queries().GetPayoutById(ctx, payoutID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.Payout{}, ErrPayoutNotFound
+		}
+		return db.Payout{}, err
+	}
+	// someone else's payout reads as missing rather than forbidden
+	if payout.UserID != userID {
+		return db.Payout{}, ErrPayoutNotFound
+	}
+	return payout, nil
+}
+
+// DuePayoutIDs lists payouts waiting to be submitted, and submitted ones due a status check.
+func (s *Svc) DuePayoutIDs(ctx context.Context, limit int32) ([]uuid.UUID, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	return s.store.Queries().GetDuePayoutIds(ctx, db.GetDuePayoutIdsParams{
+		Now:       pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		BatchSize: limit,
+	})
+}
+
+// ProcessPayout moves a payout one step along: an initiated payout is submitted to the
+// provider, a processing one is checked on. Payouts that reached a final status are left alone.
+func (s *Svc) ProcessPayout(ctx context.Context, payoutID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	payout, err := s.store.Queries().GetPayoutById(ctx, payoutID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrPayoutNotFound
+		}
+		return err
+	}
+
+	switch payout.Status {
+	case db.PayoutStatusEnumInitiated:
+		return s.submit(ctx, payout)
+	case db.PayoutStatusEnumProcessing:
+		return s.check(ctx, payout)
+	default:
+		return nil
+	}
+}
+
+// submit hands an initiated payout to the provider. Once it is accepted the hold is captured
+// into the suspense account; if it is rejected the hold is released. An accepted payout that
+// cannot be captured goes to review rather than back to the provider.
+func (s *Svc) submit(ctx context.Context, payout db.Payout) error {
+	hold, err := s.store.Queries().GetWalletHoldById(ctx, payout.HoldID)
+	if err != nil {
+		return err
+	}
+	if hold.Status != db.HoldStatusEnumActive {
+		return s.fail(ctx, payout.ID, "payout hold was released before the payout was sent")
+	}
+
+	amount, err := money.FromNumeric(payout.Amount, payout.Currency)
+	if err != nil {
+		return err
+	}
+
+	reference, err := s.provider.Submit(ctx, PayoutRequest{
+		Reference:     payout.ID.String(),
+		BankCode:      payout.BankCode,
+		AccountNumber: payout.AccountNumber,
+		AccountName:   payout.AccountName,
+		Amount:        amount,
+		Narration:     payout.Narration.String,
+	})
+	if errors.Is(err, ErrPayoutRejected) {
+		return s.fail(ctx, payout.ID, err.Error())
+	}
+	if err != nil {
+		// the provider may or may not have it; the next run submits the same reference again
+		return err
+	}
+
+	err = s.capture(ctx, payout.ID, reference)
+	if err == nil || utils.IsRetryableError(err) || ctx.Err() != nil {
+		// a transient failure is picked up by the next run, which the provider answers with the same reference
+		return err
+	}
+
+	// The bank has the money on its way but the wallet cannot be debited for it. Retrying will not
+	// change that, so the payout is parked for someone to reconcile instead of being sent again.
+	slog.Error("payout accepted by the provider could not be captured", "payout_id", payout.ID, "provider_reference", reference, "error", err)
+	return s.review(ctx, payout.ID, reference, "accepted by the provider but the wallet could not be debited: "+err.Error())
+}
+
+// capture debits the wallet for an accepted payout: the amount goes to suspense until the
+// provider confirms it, the fee to fees revenue.
+func (s *Svc) capture(ctx context.Context, payoutID uuid.UUID, reference string) error {
+	_, err := utils.Retry(3, 100, func() (struct{}, error) {
+		tx, err := s.store.Begin(ctx)
+		if err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+
+		defer func() {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback capture payout tx", "error", rbErr)
+			}
+		}()
+
+		qtx := s.store.WithTx(tx)
+
+		payout, err := qtx.GetPayoutForUpdate(ctx, payoutID)
+		if err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+		if payout.Status != db.PayoutStatusEnumInitiated {
+			return struct{}{}, nil
+		}
+
+		hold, err := qtx.GetWalletHoldForUpdate(ctx, payout.HoldID)
+		if err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+
+		amount := utils.NumericToDecimal(payout.Amount)
+		fee := utils.NumericToDecimal(payout.Fee)
+		total := amount.Add(fee)
+
+		// The held total already left the available balance, so only the ledger balance moves.
+		// Payout holds do not expire, but should this one have been released anyway the money
+		// is back in the available balance and is debited from there like any other transfer.
+		debit := ledger.Debit(payout.WalletID, total, payout.Currency)
+		if hold.Status == db.HoldStatusEnumActive {
+			debit = debit.AsLocked()
+		}
+		postings := []ledger.Posting{
+			debit,
+			ledger.CreditAccount(accounts.Suspense, amount, payout.Currency),
+		}
+		if fee.IsPositive() {
+			postings = append(postings, ledger.CreditAccount(accounts.FeesRevenue, fee, payout.Currency))
+		}
+
+		var feeScheduleID uuid.UUID
+		if payout.FeeScheduleID.Valid {
+			feeScheduleID = payout.FeeScheduleID.Bytes
+		}
+
+		created, err := ledger.Post(ctx, qtx, ledger.Journal{
+			TransactionType: db.TransactionTypeEnumWithdrawal,
+			Description:     "payout to " + payout.AccountName,
+			IdempotencyKey:  "payout:" + payout.ID.String(),
+			SenderWalletID:  payout.WalletID,
+			Amount:          amount,
+			Currency:        payout.Currency,
+			Fee:             fee,
+			FeeScheduleID:   feeScheduleID,
+			Postings:        postings,
+		})
+		if err != nil {
+			return struct{}{}, err
+		}
+
+		if hold.Status == db.HoldStatusEnumActive {
+			if _, err := qtx.UpdateWalletHoldCapture(ctx, db.UpdateWalletHoldCaptureParams{
+				CapturedAmount: hold.Amount,
+				Status:         db.HoldStatusEnumCaptured,
+				ID:             hold.ID,
+			}); err != nil {
+				return struct{}{}, &utils.RetryableError{Err: err}
+			}
+		}
+
+		payout, err = qtx.MarkPayoutProcessing(ctx, db.MarkPayoutProcessingParams{
+			ID:                 payout.ID,
+			ProviderReference:  pgtype.Text{String: reference, Valid: true},
+			DebitTransactionID: utils.ToPgUUID(created.ID),
+			NextCheckAt:        pgtype.Timestamptz{Time: time.Now().Add(s.cfg.PayoutPollInterval), Valid: true},
+		})
+		if err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+
+		if err := outbox.RecordPayout(ctx, qtx, payout); err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+		return struct{}{}, nil
+	})
+	return err
+}
+
+// review parks an initiated payout the provider accepted but that could not be captured. It
+// keeps the provider reference and drops out of the processing queue until it is reconciled.
+func (s *Svc) review(ctx context.Context, payoutID uuid.UUID, reference string, reason string) error {
+	_, err := utils.Retry(3, 100, func() (struct{}, error) {
+		tx, err := s.store.Begin(ctx)
+		if err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+
+		defer func() {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback review payout tx", "error", rbErr)
+			}
+		}()
+
+		qtx := s.store.WithTx(tx)
+
+		payout, err := qtx.GetPayoutForUpdate(ctx, payoutID)
+		if err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+		if payout.Status != db.PayoutStatusEnumInitiated {
+			return struct{}{}, nil
+		}
+
+		payout, err = qtx.MarkPayoutForReview(ctx, db.MarkPayoutForReviewParams{
+			ID:                payout.ID,
+			ProviderReference: pgtype.Text{String: reference, Valid: true},
+			FailureReason:     pgtype.Text{String: reason, Valid: true},
+		})
+		if err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+
+		if err := outbox.RecordPayout(ctx, qtx, payout); err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+		return struct{}{}, nil
+	})
+	return err
+}
+
+// fail closes an initiated payout the provider never took, giving the held total back to the wallet.
+func (s *Svc) fail(ctx context.Context, payoutID uuid.UUID, reason string) error {
+	_, err := utils.Retry(3, 100, func() (struct{}, error) {
+		tx, err := s.store.Begin(ctx)
+		if err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+
+		defer func() {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback fail payout tx", "error", rbErr)
+			}
+		}()
+
+		qtx := s.store.WithTx(tx)
+
+		payout, err := qtx.GetPayoutForUpdate(ctx, payoutID)
+		if err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+		if payout.Status != db.PayoutStatusEnumInitiated {
+			return struct{}{}, nil
+		}
+
+		hold, err := qtx.GetWalletHoldForUpdate(ctx, payout.HoldID)
+		if err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+		if hold.Status == db.HoldStatusEnumActive {
+			if err := releaseHold(ctx, qtx, hold); err != nil {
+				return struct{}{}, err
+			}
+		}
+
+		payout, err = qtx.MarkPayoutFailed(ctx, db.MarkPayoutFailedParams{
+			ID:            payout.ID,
+			FailureReason: pgtype.Text{String: reason, Valid: true},
+		})
+		if err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+
+		if err := outbox.RecordPayout(ctx, qtx, payout); err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+		return struct{}{}, nil
+	})
+	return err
+}
+
+// check asks the provider about a processing payout and settles or reverses it once the
+// outcome is known. A payout still pending is checked again later, backing off each time.
+func (s *Svc) check(ctx context.Context, payout db.Payout) error {
+	result, err := s.provider.Status(ctx, payout.ProviderReference.String)
+	if err != nil {
+		return err
+	}
+
+	switch result.Status {
+	case ProviderSucceeded:
+		return s.settle(ctx, payout.ID)
+	case ProviderFailed:
+		return s.reverse(ctx, payout.ID, result.Reason)
+	default:
+		_, err := s.store.Queries().SchedulePayoutCheck(ctx, db.SchedulePayoutCheckParams{
+			ID:          payout.ID,
+			NextCheckAt: pgtype.Timestamptz{Time: time.Now().Add(checkBackoff(s.cfg.PayoutPollInterval, payout.Checks)), Valid: true},
+		})
+		return err
+	}
+}
+
+// settle clears a paid-out amount from suspense against the settlement account.
+func (s *Svc) settle(ctx context.Context, payoutID uuid.UUID) error {
+	_, err := utils.Retry(3, 100, func() (struct{}, error) {
+		tx, err := s.store.Begin(ctx)
+		if err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+
+		defer func() {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback settle payout tx", "error", rbErr)
+			}
+		}()
+
+		qtx := s.store.WithTx(tx)
+
+		payout, err := qtx.GetPayoutForUpdate(ctx, payoutID)
+		if err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+		if payout.Status != db.PayoutStatusEnumProcessing {
+			return struct{}{}, nil
+		}
+
+		amount := utils.NumericToDecimal(payout.Amount)
+		created, err := ledger.Post(ctx, qtx, ledger.Journal{
+			TransactionType:     db.TransactionTypeEnumWithdrawal,
+			Description:         "payout settlement",
+			IdempotencyKey:      "payout:" + payout.ID.String() + ":settle",
+			ParentTransactionID: payout.DebitTransactionID.Bytes,
+			Postings: []ledger.Posting{
+				ledger.DebitAccount(accounts.Suspense, amount, payout.Currency),
+				ledger.CreditAccount(accounts.Settlement, amount, payout.Currency),
+			},
+		})
+		if err != nil {
+			return struct{}{}, err
+		}
+
+		payout, err = qtx.MarkPayoutSucceeded(ctx, db.MarkPayoutSucceededParams{
+			ID:                      payout.ID,
+			SettlementTransactionID: utils.ToPgUUID(created.ID),
+		})
+		if err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+
+		if err := outbox.RecordPayout(ctx, qtx, payout); err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+		return struct{}{}, nil
+	})
+	return err
+}
+
+// reverse refunds a payout the provider failed after the wallet was debited: the amount comes
+// back out of suspense and the fee out of fees revenue.
+func (s *Svc) reverse(ctx context.Context, payoutID uuid.UUID, reason string) error {
+	_, err := utils.Retry(3, 100, func() (struct{}, error) {
+		tx, err := s.store.Begin(ctx)
+		if err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+
+		defer func() {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback reverse payout tx", "error", rbErr)
+			}
+		}()
+
+		qtx := s.store.WithTx(tx)
+
+		payout, err := qtx.GetPayoutForUpdate(ctx, payoutID)
+		if err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+		if payout.Status != db.PayoutStatusEnumProcessing {
+			return struct{}{}, nil
+		}
+
+		amount := utils.NumericToDecimal(payout.Amount)
+		fee := utils.NumericToDecimal(payout.Fee)
+		postings := []ledger.Posting{ledger.DebitAccount(accounts.Suspense, amount, payout.Currency)}
+		if fee.IsPositive() {
+			postings = append(postings, ledger.DebitAccount(accounts.FeesRevenue, fee, payout.Currency))
+		}
+		postings = append(postings, ledger.Credit(payout.WalletID, amount.Add(fee), payout.Currency))
+
+		created, err := ledger.Post(ctx, qtx, ledger.Journal{
+			TransactionType:     db.TransactionTypeEnumReversal,
+			Description:         "payout reversal",
+			IdempotencyKey:      "payout:" + payout.ID.String() + ":reverse",
+			ReceiverWalletID:    payout.WalletID,
+			Amount:              amount.Add(fee),
+			Currency:            payout.Currency,
+			ParentTransactionID: payout.DebitTransactionID.Bytes,
+			Postings:            postings,
+		})
+		if err != nil {
+			return struct{}{}, err
+		}
+
+		if reason == "" {
+			reason = "payout failed at the provider"
+		}
+		payout, err = qtx.MarkPayoutReversed(ctx, db.MarkPayoutReversedParams{
+			ID:                    payout.ID,
+			ReversalTransactionID: utils.ToPgUUID(created.ID),
+			FailureReason:         pgtype.Text{String: reason, Valid: true},
+		})
+		if err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+
+		if err := outbox.RecordPayout(ctx, qtx, payout); err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+		return struct{}{}, nil
+	})
+	return err
+}
+
+// bank finds a bank the provider pays out to.
+func (s *Svc) bank(ctx context.Context, code string) (Bank, error) {
+	banks, err := s.provider.Banks(ctx)
+	if err != nil {
+		return Bank{}, err
+	}
+	for _, bank := range banks {
+		if bank.Code == code {
+			return bank, nil
+		}
+	}
+	return Bank{}, ErrUnknownBank
+}
+
+// destination loads one of the user's saved bank accounts; someone else's reads as missing.
+func (s *Svc) destination(ctx context.Context, userID uuid.UUID, destinationID uuid.UUID) (db.BankDestination, error) {
+	destination, err := s.store.Queries().GetBankDestinationById(ctx, destinationID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.BankDestination{}, ErrDestinationNotFound
+		}
+		return db.BankDestination{}, err
+	}
+	if destination.UserID != userID {
+		return db.BankDestination{}, ErrDestinationNotFound
+	}
+	return destination, nil
+}
+
+// releaseHold voids a locked hold and gives its uncaptured part back to the wallet's available balance.
+func releaseHold(ctx context.Context, qtx db.Querier, hold db.WalletHold) error {
+	wallets, err := qtx.GetWalletsForUpdate(ctx, []uuid.UUID{hold.WalletID})
+	if err != nil {
+		return &utils.RetryableError{Err: err}
+	}
+	if len(wallets) != 1 {
+		return transfer.ErrWalletNotFound
+	}
+
+	remaining := utils.NumericToDecimal(hold.Amount).Sub(utils.NumericToDecimal(hold.CapturedAmount))
+	available := utils.NumericToDecimal(wallets[0].AvailableBalance).Add(remaining)
+
+	if err := qtx.UpdateWalletAvailableBalance(ctx, db.UpdateWalletAvailableBalanceParams{
+		AvailableBalance: utils.DecimalToNumeric(available),
+		ID:               hold.WalletID,
+	}); err != nil {
+		return &utils.RetryableError{Err: err}
+	}
+
+	if _, err := qtx.UpdateWalletHoldStatus(ctx, db.UpdateWalletHoldStatusParams{
+		Status: db.HoldStatusEnumVoided,
+		ID:     hold.ID,
+	}); err != nil {
+		return &utils.RetryableError{Err: err}
+	}
+	return nil
+}
+
+// checkBackoff doubles the wait between status checks with each unanswered one, up to maxCheckInterval.
+func checkBackoff(interval time.Duration, checks int32) time.Duration {
+	wait := interval
+	for i := int32(0); i < checks && wait < maxCheckInterval; i++ {
+		wait *= 2
+	}
+	return min(wait, maxCheckInterval)
+}
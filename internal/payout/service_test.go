@@ -0,0 +1,291 @@
+package payout
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/luponetn/paycore/internal/accounts"
+	"github.com/luponetn/paycore/internal/config"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/fees"
+	"github.com/luponetn/paycore/internal/outbox"
+	"github.com/luponetn/paycore/internal/store"
+	"github.com/luponetn/paycore/internal/transfer"
+	"github.com/luponetn/paycore/pkg/utils"
+	"github.com/stretchr/testify/require"
+)
+
+const testPIN = "2580"
+
+// newTestService pays out through a simulator that answers at once and fails failureRate of payouts.
+func newTestService(f *store.FakeStore, failureRate float64) Service {
+	cfg := &config.Config{PayoutPollInterval: time.Second}
+	return NewService(f, NewSimulator(0, failureRate), transfer.NewService(f, nil, cfg), cfg)
+}
+
+// setupPayout gives a verified user with a PIN an NGN wallet holding balance, a saved
+// destination for accountNumber, and a flat 50 withdrawal fee.
+func setupPayout(t *testing.T, f *store.FakeStore, svc Service, balance string, accountNumber string) (uuid.UUID, uuid.UUID, db.BankDestination) {
+	t.Helper()
+	ctx := context.Background()
+
+	_, err := fees.NewService(f).CreateSchedule(ctx, fees.CreateScheduleRequest{
+		TransactionType: "withdrawal",
+		Currency:        "NGN",
+		FeeType:         "flat",
+		FlatAmount:      "50",
+	})
+	require.NoError(t, err)
+
+	user := db.User{ID: uuid.New(), EmailVerifiedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true}}
+	f.AddFakeUser(user)
+	hash, err := utils.HashPassword(testPIN)
+	require.NoError(t, err)
+	f.AddFakeTransactionPin(user.ID, hash)
+
+	walletID := f.AddFakeFundedWallet(user.ID, db.WalletTypeEnumSavings, "NGN", balance)
+
+	destination, err := svc.CreateDestination(ctx, user.ID, CreateDestinationRequest{BankCode: "901", AccountNumber: accountNumber})
+	require.NoError(t, err)
+	return user.ID, walletID, destination
+}
+
+func payoutRequest(walletID uuid.UUID, destination db.BankDestination, amount string) CreatePayoutRequest {
+	return CreatePayoutRequest{
+		WalletID:       walletID.String(),
+		DestinationID:  destination.ID.String(),
+		Amount:         amount,
+		IdempotencyKey: uuid.New().String(),
+		PIN:            testPIN,
+	}
+}
+
+func systemBalance(t *testing.T, f *store.FakeStore, code string) string {
+	t.Helper()
+	id, err := accounts.SystemWallet(context.Background(), f.Queries(), code, "NGN")
+	require.NoError(t, err)
+	return f.FakeBalance(id)
+}
+
+func payoutEvents(f *store.FakeStore) []string {
+	var events []string
+	for _, event := range f.OutboxEvents() {
+		if event.AggregateType == outbox.AggregatePayout {
+			events = append(events, event.EventType)
+		}
+	}
+	return events
+}
+
+func TestPayout_SettlesThroughSuspense(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := newTestService(f, 0)
+	ctx := context.Background()
+	userID, walletID, destination := setupPayout(t, f, svc, "1000", "0123456789")
+
+	req := payoutRequest(walletID, destination, "500")
+	payout, err := svc.CreatePayout(ctx, userID, req)
+	require.NoError(t, err)
+	require.Equal(t, db.PayoutStatusEnumInitiated, payout.Status)
+	require.Equal(t, "50.00", utils.NumericToDecimal(payout.Fee).StringFixed(2))
+	require.Equal(t, destination.AccountName, payout.AccountName)
+
+	// Replaying the request returns the same payout without holding funds again.
+	again, err := svc.CreatePayout(ctx, userID, req)
+	require.NoError(t, err)
+	require.Equal(t, payout.ID, again.ID)
+
+	balance, available := f.FakeBalances(walletID)
+	require.Equal(t, "1000.00", balance)
+	require.Equal(t, "450.00", available)
+
+	ids, err := svc.DuePayoutIDs(ctx, 10)
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{payout.ID}, ids)
+
+	// Accepted by the bank: the wallet is debited into suspense and fees revenue.
+	require.NoError(t, svc.ProcessPayout(ctx, payout.ID))
+	got, err := svc.GetPayout(ctx, userID, payout.ID)
+	require.NoError(t, err)
+	require.Equal(t, db.PayoutStatusEnumProcessing, got.Status)
+	require.True(t, got.ProviderReference.Valid)
+
+	balance, available = f.FakeBalances(walletID)
+	require.Equal(t, "450.00", balance)
+	require.Equal(t, "450.00", available)
+	require.Equal(t, "500.00", systemBalance(t, f, accounts.Suspense))
+	require.Equal(t, "50.00", systemBalance(t, f, accounts.FeesRevenue))
+
+	// Paid: suspense is cleared against settlement.
+	require.NoError(t, svc.ProcessPayout(ctx, payout.ID))
+	got, err = svc.GetPayout(ctx, userID, payout.ID)
+	require.NoError(t, err)
+	require.Equal(t, db.PayoutStatusEnumSucceeded, got.Status)
+	require.True(t, got.SettlementTransactionID.Valid)
+
+	require.Equal(t, "0.00", systemBalance(t, f, accounts.Suspense))
+	require.Equal(t, "500.00", systemBalance(t, f, accounts.Settlement))
+
+	// A late duplicate run changes nothing.
+	require.NoError(t, svc.ProcessPayout(ctx, payout.ID))
+	require.Equal(t, []string{outbox.EventPayoutInitiated, outbox.EventPayoutProcessing, outbox.EventPayoutSucceeded}, payoutEvents(f))
+
+	_, err = svc.GetPayout(ctx, uuid.New(), payout.ID)
+	require.ErrorIs(t, err, ErrPayoutNotFound)
+}
+
+func TestPayout_ReversesProviderFailure(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := newTestService(f, 1)
+	ctx := context.Background()
+	userID, walletID, destination := setupPayout(t, f, svc, "1000", "0123456789")
+
+	payout, err := svc.CreatePayout(ctx, userID, payoutRequest(walletID, destination, "500"))
+	require.NoError(t, err)
+
+	require.NoError(t, svc.ProcessPayout(ctx, payout.ID))
+	balance, _ := f.FakeBalances(walletID)
+	require.Equal(t, "450.00", balance)
+
+	require.NoError(t, svc.ProcessPayout(ctx, payout.ID))
+	got, err := svc.GetPayout(ctx, userID, payout.ID)
+	require.NoError(t, err)
+	require.Equal(t, db.PayoutStatusEnumReversed, got.Status)
+	require.True(t, got.ReversalTransactionID.Valid)
+	require.NotEmpty(t, got.FailureReason.String)
+
+	// The amount and the fee both come back.
+	balance, available := f.FakeBalances(walletID)
+	require.Equal(t, "1000.00", balance)
+	require.Equal(t, "1000.00", available)
+	require.Equal(t, "0.00", systemBalance(t, f, accounts.Suspense))
+	require.Equal(t, "0.00", systemBalance(t, f, accounts.FeesRevenue))
+	require.Equal(t, []string{outbox.EventPayoutInitiated, outbox.EventPayoutProcessing, outbox.EventPayoutReversed}, payoutEvents(f))
+}
+
+func TestPayout_RejectionReleasesHold(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := newTestService(f, 0)
+	ctx := context.Background()
+	userID, walletID, destination := setupPayout(t, f, svc, "1000", "0123459999")
+
+	_, err := svc.CreatePayout(ctx, userID, payoutRequest(walletID, destination, "960"))
+	require.ErrorIs(t, err, transfer.ErrInsufficientFunds)
+
+	payout, err := svc.CreatePayout(ctx, userID, payoutRequest(walletID, destination, "500"))
+	require.NoError(t, err)
+
+	// The simulator turns down accounts ending in 9999 before any money moves.
+	require.NoError(t, svc.ProcessPayout(ctx, payout.ID))
+	got, err := svc.GetPayout(ctx, userID, payout.ID)
+	require.NoError(t, err)
+	require.Equal(t, db.PayoutStatusEnumFailed, got.Status)
+	require.False(t, got.DebitTransactionID.Valid)
+
+	hold, err := f.GetWalletHoldById(ctx, payout.HoldID)
+	require.NoError(t, err)
+	require.Equal(t, db.HoldStatusEnumVoided, hold.Status)
+
+	balance, available := f.FakeBalances(walletID)
+	require.Equal(t, "1000.00", balance)
+	require.Equal(t, "1000.00", available)
+
+	ids, err := svc.DuePayoutIDs(ctx, 10)
+	require.NoError(t, err)
+	require.Empty(t, ids)
+}
+
+// drainingProvider is the simulator with a hook that runs while the bank has the payout.
+type drainingProvider struct {
+	*Simulator
+	beforeSubmit func()
+}
+
+func (p *drainingProvider) Submit(ctx context.Context, req PayoutRequest) (string, error) {
+	p.beforeSubmit()
+	return p.Simulator.Submit(ctx, req)
+}
+
+func TestPayout_AcceptedButUncapturableGoesToReview(t *testing.T) {
+	f := store.NewFakeStore()
+	cfg := &config.Config{PayoutPollInterval: time.Second}
+	transferSvc := transfer.NewService(f, nil, cfg)
+	provider := &drainingProvider{Simulator: NewSimulator(0, 0), beforeSubmit: func() {}}
+	svc := NewService(f, provider, transferSvc, cfg)
+	ctx := context.Background()
+	userID, walletID, destination := setupPayout(t, f, svc, "1000", "0123456789")
+
+	payout, err := svc.CreatePayout(ctx, userID, payoutRequest(walletID, destination, "500"))
+	require.NoError(t, err)
+
+	// The hold expiry job leaves payout holds alone, however long the payout waits.
+	f.ExpireFakeHold(payout.HoldID)
+	released, err := transferSvc.ReleaseExpiredHolds(ctx, 10)
+	require.NoError(t, err)
+	require.Zero(t, released)
+	_, available := f.FakeBalances(walletID)
+	require.Equal(t, "450.00", available)
+
+	// Should the money still be gone by the time the bank accepts, the debit cannot be made.
+	provider.beforeSubmit = func() {
+		_, err := f.UpdateWalletHoldStatus(ctx, db.UpdateWalletHoldStatusParams{Status: db.HoldStatusEnumVoided, ID: payout.HoldID})
+		require.NoError(t, err)
+		f.AddFakeWallet(db.GetWalletsAndLockByWalletIdsRow{
+			ID:         walletID,
+			UserID:     pgtype.UUID{Bytes: userID, Valid: true},
+			Currency:   "NGN",
+			WalletType: db.WalletTypeEnumSavings,
+		})
+	}
+
+	require.NoError(t, svc.ProcessPayout(ctx, payout.ID))
+	got, err := svc.GetPayout(ctx, userID, payout.ID)
+	require.NoError(t, err)
+	require.Equal(t, db.PayoutStatusEnumReview, got.Status)
+	require.True(t, got.ProviderReference.Valid)
+	require.Contains(t, got.FailureReason.String, "insufficient funds")
+	require.False(t, got.DebitTransactionID.Valid)
+
+	// Parked payouts are neither sent again nor polled.
+	ids, err := svc.DuePayoutIDs(ctx, 10)
+	require.NoError(t, err)
+	require.Empty(t, ids)
+	require.NoError(t, svc.ProcessPayout(ctx, payout.ID))
+
+	require.Equal(t, "0.00", systemBalance(t, f, accounts.Suspense))
+	require.Equal(t, []string{outbox.EventPayoutInitiated, outbox.EventPayoutReview}, payoutEvents(f))
+}
+
+func TestCreateDestination_ResolvesAccountName(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := newTestService(f, 0)
+	ctx := context.Background()
+	userID, _, destination := setupPayout(t, f, svc, "0", "0123456789")
+
+	details, err := svc.ResolveAccount(ctx, ResolveAccountRequest{BankCode: "901", AccountNumber: "0123456789"})
+	require.NoError(t, err)
+	require.Equal(t, details.AccountName, destination.AccountName)
+	require.Equal(t, "NGN", destination.Currency)
+
+	_, err = svc.CreateDestination(ctx, userID, CreateDestinationRequest{BankCode: "901", AccountNumber: "0123456789"})
+	require.ErrorIs(t, err, ErrDestinationExists)
+
+	_, err = svc.CreateDestination(ctx, userID, CreateDestinationRequest{BankCode: "901", AccountNumber: "0123450000"})
+	require.ErrorIs(t, err, ErrAccountNotFound)
+
+	_, err = svc.CreateDestination(ctx, userID, CreateDestinationRequest{BankCode: "901", AccountNumber: "12345"})
+	require.ErrorIs(t, err, ErrInvalidAccountNumber)
+
+	_, err = svc.CreateDestination(ctx, userID, CreateDestinationRequest{BankCode: "999", AccountNumber: "0123456789"})
+	require.ErrorIs(t, err, ErrUnknownBank)
+
+	require.ErrorIs(t, svc.DeleteDestination(ctx, uuid.New(), destination.ID), ErrDestinationNotFound)
+	require.NoError(t, svc.DeleteDestination(ctx, userID, destination.ID))
+
+	destinations, err := svc.ListDestinations(ctx, userID)
+	require.NoError(t, err)
+	require.Empty(t, destinations)
+}
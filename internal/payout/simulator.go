@@ -0,0 +1,164 @@
+package payout
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SimulatorName is the provider name of the local simulated bank.
+const SimulatorName = "simulator"
+
+// simulatorRefPrefix starts every reference the simulator hands out.
+const simulatorRefPrefix = "SIMBANK"
+
+var simulatorBanks = []Bank{
+	{Code: "901", Name: "Sandbox Bank", Currency: "NGN"},
+	{Code: "902", Name: "Testnet Trust", Currency: "NGN"},
+	{Code: "903", Name: "Mock Microfinance Bank", Currency: "NGN"},
+}
+
+var (
+	simulatorFirstNames = []string{"Ada", "Chinedu", "Fatima", "Tunde", "Ngozi", "Emeka", "Aisha", "Segun"}
+	simulatorLastNames  = []string{"Okafor", "Bello", "Adeyemi", "Eze", "Ibrahim", "Balogun", "Nwosu", "Lawal"}
+)
+
+// Simulator stands in for a payout provider in development and tests. Any ten-digit account
+// resolves to a made-up holder, except numbers ending in 0000, which do not exist; payouts to
+// numbers ending in 9999 are rejected outright. An accepted payout settles after latency, and
+// fails instead for failureRate of them.
+//
+// The outcome and due time are encoded in the reference it returns, so Status needs no state
+// and survives a restart; only the dedupe of repeated submissions lives in memory.
+type Simulator struct {
+	latency     time.Duration
+	failureRate float64
+
+	mu        sync.Mutex
+	submitted map[string]string
+}
+
+func NewSimulator(latency time.Duration, failureRate float64) *Simulator {
+	return &Simulator{
+		latency:     latency,
+		failureRate: failureRate,
+		submitted:   make(map[string]string),
+	}
+}
+
+func (s *Simulator) Name() string {
+	return SimulatorName
+}
+
+func (s *Simulator) Banks(ctx context.Context) ([]Bank, error) {
+	return simulatorBanks, nil
+}
+
+func (s *Simulator) NameEnquiry(ctx context.Context, bankCode string, accountNumber string) (AccountDetails, error) {
+	bank, err := simulatorBank(bankCode)
+	if err != nil {
+		return AccountDetails{}, err
+	}
+	if !validAccountNumber(accountNumber) {
+		return AccountDetails{}, ErrInvalidAccountNumber
+	}
+	if strings.HasSuffix(accountNumber, "0000") {
+		return AccountDetails{}, ErrAccountNotFound
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(bankCode + ":" + accountNumber))
+	sum := h.Sum32()
+	return AccountDetails{
+		BankCode:      bank.Code,
+		BankName:      bank.Name,
+		AccountNumber: accountNumber,
+		AccountName:   simulatorFirstNames[sum%uint32(len(simulatorFirstNames))] + " " + simulatorLastNames[(sum>>8)%uint32(len(simulatorLastNames))],
+	}, nil
+}
+
+func (s *Simulator) Submit(ctx context.Context, req PayoutRequest) (string, error) {
+	if _, err := s.NameEnquiry(ctx, req.BankCode, req.AccountNumber); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrPayoutRejected, err)
+	}
+	if strings.HasSuffix(req.AccountNumber, "9999") {
+		return "", fmt.Errorf("%w: beneficiary account is closed", ErrPayoutRejected)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ref, ok := s.submitted[req.Reference]; ok {
+		return ref, nil
+	}
+
+	nonce := make([]byte, 4)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	roll, err := rand.Int(rand.Reader, big.NewInt(10_000))
+	if err != nil {
+		return "", err
+	}
+	outcome := "S"
+	if float64(roll.Int64()) < s.failureRate*10_000 {
+		outcome = "F"
+	}
+
+	due := time.Now().Add(s.latency)
+	ref := fmt.Sprintf("%s-%d-%s-%s", simulatorRefPrefix, due.UnixMilli(), outcome, hex.EncodeToString(nonce))
+	s.submitted[req.Reference] = ref
+	return ref, nil
+}
+
+// Status reads the outcome back out of a reference Submit returned: "SIMBANK-<due unix ms>-<S|F>-<nonce>".
+func (s *Simulator) Status(ctx context.Context, providerReference string) (StatusResult, error) {
+	parts := strings.Split(providerReference, "-")
+	if len(parts) != 4 || parts[0] != simulatorRefPrefix {
+		return StatusResult{}, fmt.Errorf("unknown simulator reference %q", providerReference)
+	}
+	dueMilli, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return StatusResult{}, fmt.Errorf("unknown simulator reference %q", providerReference)
+	}
+
+	if time.Now().Before(time.UnixMilli(dueMilli)) {
+		return StatusResult{Status: ProviderPending}, nil
+	}
+	switch parts[2] {
+	case "S":
+		return StatusResult{Status: ProviderSucceeded}, nil
+	case "F":
+		return StatusResult{Status: ProviderFailed, Reason: "beneficiary bank declined the credit"}, nil
+	default:
+		return StatusResult{}, fmt.Errorf("unknown simulator reference %q", providerReference)
+	}
+}
+
+func simulatorBank(code string) (Bank, error) {
+	for _, bank := range simulatorBanks {
+		if bank.Code == code {
+			return bank, nil
+		}
+	}
+	return Bank{}, ErrUnknownBank
+}
+
+func validAccountNumber(accountNumber string) bool {
+	if len(accountNumber) != 10 {
+		return false
+	}
+	for _, r := range accountNumber {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,27 @@
+package payout
+
+type ResolveAccountRequest struct {
+	BankCode      string `json:"bank_code" binding:"required"`
+	AccountNumber string `json:"account_number" binding:"required"`
+}
+
+// CreateDestinationRequest saves a bank account to pay out to. The account name is taken from
+// name enquiry, not from the request.
+type CreateDestinationRequest struct {
+	BankCode      string `json:"bank_code" binding:"required"`
+	AccountNumber string `json:"account_number" binding:"required"`
+	Nickname      string `json:"nickname" binding:"omitempty,max=64"`
+}
+
+type CreatePayoutRequest struct {
+	WalletID       string `json:"wallet_id" binding:"required,uuid"`
+	DestinationID  string `json:"destination_id" binding:"required,uuid"`
+	Amount         string `json:"amount" binding:"required"`
+	Narration      string `json:"narration" binding:"omitempty,max=128"`
+	IdempotencyKey string `json:"idempotency_key" binding:"required"`
+	PIN            string `json:"pin" binding:"required,numeric,min=4,max=6"`
+}
+
+type ListPayoutsQuery struct {
+	Limit int32 `form:"limit" binding:"omitempty,min=1,max=100"`
+}
@@ -0,0 +1,13 @@
+package schedule
+
+import "errors"
+
+var (
+	ErrScheduleNotFound   = errors.New("schedule not found")
+	ErrInvalidWindow      = errors.New("end date must be after the start date")
+	ErrStartInPast        = errors.New("start date cannot be in the past")
+	ErrReceiverRequired   = errors.New("either receiver wallet id or account number is required")
+	ErrSameWallet         = errors.New("sender and receiver wallet cannot be the same")
+	ErrUnauthorizedWallet = errors.New("you do not own this wallet")
+	ErrScheduleClosed     = errors.New("schedule is completed or cancelled")
+)
@@ -0,0 +1,220 @@
+package schedule
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/luponetn/paycore/internal/transfer"
+)
+
+type Handler struct {
+	svc Service
+}
+
+func NewHandler(svc Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+func (h *Handler) HandleCreateSchedule(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	var req CreateScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	schedule, err := h.svc.CreateSchedule(c.Request.Context(), userID, req)
+	if err != nil {
+		c.AbortWithStatusJSON(scheduleErrorStatus(err), gin.H{
+			"message": "failed to create schedule",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "schedule created successfully",
+		"data":    schedule,
+	})
+}
+
+func (h *Handler) HandleListSchedules(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	schedules, err := h.svc.ListSchedules(c.Request.Context(), userID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"message": "failed to fetch schedules",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "schedules fetched successfully",
+		"data":    schedules,
+	})
+}
+
+func (h *Handler) HandleGetSchedule(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	scheduleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid schedule id"})
+		return
+	}
+
+	schedule, err := h.svc.GetSchedule(c.Request.Context(), userID, scheduleID)
+	if err != nil {
+		c.AbortWithStatusJSON(scheduleErrorStatus(err), gin.H{
+			"message": "failed to fetch schedule",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "schedule fetched successfully",
+		"data":    schedule,
+	})
+}
+
+func (h *Handler) HandleUpdateSchedule(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	scheduleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid schedule id"})
+		return
+	}
+
+	var req UpdateScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	schedule, err := h.svc.UpdateSchedule(c.Request.Context(), userID, scheduleID, req)
+	if err != nil {
+		c.AbortWithStatusJSON(scheduleErrorStatus(err), gin.H{
+			"message": "failed to update schedule",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "schedule updated successfully",
+		"data":    schedule,
+	})
+}
+
+func (h *Handler) HandleCancelSchedule(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	scheduleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid schedule id"})
+		return
+	}
+
+	schedule, err := h.svc.CancelSchedule(c.Request.Context(), userID, scheduleID)
+	if err != nil {
+		c.AbortWithStatusJSON(scheduleErrorStatus(err), gin.H{
+			"message": "failed to cancel schedule",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "schedule cancelled successfully",
+		"data":    schedule,
+	})
+}
+
+func (h *Handler) HandleListRuns(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	scheduleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid schedule id"})
+		return
+	}
+
+	var query PaginationQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid query parameters"})
+		return
+	}
+
+	runs, err := h.svc.ListRuns(c.Request.Context(), userID, scheduleID, query.PageSize, (query.Page-1)*query.PageSize)
+	if err != nil {
+		c.AbortWithStatusJSON(scheduleErrorStatus(err), gin.H{
+			"message": "failed to fetch schedule runs",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "schedule runs fetched successfully",
+		"data":    runs,
+	})
+}
+
+func authUserID(c *gin.Context) (uuid.UUID, bool) {
+	userIdVal, exists := c.Get("user_id")
+	if !exists {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return uuid.Nil, false
+	}
+	userID, ok := userIdVal.(uuid.UUID)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "invalid user id type"})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+func scheduleErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrScheduleClosed):
+		return http.StatusConflict
+	case transfer.IsInvalidAmount(err), errors.Is(err, ErrInvalidWindow), errors.Is(err, ErrStartInPast),
+		errors.Is(err, ErrReceiverRequired), errors.Is(err, ErrSameWallet), errors.Is(err, transfer.ErrCurrencyMismatch):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrScheduleNotFound), errors.Is(err, transfer.ErrWalletNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrUnauthorizedWallet), errors.Is(err, transfer.ErrPINNotSet):
+		return http.StatusForbidden
+	case errors.Is(err, transfer.ErrInvalidPIN):
+		return http.StatusUnauthorized
+	case errors.Is(err, transfer.ErrPINLocked):
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
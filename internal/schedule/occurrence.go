@@ -0,0 +1,48 @@
+package schedule
+
+import (
+	"time"
+
+	"github.com/luponetn/paycore/internal/db"
+)
+
+// occurrenceAt returns when the n-th (1-based) occurrence of a schedule is due. Occurrences
+// are always derived from the start date rather than the previous run so they never drift;
+// monthly schedules started on e.g. the 31st fall on the last day of shorter months.
+func occurrenceAt(start time.Time, frequency db.ScheduleFrequencyEnum, n int32) time.Time {
+	steps := int(n - 1)
+	switch frequency {
+	case db.ScheduleFrequencyEnumDaily:
+		return start.AddDate(0, 0, steps)
+	case db.ScheduleFrequencyEnumWeekly:
+		return start.AddDate(0, 0, 7*steps)
+	default:
+		year, month, day := start.Date()
+		target := time.Date(year, month+time.Month(steps), 1, start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+		lastDay := target.AddDate(0, 1, -1).Day()
+		if day > lastDay {
+			day = lastDay
+		}
+		return target.AddDate(0, 0, day-1)
+	}
+}
+
+// isFinished reports whether occurrence n falls outside the schedule's end date or run limit.
+func isFinished(s db.ScheduledTransfer, n int32) bool {
+	if s.MaxRuns.Valid && n > s.MaxRuns.Int32 {
+		return true
+	}
+	if s.EndAt.Valid && occurrenceAt(s.StartAt.Time, s.Frequency, n).After(s.EndAt.Time) {
+		return true
+	}
+	return false
+}
+
+// retryDelay backs off exponentially between attempts of the same occurrence, capped at a day.
+func retryDelay(attempt int32) time.Duration {
+	delay := 15 * time.Minute << attempt
+	if delay > 24*time.Hour {
+		return 24 * time.Hour
+	}
+	return delay
+}
@@ -0,0 +1,43 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOccurrenceAt(t *testing.T) {
+	start := time.Date(2026, time.January, 31, 9, 0, 0, 0, time.UTC)
+
+	require.Equal(t, start, occurrenceAt(start, db.ScheduleFrequencyEnumDaily, 1))
+	require.Equal(t, time.Date(2026, time.February, 2, 9, 0, 0, 0, time.UTC), occurrenceAt(start, db.ScheduleFrequencyEnumDaily, 3))
+	require.Equal(t, time.Date(2026, time.February, 14, 9, 0, 0, 0, time.UTC), occurrenceAt(start, db.ScheduleFrequencyEnumWeekly, 3))
+
+	// Monthly schedules started on the 31st clamp to the month end and recover afterwards.
+	require.Equal(t, time.Date(2026, time.February, 28, 9, 0, 0, 0, time.UTC), occurrenceAt(start, db.ScheduleFrequencyEnumMonthly, 2))
+	require.Equal(t, time.Date(2026, time.March, 31, 9, 0, 0, 0, time.UTC), occurrenceAt(start, db.ScheduleFrequencyEnumMonthly, 3))
+	require.Equal(t, time.Date(2026, time.April, 30, 9, 0, 0, 0, time.UTC), occurrenceAt(start, db.ScheduleFrequencyEnumMonthly, 4))
+}
+
+func TestIsFinished(t *testing.T) {
+	start := time.Date(2026, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+	byRuns := db.ScheduledTransfer{
+		Frequency: db.ScheduleFrequencyEnumWeekly,
+		StartAt:   pgtype.Timestamptz{Time: start, Valid: true},
+		MaxRuns:   pgtype.Int4{Int32: 3, Valid: true},
+	}
+	require.False(t, isFinished(byRuns, 3))
+	require.True(t, isFinished(byRuns, 4))
+
+	byDate := db.ScheduledTransfer{
+		Frequency: db.ScheduleFrequencyEnumDaily,
+		StartAt:   pgtype.Timestamptz{Time: start, Valid: true},
+		EndAt:     pgtype.Timestamptz{Time: start.AddDate(0, 0, 9), Valid: true},
+	}
+	require.False(t, isFinished(byDate, 10))
+	require.True(t, isFinished(byDate, 11))
+}
@@ -0,0 +1,24 @@
+package schedule
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/luponetn/paycore/internal/middleware"
+	"github.com/luponetn/paycore/pkg/utils"
+)
+
+func RegisterRoutes(r *gin.Engine, h *Handler, keys *utils.KeySet, sessions middleware.SessionChecker) {
+	scheduleGroup := r.Group("/transfer/schedules")
+
+	//use middlewares
+	scheduleGroup.Use(middleware.AuthMiddleware(keys, sessions))
+
+	//implement routes
+	{
+		scheduleGroup.POST("", h.HandleCreateSchedule)
+		scheduleGroup.GET("", h.HandleListSchedules)
+		scheduleGroup.GET("/:id", h.HandleGetSchedule)
+		scheduleGroup.PATCH("/:id", h.HandleUpdateSchedule)
+		scheduleGroup.DELETE("/:id", h.HandleCancelSchedule)
+		scheduleGroup.GET("/:id/runs", h.HandleListRuns)
+	}
+}
@@ -0,0 +1,455 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/store"
+	"github.com/luponetn/paycore/internal/transfer"
+	"github.com/luponetn/paycore/pkg/utils"
+)
+
+type Service interface {
+	CreateSchedule(ctx context.Context, userID uuid.UUID, req CreateScheduleRequest) (db.ScheduledTransfer, error)
+	ListSchedules(ctx context.Context, userID uuid.UUID) ([]db.ScheduledTransfer, error)
+	GetSchedule(ctx context.Context, userID uuid.UUID, scheduleID uuid.UUID) (db.ScheduledTransfer, error)
+	UpdateSchedule(ctx context.Context, userID uuid.UUID, scheduleID uuid.UUID, req UpdateScheduleRequest) (db.ScheduledTransfer, error)
+	CancelSchedule(ctx context.Context, userID uuid.UUID, scheduleID uuid.UUID) (db.ScheduledTransfer, error)
+	ListRuns(ctx context.Context, userID uuid.UUID, scheduleID uuid.UUID, limit int32, offset int32) ([]db.ScheduledTransferRun, error)
+	DueScheduleIDs(ctx context.Context, limit int32) ([]uuid.UUID, error)
+	ExecuteSchedule(ctx context.Context, scheduleID uuid.UUID) error
+}
+
+type Svc struct {
+	store       store.Store
+	transferSvc transfer.Service
+}
+
+func NewService(store store.Store, transferSvc transfer.Service) Service {
+	return &Svc{store: store, transferSvc: transferSvc}
+}
+
+// CreateSchedule registers a standing order. The receiver is resolved once, up front, so later
+// changes to the receiver's account number do not redirect the payments.
+func (s *Svc) CreateSchedule(ctx context.Context, userID uuid.UUID, req CreateScheduleRequest) (db.ScheduledTransfer, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	amount, err := transfer.ParseAmount(req.Amount, req.Currency)
+	if err != nil {
+		return db.ScheduledTransfer{}, err
+	}
+
+	if req.StartAt.Before(time.Now().Add(-time.Minute)) {
+		return db.ScheduledTransfer{}, ErrStartInPast
+	}
+
+	if req.EndAt != nil && !req.EndAt.After(req.StartAt) {
+		return db.ScheduledTransfer{}, ErrInvalidWindow
+	}
+
+	senderID, err := uuid.Parse(req.SenderWalletID)
+	if err != nil {
+		return db.ScheduledTransfer{}, errors.New("invalid sender wallet id")
+	}
+
+	var receiverID uuid.UUID
+	if req.ReceiverAccountNo != "" {
+		w, err := s.store.Queries().GetWalletByAccountNoAndCurrency(ctx, db.GetWalletByAccountNoAndCurrencyParams{
+			AccountNo: req.ReceiverAccountNo,
+			Currency:  amount.Code(),
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return db.ScheduledTransfer{}, errors.New("receiver account not found")
+			}
+			return db.ScheduledTransfer{}, err
+		}
+		receiverID = w.WalletID
+	} else if req.ReceiverWalletID != "" {
+		receiverID, err = uuid.Parse(req.ReceiverWalletID)
+		if err != nil {
+			return db.ScheduledTransfer{}, errors.New("invalid receiver wallet id")
+		}
+	} else {
+		return db.ScheduledTransfer{}, ErrReceiverRequired
+	}
+
+	if senderID == receiverID {
+		return db.ScheduledTransfer{}, ErrSameWallet
+	}
+
+	sender, err := s.store.Queries().GetWalletById(ctx, senderID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.ScheduledTransfer{}, transfer.ErrWalletNotFound
+		}
+		return db.ScheduledTransfer{}, err
+	}
+
+	if !sender.UserID.Valid || uuid.UUID(sender.UserID.Bytes) != userID {
+		return db.ScheduledTransfer{}, ErrUnauthorizedWallet
+	}
+
+	if sender.Currency != amount.Code() {
+		return db.ScheduledTransfer{}, transfer.ErrCurrencyMismatch
+	}
+
+	// every future run is paid without the user present, so the PIN approves them all up front
+	if err := s.transferSvc.VerifyPIN(ctx, userID, req.PIN); err != nil {
+		return db.ScheduledTransfer{}, err
+	}
+
+	params := db.CreateScheduledTransferParams{
+		UserID:           userID,
+		SenderWalletID:   senderID,
+		ReceiverWalletID: receiverID,
+		Amount:           amount.Numeric(),
+		Currency:         amount.Code(),
+		Description:      pgtype.Text{String: req.Description, Valid: req.Description != ""},
+		Frequency:        db.ScheduleFrequencyEnum(req.Frequency),
+		StartAt:          pgtype.Timestamptz{Time: req.StartAt, Valid: true},
+		FailurePolicy:    db.ScheduleFailurePolicyEnumRetry,
+		MaxRetries:       3,
+		NextRunAt:        pgtype.Timestamptz{Time: req.StartAt, Valid: true},
+	}
+	if req.EndAt != nil {
+		params.EndAt = pgtype.Timestamptz{Time: *req.EndAt, Valid: true}
+	}
+	if req.MaxRuns != nil {
+		params.MaxRuns = pgtype.Int4{Int32: *req.MaxRuns, Valid: true}
+	}
+	if req.FailurePolicy != "" {
+		params.FailurePolicy = db.ScheduleFailurePolicyEnum(req.FailurePolicy)
+	}
+	if req.MaxRetries != nil {
+		params.MaxRetries = *req.MaxRetries
+	}
+
+	return s.store.Queries().CreateScheduledTransfer(ctx, params)
+}
+
+func (s *Svc) ListSchedules(ctx context.Context, userID uuid.UUID) ([]db.ScheduledTransfer, error) {
+	ctx, cancel := context.WithTimeout(ctx, 8*time.Second)
+	defer cancel()
+
+	return utils.Retry(3, 100, func() ([]db.ScheduledTransfer, error) {
+		schedules, err := s.store.Queries().GetScheduledTransfersByUserId(ctx, userID)
+		if err != nil {
+			return nil, &utils.RetryableError{Err: err}
+		}
+		return schedules, nil
+	})
+}
+
+func (s *Svc) GetSchedule(ctx context.Context, userID uuid.UUID, scheduleID uuid.UUID) (db.ScheduledTransfer, error) {
+	ctx, cancel := context.WithTimeout(ctx, 8*time.Second)
+	defer cancel()
+
+	schedule, err := s.store.Queries().GetScheduledTransferById(ctx, scheduleID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.ScheduledTransfer{}, ErrScheduleNotFound
+		}
+		return db.ScheduledTransfer{}, err
+	}
+
+	// Someone else's schedule looks exactly like a missing one.
+	if schedule.UserID != userID {
+		return db.ScheduledTransfer{}, ErrScheduleNotFound
+	}
+	return schedule, nil
+}
+
+// UpdateSchedule edits a schedule and pauses or resumes it. Resuming a schedule whose next run
+// is already in the past fast-forwards it to the next future occurrence instead of firing a burst.
+func (s *Svc) UpdateSchedule(ctx context.Context, userID uuid.UUID, scheduleID uuid.UUID, req UpdateScheduleRequest) (db.ScheduledTransfer, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if req.Amount != nil {
+		if err := s.transferSvc.VerifyPIN(ctx, userID, req.PIN); err != nil {
+			return db.ScheduledTransfer{}, err
+		}
+	}
+
+	return s.withLockedSchedule(ctx, userID, scheduleID, func(qtx db.Querier, schedule db.ScheduledTransfer) (db.ScheduledTransfer, error) {
+		if schedule.Status == db.ScheduleStatusEnumCompleted || schedule.Status == db.ScheduleStatusEnumCancelled {
+			return db.ScheduledTransfer{}, ErrScheduleClosed
+		}
+
+		params := db.UpdateScheduledTransferParams{ID: schedule.ID}
+
+		if req.Amount != nil {
+			amount, err := transfer.ParseAmount(*req.Amount, schedule.Currency)
+			if err != nil {
+				return db.ScheduledTransfer{}, err
+			}
+			params.Amount = amount.Numeric()
+		}
+		if req.Description != nil {
+			params.Description = pgtype.Text{String: *req.Description, Valid: true}
+		}
+		if req.EndAt != nil {
+			if !req.EndAt.After(schedule.StartAt.Time) {
+				return db.ScheduledTransfer{}, ErrInvalidWindow
+			}
+			params.EndAt = pgtype.Timestamptz{Time: *req.EndAt, Valid: true}
+		}
+		if req.MaxRuns != nil {
+			params.MaxRuns = pgtype.Int4{Int32: *req.MaxRuns, Valid: true}
+		}
+		if req.FailurePolicy != nil {
+			params.FailurePolicy = db.NullScheduleFailurePolicyEnum{ScheduleFailurePolicyEnum: db.ScheduleFailurePolicyEnum(*req.FailurePolicy), Valid: true}
+		}
+		if req.MaxRetries != nil {
+			params.MaxRetries = pgtype.Int4{Int32: *req.MaxRetries, Valid: true}
+		}
+		if req.Status != nil {
+			status := db.ScheduleStatusEnum(*req.Status)
+			params.Status = db.NullScheduleStatusEnum{ScheduleStatusEnum: status, Valid: true}
+
+			if status == db.ScheduleStatusEnumActive && schedule.Status == db.ScheduleStatusEnumPaused {
+				next := schedule.NextOccurrence
+				for occurrenceAt(schedule.StartAt.Time, schedule.Frequency, next).Before(time.Now()) {
+					next++
+				}
+				if next != schedule.NextOccurrence {
+					if _, err := qtx.AdvanceScheduledTransfer(ctx, db.AdvanceScheduledTransferParams{
+						NextOccurrence: next,
+						NextRunAt:      pgtype.Timestamptz{Time: occurrenceAt(schedule.StartAt.Time, schedule.Frequency, next), Valid: true},
+						RunsCount:      schedule.RunsCount,
+						Status:         schedule.Status,
+						LastError:      schedule.LastError,
+						ID:             schedule.ID,
+					}); err != nil {
+						return db.ScheduledTransfer{}, &utils.RetryableError{Err: err}
+					}
+				}
+			}
+		}
+
+		updated, err := qtx.UpdateScheduledTransfer(ctx, params)
+		if err != nil {
+			return db.ScheduledTransfer{}, &utils.RetryableError{Err: err}
+		}
+		return updated, nil
+	})
+}
+
+func (s *Svc) CancelSchedule(ctx context.Context, userID uuid.UUID, scheduleID uuid.UUID) (db.ScheduledTransfer, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	return s.withLockedSchedule(ctx, userID, scheduleID, func(qtx db.Querier, schedule db.ScheduledTransfer) (db.ScheduledTransfer, error) {
+		if schedule.Status == db.ScheduleStatusEnumCompleted || schedule.Status == db.ScheduleStatusEnumCancelled {
+			return db.ScheduledTransfer{}, ErrScheduleClosed
+		}
+
+		updated, err := qtx.UpdateScheduledTransfer(ctx, db.UpdateScheduledTransferParams{
+			Status: db.NullScheduleStatusEnum{ScheduleStatusEnum: db.ScheduleStatusEnumCancelled, Valid: true},
+			ID:     schedule.ID,
+		})
+		if err != nil {
+			return db.ScheduledTransfer{}, &utils.RetryableError{Err: err}
+		}
+		return updated, nil
+	})
+}
+
+func (s *Svc) ListRuns(ctx context.Context, userID uuid.UUID, scheduleID uuid.UUID, limit int32, offset int32) ([]db.ScheduledTransferRun, error) {
+	if _, err := s.GetSchedule(ctx, userID, scheduleID); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 8*time.Second)
+	defer cancel()
+
+	return s.store.Queries().GetScheduledTransferRuns(ctx, db.GetScheduledTransferRunsParams{
+		ScheduleID: scheduleID,
+		Limit:      limit,
+		Offset:     offset,
+	})
+}
+
+// DueScheduleIDs lists active schedules whose next run is due.
+func (s *Svc) DueScheduleIDs(ctx context.Context, limit int32) ([]uuid.UUID, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	return s.store.Queries().GetDueScheduledTransferIds(ctx, limit)
+}
+
+// ExecuteSchedule fires the due occurrence of a schedule through transfer.Service.CreateAuthorizedTransaction;
+// the PIN was checked when the schedule was created.
+// The idempotency key is derived from the schedule and occurrence, so a duplicate or retried task
+// can never pay the same occurrence twice, and one whose transfer already went through records it
+// as paid rather than failed. Transfer failures are recorded against the schedule and handled by
+// its failure policy; only infrastructure errors are returned to the caller.
+func (s *Svc) ExecuteSchedule(ctx context.Context, scheduleID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	schedule, err := s.store.Queries().GetScheduledTransferById(ctx, scheduleID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	if schedule.Status != db.ScheduleStatusEnumActive || schedule.NextRunAt.Time.After(time.Now()) {
+		return nil
+	}
+
+	occurrence := schedule.NextOccurrence
+	key := fmt.Sprintf("schedule:%s:%d", schedule.ID, occurrence)
+	transaction, transferErr := s.transferSvc.CreateAuthorizedTransaction(ctx, schedule.UserID, transfer.CreateTransactionRequest{
+		SenderWalletID:   schedule.SenderWalletID.String(),
+		ReceiverWalletID: schedule.ReceiverWalletID.String(),
+		TransactionType:  string(db.TransactionTypeEnumTransfer),
+		Amount:           utils.NumericToDecimal(schedule.Amount).String(),
+		Description:      schedule.Description.String,
+		Currency:         schedule.Currency,
+		IdempotencyKey:   key,
+	})
+
+	_, err = utils.Retry(3, 100, func() (struct{}, error) {
+		tx, err := s.store.Begin(ctx)
+		if err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+
+		defer func() {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback schedule run tx", "error", rbErr)
+			}
+		}()
+
+		qtx := s.store.WithTx(tx)
+
+		current, err := qtx.GetScheduledTransferForUpdate(ctx, schedule.ID)
+		if err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+
+		// Another worker already recorded this occurrence.
+		if current.NextOccurrence != occurrence || current.Status != db.ScheduleStatusEnumActive {
+			return struct{}{}, nil
+		}
+
+		// The transfer can have committed even though the call reported an error, a timeout on
+		// the way back for instance. A transaction under the key means the occurrence was paid.
+		if transferErr != nil {
+			if posted, err := qtx.GetTransactionByIdempotencyKey(ctx, key); err == nil {
+				transaction, transferErr = posted, nil
+			} else if !errors.Is(err, pgx.ErrNoRows) {
+				return struct{}{}, &utils.RetryableError{Err: err}
+			}
+		}
+
+		run := db.CreateScheduledTransferRunParams{
+			ScheduleID: current.ID,
+			Occurrence: occurrence,
+			Attempt:    current.RetryCount + 1,
+		}
+		advance := db.AdvanceScheduledTransferParams{
+			NextOccurrence: occurrence + 1,
+			RunsCount:      current.RunsCount,
+			Status:         db.ScheduleStatusEnumActive,
+			ID:             current.ID,
+		}
+
+		switch {
+		case transferErr == nil:
+			run.Status = db.ScheduleRunStatusEnumSucceeded
+			run.TransactionID = utils.ToPgUUID(transaction.ID)
+			advance.RunsCount++
+		case current.FailurePolicy == db.ScheduleFailurePolicyEnumRetry && current.RetryCount < current.MaxRetries:
+			run.Status = db.ScheduleRunStatusEnumFailed
+			run.Error = pgtype.Text{String: transferErr.Error(), Valid: true}
+			advance.NextOccurrence = occurrence
+			advance.RetryCount = current.RetryCount + 1
+			advance.NextRunAt = pgtype.Timestamptz{Time: time.Now().Add(retryDelay(advance.RetryCount)), Valid: true}
+			advance.LastError = run.Error
+		default:
+			run.Status = db.ScheduleRunStatusEnumSkipped
+			run.Error = pgtype.Text{String: transferErr.Error(), Valid: true}
+			advance.LastError = run.Error
+		}
+
+		if advance.NextOccurrence != occurrence {
+			if isFinished(current, advance.NextOccurrence) {
+				advance.Status = db.ScheduleStatusEnumCompleted
+				advance.NextRunAt = current.NextRunAt
+			} else {
+				advance.NextRunAt = pgtype.Timestamptz{Time: occurrenceAt(current.StartAt.Time, current.Frequency, advance.NextOccurrence), Valid: true}
+			}
+		}
+
+		if _, err := qtx.CreateScheduledTransferRun(ctx, run); err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+
+		if _, err := qtx.AdvanceScheduledTransfer(ctx, advance); err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+
+		if transferErr != nil {
+			slog.Warn("scheduled transfer failed", "schedule_id", current.ID, "occurrence", occurrence, "status", run.Status, "error", transferErr)
+		}
+		return struct{}{}, nil
+	})
+	return err
+}
+
+// withLockedSchedule runs fn inside a transaction holding the schedule row lock, after checking ownership.
+func (s *Svc) withLockedSchedule(ctx context.Context, userID uuid.UUID, scheduleID uuid.UUID, fn func(qtx db.Querier, schedule db.ScheduledTransfer) (db.ScheduledTransfer, error)) (db.ScheduledTransfer, error) {
+	return utils.Retry(3, 100, func() (db.ScheduledTransfer, error) {
+		tx, err := s.store.Begin(ctx)
+		if err != nil {
+			return db.ScheduledTransfer{}, &utils.RetryableError{Err: err}
+		}
+
+		defer func() {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback schedule tx", "error", rbErr)
+			}
+		}()
+
+		qtx := s.store.WithTx(tx)
+
+		schedule, err := qtx.GetScheduledTransferForUpdate(ctx, scheduleID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return db.ScheduledTransfer{}, ErrScheduleNotFound
+			}
+			return db.ScheduledTransfer{}, &utils.RetryableError{Err: err}
+		}
+
+		if schedule.UserID != userID {
+			return db.ScheduledTransfer{}, ErrScheduleNotFound
+		}
+
+		updated, err := fn(qtx, schedule)
+		if err != nil {
+			return db.ScheduledTransfer{}, err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return db.ScheduledTransfer{}, &utils.RetryableError{Err: err}
+		}
+		return updated, nil
+	})
+}
@@ -0,0 +1,180 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/luponetn/paycore/internal/config"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/store"
+	"github.com/luponetn/paycore/internal/transfer"
+	"github.com/luponetn/paycore/pkg/money"
+	"github.com/luponetn/paycore/pkg/utils"
+	"github.com/stretchr/testify/require"
+)
+
+const testPIN = "2580"
+
+// setupSchedule gives a verified user with a PIN an NGN wallet holding balance and a standing
+// order of amount to a second wallet, due now.
+func setupSchedule(t *testing.T, f *store.FakeStore, balance string, amount string, policy string, maxRetries int32) (Service, transfer.Service, db.ScheduledTransfer) {
+	t.Helper()
+	ctx := context.Background()
+
+	transferSvc := transfer.NewService(f, nil, &config.Config{})
+	svc := NewService(f, transferSvc)
+
+	user := db.User{ID: uuid.New(), EmailVerifiedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true}}
+	f.AddFakeUser(user)
+	hash, err := utils.HashPassword(testPIN)
+	require.NoError(t, err)
+	f.AddFakeTransactionPin(user.ID, hash)
+
+	sender := f.AddFakeFundedWallet(user.ID, db.WalletTypeEnumSavings, "NGN", balance)
+	receiver := f.AddFakeFundedWallet(uuid.New(), db.WalletTypeEnumSavings, "NGN", "0")
+
+	schedule, err := svc.CreateSchedule(ctx, user.ID, CreateScheduleRequest{
+		SenderWalletID:   sender.String(),
+		ReceiverWalletID: receiver.String(),
+		Amount:           amount,
+		Currency:         "NGN",
+		Frequency:        "daily",
+		StartAt:          time.Now(),
+		FailurePolicy:    policy,
+		MaxRetries:       &maxRetries,
+		PIN:              testPIN,
+	})
+	require.NoError(t, err)
+	return svc, transferSvc, schedule
+}
+
+// runStatuses lists a schedule's runs oldest first.
+func runStatuses(t *testing.T, svc Service, schedule db.ScheduledTransfer) []db.ScheduleRunStatusEnum {
+	t.Helper()
+	runs, err := svc.ListRuns(context.Background(), schedule.UserID, schedule.ID, 100, 0)
+	require.NoError(t, err)
+	statuses := make([]db.ScheduleRunStatusEnum, len(runs))
+	for i, run := range runs {
+		statuses[len(runs)-1-i] = run.Status
+	}
+	return statuses
+}
+
+func TestExecuteSchedule_RetriesThenSkipsOccurrence(t *testing.T) {
+	f := store.NewFakeStore()
+	svc, _, schedule := setupSchedule(t, f, "0", "25.00", "retry", 1)
+	ctx := context.Background()
+
+	// Nothing to pay with: the first attempt is retried later on the same occurrence.
+	require.NoError(t, svc.ExecuteSchedule(ctx, schedule.ID))
+	got, err := svc.GetSchedule(ctx, schedule.UserID, schedule.ID)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), got.NextOccurrence)
+	require.Equal(t, int32(1), got.RetryCount)
+	require.True(t, got.NextRunAt.Time.After(time.Now()))
+
+	// The retry is not due yet.
+	require.NoError(t, svc.ExecuteSchedule(ctx, schedule.ID))
+	require.Len(t, runStatuses(t, svc, schedule), 1)
+
+	// Out of retries: the occurrence is skipped and the schedule moves on.
+	f.DueFakeSchedule(schedule.ID)
+	require.NoError(t, svc.ExecuteSchedule(ctx, schedule.ID))
+	got, err = svc.GetSchedule(ctx, schedule.UserID, schedule.ID)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), got.NextOccurrence)
+	require.Equal(t, int32(0), got.RetryCount)
+	require.Equal(t, int32(0), got.RunsCount)
+	require.True(t, got.LastError.Valid)
+	require.Equal(t, db.ScheduleStatusEnumActive, got.Status)
+
+	require.Equal(t, []db.ScheduleRunStatusEnum{db.ScheduleRunStatusEnumFailed, db.ScheduleRunStatusEnumSkipped}, runStatuses(t, svc, schedule))
+}
+
+func TestExecuteSchedule_SkipPolicyMovesOnAtOnce(t *testing.T) {
+	f := store.NewFakeStore()
+	svc, _, schedule := setupSchedule(t, f, "30", "25.00", "skip", 0)
+	ctx := context.Background()
+
+	require.NoError(t, svc.ExecuteSchedule(ctx, schedule.ID))
+	f.DueFakeSchedule(schedule.ID)
+	require.NoError(t, svc.ExecuteSchedule(ctx, schedule.ID))
+
+	got, err := svc.GetSchedule(ctx, schedule.UserID, schedule.ID)
+	require.NoError(t, err)
+	require.Equal(t, int32(3), got.NextOccurrence)
+	require.Equal(t, int32(1), got.RunsCount)
+	require.Equal(t, "5.00", f.FakeBalance(schedule.SenderWalletID))
+	require.Equal(t, []db.ScheduleRunStatusEnum{db.ScheduleRunStatusEnumSucceeded, db.ScheduleRunStatusEnumSkipped}, runStatuses(t, svc, schedule))
+}
+
+func TestExecuteSchedule_RecordsOccurrenceThatAlreadyPaid(t *testing.T) {
+	f := store.NewFakeStore()
+	svc, transferSvc, schedule := setupSchedule(t, f, "100", "60.00", "retry", 3)
+	ctx := context.Background()
+
+	// An earlier worker paid the first occurrence and died before recording the run.
+	paid, err := transferSvc.CreateAuthorizedTransaction(ctx, schedule.UserID, transfer.CreateTransactionRequest{
+		SenderWalletID:   schedule.SenderWalletID.String(),
+		ReceiverWalletID: schedule.ReceiverWalletID.String(),
+		TransactionType:  string(db.TransactionTypeEnumTransfer),
+		Amount:           "60.00",
+		Currency:         "NGN",
+		IdempotencyKey:   fmt.Sprintf("schedule:%s:%d", schedule.ID, 1),
+	})
+	require.NoError(t, err)
+
+	// What is left would not cover another payment, yet the occurrence counts as paid.
+	require.NoError(t, svc.ExecuteSchedule(ctx, schedule.ID))
+	got, err := svc.GetSchedule(ctx, schedule.UserID, schedule.ID)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), got.NextOccurrence)
+	require.Equal(t, int32(1), got.RunsCount)
+	require.Equal(t, int32(0), got.RetryCount)
+	require.Equal(t, "40.00", f.FakeBalance(schedule.SenderWalletID))
+
+	runs, err := svc.ListRuns(ctx, schedule.UserID, schedule.ID, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	require.Equal(t, db.ScheduleRunStatusEnumSucceeded, runs[0].Status)
+	require.Equal(t, paid.ID, uuid.UUID(runs[0].TransactionID.Bytes))
+
+	// A duplicate task for the same occurrence changes nothing.
+	require.NoError(t, svc.ExecuteSchedule(ctx, schedule.ID))
+	require.Len(t, runStatuses(t, svc, schedule), 1)
+}
+
+func TestCreateSchedule_AmountsFollowCurrencyPrecision(t *testing.T) {
+	f := store.NewFakeStore()
+	svc, _, schedule := setupSchedule(t, f, "100", "10.00", "retry", 3)
+	ctx := context.Background()
+
+	req := CreateScheduleRequest{
+		SenderWalletID:   schedule.SenderWalletID.String(),
+		ReceiverWalletID: schedule.ReceiverWalletID.String(),
+		Amount:           "10.005",
+		Currency:         "ngn",
+		Frequency:        "weekly",
+		StartAt:          time.Now(),
+		PIN:              testPIN,
+	}
+	_, err := svc.CreateSchedule(ctx, schedule.UserID, req)
+	require.ErrorIs(t, err, money.ErrTooPrecise)
+
+	req.Amount = "0"
+	_, err = svc.CreateSchedule(ctx, schedule.UserID, req)
+	require.ErrorIs(t, err, transfer.ErrInvalidAmount)
+
+	req.Amount = "10.50"
+	created, err := svc.CreateSchedule(ctx, schedule.UserID, req)
+	require.NoError(t, err)
+	require.Equal(t, "NGN", created.Currency)
+
+	tooPrecise := "3.141"
+	_, err = svc.UpdateSchedule(ctx, schedule.UserID, schedule.ID, UpdateScheduleRequest{Amount: &tooPrecise, PIN: testPIN})
+	require.ErrorIs(t, err, money.ErrTooPrecise)
+}
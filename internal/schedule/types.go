@@ -0,0 +1,36 @@
+package schedule
+
+import "time"
+
+type CreateScheduleRequest struct {
+	SenderWalletID    string     `json:"sender_wallet_id" binding:"required,uuid"`
+	ReceiverWalletID  string     `json:"receiver_wallet_id" binding:"omitempty,uuid"`
+	ReceiverAccountNo string     `json:"receiver_account_no" binding:"omitempty"`
+	Amount            string     `json:"amount" binding:"required"`
+	Description       string     `json:"description"`
+	Currency          string     `json:"currency" binding:"required,len=3"`
+	Frequency         string     `json:"frequency" binding:"required,oneof=daily weekly monthly"`
+	StartAt           time.Time  `json:"start_at" binding:"required"`
+	EndAt             *time.Time `json:"end_at"`
+	MaxRuns           *int32     `json:"max_runs" binding:"omitempty,min=1"`
+	FailurePolicy     string     `json:"failure_policy" binding:"omitempty,oneof=retry skip"`
+	MaxRetries        *int32     `json:"max_retries" binding:"omitempty,min=0,max=10"`
+	PIN               string     `json:"pin" binding:"required,numeric,min=4,max=6"`
+}
+
+// UpdateScheduleRequest needs the PIN only when it changes the amount.
+type UpdateScheduleRequest struct {
+	Amount        *string    `json:"amount"`
+	Description   *string    `json:"description"`
+	EndAt         *time.Time `json:"end_at"`
+	MaxRuns       *int32     `json:"max_runs" binding:"omitempty,min=1"`
+	FailurePolicy *string    `json:"failure_policy" binding:"omitempty,oneof=retry skip"`
+	MaxRetries    *int32     `json:"max_retries" binding:"omitempty,min=0,max=10"`
+	Status        *string    `json:"status" binding:"omitempty,oneof=active paused"` // pause or resume
+	PIN           string     `json:"pin" binding:"omitempty,numeric,min=4,max=6"`
+}
+
+type PaginationQuery struct {
+	Page     int32 `form:"page,default=1" binding:"min=1"`
+	PageSize int32 `form:"page_size,default=20" binding:"min=1,max=100"`
+}
@@ -3,30 +3,72 @@ package store
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"math/big"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/pkg/utils"
+	"github.com/shopspring/decimal"
 )
 
-// FakeTx implements store.Transaction
-type FakeTx struct{}
+// FakeTx implements store.Transaction. It holds the store's tx lock until it is
+// committed or rolled back, which stands in for Postgres row locks in tests.
+type FakeTx struct {
+	release sync.Once
+	unlock  func()
+}
 
 func (t *FakeTx) Commit(ctx context.Context) error {
+	t.release.Do(t.unlock)
 	return nil
 }
 
 func (t *FakeTx) Rollback(ctx context.Context) error {
+	t.release.Do(t.unlock)
 	return nil
 }
 
 // FakeStore implements Store interface for testing
 type FakeStore struct {
 	mu           sync.Mutex
+	txMu         sync.Mutex
 	transactions map[uuid.UUID]db.Transaction
 	wallets      map[uuid.UUID]db.GetWalletsAndLockByWalletIdsRow
+	holds        map[uuid.UUID]db.WalletHold
+	users        map[uuid.UUID]db.User
+	otps         []db.Otp
+	pins         map[uuid.UUID]db.TransactionPin
+	deposits     map[uuid.UUID]db.FixedDeposit
+	systemCodes  map[uuid.UUID]string
+	accruals     []db.InterestAccrual
+	ledgers      []db.Ledger
+	chart        []db.ChartOfAccount
+	feeSchedules map[uuid.UUID]db.FeeSchedule
+	feeTiers     []db.FeeScheduleTier
+	fxRates      []db.FxRate
+	fxQuotes     map[uuid.UUID]db.FxQuote
+	outbox       []db.OutboxEvent
+	webhooks     map[uuid.UUID]db.WebhookEndpoint
+	deliveries   []db.WebhookDelivery
+	attempts     []db.WebhookDeliveryAttempt
+	virtuals     map[uuid.UUID]db.VirtualAccount
+	fundings     map[uuid.UUID]db.FundingDeposit
+	destinations map[uuid.UUID]db.BankDestination
+	payouts      map[uuid.UUID]db.Payout
+	schedules    map[uuid.UUID]db.ScheduledTransfer
+	sessions     map[uuid.UUID]db.RefreshSession
+	scheduleRuns []db.ScheduledTransferRun
+	totpFactors  map[uuid.UUID]db.TotpFactor
+	recovery     []db.MfaRecoveryCode
+	audits       []db.AuditEvent
 }
 
 // constructor
@@ -34,12 +76,37 @@ func NewFakeStore() *FakeStore {
 	return &FakeStore{
 		transactions: make(map[uuid.UUID]db.Transaction),
 		wallets:      make(map[uuid.UUID]db.GetWalletsAndLockByWalletIdsRow),
+		holds:        make(map[uuid.UUID]db.WalletHold),
+		users:        make(map[uuid.UUID]db.User),
+		pins:         make(map[uuid.UUID]db.TransactionPin),
+		deposits:     make(map[uuid.UUID]db.FixedDeposit),
+		systemCodes:  make(map[uuid.UUID]string),
+		feeSchedules: make(map[uuid.UUID]db.FeeSchedule),
+		fxQuotes:     make(map[uuid.UUID]db.FxQuote),
+		webhooks:     make(map[uuid.UUID]db.WebhookEndpoint),
+		virtuals:     make(map[uuid.UUID]db.VirtualAccount),
+		fundings:     make(map[uuid.UUID]db.FundingDeposit),
+		destinations: make(map[uuid.UUID]db.BankDestination),
+		payouts:      make(map[uuid.UUID]db.Payout),
+		schedules:    make(map[uuid.UUID]db.ScheduledTransfer),
+		sessions:     make(map[uuid.UUID]db.RefreshSession),
+		totpFactors:  make(map[uuid.UUID]db.TotpFactor),
+		// mirrors the chart seeded by the chart_of_accounts migration
+		chart: []db.ChartOfAccount{
+			{Code: "customer_wallets", Name: "Customer wallets", AccountType: db.AccountTypeEnumLiability, NormalBalance: db.LedgerEntryTypeCredit},
+			{Code: "fees_revenue", Name: "Fees revenue", AccountType: db.AccountTypeEnumRevenue, NormalBalance: db.LedgerEntryTypeCredit},
+			{Code: "fx_position", Name: "FX position", AccountType: db.AccountTypeEnumAsset, NormalBalance: db.LedgerEntryTypeDebit},
+			{Code: "interest_expense", Name: "Interest expense", AccountType: db.AccountTypeEnumExpense, NormalBalance: db.LedgerEntryTypeDebit},
+			{Code: "settlement", Name: "Settlement", AccountType: db.AccountTypeEnumAsset, NormalBalance: db.LedgerEntryTypeDebit},
+			{Code: "suspense", Name: "Suspense", AccountType: db.AccountTypeEnumLiability, NormalBalance: db.LedgerEntryTypeCredit},
+		},
 	}
 }
 
-// Begin returns a fake transaction
+// Begin returns a fake transaction; transactions are serialised against each other
 func (f *FakeStore) Begin(ctx context.Context) (Transaction, error) {
-	return &FakeTx{}, nil
+	f.txMu.Lock()
+	return &FakeTx{unlock: f.txMu.Unlock}, nil
 }
 
 // WithTx just returns the fake store (for query calls)
@@ -65,6 +132,10 @@ func (f *FakeStore) GetWalletsAndLockByWalletIds(ctx context.Context, params db.
 		return nil, errors.New("wallet not found")
 	}
 
+	if params.ID == params.ID2 {
+		return []db.GetWalletsAndLockByWalletIdsRow{sender}, nil
+	}
+
 	// Compare UUIDs as byte slices to determine order
 	if bytes.Compare(sender.ID[:], receiver.ID[:]) < 0 {
 		return []db.GetWalletsAndLockByWalletIdsRow{sender, receiver}, nil
@@ -86,13 +157,18 @@ func (f *FakeStore) CreateTransaction(ctx context.Context, params db.CreateTrans
 	// create transaction
 	txID := uuid.New()
 	newTx := db.Transaction{
-		ID:               txID,
-		SenderWalletID:   params.SenderWalletID,
-		ReceiverWalletID: params.ReceiverWalletID,
-		Amount:           params.Amount,
-		Status:           params.Status,
-		Currency:         params.Currency,
-		IdempotencyKey:   params.IdempotencyKey,
+		ID:                  txID,
+		SenderWalletID:      params.SenderWalletID,
+		ReceiverWalletID:    params.ReceiverWalletID,
+		TransactionType:     params.TransactionType,
+		Amount:              params.Amount,
+		Description:         params.Description,
+		Status:              params.Status,
+		Currency:            params.Currency,
+		IdempotencyKey:      params.IdempotencyKey,
+		ParentTransactionID: params.ParentTransactionID,
+		Fee:                 params.Fee,
+		FeeScheduleID:       params.FeeScheduleID,
 	}
 
 	f.transactions[txID] = newTx
@@ -108,7 +184,7 @@ func (f *FakeStore) UpdateTransactionStatus(ctx context.Context, params db.Updat
 		return errors.New("transaction not found")
 	}
 
-	tx.Status = db.TransactionStatusEnumCompleted
+	tx.Status = params.Status
 	f.transactions[params.ID] = tx
 	return nil
 }
@@ -123,13 +199,30 @@ func (f *FakeStore) UpdateWalletBalance(ctx context.Context, params db.UpdateWal
 	}
 
 	wallet.Balance = params.Balance
+	wallet.AvailableBalance = params.AvailableBalance
+	f.wallets[params.ID] = wallet
+	return nil
+}
+
+func (f *FakeStore) UpdateWalletAvailableBalance(ctx context.Context, params db.UpdateWalletAvailableBalanceParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	wallet, ok := f.wallets[params.ID]
+	if !ok {
+		return errors.New("wallet not found")
+	}
+
+	wallet.AvailableBalance = params.AvailableBalance
 	f.wallets[params.ID] = wallet
 	return nil
 }
 
 func (f *FakeStore) CreateLedger(ctx context.Context, params db.CreateLedgerParams) (db.Ledger, error) {
-	// return fake ledger entry
-	return db.Ledger{
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	entry := db.Ledger{
 		ID:            uuid.New(),
 		WalletID:      params.WalletID,
 		TransactionID: params.TransactionID,
@@ -138,7 +231,10 @@ func (f *FakeStore) CreateLedger(ctx context.Context, params db.CreateLedgerPara
 		BalanceBefore: params.BalanceBefore,
 		BalanceAfter:  params.BalanceAfter,
 		Currency:      params.Currency,
-	}, nil
+		CreatedAt:     pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	}
+	f.ledgers = append(f.ledgers, entry)
+	return entry, nil
 }
 
 func (f *FakeStore) GetTransactionById(ctx context.Context, id uuid.UUID) (db.Transaction, error) {
@@ -152,6 +248,43 @@ func (f *FakeStore) GetTransactionById(ctx context.Context, id uuid.UUID) (db.Tr
 	return tx, nil
 }
 
+func (f *FakeStore) GetTransactionByIdForUpdate(ctx context.Context, id uuid.UUID) (db.Transaction, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	tx, ok := f.transactions[id]
+	if !ok {
+		return db.Transaction{}, pgx.ErrNoRows
+	}
+	return tx, nil
+}
+
+func (f *FakeStore) GetCompensatedAmountByParentId(ctx context.Context, parentTransactionID pgtype.UUID) (pgtype.Numeric, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	total := decimal.Zero
+	for _, tx := range f.transactions {
+		if tx.ParentTransactionID == parentTransactionID && tx.Status == db.TransactionStatusEnumCompleted {
+			total = total.Add(decimal.NewFromBigInt(tx.Amount.Int, tx.Amount.Exp))
+		}
+	}
+	return pgtype.Numeric{Int: total.Coefficient(), Exp: total.Exponent(), Valid: true}, nil
+}
+
+func (f *FakeStore) GetTransactionsByParentId(ctx context.Context, parentTransactionID pgtype.UUID) ([]db.Transaction, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result []db.Transaction
+	for _, tx := range f.transactions {
+		if tx.ParentTransactionID == parentTransactionID {
+			result = append(result, tx)
+		}
+	}
+	return result, nil
+}
+
 func (f *FakeStore) GetTransactionByIdempotencyKey(ctx context.Context, key string) (db.Transaction, error) {
 	f.mu.Lock()
 	defer f.mu.Unlock()
@@ -161,7 +294,7 @@ func (f *FakeStore) GetTransactionByIdempotencyKey(ctx context.Context, key stri
 			return tx, nil
 		}
 	}
-	return db.Transaction{}, errors.New("transaction not found")
+	return db.Transaction{}, pgx.ErrNoRows
 }
 
 func (f *FakeStore) GetWalletsByUserId(ctx context.Context, userID pgtype.UUID) ([]db.Wallet, error) {
@@ -173,11 +306,13 @@ func (f *FakeStore) GetWalletsByUserId(ctx context.Context, userID pgtype.UUID)
 		if w.UserID == userID {
 			// Map GetWalletsAndLockByWalletIdsRow to db.Wallet
 			result = append(result, db.Wallet{
-				ID:         w.ID,
-				UserID:     w.UserID,
-				Balance:    w.Balance,
-				Currency:   w.Currency,
-				WalletType: db.WalletTypeEnumSavings, // Default for fake
+				ID:               w.ID,
+				UserID:           w.UserID,
+				Balance:          w.Balance,
+				AvailableBalance: w.AvailableBalance,
+				Currency:         w.Currency,
+				WalletType:       w.WalletType,
+				MaturesAt:        w.MaturesAt,
 			})
 		}
 	}
@@ -185,7 +320,21 @@ func (f *FakeStore) GetWalletsByUserId(ctx context.Context, userID pgtype.UUID)
 }
 
 func (f *FakeStore) CreateOTP(ctx context.Context, arg db.CreateOTPParams) (db.Otp, error) {
-	return db.Otp{}, errors.New("not implemented")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	otp := db.Otp{
+		ID:        uuid.New(),
+		UserID:    arg.UserID,
+		Code:      arg.Code,
+		Purpose:   arg.Purpose,
+		ExpiresAt: arg.ExpiresAt,
+		Used:      arg.Used,
+		CreatedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		Reference: arg.Reference,
+	}
+	f.otps = append(f.otps, otp)
+	return otp, nil
 }
 
 func (f *FakeStore) CreateUser(ctx context.Context, arg db.CreateUserParams) (db.User, error) {
@@ -193,7 +342,25 @@ func (f *FakeStore) CreateUser(ctx context.Context, arg db.CreateUserParams) (db
 }
 
 func (f *FakeStore) CreateWallet(ctx context.Context, arg db.CreateWalletParams) (db.Wallet, error) {
-	return db.Wallet{}, errors.New("not implemented")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, w := range f.wallets {
+		if w.UserID == arg.UserID && w.WalletType == arg.WalletType && w.Currency == arg.Currency {
+			return db.Wallet{}, &pgconn.PgError{Code: "23505"}
+		}
+	}
+
+	wallet := db.GetWalletsAndLockByWalletIdsRow{
+		ID:               uuid.New(),
+		UserID:           arg.UserID,
+		Balance:          pgtype.Numeric{Int: big.NewInt(0), Valid: true},
+		AvailableBalance: pgtype.Numeric{Int: big.NewInt(0), Valid: true},
+		Currency:         arg.Currency,
+		WalletType:       arg.WalletType,
+	}
+	f.wallets[wallet.ID] = wallet
+	return f.toWallet(wallet), nil
 }
 
 func (f *FakeStore) DeleteUser(ctx context.Context, id uuid.UUID) error {
@@ -213,11 +380,24 @@ func (f *FakeStore) GetUserBalance(ctx context.Context, walletID uuid.UUID) (int
 }
 
 func (f *FakeStore) GetUserByEmail(ctx context.Context, email string) (db.User, error) {
-	return db.User{}, errors.New("not implemented")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, user := range f.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return db.User{}, pgx.ErrNoRows
 }
 
 func (f *FakeStore) GetUserByID(ctx context.Context, id uuid.UUID) (db.User, error) {
-	return db.User{}, errors.New("not implemented")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	user, ok := f.users[id]
+	if !ok {
+		return db.User{}, pgx.ErrNoRows
+	}
+	return user, nil
 }
 
 func (f *FakeStore) GetUserByUsername(ctx context.Context, username string) (db.User, error) {
@@ -225,11 +405,59 @@ func (f *FakeStore) GetUserByUsername(ctx context.Context, username string) (db.
 }
 
 func (f *FakeStore) GetWalletById(ctx context.Context, id uuid.UUID) (db.Wallet, error) {
-	return db.Wallet{}, errors.New("not implemented")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w, ok := f.wallets[id]
+	if !ok {
+		return db.Wallet{}, pgx.ErrNoRows
+	}
+	return f.toWallet(w), nil
+}
+
+// toWallet maps a stored wallet row to db.Wallet; callers hold f.mu.
+func (f *FakeStore) toWallet(w db.GetWalletsAndLockByWalletIdsRow) db.Wallet {
+	wallet := db.Wallet{
+		ID:               w.ID,
+		UserID:           w.UserID,
+		Balance:          w.Balance,
+		AvailableBalance: w.AvailableBalance,
+		Currency:         w.Currency,
+		WalletType:       w.WalletType,
+		MaturesAt:        w.MaturesAt,
+	}
+	if code, ok := f.systemCodes[w.ID]; ok {
+		wallet.SystemCode = pgtype.Text{String: code, Valid: true}
+	}
+	return wallet
 }
 
 func (f *FakeStore) UpdateUser(ctx context.Context, arg db.UpdateUserParams) (db.User, error) {
-	return db.User{}, errors.New("not implemented")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	user, ok := f.users[arg.ID]
+	if !ok {
+		return db.User{}, pgx.ErrNoRows
+	}
+	set := func(dst *string, v pgtype.Text) {
+		if v.Valid {
+			*dst = v.String
+		}
+	}
+	set(&user.FullName, arg.FullName)
+	set(&user.PhoneNumber, arg.PhoneNumber)
+	set(&user.Email, arg.Email)
+	set(&user.Username, arg.Username)
+	set(&user.AccountNo, arg.AccountNo)
+	set(&user.Nationality, arg.Nationality)
+	set(&user.CountryCode, arg.CountryCode)
+	if arg.PasswordHash.Valid {
+		user.Passwordhash = arg.PasswordHash.String
+		user.PasswordChangedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	}
+	user.UpdatedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	f.users[arg.ID] = user
+	return user, nil
 }
 
 func (f *FakeStore) GetUserByAccountNo(ctx context.Context, accountNo string) (db.User, error) {
@@ -240,9 +468,2443 @@ func (f *FakeStore) GetWalletByAccountNo(ctx context.Context, accountNo string)
 	return db.GetWalletByAccountNoRow{}, errors.New("not implemented")
 }
 
-// helper: populate fake wallets for testing
+func (f *FakeStore) CreateWalletHold(ctx context.Context, arg db.CreateWalletHoldParams) (db.WalletHold, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, h := range f.holds {
+		if h.IdempotencyKey == arg.IdempotencyKey {
+			return db.WalletHold{}, errors.New("hold with this idempotency key already exists")
+		}
+	}
+
+	hold := db.WalletHold{
+		ID:               uuid.New(),
+		WalletID:         arg.WalletID,
+		ReceiverWalletID: arg.ReceiverWalletID,
+		Amount:           arg.Amount,
+		CapturedAmount:   pgtype.Numeric{Int: big.NewInt(0), Valid: true},
+		Currency:         arg.Currency,
+		Status:           db.HoldStatusEnumActive,
+		Description:      arg.Description,
+		IdempotencyKey:   arg.IdempotencyKey,
+		ExpiresAt:        arg.ExpiresAt,
+		CreatedAt:        pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	}
+	f.holds[hold.ID] = hold
+	return hold, nil
+}
+
+func (f *FakeStore) GetWalletHoldForUpdate(ctx context.Context, id uuid.UUID) (db.WalletHold, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	hold, ok := f.holds[id]
+	if !ok {
+		return db.WalletHold{}, pgx.ErrNoRows
+	}
+	return hold, nil
+}
+
+func (f *FakeStore) GetWalletHoldByIdempotencyKey(ctx context.Context, key string) (db.WalletHold, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, h := range f.holds {
+		if h.IdempotencyKey == key {
+			return h, nil
+		}
+	}
+	return db.WalletHold{}, pgx.ErrNoRows
+}
+
+func (f *FakeStore) UpdateWalletHoldCapture(ctx context.Context, arg db.UpdateWalletHoldCaptureParams) (db.WalletHold, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	hold, ok := f.holds[arg.ID]
+	if !ok {
+		return db.WalletHold{}, pgx.ErrNoRows
+	}
+	hold.CapturedAmount = arg.CapturedAmount
+	hold.Status = arg.Status
+	f.holds[arg.ID] = hold
+	return hold, nil
+}
+
+func (f *FakeStore) UpdateWalletHoldStatus(ctx context.Context, arg db.UpdateWalletHoldStatusParams) (db.WalletHold, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	hold, ok := f.holds[arg.ID]
+	if !ok {
+		return db.WalletHold{}, pgx.ErrNoRows
+	}
+	hold.Status = arg.Status
+	f.holds[arg.ID] = hold
+	return hold, nil
+}
+
+func (f *FakeStore) UpdateWalletHoldExpiry(ctx context.Context, arg db.UpdateWalletHoldExpiryParams) (db.WalletHold, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	hold, ok := f.holds[arg.ID]
+	if !ok {
+		return db.WalletHold{}, pgx.ErrNoRows
+	}
+	hold.ExpiresAt = arg.ExpiresAt
+	f.holds[arg.ID] = hold
+	return hold, nil
+}
+
+func (f *FakeStore) GetExpiredActiveHolds(ctx context.Context, limit int32) ([]db.WalletHold, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	payoutHolds := make(map[uuid.UUID]bool)
+	for _, p := range f.payouts {
+		payoutHolds[p.HoldID] = true
+	}
+
+	var result []db.WalletHold
+	for _, h := range f.holds {
+		if h.Status == db.HoldStatusEnumActive && !h.ExpiresAt.Time.After(time.Now()) && !payoutHolds[h.ID] {
+			result = append(result, h)
+		}
+		if int32(len(result)) == limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+// helper: populate fake wallets for testing. Like the migration backfill, a wallet
+// without an available balance starts with available_balance = balance; wallets
+// without a type are savings wallets.
 func (f *FakeStore) AddFakeWallet(wallet db.GetWalletsAndLockByWalletIdsRow) {
 	f.mu.Lock()
 	defer f.mu.Unlock()
+	if !wallet.AvailableBalance.Valid {
+		wallet.AvailableBalance = wallet.Balance
+	}
+	if wallet.WalletType == "" {
+		wallet.WalletType = db.WalletTypeEnumSavings
+	}
 	f.wallets[wallet.ID] = wallet
+
+	// owners not registered through AddFakeUser are treated as verified users
+	if wallet.UserID.Valid {
+		ownerID := uuid.UUID(wallet.UserID.Bytes)
+		if _, ok := f.users[ownerID]; !ok {
+			f.users[ownerID] = db.User{
+				ID:              ownerID,
+				EmailVerifiedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+				KycTier:         1,
+			}
+		}
+	}
+}
+
+// helper: add a wallet of walletType owned by userID that holds balance, and return its id.
+// Tests use it for every wallet they do not need to shape field by field.
+func (f *FakeStore) AddFakeFundedWallet(userID uuid.UUID, walletType db.WalletTypeEnum, currency string, balance string) uuid.UUID {
+	wallet := db.GetWalletsAndLockByWalletIdsRow{
+		ID:         uuid.New(),
+		UserID:     pgtype.UUID{Bytes: userID, Valid: true},
+		Currency:   currency,
+		WalletType: walletType,
+	}
+	if err := wallet.Balance.Scan(balance); err != nil {
+		panic("fake wallet balance " + balance + ": " + err.Error())
+	}
+	f.AddFakeWallet(wallet)
+	return wallet.ID
+}
+
+// helper: a wallet's ledger balance, to two decimal places
+func (f *FakeStore) FakeBalance(walletID uuid.UUID) string {
+	balance, _ := f.FakeBalances(walletID)
+	return balance
+}
+
+// helper: a wallet's ledger and available balance, to two decimal places
+func (f *FakeStore) FakeBalances(walletID uuid.UUID) (string, string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	wallet := f.wallets[walletID]
+	return utils.NumericToDecimal(wallet.Balance).StringFixed(2), utils.NumericToDecimal(wallet.AvailableBalance).StringFixed(2)
+}
+
+// helper: add a fake user
+func (f *FakeStore) AddFakeUser(user db.User) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.users[user.ID] = user
+}
+
+// helper: set a transaction PIN for a user, stored as a bcrypt hash like the real service does
+func (f *FakeStore) AddFakeTransactionPin(userID uuid.UUID, pinHash string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pins[userID] = db.TransactionPin{UserID: userID, PinHash: pinHash}
+}
+
+// helper: return every recorded outbox event in sequence order
+func (f *FakeStore) OutboxEvents() []db.OutboxEvent {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]db.OutboxEvent(nil), f.outbox...)
+}
+
+// helper: move the outbox events up to sequence back in time, as if by had passed since they were recorded
+func (f *FakeStore) AgeFakeOutboxEvents(through int64, by time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i := range f.outbox {
+		if f.outbox[i].Sequence <= through {
+			f.outbox[i].CreatedAt.Time = f.outbox[i].CreatedAt.Time.Add(-by)
+		}
+	}
+}
+
+// helper: move a user's codes back in time, as if by had passed since they were issued
+func (f *FakeStore) AgeFakeOTPs(userID uuid.UUID, by time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i := range f.otps {
+		if f.otps[i].UserID == userID {
+			f.otps[i].CreatedAt.Time = f.otps[i].CreatedAt.Time.Add(-by)
+			f.otps[i].ExpiresAt.Time = f.otps[i].ExpiresAt.Time.Add(-by)
+		}
+	}
+}
+
+// helper: return every recorded audit event in insertion order
+func (f *FakeStore) AuditEvents() []db.AuditEvent {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]db.AuditEvent(nil), f.audits...)
+}
+
+// helper: make a schedule's next run due now, as if its retry delay had passed
+func (f *FakeStore) DueFakeSchedule(id uuid.UUID) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	schedule := f.schedules[id]
+	schedule.NextRunAt = pgtype.Timestamptz{Time: time.Now().Add(-time.Minute), Valid: true}
+	f.schedules[id] = schedule
+}
+
+// helper: expire a fake hold immediately for testing
+func (f *FakeStore) ExpireFakeHold(id uuid.UUID) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	hold := f.holds[id]
+	hold.ExpiresAt = pgtype.Timestamptz{Time: time.Now().Add(-time.Minute), Valid: true}
+	f.holds[id] = hold
+}
+
+func (f *FakeStore) AdvanceScheduledTransfer(ctx context.Context, arg db.AdvanceScheduledTransferParams) (db.ScheduledTransfer, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	schedule, ok := f.schedules[arg.ID]
+	if !ok {
+		return db.ScheduledTransfer{}, pgx.ErrNoRows
+	}
+	now := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	schedule.NextOccurrence = arg.NextOccurrence
+	schedule.NextRunAt = arg.NextRunAt
+	schedule.RetryCount = arg.RetryCount
+	schedule.RunsCount = arg.RunsCount
+	schedule.Status = arg.Status
+	schedule.LastError = arg.LastError
+	schedule.LastRunAt = now
+	schedule.UpdatedAt = now
+	f.schedules[arg.ID] = schedule
+	return schedule, nil
+}
+
+func (f *FakeStore) CreateScheduledTransfer(ctx context.Context, arg db.CreateScheduledTransferParams) (db.ScheduledTransfer, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	schedule := db.ScheduledTransfer{
+		ID:               uuid.New(),
+		UserID:           arg.UserID,
+		SenderWalletID:   arg.SenderWalletID,
+		ReceiverWalletID: arg.ReceiverWalletID,
+		Amount:           arg.Amount,
+		Currency:         arg.Currency,
+		Description:      arg.Description,
+		Frequency:        arg.Frequency,
+		StartAt:          arg.StartAt,
+		EndAt:            arg.EndAt,
+		MaxRuns:          arg.MaxRuns,
+		FailurePolicy:    arg.FailurePolicy,
+		MaxRetries:       arg.MaxRetries,
+		Status:           db.ScheduleStatusEnumActive,
+		NextOccurrence:   1,
+		NextRunAt:        arg.NextRunAt,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	f.schedules[schedule.ID] = schedule
+	return schedule, nil
+}
+
+func (f *FakeStore) CreateScheduledTransferRun(ctx context.Context, arg db.CreateScheduledTransferRunParams) (db.ScheduledTransferRun, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	run := db.ScheduledTransferRun{
+		ID:            uuid.New(),
+		ScheduleID:    arg.ScheduleID,
+		Occurrence:    arg.Occurrence,
+		Attempt:       arg.Attempt,
+		Status:        arg.Status,
+		TransactionID: arg.TransactionID,
+		Error:         arg.Error,
+		CreatedAt:     pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	}
+	f.scheduleRuns = append(f.scheduleRuns, run)
+	return run, nil
+}
+
+func (f *FakeStore) GetDueScheduledTransferIds(ctx context.Context, limit int32) ([]uuid.UUID, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var due []db.ScheduledTransfer
+	for _, schedule := range f.schedules {
+		if schedule.Status == db.ScheduleStatusEnumActive && !schedule.NextRunAt.Time.After(time.Now()) {
+			due = append(due, schedule)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextRunAt.Time.Before(due[j].NextRunAt.Time) })
+
+	var ids []uuid.UUID
+	for _, schedule := range due {
+		if int32(len(ids)) == limit {
+			break
+		}
+		ids = append(ids, schedule.ID)
+	}
+	return ids, nil
+}
+
+func (f *FakeStore) GetScheduledTransferById(ctx context.Context, id uuid.UUID) (db.ScheduledTransfer, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	schedule, ok := f.schedules[id]
+	if !ok {
+		return db.ScheduledTransfer{}, pgx.ErrNoRows
+	}
+	return schedule, nil
+}
+
+func (f *FakeStore) GetScheduledTransferForUpdate(ctx context.Context, id uuid.UUID) (db.ScheduledTransfer, error) {
+	return f.GetScheduledTransferById(ctx, id)
+}
+
+func (f *FakeStore) GetScheduledTransferRuns(ctx context.Context, arg db.GetScheduledTransferRunsParams) ([]db.ScheduledTransferRun, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// newest first, like the query
+	var runs []db.ScheduledTransferRun
+	for i := len(f.scheduleRuns) - 1; i >= 0; i-- {
+		if f.scheduleRuns[i].ScheduleID == arg.ScheduleID {
+			runs = append(runs, f.scheduleRuns[i])
+		}
+	}
+	if int(arg.Offset) >= len(runs) {
+		return nil, nil
+	}
+	runs = runs[arg.Offset:]
+	if len(runs) > int(arg.Limit) {
+		runs = runs[:arg.Limit]
+	}
+	return runs, nil
+}
+
+func (f *FakeStore) GetScheduledTransfersByUserId(ctx context.Context, userID uuid.UUID) ([]db.ScheduledTransfer, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result []db.ScheduledTransfer
+	for _, schedule := range f.schedules {
+		if schedule.UserID == userID {
+			result = append(result, schedule)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Time.After(result[j].CreatedAt.Time) })
+	return result, nil
+}
+
+func (f *FakeStore) UpdateScheduledTransfer(ctx context.Context, arg db.UpdateScheduledTransferParams) (db.ScheduledTransfer, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	schedule, ok := f.schedules[arg.ID]
+	if !ok {
+		return db.ScheduledTransfer{}, pgx.ErrNoRows
+	}
+	if arg.Amount.Valid {
+		schedule.Amount = arg.Amount
+	}
+	if arg.Description.Valid {
+		schedule.Description = arg.Description
+	}
+	if arg.EndAt.Valid {
+		schedule.EndAt = arg.EndAt
+	}
+	if arg.MaxRuns.Valid {
+		schedule.MaxRuns = arg.MaxRuns
+	}
+	if arg.FailurePolicy.Valid {
+		schedule.FailurePolicy = arg.FailurePolicy.ScheduleFailurePolicyEnum
+	}
+	if arg.MaxRetries.Valid {
+		schedule.MaxRetries = arg.MaxRetries.Int32
+	}
+	if arg.Status.Valid {
+		schedule.Status = arg.Status.ScheduleStatusEnum
+	}
+	if arg.NextRunAt.Valid {
+		schedule.NextRunAt = arg.NextRunAt
+	}
+	schedule.UpdatedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	f.schedules[arg.ID] = schedule
+	return schedule, nil
+}
+
+func (f *FakeStore) GetLatestOTPByPurpose(ctx context.Context, arg db.GetLatestOTPByPurposeParams) (db.Otp, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i := len(f.otps) - 1; i >= 0; i-- {
+		if f.otps[i].UserID == arg.UserID && f.otps[i].Purpose == arg.Purpose {
+			return f.otps[i], nil
+		}
+	}
+	return db.Otp{}, pgx.ErrNoRows
+}
+
+func (f *FakeStore) GetLatestOTPForUpdate(ctx context.Context, arg db.GetLatestOTPForUpdateParams) (db.Otp, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i := len(f.otps) - 1; i >= 0; i-- {
+		otp := f.otps[i]
+		if otp.UserID == arg.UserID && otp.Purpose == arg.Purpose && !otp.Used.Bool {
+			return otp, nil
+		}
+	}
+	return db.Otp{}, pgx.ErrNoRows
+}
+
+func (f *FakeStore) IncrementOTPAttempts(ctx context.Context, id uuid.UUID) (db.Otp, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i := range f.otps {
+		if f.otps[i].ID == id {
+			f.otps[i].Attempts++
+			return f.otps[i], nil
+		}
+	}
+	return db.Otp{}, pgx.ErrNoRows
+}
+
+func (f *FakeStore) InvalidateOTPs(ctx context.Context, arg db.InvalidateOTPsParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i := range f.otps {
+		if f.otps[i].UserID == arg.UserID && f.otps[i].Purpose == arg.Purpose {
+			f.otps[i].Used = pgtype.Bool{Bool: true, Valid: true}
+		}
+	}
+	return nil
+}
+
+func (f *FakeStore) MarkOTPUsed(ctx context.Context, id uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i := range f.otps {
+		if f.otps[i].ID == id {
+			f.otps[i].Used = pgtype.Bool{Bool: true, Valid: true}
+		}
+	}
+	return nil
+}
+
+func (f *FakeStore) MarkUserEmailVerified(ctx context.Context, id uuid.UUID) (db.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	user, ok := f.users[id]
+	if !ok {
+		return db.User{}, pgx.ErrNoRows
+	}
+	user.EmailVerifiedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	f.users[id] = user
+	return user, nil
+}
+
+func (f *FakeStore) CreateRefreshSession(ctx context.Context, arg db.CreateRefreshSessionParams) (db.RefreshSession, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	session := db.RefreshSession{
+		ID:         arg.ID,
+		UserID:     arg.UserID,
+		FamilyID:   arg.FamilyID,
+		ParentID:   arg.ParentID,
+		Device:     arg.Device,
+		IpAddress:  arg.IpAddress,
+		UserAgent:  arg.UserAgent,
+		SignedInAt: arg.SignedInAt,
+		ExpiresAt:  arg.ExpiresAt,
+		LastUsedAt: now,
+		CreatedAt:  now,
+	}
+	f.sessions[session.ID] = session
+	return session, nil
+}
+
+func (f *FakeStore) GetActiveRefreshSessionsByUserId(ctx context.Context, userID uuid.UUID) ([]db.RefreshSession, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result []db.RefreshSession
+	for _, session := range f.sessions {
+		if session.UserID == userID && !session.RevokedAt.Valid && !session.RotatedAt.Valid && session.ExpiresAt.Time.After(time.Now()) {
+			result = append(result, session)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].LastUsedAt.Time.After(result[j].LastUsedAt.Time) })
+	return result, nil
+}
+
+func (f *FakeStore) GetRefreshSessionForUpdate(ctx context.Context, id uuid.UUID) (db.RefreshSession, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	session, ok := f.sessions[id]
+	if !ok {
+		return db.RefreshSession{}, pgx.ErrNoRows
+	}
+	return session, nil
+}
+
+func (f *FakeStore) IsSessionFamilyActive(ctx context.Context, arg db.IsSessionFamilyActiveParams) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if changed := f.users[arg.UserID].PasswordChangedAt; changed.Valid && changed.Time.Truncate(time.Second).After(arg.IssuedAt.Time) {
+		return false, nil
+	}
+	for _, session := range f.sessions {
+		if session.FamilyID == arg.FamilyID && session.UserID == arg.UserID && !session.RevokedAt.Valid {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *FakeStore) RevokeRefreshSessionFamily(ctx context.Context, arg db.RevokeRefreshSessionFamilyParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for id, session := range f.sessions {
+		if session.FamilyID == arg.FamilyID && !session.RevokedAt.Valid {
+			session.RevokedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+			session.RevokedReason = arg.RevokedReason
+			f.sessions[id] = session
+		}
+	}
+	return nil
+}
+
+func (f *FakeStore) RevokeUserRefreshSessions(ctx context.Context, arg db.RevokeUserRefreshSessionsParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for id, session := range f.sessions {
+		if session.UserID == arg.UserID && !session.RevokedAt.Valid {
+			session.RevokedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+			session.RevokedReason = arg.RevokedReason
+			f.sessions[id] = session
+		}
+	}
+	return nil
+}
+
+func (f *FakeStore) RotateRefreshSession(ctx context.Context, id uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	session, ok := f.sessions[id]
+	if !ok {
+		return nil
+	}
+	now := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	session.RotatedAt = now
+	session.LastUsedAt = now
+	f.sessions[id] = session
+	return nil
+}
+
+func (f *FakeStore) GetOTPByIdForUpdate(ctx context.Context, id uuid.UUID) (db.Otp, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, otp := range f.otps {
+		if otp.ID == id {
+			return otp, nil
+		}
+	}
+	return db.Otp{}, pgx.ErrNoRows
+}
+
+func (f *FakeStore) CreateAuditEvent(ctx context.Context, arg db.CreateAuditEventParams) (db.AuditEvent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	event := db.AuditEvent{
+		ID:        uuid.New(),
+		UserID:    arg.UserID,
+		Event:     arg.Event,
+		IpAddress: arg.IpAddress,
+		UserAgent: arg.UserAgent,
+		Metadata:  arg.Metadata,
+		CreatedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	}
+	f.audits = append(f.audits, event)
+	return event, nil
+}
+
+func (f *FakeStore) ConfirmTOTPFactor(ctx context.Context, arg db.ConfirmTOTPFactorParams) (db.TotpFactor, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	factor, ok := f.totpFactors[arg.UserID]
+	if !ok {
+		return db.TotpFactor{}, pgx.ErrNoRows
+	}
+	now := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	factor.ConfirmedAt = now
+	factor.LastUsedStep = arg.LastUsedStep
+	factor.UpdatedAt = now
+	f.totpFactors[arg.UserID] = factor
+	return factor, nil
+}
+
+func (f *FakeStore) CountUnusedRecoveryCodes(ctx context.Context, userID uuid.UUID) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var count int64
+	for _, code := range f.recovery {
+		if code.UserID == userID && !code.UsedAt.Valid {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (f *FakeStore) CreateRecoveryCode(ctx context.Context, arg db.CreateRecoveryCodeParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.recovery = append(f.recovery, db.MfaRecoveryCode{
+		ID:        uuid.New(),
+		UserID:    arg.UserID,
+		CodeHash:  arg.CodeHash,
+		CreatedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	})
+	return nil
+}
+
+func (f *FakeStore) DeleteRecoveryCodes(ctx context.Context, userID uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	kept := f.recovery[:0]
+	for _, code := range f.recovery {
+		if code.UserID != userID {
+			kept = append(kept, code)
+		}
+	}
+	f.recovery = kept
+	return nil
+}
+
+func (f *FakeStore) DeleteTOTPFactor(ctx context.Context, userID uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.totpFactors, userID)
+	return nil
+}
+
+func (f *FakeStore) GetTOTPFactor(ctx context.Context, userID uuid.UUID) (db.TotpFactor, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	factor, ok := f.totpFactors[userID]
+	if !ok {
+		return db.TotpFactor{}, pgx.ErrNoRows
+	}
+	return factor, nil
+}
+
+func (f *FakeStore) GetTOTPFactorForUpdate(ctx context.Context, userID uuid.UUID) (db.TotpFactor, error) {
+	return f.GetTOTPFactor(ctx, userID)
+}
+
+func (f *FakeStore) UpdateTOTPLastUsedStep(ctx context.Context, arg db.UpdateTOTPLastUsedStepParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	factor, ok := f.totpFactors[arg.UserID]
+	if !ok {
+		return nil
+	}
+	factor.LastUsedStep = arg.LastUsedStep
+	factor.UpdatedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	f.totpFactors[arg.UserID] = factor
+	return nil
+}
+
+func (f *FakeStore) UpsertPendingTOTPFactor(ctx context.Context, arg db.UpsertPendingTOTPFactorParams) (db.TotpFactor, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	factor, ok := f.totpFactors[arg.UserID]
+	if !ok {
+		factor = db.TotpFactor{UserID: arg.UserID, CreatedAt: now}
+	}
+	factor.Secret = arg.Secret
+	factor.ConfirmedAt = pgtype.Timestamptz{}
+	factor.LastUsedStep = 0
+	factor.UpdatedAt = now
+	f.totpFactors[arg.UserID] = factor
+	return factor, nil
+}
+
+func (f *FakeStore) UseRecoveryCode(ctx context.Context, arg db.UseRecoveryCodeParams) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i, code := range f.recovery {
+		if code.UserID == arg.UserID && code.CodeHash == arg.CodeHash && !code.UsedAt.Valid {
+			f.recovery[i].UsedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func (f *FakeStore) CountCompletedTransfersToWallet(ctx context.Context, arg db.CountCompletedTransfersToWalletParams) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var count int64
+	for _, tx := range f.transactions {
+		if tx.ReceiverWalletID != arg.ReceiverWalletID || tx.Status != db.TransactionStatusEnumCompleted || !tx.SenderWalletID.Valid {
+			continue
+		}
+		if sender, ok := f.wallets[uuid.UUID(tx.SenderWalletID.Bytes)]; ok && sender.UserID == arg.UserID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (f *FakeStore) GetSessionFamilySignedInAt(ctx context.Context, familyID uuid.UUID) (pgtype.Timestamptz, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var first *db.RefreshSession
+	for _, session := range f.sessions {
+		if session.FamilyID == familyID && (first == nil || session.CreatedAt.Time.Before(first.CreatedAt.Time)) {
+			session := session
+			first = &session
+		}
+	}
+	if first == nil {
+		return pgtype.Timestamptz{}, pgx.ErrNoRows
+	}
+	return first.SignedInAt, nil
+}
+
+func (f *FakeStore) GetTransactionPin(ctx context.Context, userID uuid.UUID) (db.TransactionPin, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	pin, ok := f.pins[userID]
+	if !ok {
+		return db.TransactionPin{}, pgx.ErrNoRows
+	}
+	return pin, nil
+}
+
+func (f *FakeStore) GetTransactionPinForUpdate(ctx context.Context, userID uuid.UUID) (db.TransactionPin, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	pin, ok := f.pins[userID]
+	if !ok {
+		return db.TransactionPin{}, pgx.ErrNoRows
+	}
+	return pin, nil
+}
+
+func (f *FakeStore) UpdateTransactionPinAttempts(ctx context.Context, arg db.UpdateTransactionPinAttemptsParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	pin, ok := f.pins[arg.UserID]
+	if !ok {
+		return pgx.ErrNoRows
+	}
+	pin.FailedAttempts = arg.FailedAttempts
+	pin.LockedUntil = arg.LockedUntil
+	pin.UpdatedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	f.pins[arg.UserID] = pin
+	return nil
+}
+
+func (f *FakeStore) UpsertTransactionPin(ctx context.Context, arg db.UpsertTransactionPinParams) (db.TransactionPin, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	pin, ok := f.pins[arg.UserID]
+	if !ok {
+		pin = db.TransactionPin{UserID: arg.UserID, CreatedAt: now}
+	}
+	pin.PinHash = arg.PinHash
+	pin.FailedAttempts = 0
+	pin.LockedUntil = pgtype.Timestamptz{}
+	pin.UpdatedAt = now
+	f.pins[arg.UserID] = pin
+	return pin, nil
+}
+
+func (f *FakeStore) CloseFixedDeposit(ctx context.Context, arg db.CloseFixedDepositParams) (db.FixedDeposit, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	deposit, ok := f.deposits[arg.ID]
+	if !ok {
+		return db.FixedDeposit{}, pgx.ErrNoRows
+	}
+	deposit.Status = arg.Status
+	deposit.Penalty = arg.Penalty
+	deposit.ClosedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	f.deposits[arg.ID] = deposit
+	return deposit, nil
+}
+
+func (f *FakeStore) CreateFixedDeposit(ctx context.Context, arg db.CreateFixedDepositParams) (db.FixedDeposit, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, d := range f.deposits {
+		if d.IdempotencyKey == arg.IdempotencyKey {
+			return db.FixedDeposit{}, &pgconn.PgError{Code: "23505"}
+		}
+	}
+
+	now := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	deposit := db.FixedDeposit{
+		ID:              uuid.New(),
+		UserID:          arg.UserID,
+		WalletID:        arg.WalletID,
+		PayoutWalletID:  arg.PayoutWalletID,
+		ParentDepositID: arg.ParentDepositID,
+		Principal:       arg.Principal,
+		AccruedInterest: utils.DecimalToNumeric(decimal.Zero),
+		AnnualRate:      arg.AnnualRate,
+		TenorDays:       arg.TenorDays,
+		Currency:        arg.Currency,
+		MaturityAction:  arg.MaturityAction,
+		Status:          db.DepositStatusEnumActive,
+		StartDate:       arg.StartDate,
+		MaturityDate:    arg.MaturityDate,
+		AccruedThrough:  arg.AccruedThrough,
+		Penalty:         utils.DecimalToNumeric(decimal.Zero),
+		IdempotencyKey:  arg.IdempotencyKey,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	f.deposits[deposit.ID] = deposit
+	return deposit, nil
+}
+
+func (f *FakeStore) CreateSystemWallet(ctx context.Context, arg db.CreateSystemWalletParams) (db.Wallet, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for id, code := range f.systemCodes {
+		if code == arg.SystemCode.String && f.wallets[id].Currency == arg.Currency {
+			return db.Wallet{}, pgx.ErrNoRows
+		}
+	}
+
+	w := db.GetWalletsAndLockByWalletIdsRow{
+		ID:               uuid.New(),
+		Balance:          utils.DecimalToNumeric(decimal.Zero),
+		AvailableBalance: utils.DecimalToNumeric(decimal.Zero),
+		Currency:         arg.Currency,
+		WalletType:       db.WalletTypeEnumSystem,
+	}
+	f.wallets[w.ID] = w
+	f.systemCodes[w.ID] = arg.SystemCode.String
+	return f.toWallet(w), nil
+}
+
+func (f *FakeStore) GetFixedDepositById(ctx context.Context, id uuid.UUID) (db.FixedDeposit, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	deposit, ok := f.deposits[id]
+	if !ok {
+		return db.FixedDeposit{}, pgx.ErrNoRows
+	}
+	return deposit, nil
+}
+
+func (f *FakeStore) GetFixedDepositByIdempotencyKey(ctx context.Context, idempotencyKey string) (db.FixedDeposit, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, d := range f.deposits {
+		if d.IdempotencyKey == idempotencyKey {
+			return d, nil
+		}
+	}
+	return db.FixedDeposit{}, pgx.ErrNoRows
+}
+
+func (f *FakeStore) GetFixedDepositForUpdate(ctx context.Context, id uuid.UUID) (db.FixedDeposit, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	deposit, ok := f.deposits[id]
+	if !ok {
+		return db.FixedDeposit{}, pgx.ErrNoRows
+	}
+	return deposit, nil
+}
+
+func (f *FakeStore) GetFixedDepositIdsDueForAccrual(ctx context.Context, arg db.GetFixedDepositIdsDueForAccrualParams) ([]uuid.UUID, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result []uuid.UUID
+	for _, d := range f.deposits {
+		if int32(len(result)) == arg.BatchSize {
+			break
+		}
+		if d.Status == db.DepositStatusEnumActive && d.AccruedThrough.Time.Before(arg.AsOf.Time) {
+			result = append(result, d.ID)
+		}
+	}
+	return result, nil
+}
+
+func (f *FakeStore) GetFixedDepositsByUserId(ctx context.Context, userID uuid.UUID) ([]db.FixedDeposit, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result []db.FixedDeposit
+	for _, d := range f.deposits {
+		if d.UserID == userID {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+func (f *FakeStore) GetSystemWallet(ctx context.Context, arg db.GetSystemWalletParams) (db.Wallet, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for id, code := range f.systemCodes {
+		if w := f.wallets[id]; code == arg.SystemCode.String && w.Currency == arg.Currency {
+			return f.toWallet(w), nil
+		}
+	}
+	return db.Wallet{}, pgx.ErrNoRows
+}
+
+func (f *FakeStore) GetWalletByUserIdAndType(ctx context.Context, arg db.GetWalletByUserIdAndTypeParams) (db.Wallet, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, w := range f.wallets {
+		if w.UserID == arg.UserID && w.WalletType == arg.WalletType && w.Currency == arg.Currency {
+			return f.toWallet(w), nil
+		}
+	}
+	return db.Wallet{}, pgx.ErrNoRows
+}
+
+func (f *FakeStore) UpdateFixedDepositAccrual(ctx context.Context, arg db.UpdateFixedDepositAccrualParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	deposit, ok := f.deposits[arg.ID]
+	if !ok {
+		return pgx.ErrNoRows
+	}
+	deposit.AccruedInterest = arg.AccruedInterest
+	deposit.AccruedThrough = arg.AccruedThrough
+	f.deposits[arg.ID] = deposit
+	return nil
+}
+
+// RefreshWalletMaturity locks the wallet until its latest active deposit matures, like the query.
+func (f *FakeStore) RefreshWalletMaturity(ctx context.Context, walletID uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	wallet, ok := f.wallets[walletID]
+	if !ok {
+		return nil
+	}
+
+	wallet.MaturesAt = pgtype.Timestamptz{}
+	for _, d := range f.deposits {
+		if d.WalletID != walletID || d.Status != db.DepositStatusEnumActive {
+			continue
+		}
+		if !wallet.MaturesAt.Valid || d.MaturityDate.Time.After(wallet.MaturesAt.Time) {
+			wallet.MaturesAt = pgtype.Timestamptz{Time: d.MaturityDate.Time, Valid: true}
+		}
+	}
+	f.wallets[walletID] = wallet
+	return nil
+}
+
+func (f *FakeStore) CreateInterestAccrual(ctx context.Context, arg db.CreateInterestAccrualParams) (db.InterestAccrual, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, a := range f.accruals {
+		if a.WalletID == arg.WalletID && a.AccrualDate.Time.Equal(arg.AccrualDate.Time) {
+			return db.InterestAccrual{}, pgx.ErrNoRows
+		}
+	}
+
+	accrual := db.InterestAccrual{
+		ID:          uuid.New(),
+		WalletID:    arg.WalletID,
+		AccrualDate: arg.AccrualDate,
+		Balance:     arg.Balance,
+		AnnualRate:  arg.AnnualRate,
+		DayCount:    arg.DayCount,
+		Amount:      arg.Amount,
+		Currency:    arg.Currency,
+		CreatedAt:   pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	}
+	f.accruals = append(f.accruals, accrual)
+	return accrual, nil
+}
+
+func (f *FakeStore) GetInterestAccrualsByDate(ctx context.Context, accrualDate pgtype.Date) ([]db.InterestAccrual, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result []db.InterestAccrual
+	for _, a := range f.accruals {
+		if a.AccrualDate.Time.Equal(accrualDate.Time) {
+			result = append(result, a)
+		}
+	}
+	return result, nil
+}
+
+func (f *FakeStore) GetInterestAccrualsByWalletId(ctx context.Context, arg db.GetInterestAccrualsByWalletIdParams) ([]db.InterestAccrual, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result []db.InterestAccrual
+	for i := len(f.accruals) - 1; i >= 0 && int32(len(result)) < arg.Limit; i-- {
+		if f.accruals[i].WalletID == arg.WalletID {
+			result = append(result, f.accruals[i])
+		}
+	}
+	return result, nil
+}
+
+func (f *FakeStore) GetSavingsWalletIdsDueForAccrual(ctx context.Context, arg db.GetSavingsWalletIdsDueForAccrualParams) ([]uuid.UUID, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	accrued := make(map[uuid.UUID]bool)
+	for _, a := range f.accruals {
+		if a.AccrualDate.Time.Equal(arg.AccrualDate.Time) {
+			accrued[a.WalletID] = true
+		}
+	}
+
+	// wallets carry no creation time here, so CreatedBefore is not applied
+	var result []uuid.UUID
+	for _, w := range f.savingsWallets() {
+		if int32(len(result)) == arg.BatchSize {
+			break
+		}
+		if !accrued[w.ID] {
+			result = append(result, w.ID)
+		}
+	}
+	return result, nil
+}
+
+func (f *FakeStore) GetWalletIdsWithUncapitalisedInterest(ctx context.Context, arg db.GetWalletIdsWithUncapitalisedInterestParams) ([]uuid.UUID, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	seen := make(map[uuid.UUID]bool)
+	var result []uuid.UUID
+	for _, a := range f.accruals {
+		if int32(len(result)) == arg.BatchSize {
+			break
+		}
+		if !a.CapitalisedAt.Valid && a.AccrualDate.Time.Before(arg.Before.Time) && !seen[a.WalletID] {
+			seen[a.WalletID] = true
+			result = append(result, a.WalletID)
+		}
+	}
+	return result, nil
+}
+
+func (f *FakeStore) ListSavingsWalletsForInterest(ctx context.Context, arg db.ListSavingsWalletsForInterestParams) ([]db.ListSavingsWalletsForInterestRow, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result []db.ListSavingsWalletsForInterestRow
+	for _, w := range f.savingsWallets() {
+		if int32(len(result)) == arg.BatchSize {
+			break
+		}
+		if bytes.Compare(w.ID[:], arg.AfterID[:]) > 0 {
+			result = append(result, db.ListSavingsWalletsForInterestRow{ID: w.ID, Balance: w.Balance, Currency: w.Currency})
+		}
+	}
+	return result, nil
+}
+
+func (f *FakeStore) MarkInterestCapitalised(ctx context.Context, arg db.MarkInterestCapitalisedParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i, a := range f.accruals {
+		if a.WalletID == arg.WalletID && !a.CapitalisedAt.Valid && a.AccrualDate.Time.Before(arg.Before.Time) {
+			f.accruals[i].TransactionID = arg.TransactionID
+			f.accruals[i].CapitalisedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+		}
+	}
+	return nil
+}
+
+func (f *FakeStore) SumUncapitalisedInterest(ctx context.Context, arg db.SumUncapitalisedInterestParams) (pgtype.Numeric, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	total := decimal.Zero
+	for _, a := range f.accruals {
+		if a.WalletID == arg.WalletID && !a.CapitalisedAt.Valid && a.AccrualDate.Time.Before(arg.Before.Time) {
+			total = total.Add(utils.NumericToDecimal(a.Amount))
+		}
+	}
+	return utils.DecimalToNumeric(total), nil
+}
+
+// savingsWallets returns savings wallets with a positive balance in id order; callers hold f.mu.
+func (f *FakeStore) savingsWallets() []db.GetWalletsAndLockByWalletIdsRow {
+	var result []db.GetWalletsAndLockByWalletIdsRow
+	for _, w := range f.wallets {
+		if w.WalletType == db.WalletTypeEnumSavings && utils.NumericToDecimal(w.Balance).IsPositive() {
+			result = append(result, w)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return bytes.Compare(result[i].ID[:], result[j].ID[:]) < 0 })
+	return result
+}
+
+func (f *FakeStore) GetChartOfAccounts(ctx context.Context) ([]db.ChartOfAccount, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]db.ChartOfAccount(nil), f.chart...), nil
+}
+
+func (f *FakeStore) GetSystemWallets(ctx context.Context) ([]db.Wallet, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result []db.Wallet
+	for id := range f.systemCodes {
+		result = append(result, f.toWallet(f.wallets[id]))
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].SystemCode.String != result[j].SystemCode.String {
+			return result[i].SystemCode.String < result[j].SystemCode.String
+		}
+		return result[i].Currency < result[j].Currency
+	})
+	return result, nil
+}
+
+func (f *FakeStore) GetTrialBalance(ctx context.Context, asOf pgtype.Timestamptz) ([]db.GetTrialBalanceRow, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	type key struct{ code, currency string }
+	totals := make(map[key][2]decimal.Decimal)
+	for _, entry := range f.ledgers {
+		if !entry.CreatedAt.Time.Before(asOf.Time) {
+			continue
+		}
+		k := key{code: "customer_wallets", currency: entry.Currency}
+		if code, ok := f.systemCodes[entry.WalletID]; ok {
+			k.code = code
+		}
+		sums := totals[k]
+		if entry.EntryType == db.LedgerEntryTypeDebit {
+			sums[0] = sums[0].Add(utils.NumericToDecimal(entry.Amount))
+		} else {
+			sums[1] = sums[1].Add(utils.NumericToDecimal(entry.Amount))
+		}
+		totals[k] = sums
+	}
+
+	result := make([]db.GetTrialBalanceRow, 0, len(totals))
+	for k, sums := range totals {
+		result = append(result, db.GetTrialBalanceRow{
+			AccountCode: k.code,
+			Currency:    k.currency,
+			Debits:      utils.DecimalToNumeric(sums[0]),
+			Credits:     utils.DecimalToNumeric(sums[1]),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].AccountCode != result[j].AccountCode {
+			return result[i].AccountCode < result[j].AccountCode
+		}
+		return result[i].Currency < result[j].Currency
+	})
+	return result, nil
+}
+
+func (f *FakeStore) GetWalletsForUpdate(ctx context.Context, ids []uuid.UUID) ([]db.GetWalletsForUpdateRow, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result []db.GetWalletsForUpdateRow
+	for _, id := range ids {
+		w, ok := f.wallets[id]
+		if !ok {
+			continue
+		}
+		result = append(result, db.GetWalletsForUpdateRow{
+			ID:               w.ID,
+			UserID:           w.UserID,
+			Balance:          w.Balance,
+			AvailableBalance: w.AvailableBalance,
+			Currency:         w.Currency,
+			WalletType:       w.WalletType,
+			SystemCode:       f.toWallet(w).SystemCode,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return bytes.Compare(result[i].ID[:], result[j].ID[:]) < 0 })
+	return result, nil
+}
+
+func (f *FakeStore) CreateFeeSchedule(ctx context.Context, arg db.CreateFeeScheduleParams) (db.FeeSchedule, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, existing := range f.feeSchedules {
+		if existing.Active && existing.TransactionType == arg.TransactionType && existing.Currency == arg.Currency &&
+			existing.KycTier == arg.KycTier {
+			return db.FeeSchedule{}, &pgconn.PgError{Code: "23505"}
+		}
+	}
+
+	schedule := db.FeeSchedule{
+		ID:              uuid.New(),
+		TransactionType: arg.TransactionType,
+		Currency:        arg.Currency,
+		KycTier:         arg.KycTier,
+		FeeType:         arg.FeeType,
+		FlatAmount:      arg.FlatAmount,
+		Rate:            arg.Rate,
+		MinFee:          arg.MinFee,
+		MaxFee:          arg.MaxFee,
+		Active:          true,
+		CreatedAt:       pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	}
+	f.feeSchedules[schedule.ID] = schedule
+	return schedule, nil
+}
+
+func (f *FakeStore) CreateFeeScheduleTier(ctx context.Context, arg db.CreateFeeScheduleTierParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.feeTiers = append(f.feeTiers, db.FeeScheduleTier{
+		ScheduleID: arg.ScheduleID,
+		Floor:      arg.Floor,
+		FlatAmount: arg.FlatAmount,
+		Rate:       arg.Rate,
+	})
+	return nil
+}
+
+func (f *FakeStore) DeactivateFeeSchedule(ctx context.Context, id uuid.UUID) (db.FeeSchedule, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	schedule, ok := f.feeSchedules[id]
+	if !ok || !schedule.Active {
+		return db.FeeSchedule{}, pgx.ErrNoRows
+	}
+	schedule.Active = false
+	schedule.DeactivatedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	f.feeSchedules[id] = schedule
+	return schedule, nil
+}
+
+func (f *FakeStore) FindFeeSchedule(ctx context.Context, arg db.FindFeeScheduleParams) (db.FeeSchedule, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var found *db.FeeSchedule
+	for _, schedule := range f.feeSchedules {
+		if !schedule.Active || schedule.TransactionType != arg.TransactionType || schedule.Currency != arg.Currency {
+			continue
+		}
+		if schedule.KycTier.Valid && schedule.KycTier.Int16 != arg.KycTier {
+			continue
+		}
+		if found == nil || schedule.KycTier.Valid {
+			found = &schedule
+		}
+	}
+	if found == nil {
+		return db.FeeSchedule{}, pgx.ErrNoRows
+	}
+	return *found, nil
+}
+
+func (f *FakeStore) GetActiveFeeSchedules(ctx context.Context) ([]db.FeeSchedule, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result []db.FeeSchedule
+	for _, schedule := range f.feeSchedules {
+		if schedule.Active {
+			result = append(result, schedule)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].TransactionType != result[j].TransactionType {
+			return result[i].TransactionType < result[j].TransactionType
+		}
+		if result[i].Currency != result[j].Currency {
+			return result[i].Currency < result[j].Currency
+		}
+		return result[i].KycTier.Int16 < result[j].KycTier.Int16
+	})
+	return result, nil
+}
+
+func (f *FakeStore) GetFeeScheduleById(ctx context.Context, id uuid.UUID) (db.FeeSchedule, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	schedule, ok := f.feeSchedules[id]
+	if !ok {
+		return db.FeeSchedule{}, pgx.ErrNoRows
+	}
+	return schedule, nil
+}
+
+func (f *FakeStore) GetFeeScheduleTiers(ctx context.Context, scheduleID uuid.UUID) ([]db.FeeScheduleTier, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result []db.FeeScheduleTier
+	for _, tier := range f.feeTiers {
+		if tier.ScheduleID == scheduleID {
+			result = append(result, tier)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return utils.NumericToDecimal(result[i].Floor).LessThan(utils.NumericToDecimal(result[j].Floor))
+	})
+	return result, nil
+}
+
+func (f *FakeStore) DeactivateActiveFeeSchedule(ctx context.Context, arg db.DeactivateActiveFeeScheduleParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for id, schedule := range f.feeSchedules {
+		if schedule.Active && schedule.TransactionType == arg.TransactionType && schedule.Currency == arg.Currency &&
+			schedule.KycTier.Int16 == arg.KycTier {
+			schedule.Active = false
+			schedule.DeactivatedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+			f.feeSchedules[id] = schedule
+		}
+	}
+	return nil
+}
+
+func (f *FakeStore) CreateFxQuote(ctx context.Context, arg db.CreateFxQuoteParams) (db.FxQuote, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	quote := db.FxQuote{
+		ID:           uuid.New(),
+		UserID:       arg.UserID,
+		FromWalletID: arg.FromWalletID,
+		ToWalletID:   arg.ToWalletID,
+		FromCurrency: arg.FromCurrency,
+		ToCurrency:   arg.ToCurrency,
+		SellAmount:   arg.SellAmount,
+		BuyAmount:    arg.BuyAmount,
+		MidRate:      arg.MidRate,
+		Rate:         arg.Rate,
+		Spread:       arg.Spread,
+		ExpiresAt:    arg.ExpiresAt,
+		CreatedAt:    pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	}
+	f.fxQuotes[quote.ID] = quote
+	return quote, nil
+}
+
+func (f *FakeStore) CreateFxRate(ctx context.Context, arg db.CreateFxRateParams) (db.FxRate, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	rate := db.FxRate{
+		ID:            uuid.New(),
+		BaseCurrency:  arg.BaseCurrency,
+		QuoteCurrency: arg.QuoteCurrency,
+		Rate:          arg.Rate,
+		Source:        arg.Source,
+		CreatedAt:     pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	}
+	f.fxRates = append(f.fxRates, rate)
+	return rate, nil
+}
+
+func (f *FakeStore) GetFxQuoteForUpdate(ctx context.Context, id uuid.UUID) (db.FxQuote, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	quote, ok := f.fxQuotes[id]
+	if !ok {
+		return db.FxQuote{}, pgx.ErrNoRows
+	}
+	return quote, nil
+}
+
+func (f *FakeStore) GetLatestFxRate(ctx context.Context, arg db.GetLatestFxRateParams) (db.FxRate, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// rates are appended in time order, so the last match is the newest
+	for i := len(f.fxRates) - 1; i >= 0; i-- {
+		if f.fxRates[i].BaseCurrency == arg.BaseCurrency && f.fxRates[i].QuoteCurrency == arg.QuoteCurrency {
+			return f.fxRates[i], nil
+		}
+	}
+	return db.FxRate{}, pgx.ErrNoRows
+}
+
+func (f *FakeStore) GetLatestFxRates(ctx context.Context) ([]db.FxRate, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	latest := make(map[[2]string]db.FxRate)
+	for _, rate := range f.fxRates {
+		latest[[2]string{rate.BaseCurrency, rate.QuoteCurrency}] = rate
+	}
+	result := make([]db.FxRate, 0, len(latest))
+	for _, rate := range latest {
+		result = append(result, rate)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].BaseCurrency != result[j].BaseCurrency {
+			return result[i].BaseCurrency < result[j].BaseCurrency
+		}
+		return result[i].QuoteCurrency < result[j].QuoteCurrency
+	})
+	return result, nil
+}
+
+func (f *FakeStore) GetWalletByAccountNoAndCurrency(ctx context.Context, arg db.GetWalletByAccountNoAndCurrencyParams) (db.GetWalletByAccountNoAndCurrencyRow, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, user := range f.users {
+		if user.AccountNo == "" || user.AccountNo != arg.AccountNo {
+			continue
+		}
+		var found *db.GetWalletsAndLockByWalletIdsRow
+		for _, w := range f.wallets {
+			if w.UserID.Valid && uuid.UUID(w.UserID.Bytes) == user.ID && w.Currency == arg.Currency {
+				if found == nil || w.WalletType == db.WalletTypeEnumSavings {
+					found = &w
+				}
+			}
+		}
+		if found != nil {
+			return db.GetWalletByAccountNoAndCurrencyRow{
+				WalletID:  found.ID,
+				UserID:    found.UserID,
+				Balance:   found.Balance,
+				Currency:  found.Currency,
+				FullName:  user.FullName,
+				Email:     user.Email,
+				AccountNo: user.AccountNo,
+			}, nil
+		}
+	}
+	return db.GetWalletByAccountNoAndCurrencyRow{}, pgx.ErrNoRows
+}
+
+func (f *FakeStore) MarkFxQuoteConverted(ctx context.Context, arg db.MarkFxQuoteConvertedParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	quote, ok := f.fxQuotes[arg.ID]
+	if !ok {
+		return pgx.ErrNoRows
+	}
+	quote.TransactionID = arg.TransactionID
+	f.fxQuotes[arg.ID] = quote
+	return nil
+}
+
+func (f *FakeStore) CreateOutboxEvent(ctx context.Context, arg db.CreateOutboxEventParams) (db.OutboxEvent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	event := db.OutboxEvent{
+		ID:             uuid.New(),
+		Sequence:       int64(len(f.outbox) + 1),
+		AggregateType:  arg.AggregateType,
+		AggregateID:    arg.AggregateID,
+		EventType:      arg.EventType,
+		Payload:        arg.Payload,
+		NextAttemptAt:  now,
+		PublishedSinks: []string{},
+		CreatedAt:      now,
+	}
+	f.outbox = append(f.outbox, event)
+	return event, nil
+}
+
+func (f *FakeStore) ClaimRelayableOutboxEvents(ctx context.Context, arg db.ClaimRelayableOutboxEventsParams) ([]db.OutboxEvent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// the outbox is in sequence order, so the first unpublished event per aggregate is its head
+	seen := make(map[string]bool)
+	var result []db.OutboxEvent
+	for i, event := range f.outbox {
+		key := event.AggregateType + ":" + event.AggregateID.String()
+		if event.PublishedAt.Valid || seen[key] {
+			continue
+		}
+		seen[key] = true
+		if event.NextAttemptAt.Time.After(time.Now()) {
+			continue
+		}
+		f.outbox[i].NextAttemptAt = arg.ClaimedUntil
+		result = append(result, f.outbox[i])
+		if int32(len(result)) == arg.BatchSize {
+			break
+		}
+	}
+	return result, nil
+}
+
+func (f *FakeStore) MarkOutboxEventFailed(ctx context.Context, arg db.MarkOutboxEventFailedParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i := range f.outbox {
+		if f.outbox[i].ID == arg.ID {
+			f.outbox[i].Attempts++
+			f.outbox[i].LastError = arg.LastError
+			f.outbox[i].NextAttemptAt = arg.NextAttemptAt
+			f.outbox[i].PublishedSinks = arg.PublishedSinks
+			return nil
+		}
+	}
+	return nil
+}
+
+func (f *FakeStore) MarkOutboxEventPublished(ctx context.Context, id uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i := range f.outbox {
+		if f.outbox[i].ID == id {
+			f.outbox[i].PublishedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+			f.outbox[i].LastError = pgtype.Text{}
+			return nil
+		}
+	}
+	return nil
+}
+
+func (f *FakeStore) CreateWebhookDelivery(ctx context.Context, arg db.CreateWebhookDeliveryParams) (db.WebhookDelivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !arg.RedeliveryOf.Valid {
+		for _, d := range f.deliveries {
+			if d.EndpointID == arg.EndpointID && d.EventID == arg.EventID && !d.RedeliveryOf.Valid {
+				// ON CONFLICT DO NOTHING returns no row
+				return db.WebhookDelivery{}, pgx.ErrNoRows
+			}
+		}
+	}
+
+	now := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	delivery := db.WebhookDelivery{
+		ID:           uuid.New(),
+		EndpointID:   arg.EndpointID,
+		EventID:      arg.EventID,
+		EventType:    arg.EventType,
+		Payload:      arg.Payload,
+		RedeliveryOf: arg.RedeliveryOf,
+		Status:       db.WebhookDeliveryStatusEnumPending,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	f.deliveries = append(f.deliveries, delivery)
+	return delivery, nil
+}
+
+func (f *FakeStore) CreateWebhookDeliveryAttempt(ctx context.Context, arg db.CreateWebhookDeliveryAttemptParams) (db.WebhookDeliveryAttempt, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	attempt := db.WebhookDeliveryAttempt{
+		ID:           uuid.New(),
+		DeliveryID:   arg.DeliveryID,
+		Attempt:      arg.Attempt,
+		ResponseCode: arg.ResponseCode,
+		ResponseBody: arg.ResponseBody,
+		Error:        arg.Error,
+		DurationMs:   arg.DurationMs,
+		CreatedAt:    pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	}
+	f.attempts = append(f.attempts, attempt)
+	return attempt, nil
+}
+
+func (f *FakeStore) CreateWebhookEndpoint(ctx context.Context, arg db.CreateWebhookEndpointParams) (db.WebhookEndpoint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	endpoint := db.WebhookEndpoint{
+		ID:          uuid.New(),
+		UserID:      arg.UserID,
+		Url:         arg.Url,
+		Description: arg.Description,
+		EventTypes:  arg.EventTypes,
+		Secret:      arg.Secret,
+		Status:      db.WebhookEndpointStatusEnumActive,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	f.webhooks[endpoint.ID] = endpoint
+	return endpoint, nil
+}
+
+func (f *FakeStore) DeleteWebhookEndpoint(ctx context.Context, id uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.webhooks, id)
+	return nil
+}
+
+func (f *FakeStore) DisableWebhookEndpoint(ctx context.Context, arg db.DisableWebhookEndpointParams) (db.WebhookEndpoint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	endpoint, ok := f.webhooks[arg.ID]
+	if !ok {
+		return db.WebhookEndpoint{}, pgx.ErrNoRows
+	}
+	endpoint.Status = db.WebhookEndpointStatusEnumDisabled
+	endpoint.DisabledReason = arg.DisabledReason
+	endpoint.DisabledAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	f.webhooks[arg.ID] = endpoint
+	return endpoint, nil
+}
+
+func (f *FakeStore) EnableWebhookEndpoint(ctx context.Context, id uuid.UUID) (db.WebhookEndpoint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	endpoint, ok := f.webhooks[id]
+	if !ok {
+		return db.WebhookEndpoint{}, pgx.ErrNoRows
+	}
+	endpoint.Status = db.WebhookEndpointStatusEnumActive
+	endpoint.ConsecutiveFailures = 0
+	endpoint.DisabledReason = pgtype.Text{}
+	endpoint.DisabledAt = pgtype.Timestamptz{}
+	f.webhooks[id] = endpoint
+	return endpoint, nil
+}
+
+func (f *FakeStore) GetActiveWebhookEndpointsForEvent(ctx context.Context, arg db.GetActiveWebhookEndpointsForEventParams) ([]db.WebhookEndpoint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result []db.WebhookEndpoint
+	for _, endpoint := range f.webhooks {
+		if endpoint.Status != db.WebhookEndpointStatusEnumActive {
+			continue
+		}
+		owned, subscribed := false, false
+		for _, id := range arg.UserIds {
+			owned = owned || id == endpoint.UserID
+		}
+		for _, t := range endpoint.EventTypes {
+			subscribed = subscribed || t == arg.EventType || t == "*"
+		}
+		if owned && subscribed {
+			result = append(result, endpoint)
+		}
+	}
+	sort.Slice(result, func(i, k int) bool { return result[i].CreatedAt.Time.Before(result[k].CreatedAt.Time) })
+	return result, nil
+}
+
+func (f *FakeStore) GetWebhookDeliveriesByEndpointId(ctx context.Context, arg db.GetWebhookDeliveriesByEndpointIdParams) ([]db.WebhookDelivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// newest first, like the query
+	var result []db.WebhookDelivery
+	for i := len(f.deliveries) - 1; i >= 0; i-- {
+		if f.deliveries[i].EndpointID == arg.EndpointID {
+			result = append(result, f.deliveries[i])
+		}
+	}
+	if int(arg.Offset) >= len(result) {
+		return nil, nil
+	}
+	result = result[arg.Offset:]
+	if int(arg.Limit) < len(result) {
+		result = result[:arg.Limit]
+	}
+	return result, nil
+}
+
+func (f *FakeStore) GetWebhookDeliveryAttemptsByDeliveryId(ctx context.Context, deliveryID uuid.UUID) ([]db.WebhookDeliveryAttempt, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result []db.WebhookDeliveryAttempt
+	for _, a := range f.attempts {
+		if a.DeliveryID == deliveryID {
+			result = append(result, a)
+		}
+	}
+	return result, nil
+}
+
+func (f *FakeStore) GetWebhookDeliveryByEvent(ctx context.Context, arg db.GetWebhookDeliveryByEventParams) (db.WebhookDelivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, d := range f.deliveries {
+		if d.EndpointID == arg.EndpointID && d.EventID == arg.EventID && !d.RedeliveryOf.Valid {
+			return d, nil
+		}
+	}
+	return db.WebhookDelivery{}, pgx.ErrNoRows
+}
+
+func (f *FakeStore) GetWebhookDeliveryById(ctx context.Context, id uuid.UUID) (db.WebhookDelivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, d := range f.deliveries {
+		if d.ID == id {
+			return d, nil
+		}
+	}
+	return db.WebhookDelivery{}, pgx.ErrNoRows
+}
+
+func (f *FakeStore) GetWebhookDeliveryForUpdate(ctx context.Context, id uuid.UUID) (db.WebhookDelivery, error) {
+	return f.GetWebhookDeliveryById(ctx, id)
+}
+
+func (f *FakeStore) GetWebhookEndpointById(ctx context.Context, id uuid.UUID) (db.WebhookEndpoint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	endpoint, ok := f.webhooks[id]
+	if !ok {
+		return db.WebhookEndpoint{}, pgx.ErrNoRows
+	}
+	return endpoint, nil
+}
+
+func (f *FakeStore) GetWebhookEndpointsByUserId(ctx context.Context, userID uuid.UUID) ([]db.WebhookEndpoint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result []db.WebhookEndpoint
+	for _, endpoint := range f.webhooks {
+		if endpoint.UserID == userID {
+			result = append(result, endpoint)
+		}
+	}
+	sort.Slice(result, func(i, k int) bool { return result[i].CreatedAt.Time.Before(result[k].CreatedAt.Time) })
+	return result, nil
+}
+
+func (f *FakeStore) RecordWebhookEndpointFailure(ctx context.Context, id uuid.UUID) (db.WebhookEndpoint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	endpoint, ok := f.webhooks[id]
+	if !ok {
+		return db.WebhookEndpoint{}, pgx.ErrNoRows
+	}
+	endpoint.ConsecutiveFailures++
+	f.webhooks[id] = endpoint
+	return endpoint, nil
+}
+
+func (f *FakeStore) RecordWebhookEndpointSuccess(ctx context.Context, id uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if endpoint, ok := f.webhooks[id]; ok {
+		endpoint.ConsecutiveFailures = 0
+		f.webhooks[id] = endpoint
+	}
+	return nil
+}
+
+func (f *FakeStore) RotateWebhookEndpointSecret(ctx context.Context, arg db.RotateWebhookEndpointSecretParams) (db.WebhookEndpoint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	endpoint, ok := f.webhooks[arg.ID]
+	if !ok {
+		return db.WebhookEndpoint{}, pgx.ErrNoRows
+	}
+	endpoint.PreviousSecret = pgtype.Text{String: endpoint.Secret, Valid: true}
+	endpoint.Secret = arg.Secret
+	endpoint.PreviousSecretExpiresAt = arg.PreviousSecretExpiresAt
+	f.webhooks[arg.ID] = endpoint
+	return endpoint, nil
+}
+
+func (f *FakeStore) UpdateWebhookDeliveryResult(ctx context.Context, arg db.UpdateWebhookDeliveryResultParams) (db.WebhookDelivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i := range f.deliveries {
+		if f.deliveries[i].ID == arg.ID {
+			d := &f.deliveries[i]
+			d.Status = arg.Status
+			d.Attempts++
+			d.ResponseCode = arg.ResponseCode
+			d.LastError = arg.LastError
+			d.NextAttemptAt = arg.NextAttemptAt
+			d.DeliveredAt = arg.DeliveredAt
+			d.UpdatedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+			return *d, nil
+		}
+	}
+	return db.WebhookDelivery{}, pgx.ErrNoRows
+}
+
+func (f *FakeStore) UpdateWebhookEndpoint(ctx context.Context, arg db.UpdateWebhookEndpointParams) (db.WebhookEndpoint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	endpoint, ok := f.webhooks[arg.ID]
+	if !ok {
+		return db.WebhookEndpoint{}, pgx.ErrNoRows
+	}
+	if arg.Url.Valid {
+		endpoint.Url = arg.Url.String
+	}
+	if arg.Description.Valid {
+		endpoint.Description = arg.Description
+	}
+	if arg.EventTypes != nil {
+		endpoint.EventTypes = arg.EventTypes
+	}
+	endpoint.UpdatedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	f.webhooks[arg.ID] = endpoint
+	return endpoint, nil
+}
+
+func (f *FakeStore) FailWebhookDelivery(ctx context.Context, arg db.FailWebhookDeliveryParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i := range f.deliveries {
+		if f.deliveries[i].ID == arg.ID {
+			f.deliveries[i].Status = db.WebhookDeliveryStatusEnumFailed
+			f.deliveries[i].LastError = arg.LastError
+			f.deliveries[i].NextAttemptAt = pgtype.Timestamptz{}
+			return nil
+		}
+	}
+	return nil
+}
+
+func (f *FakeStore) GetOutboxEventBySequence(ctx context.Context, sequence int64) (db.OutboxEvent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, e := range f.outbox {
+		if e.Sequence == sequence {
+			return e, nil
+		}
+	}
+	return db.OutboxEvent{}, pgx.ErrNoRows
+}
+
+func (f *FakeStore) GetWalletStreamEvents(ctx context.Context, arg db.GetWalletStreamEventsParams) ([]db.OutboxEvent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	wanted := make(map[uuid.UUID]bool, len(arg.WalletIds))
+	for _, id := range arg.WalletIds {
+		wanted[id] = true
+	}
+
+	var result []db.OutboxEvent
+	for _, e := range f.outbox {
+		if e.Sequence == arg.AfterSequence ||
+			(e.Sequence < arg.AfterSequence && (!arg.Since.Valid || e.CreatedAt.Time.Before(arg.Since.Time))) {
+			continue
+		}
+		match := e.AggregateType == "wallet" && wanted[e.AggregateID]
+		if e.AggregateType == "transaction" {
+			var data struct {
+				Legs []struct {
+					WalletID uuid.UUID `json:"wallet_id"`
+				} `json:"legs"`
+			}
+			if err := json.Unmarshal(e.Payload, &data); err != nil {
+				return nil, err
+			}
+			for _, leg := range data.Legs {
+				match = match || wanted[leg.WalletID]
+			}
+		}
+		if match {
+			result = append(result, e)
+		}
+		if len(result) == int(arg.BatchSize) {
+			break
+		}
+	}
+	return result, nil
+}
+
+func (f *FakeStore) GetLatestOutboxSequence(ctx context.Context) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var latest int64
+	for _, e := range f.outbox {
+		latest = max(latest, e.Sequence)
+	}
+	return latest, nil
+}
+
+func (f *FakeStore) CreateFundingDeposit(ctx context.Context, arg db.CreateFundingDepositParams) (db.FundingDeposit, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, d := range f.fundings {
+		if d.Provider == arg.Provider && d.ProviderReference == arg.ProviderReference {
+			return db.FundingDeposit{}, pgx.ErrNoRows
+		}
+	}
+	now := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	deposit := db.FundingDeposit{
+		ID:                  uuid.New(),
+		Provider:            arg.Provider,
+		ProviderReference:   arg.ProviderReference,
+		VirtualAccountID:    arg.VirtualAccountID,
+		WalletID:            arg.WalletID,
+		Amount:              arg.Amount,
+		Currency:            arg.Currency,
+		Status:              db.FundingDepositStatusEnumPending,
+		SenderName:          arg.SenderName,
+		SenderAccountNumber: arg.SenderAccountNumber,
+		SenderBank:          arg.SenderBank,
+		CreatedAt:           now,
+		UpdatedAt:           now,
+	}
+	f.fundings[deposit.ID] = deposit
+	return deposit, nil
+}
+
+func (f *FakeStore) CreateVirtualAccount(ctx context.Context, arg db.CreateVirtualAccountParams) (db.VirtualAccount, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, v := range f.virtuals {
+		if v.Provider == arg.Provider && (v.AccountNumber == arg.AccountNumber || v.WalletID == arg.WalletID) {
+			return db.VirtualAccount{}, &pgconn.PgError{Code: "23505"}
+		}
+	}
+	account := db.VirtualAccount{
+		ID:            uuid.New(),
+		UserID:        arg.UserID,
+		WalletID:      arg.WalletID,
+		Provider:      arg.Provider,
+		AccountNumber: arg.AccountNumber,
+		AccountName:   arg.AccountName,
+		BankName:      arg.BankName,
+		Currency:      arg.Currency,
+		CreatedAt:     pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	}
+	f.virtuals[account.ID] = account
+	return account, nil
+}
+
+func (f *FakeStore) FailFundingDeposit(ctx context.Context, arg db.FailFundingDepositParams) (db.FundingDeposit, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	d, ok := f.fundings[arg.ID]
+	if !ok {
+		return db.FundingDeposit{}, pgx.ErrNoRows
+	}
+	d.Status = db.FundingDepositStatusEnumFailed
+	d.FailureReason = arg.FailureReason
+	d.UpdatedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	f.fundings[d.ID] = d
+	return d, nil
+}
+
+func (f *FakeStore) GetFundingDepositById(ctx context.Context, id uuid.UUID) (db.FundingDeposit, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	d, ok := f.fundings[id]
+	if !ok {
+		return db.FundingDeposit{}, pgx.ErrNoRows
+	}
+	return d, nil
+}
+
+func (f *FakeStore) GetFundingDepositForUpdate(ctx context.Context, arg db.GetFundingDepositForUpdateParams) (db.FundingDeposit, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, d := range f.fundings {
+		if d.Provider == arg.Provider && d.ProviderReference == arg.ProviderReference {
+			return d, nil
+		}
+	}
+	return db.FundingDeposit{}, pgx.ErrNoRows
+}
+
+func (f *FakeStore) GetFundingDepositsByUserId(ctx context.Context, arg db.GetFundingDepositsByUserIdParams) ([]db.FundingDeposit, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result []db.FundingDeposit
+	for _, d := range f.fundings {
+		if f.virtuals[d.VirtualAccountID].UserID == arg.UserID {
+			result = append(result, d)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Time.After(result[j].CreatedAt.Time) })
+	if len(result) > int(arg.BatchSize) {
+		result = result[:arg.BatchSize]
+	}
+	return result, nil
+}
+
+func (f *FakeStore) GetVirtualAccountByNumber(ctx context.Context, arg db.GetVirtualAccountByNumberParams) (db.VirtualAccount, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, v := range f.virtuals {
+		if v.Provider == arg.Provider && v.AccountNumber == arg.AccountNumber {
+			return v, nil
+		}
+	}
+	return db.VirtualAccount{}, pgx.ErrNoRows
+}
+
+func (f *FakeStore) GetVirtualAccountByWallet(ctx context.Context, arg db.GetVirtualAccountByWalletParams) (db.VirtualAccount, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, v := range f.virtuals {
+		if v.WalletID == arg.WalletID && v.Provider == arg.Provider {
+			return v, nil
+		}
+	}
+	return db.VirtualAccount{}, pgx.ErrNoRows
+}
+
+func (f *FakeStore) GetVirtualAccountsByUserId(ctx context.Context, userID uuid.UUID) ([]db.VirtualAccount, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result []db.VirtualAccount
+	for _, v := range f.virtuals {
+		if v.UserID == userID {
+			result = append(result, v)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Time.Before(result[j].CreatedAt.Time) })
+	return result, nil
+}
+
+func (f *FakeStore) SettleFundingDeposit(ctx context.Context, arg db.SettleFundingDepositParams) (db.FundingDeposit, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	d, ok := f.fundings[arg.ID]
+	if !ok {
+		return db.FundingDeposit{}, pgx.ErrNoRows
+	}
+	now := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	d.Status = db.FundingDepositStatusEnumSettled
+	d.TransactionID = arg.TransactionID
+	d.SettledAt = now
+	d.UpdatedAt = now
+	f.fundings[d.ID] = d
+	return d, nil
+}
+
+func (f *FakeStore) CreateBankDestination(ctx context.Context, arg db.CreateBankDestinationParams) (db.BankDestination, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, d := range f.destinations {
+		if d.UserID == arg.UserID && d.BankCode == arg.BankCode && d.AccountNumber == arg.AccountNumber {
+			return db.BankDestination{}, &pgconn.PgError{Code: "23505"}
+		}
+	}
+	destination := db.BankDestination{
+		ID:            uuid.New(),
+		UserID:        arg.UserID,
+		BankCode:      arg.BankCode,
+		BankName:      arg.BankName,
+		AccountNumber: arg.AccountNumber,
+		AccountName:   arg.AccountName,
+		Currency:      arg.Currency,
+		Nickname:      arg.Nickname,
+		CreatedAt:     pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	}
+	f.destinations[destination.ID] = destination
+	return destination, nil
+}
+
+func (f *FakeStore) CreatePayout(ctx context.Context, arg db.CreatePayoutParams) (db.Payout, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, p := range f.payouts {
+		if p.IdempotencyKey == arg.IdempotencyKey {
+			return db.Payout{}, &pgconn.PgError{Code: "23505"}
+		}
+	}
+	now := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	payout := db.Payout{
+		ID:             uuid.New(),
+		UserID:         arg.UserID,
+		WalletID:       arg.WalletID,
+		DestinationID:  arg.DestinationID,
+		BankCode:       arg.BankCode,
+		AccountNumber:  arg.AccountNumber,
+		AccountName:    arg.AccountName,
+		Amount:         arg.Amount,
+		Fee:            arg.Fee,
+		FeeScheduleID:  arg.FeeScheduleID,
+		Currency:       arg.Currency,
+		Narration:      arg.Narration,
+		Status:         db.PayoutStatusEnumInitiated,
+		HoldID:         arg.HoldID,
+		Provider:       arg.Provider,
+		IdempotencyKey: arg.IdempotencyKey,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	f.payouts[payout.ID] = payout
+	return payout, nil
+}
+
+func (f *FakeStore) DeleteBankDestination(ctx context.Context, id uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.destinations, id)
+	for pid, p := range f.payouts {
+		if p.DestinationID.Valid && uuid.UUID(p.DestinationID.Bytes) == id {
+			p.DestinationID = pgtype.UUID{}
+			f.payouts[pid] = p
+		}
+	}
+	return nil
+}
+
+func (f *FakeStore) GetBankDestinationById(ctx context.Context, id uuid.UUID) (db.BankDestination, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	d, ok := f.destinations[id]
+	if !ok {
+		return db.BankDestination{}, pgx.ErrNoRows
+	}
+	return d, nil
+}
+
+func (f *FakeStore) GetBankDestinationsByUserId(ctx context.Context, userID uuid.UUID) ([]db.BankDestination, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result []db.BankDestination
+	for _, d := range f.destinations {
+		if d.UserID == userID {
+			result = append(result, d)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Time.Before(result[j].CreatedAt.Time) })
+	return result, nil
+}
+
+func (f *FakeStore) GetDuePayoutIds(ctx context.Context, arg db.GetDuePayoutIdsParams) ([]uuid.UUID, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var due []db.Payout
+	for _, p := range f.payouts {
+		if p.Status == db.PayoutStatusEnumInitiated ||
+			(p.Status == db.PayoutStatusEnumProcessing && !p.NextCheckAt.Time.After(arg.Now.Time)) {
+			due = append(due, p)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].CreatedAt.Time.Before(due[j].CreatedAt.Time) })
+
+	var ids []uuid.UUID
+	for _, p := range due {
+		if len(ids) == int(arg.BatchSize) {
+			break
+		}
+		ids = append(ids, p.ID)
+	}
+	return ids, nil
+}
+
+func (f *FakeStore) GetPayoutById(ctx context.Context, id uuid.UUID) (db.Payout, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p, ok := f.payouts[id]
+	if !ok {
+		return db.Payout{}, pgx.ErrNoRows
+	}
+	return p, nil
+}
+
+func (f *FakeStore) GetPayoutByIdempotencyKey(ctx context.Context, idempotencyKey string) (db.Payout, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, p := range f.payouts {
+		if p.IdempotencyKey == idempotencyKey {
+			return p, nil
+		}
+	}
+	return db.Payout{}, pgx.ErrNoRows
+}
+
+func (f *FakeStore) GetPayoutForUpdate(ctx context.Context, id uuid.UUID) (db.Payout, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p, ok := f.payouts[id]
+	if !ok {
+		return db.Payout{}, pgx.ErrNoRows
+	}
+	return p, nil
+}
+
+func (f *FakeStore) GetPayoutsByUserId(ctx context.Context, arg db.GetPayoutsByUserIdParams) ([]db.Payout, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result []db.Payout
+	for _, p := range f.payouts {
+		if p.UserID == arg.UserID {
+			result = append(result, p)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Time.After(result[j].CreatedAt.Time) })
+	if len(result) > int(arg.Limit) {
+		result = result[:arg.Limit]
+	}
+	return result, nil
+}
+
+func (f *FakeStore) GetWalletHoldById(ctx context.Context, id uuid.UUID) (db.WalletHold, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	hold, ok := f.holds[id]
+	if !ok {
+		return db.WalletHold{}, pgx.ErrNoRows
+	}
+	return hold, nil
+}
+
+func (f *FakeStore) MarkPayoutFailed(ctx context.Context, arg db.MarkPayoutFailedParams) (db.Payout, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p, ok := f.payouts[arg.ID]
+	if !ok {
+		return db.Payout{}, pgx.ErrNoRows
+	}
+	now := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	p.Status = db.PayoutStatusEnumFailed
+	p.FailureReason = arg.FailureReason
+	p.NextCheckAt = pgtype.Timestamptz{}
+	p.CompletedAt = now
+	p.UpdatedAt = now
+	f.payouts[p.ID] = p
+	return p, nil
+}
+
+func (f *FakeStore) MarkPayoutProcessing(ctx context.Context, arg db.MarkPayoutProcessingParams) (db.Payout, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p, ok := f.payouts[arg.ID]
+	if !ok {
+		return db.Payout{}, pgx.ErrNoRows
+	}
+	now := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	p.Status = db.PayoutStatusEnumProcessing
+	p.ProviderReference = arg.ProviderReference
+	p.DebitTransactionID = arg.DebitTransactionID
+	p.NextCheckAt = arg.NextCheckAt
+	p.UpdatedAt = now
+	f.payouts[p.ID] = p
+	return p, nil
+}
+
+func (f *FakeStore) MarkPayoutReversed(ctx context.Context, arg db.MarkPayoutReversedParams) (db.Payout, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p, ok := f.payouts[arg.ID]
+	if !ok {
+		return db.Payout{}, pgx.ErrNoRows
+	}
+	now := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	p.Status = db.PayoutStatusEnumReversed
+	p.ReversalTransactionID = arg.ReversalTransactionID
+	p.FailureReason = arg.FailureReason
+	p.NextCheckAt = pgtype.Timestamptz{}
+	p.CompletedAt = now
+	p.UpdatedAt = now
+	f.payouts[p.ID] = p
+	return p, nil
+}
+
+func (f *FakeStore) MarkPayoutForReview(ctx context.Context, arg db.MarkPayoutForReviewParams) (db.Payout, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p, ok := f.payouts[arg.ID]
+	if !ok {
+		return db.Payout{}, pgx.ErrNoRows
+	}
+	p.Status = db.PayoutStatusEnumReview
+	p.ProviderReference = arg.ProviderReference
+	p.FailureReason = arg.FailureReason
+	p.NextCheckAt = pgtype.Timestamptz{}
+	p.UpdatedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	f.payouts[p.ID] = p
+	return p, nil
+}
+
+func (f *FakeStore) MarkPayoutSucceeded(ctx context.Context, arg db.MarkPayoutSucceededParams) (db.Payout, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p, ok := f.payouts[arg.ID]
+	if !ok {
+		return db.Payout{}, pgx.ErrNoRows
+	}
+	now := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	p.Status = db.PayoutStatusEnumSucceeded
+	p.SettlementTransactionID = arg.SettlementTransactionID
+	p.NextCheckAt = pgtype.Timestamptz{}
+	p.CompletedAt = now
+	p.UpdatedAt = now
+	f.payouts[p.ID] = p
+	return p, nil
+}
+
+func (f *FakeStore) SchedulePayoutCheck(ctx context.Context, arg db.SchedulePayoutCheckParams) (db.Payout, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p, ok := f.payouts[arg.ID]
+	if !ok {
+		return db.Payout{}, pgx.ErrNoRows
+	}
+	now := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	p.Checks++
+	p.NextCheckAt = arg.NextCheckAt
+	p.UpdatedAt = now
+	f.payouts[p.ID] = p
+	return p, nil
 }
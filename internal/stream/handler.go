@@ -0,0 +1,173 @@
+package stream
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/luponetn/paycore/internal/outbox"
+)
+
+const (
+	// replayLimit is how many missed events a reconnecting client is sent; one further behind is
+	// told to resync from the REST endpoints instead.
+	replayLimit = 1000
+	// retryAfter is how long clients wait before reconnecting, in milliseconds.
+	retryAfter = 3000
+	// writeTimeout bounds each write so a stalled client does not pin its stream open.
+	writeTimeout = 10 * time.Second
+)
+
+type Handler struct {
+	Hub *Hub
+	// Heartbeat is how often an idle stream sends a comment to keep proxies from closing it.
+	Heartbeat time.Duration
+}
+
+func NewHandler(hub *Hub) *Handler {
+	return &Handler{Hub: hub, Heartbeat: 15 * time.Second}
+}
+
+// HandleStream handles GET /wallets/stream
+// It streams the balance changes and transactions of the caller's wallets as server-sent
+// events. A client resuming with Last-Event-ID (or ?last_event_id= where the header cannot be
+// set) first receives what it missed; one too far behind receives a resync event and should
+// refetch its wallets. Events carry the outbox envelope and are delivered at least once.
+func (h *Handler) HandleStream(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	after := int64(-1)
+	if lastEventID != "" {
+		parsed, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || parsed < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid last event id"})
+			return
+		}
+		after = parsed
+	}
+
+	// subscribing before reading the backlog means nothing falls between the two
+	sub := h.Hub.Subscribe(userID)
+	defer h.Hub.Unsubscribe(sub)
+
+	var backlog []outbox.Event
+	var latest int64
+	if after >= 0 {
+		var err error
+		backlog, latest, err = h.Hub.Replay(c.Request.Context(), userID, after, replayLimit+1)
+		if err != nil {
+			slog.Error("failed to replay wallet stream", "user_id", userID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to open stream"})
+			return
+		}
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	rc := http.NewResponseController(c.Writer)
+	send := func(write func(w io.Writer) error) bool {
+		if err := rc.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return false
+		}
+		if err := write(c.Writer); err != nil {
+			return false
+		}
+		c.Writer.Flush()
+		return true
+	}
+
+	if !send(func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "retry: %d\n\n", retryAfter)
+		return err
+	}) {
+		return
+	}
+
+	// events already replayed, or skipped by a resync, can arrive again from the subscription
+	replayed := make(map[uuid.UUID]bool, len(backlog))
+	skipThrough := int64(-1)
+	if len(backlog) > replayLimit {
+		if !send(func(w io.Writer) error {
+			_, err := fmt.Fprintf(w, "id: %d\nevent: resync\ndata: {}\n\n", latest)
+			return err
+		}) {
+			return
+		}
+		skipThrough = latest
+	} else {
+		for _, event := range backlog {
+			replayed[event.ID] = true
+			if !send(func(w io.Writer) error { return writeEvent(w, event) }) {
+				return
+			}
+		}
+	}
+
+	heartbeat := time.NewTicker(h.Heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-sub.Done():
+			// fell behind or shutting down; the client reconnects and replays
+			return
+		case event := <-sub.Events():
+			if replayed[event.ID] || event.Sequence <= skipThrough {
+				continue
+			}
+			if !send(func(w io.Writer) error { return writeEvent(w, event) }) {
+				return
+			}
+		case <-heartbeat.C:
+			if !send(func(w io.Writer) error {
+				_, err := io.WriteString(w, ": ping\n\n")
+				return err
+			}) {
+				return
+			}
+		}
+	}
+}
+
+func writeEvent(w io.Writer, event outbox.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Sequence, event.Type, data)
+	return err
+}
+
+func authUserID(c *gin.Context) (uuid.UUID, bool) {
+	userIdVal, exists := c.Get("user_id")
+	if !exists {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return uuid.Nil, false
+	}
+	userID, ok := userIdVal.(uuid.UUID)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "invalid user id type"})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
@@ -0,0 +1,112 @@
+package stream
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/luponetn/paycore/internal/outbox"
+	"github.com/luponetn/paycore/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+type frame struct {
+	id    string
+	event string
+	data  string
+}
+
+// readFrame reads the next event from an SSE stream, skipping comments and the retry hint.
+func readFrame(t *testing.T, r *bufio.Reader) frame {
+	t.Helper()
+	var f frame
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+
+		switch {
+		case line == "":
+			if f.event != "" {
+				return f
+			}
+		case strings.HasPrefix(line, "id: "):
+			f.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			f.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			f.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestHandleStream_ReplaysFromLastEventIDThenStreamsLive(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	f := store.NewFakeStore()
+	hub := NewHub(f)
+	defer hub.Close()
+
+	payer, payerWallet := addWallet(f, "100")
+	_, payeeWallet := addWallet(f, "0")
+
+	router := gin.New()
+	router.GET("/wallets/stream", func(c *gin.Context) {
+		c.Set("user_id", payer)
+	}, NewHandler(hub).HandleStream)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	// The first transfer records transfer.completed, then the payer's and payee's balances.
+	first := transfer(t, f, payerWallet, payeeWallet, "10")
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/wallets/stream", nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	body := bufio.NewReader(resp.Body)
+	replayed := readFrame(t, body)
+	require.Equal(t, "2", replayed.id)
+	require.Equal(t, outbox.EventWalletBalanceChanged, replayed.event)
+
+	var envelope outbox.Event
+	require.NoError(t, json.Unmarshal([]byte(replayed.data), &envelope))
+	require.Equal(t, first[1].ID, envelope.ID)
+	require.Equal(t, payerWallet, envelope.AggregateID)
+
+	// A redelivered replayed event is skipped; new ones stream as they are published.
+	require.NoError(t, hub.Publish(context.Background(), first[1]))
+	for _, event := range transfer(t, f, payerWallet, payeeWallet, "5") {
+		require.NoError(t, hub.Publish(context.Background(), event))
+	}
+	live := readFrame(t, body)
+	require.Equal(t, "4", live.id)
+	require.Equal(t, "transfer.completed", live.event)
+	live = readFrame(t, body)
+	require.Equal(t, "5", live.id)
+	require.Equal(t, outbox.EventWalletBalanceChanged, live.event)
+}
+
+func TestHandleStream_RejectsInvalidLastEventID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	hub := NewHub(store.NewFakeStore())
+
+	router := gin.New()
+	router.GET("/wallets/stream", func(c *gin.Context) {
+		c.Set("user_id", uuid.New())
+	}, NewHandler(hub).HandleStream)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/wallets/stream?last_event_id=abc", nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
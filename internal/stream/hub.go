@@ -0,0 +1,277 @@
+// Package stream pushes wallet and transaction events to connected clients over server-sent
+// events. Every API instance listens for outbox notifications from Postgres and fans each
+// event out to the subscribers it concerns, so a client may connect to any instance.
+package stream
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/outbox"
+	"github.com/luponetn/paycore/internal/store"
+	"github.com/luponetn/paycore/pkg/utils"
+)
+
+// Channel is the Postgres notification channel the outbox trigger announces events on.
+const Channel = "outbox_events"
+
+// subscriptionBuffer is how many events a subscriber may fall behind by before it is dropped.
+const subscriptionBuffer = 64
+
+// replayOverlap is how far before a resumed-from event replay starts again. Sequences are taken
+// when an event is written, not when its transaction commits, so an event with a lower sequence
+// can become visible after a client has seen a higher one; no transaction runs this long.
+const replayOverlap = 2 * time.Minute
+
+// Subscription receives the live events of one user's wallets.
+type Subscription struct {
+	userID uuid.UUID
+	events chan outbox.Event
+	done   chan struct{}
+	once   sync.Once
+}
+
+// Events delivers events in the order the hub received them.
+func (s *Subscription) Events() <-chan outbox.Event {
+	return s.events
+}
+
+// Done is closed when the subscription ends: the subscriber fell too far behind or the hub
+// shut down. Clients reconnect and replay what they missed from their last event id.
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+func (s *Subscription) close() {
+	s.once.Do(func() { close(s.done) })
+}
+
+// Hub tracks the open subscriptions of this instance.
+type Hub struct {
+	store store.Store
+
+	mu     sync.Mutex
+	subs   map[uuid.UUID]map[*Subscription]struct{}
+	closed bool
+}
+
+func NewHub(store store.Store) *Hub {
+	return &Hub{
+		store: store,
+		subs:  make(map[uuid.UUID]map[*Subscription]struct{}),
+	}
+}
+
+// Subscribe opens a subscription to userID's events. Callers must Unsubscribe when done.
+func (h *Hub) Subscribe(userID uuid.UUID) *Subscription {
+	sub := &Subscription{
+		userID: userID,
+		events: make(chan outbox.Event, subscriptionBuffer),
+		done:   make(chan struct{}),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		sub.close()
+		return sub
+	}
+	if h.subs[userID] == nil {
+		h.subs[userID] = make(map[*Subscription]struct{})
+	}
+	h.subs[userID][sub] = struct{}{}
+	return sub
+}
+
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.subs[sub.userID], sub)
+	if len(h.subs[sub.userID]) == 0 {
+		delete(h.subs, sub.userID)
+	}
+	sub.close()
+}
+
+// Publish hands an event to the subscriptions of every user it concerns. It never blocks: a
+// subscriber whose buffer is full is ended instead, so one slow client cannot hold up the rest.
+func (h *Hub) Publish(ctx context.Context, event outbox.Event) error {
+	// most notifications reach instances nobody is streaming from
+	h.mu.Lock()
+	idle := len(h.subs) == 0
+	h.mu.Unlock()
+	if idle {
+		return nil
+	}
+
+	owners, err := outbox.Owners(ctx, h.store.Queries(), event)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, owner := range owners {
+		for sub := range h.subs[owner] {
+			select {
+			case sub.events <- event:
+			default:
+				delete(h.subs[owner], sub)
+				sub.close()
+			}
+		}
+		if len(h.subs[owner]) == 0 {
+			delete(h.subs, owner)
+		}
+	}
+	return nil
+}
+
+// Close ends every subscription and refuses new ones, letting open streams finish before the
+// server shuts down.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for userID, subs := range h.subs {
+		for sub := range subs {
+			sub.close()
+		}
+		delete(h.subs, userID)
+	}
+}
+
+// Listen publishes the events announced on Channel until ctx is cancelled, then closes the
+// hub. A lost connection is re-established after a backoff; events committed while it was
+// down reach clients when they reconnect and replay.
+func (h *Hub) Listen(ctx context.Context, pool *pgxpool.Pool) {
+	defer h.Close()
+
+	attempt := 0
+	for {
+		connected, err := h.listen(ctx, pool)
+		if ctx.Err() != nil {
+			return
+		}
+		slog.Error("outbox notification listener stopped", "error", err)
+
+		if connected {
+			attempt = 0
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(utils.Backoff(time.Second, attempt)):
+		}
+		attempt = min(attempt+1, 5)
+	}
+}
+
+// listen relays notifications over one connection until it fails, reporting whether it got as
+// far as listening.
+func (h *Hub) listen(ctx context.Context, pool *pgxpool.Pool) (bool, error) {
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := conn.Exec(ctx, "LISTEN "+Channel); err != nil {
+		conn.Release()
+		return false, err
+	}
+
+	// the connection stays subscribed to the channel, so it is closed rather than returned to
+	// the pool
+	pgConn := conn.Hijack()
+	defer pgConn.Close(context.Background())
+
+	for {
+		notification, err := pgConn.WaitForNotification(ctx)
+		if err != nil {
+			return true, err
+		}
+
+		sequence, err := strconv.ParseInt(notification.Payload, 10, 64)
+		if err != nil {
+			slog.Error("invalid outbox notification", "payload", notification.Payload)
+			continue
+		}
+		if err := h.publishSequence(ctx, sequence); err != nil {
+			slog.Error("failed to publish outbox event to streams", "sequence", sequence, "error", err)
+		}
+	}
+}
+
+func (h *Hub) publishSequence(ctx context.Context, sequence int64) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	row, err := h.store.Queries().GetOutboxEventBySequence(ctx, sequence)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	return h.Publish(ctx, outbox.FromRow(row))
+}
+
+// Replay reads up to limit of the user's events recorded after sequence, oldest first, along
+// with the latest sequence recorded at all. It also replays the user's earlier events recorded
+// within replayOverlap before that sequence's event, which may have committed after the client
+// saw it; clients already hold some of those and drop them by event id.
+func (h *Hub) Replay(ctx context.Context, userID uuid.UUID, after int64, limit int32) ([]outbox.Event, int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	latest, err := h.store.Queries().GetLatestOutboxSequence(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// an unknown sequence leaves since unset, so only later events are replayed
+	var since pgtype.Timestamptz
+	last, err := h.store.Queries().GetOutboxEventBySequence(ctx, after)
+	if err == nil {
+		since = pgtype.Timestamptz{Time: last.CreatedAt.Time.Add(-replayOverlap), Valid: true}
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, 0, err
+	}
+
+	wallets, err := h.store.Queries().GetWalletsByUserId(ctx, utils.ToPgUUID(userID))
+	if err != nil {
+		return nil, 0, err
+	}
+	walletIDs := make([]uuid.UUID, 0, len(wallets))
+	for _, w := range wallets {
+		walletIDs = append(walletIDs, w.ID)
+	}
+
+	rows, err := h.store.Queries().GetWalletStreamEvents(ctx, db.GetWalletStreamEventsParams{
+		AfterSequence: after,
+		Since:         since,
+		WalletIds:     walletIDs,
+		BatchSize:     limit,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	events := make([]outbox.Event, 0, len(rows))
+	for _, row := range rows {
+		events = append(events, outbox.FromRow(row))
+	}
+	return events, latest, nil
+}
@@ -0,0 +1,177 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/ledger"
+	"github.com/luponetn/paycore/internal/outbox"
+	"github.com/luponetn/paycore/internal/store"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+// addWallet gives a new user an NGN wallet holding balance and returns the user and wallet ids.
+func addWallet(f *store.FakeStore, balance string) (uuid.UUID, uuid.UUID) {
+	userID := uuid.New()
+	return userID, f.AddFakeFundedWallet(userID, db.WalletTypeEnumSavings, "NGN", balance)
+}
+
+// transfer posts a transfer and returns the outbox events it recorded.
+func transfer(t *testing.T, f *store.FakeStore, from, to uuid.UUID, amount string) []outbox.Event {
+	t.Helper()
+	before := len(f.OutboxEvents())
+
+	value := decimal.RequireFromString(amount)
+	_, err := ledger.NewService(f).Post(context.Background(), ledger.Journal{
+		TransactionType:  db.TransactionTypeEnumTransfer,
+		IdempotencyKey:   uuid.New().String(),
+		SenderWalletID:   from,
+		ReceiverWalletID: to,
+		Amount:           value,
+		Postings: []ledger.Posting{
+			ledger.Debit(from, value, "NGN"),
+			ledger.Credit(to, value, "NGN"),
+		},
+	})
+	require.NoError(t, err)
+
+	var events []outbox.Event
+	for _, row := range f.OutboxEvents()[before:] {
+		events = append(events, outbox.FromRow(row))
+	}
+	return events
+}
+
+func received(sub *Subscription) []outbox.Event {
+	var events []outbox.Event
+	for {
+		select {
+		case event := <-sub.Events():
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func TestPublish_RoutesEventsToWalletOwners(t *testing.T) {
+	f := store.NewFakeStore()
+	hub := NewHub(f)
+	ctx := context.Background()
+
+	payer, payerWallet := addWallet(f, "100")
+	payee, payeeWallet := addWallet(f, "0")
+
+	payerSub := hub.Subscribe(payer)
+	defer hub.Unsubscribe(payerSub)
+	payeeSub := hub.Subscribe(payee)
+	defer hub.Unsubscribe(payeeSub)
+	otherSub := hub.Subscribe(uuid.New())
+	defer hub.Unsubscribe(otherSub)
+
+	for _, event := range transfer(t, f, payerWallet, payeeWallet, "40") {
+		require.NoError(t, hub.Publish(ctx, event))
+	}
+
+	// Both parties see the transfer and their own balance change.
+	got := received(payerSub)
+	require.Len(t, got, 2)
+	require.Equal(t, "transfer.completed", got[0].Type)
+	require.Equal(t, outbox.EventWalletBalanceChanged, got[1].Type)
+	require.Equal(t, payerWallet, got[1].AggregateID)
+
+	got = received(payeeSub)
+	require.Len(t, got, 2)
+	require.Equal(t, "transfer.completed", got[0].Type)
+	require.Equal(t, payeeWallet, got[1].AggregateID)
+
+	require.Empty(t, received(otherSub))
+}
+
+func TestPublish_EndsSubscriptionThatFallsBehind(t *testing.T) {
+	f := store.NewFakeStore()
+	hub := NewHub(f)
+	ctx := context.Background()
+
+	payer, payerWallet := addWallet(f, "100")
+	_, payeeWallet := addWallet(f, "0")
+
+	sub := hub.Subscribe(payer)
+	defer hub.Unsubscribe(sub)
+
+	events := transfer(t, f, payerWallet, payeeWallet, "1")
+	for range subscriptionBuffer {
+		require.NoError(t, hub.Publish(ctx, events[1]))
+	}
+	select {
+	case <-sub.Done():
+		t.Fatal("subscription ended with room in its buffer")
+	default:
+	}
+
+	require.NoError(t, hub.Publish(ctx, events[1]))
+	<-sub.Done()
+
+	// Closing the hub ends the remaining subscriptions and refuses new ones.
+	live := hub.Subscribe(payer)
+	hub.Close()
+	<-live.Done()
+	<-hub.Subscribe(payer).Done()
+}
+
+func TestPublish_SkipsOwnerLookupWithoutSubscribers(t *testing.T) {
+	f := store.NewFakeStore()
+	hub := NewHub(f)
+	ctx := context.Background()
+
+	// Looking up the owners of this event fails, as its wallet does not exist.
+	event := outbox.Event{
+		ID:            uuid.New(),
+		Type:          "transfer.completed",
+		AggregateType: "transaction",
+		AggregateID:   uuid.New(),
+		Data:          json.RawMessage(`{"legs":[{"wallet_id":"` + uuid.NewString() + `"}]}`),
+	}
+	require.NoError(t, hub.Publish(ctx, event))
+
+	sub := hub.Subscribe(uuid.New())
+	defer hub.Unsubscribe(sub)
+	require.Error(t, hub.Publish(ctx, event))
+}
+
+func TestReplay_CoversEventsThatCommitLate(t *testing.T) {
+	f := store.NewFakeStore()
+	hub := NewHub(f)
+	ctx := context.Background()
+
+	payer, payerWallet := addWallet(f, "100")
+	_, payeeWallet := addWallet(f, "0")
+
+	// Each transfer records transfer.completed, then the payer's and payee's balances.
+	transfer(t, f, payerWallet, payeeWallet, "10")
+	f.AgeFakeOutboxEvents(3, time.Hour)
+	transfer(t, f, payerWallet, payeeWallet, "10")
+	transfer(t, f, payerWallet, payeeWallet, "10")
+
+	// A client that saw event 7 may have done so before events 4 and 5 committed, so those
+	// are replayed again; the first transfer's are too old to still be in flight.
+	events, latest, err := hub.Replay(ctx, payer, 7, 100)
+	require.NoError(t, err)
+	require.Equal(t, int64(9), latest)
+
+	var sequences []int64
+	for _, event := range events {
+		sequences = append(sequences, event.Sequence)
+	}
+	require.Equal(t, []int64{4, 5, 8}, sequences)
+
+	// Resuming from a sequence that no longer exists replays only what follows it.
+	events, _, err = hub.Replay(ctx, payer, 100, 100)
+	require.NoError(t, err)
+	require.Empty(t, events)
+}
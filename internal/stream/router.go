@@ -0,0 +1,15 @@
+package stream
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/luponetn/paycore/internal/middleware"
+	"github.com/luponetn/paycore/pkg/utils"
+)
+
+func RegisterRoutes(r *gin.Engine, h *Handler, keys *utils.KeySet, sessions middleware.SessionChecker) {
+	streamGroup := r.Group("/wallets")
+	streamGroup.Use(middleware.AuthMiddleware(keys, sessions))
+	{
+		streamGroup.GET("/stream", h.HandleStream)
+	}
+}
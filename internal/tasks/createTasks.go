@@ -16,3 +16,123 @@ func NewSendOTPEmailTask(payload SendOTPEmailPayload) (*asynq.Task, error) {
 
 	return asynq.NewTask(TypeSendOTPEmail, payloadBytes), nil
 }
+
+func NewReleaseExpiredHoldsTask(payload ReleaseExpiredHoldsPayload) (*asynq.Task, error) {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		slog.Error("failed to marshal release expired holds payload", "error", err)
+		return nil, err
+	}
+
+	return asynq.NewTask(TypeReleaseExpiredHolds, payloadBytes), nil
+}
+
+func NewDispatchScheduledTransfersTask(payload DispatchScheduledTransfersPayload) (*asynq.Task, error) {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		slog.Error("failed to marshal dispatch scheduled transfers payload", "error", err)
+		return nil, err
+	}
+
+	return asynq.NewTask(TypeDispatchScheduledTransfers, payloadBytes), nil
+}
+
+func NewExecuteScheduledTransferTask(payload ExecuteScheduledTransferPayload) (*asynq.Task, error) {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		slog.Error("failed to marshal execute scheduled transfer payload", "error", err)
+		return nil, err
+	}
+
+	return asynq.NewTask(TypeExecuteScheduledTransfer, payloadBytes), nil
+}
+
+func NewAccrueDepositInterestTask(payload AccrueDepositInterestPayload) (*asynq.Task, error) {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		slog.Error("failed to marshal accrue deposit interest payload", "error", err)
+		return nil, err
+	}
+
+	return asynq.NewTask(TypeAccrueDepositInterest, payloadBytes), nil
+}
+
+func NewAccrueSavingsInterestTask(payload AccrueSavingsInterestPayload) (*asynq.Task, error) {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		slog.Error("failed to marshal accrue savings interest payload", "error", err)
+		return nil, err
+	}
+
+	return asynq.NewTask(TypeAccrueSavingsInterest, payloadBytes), nil
+}
+
+func NewSendPasswordResetEmailTask(payload SendPasswordResetEmailPayload) (*asynq.Task, error) {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		slog.Error("failed to marshal send password reset email payload", "error", err)
+		return nil, err
+	}
+
+	return asynq.NewTask(TypeSendPasswordResetEmail, payloadBytes), nil
+}
+
+func NewSendTransferReceiptEmailTask(payload SendTransferReceiptEmailPayload) (*asynq.Task, error) {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		slog.Error("failed to marshal send transfer receipt email payload", "error", err)
+		return nil, err
+	}
+
+	return asynq.NewTask(TypeSendTransferReceiptEmail, payloadBytes), nil
+}
+
+func NewSendSecurityAlertEmailTask(payload SendSecurityAlertEmailPayload) (*asynq.Task, error) {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		slog.Error("failed to marshal send security alert email payload", "error", err)
+		return nil, err
+	}
+
+	return asynq.NewTask(TypeSendSecurityAlertEmail, payloadBytes), nil
+}
+
+func NewRelayOutboxEventsTask(payload RelayOutboxEventsPayload) (*asynq.Task, error) {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		slog.Error("failed to marshal relay outbox events payload", "error", err)
+		return nil, err
+	}
+
+	return asynq.NewTask(TypeRelayOutboxEvents, payloadBytes), nil
+}
+
+func NewDeliverWebhookTask(payload DeliverWebhookPayload) (*asynq.Task, error) {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		slog.Error("failed to marshal deliver webhook payload", "error", err)
+		return nil, err
+	}
+
+	return asynq.NewTask(TypeDeliverWebhook, payloadBytes), nil
+}
+
+func NewDispatchPayoutsTask(payload DispatchPayoutsPayload) (*asynq.Task, error) {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		slog.Error("failed to marshal dispatch payouts payload", "error", err)
+		return nil, err
+	}
+
+	return asynq.NewTask(TypeDispatchPayouts, payloadBytes), nil
+}
+
+func NewProcessPayoutTask(payload ProcessPayoutPayload) (*asynq.Task, error) {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		slog.Error("failed to marshal process payout payload", "error", err)
+		return nil, err
+	}
+
+	return asynq.NewTask(TypeProcessPayout, payloadBytes), nil
+}
@@ -3,16 +3,430 @@ package tasks
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/hibiken/asynq"
+	"github.com/luponetn/paycore/internal/mailer"
 )
 
-func HandleSendOTPEmailTask(ctx context.Context, t *asynq.Task) error {
-	var payload SendOTPEmailPayload
-	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
-		slog.Error("failed to unmarshal send otp email payload", "error", err)
+// otpActions completes the "Use the code below to ..." sentence of the OTP email per purpose.
+var otpActions = map[string]string{
+	"email_verification": "verify your email address",
+	"transaction":        "confirm your transfer",
+	"account_unlock":     "unlock your account",
+}
+
+func HandleSendOTPEmailTask(m mailer.Mailer) asynq.HandlerFunc {
+	return func(ctx context.Context, t *asynq.Task) error {
+		var payload SendOTPEmailPayload
+		if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+			slog.Error("failed to unmarshal send otp email payload", "error", err)
+			return fmt.Errorf("%w: %w", err, asynq.SkipRetry)
+		}
+
+		action, ok := otpActions[payload.Purpose]
+		if !ok {
+			action = "continue"
+		}
+
+		return sendEmail(ctx, m, mailer.TemplateOTP, payload.Email, mailer.OTPData{
+			Name:             payload.FullName,
+			Code:             payload.OTP,
+			Action:           action,
+			ExpiresInMinutes: payload.ExpiresInMinutes,
+		})
+	}
+}
+
+func HandleSendPasswordResetEmailTask(m mailer.Mailer) asynq.HandlerFunc {
+	return func(ctx context.Context, t *asynq.Task) error {
+		var payload SendPasswordResetEmailPayload
+		if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+			slog.Error("failed to unmarshal send password reset email payload", "error", err)
+			return fmt.Errorf("%w: %w", err, asynq.SkipRetry)
+		}
+
+		return sendEmail(ctx, m, mailer.TemplatePasswordReset, payload.Email, mailer.PasswordResetData{
+			Name:             payload.FullName,
+			Code:             payload.Code,
+			Link:             payload.Link,
+			ExpiresInMinutes: payload.ExpiresInMinutes,
+		})
+	}
+}
+
+func HandleSendTransferReceiptEmailTask(m mailer.Mailer) asynq.HandlerFunc {
+	return func(ctx context.Context, t *asynq.Task) error {
+		var payload SendTransferReceiptEmailPayload
+		if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+			slog.Error("failed to unmarshal send transfer receipt email payload", "error", err)
+			return fmt.Errorf("%w: %w", err, asynq.SkipRetry)
+		}
+
+		return sendEmail(ctx, m, mailer.TemplateTransferReceipt, payload.Email, mailer.TransferReceiptData{
+			Name:          payload.FullName,
+			Direction:     payload.Direction,
+			Amount:        payload.Amount,
+			Currency:      payload.Currency,
+			Counterparty:  payload.Counterparty,
+			Description:   payload.Description,
+			TransactionID: payload.TransactionID,
+			OccurredAt:    payload.OccurredAt,
+		})
+	}
+}
+
+func HandleSendSecurityAlertEmailTask(m mailer.Mailer) asynq.HandlerFunc {
+	return func(ctx context.Context, t *asynq.Task) error {
+		var payload SendSecurityAlertEmailPayload
+		if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+			slog.Error("failed to unmarshal send security alert email payload", "error", err)
+			return fmt.Errorf("%w: %w", err, asynq.SkipRetry)
+		}
+
+		return sendEmail(ctx, m, mailer.TemplateSecurityAlert, payload.Email, mailer.SecurityAlertData{
+			Name:       payload.FullName,
+			Event:      payload.Event,
+			IPAddress:  payload.IPAddress,
+			UserAgent:  payload.UserAgent,
+			OccurredAt: payload.OccurredAt,
+		})
+	}
+}
+
+// sendEmail renders and delivers one message. Permanent failures skip asynq's retries;
+// everything else is returned as-is so the task is retried with backoff.
+func sendEmail(ctx context.Context, m mailer.Mailer, template string, to string, data any) error {
+	msg, err := mailer.Render(template, to, data)
+	if err == nil {
+		err = m.Send(ctx, msg)
+	}
+	if err != nil {
+		slog.Error("failed to send email", "template", template, "error", err)
+		if errors.Is(err, mailer.ErrPermanent) {
+			return fmt.Errorf("%w: %w", err, asynq.SkipRetry)
+		}
 		return err
 	}
 	return nil
 }
+
+// HandleReleaseExpiredHoldsTask returns a handler that releases stale holds in batches
+// until none are left, so one run clears any backlog.
+func HandleReleaseExpiredHoldsTask(releaser HoldReleaser) asynq.HandlerFunc {
+	return func(ctx context.Context, t *asynq.Task) error {
+		var payload ReleaseExpiredHoldsPayload
+		if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+			slog.Error("failed to unmarshal release expired holds payload", "error", err)
+			return err
+		}
+
+		batchSize := payload.BatchSize
+		if batchSize <= 0 {
+			batchSize = 100
+		}
+
+		total := 0
+		for {
+			released, err := releaser.ReleaseExpiredHolds(ctx, batchSize)
+			if err != nil {
+				slog.Error("failed to release expired holds", "error", err, "released", total)
+				return err
+			}
+			total += released
+			if released < int(batchSize) {
+				break
+			}
+		}
+
+		slog.Info("released expired holds", "count", total)
+		return nil
+	}
+}
+
+// HandleDispatchScheduledTransfersTask returns a handler that fans due schedules out into one
+// execute task each. Tasks are unique per schedule, so overlapping dispatch runs do not queue
+// the same schedule twice; the per-occurrence idempotency key guards against anything that slips through.
+func HandleDispatchScheduledTransfersTask(runner ScheduledTransferRunner, client *asynq.Client) asynq.HandlerFunc {
+	return func(ctx context.Context, t *asynq.Task) error {
+		var payload DispatchScheduledTransfersPayload
+		if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+			slog.Error("failed to unmarshal dispatch scheduled transfers payload", "error", err)
+			return err
+		}
+
+		batchSize := payload.BatchSize
+		if batchSize <= 0 {
+			batchSize = 500
+		}
+
+		ids, err := runner.DueScheduleIDs(ctx, batchSize)
+		if err != nil {
+			slog.Error("failed to fetch due schedules", "error", err)
+			return err
+		}
+
+		for _, id := range ids {
+			task, err := NewExecuteScheduledTransferTask(ExecuteScheduledTransferPayload{ScheduleID: id})
+			if err != nil {
+				return err
+			}
+
+			if _, err := client.EnqueueContext(ctx, task, asynq.Queue(QueueCritical), asynq.Unique(5*time.Minute), asynq.MaxRetry(5)); err != nil && !errors.Is(err, asynq.ErrDuplicateTask) {
+				slog.Error("failed to enqueue scheduled transfer", "schedule_id", id, "error", err)
+				return err
+			}
+		}
+
+		slog.Info("dispatched scheduled transfers", "count", len(ids))
+		return nil
+	}
+}
+
+func HandleExecuteScheduledTransferTask(runner ScheduledTransferRunner) asynq.HandlerFunc {
+	return func(ctx context.Context, t *asynq.Task) error {
+		var payload ExecuteScheduledTransferPayload
+		if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+			slog.Error("failed to unmarshal execute scheduled transfer payload", "error", err)
+			return err
+		}
+
+		if err := runner.ExecuteSchedule(ctx, payload.ScheduleID); err != nil {
+			slog.Error("failed to execute scheduled transfer", "schedule_id", payload.ScheduleID, "error", err)
+			return err
+		}
+		return nil
+	}
+}
+
+// HandleAccrueDepositInterestTask returns a handler that accrues interest, and settles matured
+// deposits, for up to one batch of deposits per run. Accrual is idempotent per day, so a failed
+// deposit is simply picked up again by the next run; the others in the batch still go through.
+func HandleAccrueDepositInterestTask(accruer DepositAccruer) asynq.HandlerFunc {
+	return func(ctx context.Context, t *asynq.Task) error {
+		var payload AccrueDepositInterestPayload
+		if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+			slog.Error("failed to unmarshal accrue deposit interest payload", "error", err)
+			return err
+		}
+
+		batchSize := payload.BatchSize
+		if batchSize <= 0 {
+			batchSize = 500
+		}
+
+		asOf := time.Now()
+		ids, err := accruer.DueDepositIDs(ctx, asOf, batchSize)
+		if err != nil {
+			slog.Error("failed to fetch deposits due for accrual", "error", err)
+			return err
+		}
+
+		var lastErr error
+		failed := 0
+		for _, id := range ids {
+			if err := accruer.AccrueDeposit(ctx, id, asOf); err != nil {
+				slog.Error("failed to accrue deposit interest", "deposit_id", id, "error", err)
+				lastErr = err
+				failed++
+			}
+		}
+
+		slog.Info("accrued deposit interest", "count", len(ids)-failed, "failed", failed)
+		return lastErr
+	}
+}
+
+// HandleAccrueSavingsInterestTask returns a handler that records yesterday's accrual for up to
+// one batch of savings wallets, then capitalises earlier months' accruals once the whole day is
+// recorded, so a month is never posted while its last day is still missing for some wallets.
+func HandleAccrueSavingsInterestTask(engine SavingsInterestEngine) asynq.HandlerFunc {
+	return func(ctx context.Context, t *asynq.Task) error {
+		var payload AccrueSavingsInterestPayload
+		if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+			slog.Error("failed to unmarshal accrue savings interest payload", "error", err)
+			return err
+		}
+
+		batchSize := payload.BatchSize
+		if batchSize <= 0 {
+			batchSize = 500
+		}
+
+		now := time.Now()
+		day := now.AddDate(0, 0, -1)
+		ids, err := engine.DueWalletIDs(ctx, day, batchSize)
+		if err != nil {
+			slog.Error("failed to fetch wallets due for interest accrual", "error", err)
+			return err
+		}
+
+		var lastErr error
+		failed := 0
+		for _, id := range ids {
+			if err := engine.AccrueWallet(ctx, id, day); err != nil {
+				slog.Error("failed to accrue savings interest", "wallet_id", id, "error", err)
+				lastErr = err
+				failed++
+			}
+		}
+		slog.Info("accrued savings interest", "count", len(ids)-failed, "failed", failed)
+
+		if len(ids) == int(batchSize) || lastErr != nil {
+			return lastErr
+		}
+
+		due, err := engine.CapitalisationDue(ctx, now, batchSize)
+		if err != nil {
+			slog.Error("failed to fetch wallets due for interest capitalisation", "error", err)
+			return err
+		}
+
+		failed = 0
+		for _, id := range due {
+			if err := engine.CapitaliseWallet(ctx, id, now); err != nil {
+				slog.Error("failed to capitalise savings interest", "wallet_id", id, "error", err)
+				lastErr = err
+				failed++
+			}
+		}
+		if len(due) > 0 {
+			slog.Info("capitalised savings interest", "count", len(due)-failed, "failed", failed)
+		}
+		return lastErr
+	}
+}
+
+// maxRelayBatches bounds one relay run so a large backlog cannot hold the task forever; the
+// next run picks up where this one stopped.
+const maxRelayBatches = 20
+
+// HandleRelayOutboxEventsTask returns a handler that relays outbox events in batches until a
+// batch comes back short.
+func HandleRelayOutboxEventsTask(relayer OutboxRelayer) asynq.HandlerFunc {
+	return func(ctx context.Context, t *asynq.Task) error {
+		var payload RelayOutboxEventsPayload
+		if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+			slog.Error("failed to unmarshal relay outbox events payload", "error", err)
+			return err
+		}
+
+		batchSize := payload.BatchSize
+		if batchSize <= 0 {
+			batchSize = 100
+		}
+
+		total := 0
+		for range maxRelayBatches {
+			published, err := relayer.RelayBatch(ctx, batchSize)
+			if err != nil {
+				slog.Error("failed to relay outbox events", "error", err, "published", total)
+				return err
+			}
+			total += published
+			if published < int(batchSize) {
+				break
+			}
+		}
+
+		if total > 0 {
+			slog.Info("relayed outbox events", "count", total)
+		}
+		return nil
+	}
+}
+
+// HandleDomainEventTask returns the handler for relayed domain events, registered on the event
+// task prefix: it fans each event out to the webhook endpoints subscribed to it. Delivery ids
+// are unique per endpoint and event, so a redelivered event does not queue twice.
+func HandleDomainEventTask(deliverer WebhookDeliverer) asynq.HandlerFunc {
+	return func(ctx context.Context, t *asynq.Task) error {
+		queued, err := deliverer.DispatchEvent(ctx, t.Payload())
+		if err != nil {
+			slog.Error("failed to dispatch domain event", "type", t.Type(), "error", err)
+			return err
+		}
+
+		slog.Info("domain event dispatched", "type", t.Type(), "webhooks", queued)
+		return nil
+	}
+}
+
+// HandleDeliverWebhookTask returns a handler that makes one delivery attempt. The service
+// schedules any retry itself, so an error here means the attempt could not be recorded.
+func HandleDeliverWebhookTask(deliverer WebhookDeliverer) asynq.HandlerFunc {
+	return func(ctx context.Context, t *asynq.Task) error {
+		var payload DeliverWebhookPayload
+		if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+			slog.Error("failed to unmarshal deliver webhook payload", "error", err)
+			return err
+		}
+
+		if err := deliverer.Deliver(ctx, payload.DeliveryID); err != nil {
+			slog.Error("failed to deliver webhook", "delivery_id", payload.DeliveryID, "error", err)
+			return err
+		}
+		return nil
+	}
+}
+
+// HandleDispatchPayoutsTask returns a handler that fans payouts waiting on the provider out into
+// one process task each. Tasks are unique per payout, so a payout is never worked on twice at
+// once; each step checks the payout's status under a row lock as well.
+func HandleDispatchPayoutsTask(processor PayoutProcessor, client *asynq.Client) asynq.HandlerFunc {
+	return func(ctx context.Context, t *asynq.Task) error {
+		var payload DispatchPayoutsPayload
+		if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+			slog.Error("failed to unmarshal dispatch payouts payload", "error", err)
+			return err
+		}
+
+		batchSize := payload.BatchSize
+		if batchSize <= 0 {
+			batchSize = 100
+		}
+
+		ids, err := processor.DuePayoutIDs(ctx, batchSize)
+		if err != nil {
+			slog.Error("failed to fetch due payouts", "error", err)
+			return err
+		}
+
+		for _, id := range ids {
+			task, err := NewProcessPayoutTask(ProcessPayoutPayload{PayoutID: id})
+			if err != nil {
+				return err
+			}
+
+			// failed steps are not retried here; the payout stays due and the next dispatch picks it up
+			if _, err := client.EnqueueContext(ctx, task, asynq.Queue(QueueCritical), asynq.Unique(time.Minute), asynq.MaxRetry(0)); err != nil && !errors.Is(err, asynq.ErrDuplicateTask) {
+				slog.Error("failed to enqueue payout", "payout_id", id, "error", err)
+				return err
+			}
+		}
+
+		if len(ids) > 0 {
+			slog.Info("dispatched payouts", "count", len(ids))
+		}
+		return nil
+	}
+}
+
+func HandleProcessPayoutTask(processor PayoutProcessor) asynq.HandlerFunc {
+	return func(ctx context.Context, t *asynq.Task) error {
+		var payload ProcessPayoutPayload
+		if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+			slog.Error("failed to unmarshal process payout payload", "error", err)
+			return err
+		}
+
+		if err := processor.ProcessPayout(ctx, payload.PayoutID); err != nil {
+			slog.Error("failed to process payout", "payout_id", payload.PayoutID, "error", err)
+			return err
+		}
+		return nil
+	}
+}
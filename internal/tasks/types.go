@@ -1,11 +1,160 @@
 package tasks
 
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Queue names; their relative priorities come from config.Config.WorkerQueues.
 const (
-	TypeSendOTPEmail = "task:send_otp_email"
+	QueueCritical = "critical"
+	QueueDefault  = "default"
+	QueueLow      = "low"
+)
+
+const (
+	TypeSendOTPEmail             = "task:send_otp_email"
+	TypeSendPasswordResetEmail   = "task:send_password_reset_email"
+	TypeSendTransferReceiptEmail = "task:send_transfer_receipt_email"
+	TypeSendSecurityAlertEmail   = "task:send_security_alert_email"
+	TypeReleaseExpiredHolds      = "task:release_expired_holds"
+
+	TypeDispatchScheduledTransfers = "task:dispatch_scheduled_transfers"
+	TypeExecuteScheduledTransfer   = "task:execute_scheduled_transfer"
+
+	TypeAccrueDepositInterest = "task:accrue_deposit_interest"
+	TypeAccrueSavingsInterest = "task:accrue_savings_interest"
+
+	TypeRelayOutboxEvents = "task:relay_outbox_events"
+	TypeDeliverWebhook    = "task:deliver_webhook"
+
+	TypeDispatchPayouts = "task:dispatch_payouts"
+	TypeProcessPayout   = "task:process_payout"
+
+	// TypeDomainEventPrefix prefixes the task type of every relayed domain event, e.g.
+	// "event:transfer.completed".
+	TypeDomainEventPrefix = "event:"
 )
 
 type SendOTPEmailPayload struct {
-	UserID string `json:"user_id"`
-	Email  string `json:"email"`
-	OTP    string `json:"otp"`
+	UserID   string `json:"user_id"`
+	Email    string `json:"email"`
+	FullName string `json:"full_name"`
+	OTP      string `json:"otp"`
+	Purpose  string `json:"purpose"`
+
+	ExpiresInMinutes int `json:"expires_in_minutes"`
+}
+
+type SendPasswordResetEmailPayload struct {
+	UserID   string `json:"user_id"`
+	Email    string `json:"email"`
+	FullName string `json:"full_name"`
+	Code     string `json:"code,omitempty"`
+	Link     string `json:"link,omitempty"`
+
+	ExpiresInMinutes int `json:"expires_in_minutes"`
+}
+
+type SendTransferReceiptEmailPayload struct {
+	Email         string    `json:"email"`
+	FullName      string    `json:"full_name"`
+	Direction     string    `json:"direction"`
+	Amount        string    `json:"amount"`
+	Currency      string    `json:"currency"`
+	Counterparty  string    `json:"counterparty"`
+	Description   string    `json:"description"`
+	TransactionID string    `json:"transaction_id"`
+	OccurredAt    time.Time `json:"occurred_at"`
+}
+
+type SendSecurityAlertEmailPayload struct {
+	UserID     string    `json:"user_id"`
+	Email      string    `json:"email"`
+	FullName   string    `json:"full_name"`
+	Event      string    `json:"event"`
+	IPAddress  string    `json:"ip_address"`
+	UserAgent  string    `json:"user_agent"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+type ReleaseExpiredHoldsPayload struct {
+	BatchSize int32 `json:"batch_size"`
+}
+
+// HoldReleaser is implemented by transfer.Service; kept as an interface so this package does not import it.
+type HoldReleaser interface {
+	ReleaseExpiredHolds(ctx context.Context, limit int32) (int, error)
+}
+
+type DispatchScheduledTransfersPayload struct {
+	BatchSize int32 `json:"batch_size"`
+}
+
+type ExecuteScheduledTransferPayload struct {
+	ScheduleID uuid.UUID `json:"schedule_id"`
+}
+
+// ScheduledTransferRunner is implemented by schedule.Service.
+type ScheduledTransferRunner interface {
+	DueScheduleIDs(ctx context.Context, limit int32) ([]uuid.UUID, error)
+	ExecuteSchedule(ctx context.Context, scheduleID uuid.UUID) error
+}
+
+type AccrueDepositInterestPayload struct {
+	BatchSize int32 `json:"batch_size"`
+}
+
+// DepositAccruer is implemented by deposit.Service.
+type DepositAccruer interface {
+	DueDepositIDs(ctx context.Context, asOf time.Time, limit int32) ([]uuid.UUID, error)
+	AccrueDeposit(ctx context.Context, depositID uuid.UUID, asOf time.Time) error
+}
+
+type AccrueSavingsInterestPayload struct {
+	BatchSize int32 `json:"batch_size"`
+}
+
+// SavingsInterestEngine is implemented by interest.Service.
+type SavingsInterestEngine interface {
+	DueWalletIDs(ctx context.Context, day time.Time, limit int32) ([]uuid.UUID, error)
+	AccrueWallet(ctx context.Context, walletID uuid.UUID, day time.Time) error
+	CapitalisationDue(ctx context.Context, asOf time.Time, limit int32) ([]uuid.UUID, error)
+	CapitaliseWallet(ctx context.Context, walletID uuid.UUID, asOf time.Time) error
+}
+
+type RelayOutboxEventsPayload struct {
+	BatchSize int32 `json:"batch_size"`
+}
+
+// OutboxRelayer is implemented by *outbox.Relay.
+type OutboxRelayer interface {
+	RelayBatch(ctx context.Context, limit int32) (int, error)
+}
+
+type DeliverWebhookPayload struct {
+	DeliveryID uuid.UUID `json:"delivery_id"`
+}
+
+// WebhookDeliverer is implemented by webhook.Service. DispatchEvent takes a relayed
+// outbox.Event as JSON and returns how many deliveries it queued.
+type WebhookDeliverer interface {
+	DispatchEvent(ctx context.Context, event []byte) (int, error)
+	Deliver(ctx context.Context, deliveryID uuid.UUID) error
+}
+
+type DispatchPayoutsPayload struct {
+	BatchSize int32 `json:"batch_size"`
+}
+
+type ProcessPayoutPayload struct {
+	PayoutID uuid.UUID `json:"payout_id"`
+}
+
+// PayoutProcessor is implemented by payout.Service.
+type PayoutProcessor interface {
+	DuePayoutIDs(ctx context.Context, limit int32) ([]uuid.UUID, error)
+	ProcessPayout(ctx context.Context, payoutID uuid.UUID) error
 }
@@ -1,13 +1,40 @@
 package transfer
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/luponetn/paycore/internal/ledger"
+	"github.com/luponetn/paycore/internal/otp"
+)
 
 var (
-	ErrInsufficientFunds   = errors.New("insufficient funds")
+	ErrInsufficientFunds   = ledger.ErrInsufficientFunds
 	ErrSameWallet         = errors.New("sender and receiver wallet cannot be the same")
-	ErrCurrencyMismatch   = errors.New("wallet currencies must be the same")
+	ErrCurrencyMismatch   = ledger.ErrCurrencyMismatch
 	ErrInvalidAmount       = errors.New("amount must be greater than 0")
-	ErrWalletNotFound      = errors.New("wallet not found")
+	ErrWalletNotFound      = ledger.ErrWalletNotFound
 	ErrUnauthorizedWallet  = errors.New("you do not own this wallet")
 	ErrTransactionFailed   = errors.New("transaction failed")
+	ErrIdempotencyKeyReused = ledger.ErrIdempotencyKeyReused
+	ErrEmailNotVerified    = errors.New("verify your email before moving money")
+	ErrWalletNotMatured    = errors.New("fixed wallet cannot be debited before it matures")
+	ErrHoldNotFound        = errors.New("hold not found")
+	ErrHoldNotActive       = errors.New("hold is no longer active")
+	ErrHoldExpired         = errors.New("hold has expired")
+	ErrCaptureExceedsHold  = errors.New("capture amount exceeds the remaining held amount")
+	ErrInvalidHoldExpiry   = errors.New("hold expiry exceeds the maximum hold duration")
+	ErrTransactionNotFound = errors.New("transaction not found")
+	ErrNotRefundable       = errors.New("only completed transfers can be reversed or refunded")
+	ErrAlreadyRefunded     = errors.New("transaction has already been fully reversed or refunded")
+	ErrRefundExceedsOriginal = errors.New("refund amount exceeds the amount left to refund")
+	ErrRefundOverdraw      = errors.New("receiver does not have enough available funds for this refund")
+	ErrPINNotSet           = errors.New("set a transaction pin before moving money")
+	ErrInvalidPIN          = errors.New("incorrect transaction pin")
+	ErrPINLocked           = errors.New("transaction pin is locked after too many incorrect attempts, try again later")
+	ErrWeakPIN             = errors.New("transaction pin is too easy to guess")
+	ErrInvalidPassword     = errors.New("incorrect password")
+	ErrStepUpRequired      = errors.New("additional verification required, enter the code sent to your email")
+	ErrOTPInvalid          = otp.ErrInvalid
+	ErrOTPExpired          = otp.ErrExpired
+	ErrOTPAttemptsExceeded = otp.ErrAttemptsExceeded
 )
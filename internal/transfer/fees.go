@@ -0,0 +1,72 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/fees"
+	"github.com/luponetn/paycore/pkg/utils"
+	"github.com/shopspring/decimal"
+)
+
+// QuoteTransaction previews the fee a transaction would be charged from one of the user's
+// wallets, without checking funds or moving money.
+func (s *Svc) QuoteTransaction(ctx context.Context, userID uuid.UUID, req QuoteTransactionRequest) (fees.Breakdown, error) {
+	ctx, cancel := context.WithTimeout(ctx, 8*time.Second)
+	defer cancel()
+
+	amount, err := ParseAmount(req.Amount, req.Currency)
+	if err != nil {
+		return fees.Breakdown{}, err
+	}
+
+	senderID, err := uuid.Parse(req.SenderWalletID)
+	if err != nil {
+		return fees.Breakdown{}, errors.New("invalid sender wallet id")
+	}
+
+	wallet, err := s.store.Queries().GetWalletById(ctx, senderID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fees.Breakdown{}, ErrWalletNotFound
+		}
+		return fees.Breakdown{}, err
+	}
+	if !wallet.UserID.Valid || uuid.UUID(wallet.UserID.Bytes) != userID {
+		return fees.Breakdown{}, ErrUnauthorizedWallet
+	}
+	if wallet.Currency != amount.Code() {
+		return fees.Breakdown{}, ErrCurrencyMismatch
+	}
+
+	return s.quoteFee(ctx, s.store.Queries(), userID, db.TransactionTypeEnum(req.TransactionType), amount.Code(), amount.Amount())
+}
+
+// FeeBreakdown explains the fee charged on a transaction.
+func (s *Svc) FeeBreakdown(ctx context.Context, transaction db.Transaction) (fees.Breakdown, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return fees.Explain(ctx, s.store.Queries(), transaction)
+}
+
+// quoteFee prices a transaction for the user's KYC tier.
+func (s *Svc) quoteFee(ctx context.Context, q db.Querier, userID uuid.UUID, txType db.TransactionTypeEnum, currency string, amount decimal.Decimal) (fees.Breakdown, error) {
+	user, err := q.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fees.Breakdown{}, ErrUnauthorizedWallet
+		}
+		return fees.Breakdown{}, &utils.RetryableError{Err: err}
+	}
+
+	quote, err := fees.Quote(ctx, q, txType, currency, user.KycTier, amount)
+	if err != nil {
+		return fees.Breakdown{}, &utils.RetryableError{Err: err}
+	}
+	return quote, nil
+}
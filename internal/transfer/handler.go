@@ -2,6 +2,7 @@ package transfer
 
 import (
 	"errors"
+	"log/slog"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -34,18 +35,36 @@ func (h *Handler) HandleCreateTransaction(c *gin.Context) {
 		return
 	}
 
-	transaction, err := h.svc.CreateTransaction(c.Request.Context(), userID, req)
+	sessionID, _ := c.Get("session_id")
+	sid, _ := sessionID.(uuid.UUID)
+
+	transaction, err := h.svc.CreateTransaction(c.Request.Context(), userID, sid, req)
 	if err != nil {
+		var stepUp *StepUpRequiredError
+		if errors.As(err, &stepUp) {
+			c.JSON(http.StatusAccepted, gin.H{
+				"message":         "enter the code sent to your email to complete the transfer",
+				"error":           ErrStepUpRequired.Error(),
+				"otp_required":    true,
+				"step_up_reasons": stepUp.Reasons,
+			})
+			return
+		}
+
 		status := http.StatusInternalServerError
 		switch {
-		case errors.Is(err, ErrInsufficientFunds):
+		case errors.Is(err, ErrInsufficientFunds), errors.Is(err, ErrWalletNotMatured), errors.Is(err, ErrIdempotencyKeyReused):
 			status = http.StatusConflict
-		case errors.Is(err, ErrSameWallet), errors.Is(err, ErrInvalidAmount), errors.Is(err, ErrCurrencyMismatch):
+		case errors.Is(err, ErrSameWallet), IsInvalidAmount(err), errors.Is(err, ErrCurrencyMismatch):
 			status = http.StatusBadRequest
 		case errors.Is(err, ErrWalletNotFound):
 			status = http.StatusNotFound
-		case errors.Is(err, ErrUnauthorizedWallet):
+		case errors.Is(err, ErrUnauthorizedWallet), errors.Is(err, ErrEmailNotVerified):
 			status = http.StatusForbidden
+		default:
+			if pinStatus := pinErrorStatus(err); pinStatus != 0 {
+				status = pinStatus
+			}
 		}
 
 		c.AbortWithStatusJSON(status, gin.H{
@@ -55,9 +74,51 @@ func (h *Handler) HandleCreateTransaction(c *gin.Context) {
 		return
 	}
 
+	// The money has moved by now, so a failure to explain the fee is logged rather than returned.
+	breakdown, err := h.svc.FeeBreakdown(c.Request.Context(), transaction)
+	if err != nil {
+		slog.Error("failed to build fee breakdown", "transaction_id", transaction.ID, "error", err)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "transaction created successfully",
-		"data":    transaction,
+		"data":    TransactionResponse{Transaction: transaction, FeeBreakdown: breakdown},
+	})
+}
+
+func (h *Handler) HandleQuoteTransaction(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	var req QuoteTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	quote, err := h.svc.QuoteTransaction(c.Request.Context(), userID, req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case IsInvalidAmount(err), errors.Is(err, ErrCurrencyMismatch):
+			status = http.StatusBadRequest
+		case errors.Is(err, ErrWalletNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, ErrUnauthorizedWallet):
+			status = http.StatusForbidden
+		}
+		c.AbortWithStatusJSON(status, gin.H{
+			"message": "failed to quote transaction",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "transaction quoted successfully",
+		"data":    quote,
 	})
 }
 
@@ -87,3 +148,299 @@ func (h *Handler) HandleGetTransactionByID(c *gin.Context) {
 		"transaction": transaction,
 	})
 }
+
+func (h *Handler) HandlePlaceHold(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	var req PlaceHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	hold, err := h.svc.PlaceHold(c.Request.Context(), userID, req)
+	if err != nil {
+		c.AbortWithStatusJSON(holdErrorStatus(err), gin.H{
+			"message": "failed to place hold",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "hold placed successfully",
+		"data":    hold,
+	})
+}
+
+func (h *Handler) HandleCaptureHold(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	holdID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid hold id"})
+		return
+	}
+
+	var req CaptureHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	transaction, err := h.svc.CaptureHold(c.Request.Context(), userID, holdID, req)
+	if err != nil {
+		c.AbortWithStatusJSON(holdErrorStatus(err), gin.H{
+			"message": "failed to capture hold",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "hold captured successfully",
+		"data":    transaction,
+	})
+}
+
+func (h *Handler) HandleExtendHold(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	holdID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid hold id"})
+		return
+	}
+
+	var req ExtendHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	hold, err := h.svc.ExtendHold(c.Request.Context(), userID, holdID, req)
+	if err != nil {
+		c.AbortWithStatusJSON(holdErrorStatus(err), gin.H{
+			"message": "failed to extend hold",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "hold extended successfully",
+		"data":    hold,
+	})
+}
+
+func (h *Handler) HandleVoidHold(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	holdID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid hold id"})
+		return
+	}
+
+	hold, err := h.svc.VoidHold(c.Request.Context(), userID, holdID)
+	if err != nil {
+		c.AbortWithStatusJSON(holdErrorStatus(err), gin.H{
+			"message": "failed to void hold",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "hold voided successfully",
+		"data":    hold,
+	})
+}
+
+// authUserID reads the authenticated user id set by the auth middleware, aborting the request if it is missing.
+func authUserID(c *gin.Context) (uuid.UUID, bool) {
+	userIdVal, exists := c.Get("user_id")
+	if !exists {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return uuid.Nil, false
+	}
+	userID, ok := userIdVal.(uuid.UUID)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "invalid user id type"})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+func holdErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrInsufficientFunds), errors.Is(err, ErrHoldNotActive), errors.Is(err, ErrHoldExpired),
+		errors.Is(err, ErrWalletNotMatured):
+		return http.StatusConflict
+	case errors.Is(err, ErrSameWallet), IsInvalidAmount(err), errors.Is(err, ErrCurrencyMismatch),
+		errors.Is(err, ErrCaptureExceedsHold), errors.Is(err, ErrInvalidHoldExpiry):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrWalletNotFound), errors.Is(err, ErrHoldNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrUnauthorizedWallet), errors.Is(err, ErrEmailNotVerified):
+		return http.StatusForbidden
+	}
+	if status := pinErrorStatus(err); status != 0 {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+func (h *Handler) HandleSetPIN(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	var req SetPINRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	meta := RequestMeta{IPAddress: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+	if err := h.svc.SetPIN(c.Request.Context(), userID, req, meta); err != nil {
+		status := pinErrorStatus(err)
+		if status == 0 {
+			status = http.StatusInternalServerError
+		}
+		c.AbortWithStatusJSON(status, gin.H{
+			"message": "failed to set transaction pin",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "transaction pin set successfully"})
+}
+
+func (h *Handler) HandlePINStatus(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.svc.PINStatus(c.Request.Context(), userID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"message": "failed to fetch transaction pin status",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": status})
+}
+
+// pinErrorStatus maps PIN and step-up OTP failures, returning 0 for anything else.
+func pinErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrPINNotSet):
+		return http.StatusForbidden
+	case errors.Is(err, ErrInvalidPIN), errors.Is(err, ErrInvalidPassword):
+		return http.StatusUnauthorized
+	case errors.Is(err, ErrPINLocked), errors.Is(err, ErrOTPAttemptsExceeded):
+		return http.StatusTooManyRequests
+	case errors.Is(err, ErrWeakPIN), errors.Is(err, ErrOTPInvalid), errors.Is(err, ErrOTPExpired):
+		return http.StatusBadRequest
+	}
+	return 0
+}
+
+func (h *Handler) HandleReverseTransaction(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	transactionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid transaction id"})
+		return
+	}
+
+	var req ReverseTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	transaction, err := h.svc.ReverseTransaction(c.Request.Context(), userID, transactionID, req)
+	if err != nil {
+		c.AbortWithStatusJSON(refundErrorStatus(err), gin.H{
+			"message": "failed to reverse transaction",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "transaction reversed successfully",
+		"data":    transaction,
+	})
+}
+
+func (h *Handler) HandleRefundTransaction(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	transactionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid transaction id"})
+		return
+	}
+
+	var req RefundTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	transaction, err := h.svc.RefundTransaction(c.Request.Context(), userID, transactionID, req)
+	if err != nil {
+		c.AbortWithStatusJSON(refundErrorStatus(err), gin.H{
+			"message": "failed to refund transaction",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "transaction refunded successfully",
+		"data":    transaction,
+	})
+}
+
+func refundErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrAlreadyRefunded), errors.Is(err, ErrRefundOverdraw), errors.Is(err, ErrNotRefundable),
+		errors.Is(err, ErrWalletNotMatured):
+		return http.StatusConflict
+	case IsInvalidAmount(err), errors.Is(err, ErrRefundExceedsOriginal):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrTransactionNotFound), errors.Is(err, ErrWalletNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrUnauthorizedWallet):
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/pkg/money"
 )
 
 func CreateDoubleLedgerEntries(ctx context.Context, args db.CreateLedgerParams, qtx *db.Queries) error {
@@ -37,3 +40,49 @@ func GetUserBalance(ctx context.Context, walletID uuid.UUID, qtx *db.Queries) (i
 	}
 	return balance, nil
 }
+
+// checkDebitAllowed applies the per-wallet-type rules to a wallet about to be debited: a fixed
+// wallet stays locked until it matures.
+func checkDebitAllowed(wallet db.GetWalletsAndLockByWalletIdsRow, now time.Time) error {
+	if wallet.WalletType == db.WalletTypeEnumFixed && wallet.MaturesAt.Valid && now.Before(wallet.MaturesAt.Time) {
+		return ErrWalletNotMatured
+	}
+	return nil
+}
+
+// requireVerifiedEmail blocks money movement for users who have not verified their email yet.
+func (s *Svc) requireVerifiedEmail(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.store.Queries().GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrUnauthorizedWallet
+		}
+		return err
+	}
+	if !user.EmailVerifiedAt.Valid {
+		return ErrEmailNotVerified
+	}
+	return nil
+}
+
+// ParseAmount reads a request amount in currency. It must be positive and carry no more
+// decimals than the currency's minor unit.
+func ParseAmount(raw string, currency string) (money.Money, error) {
+	amount, err := money.Parse(raw, currency)
+	if errors.Is(err, money.ErrInvalidAmount) {
+		return money.Money{}, ErrInvalidAmount
+	}
+	if err != nil {
+		return money.Money{}, err
+	}
+	if !amount.IsPositive() {
+		return money.Money{}, ErrInvalidAmount
+	}
+	return amount, nil
+}
+
+// IsInvalidAmount reports whether err rejects a request amount: not a positive number, finer
+// than the currency's minor unit, or in a currency the platform does not know.
+func IsInvalidAmount(err error) bool {
+	return errors.Is(err, ErrInvalidAmount) || errors.Is(err, money.ErrTooPrecise) || errors.Is(err, money.ErrUnknownCurrency)
+}
@@ -0,0 +1,495 @@
+package transfer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/ledger"
+	"github.com/luponetn/paycore/pkg/money"
+	"github.com/luponetn/paycore/pkg/utils"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	defaultHoldTTL = 7 * 24 * time.Hour
+	maxHoldTTL     = 30 * 24 * time.Hour
+)
+
+// PlaceHold reserves funds on the sender wallet for a later capture by the receiver.
+// The ledger balance is untouched; only the sender's available balance drops.
+func (s *Svc) PlaceHold(ctx context.Context, userID uuid.UUID, req PlaceHoldRequest) (db.WalletHold, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	amount, err := ParseAmount(req.Amount, req.Currency)
+	if err != nil {
+		return db.WalletHold{}, err
+	}
+
+	senderID, err := uuid.Parse(req.SenderWalletID)
+	if err != nil {
+		return db.WalletHold{}, errors.New("invalid sender wallet id")
+	}
+
+	receiverID, err := uuid.Parse(req.ReceiverWalletID)
+	if err != nil {
+		return db.WalletHold{}, errors.New("invalid receiver wallet id")
+	}
+
+	if senderID == receiverID {
+		return db.WalletHold{}, ErrSameWallet
+	}
+
+	if err := s.requireVerifiedEmail(ctx, userID); err != nil {
+		return db.WalletHold{}, err
+	}
+
+	// the capture pays whoever the hold names, so placing one needs the PIN like a transfer
+	if err := s.VerifyPIN(ctx, userID, req.PIN); err != nil {
+		return db.WalletHold{}, err
+	}
+
+	ttl := defaultHoldTTL
+	if req.ExpiresInMinutes > 0 {
+		ttl = time.Duration(req.ExpiresInMinutes) * time.Minute
+	}
+	if ttl > maxHoldTTL {
+		return db.WalletHold{}, ErrInvalidHoldExpiry
+	}
+
+	return utils.Retry(3, 100, func() (db.WalletHold, error) {
+		tx, err := s.store.Begin(ctx)
+		if err != nil {
+			return db.WalletHold{}, &utils.RetryableError{Err: err}
+		}
+
+		defer func() {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback place hold tx", "error", rbErr)
+			}
+		}()
+
+		qtx := s.store.WithTx(tx)
+
+		wallets, err := qtx.GetWalletsAndLockByWalletIds(ctx, db.GetWalletsAndLockByWalletIdsParams{
+			ID:  senderID,
+			ID2: receiverID,
+		})
+		if err != nil {
+			return db.WalletHold{}, &utils.RetryableError{Err: err}
+		}
+
+		if len(wallets) != 2 {
+			return db.WalletHold{}, ErrWalletNotFound
+		}
+
+		senderWallet, receiverWallet := pickWallets(wallets, senderID)
+
+		if !senderWallet.UserID.Valid || uuid.UUID(senderWallet.UserID.Bytes) != userID {
+			return db.WalletHold{}, ErrUnauthorizedWallet
+		}
+
+		if err := checkDebitAllowed(senderWallet, time.Now()); err != nil {
+			return db.WalletHold{}, err
+		}
+
+		if senderWallet.Currency != amount.Code() || receiverWallet.Currency != amount.Code() {
+			return db.WalletHold{}, ErrCurrencyMismatch
+		}
+
+		// The sender wallet lock serialises hold placement, so this lookup is race free.
+		if existing, err := qtx.GetWalletHoldByIdempotencyKey(ctx, req.IdempotencyKey); err == nil {
+			return existing, nil
+		} else if !errors.Is(err, pgx.ErrNoRows) {
+			return db.WalletHold{}, &utils.RetryableError{Err: err}
+		}
+
+		available, err := money.FromNumeric(senderWallet.AvailableBalance, senderWallet.Currency)
+		if err != nil {
+			return db.WalletHold{}, err
+		}
+		remaining, err := available.Sub(amount)
+		if err != nil {
+			return db.WalletHold{}, ErrCurrencyMismatch
+		}
+		if remaining.IsNegative() {
+			return db.WalletHold{}, ErrInsufficientFunds
+		}
+
+		hold, err := qtx.CreateWalletHold(ctx, db.CreateWalletHoldParams{
+			WalletID:         senderWallet.ID,
+			ReceiverWalletID: receiverWallet.ID,
+			Amount:           amount.Numeric(),
+			Currency:         amount.Code(),
+			Description:      pgtype.Text{String: req.Description, Valid: req.Description != ""},
+			IdempotencyKey:   req.IdempotencyKey,
+			ExpiresAt:        pgtype.Timestamptz{Time: time.Now().Add(ttl), Valid: true},
+		})
+		if err != nil {
+			return db.WalletHold{}, &utils.RetryableError{Err: err}
+		}
+
+		if err := qtx.UpdateWalletAvailableBalance(ctx, db.UpdateWalletAvailableBalanceParams{
+			AvailableBalance: remaining.Numeric(),
+			ID:               senderWallet.ID,
+		}); err != nil {
+			return db.WalletHold{}, &utils.RetryableError{Err: err}
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return db.WalletHold{}, &utils.RetryableError{Err: err}
+		}
+
+		return hold, nil
+	})
+}
+
+// CaptureHold moves some or all of the held amount to the receiver wallet. Each capture
+// posts the usual debit/credit ledger pair under its own transaction. When req.Final is
+// set, whatever is left on the hold after this capture is released back to the sender.
+// Idempotency keys are scoped to the hold, so a key replays only a capture of this hold.
+func (s *Svc) CaptureHold(ctx context.Context, userID uuid.UUID, holdID uuid.UUID, req CaptureHoldRequest) (db.Transaction, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	requested, err := decimal.NewFromString(req.Amount)
+	if err != nil || !requested.IsPositive() {
+		return db.Transaction{}, ErrInvalidAmount
+	}
+
+	return utils.Retry(3, 100, func() (db.Transaction, error) {
+		tx, err := s.store.Begin(ctx)
+		if err != nil {
+			return db.Transaction{}, &utils.RetryableError{Err: err}
+		}
+
+		defer func() {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback capture hold tx", "error", rbErr)
+			}
+		}()
+
+		qtx := s.store.WithTx(tx)
+
+		// Lock the hold first, then the wallets, so concurrent captures of the same hold queue up here.
+		hold, err := qtx.GetWalletHoldForUpdate(ctx, holdID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return db.Transaction{}, ErrHoldNotFound
+			}
+			return db.Transaction{}, &utils.RetryableError{Err: err}
+		}
+
+		wallets, err := qtx.GetWalletsAndLockByWalletIds(ctx, db.GetWalletsAndLockByWalletIdsParams{
+			ID:  hold.WalletID,
+			ID2: hold.ReceiverWalletID,
+		})
+		if err != nil {
+			return db.Transaction{}, &utils.RetryableError{Err: err}
+		}
+
+		if len(wallets) != 2 {
+			return db.Transaction{}, ErrWalletNotFound
+		}
+
+		senderWallet, receiverWallet := pickWallets(wallets, hold.WalletID)
+
+		if !receiverWallet.UserID.Valid || uuid.UUID(receiverWallet.UserID.Bytes) != userID {
+			return db.Transaction{}, ErrUnauthorizedWallet
+		}
+
+		key := captureKey(hold.ID, req.IdempotencyKey)
+		if existingTx, err := qtx.GetTransactionByIdempotencyKey(ctx, key); err == nil {
+			return existingTx, nil
+		} else if !errors.Is(err, pgx.ErrNoRows) {
+			return db.Transaction{}, &utils.RetryableError{Err: err}
+		}
+
+		if hold.Status != db.HoldStatusEnumActive {
+			return db.Transaction{}, ErrHoldNotActive
+		}
+
+		if !hold.ExpiresAt.Time.After(time.Now()) {
+			return db.Transaction{}, ErrHoldExpired
+		}
+
+		// the hold's currency decides how many decimals the capture may carry
+		amount, err := money.New(requested, hold.Currency)
+		if err != nil {
+			return db.Transaction{}, err
+		}
+		holdAmount := utils.NumericToDecimal(hold.Amount)
+		captured := utils.NumericToDecimal(hold.CapturedAmount)
+		remaining := holdAmount.Sub(captured)
+		if amount.Amount().GreaterThan(remaining) {
+			return db.Transaction{}, ErrCaptureExceedsHold
+		}
+
+		// The captured amount was already taken out of the sender's available balance when
+		// the hold was placed, so only the ledger balance moves on the sender side.
+		createdTransaction, err := ledger.Post(ctx, qtx, ledger.Journal{
+			TransactionType: db.TransactionTypeEnumTransfer,
+			Description:     hold.Description.String,
+			IdempotencyKey:  key,
+			Postings: []ledger.Posting{
+				ledger.Debit(senderWallet.ID, amount.Amount(), amount.Code()).AsLocked(),
+				ledger.Credit(receiverWallet.ID, amount.Amount(), amount.Code()),
+			},
+		})
+		if err != nil {
+			return db.Transaction{}, err
+		}
+
+		newCaptured := captured.Add(amount.Amount())
+		status := db.HoldStatusEnumActive
+		if newCaptured.Equal(holdAmount) {
+			status = db.HoldStatusEnumCaptured
+		} else if req.Final {
+			status = db.HoldStatusEnumCaptured
+			released := utils.NumericToDecimal(senderWallet.AvailableBalance).Add(holdAmount.Sub(newCaptured))
+			if err := qtx.UpdateWalletAvailableBalance(ctx, db.UpdateWalletAvailableBalanceParams{
+				AvailableBalance: utils.DecimalToNumeric(released),
+				ID:               senderWallet.ID,
+			}); err != nil {
+				return db.Transaction{}, &utils.RetryableError{Err: err}
+			}
+		}
+
+		if _, err := qtx.UpdateWalletHoldCapture(ctx, db.UpdateWalletHoldCaptureParams{
+			CapturedAmount: utils.DecimalToNumeric(newCaptured),
+			Status:         status,
+			ID:             hold.ID,
+		}); err != nil {
+			return db.Transaction{}, &utils.RetryableError{Err: err}
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return db.Transaction{}, &utils.RetryableError{Err: err}
+		}
+
+		return createdTransaction, nil
+	})
+}
+
+// captureKey namespaces a client's capture key to the hold being captured.
+func captureKey(holdID uuid.UUID, key string) string {
+	return "hold:" + holdID.String() + ":" + key
+}
+
+// ExtendHold pushes back the expiry of an active hold.
+func (s *Svc) ExtendHold(ctx context.Context, userID uuid.UUID, holdID uuid.UUID, req ExtendHoldRequest) (db.WalletHold, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	return utils.Retry(3, 100, func() (db.WalletHold, error) {
+		tx, err := s.store.Begin(ctx)
+		if err != nil {
+			return db.WalletHold{}, &utils.RetryableError{Err: err}
+		}
+
+		defer func() {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback extend hold tx", "error", rbErr)
+			}
+		}()
+
+		qtx := s.store.WithTx(tx)
+
+		hold, err := qtx.GetWalletHoldForUpdate(ctx, holdID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return db.WalletHold{}, ErrHoldNotFound
+			}
+			return db.WalletHold{}, &utils.RetryableError{Err: err}
+		}
+
+		if err := s.checkHoldReceiver(ctx, qtx, hold, userID); err != nil {
+			return db.WalletHold{}, err
+		}
+
+		if hold.Status != db.HoldStatusEnumActive {
+			return db.WalletHold{}, ErrHoldNotActive
+		}
+
+		if !hold.ExpiresAt.Time.After(time.Now()) {
+			return db.WalletHold{}, ErrHoldExpired
+		}
+
+		newExpiry := hold.ExpiresAt.Time.Add(time.Duration(req.ExtendByMinutes) * time.Minute)
+		if newExpiry.Sub(hold.CreatedAt.Time) > maxHoldTTL {
+			return db.WalletHold{}, ErrInvalidHoldExpiry
+		}
+
+		updated, err := qtx.UpdateWalletHoldExpiry(ctx, db.UpdateWalletHoldExpiryParams{
+			ExpiresAt: pgtype.Timestamptz{Time: newExpiry, Valid: true},
+			ID:        hold.ID,
+		})
+		if err != nil {
+			return db.WalletHold{}, &utils.RetryableError{Err: err}
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return db.WalletHold{}, &utils.RetryableError{Err: err}
+		}
+
+		return updated, nil
+	})
+}
+
+// VoidHold cancels an active hold and returns the uncaptured remainder to the sender's available balance.
+func (s *Svc) VoidHold(ctx context.Context, userID uuid.UUID, holdID uuid.UUID) (db.WalletHold, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	return utils.Retry(3, 100, func() (db.WalletHold, error) {
+		tx, err := s.store.Begin(ctx)
+		if err != nil {
+			return db.WalletHold{}, &utils.RetryableError{Err: err}
+		}
+
+		defer func() {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback void hold tx", "error", rbErr)
+			}
+		}()
+
+		qtx := s.store.WithTx(tx)
+
+		hold, err := qtx.GetWalletHoldForUpdate(ctx, holdID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return db.WalletHold{}, ErrHoldNotFound
+			}
+			return db.WalletHold{}, &utils.RetryableError{Err: err}
+		}
+
+		if err := s.checkHoldReceiver(ctx, qtx, hold, userID); err != nil {
+			return db.WalletHold{}, err
+		}
+
+		if hold.Status != db.HoldStatusEnumActive {
+			return db.WalletHold{}, ErrHoldNotActive
+		}
+
+		voided, err := releaseHold(ctx, qtx, hold, db.HoldStatusEnumVoided)
+		if err != nil {
+			return db.WalletHold{}, err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return db.WalletHold{}, &utils.RetryableError{Err: err}
+		}
+
+		return voided, nil
+	})
+}
+
+// ReleaseExpiredHolds expires up to limit stale active holds and returns how many were released.
+// Rows locked by another worker are skipped, so several workers can run this concurrently.
+func (s *Svc) ReleaseExpiredHolds(ctx context.Context, limit int32) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	return utils.Retry(3, 100, func() (int, error) {
+		tx, err := s.store.Begin(ctx)
+		if err != nil {
+			return 0, &utils.RetryableError{Err: err}
+		}
+
+		defer func() {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback release expired holds tx", "error", rbErr)
+			}
+		}()
+
+		qtx := s.store.WithTx(tx)
+
+		holds, err := qtx.GetExpiredActiveHolds(ctx, limit)
+		if err != nil {
+			return 0, &utils.RetryableError{Err: err}
+		}
+
+		// Release in wallet order so this batch takes wallet locks in the same order as transfers.
+		sort.Slice(holds, func(i, j int) bool {
+			return bytes.Compare(holds[i].WalletID[:], holds[j].WalletID[:]) < 0
+		})
+
+		for _, hold := range holds {
+			if _, err := releaseHold(ctx, qtx, hold, db.HoldStatusEnumExpired); err != nil {
+				return 0, err
+			}
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return 0, &utils.RetryableError{Err: err}
+		}
+
+		return len(holds), nil
+	})
+}
+
+// checkHoldReceiver locks both wallets of a hold in the usual deterministic order and
+// makes sure userID owns the wallet the hold is payable to.
+func (s *Svc) checkHoldReceiver(ctx context.Context, qtx db.Querier, hold db.WalletHold, userID uuid.UUID) error {
+	wallets, err := qtx.GetWalletsAndLockByWalletIds(ctx, db.GetWalletsAndLockByWalletIdsParams{
+		ID:  hold.WalletID,
+		ID2: hold.ReceiverWalletID,
+	})
+	if err != nil {
+		return &utils.RetryableError{Err: err}
+	}
+
+	if len(wallets) != 2 {
+		return ErrWalletNotFound
+	}
+
+	_, receiver := pickWallets(wallets, hold.WalletID)
+	if !receiver.UserID.Valid || uuid.UUID(receiver.UserID.Bytes) != userID {
+		return ErrUnauthorizedWallet
+	}
+	return nil
+}
+
+// releaseHold gives the uncaptured part of a locked hold back to the sender and closes it with status.
+func releaseHold(ctx context.Context, qtx db.Querier, hold db.WalletHold, status db.HoldStatusEnum) (db.WalletHold, error) {
+	wallet, err := qtx.GetWalletById(ctx, hold.WalletID)
+	if err != nil {
+		return db.WalletHold{}, &utils.RetryableError{Err: err}
+	}
+
+	remaining := utils.NumericToDecimal(hold.Amount).Sub(utils.NumericToDecimal(hold.CapturedAmount))
+	available := utils.NumericToDecimal(wallet.AvailableBalance).Add(remaining)
+
+	if err := qtx.UpdateWalletAvailableBalance(ctx, db.UpdateWalletAvailableBalanceParams{
+		AvailableBalance: utils.DecimalToNumeric(available),
+		ID:               wallet.ID,
+	}); err != nil {
+		return db.WalletHold{}, &utils.RetryableError{Err: err}
+	}
+
+	released, err := qtx.UpdateWalletHoldStatus(ctx, db.UpdateWalletHoldStatusParams{
+		Status: status,
+		ID:     hold.ID,
+	})
+	if err != nil {
+		return db.WalletHold{}, &utils.RetryableError{Err: err}
+	}
+
+	return released, nil
+}
+
+// pickWallets splits the rows returned by GetWalletsAndLockByWalletIds into sender and receiver.
+func pickWallets(wallets []db.GetWalletsAndLockByWalletIdsRow, senderID uuid.UUID) (db.GetWalletsAndLockByWalletIdsRow, db.GetWalletsAndLockByWalletIdsRow) {
+	if wallets[0].ID == senderID {
+		return wallets[0], wallets[1]
+	}
+	return wallets[1], wallets[0]
+}
@@ -0,0 +1,216 @@
+package transfer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/store"
+	"github.com/luponetn/paycore/pkg/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func setupHoldWallets(t *testing.T, f *store.FakeStore, balance string) (payer, merchant, payerWallet, merchantWallet uuid.UUID) {
+	t.Helper()
+
+	payer, merchant = uuid.New(), uuid.New()
+	payerWallet, merchantWallet = uuid.New(), uuid.New()
+
+	sender := db.GetWalletsAndLockByWalletIdsRow{
+		ID:       payerWallet,
+		UserID:   pgtype.UUID{Bytes: payer, Valid: true},
+		Currency: "NGN",
+	}
+	require.NoError(t, sender.Balance.Scan(balance))
+
+	receiver := db.GetWalletsAndLockByWalletIdsRow{
+		ID:       merchantWallet,
+		UserID:   pgtype.UUID{Bytes: merchant, Valid: true},
+		Currency: "NGN",
+	}
+	require.NoError(t, receiver.Balance.Scan("0"))
+
+	f.AddFakeWallet(sender)
+	f.AddFakeWallet(receiver)
+	setTestPIN(t, f, payer)
+	setTestPIN(t, f, merchant)
+	return payer, merchant, payerWallet, merchantWallet
+}
+
+func walletBalances(t *testing.T, f *store.FakeStore, id uuid.UUID) (string, string) {
+	t.Helper()
+	w, err := f.GetWalletById(context.Background(), id)
+	require.NoError(t, err)
+	return utils.NumericToDecimal(w.Balance).StringFixed(2), utils.NumericToDecimal(w.AvailableBalance).StringFixed(2)
+}
+
+func TestPlaceHold_ReservesAvailableBalance(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := newTestService(f)
+	payer, _, payerWallet, merchantWallet := setupHoldWallets(t, f, "100")
+
+	hold, err := svc.PlaceHold(context.Background(), payer, PlaceHoldRequest{
+		SenderWalletID:   payerWallet.String(),
+		ReceiverWalletID: merchantWallet.String(),
+		Amount:           "60.00",
+		Currency:         "NGN",
+		IdempotencyKey:   uuid.New().String(),
+		PIN:              testPIN,
+	})
+	require.NoError(t, err)
+	require.Equal(t, db.HoldStatusEnumActive, hold.Status)
+
+	balance, available := walletBalances(t, f, payerWallet)
+	require.Equal(t, "100.00", balance)
+	require.Equal(t, "40.00", available)
+
+	// A transfer can only spend what is not held.
+	_, err = svc.CreateTransaction(context.Background(), payer, uuid.Nil, CreateTransactionRequest{
+		SenderWalletID:   payerWallet.String(),
+		ReceiverWalletID: merchantWallet.String(),
+		TransactionType:  "transfer",
+		Amount:           "50.00",
+		Currency:         "NGN",
+		IdempotencyKey:   uuid.New().String(),
+		PIN:              testPIN,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestCaptureHold_PartialThenFinal(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := newTestService(f)
+	payer, merchant, payerWallet, merchantWallet := setupHoldWallets(t, f, "100")
+	ctx := context.Background()
+
+	hold, err := svc.PlaceHold(ctx, payer, PlaceHoldRequest{
+		SenderWalletID:   payerWallet.String(),
+		ReceiverWalletID: merchantWallet.String(),
+		Amount:           "60.00",
+		Currency:         "NGN",
+		IdempotencyKey:   uuid.New().String(),
+		PIN:              testPIN,
+	})
+	require.NoError(t, err)
+
+	_, err = svc.CaptureHold(ctx, payer, hold.ID, CaptureHoldRequest{Amount: "10.00", IdempotencyKey: uuid.New().String()})
+	require.ErrorIs(t, err, ErrUnauthorizedWallet)
+
+	_, err = svc.CaptureHold(ctx, merchant, hold.ID, CaptureHoldRequest{Amount: "70.00", IdempotencyKey: uuid.New().String()})
+	require.ErrorIs(t, err, ErrCaptureExceedsHold)
+
+	_, err = svc.CaptureHold(ctx, merchant, hold.ID, CaptureHoldRequest{Amount: "20.00", IdempotencyKey: uuid.New().String()})
+	require.NoError(t, err)
+
+	balance, available := walletBalances(t, f, payerWallet)
+	require.Equal(t, "80.00", balance)
+	require.Equal(t, "40.00", available)
+
+	_, err = svc.CaptureHold(ctx, merchant, hold.ID, CaptureHoldRequest{Amount: "15.00", Final: true, IdempotencyKey: uuid.New().String()})
+	require.NoError(t, err)
+
+	// 35 captured in total; the other 25 goes back to the payer.
+	balance, available = walletBalances(t, f, payerWallet)
+	require.Equal(t, "65.00", balance)
+	require.Equal(t, "65.00", available)
+
+	balance, available = walletBalances(t, f, merchantWallet)
+	require.Equal(t, "35.00", balance)
+	require.Equal(t, "35.00", available)
+
+	_, err = svc.CaptureHold(ctx, merchant, hold.ID, CaptureHoldRequest{Amount: "1.00", IdempotencyKey: uuid.New().String()})
+	require.ErrorIs(t, err, ErrHoldNotActive)
+}
+
+func TestCaptureHold_ScopesKeyToHold(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := newTestService(f)
+	payer, merchant, payerWallet, merchantWallet := setupHoldWallets(t, f, "100")
+	ctx := context.Background()
+
+	placeHold := func() db.WalletHold {
+		hold, err := svc.PlaceHold(ctx, payer, PlaceHoldRequest{
+			SenderWalletID:   payerWallet.String(),
+			ReceiverWalletID: merchantWallet.String(),
+			Amount:           "30.00",
+			Currency:         "NGN",
+			IdempotencyKey:   uuid.New().String(),
+			PIN:              testPIN,
+		})
+		require.NoError(t, err)
+		return hold
+	}
+	first, second := placeHold(), placeHold()
+
+	// A transfer already holds the key the merchant sends with both captures.
+	key := uuid.New().String()
+	_, err := svc.CreateTransaction(ctx, payer, uuid.Nil, CreateTransactionRequest{
+		SenderWalletID:   payerWallet.String(),
+		ReceiverWalletID: merchantWallet.String(),
+		TransactionType:  "transfer",
+		Amount:           "5.00",
+		Currency:         "NGN",
+		IdempotencyKey:   key,
+		PIN:              testPIN,
+	})
+	require.NoError(t, err)
+
+	capture, err := svc.CaptureHold(ctx, merchant, first.ID, CaptureHoldRequest{Amount: "10.00", IdempotencyKey: key})
+	require.NoError(t, err)
+
+	other, err := svc.CaptureHold(ctx, merchant, second.ID, CaptureHoldRequest{Amount: "10.00", IdempotencyKey: key})
+	require.NoError(t, err)
+	require.NotEqual(t, capture.ID, other.ID)
+
+	again, err := svc.CaptureHold(ctx, merchant, first.ID, CaptureHoldRequest{Amount: "10.00", IdempotencyKey: key})
+	require.NoError(t, err)
+	require.Equal(t, capture.ID, again.ID)
+
+	balance, available := walletBalances(t, f, merchantWallet)
+	require.Equal(t, "25.00", balance)
+	require.Equal(t, "25.00", available)
+}
+
+func TestVoidAndExpireHold_ReleaseFunds(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := newTestService(f)
+	payer, merchant, payerWallet, merchantWallet := setupHoldWallets(t, f, "100")
+	ctx := context.Background()
+
+	place := func() db.WalletHold {
+		hold, err := svc.PlaceHold(ctx, payer, PlaceHoldRequest{
+			SenderWalletID:   payerWallet.String(),
+			ReceiverWalletID: merchantWallet.String(),
+			Amount:           "30.00",
+			Currency:         "NGN",
+			IdempotencyKey:   uuid.New().String(),
+			PIN:              testPIN,
+		})
+		require.NoError(t, err)
+		return hold
+	}
+
+	voided, err := svc.VoidHold(ctx, merchant, place().ID)
+	require.NoError(t, err)
+	require.Equal(t, db.HoldStatusEnumVoided, voided.Status)
+
+	stale := place()
+	f.ExpireFakeHold(stale.ID)
+
+	_, err = svc.CaptureHold(ctx, merchant, stale.ID, CaptureHoldRequest{Amount: "5.00", IdempotencyKey: uuid.New().String()})
+	require.ErrorIs(t, err, ErrHoldExpired)
+
+	released, err := svc.ReleaseExpiredHolds(ctx, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, released)
+
+	balance, available := walletBalances(t, f, payerWallet)
+	require.Equal(t, "100.00", balance)
+	require.Equal(t, "100.00", available)
+}
+
+func numericString(n pgtype.Numeric) string {
+	return utils.NumericToDecimal(n).StringFixed(2)
+}
@@ -0,0 +1,175 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/tasks"
+	"github.com/luponetn/paycore/pkg/utils"
+)
+
+const (
+	pinMaxAttempts = 5
+	pinLockout     = 15 * time.Minute
+)
+
+// SetPIN sets or replaces the transaction PIN. The account password is required, so a stolen
+// access token alone can neither set a first PIN nor change an existing one.
+func (s *Svc) SetPIN(ctx context.Context, userID uuid.UUID, req SetPINRequest, meta RequestMeta) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if weakPIN(req.PIN) {
+		return ErrWeakPIN
+	}
+
+	pinHash, err := utils.HashPassword(req.PIN)
+	if err != nil {
+		return err
+	}
+
+	user, err := utils.Retry(3, 100, func() (db.User, error) {
+		user, err := s.store.Queries().GetUserByID(ctx, userID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return db.User{}, ErrUnauthorizedWallet
+			}
+			return db.User{}, &utils.RetryableError{Err: err}
+		}
+
+		if err := utils.CheckPassword(req.Password, user.Passwordhash); err != nil {
+			return db.User{}, ErrInvalidPassword
+		}
+
+		if _, err := s.store.Queries().UpsertTransactionPin(ctx, db.UpsertTransactionPinParams{UserID: userID, PinHash: pinHash}); err != nil {
+			return db.User{}, &utils.RetryableError{Err: err}
+		}
+		return user, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.enqueueSecurityAlert(ctx, user, "Your transaction PIN was set or changed.", meta)
+	return nil
+}
+
+// PINStatus reports whether a PIN is set and whether it is locked after failed attempts.
+func (s *Svc) PINStatus(ctx context.Context, userID uuid.UUID) (PINStatusResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	pin, err := s.store.Queries().GetTransactionPin(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return PINStatusResponse{}, nil
+		}
+		return PINStatusResponse{}, err
+	}
+
+	resp := PINStatusResponse{IsSet: true}
+	if pin.LockedUntil.Valid && pin.LockedUntil.Time.After(time.Now()) {
+		resp.LockedUntil = pin.LockedUntil
+	}
+	return resp, nil
+}
+
+// VerifyPIN checks the user's transaction PIN. After pinMaxAttempts wrong PINs in a row the
+// PIN is locked for pinLockout; the failed attempt is committed even though an error is returned.
+func (s *Svc) VerifyPIN(ctx context.Context, userID uuid.UUID, pin string) error {
+	_, err := utils.Retry(3, 100, func() (struct{}, error) {
+		tx, err := s.store.Begin(ctx)
+		if err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+
+		defer func() {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback verify pin tx", "error", rbErr)
+			}
+		}()
+
+		qtx := s.store.WithTx(tx)
+
+		stored, err := qtx.GetTransactionPinForUpdate(ctx, userID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return struct{}{}, ErrPINNotSet
+			}
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+
+		if stored.LockedUntil.Valid && stored.LockedUntil.Time.After(time.Now()) {
+			return struct{}{}, ErrPINLocked
+		}
+
+		params := db.UpdateTransactionPinAttemptsParams{UserID: userID}
+		verifyErr := utils.CheckPassword(pin, stored.PinHash)
+		if verifyErr != nil {
+			params.FailedAttempts = stored.FailedAttempts + 1
+			if params.FailedAttempts >= pinMaxAttempts {
+				params.FailedAttempts = 0
+				params.LockedUntil = pgtype.Timestamptz{Time: time.Now().Add(pinLockout), Valid: true}
+			}
+		} else if stored.FailedAttempts == 0 && !stored.LockedUntil.Valid {
+			return struct{}{}, nil
+		}
+
+		if err := qtx.UpdateTransactionPinAttempts(ctx, params); err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+
+		switch {
+		case verifyErr == nil:
+			return struct{}{}, nil
+		case params.LockedUntil.Valid:
+			return struct{}{}, ErrPINLocked
+		default:
+			return struct{}{}, ErrInvalidPIN
+		}
+	})
+	return err
+}
+
+// weakPIN rejects PINs that are trivially guessed: one repeated digit or a straight run.
+func weakPIN(pin string) bool {
+	repeated, ascending, descending := true, true, true
+	for i := 1; i < len(pin); i++ {
+		repeated = repeated && pin[i] == pin[0]
+		ascending = ascending && pin[i] == pin[i-1]+1
+		descending = descending && pin[i] == pin[i-1]-1
+	}
+	return repeated || ascending || descending
+}
+
+// enqueueSecurityAlert emails the user about a change to how their money is protected.
+// Failures are logged; the change itself has already been committed.
+func (s *Svc) enqueueSecurityAlert(ctx context.Context, user db.User, event string, meta RequestMeta) {
+	task, err := tasks.NewSendSecurityAlertEmailTask(tasks.SendSecurityAlertEmailPayload{
+		UserID:     user.ID.String(),
+		Email:      user.Email,
+		FullName:   user.FullName,
+		Event:      event,
+		IPAddress:  meta.IPAddress,
+		UserAgent:  meta.UserAgent,
+		OccurredAt: time.Now(),
+	})
+	if err != nil {
+		return
+	}
+
+	if _, err := s.taskClient.EnqueueContext(ctx, task, asynq.Queue(tasks.QueueCritical), asynq.MaxRetry(5)); err != nil {
+		slog.Error("failed to enqueue security alert", "user_id", user.ID, "error", err)
+	}
+}
@@ -0,0 +1,166 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/ledger"
+	"github.com/luponetn/paycore/pkg/money"
+	"github.com/luponetn/paycore/pkg/utils"
+	"github.com/shopspring/decimal"
+)
+
+// ReverseTransaction undoes whatever is still outstanding on a completed transfer with a
+// single linked reversal transaction. Any fee charged on the transfer is kept.
+func (s *Svc) ReverseTransaction(ctx context.Context, userID uuid.UUID, transactionID uuid.UUID, req ReverseTransactionRequest) (db.Transaction, error) {
+	return s.compensate(ctx, userID, transactionID, nil, db.TransactionTypeEnumReversal, req.IdempotencyKey, req.Reason)
+}
+
+// RefundTransaction returns part (or all) of a completed transfer to the original sender.
+// Several partial refunds may be made as long as their total stays within the original amount.
+func (s *Svc) RefundTransaction(ctx context.Context, userID uuid.UUID, transactionID uuid.UUID, req RefundTransactionRequest) (db.Transaction, error) {
+	requested, err := decimal.NewFromString(req.Amount)
+	if err != nil || !requested.IsPositive() {
+		return db.Transaction{}, ErrInvalidAmount
+	}
+
+	return s.compensate(ctx, userID, transactionID, &requested, db.TransactionTypeEnumRefund, req.IdempotencyKey, req.Reason)
+}
+
+// compensate creates a transaction linked to parentID that moves amount back from the original
+// receiver to the original sender. A nil amount means "everything not yet compensated".
+func (s *Svc) compensate(ctx context.Context, userID uuid.UUID, parentID uuid.UUID, amount *decimal.Decimal, txType db.TransactionTypeEnum, idempotencyKey string, reason string) (db.Transaction, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	return utils.Retry(3, 100, func() (db.Transaction, error) {
+		tx, err := s.store.Begin(ctx)
+		if err != nil {
+			return db.Transaction{}, &utils.RetryableError{Err: err}
+		}
+
+		defer func() {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback compensating tx", "error", rbErr)
+			}
+		}()
+
+		qtx := s.store.WithTx(tx)
+
+		// Locking the parent serialises refunds of the same transfer, so the outstanding
+		// amount computed below cannot be spent twice.
+		parent, err := qtx.GetTransactionByIdForUpdate(ctx, parentID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return db.Transaction{}, ErrTransactionNotFound
+			}
+			return db.Transaction{}, &utils.RetryableError{Err: err}
+		}
+
+		// Only user-to-user transfers can be sent back; moves between a user's own wallets,
+		// hold captures, deposits, interest and conversions have their own ways of unwinding.
+		if parent.TransactionType != db.TransactionTypeEnumTransfer || parent.Status != db.TransactionStatusEnumCompleted ||
+			parent.ParentTransactionID.Valid || !parent.SenderWalletID.Valid || !parent.ReceiverWalletID.Valid {
+			return db.Transaction{}, ErrNotRefundable
+		}
+
+		originalSenderID := uuid.UUID(parent.SenderWalletID.Bytes)
+		originalReceiverID := uuid.UUID(parent.ReceiverWalletID.Bytes)
+
+		wallets, err := qtx.GetWalletsAndLockByWalletIds(ctx, db.GetWalletsAndLockByWalletIdsParams{
+			ID:  originalReceiverID,
+			ID2: originalSenderID,
+		})
+		if err != nil {
+			return db.Transaction{}, &utils.RetryableError{Err: err}
+		}
+
+		if len(wallets) != 2 {
+			return db.Transaction{}, ErrWalletNotFound
+		}
+
+		// Money flows back, so the original receiver is the payer this time.
+		payer, payee := pickWallets(wallets, originalReceiverID)
+
+		if !payer.UserID.Valid || uuid.UUID(payer.UserID.Bytes) != userID {
+			return db.Transaction{}, ErrUnauthorizedWallet
+		}
+
+		// a replay must be this kind of compensation of this parent, not whatever holds the key
+		if existingTx, err := qtx.GetTransactionByIdempotencyKey(ctx, idempotencyKey); err == nil {
+			if existingTx.ParentTransactionID != utils.ToPgUUID(parent.ID) || existingTx.TransactionType != txType {
+				return db.Transaction{}, ErrIdempotencyKeyReused
+			}
+			return existingTx, nil
+		} else if !errors.Is(err, pgx.ErrNoRows) {
+			return db.Transaction{}, &utils.RetryableError{Err: err}
+		}
+
+		if err := checkDebitAllowed(payer, time.Now()); err != nil {
+			return db.Transaction{}, err
+		}
+
+		compensated, err := qtx.GetCompensatedAmountByParentId(ctx, utils.ToPgUUID(parent.ID))
+		if err != nil {
+			return db.Transaction{}, &utils.RetryableError{Err: err}
+		}
+
+		original, err := money.FromNumeric(parent.Amount, parent.Currency)
+		if err != nil {
+			return db.Transaction{}, err
+		}
+		returned, err := money.FromNumeric(compensated, parent.Currency)
+		if err != nil {
+			return db.Transaction{}, err
+		}
+		outstanding, err := original.Sub(returned)
+		if err != nil {
+			return db.Transaction{}, err
+		}
+		if !outstanding.IsPositive() {
+			return db.Transaction{}, ErrAlreadyRefunded
+		}
+
+		refundAmount := outstanding
+		if amount != nil {
+			// a partial refund is in the original transfer's currency
+			requested, err := money.New(*amount, parent.Currency)
+			if err != nil {
+				return db.Transaction{}, err
+			}
+			if requested.Amount().GreaterThan(outstanding.Amount()) {
+				return db.Transaction{}, ErrRefundExceedsOriginal
+			}
+			refundAmount = requested
+		}
+
+		if utils.NumericToDecimal(payer.AvailableBalance).LessThan(refundAmount.Amount()) {
+			return db.Transaction{}, ErrRefundOverdraw
+		}
+
+		createdTransaction, err := ledger.Post(ctx, qtx, ledger.Journal{
+			TransactionType:     txType,
+			Description:         reason,
+			IdempotencyKey:      idempotencyKey,
+			ParentTransactionID: parent.ID,
+			Postings: []ledger.Posting{
+				ledger.Debit(payer.ID, refundAmount.Amount(), refundAmount.Code()),
+				ledger.Credit(payee.ID, refundAmount.Amount(), refundAmount.Code()),
+			},
+		})
+		if err != nil {
+			return db.Transaction{}, err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return db.Transaction{}, &utils.RetryableError{Err: err}
+		}
+
+		return createdTransaction, nil
+	})
+}
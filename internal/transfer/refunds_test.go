@@ -0,0 +1,187 @@
+package transfer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefundTransaction_PartialRefundsThenReversal(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := newTestService(f)
+	payer, merchant, payerWallet, merchantWallet := setupHoldWallets(t, f, "100")
+	ctx := context.Background()
+
+	original, err := svc.CreateTransaction(ctx, payer, uuid.Nil, CreateTransactionRequest{
+		SenderWalletID:   payerWallet.String(),
+		ReceiverWalletID: merchantWallet.String(),
+		TransactionType:  "transfer",
+		Amount:           "50.00",
+		Currency:         "NGN",
+		IdempotencyKey:   uuid.New().String(),
+		PIN:              testPIN,
+	})
+	require.NoError(t, err)
+
+	_, err = svc.RefundTransaction(ctx, payer, original.ID, RefundTransactionRequest{Amount: "10.00", IdempotencyKey: uuid.New().String()})
+	require.ErrorIs(t, err, ErrUnauthorizedWallet)
+
+	refund, err := svc.RefundTransaction(ctx, merchant, original.ID, RefundTransactionRequest{Amount: "20.00", IdempotencyKey: uuid.New().String()})
+	require.NoError(t, err)
+	require.Equal(t, db.TransactionTypeEnumRefund, refund.TransactionType)
+	require.Equal(t, original.ID, uuid.UUID(refund.ParentTransactionID.Bytes))
+
+	_, err = svc.RefundTransaction(ctx, merchant, original.ID, RefundTransactionRequest{Amount: "40.00", IdempotencyKey: uuid.New().String()})
+	require.ErrorIs(t, err, ErrRefundExceedsOriginal)
+
+	reversal, err := svc.ReverseTransaction(ctx, merchant, original.ID, ReverseTransactionRequest{IdempotencyKey: uuid.New().String()})
+	require.NoError(t, err)
+	require.Equal(t, "30.00", numericString(reversal.Amount))
+
+	_, err = svc.RefundTransaction(ctx, merchant, original.ID, RefundTransactionRequest{Amount: "1.00", IdempotencyKey: uuid.New().String()})
+	require.ErrorIs(t, err, ErrAlreadyRefunded)
+
+	balance, available := walletBalances(t, f, payerWallet)
+	require.Equal(t, "100.00", balance)
+	require.Equal(t, "100.00", available)
+}
+
+func TestRefundTransaction_RejectsOverdraw(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := newTestService(f)
+	payer, merchant, payerWallet, merchantWallet := setupHoldWallets(t, f, "100")
+	ctx := context.Background()
+
+	original, err := svc.CreateTransaction(ctx, payer, uuid.Nil, CreateTransactionRequest{
+		SenderWalletID:   payerWallet.String(),
+		ReceiverWalletID: merchantWallet.String(),
+		TransactionType:  "transfer",
+		Amount:           "50.00",
+		Currency:         "NGN",
+		IdempotencyKey:   uuid.New().String(),
+		PIN:              testPIN,
+	})
+	require.NoError(t, err)
+
+	// The merchant spends most of the money before the refund comes in.
+	_, err = svc.CreateTransaction(ctx, merchant, uuid.Nil, CreateTransactionRequest{
+		SenderWalletID:   merchantWallet.String(),
+		ReceiverWalletID: payerWallet.String(),
+		TransactionType:  "transfer",
+		Amount:           "45.00",
+		Currency:         "NGN",
+		IdempotencyKey:   uuid.New().String(),
+		PIN:              testPIN,
+	})
+	require.NoError(t, err)
+
+	_, err = svc.RefundTransaction(ctx, merchant, original.ID, RefundTransactionRequest{Amount: "10.00", IdempotencyKey: uuid.New().String()})
+	require.ErrorIs(t, err, ErrRefundOverdraw)
+}
+
+func TestRefundTransaction_OnlyTransfersFromDebitableWallets(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := newTestService(f)
+	payer, merchant, payerWallet, merchantWallet := setupHoldWallets(t, f, "100")
+	ctx := context.Background()
+
+	// A move between the payer's own wallets is not a transfer that can be sent back.
+	misc := db.GetWalletsAndLockByWalletIdsRow{
+		ID:         uuid.New(),
+		UserID:     pgtype.UUID{Bytes: payer, Valid: true},
+		Currency:   "NGN",
+		WalletType: db.WalletTypeEnumMisc,
+	}
+	require.NoError(t, misc.Balance.Scan("0"))
+	f.AddFakeWallet(misc)
+
+	move, err := svc.CreateAuthorizedTransaction(ctx, payer, CreateTransactionRequest{
+		SenderWalletID:   payerWallet.String(),
+		ReceiverWalletID: misc.ID.String(),
+		TransactionType:  string(db.TransactionTypeEnumInternalMove),
+		Amount:           "10.00",
+		Currency:         "NGN",
+		IdempotencyKey:   uuid.New().String(),
+	})
+	require.NoError(t, err)
+
+	_, err = svc.ReverseTransaction(ctx, payer, move.ID, ReverseTransactionRequest{IdempotencyKey: uuid.New().String()})
+	require.ErrorIs(t, err, ErrNotRefundable)
+
+	// Money paid into a fixed wallet stays there until it matures, refund or not.
+	fixed, err := f.GetWalletById(ctx, merchantWallet)
+	require.NoError(t, err)
+	f.AddFakeWallet(db.GetWalletsAndLockByWalletIdsRow{
+		ID:               fixed.ID,
+		UserID:           fixed.UserID,
+		Balance:          fixed.Balance,
+		AvailableBalance: fixed.AvailableBalance,
+		Currency:         fixed.Currency,
+		WalletType:       db.WalletTypeEnumFixed,
+		MaturesAt:        pgtype.Timestamptz{Time: time.Now().Add(24 * time.Hour), Valid: true},
+	})
+
+	original, err := svc.CreateTransaction(ctx, payer, uuid.Nil, CreateTransactionRequest{
+		SenderWalletID:   payerWallet.String(),
+		ReceiverWalletID: merchantWallet.String(),
+		TransactionType:  "transfer",
+		Amount:           "50.00",
+		Currency:         "NGN",
+		IdempotencyKey:   uuid.New().String(),
+		PIN:              testPIN,
+	})
+	require.NoError(t, err)
+
+	_, err = svc.RefundTransaction(ctx, merchant, original.ID, RefundTransactionRequest{Amount: "10.00", IdempotencyKey: uuid.New().String()})
+	require.ErrorIs(t, err, ErrWalletNotMatured)
+}
+
+func TestRefundTransaction_ReplaysOnlyTheSameCompensation(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := newTestService(f)
+	payer, merchant, payerWallet, merchantWallet := setupHoldWallets(t, f, "100")
+	ctx := context.Background()
+
+	pay := func() db.Transaction {
+		original, err := svc.CreateTransaction(ctx, payer, uuid.Nil, CreateTransactionRequest{
+			SenderWalletID:   payerWallet.String(),
+			ReceiverWalletID: merchantWallet.String(),
+			TransactionType:  "transfer",
+			Amount:           "30.00",
+			Currency:         "NGN",
+			IdempotencyKey:   uuid.New().String(),
+			PIN:              testPIN,
+		})
+		require.NoError(t, err)
+		return original
+	}
+	first, second := pay(), pay()
+
+	key := uuid.New().String()
+	refund, err := svc.RefundTransaction(ctx, merchant, first.ID, RefundTransactionRequest{Amount: "10.00", IdempotencyKey: key})
+	require.NoError(t, err)
+
+	// The key does not hand the refund to someone who may not refund the transfer.
+	_, err = svc.RefundTransaction(ctx, payer, first.ID, RefundTransactionRequest{Amount: "10.00", IdempotencyKey: key})
+	require.ErrorIs(t, err, ErrUnauthorizedWallet)
+
+	// Nor does it stand in for a reversal or for a refund of another transfer.
+	_, err = svc.ReverseTransaction(ctx, merchant, first.ID, ReverseTransactionRequest{IdempotencyKey: key})
+	require.ErrorIs(t, err, ErrIdempotencyKeyReused)
+
+	_, err = svc.RefundTransaction(ctx, merchant, second.ID, RefundTransactionRequest{Amount: "10.00", IdempotencyKey: key})
+	require.ErrorIs(t, err, ErrIdempotencyKeyReused)
+
+	again, err := svc.RefundTransaction(ctx, merchant, first.ID, RefundTransactionRequest{Amount: "10.00", IdempotencyKey: key})
+	require.NoError(t, err)
+	require.Equal(t, refund.ID, again.ID)
+
+	balance, _ := walletBalances(t, f, payerWallet)
+	require.Equal(t, "50.00", balance)
+}
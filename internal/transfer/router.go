@@ -3,17 +3,29 @@ package transfer
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/luponetn/paycore/internal/middleware"
+	"github.com/luponetn/paycore/pkg/utils"
 )
 
-func RegisterRoutes(r *gin.Engine, h *Handler, secret string) {
+func RegisterRoutes(r *gin.Engine, h *Handler, keys *utils.KeySet, sessions middleware.SessionChecker) {
 	transferGroup := r.Group("/transfer")
 	
 	//use middlewares
-	transferGroup.Use(middleware.AuthMiddleware(secret))
+	transferGroup.Use(middleware.AuthMiddleware(keys, sessions))
 
 	//implement routes
 	{
 		transferGroup.POST("/", h.HandleCreateTransaction)
+		transferGroup.POST("/quote", h.HandleQuoteTransaction)
 		transferGroup.GET("/:id", h.HandleGetTransactionByID)
+		transferGroup.POST("/:id/reverse", h.HandleReverseTransaction)
+		transferGroup.POST("/:id/refund", h.HandleRefundTransaction)
+
+		transferGroup.POST("/holds", h.HandlePlaceHold)
+		transferGroup.POST("/holds/:id/capture", h.HandleCaptureHold)
+		transferGroup.POST("/holds/:id/extend", h.HandleExtendHold)
+		transferGroup.POST("/holds/:id/void", h.HandleVoidHold)
+
+		transferGroup.GET("/pin", h.HandlePINStatus)
+		transferGroup.PUT("/pin", h.HandleSetPIN)
 	}
 }
@@ -7,74 +7,176 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgconn"
-	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/luponetn/paycore/internal/accounts"
+	"github.com/luponetn/paycore/internal/config"
 	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/fees"
+	"github.com/luponetn/paycore/internal/ledger"
 	"github.com/luponetn/paycore/internal/store"
+	"github.com/luponetn/paycore/pkg/money"
 	"github.com/luponetn/paycore/pkg/utils"
-	"github.com/shopspring/decimal"
 )
 
 type Service interface {
-	CreateTransaction(ctx context.Context, userID uuid.UUID, req CreateTransactionRequest) (db.Transaction, error)
+	CreateTransaction(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID, req CreateTransactionRequest) (db.Transaction, error)
+	CreateAuthorizedTransaction(ctx context.Context, userID uuid.UUID, req CreateTransactionRequest) (db.Transaction, error)
 	GetTransactionByID(ctx context.Context, transactionID uuid.UUID) (db.Transaction, error)
+	PlaceHold(ctx context.Context, userID uuid.UUID, req PlaceHoldRequest) (db.WalletHold, error)
+	CaptureHold(ctx context.Context, userID uuid.UUID, holdID uuid.UUID, req CaptureHoldRequest) (db.Transaction, error)
+	ExtendHold(ctx context.Context, userID uuid.UUID, holdID uuid.UUID, req ExtendHoldRequest) (db.WalletHold, error)
+	VoidHold(ctx context.Context, userID uuid.UUID, holdID uuid.UUID) (db.WalletHold, error)
+	ReleaseExpiredHolds(ctx context.Context, limit int32) (int, error)
+	ReverseTransaction(ctx context.Context, userID uuid.UUID, transactionID uuid.UUID, req ReverseTransactionRequest) (db.Transaction, error)
+	RefundTransaction(ctx context.Context, userID uuid.UUID, transactionID uuid.UUID, req RefundTransactionRequest) (db.Transaction, error)
+	SetPIN(ctx context.Context, userID uuid.UUID, req SetPINRequest, meta RequestMeta) error
+	PINStatus(ctx context.Context, userID uuid.UUID) (PINStatusResponse, error)
+	VerifyPIN(ctx context.Context, userID uuid.UUID, pin string) error
+	QuoteTransaction(ctx context.Context, userID uuid.UUID, req QuoteTransactionRequest) (fees.Breakdown, error)
+	FeeBreakdown(ctx context.Context, transaction db.Transaction) (fees.Breakdown, error)
+}
+
+// TaskEnqueuer is the part of *asynq.Client the service needs.
+type TaskEnqueuer interface {
+	EnqueueContext(ctx context.Context, task *asynq.Task, opts ...asynq.Option) (*asynq.TaskInfo, error)
 }
 
 type Svc struct {
-	store store.Store
+	store      store.Store
+	taskClient TaskEnqueuer
+	cfg        *config.Config
 }
 
-func NewService(store store.Store) Service {
-	return &Svc{store: store}
+// NewService wires the transfer service. taskClient delivers step-up codes and security alerts;
+// cfg carries the step-up rules.
+func NewService(store store.Store, taskClient TaskEnqueuer, cfg *config.Config) Service {
+	return &Svc{store: store, taskClient: taskClient, cfg: cfg}
 }
 
-// CreateTransaction - creates an atomic wallet-to-wallet transfer
-func (s *Svc) CreateTransaction(ctx context.Context, userID uuid.UUID, req CreateTransactionRequest) (db.Transaction, error) {
+// CreateTransaction - creates an atomic wallet-to-wallet transfer on behalf of the user. The
+// transaction PIN is always required; transfers matching a step-up rule also need an emailed OTP.
+func (s *Svc) CreateTransaction(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID, req CreateTransactionRequest) (db.Transaction, error) {
 	// Single timeout for entire operation
 	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
 	defer cancel()
 
-	// Parse amounts and IDs outside the retry loop if possible,
-	// but here we deal with strings so it's safer inside or just before.
-	amountDecimal, err := decimal.NewFromString(req.Amount)
+	senderID, receiverID, amount, err := s.resolveTransfer(ctx, userID, req)
+	if err != nil {
+		return db.Transaction{}, err
+	}
+
+	if err := s.VerifyPIN(ctx, userID, req.PIN); err != nil {
+		return db.Transaction{}, err
+	}
+
+	// A retry of a transfer that already went through needs no second step-up code, the first
+	// attempt burned it; executeTransfer hands the existing transaction back under the lock.
+	replayed, err := s.alreadyPosted(ctx, senderID, req.IdempotencyKey)
+	if err != nil {
+		return db.Transaction{}, err
+	}
+	if replayed {
+		return s.executeTransfer(ctx, userID, senderID, receiverID, amount, req)
+	}
+
+	if err := s.authorizeTransfer(ctx, transferAuthorization{
+		userID:     userID,
+		sessionID:  sessionID,
+		senderID:   senderID,
+		receiverID: receiverID,
+		amount:     amount.Amount(),
+		currency:   amount.Code(),
+		idemKey:    req.IdempotencyKey,
+		otp:        req.OTP,
+	}); err != nil {
+		return db.Transaction{}, err
+	}
+
+	return s.executeTransfer(ctx, userID, senderID, receiverID, amount, req)
+}
+
+// CreateAuthorizedTransaction runs a transfer that needs no PIN at this point: a standing order
+// the user approved with the PIN when creating it, or an internal_move between the user's own
+// wallets. It skips the PIN and step-up checks, so handlers must not pass user-chosen
+// transaction types through it.
+func (s *Svc) CreateAuthorizedTransaction(ctx context.Context, userID uuid.UUID, req CreateTransactionRequest) (db.Transaction, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	senderID, receiverID, amount, err := s.resolveTransfer(ctx, userID, req)
 	if err != nil {
-		return db.Transaction{}, ErrInvalidAmount
+		return db.Transaction{}, err
 	}
 
-	if amountDecimal.LessThanOrEqual(decimal.Zero) {
-		return db.Transaction{}, ErrInvalidAmount
+	return s.executeTransfer(ctx, userID, senderID, receiverID, amount, req)
+}
+
+// resolveTransfer validates the request and resolves both wallet ids.
+func (s *Svc) resolveTransfer(ctx context.Context, userID uuid.UUID, req CreateTransactionRequest) (uuid.UUID, uuid.UUID, money.Money, error) {
+	// Parse amounts and IDs outside the retry loop if possible,
+	// but here we deal with strings so it's safer inside or just before.
+	amount, err := ParseAmount(req.Amount, req.Currency)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, money.Money{}, err
 	}
 
 	senderID, err := uuid.Parse(req.SenderWalletID)
 	if err != nil {
-		return db.Transaction{}, errors.New("invalid sender wallet id")
+		return uuid.Nil, uuid.Nil, money.Money{}, errors.New("invalid sender wallet id")
 	}
 
 	var receiverID uuid.UUID
 	if req.ReceiverAccountNo != "" {
 		// Resolve wallet ID from account number
-		w, err := s.store.Queries().GetWalletByAccountNo(ctx, req.ReceiverAccountNo)
+		w, err := s.store.Queries().GetWalletByAccountNoAndCurrency(ctx, db.GetWalletByAccountNoAndCurrencyParams{
+			AccountNo: req.ReceiverAccountNo,
+			Currency:  amount.Code(),
+		})
 		if err != nil {
 			if errors.Is(err, pgx.ErrNoRows) {
-				return db.Transaction{}, errors.New("receiver account not found")
+				return uuid.Nil, uuid.Nil, money.Money{}, errors.New("receiver account not found")
 			}
-			return db.Transaction{}, err
+			return uuid.Nil, uuid.Nil, money.Money{}, err
 		}
 		receiverID = w.WalletID
 	} else if req.ReceiverWalletID != "" {
 		receiverID, err = uuid.Parse(req.ReceiverWalletID)
 		if err != nil {
-			return db.Transaction{}, errors.New("invalid receiver wallet id")
+			return uuid.Nil, uuid.Nil, money.Money{}, errors.New("invalid receiver wallet id")
 		}
 	} else {
-		return db.Transaction{}, errors.New("either receiver wallet id or account number is required")
+		return uuid.Nil, uuid.Nil, money.Money{}, errors.New("either receiver wallet id or account number is required")
+	}
+
+	if err := s.requireVerifiedEmail(ctx, userID); err != nil {
+		return uuid.Nil, uuid.Nil, money.Money{}, err
 	}
 
 	if senderID == receiverID {
-		return db.Transaction{}, ErrSameWallet
+		return uuid.Nil, uuid.Nil, money.Money{}, ErrSameWallet
+	}
+
+	return senderID, receiverID, amount, nil
+}
+
+// alreadyPosted reports whether the sender already has a transaction under idempotencyKey. It
+// only decides whether step-up can be skipped; executeTransfer repeats the lookup under the lock.
+func (s *Svc) alreadyPosted(ctx context.Context, senderID uuid.UUID, idempotencyKey string) (bool, error) {
+	existing, err := s.store.Queries().GetTransactionByIdempotencyKey(ctx, idempotencyKey)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
 	}
+	return existing.SenderWalletID.Valid && uuid.UUID(existing.SenderWalletID.Bytes) == senderID, nil
+}
 
+// executeTransfer moves the money: lock both wallets, check ownership, replay an idempotency key
+// that already went through, then price the fee, check funds and write the transaction, ledger
+// legs and balances in one database transaction.
+func (s *Svc) executeTransfer(ctx context.Context, userID uuid.UUID, senderID uuid.UUID, receiverID uuid.UUID, amount money.Money, req CreateTransactionRequest) (db.Transaction, error) {
 	return utils.Retry(3, 100, func() (db.Transaction, error) {
 		tx, err := s.store.Begin(ctx)
 		if err != nil {
@@ -89,7 +191,27 @@ func (s *Svc) CreateTransaction(ctx context.Context, userID uuid.UUID, req Creat
 
 		qtx := s.store.WithTx(tx)
 
-		// 1. Fetch wallets and lock in deterministic order
+		// The fee is charged on top of the amount, so the sender is debited the quote's total.
+		// It does not depend on the wallets, so it is quoted before anything is locked.
+		quote, err := s.quoteFee(ctx, qtx, userID, db.TransactionTypeEnum(req.TransactionType), amount.Code(), amount.Amount())
+		if err != nil {
+			return db.Transaction{}, err
+		}
+
+		// 1. Fetch wallets and lock in deterministic order. The fee leg's revenue wallet is
+		// locked in the same id order now rather than by ledger.Post after the other two.
+		lockIDs := []uuid.UUID{senderID, receiverID}
+		if quote.Fee.IsPositive() {
+			feesID, err := accounts.SystemWallet(ctx, qtx, accounts.FeesRevenue, amount.Code())
+			if err != nil {
+				return db.Transaction{}, err
+			}
+			lockIDs = append(lockIDs, feesID)
+		}
+		if err := ledger.LockWallets(ctx, qtx, lockIDs...); err != nil {
+			return db.Transaction{}, err
+		}
+
 		fetchWalletsParams := db.GetWalletsAndLockByWalletIdsParams{
 			ID:  senderID,
 			ID2: receiverID,
@@ -118,91 +240,66 @@ func (s *Svc) CreateTransaction(ctx context.Context, userID uuid.UUID, req Creat
 			return db.Transaction{}, ErrUnauthorizedWallet
 		}
 
-		// 3. Business Validation
-		senderBalance := decimal.NewFromBigInt(senderWallet.Balance.Int, senderWallet.Balance.Exp)
-		if senderBalance.LessThan(amountDecimal) {
-			return db.Transaction{}, ErrInsufficientFunds
+		// A retry returns what the first attempt posted, whatever the balance is now. Holding the
+		// sender's lock means a concurrent attempt with the same key has committed by this point.
+		if existing, err := qtx.GetTransactionByIdempotencyKey(ctx, req.IdempotencyKey); err == nil {
+			if !existing.SenderWalletID.Valid || uuid.UUID(existing.SenderWalletID.Bytes) != senderID {
+				return db.Transaction{}, ErrIdempotencyKeyReused
+			}
+			return existing, nil
+		} else if !errors.Is(err, pgx.ErrNoRows) {
+			return db.Transaction{}, &utils.RetryableError{Err: err}
 		}
 
-		if senderWallet.Currency != req.Currency || receiverWallet.Currency != req.Currency {
-			return db.Transaction{}, ErrCurrencyMismatch
+		// An internal move never leaves the user, which is why it needs no PIN.
+		if db.TransactionTypeEnum(req.TransactionType) == db.TransactionTypeEnumInternalMove &&
+			(!receiverWallet.UserID.Valid || uuid.UUID(receiverWallet.UserID.Bytes) != userID) {
+			return db.Transaction{}, ErrUnauthorizedWallet
 		}
 
-		// 4. Create Transaction record (Idempotency)
-		transactionParams := db.CreateTransactionParams{
-			SenderWalletID:   pgtype.UUID{Bytes: senderID, Valid: true},
-			ReceiverWalletID: pgtype.UUID{Bytes: receiverID, Valid: true},
-			TransactionType:  db.TransactionTypeEnum(req.TransactionType),
-			Amount:           utils.DecimalToNumeric(amountDecimal),
-			Description:      pgtype.Text{String: req.Description, Valid: req.Description != ""},
-			Status:           db.TransactionStatusEnumPending,
-			Currency:         req.Currency,
-			IdempotencyKey:   req.IdempotencyKey,
+		if err := checkDebitAllowed(senderWallet, time.Now()); err != nil {
+			return db.Transaction{}, err
 		}
 
-		createdTransaction, transactionErr := qtx.CreateTransaction(ctx, transactionParams)
-		if transactionErr != nil {
-			if pgErr, ok := transactionErr.(*pgconn.PgError); ok && pgErr.Code == "23505" {
-				existingTx, getErr := qtx.GetTransactionByIdempotencyKey(ctx, req.IdempotencyKey)
-				if getErr != nil {
-					return db.Transaction{}, &utils.RetryableError{Err: getErr}
-				}
-				return existingTx, nil
-			}
-			return db.Transaction{}, &utils.RetryableError{Err: transactionErr}
-		}
-
-		// 5. Update Balances and Ledger
-		newSenderBalance := senderBalance.Sub(amountDecimal)
-		receiverBalance := decimal.NewFromBigInt(receiverWallet.Balance.Int, receiverWallet.Balance.Exp)
-		newReceiverBalance := receiverBalance.Add(amountDecimal)
-
-		// Create Ledger Entries
-		if _, err := qtx.CreateLedger(ctx, db.CreateLedgerParams{
-			WalletID:      senderWallet.ID,
-			TransactionID: createdTransaction.ID,
-			Amount:        utils.DecimalToNumeric(amountDecimal),
-			EntryType:     db.LedgerEntryTypeDebit,
-			Currency:      senderWallet.Currency,
-			BalanceBefore: senderWallet.Balance,
-			BalanceAfter:  utils.DecimalToNumeric(newSenderBalance),
-		}); err != nil {
-			return db.Transaction{}, &utils.RetryableError{Err: err}
+		// 3. Business Validation
+		// Spendable funds are the available balance: funds reserved by active holds are excluded.
+		senderAvailable := utils.NumericToDecimal(senderWallet.AvailableBalance)
+		if senderAvailable.LessThan(quote.Total) {
+			return db.Transaction{}, ErrInsufficientFunds
 		}
 
-		if _, err := qtx.CreateLedger(ctx, db.CreateLedgerParams{
-			WalletID:      receiverWallet.ID,
-			TransactionID: createdTransaction.ID,
-			Amount:        utils.DecimalToNumeric(amountDecimal),
-			EntryType:     db.LedgerEntryTypeCredit,
-			Currency:      receiverWallet.Currency,
-			BalanceBefore: receiverWallet.Balance,
-			BalanceAfter:  utils.DecimalToNumeric(newReceiverBalance),
-		}); err != nil {
-			return db.Transaction{}, &utils.RetryableError{Err: err}
+		if senderWallet.Currency != amount.Code() || receiverWallet.Currency != amount.Code() {
+			return db.Transaction{}, ErrCurrencyMismatch
 		}
 
-		// Update Wallet Balances
-		if err := qtx.UpdateWalletBalance(ctx, db.UpdateWalletBalanceParams{
-			Balance: utils.DecimalToNumeric(newSenderBalance),
-			ID:      senderWallet.ID,
-		}); err != nil {
-			return db.Transaction{}, &utils.RetryableError{Err: err}
+		// 4. Post the transfer, and the fee leg if there is one, under one transactions row. A
+		// replayed idempotency key returns the transaction it already created.
+		postings := []ledger.Posting{
+			ledger.Debit(senderID, quote.Total, amount.Code()),
+			ledger.Credit(receiverID, amount.Amount(), amount.Code()),
+		}
+		if quote.Fee.IsPositive() {
+			postings = append(postings, ledger.CreditAccount(accounts.FeesRevenue, quote.Fee, amount.Code()))
 		}
 
-		if err := qtx.UpdateWalletBalance(ctx, db.UpdateWalletBalanceParams{
-			Balance: utils.DecimalToNumeric(newReceiverBalance),
-			ID:      receiverWallet.ID,
-		}); err != nil {
-			return db.Transaction{}, &utils.RetryableError{Err: err}
+		journal := ledger.Journal{
+			TransactionType:  db.TransactionTypeEnum(req.TransactionType),
+			Description:      req.Description,
+			IdempotencyKey:   req.IdempotencyKey,
+			SenderWalletID:   senderID,
+			ReceiverWalletID: receiverID,
+			Amount:           amount.Amount(),
+			Currency:         amount.Code(),
+			Fee:              quote.Fee,
+			Postings:         postings,
+		}
+		if quote.ScheduleID != nil {
+			journal.FeeScheduleID = *quote.ScheduleID
 		}
 
-		// 6. Complete Transaction
-		if err := qtx.UpdateTransactionStatus(ctx, db.UpdateTransactionStatusParams{
-			Status: db.TransactionStatusEnumCompleted,
-			ID:     createdTransaction.ID,
-		}); err != nil {
-			return db.Transaction{}, &utils.RetryableError{Err: err}
+		createdTransaction, err := ledger.Post(ctx, qtx, journal)
+		if err != nil {
+			return db.Transaction{}, err
 		}
 
 		if err := tx.Commit(ctx); err != nil {
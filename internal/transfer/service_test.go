@@ -7,17 +7,60 @@ import (
 	"testing"
 
 	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/luponetn/paycore/internal/accounts"
+	"github.com/luponetn/paycore/internal/config"
 	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/fees"
 	"github.com/luponetn/paycore/internal/store"
+	"github.com/luponetn/paycore/pkg/money"
+	"github.com/luponetn/paycore/pkg/utils"
 	"github.com/stretchr/testify/require"
 )
 
+const testPIN = "2580"
+
+var (
+	testPINHash     string
+	testPINHashOnce sync.Once
+)
+
+// setTestPIN gives the user the transaction PIN testPIN. The bcrypt hash is computed once per run.
+func setTestPIN(t *testing.T, f *store.FakeStore, userID uuid.UUID) {
+	t.Helper()
+	testPINHashOnce.Do(func() {
+		hash, err := utils.HashPassword(testPIN)
+		require.NoError(t, err)
+		testPINHash = hash
+	})
+	f.AddFakeTransactionPin(userID, testPINHash)
+}
+
+// fakeEnqueuer records tasks instead of sending them to Redis.
+type fakeEnqueuer struct {
+	mu    sync.Mutex
+	tasks []*asynq.Task
+}
+
+func (e *fakeEnqueuer) EnqueueContext(ctx context.Context, task *asynq.Task, opts ...asynq.Option) (*asynq.TaskInfo, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.tasks = append(e.tasks, task)
+	return &asynq.TaskInfo{}, nil
+}
+
+// newTestService builds a service with every step-up rule switched off.
+func newTestService(f *store.FakeStore) Service {
+	return NewService(f, &fakeEnqueuer{}, &config.Config{})
+}
+
 func TestCreateTransaction(t *testing.T) {
 	f := store.NewFakeStore()
-	svc := NewService(f)
+	svc := newTestService(f)
 
 	userID := uuid.New()
+	setTestPIN(t, f, userID)
 	senderWalletID := uuid.New()
 	receiverWalletID := uuid.New()
 
@@ -48,10 +91,11 @@ func TestCreateTransaction(t *testing.T) {
 		Description:      "Test transfer",
 		Currency:         "NGN",
 		IdempotencyKey:   uuid.New().String(),
+		PIN:              testPIN,
 	}
 
 	ctx := context.Background()
-	_, err := svc.CreateTransaction(ctx, userID, req)
+	_, err := svc.CreateTransaction(ctx, userID, uuid.Nil, req)
 	require.NoError(t, err)
 
 	// Verify balance update in fake store
@@ -62,16 +106,56 @@ func TestCreateTransaction(t *testing.T) {
 
 	for _, w := range wallets {
 		if w.ID == senderWalletID {
-			require.Equal(t, "80.00", w.Balance.Int.String()) // This depends on how pgtype.Numeric works in fake store
+			require.Equal(t, "80.00", utils.NumericToDecimal(w.Balance).StringFixed(2))
 		}
 	}
 }
 
+func TestCreateTransactionRequiresVerifiedEmail(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := newTestService(f)
+
+	userID := uuid.New()
+	senderWalletID := uuid.New()
+	receiverWalletID := uuid.New()
+
+	f.AddFakeUser(db.User{ID: userID})
+
+	senderWallet := db.GetWalletsAndLockByWalletIdsRow{
+		ID:       senderWalletID,
+		UserID:   pgtype.UUID{Bytes: userID, Valid: true},
+		Currency: "NGN",
+	}
+	_ = senderWallet.Balance.Scan("100")
+
+	receiverWallet := db.GetWalletsAndLockByWalletIdsRow{
+		ID:       receiverWalletID,
+		UserID:   pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		Currency: "NGN",
+	}
+	_ = receiverWallet.Balance.Scan("50")
+
+	f.AddFakeWallet(senderWallet)
+	f.AddFakeWallet(receiverWallet)
+
+	_, err := svc.CreateTransaction(context.Background(), userID, uuid.Nil, CreateTransactionRequest{
+		SenderWalletID:   senderWalletID.String(),
+		ReceiverWalletID: receiverWalletID.String(),
+		TransactionType:  "transfer",
+		Amount:           "20.00",
+		Currency:         "NGN",
+		IdempotencyKey:   uuid.New().String(),
+		PIN:              testPIN,
+	})
+	require.ErrorIs(t, err, ErrEmailNotVerified)
+}
+
 func TestCreateTransaction_Unauthorized(t *testing.T) {
 	f := store.NewFakeStore()
-	svc := NewService(f)
+	svc := newTestService(f)
 
 	userID := uuid.New()
+	setTestPIN(t, f, userID)
 	wrongUserID := uuid.New()
 	senderWalletID := uuid.New()
 	receiverWalletID := uuid.New()
@@ -93,17 +177,19 @@ func TestCreateTransaction_Unauthorized(t *testing.T) {
 		Amount:           "20.00",
 		Currency:         "NGN",
 		IdempotencyKey:   uuid.New().String(),
+		PIN:              testPIN,
 	}
 
-	_, err := svc.CreateTransaction(context.Background(), userID, req)
+	_, err := svc.CreateTransaction(context.Background(), userID, uuid.Nil, req)
 	require.ErrorIs(t, err, ErrUnauthorizedWallet)
 }
 
 func TestCreateTransaction_InsufficientFunds(t *testing.T) {
 	f := store.NewFakeStore()
-	svc := NewService(f)
+	svc := newTestService(f)
 
 	userID := uuid.New()
+	setTestPIN(t, f, userID)
 	senderWalletID := uuid.New()
 	receiverWalletID := uuid.New()
 
@@ -124,17 +210,53 @@ func TestCreateTransaction_InsufficientFunds(t *testing.T) {
 		Amount:           "100.00",
 		Currency:         "NGN",
 		IdempotencyKey:   uuid.New().String(),
+		PIN:              testPIN,
 	}
 
-	_, err := svc.CreateTransaction(context.Background(), userID, req)
+	_, err := svc.CreateTransaction(context.Background(), userID, uuid.Nil, req)
 	require.ErrorIs(t, err, ErrInsufficientFunds)
 }
 
+func TestCreateAuthorizedTransaction_ReplaysUnderLock(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := newTestService(f)
+	payer, merchant, payerWallet, merchantWallet := setupHoldWallets(t, f, "100")
+	ctx := context.Background()
+
+	req := CreateTransactionRequest{
+		SenderWalletID:   payerWallet.String(),
+		ReceiverWalletID: merchantWallet.String(),
+		TransactionType:  "transfer",
+		Amount:           "100.00",
+		Currency:         "NGN",
+		IdempotencyKey:   uuid.New().String(),
+	}
+	first, err := svc.CreateAuthorizedTransaction(ctx, payer, req)
+	require.NoError(t, err)
+
+	// The wallet is empty now, but a retry still gets the transfer it already made.
+	again, err := svc.CreateAuthorizedTransaction(ctx, payer, req)
+	require.NoError(t, err)
+	require.Equal(t, first.ID, again.ID)
+
+	balance, _ := walletBalances(t, f, payerWallet)
+	require.Equal(t, "0.00", balance)
+
+	// Someone else's key never hands back their transaction.
+	stolen := req
+	stolen.SenderWalletID = merchantWallet.String()
+	stolen.ReceiverWalletID = payerWallet.String()
+	stolen.Amount = "1.00"
+	_, err = svc.CreateAuthorizedTransaction(ctx, merchant, stolen)
+	require.ErrorIs(t, err, ErrIdempotencyKeyReused)
+}
+
 func TestCreateTransaction_Concurrency(t *testing.T) {
 	f := store.NewFakeStore()
-	svc := NewService(f)
+	svc := newTestService(f)
 
 	userID := uuid.New()
+	setTestPIN(t, f, userID)
 	senderWalletID := uuid.New()
 	receiverWalletID := uuid.New()
 
@@ -164,8 +286,9 @@ func TestCreateTransaction_Concurrency(t *testing.T) {
 				Amount:           "100.00",
 				Currency:         "NGN",
 				IdempotencyKey:   uuid.New().String(),
+				PIN:              testPIN,
 			}
-			_, err := svc.CreateTransaction(context.Background(), userID, req)
+			_, err := svc.CreateTransaction(context.Background(), userID, uuid.Nil, req)
 			errs <- err
 		}()
 	}
@@ -185,3 +308,111 @@ func TestCreateTransaction_Concurrency(t *testing.T) {
 	require.Equal(t, 1, success)
 	require.Equal(t, 1, insufficient)
 }
+
+func TestCreateTransaction_ChargesFee(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := newTestService(f)
+	ctx := context.Background()
+
+	_, err := fees.NewService(f).CreateSchedule(ctx, fees.CreateScheduleRequest{
+		TransactionType: "transfer",
+		Currency:        "NGN",
+		FeeType:         "percentage",
+		Rate:            "0.01",
+		MinFee:          "1",
+	})
+	require.NoError(t, err)
+
+	userID := uuid.New()
+	setTestPIN(t, f, userID)
+	sender := db.GetWalletsAndLockByWalletIdsRow{ID: uuid.New(), UserID: pgtype.UUID{Bytes: userID, Valid: true}, Currency: "NGN"}
+	require.NoError(t, sender.Balance.Scan("100"))
+	receiver := db.GetWalletsAndLockByWalletIdsRow{ID: uuid.New(), UserID: pgtype.UUID{Bytes: uuid.New(), Valid: true}, Currency: "NGN"}
+	require.NoError(t, receiver.Balance.Scan("0"))
+	f.AddFakeWallet(sender)
+	f.AddFakeWallet(receiver)
+
+	quote, err := svc.QuoteTransaction(ctx, userID, QuoteTransactionRequest{
+		SenderWalletID:  sender.ID.String(),
+		TransactionType: "transfer",
+		Amount:          "50",
+		Currency:        "NGN",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "1.00", quote.Fee.StringFixed(2))
+	require.Equal(t, "51.00", quote.Total.StringFixed(2))
+
+	req := CreateTransactionRequest{
+		SenderWalletID:   sender.ID.String(),
+		ReceiverWalletID: receiver.ID.String(),
+		TransactionType:  "transfer",
+		Amount:           "99.50",
+		Currency:         "NGN",
+		IdempotencyKey:   uuid.New().String(),
+		PIN:              testPIN,
+	}
+	// 99.50 plus the 1.00 minimum fee is more than the wallet holds.
+	_, err = svc.CreateTransaction(ctx, userID, uuid.Nil, req)
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	req.Amount = "50"
+	transaction, err := svc.CreateTransaction(ctx, userID, uuid.Nil, req)
+	require.NoError(t, err)
+	require.Equal(t, "1.00", utils.NumericToDecimal(transaction.Fee).StringFixed(2))
+
+	breakdown, err := svc.FeeBreakdown(ctx, transaction)
+	require.NoError(t, err)
+	require.Equal(t, quote, breakdown)
+
+	feesID, err := accounts.SystemWallet(ctx, f.Queries(), accounts.FeesRevenue, "NGN")
+	require.NoError(t, err)
+	for id, want := range map[uuid.UUID]string{sender.ID: "49.00", receiver.ID: "50.00", feesID: "1.00"} {
+		w, err := f.GetWalletById(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, want, utils.NumericToDecimal(w.Balance).StringFixed(2))
+	}
+}
+
+func TestCreateTransaction_RejectsAmountsFinerThanMinorUnit(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := newTestService(f)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	setTestPIN(t, f, userID)
+	senderWalletID := uuid.New()
+	receiverWalletID := uuid.New()
+
+	senderWallet := db.GetWalletsAndLockByWalletIdsRow{
+		ID:       senderWalletID,
+		UserID:   pgtype.UUID{Bytes: userID, Valid: true},
+		Currency: "JPY",
+	}
+	require.NoError(t, senderWallet.Balance.Scan("1000"))
+	f.AddFakeWallet(senderWallet)
+	f.AddFakeWallet(db.GetWalletsAndLockByWalletIdsRow{ID: receiverWalletID, Currency: "JPY"})
+
+	req := CreateTransactionRequest{
+		SenderWalletID:   senderWalletID.String(),
+		ReceiverWalletID: receiverWalletID.String(),
+		TransactionType:  "transfer",
+		Amount:           "100.50",
+		Currency:         "JPY",
+		IdempotencyKey:   uuid.New().String(),
+		PIN:              testPIN,
+	}
+
+	// the yen has no minor unit
+	_, err := svc.CreateTransaction(ctx, userID, uuid.Nil, req)
+	require.ErrorIs(t, err, money.ErrTooPrecise)
+	require.True(t, IsInvalidAmount(err))
+
+	req.Amount = "100"
+	created, err := svc.CreateTransaction(ctx, userID, uuid.Nil, req)
+	require.NoError(t, err)
+	require.Equal(t, "100", utils.NumericToDecimal(created.Amount).String())
+
+	sender, err := f.GetWalletById(ctx, senderWalletID)
+	require.NoError(t, err)
+	require.Equal(t, "900", utils.NumericToDecimal(sender.Balance).String())
+}
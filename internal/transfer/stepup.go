@@ -0,0 +1,225 @@
+package transfer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/otp"
+	"github.com/luponetn/paycore/internal/tasks"
+	"github.com/luponetn/paycore/pkg/utils"
+	"github.com/shopspring/decimal"
+)
+
+// PurposeTransaction is the otps.purpose of step-up codes.
+const PurposeTransaction = "transaction"
+
+// Reasons a transfer needs step-up verification.
+const (
+	StepUpAmountThreshold = "amount_threshold"
+	StepUpNewBeneficiary  = "new_beneficiary"
+	StepUpNewDevice       = "new_device"
+)
+
+// StepUpRequiredError is returned when a transfer needs an emailed OTP. The code has been
+// sent; repeating the request with it in the otp field completes the transfer.
+type StepUpRequiredError struct {
+	Reasons []string
+}
+
+func (e *StepUpRequiredError) Error() string {
+	return fmt.Sprintf("%s (%s)", ErrStepUpRequired, strings.Join(e.Reasons, ", "))
+}
+
+func (e *StepUpRequiredError) Unwrap() error {
+	return ErrStepUpRequired
+}
+
+// transferAuthorization is what the caller presented to approve a transfer.
+type transferAuthorization struct {
+	userID     uuid.UUID
+	sessionID  uuid.UUID
+	senderID   uuid.UUID
+	receiverID uuid.UUID
+	amount     decimal.Decimal
+	currency   string
+	idemKey    string
+	otp        string
+}
+
+// authorizeTransfer applies the configured step-up rules. When any rule matches, the transfer
+// only proceeds with a valid OTP issued for exactly this transfer; without one, a code is
+// emailed and a *StepUpRequiredError is returned.
+func (s *Svc) authorizeTransfer(ctx context.Context, auth transferAuthorization) error {
+	reasons, err := s.stepUpReasons(ctx, auth)
+	if err != nil {
+		return err
+	}
+	if len(reasons) == 0 {
+		return nil
+	}
+
+	reference := auth.reference()
+
+	if auth.otp == "" {
+		if err := s.sendStepUpOTP(ctx, auth.userID, reference); err != nil {
+			return err
+		}
+		return &StepUpRequiredError{Reasons: reasons}
+	}
+
+	_, err = utils.Retry(3, 100, func() (struct{}, error) {
+		tx, err := s.store.Begin(ctx)
+		if err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+
+		defer func() {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback step-up otp tx", "error", rbErr)
+			}
+		}()
+
+		otpErr := otp.Consume(ctx, s.store.WithTx(tx), auth.userID, PurposeTransaction, reference, auth.otp)
+		if utils.IsRetryableError(otpErr) {
+			return struct{}{}, otpErr
+		}
+
+		// commit on failure too, so wrong codes keep counting against the attempt limit
+		if err := tx.Commit(ctx); err != nil {
+			return struct{}{}, &utils.RetryableError{Err: err}
+		}
+		return struct{}{}, otpErr
+	})
+	return err
+}
+
+func (s *Svc) stepUpReasons(ctx context.Context, auth transferAuthorization) ([]string, error) {
+	var reasons []string
+
+	if threshold := s.cfg.StepUpAmountThreshold; threshold.IsPositive() && auth.amount.GreaterThanOrEqual(threshold) {
+		reasons = append(reasons, StepUpAmountThreshold)
+	}
+
+	if s.cfg.StepUpNewBeneficiary {
+		receiver, err := s.store.Queries().GetWalletById(ctx, auth.receiverID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, ErrWalletNotFound
+			}
+			return nil, err
+		}
+
+		// moving money between your own wallets never counts as a new beneficiary
+		if !receiver.UserID.Valid || uuid.UUID(receiver.UserID.Bytes) != auth.userID {
+			previous, err := s.store.Queries().CountCompletedTransfersToWallet(ctx, db.CountCompletedTransfersToWalletParams{
+				ReceiverWalletID: utils.ToPgUUID(auth.receiverID),
+				UserID:           utils.ToPgUUID(auth.userID),
+			})
+			if err != nil {
+				return nil, err
+			}
+			if previous == 0 {
+				reasons = append(reasons, StepUpNewBeneficiary)
+			}
+		}
+	}
+
+	if window := s.cfg.StepUpNewDeviceWindow; window > 0 {
+		newDevice := true
+		if auth.sessionID != uuid.Nil {
+			signedInAt, err := s.store.Queries().GetSessionFamilySignedInAt(ctx, auth.sessionID)
+			if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+				return nil, err
+			}
+			newDevice = err != nil || time.Since(signedInAt.Time) < window
+		}
+		if newDevice {
+			reasons = append(reasons, StepUpNewDevice)
+		}
+	}
+
+	return reasons, nil
+}
+
+// sendStepUpOTP issues a code bound to reference and emails it. While the last code for the
+// same transfer is younger than otp.ResendAfter it is reused, so retried requests do not
+// flood the user's inbox.
+func (s *Svc) sendStepUpOTP(ctx context.Context, userID uuid.UUID, reference string) error {
+	var user db.User
+	code, err := utils.Retry(3, 100, func() (string, error) {
+		tx, err := s.store.Begin(ctx)
+		if err != nil {
+			return "", &utils.RetryableError{Err: err}
+		}
+
+		defer func() {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback step-up issue tx", "error", rbErr)
+			}
+		}()
+
+		qtx := s.store.WithTx(tx)
+
+		user, err = qtx.GetUserByID(ctx, userID)
+		if err != nil {
+			return "", &utils.RetryableError{Err: err}
+		}
+
+		latest, err := qtx.GetLatestOTPByPurpose(ctx, db.GetLatestOTPByPurposeParams{UserID: userID, Purpose: PurposeTransaction})
+		if err == nil && latest.Reference == reference && !latest.Used.Bool && time.Since(latest.CreatedAt.Time) < otp.ResendAfter {
+			return "", nil
+		} else if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return "", &utils.RetryableError{Err: err}
+		}
+
+		_, code, err := otp.Issue(ctx, qtx, userID, PurposeTransaction, reference)
+		if err != nil {
+			return "", &utils.RetryableError{Err: err}
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return "", &utils.RetryableError{Err: err}
+		}
+		return code, nil
+	})
+	if err != nil || code == "" {
+		return err
+	}
+
+	task, err := tasks.NewSendOTPEmailTask(tasks.SendOTPEmailPayload{
+		UserID:   user.ID.String(),
+		Email:    user.Email,
+		FullName: user.FullName,
+		OTP:      code,
+		Purpose:  PurposeTransaction,
+
+		ExpiresInMinutes: int(otp.TTL.Minutes()),
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.taskClient.EnqueueContext(ctx, task, asynq.Queue(tasks.QueueCritical), asynq.MaxRetry(5)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// reference binds a step-up code to one transfer: a code issued for one payment cannot
+// approve a different receiver, amount or idempotency key.
+func (a transferAuthorization) reference() string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		a.idemKey, a.senderID.String(), a.receiverID.String(), a.amount.String(), a.currency,
+	}, "|")))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,92 @@
+package transfer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/luponetn/paycore/internal/config"
+	"github.com/luponetn/paycore/internal/store"
+	"github.com/luponetn/paycore/internal/tasks"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyPIN_LocksAfterRepeatedFailures(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := newTestService(f)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	require.ErrorIs(t, svc.VerifyPIN(ctx, userID, testPIN), ErrPINNotSet)
+
+	setTestPIN(t, f, userID)
+	require.NoError(t, svc.VerifyPIN(ctx, userID, testPIN))
+
+	for i := 1; i < pinMaxAttempts; i++ {
+		require.ErrorIs(t, svc.VerifyPIN(ctx, userID, "9999"), ErrInvalidPIN)
+	}
+	require.ErrorIs(t, svc.VerifyPIN(ctx, userID, "9999"), ErrPINLocked)
+
+	// The right PIN does not get through while the lock holds.
+	require.ErrorIs(t, svc.VerifyPIN(ctx, userID, testPIN), ErrPINLocked)
+
+	status, err := svc.PINStatus(ctx, userID)
+	require.NoError(t, err)
+	require.True(t, status.IsSet)
+	require.True(t, status.LockedUntil.Time.After(time.Now()))
+}
+
+func TestCreateTransaction_StepUpAboveThreshold(t *testing.T) {
+	f := store.NewFakeStore()
+	enqueuer := &fakeEnqueuer{}
+	svc := NewService(f, enqueuer, &config.Config{StepUpAmountThreshold: decimal.NewFromInt(50)})
+	payer, _, payerWallet, merchantWallet := setupHoldWallets(t, f, "100")
+	ctx := context.Background()
+
+	req := CreateTransactionRequest{
+		SenderWalletID:   payerWallet.String(),
+		ReceiverWalletID: merchantWallet.String(),
+		TransactionType:  "transfer",
+		Amount:           "60.00",
+		Currency:         "NGN",
+		IdempotencyKey:   uuid.New().String(),
+		PIN:              testPIN,
+	}
+
+	// Below the threshold no code is needed.
+	small := req
+	small.Amount = "10.00"
+	small.IdempotencyKey = uuid.New().String()
+	_, err := svc.CreateTransaction(ctx, payer, uuid.Nil, small)
+	require.NoError(t, err)
+	require.Empty(t, enqueuer.tasks)
+
+	_, err = svc.CreateTransaction(ctx, payer, uuid.Nil, req)
+	var stepUp *StepUpRequiredError
+	require.True(t, errors.As(err, &stepUp))
+	require.Equal(t, []string{StepUpAmountThreshold}, stepUp.Reasons)
+
+	require.Len(t, enqueuer.tasks, 1)
+	require.Equal(t, tasks.TypeSendOTPEmail, enqueuer.tasks[0].Type())
+	var payload tasks.SendOTPEmailPayload
+	require.NoError(t, json.Unmarshal(enqueuer.tasks[0].Payload(), &payload))
+	require.Equal(t, PurposeTransaction, payload.Purpose)
+
+	// The code only approves the transfer it was issued for.
+	changed := req
+	changed.Amount = "70.00"
+	changed.OTP = payload.OTP
+	_, err = svc.CreateTransaction(ctx, payer, uuid.Nil, changed)
+	require.ErrorIs(t, err, ErrOTPInvalid)
+
+	req.OTP = payload.OTP
+	_, err = svc.CreateTransaction(ctx, payer, uuid.Nil, req)
+	require.NoError(t, err)
+
+	balance, _ := walletBalances(t, f, payerWallet)
+	require.Equal(t, "30.00", balance)
+}
@@ -1,12 +1,83 @@
 package transfer
 
+import (
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/fees"
+)
+
+// CreateTransactionRequest moves money between wallets. Money entering or leaving the platform
+// goes through the funding provider instead.
 type CreateTransactionRequest struct {
 	SenderWalletID    string `json:"sender_wallet_id" binding:"required,uuid"`
 	ReceiverWalletID  string `json:"receiver_wallet_id" binding:"omitempty,uuid"`
 	ReceiverAccountNo string `json:"receiver_account_no" binding:"omitempty"`
-	TransactionType   string `json:"transaction_type" binding:"required,oneof=transfer deposit withdrawal"`
+	TransactionType   string `json:"transaction_type" binding:"required,oneof=transfer"`
 	Amount            string `json:"amount" binding:"required"` // Using string for precision from frontend
 	Description       string `json:"description"`
 	Currency          string `json:"currency" binding:"required,len=3"`
 	IdempotencyKey    string `json:"idempotency_key" binding:"required"`
+	PIN               string `json:"pin" binding:"required,numeric,min=4,max=6"`
+	OTP               string `json:"otp" binding:"omitempty,len=6,numeric"` // step-up code, when asked for one
+}
+
+type QuoteTransactionRequest struct {
+	SenderWalletID  string `json:"sender_wallet_id" binding:"required,uuid"`
+	TransactionType string `json:"transaction_type" binding:"required,oneof=transfer"`
+	Amount          string `json:"amount" binding:"required"`
+	Currency        string `json:"currency" binding:"required,len=3"`
+}
+
+// TransactionResponse is a transaction with the breakdown of the fee charged on it.
+type TransactionResponse struct {
+	db.Transaction
+	FeeBreakdown fees.Breakdown `json:"fee_breakdown"`
+}
+
+type PlaceHoldRequest struct {
+	SenderWalletID   string `json:"sender_wallet_id" binding:"required,uuid"`
+	ReceiverWalletID string `json:"receiver_wallet_id" binding:"required,uuid"`
+	Amount           string `json:"amount" binding:"required"`
+	Description      string `json:"description"`
+	Currency         string `json:"currency" binding:"required,len=3"`
+	ExpiresInMinutes int    `json:"expires_in_minutes" binding:"omitempty,min=1"`
+	IdempotencyKey   string `json:"idempotency_key" binding:"required"`
+	PIN              string `json:"pin" binding:"required,numeric,min=4,max=6"`
+}
+
+type CaptureHoldRequest struct {
+	Amount         string `json:"amount" binding:"required"`
+	Final          bool   `json:"final"` // release whatever is left on the hold after this capture
+	IdempotencyKey string `json:"idempotency_key" binding:"required"`
+}
+
+type ExtendHoldRequest struct {
+	ExtendByMinutes int `json:"extend_by_minutes" binding:"required,min=1"`
+}
+
+type ReverseTransactionRequest struct {
+	Reason         string `json:"reason"`
+	IdempotencyKey string `json:"idempotency_key" binding:"required"`
+}
+
+type RefundTransactionRequest struct {
+	Amount         string `json:"amount" binding:"required"`
+	Reason         string `json:"reason"`
+	IdempotencyKey string `json:"idempotency_key" binding:"required"`
+}
+
+type SetPINRequest struct {
+	Password string `json:"password" binding:"required"`
+	PIN      string `json:"pin" binding:"required,numeric,min=4,max=6"`
+}
+
+type PINStatusResponse struct {
+	IsSet       bool               `json:"is_set"`
+	LockedUntil pgtype.Timestamptz `json:"locked_until"`
+}
+
+// RequestMeta describes the client a request came from, for security alerts.
+type RequestMeta struct {
+	IPAddress string
+	UserAgent string
 }
@@ -0,0 +1,8 @@
+package wallet
+
+import "errors"
+
+var (
+	ErrUnsupportedCurrency = errors.New("wallets cannot be opened in this currency")
+	ErrWalletExists        = errors.New("you already have a wallet of this type in this currency")
+)
@@ -1,10 +1,12 @@
 package wallet
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/luponetn/paycore/internal/transfer"
 	"github.com/luponetn/paycore/pkg/utils"
 )
 
@@ -153,3 +155,66 @@ func (h *Handler) ResolveAccountHandler(c *gin.Context) {
 		"data":    row,
 	})
 }
+
+// MoveFundsHandler handles POST /wallets/move
+func (h *Handler) MoveFundsHandler(c *gin.Context) {
+	var req MoveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	userIDVal, _ := c.Get("user_id")
+	authUserID := userIDVal.(uuid.UUID)
+
+	transaction, err := h.Svc.MoveFundsService(c.Request.Context(), authUserID, req)
+	if err != nil {
+		c.JSON(moveErrorStatus(err), gin.H{"message": "failed to move funds", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "funds moved successfully", "transaction": transaction})
+}
+
+// OpenWalletHandler handles POST /wallets
+func (h *Handler) OpenWalletHandler(c *gin.Context) {
+	var req OpenWalletRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	userIDVal, _ := c.Get("user_id")
+	authUserID := userIDVal.(uuid.UUID)
+
+	wallet, err := h.Svc.OpenWalletService(c.Request.Context(), authUserID, req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, ErrUnsupportedCurrency):
+			status = http.StatusBadRequest
+		case errors.Is(err, ErrWalletExists):
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"message": "failed to open wallet", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "wallet opened successfully", "wallet": wallet})
+}
+
+func moveErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, transfer.ErrInsufficientFunds), errors.Is(err, transfer.ErrWalletNotMatured),
+		errors.Is(err, transfer.ErrIdempotencyKeyReused):
+		return http.StatusConflict
+	case errors.Is(err, transfer.ErrSameWallet), transfer.IsInvalidAmount(err), errors.Is(err, transfer.ErrCurrencyMismatch):
+		return http.StatusBadRequest
+	case errors.Is(err, transfer.ErrWalletNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, transfer.ErrUnauthorizedWallet), errors.Is(err, transfer.ErrEmailNotVerified):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
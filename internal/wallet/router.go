@@ -3,14 +3,17 @@ package wallet
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/luponetn/paycore/internal/middleware"
+	"github.com/luponetn/paycore/pkg/utils"
 )
 
-func RegisterRoutes(r *gin.Engine, h *Handler, secret string) {
+func RegisterRoutes(r *gin.Engine, h *Handler, keys *utils.KeySet, sessions middleware.SessionChecker) {
 	walletGroup := r.Group("/wallets")
-	walletGroup.Use(middleware.AuthMiddleware(secret))
+	walletGroup.Use(middleware.AuthMiddleware(keys, sessions))
 	{
+		walletGroup.POST("", h.OpenWalletHandler)
 		walletGroup.GET("/me", h.GetMyWallets)
 		walletGroup.GET("/resolve", h.ResolveAccountHandler)
+		walletGroup.POST("/move", h.MoveFundsHandler)
 		walletGroup.GET("/:id", h.GetWalletHandler)
 		walletGroup.GET("/:id/transactions", h.GetWalletTransactionsHandler)
 	}
@@ -2,11 +2,19 @@ package wallet
 
 import (
 	"context"
+	"errors"
+	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/luponetn/paycore/internal/config"
 	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/outbox"
 	"github.com/luponetn/paycore/internal/store"
+	"github.com/luponetn/paycore/internal/transfer"
 	"github.com/luponetn/paycore/pkg/utils"
 )
 
@@ -15,14 +23,19 @@ type Service interface {
 	GetWalletsByUserService(ctx context.Context, userID uuid.UUID) ([]db.Wallet, error)
 	GetWalletTransactionsService(ctx context.Context, walletID uuid.UUID, limit int32, offset int32) ([]db.Transaction, error)
 	ResolveAccountNumberService(ctx context.Context, accountNo string) (db.GetWalletByAccountNoRow, error)
+	MoveFundsService(ctx context.Context, userID uuid.UUID, req MoveRequest) (db.Transaction, error)
+	OpenWalletService(ctx context.Context, userID uuid.UUID, req OpenWalletRequest) (db.Wallet, error)
 }
 
 type Svc struct {
-	store store.Store
+	store       store.Store
+	transferSvc transfer.Service
+	cfg         *config.Config
 }
 
-func NewService(store store.Store) Service {
-	return &Svc{store: store}
+// NewService wires the wallet service; cfg carries the currencies wallets may be opened in.
+func NewService(store store.Store, transferSvc transfer.Service, cfg *config.Config) Service {
+	return &Svc{store: store, transferSvc: transferSvc, cfg: cfg}
 }
 
 // implement services for all wallet operations
@@ -87,3 +100,103 @@ func (s *Svc) ResolveAccountNumberService(ctx context.Context, accountNo string)
 		return row, nil
 	})
 }
+
+// MoveFundsService moves money between two of the user's own wallets as an internal_move
+// transaction. The money never leaves the user, so no PIN is asked for; wallet-type rules such
+// as the fixed wallet maturity lock are enforced by the transfer service under the wallet locks.
+func (s *Svc) MoveFundsService(ctx context.Context, userID uuid.UUID, req MoveRequest) (db.Transaction, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	fromID, err := uuid.Parse(req.FromWalletID)
+	if err != nil {
+		return db.Transaction{}, transfer.ErrWalletNotFound
+	}
+
+	from, err := utils.Retry(3, 100, func() (db.Wallet, error) {
+		wallet, err := s.store.Queries().GetWalletById(ctx, fromID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return db.Wallet{}, transfer.ErrWalletNotFound
+			}
+			return db.Wallet{}, &utils.RetryableError{Err: err}
+		}
+		return wallet, nil
+	})
+	if err != nil {
+		return db.Transaction{}, err
+	}
+
+	if from.UserID != utils.ToPgUUID(userID) {
+		return db.Transaction{}, transfer.ErrUnauthorizedWallet
+	}
+
+	amount, err := transfer.ParseAmount(req.Amount, from.Currency)
+	if err != nil {
+		return db.Transaction{}, err
+	}
+
+	return s.transferSvc.CreateAuthorizedTransaction(ctx, userID, transfer.CreateTransactionRequest{
+		SenderWalletID:   req.FromWalletID,
+		ReceiverWalletID: req.ToWalletID,
+		TransactionType:  string(db.TransactionTypeEnumInternalMove),
+		Amount:           amount.StringFixed(),
+		Description:      req.Description,
+		Currency:         amount.Code(),
+		IdempotencyKey:   req.IdempotencyKey,
+	})
+}
+
+// OpenWalletService opens a wallet of the given type in another currency. A user has at most one
+// wallet of each type per currency.
+func (s *Svc) OpenWalletService(ctx context.Context, userID uuid.UUID, req OpenWalletRequest) (db.Wallet, error) {
+	ctx, cancel := context.WithTimeout(ctx, 8*time.Second)
+	defer cancel()
+
+	currency := strings.ToUpper(req.Currency)
+	if !s.cfg.SupportsCurrency(currency) {
+		return db.Wallet{}, ErrUnsupportedCurrency
+	}
+
+	walletType := db.WalletTypeEnumSavings
+	if req.WalletType != "" {
+		walletType = db.WalletTypeEnum(req.WalletType)
+	}
+
+	return utils.Retry(3, 100, func() (db.Wallet, error) {
+		tx, err := s.store.Begin(ctx)
+		if err != nil {
+			return db.Wallet{}, &utils.RetryableError{Err: err}
+		}
+
+		defer func() {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback open wallet tx", "error", rbErr)
+			}
+		}()
+
+		qtx := s.store.WithTx(tx)
+
+		wallet, err := qtx.CreateWallet(ctx, db.CreateWalletParams{
+			UserID:     utils.ToPgUUID(userID),
+			WalletType: walletType,
+			Currency:   currency,
+		})
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				return db.Wallet{}, ErrWalletExists
+			}
+			return db.Wallet{}, &utils.RetryableError{Err: err}
+		}
+
+		if err := outbox.RecordWalletCreated(ctx, qtx, wallet); err != nil {
+			return db.Wallet{}, &utils.RetryableError{Err: err}
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return db.Wallet{}, &utils.RetryableError{Err: err}
+		}
+		return wallet, nil
+	})
+}
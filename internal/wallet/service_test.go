@@ -0,0 +1,137 @@
+package wallet
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/luponetn/paycore/internal/config"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/deposit"
+	"github.com/luponetn/paycore/internal/outbox"
+	"github.com/luponetn/paycore/internal/store"
+	"github.com/luponetn/paycore/internal/transfer"
+	"github.com/luponetn/paycore/pkg/utils"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func addWallet(t *testing.T, f *store.FakeStore, owner uuid.UUID, walletType db.WalletTypeEnum, balance string) uuid.UUID {
+	t.Helper()
+	w := db.GetWalletsAndLockByWalletIdsRow{
+		ID:         uuid.New(),
+		UserID:     pgtype.UUID{Bytes: owner, Valid: true},
+		Currency:   "NGN",
+		WalletType: walletType,
+	}
+	require.NoError(t, w.Balance.Scan(balance))
+	f.AddFakeWallet(w)
+	return w.ID
+}
+
+func TestMoveFunds_BetweenOwnWallets(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := NewService(f, transfer.NewService(f, nil, &config.Config{}), &config.Config{})
+	ctx := context.Background()
+
+	userID := uuid.New()
+	savings := addWallet(t, f, userID, db.WalletTypeEnumSavings, "100")
+	misc := addWallet(t, f, userID, db.WalletTypeEnumMisc, "0")
+
+	moved, err := svc.MoveFundsService(ctx, userID, MoveRequest{
+		FromWalletID:   savings.String(),
+		ToWalletID:     misc.String(),
+		Amount:         "40.00",
+		IdempotencyKey: uuid.New().String(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, db.TransactionTypeEnumInternalMove, moved.TransactionType)
+
+	w, err := f.GetWalletById(ctx, misc)
+	require.NoError(t, err)
+	require.Equal(t, "40.00", utils.NumericToDecimal(w.Balance).StringFixed(2))
+
+	// Someone else's wallet is never a valid destination for an internal move.
+	other := addWallet(t, f, uuid.New(), db.WalletTypeEnumSavings, "0")
+	_, err = svc.MoveFundsService(ctx, userID, MoveRequest{
+		FromWalletID:   savings.String(),
+		ToWalletID:     other.String(),
+		Amount:         "10.00",
+		IdempotencyKey: uuid.New().String(),
+	})
+	require.ErrorIs(t, err, transfer.ErrUnauthorizedWallet)
+}
+
+func TestMoveFunds_FixedWalletLockedWhileDepositRuns(t *testing.T) {
+	f := store.NewFakeStore()
+	cfg := &config.Config{FixedDepositRates: map[int32]decimal.Decimal{30: decimal.RequireFromString("0.10")}}
+	svc := NewService(f, transfer.NewService(f, nil, cfg), cfg)
+	deposits := deposit.NewService(f, cfg)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	savings := addWallet(t, f, userID, db.WalletTypeEnumSavings, "1000")
+	fixed := addWallet(t, f, userID, db.WalletTypeEnumFixed, "0")
+
+	fd, err := deposits.CreateDeposit(ctx, userID, deposit.CreateDepositRequest{
+		SourceWalletID: savings.String(),
+		Amount:         "500.00",
+		TenorDays:      30,
+		IdempotencyKey: uuid.New().String(),
+	})
+	require.NoError(t, err)
+
+	// Topping up the fixed wallet is fine, taking it straight back out is not.
+	_, err = svc.MoveFundsService(ctx, userID, MoveRequest{
+		FromWalletID:   savings.String(),
+		ToWalletID:     fixed.String(),
+		Amount:         "200.00",
+		IdempotencyKey: uuid.New().String(),
+	})
+	require.NoError(t, err)
+
+	back := MoveRequest{
+		FromWalletID:   fixed.String(),
+		ToWalletID:     savings.String(),
+		Amount:         "200.00",
+		IdempotencyKey: uuid.New().String(),
+	}
+	_, err = svc.MoveFundsService(ctx, userID, back)
+	require.ErrorIs(t, err, transfer.ErrWalletNotMatured)
+
+	// Once no deposit is running the wallet can be debited again.
+	_, err = deposits.BreakDeposit(ctx, userID, fd.ID)
+	require.NoError(t, err)
+
+	back.IdempotencyKey = uuid.New().String()
+	_, err = svc.MoveFundsService(ctx, userID, back)
+	require.NoError(t, err)
+}
+
+func TestOpenWallet_OnePerTypeAndCurrency(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := NewService(f, transfer.NewService(f, nil, &config.Config{}), &config.Config{SupportedCurrencies: []string{"NGN", "USD"}})
+	ctx := context.Background()
+	userID := uuid.New()
+
+	wallet, err := svc.OpenWalletService(ctx, userID, OpenWalletRequest{Currency: "usd"})
+	require.NoError(t, err)
+	require.Equal(t, "USD", wallet.Currency)
+	require.Equal(t, db.WalletTypeEnumSavings, wallet.WalletType)
+
+	_, err = svc.OpenWalletService(ctx, userID, OpenWalletRequest{Currency: "USD", WalletType: "savings"})
+	require.ErrorIs(t, err, ErrWalletExists)
+
+	_, err = svc.OpenWalletService(ctx, userID, OpenWalletRequest{Currency: "USD", WalletType: "fixed"})
+	require.NoError(t, err)
+
+	_, err = svc.OpenWalletService(ctx, userID, OpenWalletRequest{Currency: "JPY"})
+	require.ErrorIs(t, err, ErrUnsupportedCurrency)
+
+	// Only the two wallets actually opened are announced.
+	events := f.OutboxEvents()
+	require.Len(t, events, 2)
+	require.Equal(t, wallet.ID, events[0].AggregateID)
+	require.Equal(t, outbox.EventWalletCreated, events[0].EventType)
+}
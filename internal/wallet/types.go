@@ -4,3 +4,19 @@ type PaginationQuery struct {
 	Page     int32 `form:"page,default=1" binding:"min=1"`
 	PageSize int32 `form:"page_size,default=20" binding:"min=1,max=100"`
 }
+
+// MoveRequest moves money between two wallets owned by the caller. The currency is taken from
+// the source wallet.
+type MoveRequest struct {
+	FromWalletID   string `json:"from_wallet_id" binding:"required,uuid"`
+	ToWalletID     string `json:"to_wallet_id" binding:"required,uuid"`
+	Amount         string `json:"amount" binding:"required"`
+	Description    string `json:"description"`
+	IdempotencyKey string `json:"idempotency_key" binding:"required"`
+}
+
+// OpenWalletRequest opens another wallet for the caller; the type defaults to savings.
+type OpenWalletRequest struct {
+	Currency   string `json:"currency" binding:"required,len=3"`
+	WalletType string `json:"wallet_type" binding:"omitempty,oneof=savings fixed misc"`
+}
@@ -0,0 +1,102 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// hostResolver looks up the addresses of an endpoint's host; *net.Resolver implements it.
+type hostResolver interface {
+	LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error)
+}
+
+// nonPublicPrefixes are ranges that are neither private nor loopback by the net/netip
+// definitions but still do not belong to anyone's public server.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // "this network"
+	netip.MustParsePrefix("100.64.0.0/10"),  // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),  // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),    // reserved
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64, which can reach private IPv4 addresses
+	netip.MustParsePrefix("64:ff9b:1::/48"), // local-use NAT64
+	netip.MustParsePrefix("2001:db8::/32"),  // documentation
+}
+
+// isPublicAddr reports whether addr can be a receiver on the internet. Loopback, private,
+// link-local (which includes cloud metadata services), multicast and unspecified addresses
+// cannot.
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkHost resolves host and fails with ErrPrivateURL if any of its addresses is not public.
+// Every address is checked because the dialer may pick any of them.
+func (s *Svc) checkHost(ctx context.Context, host string) error {
+	if s.cfg.WebhookAllowPrivateNetworks {
+		return nil
+	}
+
+	addrs, err := s.resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return ErrPrivateURL
+		}
+		return err
+	}
+	for _, addr := range addrs {
+		if !isPublicAddr(addr) {
+			return ErrPrivateURL
+		}
+	}
+	return nil
+}
+
+// newHTTPClient returns the client deliveries are posted with. Unless private networks are
+// allowed its dialer refuses non-public addresses, so an endpoint whose name resolved to a
+// public address when it was registered cannot later be pointed at an internal one.
+func newHTTPClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}
+	if !allowPrivate {
+		dialer.Control = func(network, address string, c syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !isPublicAddr(addrPort.Addr()) {
+				return fmt.Errorf("webhook receiver address %s is not public", addrPort.Addr())
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// deliveries go straight to the receiver so the dialer sees its real address
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		// a redirect would send the signed payload somewhere the owner did not register
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
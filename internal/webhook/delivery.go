@@ -0,0 +1,324 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/outbox"
+	"github.com/luponetn/paycore/internal/tasks"
+	"github.com/luponetn/paycore/pkg/utils"
+)
+
+// maxResponseBody is how much of a receiver's response the delivery log keeps.
+const maxResponseBody = 1024
+
+// attemptResult is what one POST to an endpoint came back with.
+type attemptResult struct {
+	code     int
+	body     string
+	err      error
+	duration time.Duration
+}
+
+func (r attemptResult) ok() bool {
+	return r.err == nil && r.code >= 200 && r.code < 300
+}
+
+func (r attemptResult) failure() string {
+	if r.err != nil {
+		return r.err.Error()
+	}
+	return fmt.Sprintf("receiver answered %d", r.code)
+}
+
+// DispatchEvent queues a delivery of a relayed event to every active endpoint of the users it
+// concerns that subscribes to its type. An event is delivered once per endpoint however often
+// it is relayed.
+func (s *Svc) DispatchEvent(ctx context.Context, raw []byte) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	var event outbox.Event
+	if err := json.Unmarshal(raw, &event); err != nil {
+		return 0, err
+	}
+
+	owners, err := outbox.Owners(ctx, s.store.Queries(), event)
+	if err != nil || len(owners) == 0 {
+		return 0, err
+	}
+
+	endpoints, err := s.store.Queries().GetActiveWebhookEndpointsForEvent(ctx, db.GetActiveWebhookEndpointsForEventParams{
+		UserIds:   owners,
+		EventType: event.Type,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	queued := 0
+	for _, endpoint := range endpoints {
+		delivery, err := s.store.Queries().CreateWebhookDelivery(ctx, db.CreateWebhookDeliveryParams{
+			EndpointID: endpoint.ID,
+			EventID:    event.ID,
+			EventType:  event.Type,
+			Payload:    raw,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			// an earlier run created it and may have stopped before queueing the first attempt
+			delivery, err = s.store.Queries().GetWebhookDeliveryByEvent(ctx, db.GetWebhookDeliveryByEventParams{
+				EndpointID: endpoint.ID,
+				EventID:    event.ID,
+			})
+		}
+		if err != nil {
+			return queued, err
+		}
+		if delivery.Status != db.WebhookDeliveryStatusEnumPending || delivery.Attempts > 0 {
+			continue
+		}
+
+		if err := s.enqueue(ctx, delivery.ID, 0, 0); err != nil {
+			return queued, err
+		}
+		queued++
+	}
+	return queued, nil
+}
+
+// Deliver makes the next attempt at a pending delivery and records it. A failed attempt is
+// retried after an exponential backoff until cfg.WebhookMaxAttempts; a delivery that runs out
+// of attempts counts against its endpoint, which is disabled after cfg.WebhookDisableAfter
+// such deliveries in a row.
+func (s *Svc) Deliver(ctx context.Context, deliveryID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.WebhookTimeout+10*time.Second)
+	defer cancel()
+
+	delivery, err := s.store.Queries().GetWebhookDeliveryById(ctx, deliveryID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// the endpoint was deleted along with its deliveries
+			return nil
+		}
+		return err
+	}
+	if delivery.Status != db.WebhookDeliveryStatusEnumPending {
+		return nil
+	}
+
+	endpoint, err := s.store.Queries().GetWebhookEndpointById(ctx, delivery.EndpointID)
+	if err != nil {
+		return err
+	}
+	if endpoint.Status != db.WebhookEndpointStatusEnumActive {
+		return s.store.Queries().FailWebhookDelivery(ctx, db.FailWebhookDeliveryParams{
+			ID:        delivery.ID,
+			LastError: pgtype.Text{String: "endpoint disabled", Valid: true},
+		})
+	}
+
+	result := s.post(ctx, endpoint, delivery)
+
+	retryIn, err := utils.Retry(3, 100, func() (time.Duration, error) {
+		return s.recordAttempt(ctx, delivery.ID, result)
+	})
+	if err != nil {
+		return err
+	}
+
+	if retryIn > 0 {
+		return s.enqueue(ctx, delivery.ID, delivery.Attempts+1, retryIn)
+	}
+	return nil
+}
+
+// recordAttempt writes the attempt to the delivery log and moves the delivery on, returning
+// how long to wait before the next attempt, or zero if there is none.
+func (s *Svc) recordAttempt(ctx context.Context, deliveryID uuid.UUID, result attemptResult) (time.Duration, error) {
+	tx, err := s.store.Begin(ctx)
+	if err != nil {
+		return 0, &utils.RetryableError{Err: err}
+	}
+
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			slog.Error("failed to rollback webhook delivery tx", "error", rbErr)
+		}
+	}()
+
+	qtx := s.store.WithTx(tx)
+
+	delivery, err := qtx.GetWebhookDeliveryForUpdate(ctx, deliveryID)
+	if err != nil {
+		return 0, &utils.RetryableError{Err: err}
+	}
+	if delivery.Status != db.WebhookDeliveryStatusEnumPending {
+		return 0, nil
+	}
+
+	attempt := delivery.Attempts + 1
+	code := pgtype.Int4{Int32: int32(result.code), Valid: result.code != 0}
+	log := db.CreateWebhookDeliveryAttemptParams{
+		DeliveryID:   delivery.ID,
+		Attempt:      attempt,
+		ResponseCode: code,
+		ResponseBody: pgtype.Text{String: result.body, Valid: result.body != ""},
+		DurationMs:   int32(result.duration.Milliseconds()),
+	}
+	if result.err != nil {
+		log.Error = pgtype.Text{String: result.err.Error(), Valid: true}
+	}
+	if _, err := qtx.CreateWebhookDeliveryAttempt(ctx, log); err != nil {
+		return 0, &utils.RetryableError{Err: err}
+	}
+
+	update := db.UpdateWebhookDeliveryResultParams{ID: delivery.ID, ResponseCode: code}
+	var retryIn time.Duration
+	switch {
+	case result.ok():
+		update.Status = db.WebhookDeliveryStatusEnumSucceeded
+		update.DeliveredAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+		if err := qtx.RecordWebhookEndpointSuccess(ctx, delivery.EndpointID); err != nil {
+			return 0, &utils.RetryableError{Err: err}
+		}
+	case int(attempt) < s.cfg.WebhookMaxAttempts:
+		retryIn = utils.Backoff(s.cfg.WebhookRetryBase, int(attempt-1))
+		update.Status = db.WebhookDeliveryStatusEnumPending
+		update.LastError = pgtype.Text{String: result.failure(), Valid: true}
+		update.NextAttemptAt = pgtype.Timestamptz{Time: time.Now().Add(retryIn), Valid: true}
+	default:
+		update.Status = db.WebhookDeliveryStatusEnumFailed
+		update.LastError = pgtype.Text{String: result.failure(), Valid: true}
+		if err := s.recordEndpointFailure(ctx, qtx, delivery.EndpointID); err != nil {
+			return 0, err
+		}
+	}
+
+	if _, err := qtx.UpdateWebhookDeliveryResult(ctx, update); err != nil {
+		return 0, &utils.RetryableError{Err: err}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, &utils.RetryableError{Err: err}
+	}
+	return retryIn, nil
+}
+
+func (s *Svc) recordEndpointFailure(ctx context.Context, qtx db.Querier, endpointID uuid.UUID) error {
+	endpoint, err := qtx.RecordWebhookEndpointFailure(ctx, endpointID)
+	if err != nil {
+		return &utils.RetryableError{Err: err}
+	}
+	if endpoint.Status != db.WebhookEndpointStatusEnumActive || int(endpoint.ConsecutiveFailures) < s.cfg.WebhookDisableAfter {
+		return nil
+	}
+
+	slog.Warn("disabling failing webhook endpoint", "endpoint_id", endpoint.ID, "consecutive_failures", endpoint.ConsecutiveFailures)
+	if _, err := qtx.DisableWebhookEndpoint(ctx, db.DisableWebhookEndpointParams{
+		ID:             endpoint.ID,
+		DisabledReason: pgtype.Text{String: fmt.Sprintf("%d deliveries in a row failed", endpoint.ConsecutiveFailures), Valid: true},
+	}); err != nil {
+		return &utils.RetryableError{Err: err}
+	}
+	return nil
+}
+
+// post sends the delivery's payload to the endpoint, signed under its secret and, while a
+// rotation overlaps, its previous secret.
+func (s *Svc) post(ctx context.Context, endpoint db.WebhookEndpoint, delivery db.WebhookDelivery) attemptResult {
+	now := time.Now()
+	secrets := []string{endpoint.Secret}
+	if endpoint.PreviousSecret.Valid && endpoint.PreviousSecretExpiresAt.Time.After(now) {
+		secrets = append(secrets, endpoint.PreviousSecret.String)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return attemptResult{err: err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Paycore-Webhooks/1.0")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderEventID, delivery.EventID.String())
+	req.Header.Set(HeaderDelivery, delivery.ID.String())
+	req.Header.Set(HeaderTimestamp, fmt.Sprint(now.Unix()))
+	req.Header.Set(HeaderSignature, signatureHeader(now.Unix(), delivery.Payload, secrets...))
+
+	resp, err := s.client.Do(req)
+	duration := time.Since(now)
+	if err != nil {
+		return attemptResult{err: err, duration: duration}
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	return attemptResult{code: resp.StatusCode, body: string(body), duration: duration}
+}
+
+// Redeliver sends a delivery's event to its endpoint again as a new delivery with its own
+// attempts, whatever became of the original.
+func (s *Svc) Redeliver(ctx context.Context, userID uuid.UUID, endpointID uuid.UUID, deliveryID uuid.UUID) (DeliveryResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 8*time.Second)
+	defer cancel()
+
+	original, err := s.ownDelivery(ctx, userID, endpointID, deliveryID)
+	if err != nil {
+		return DeliveryResponse{}, err
+	}
+
+	endpoint, err := s.store.Queries().GetWebhookEndpointById(ctx, original.EndpointID)
+	if err != nil {
+		return DeliveryResponse{}, err
+	}
+	if endpoint.Status != db.WebhookEndpointStatusEnumActive {
+		return DeliveryResponse{}, ErrEndpointDisabled
+	}
+
+	delivery, err := s.store.Queries().CreateWebhookDelivery(ctx, db.CreateWebhookDeliveryParams{
+		EndpointID:   original.EndpointID,
+		EventID:      original.EventID,
+		EventType:    original.EventType,
+		Payload:      original.Payload,
+		RedeliveryOf: utils.ToPgUUID(original.ID),
+	})
+	if err != nil {
+		return DeliveryResponse{}, err
+	}
+
+	if err := s.enqueue(ctx, delivery.ID, 0, 0); err != nil {
+		return DeliveryResponse{}, err
+	}
+	return toDeliveryResponse(delivery, nil), nil
+}
+
+// enqueue queues attempt number attempt+1 of a delivery. The task id is unique per attempt, so
+// a duplicate dispatch does not queue the same attempt twice.
+func (s *Svc) enqueue(ctx context.Context, deliveryID uuid.UUID, attempt int32, delay time.Duration) error {
+	task, err := tasks.NewDeliverWebhookTask(tasks.DeliverWebhookPayload{DeliveryID: deliveryID})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.taskClient.EnqueueContext(ctx, task,
+		asynq.TaskID(fmt.Sprintf("webhook:%s:%d", deliveryID, attempt)),
+		asynq.Queue(tasks.QueueDefault),
+		asynq.ProcessIn(delay),
+		asynq.MaxRetry(5),
+	)
+	if errors.Is(err, asynq.ErrTaskIDConflict) {
+		return nil
+	}
+	return err
+}
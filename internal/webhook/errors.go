@@ -0,0 +1,14 @@
+package webhook
+
+import "errors"
+
+var (
+	ErrEndpointNotFound = errors.New("webhook endpoint not found")
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+	ErrInvalidURL       = errors.New("webhook url must be an absolute https url")
+	ErrPrivateURL       = errors.New("webhook url must resolve to public internet addresses")
+	ErrInvalidEventType = errors.New("event types must be event names such as transfer.completed, or * for every event")
+	ErrEndpointDisabled = errors.New("webhook endpoint is disabled, enable it before redelivering")
+	ErrInvalidSignature = errors.New("webhook signature does not match")
+	ErrStaleSignature   = errors.New("webhook timestamp is missing or outside the tolerance")
+)
@@ -0,0 +1,299 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type Handler struct {
+	svc Service
+}
+
+func NewHandler(svc Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+func (h *Handler) HandleCreateEndpoint(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	var req CreateEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	endpoint, err := h.svc.CreateEndpoint(c.Request.Context(), userID, req)
+	if err != nil {
+		c.AbortWithStatusJSON(webhookErrorStatus(err), gin.H{
+			"message": "failed to create webhook endpoint",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "webhook endpoint created successfully, store the secret now as it is not shown again",
+		"data":    endpoint,
+	})
+}
+
+func (h *Handler) HandleListEndpoints(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	endpoints, err := h.svc.ListEndpoints(c.Request.Context(), userID)
+	if err != nil {
+		c.AbortWithStatusJSON(webhookErrorStatus(err), gin.H{
+			"message": "failed to fetch webhook endpoints",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "webhook endpoints fetched successfully",
+		"data":    endpoints,
+	})
+}
+
+func (h *Handler) HandleGetEndpoint(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	endpointID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid webhook endpoint id"})
+		return
+	}
+
+	endpoint, err := h.svc.GetEndpoint(c.Request.Context(), userID, endpointID)
+	if err != nil {
+		c.AbortWithStatusJSON(webhookErrorStatus(err), gin.H{
+			"message": "failed to fetch webhook endpoint",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "webhook endpoint fetched successfully",
+		"data":    endpoint,
+	})
+}
+
+func (h *Handler) HandleUpdateEndpoint(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	endpointID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid webhook endpoint id"})
+		return
+	}
+
+	var req UpdateEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	endpoint, err := h.svc.UpdateEndpoint(c.Request.Context(), userID, endpointID, req)
+	if err != nil {
+		c.AbortWithStatusJSON(webhookErrorStatus(err), gin.H{
+			"message": "failed to update webhook endpoint",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "webhook endpoint updated successfully",
+		"data":    endpoint,
+	})
+}
+
+func (h *Handler) HandleDeleteEndpoint(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	endpointID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid webhook endpoint id"})
+		return
+	}
+
+	if err := h.svc.DeleteEndpoint(c.Request.Context(), userID, endpointID); err != nil {
+		c.AbortWithStatusJSON(webhookErrorStatus(err), gin.H{
+			"message": "failed to delete webhook endpoint",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "webhook endpoint deleted successfully",
+	})
+}
+
+func (h *Handler) HandleRotateSecret(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	endpointID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid webhook endpoint id"})
+		return
+	}
+
+	endpoint, err := h.svc.RotateSecret(c.Request.Context(), userID, endpointID)
+	if err != nil {
+		c.AbortWithStatusJSON(webhookErrorStatus(err), gin.H{
+			"message": "failed to rotate webhook secret",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "webhook secret rotated successfully, the previous secret keeps signing until previous_secret_until",
+		"data":    endpoint,
+	})
+}
+
+func (h *Handler) HandleListDeliveries(c *gin.Context) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return
+	}
+
+	endpointID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid webhook endpoint id"})
+		return
+	}
+
+	var query PaginationQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid query parameters"})
+		return
+	}
+
+	deliveries, err := h.svc.ListDeliveries(c.Request.Context(), userID, endpointID, query.PageSize, (query.Page-1)*query.PageSize)
+	if err != nil {
+		c.AbortWithStatusJSON(webhookErrorStatus(err), gin.H{
+			"message": "failed to fetch webhook deliveries",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "webhook deliveries fetched successfully",
+		"data":    deliveries,
+	})
+}
+
+func (h *Handler) HandleGetDelivery(c *gin.Context) {
+	userID, endpointID, deliveryID, ok := deliveryParams(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.svc.GetDelivery(c.Request.Context(), userID, endpointID, deliveryID)
+	if err != nil {
+		c.AbortWithStatusJSON(webhookErrorStatus(err), gin.H{
+			"message": "failed to fetch webhook delivery",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "webhook delivery fetched successfully",
+		"data":    delivery,
+	})
+}
+
+func (h *Handler) HandleRedeliver(c *gin.Context) {
+	userID, endpointID, deliveryID, ok := deliveryParams(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.svc.Redeliver(c.Request.Context(), userID, endpointID, deliveryID)
+	if err != nil {
+		c.AbortWithStatusJSON(webhookErrorStatus(err), gin.H{
+			"message": "failed to redeliver webhook",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "webhook redelivery queued successfully",
+		"data":    delivery,
+	})
+}
+
+func deliveryParams(c *gin.Context) (uuid.UUID, uuid.UUID, uuid.UUID, bool) {
+	userID, ok := authUserID(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+
+	endpointID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid webhook endpoint id"})
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+
+	deliveryID, err := uuid.Parse(c.Param("delivery_id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid webhook delivery id"})
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+	return userID, endpointID, deliveryID, true
+}
+
+func authUserID(c *gin.Context) (uuid.UUID, bool) {
+	userIdVal, exists := c.Get("user_id")
+	if !exists {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return uuid.Nil, false
+	}
+	userID, ok := userIdVal.(uuid.UUID)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "invalid user id type"})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+func webhookErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrInvalidURL), errors.Is(err, ErrPrivateURL), errors.Is(err, ErrInvalidEventType):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrEndpointNotFound), errors.Is(err, ErrDeliveryNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrEndpointDisabled):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
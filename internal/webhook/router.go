@@ -0,0 +1,27 @@
+package webhook
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/luponetn/paycore/internal/middleware"
+	"github.com/luponetn/paycore/pkg/utils"
+)
+
+func RegisterRoutes(r *gin.Engine, h *Handler, keys *utils.KeySet, sessions middleware.SessionChecker) {
+	webhookGroup := r.Group("/webhooks")
+
+	//use middlewares
+	webhookGroup.Use(middleware.AuthMiddleware(keys, sessions))
+
+	//implement routes
+	{
+		webhookGroup.POST("", h.HandleCreateEndpoint)
+		webhookGroup.GET("", h.HandleListEndpoints)
+		webhookGroup.GET("/:id", h.HandleGetEndpoint)
+		webhookGroup.PATCH("/:id", h.HandleUpdateEndpoint)
+		webhookGroup.DELETE("/:id", h.HandleDeleteEndpoint)
+		webhookGroup.POST("/:id/rotate-secret", h.HandleRotateSecret)
+		webhookGroup.GET("/:id/deliveries", h.HandleListDeliveries)
+		webhookGroup.GET("/:id/deliveries/:delivery_id", h.HandleGetDelivery)
+		webhookGroup.POST("/:id/deliveries/:delivery_id/redeliver", h.HandleRedeliver)
+	}
+}
@@ -0,0 +1,311 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/luponetn/paycore/internal/config"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/store"
+)
+
+type Service interface {
+	CreateEndpoint(ctx context.Context, userID uuid.UUID, req CreateEndpointRequest) (EndpointResponse, error)
+	ListEndpoints(ctx context.Context, userID uuid.UUID) ([]EndpointResponse, error)
+	GetEndpoint(ctx context.Context, userID uuid.UUID, endpointID uuid.UUID) (EndpointResponse, error)
+	UpdateEndpoint(ctx context.Context, userID uuid.UUID, endpointID uuid.UUID, req UpdateEndpointRequest) (EndpointResponse, error)
+	DeleteEndpoint(ctx context.Context, userID uuid.UUID, endpointID uuid.UUID) error
+	RotateSecret(ctx context.Context, userID uuid.UUID, endpointID uuid.UUID) (EndpointResponse, error)
+	ListDeliveries(ctx context.Context, userID uuid.UUID, endpointID uuid.UUID, limit int32, offset int32) ([]DeliveryResponse, error)
+	GetDelivery(ctx context.Context, userID uuid.UUID, endpointID uuid.UUID, deliveryID uuid.UUID) (DeliveryResponse, error)
+	Redeliver(ctx context.Context, userID uuid.UUID, endpointID uuid.UUID, deliveryID uuid.UUID) (DeliveryResponse, error)
+	DispatchEvent(ctx context.Context, event []byte) (int, error)
+	Deliver(ctx context.Context, deliveryID uuid.UUID) error
+}
+
+// TaskEnqueuer is the part of *asynq.Client the service needs.
+type TaskEnqueuer interface {
+	EnqueueContext(ctx context.Context, task *asynq.Task, opts ...asynq.Option) (*asynq.TaskInfo, error)
+}
+
+type Svc struct {
+	store      store.Store
+	taskClient TaskEnqueuer
+	client     *http.Client
+	resolver   hostResolver
+	cfg        *config.Config
+}
+
+// NewService wires the webhook service. taskClient queues delivery attempts; cfg carries the
+// timeout, retry and auto-disable settings and which URLs endpoints may use.
+func NewService(store store.Store, taskClient TaskEnqueuer, cfg *config.Config) Service {
+	return &Svc{
+		store:      store,
+		taskClient: taskClient,
+		client:     newHTTPClient(cfg.WebhookTimeout, cfg.WebhookAllowPrivateNetworks),
+		resolver:   net.DefaultResolver,
+		cfg:        cfg,
+	}
+}
+
+var eventTypePattern = regexp.MustCompile(`^[a-z_]+\.[a-z_]+$`)
+
+// CreateEndpoint registers an endpoint and returns it with its signing secret, which is not
+// shown again until it is rotated.
+func (s *Svc) CreateEndpoint(ctx context.Context, userID uuid.UUID, req CreateEndpointRequest) (EndpointResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 8*time.Second)
+	defer cancel()
+
+	if err := s.validateURL(ctx, req.URL); err != nil {
+		return EndpointResponse{}, err
+	}
+	eventTypes, err := normaliseEventTypes(req.EventTypes)
+	if err != nil {
+		return EndpointResponse{}, err
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		return EndpointResponse{}, err
+	}
+
+	endpoint, err := s.store.Queries().CreateWebhookEndpoint(ctx, db.CreateWebhookEndpointParams{
+		UserID:      userID,
+		Url:         req.URL,
+		Description: pgtype.Text{String: req.Description, Valid: req.Description != ""},
+		EventTypes:  eventTypes,
+		Secret:      secret,
+	})
+	if err != nil {
+		return EndpointResponse{}, err
+	}
+	return toEndpointResponse(endpoint, true), nil
+}
+
+func (s *Svc) ListEndpoints(ctx context.Context, userID uuid.UUID) ([]EndpointResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 8*time.Second)
+	defer cancel()
+
+	endpoints, err := s.store.Queries().GetWebhookEndpointsByUserId(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]EndpointResponse, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		resp = append(resp, toEndpointResponse(endpoint, false))
+	}
+	return resp, nil
+}
+
+func (s *Svc) GetEndpoint(ctx context.Context, userID uuid.UUID, endpointID uuid.UUID) (EndpointResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 8*time.Second)
+	defer cancel()
+
+	endpoint, err := s.ownEndpoint(ctx, userID, endpointID)
+	if err != nil {
+		return EndpointResponse{}, err
+	}
+	return toEndpointResponse(endpoint, false), nil
+}
+
+// UpdateEndpoint changes the fields req sets. Re-enabling an endpoint clears its failure count.
+func (s *Svc) UpdateEndpoint(ctx context.Context, userID uuid.UUID, endpointID uuid.UUID, req UpdateEndpointRequest) (EndpointResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 8*time.Second)
+	defer cancel()
+
+	endpoint, err := s.ownEndpoint(ctx, userID, endpointID)
+	if err != nil {
+		return EndpointResponse{}, err
+	}
+
+	params := db.UpdateWebhookEndpointParams{ID: endpoint.ID}
+	if req.URL != nil {
+		if err := s.validateURL(ctx, *req.URL); err != nil {
+			return EndpointResponse{}, err
+		}
+		params.Url = pgtype.Text{String: *req.URL, Valid: true}
+	}
+	if req.Description != nil {
+		params.Description = pgtype.Text{String: *req.Description, Valid: true}
+	}
+	if req.EventTypes != nil {
+		if params.EventTypes, err = normaliseEventTypes(req.EventTypes); err != nil {
+			return EndpointResponse{}, err
+		}
+	}
+
+	if endpoint, err = s.store.Queries().UpdateWebhookEndpoint(ctx, params); err != nil {
+		return EndpointResponse{}, err
+	}
+
+	if req.Status != nil && db.WebhookEndpointStatusEnum(*req.Status) != endpoint.Status {
+		if db.WebhookEndpointStatusEnum(*req.Status) == db.WebhookEndpointStatusEnumActive {
+			endpoint, err = s.store.Queries().EnableWebhookEndpoint(ctx, endpoint.ID)
+		} else {
+			endpoint, err = s.store.Queries().DisableWebhookEndpoint(ctx, db.DisableWebhookEndpointParams{
+				ID:             endpoint.ID,
+				DisabledReason: pgtype.Text{String: "disabled by owner", Valid: true},
+			})
+		}
+		if err != nil {
+			return EndpointResponse{}, err
+		}
+	}
+	return toEndpointResponse(endpoint, false), nil
+}
+
+// DeleteEndpoint removes the endpoint and its delivery log. Queued attempts find it gone and stop.
+func (s *Svc) DeleteEndpoint(ctx context.Context, userID uuid.UUID, endpointID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, 8*time.Second)
+	defer cancel()
+
+	endpoint, err := s.ownEndpoint(ctx, userID, endpointID)
+	if err != nil {
+		return err
+	}
+	return s.store.Queries().DeleteWebhookEndpoint(ctx, endpoint.ID)
+}
+
+// RotateSecret issues a new signing secret. Deliveries carry a signature under the old secret
+// as well for cfg.WebhookSecretOverlap, giving the receiver time to switch over.
+func (s *Svc) RotateSecret(ctx context.Context, userID uuid.UUID, endpointID uuid.UUID) (EndpointResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 8*time.Second)
+	defer cancel()
+
+	endpoint, err := s.ownEndpoint(ctx, userID, endpointID)
+	if err != nil {
+		return EndpointResponse{}, err
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		return EndpointResponse{}, err
+	}
+
+	endpoint, err = s.store.Queries().RotateWebhookEndpointSecret(ctx, db.RotateWebhookEndpointSecretParams{
+		ID:                      endpoint.ID,
+		Secret:                  secret,
+		PreviousSecretExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(s.cfg.WebhookSecretOverlap), Valid: true},
+	})
+	if err != nil {
+		return EndpointResponse{}, err
+	}
+	return toEndpointResponse(endpoint, true), nil
+}
+
+// ListDeliveries pages through an endpoint's deliveries, newest first.
+func (s *Svc) ListDeliveries(ctx context.Context, userID uuid.UUID, endpointID uuid.UUID, limit int32, offset int32) ([]DeliveryResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 8*time.Second)
+	defer cancel()
+
+	endpoint, err := s.ownEndpoint(ctx, userID, endpointID)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.store.Queries().GetWebhookDeliveriesByEndpointId(ctx, db.GetWebhookDeliveriesByEndpointIdParams{
+		EndpointID: endpoint.ID,
+		Limit:      limit,
+		Offset:     offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]DeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		resp = append(resp, toDeliveryResponse(delivery, nil))
+	}
+	return resp, nil
+}
+
+// GetDelivery returns one delivery with the log of its attempts and the responses they got.
+func (s *Svc) GetDelivery(ctx context.Context, userID uuid.UUID, endpointID uuid.UUID, deliveryID uuid.UUID) (DeliveryResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 8*time.Second)
+	defer cancel()
+
+	delivery, err := s.ownDelivery(ctx, userID, endpointID, deliveryID)
+	if err != nil {
+		return DeliveryResponse{}, err
+	}
+
+	log, err := s.store.Queries().GetWebhookDeliveryAttemptsByDeliveryId(ctx, delivery.ID)
+	if err != nil {
+		return DeliveryResponse{}, err
+	}
+	return toDeliveryResponse(delivery, log), nil
+}
+
+func (s *Svc) ownEndpoint(ctx context.Context, userID uuid.UUID, endpointID uuid.UUID) (db.WebhookEndpoint, error) {
+	endpoint, err := s.store.Queries().GetWebhookEndpointById(ctx, endpointID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.WebhookEndpoint{}, ErrEndpointNotFound
+		}
+		return db.WebhookEndpoint{}, err
+	}
+	// someone else's endpoint reads as missing rather than forbidden
+	if endpoint.UserID != userID {
+		return db.WebhookEndpoint{}, ErrEndpointNotFound
+	}
+	return endpoint, nil
+}
+
+func (s *Svc) ownDelivery(ctx context.Context, userID uuid.UUID, endpointID uuid.UUID, deliveryID uuid.UUID) (db.WebhookDelivery, error) {
+	endpoint, err := s.ownEndpoint(ctx, userID, endpointID)
+	if err != nil {
+		return db.WebhookDelivery{}, err
+	}
+
+	delivery, err := s.store.Queries().GetWebhookDeliveryById(ctx, deliveryID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.WebhookDelivery{}, ErrDeliveryNotFound
+		}
+		return db.WebhookDelivery{}, err
+	}
+	if delivery.EndpointID != endpoint.ID {
+		return db.WebhookDelivery{}, ErrDeliveryNotFound
+	}
+	return delivery, nil
+}
+
+// validateURL checks that raw is a URL deliveries may be posted to: https, unless
+// cfg.WebhookAllowHTTP, on a host that resolves only to public addresses, unless
+// cfg.WebhookAllowPrivateNetworks.
+func (s *Svc) validateURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Hostname() == "" || u.User != nil {
+		return ErrInvalidURL
+	}
+	if u.Scheme != "https" && !(u.Scheme == "http" && s.cfg.WebhookAllowHTTP) {
+		return ErrInvalidURL
+	}
+	return s.checkHost(ctx, u.Hostname())
+}
+
+// normaliseEventTypes checks the filter and drops duplicates, keeping the caller's order.
+func normaliseEventTypes(eventTypes []string) ([]string, error) {
+	seen := make(map[string]bool, len(eventTypes))
+	result := make([]string, 0, len(eventTypes))
+	for _, t := range eventTypes {
+		if t != "*" && !eventTypePattern.MatchString(t) {
+			return nil, ErrInvalidEventType
+		}
+		if !seen[t] {
+			seen[t] = true
+			result = append(result, t)
+		}
+	}
+	return result, nil
+}
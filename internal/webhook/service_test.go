@@ -0,0 +1,361 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/luponetn/paycore/internal/config"
+	"github.com/luponetn/paycore/internal/db"
+	"github.com/luponetn/paycore/internal/outbox"
+	"github.com/luponetn/paycore/internal/store"
+	"github.com/luponetn/paycore/internal/tasks"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEnqueuer records the delivery ids of queued tasks instead of sending them to Redis.
+type fakeEnqueuer struct {
+	mu         sync.Mutex
+	deliveries []uuid.UUID
+}
+
+func (e *fakeEnqueuer) EnqueueContext(ctx context.Context, task *asynq.Task, opts ...asynq.Option) (*asynq.TaskInfo, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var payload tasks.DeliverWebhookPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return nil, err
+	}
+	e.deliveries = append(e.deliveries, payload.DeliveryID)
+	return &asynq.TaskInfo{}, nil
+}
+
+func (e *fakeEnqueuer) last() uuid.UUID {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.deliveries[len(e.deliveries)-1]
+}
+
+func newTestService(f *store.FakeStore, enqueuer *fakeEnqueuer) Service {
+	return NewService(f, enqueuer, &config.Config{
+		WebhookTimeout:       2 * time.Second,
+		WebhookMaxAttempts:   2,
+		WebhookRetryBase:     time.Second,
+		WebhookDisableAfter:  1,
+		WebhookSecretOverlap: time.Hour,
+		WebhookAllowHTTP:     true,
+		// test receivers listen on loopback
+		WebhookAllowPrivateNetworks: true,
+	})
+}
+
+// fakeResolver answers lookups from a fixed table instead of DNS.
+type fakeResolver map[string][]netip.Addr
+
+func (r fakeResolver) LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error) {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return []netip.Addr{addr}, nil
+	}
+	addrs, ok := r[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return addrs, nil
+}
+
+// receiver is a local webhook endpoint answering with status and keeping what it was sent.
+type receiver struct {
+	*httptest.Server
+	status   atomic.Int32
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newReceiver(t *testing.T, status int) *receiver {
+	r := &receiver{}
+	r.status.Store(int32(status))
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		r.requests = append(r.requests, req)
+		r.bodies = append(r.bodies, body)
+		r.mu.Unlock()
+		w.WriteHeader(int(r.status.Load()))
+		_, _ = w.Write([]byte(`{"received":true}`))
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func event(t *testing.T, eventType string, data any) []byte {
+	t.Helper()
+	raw, err := json.Marshal(data)
+	require.NoError(t, err)
+	body, err := json.Marshal(outbox.Event{
+		ID:            uuid.New(),
+		Type:          eventType,
+		AggregateType: outbox.AggregateWallet,
+		AggregateID:   uuid.New(),
+		Sequence:      1,
+		OccurredAt:    time.Now(),
+		Data:          raw,
+	})
+	require.NoError(t, err)
+	return body
+}
+
+func TestDeliver_SignsPayloadAndLogsResponse(t *testing.T) {
+	f := store.NewFakeStore()
+	enqueuer := &fakeEnqueuer{}
+	svc := newTestService(f, enqueuer)
+	ctx := context.Background()
+	userID := uuid.New()
+	rcv := newReceiver(t, http.StatusOK)
+
+	endpoint, err := svc.CreateEndpoint(ctx, userID, CreateEndpointRequest{URL: rcv.URL, EventTypes: []string{outbox.EventWalletCreated}})
+	require.NoError(t, err)
+	require.NotEmpty(t, endpoint.Secret)
+
+	payload := event(t, outbox.EventWalletCreated, outbox.WalletCreatedPayload{WalletID: uuid.New(), UserID: userID})
+	queued, err := svc.DispatchEvent(ctx, payload)
+	require.NoError(t, err)
+	require.Equal(t, 1, queued)
+
+	// Other users' events and unsubscribed types queue nothing.
+	queued, err = svc.DispatchEvent(ctx, event(t, outbox.EventWalletCreated, outbox.WalletCreatedPayload{UserID: uuid.New()}))
+	require.NoError(t, err)
+	require.Zero(t, queued)
+	queued, err = svc.DispatchEvent(ctx, event(t, outbox.EventUserRegistered, outbox.UserRegisteredPayload{UserID: userID}))
+	require.NoError(t, err)
+	require.Zero(t, queued)
+
+	deliveryID := enqueuer.last()
+	require.NoError(t, svc.Deliver(ctx, deliveryID))
+
+	require.Len(t, rcv.requests, 1)
+	req := rcv.requests[0]
+	require.Equal(t, payload, rcv.bodies[0])
+	require.Equal(t, outbox.EventWalletCreated, req.Header.Get(HeaderEvent))
+	require.Equal(t, deliveryID.String(), req.Header.Get(HeaderDelivery))
+	require.NoError(t, Verify(endpoint.Secret, req.Header, rcv.bodies[0], time.Minute))
+	require.ErrorIs(t, Verify("whsec_wrong", req.Header, rcv.bodies[0], time.Minute), ErrInvalidSignature)
+
+	delivery, err := svc.GetDelivery(ctx, userID, endpoint.ID, deliveryID)
+	require.NoError(t, err)
+	require.Equal(t, db.WebhookDeliveryStatusEnumSucceeded, delivery.Status)
+	require.EqualValues(t, 200, *delivery.ResponseCode)
+	require.Len(t, delivery.Log, 1)
+	require.Equal(t, `{"received":true}`, delivery.Log[0].ResponseBody)
+
+	// A delivered event is not sent again if it is relayed or its task runs twice.
+	queued, err = svc.DispatchEvent(ctx, payload)
+	require.NoError(t, err)
+	require.Zero(t, queued)
+	require.NoError(t, svc.Deliver(ctx, deliveryID))
+	require.Len(t, rcv.requests, 1)
+
+	deliveries, err := svc.ListDeliveries(ctx, userID, endpoint.ID, 20, 0)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+
+	_, err = svc.GetDelivery(ctx, uuid.New(), endpoint.ID, deliveryID)
+	require.ErrorIs(t, err, ErrEndpointNotFound)
+}
+
+func TestDeliver_RetriesThenDisablesEndpoint(t *testing.T) {
+	f := store.NewFakeStore()
+	enqueuer := &fakeEnqueuer{}
+	svc := newTestService(f, enqueuer)
+	ctx := context.Background()
+	userID := uuid.New()
+	rcv := newReceiver(t, http.StatusInternalServerError)
+
+	walletID := uuid.New()
+	f.AddFakeWallet(db.GetWalletsAndLockByWalletIdsRow{ID: walletID, UserID: pgtype.UUID{Bytes: userID, Valid: true}, Currency: "NGN"})
+
+	endpoint, err := svc.CreateEndpoint(ctx, userID, CreateEndpointRequest{URL: rcv.URL, EventTypes: []string{"*"}})
+	require.NoError(t, err)
+
+	// The receiving wallet's owner is found through the transaction's legs.
+	queued, err := svc.DispatchEvent(ctx, event(t, "transfer.completed", outbox.TransactionCompletedPayload{
+		TransactionID: uuid.New(),
+		Legs:          []outbox.TransactionLeg{{WalletID: walletID, EntryType: db.LedgerEntryTypeCredit}},
+	}))
+	require.NoError(t, err)
+	require.Equal(t, 1, queued)
+	deliveryID := enqueuer.last()
+
+	require.NoError(t, svc.Deliver(ctx, deliveryID))
+	delivery, err := svc.GetDelivery(ctx, userID, endpoint.ID, deliveryID)
+	require.NoError(t, err)
+	require.Equal(t, db.WebhookDeliveryStatusEnumPending, delivery.Status)
+	require.NotNil(t, delivery.NextAttemptAt)
+	require.Len(t, enqueuer.deliveries, 2, "the retry is queued")
+
+	require.NoError(t, svc.Deliver(ctx, deliveryID))
+	delivery, err = svc.GetDelivery(ctx, userID, endpoint.ID, deliveryID)
+	require.NoError(t, err)
+	require.Equal(t, db.WebhookDeliveryStatusEnumFailed, delivery.Status)
+	require.Equal(t, "receiver answered 500", delivery.LastError)
+	require.Len(t, delivery.Log, 2)
+
+	got, err := svc.GetEndpoint(ctx, userID, endpoint.ID)
+	require.NoError(t, err)
+	require.Equal(t, db.WebhookEndpointStatusEnumDisabled, got.Status)
+
+	_, err = svc.Redeliver(ctx, userID, endpoint.ID, deliveryID)
+	require.ErrorIs(t, err, ErrEndpointDisabled)
+
+	active := "active"
+	got, err = svc.UpdateEndpoint(ctx, userID, endpoint.ID, UpdateEndpointRequest{Status: &active})
+	require.NoError(t, err)
+	require.Zero(t, got.ConsecutiveFailures)
+
+	rcv.status.Store(http.StatusNoContent)
+	redelivery, err := svc.Redeliver(ctx, userID, endpoint.ID, deliveryID)
+	require.NoError(t, err)
+	require.Equal(t, deliveryID, *redelivery.RedeliveryOf)
+	require.NoError(t, svc.Deliver(ctx, redelivery.ID))
+
+	redelivered, err := svc.GetDelivery(ctx, userID, endpoint.ID, redelivery.ID)
+	require.NoError(t, err)
+	require.Equal(t, db.WebhookDeliveryStatusEnumSucceeded, redelivered.Status)
+	require.Equal(t, rcv.requests[0].Header.Get(HeaderEventID), rcv.requests[2].Header.Get(HeaderEventID))
+}
+
+func TestRotateSecret_SignsWithBothSecretsDuringOverlap(t *testing.T) {
+	f := store.NewFakeStore()
+	enqueuer := &fakeEnqueuer{}
+	svc := newTestService(f, enqueuer)
+	ctx := context.Background()
+	userID := uuid.New()
+	rcv := newReceiver(t, http.StatusOK)
+
+	endpoint, err := svc.CreateEndpoint(ctx, userID, CreateEndpointRequest{URL: rcv.URL, EventTypes: []string{outbox.EventUserRegistered}})
+	require.NoError(t, err)
+
+	rotated, err := svc.RotateSecret(ctx, userID, endpoint.ID)
+	require.NoError(t, err)
+	require.NotEqual(t, endpoint.Secret, rotated.Secret)
+	require.NotNil(t, rotated.PreviousSecretUntil)
+
+	_, err = svc.DispatchEvent(ctx, event(t, outbox.EventUserRegistered, outbox.UserRegisteredPayload{UserID: userID}))
+	require.NoError(t, err)
+	require.NoError(t, svc.Deliver(ctx, enqueuer.last()))
+
+	require.NoError(t, Verify(rotated.Secret, rcv.requests[0].Header, rcv.bodies[0], time.Minute))
+	require.NoError(t, Verify(endpoint.Secret, rcv.requests[0].Header, rcv.bodies[0], time.Minute))
+
+	listed, err := svc.ListEndpoints(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	require.Empty(t, listed[0].Secret)
+}
+
+func TestCreateEndpoint_ValidatesURLAndEventTypes(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := NewService(f, &fakeEnqueuer{}, &config.Config{WebhookTimeout: time.Second}).(*Svc)
+	svc.resolver = fakeResolver{"example.com": {netip.MustParseAddr("203.0.113.10")}}
+	ctx := context.Background()
+
+	_, err := svc.CreateEndpoint(ctx, uuid.New(), CreateEndpointRequest{URL: "http://example.com/hooks", EventTypes: []string{"*"}})
+	require.ErrorIs(t, err, ErrInvalidURL)
+
+	_, err = svc.CreateEndpoint(ctx, uuid.New(), CreateEndpointRequest{URL: "https://example.com/hooks", EventTypes: []string{"Transfer Completed"}})
+	require.ErrorIs(t, err, ErrInvalidEventType)
+
+	endpoint, err := svc.CreateEndpoint(ctx, uuid.New(), CreateEndpointRequest{
+		URL:        "https://example.com/hooks",
+		EventTypes: []string{"transfer.completed", "transfer.completed", "wallet.created"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"transfer.completed", "wallet.created"}, endpoint.EventTypes)
+}
+
+func TestCreateEndpoint_RejectsPrivateAddresses(t *testing.T) {
+	f := store.NewFakeStore()
+	svc := NewService(f, &fakeEnqueuer{}, &config.Config{WebhookTimeout: time.Second}).(*Svc)
+	svc.resolver = fakeResolver{
+		"hooks.example.com":    {netip.MustParseAddr("203.0.113.10")},
+		"internal.example.com": {netip.MustParseAddr("10.0.0.7")},
+		// one private answer is enough, the dialer could pick it
+		"mixed.example.com": {netip.MustParseAddr("203.0.113.10"), netip.MustParseAddr("192.168.1.1")},
+	}
+	ctx := context.Background()
+	userID := uuid.New()
+
+	for _, raw := range []string{
+		"https://127.0.0.1/hooks",
+		"https://[::1]/hooks",
+		"https://169.254.169.254/latest/meta-data",
+		"https://[::ffff:10.0.0.1]/hooks",
+		"https://0.0.0.0/hooks",
+		"https://100.64.0.1/hooks",
+		"https://[fd00::1]/hooks",
+		"https://internal.example.com/hooks",
+		"https://mixed.example.com/hooks",
+		"https://nowhere.example.com/hooks",
+	} {
+		_, err := svc.CreateEndpoint(ctx, userID, CreateEndpointRequest{URL: raw, EventTypes: []string{"*"}})
+		require.ErrorIs(t, err, ErrPrivateURL, raw)
+	}
+
+	endpoint, err := svc.CreateEndpoint(ctx, userID, CreateEndpointRequest{URL: "https://hooks.example.com/hooks", EventTypes: []string{"*"}})
+	require.NoError(t, err)
+
+	internal := "https://internal.example.com/hooks"
+	_, err = svc.UpdateEndpoint(ctx, userID, endpoint.ID, UpdateEndpointRequest{URL: &internal})
+	require.ErrorIs(t, err, ErrPrivateURL)
+
+	// Development setups can opt in.
+	svc.cfg.WebhookAllowPrivateNetworks = true
+	_, err = svc.CreateEndpoint(ctx, userID, CreateEndpointRequest{URL: "https://127.0.0.1/hooks", EventTypes: []string{"*"}})
+	require.NoError(t, err)
+}
+
+func TestDeliver_RefusesPrivateAddressAtDialTime(t *testing.T) {
+	f := store.NewFakeStore()
+	enqueuer := &fakeEnqueuer{}
+	svc := newTestService(f, enqueuer)
+	ctx := context.Background()
+	userID := uuid.New()
+	rcv := newReceiver(t, http.StatusOK)
+
+	// Registered while private networks were allowed, or a name that has since been
+	// re-pointed; either way the delivery must not reach it.
+	_, err := svc.CreateEndpoint(ctx, userID, CreateEndpointRequest{URL: rcv.URL, EventTypes: []string{"*"}})
+	require.NoError(t, err)
+
+	strict := NewService(f, enqueuer, &config.Config{
+		WebhookTimeout:      2 * time.Second,
+		WebhookMaxAttempts:  1,
+		WebhookRetryBase:    time.Second,
+		WebhookDisableAfter: 5,
+		WebhookAllowHTTP:    true,
+	})
+	_, err = strict.DispatchEvent(ctx, event(t, outbox.EventUserRegistered, outbox.UserRegisteredPayload{UserID: userID}))
+	require.NoError(t, err)
+	require.NoError(t, strict.Deliver(ctx, enqueuer.last()))
+
+	rcv.mu.Lock()
+	require.Empty(t, rcv.requests)
+	rcv.mu.Unlock()
+
+	delivery, err := f.GetWebhookDeliveryById(ctx, enqueuer.last())
+	require.NoError(t, err)
+	require.Equal(t, db.WebhookDeliveryStatusEnumFailed, delivery.Status)
+	require.Contains(t, delivery.LastError.String, "is not public")
+}
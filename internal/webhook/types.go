@@ -0,0 +1,138 @@
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/luponetn/paycore/internal/db"
+)
+
+type CreateEndpointRequest struct {
+	URL         string   `json:"url" binding:"required,url"`
+	Description string   `json:"description" binding:"max=255"`
+	EventTypes  []string `json:"event_types" binding:"required,min=1,max=20"`
+}
+
+// UpdateEndpointRequest changes only the fields it sets. Setting status to active re-enables an
+// endpoint that was disabled, by its owner or after repeated failures.
+type UpdateEndpointRequest struct {
+	URL         *string  `json:"url" binding:"omitempty,url"`
+	Description *string  `json:"description" binding:"omitempty,max=255"`
+	EventTypes  []string `json:"event_types" binding:"omitempty,min=1,max=20"`
+	Status      *string  `json:"status" binding:"omitempty,oneof=active disabled"`
+}
+
+type PaginationQuery struct {
+	Page     int32 `form:"page,default=1" binding:"min=1"`
+	PageSize int32 `form:"page_size,default=20" binding:"min=1,max=100"`
+}
+
+// EndpointResponse carries the signing secret only when it is created or rotated.
+type EndpointResponse struct {
+	ID                  uuid.UUID                    `json:"id"`
+	URL                 string                       `json:"url"`
+	Description         string                       `json:"description,omitempty"`
+	EventTypes          []string                     `json:"event_types"`
+	Status              db.WebhookEndpointStatusEnum `json:"status"`
+	ConsecutiveFailures int32                        `json:"consecutive_failures"`
+	DisabledReason      string                       `json:"disabled_reason,omitempty"`
+	DisabledAt          *time.Time                   `json:"disabled_at,omitempty"`
+	Secret              string                       `json:"secret,omitempty"`
+	PreviousSecretUntil *time.Time                   `json:"previous_secret_until,omitempty"`
+	CreatedAt           time.Time                    `json:"created_at"`
+	UpdatedAt           time.Time                    `json:"updated_at"`
+}
+
+type DeliveryResponse struct {
+	ID            uuid.UUID                    `json:"id"`
+	EndpointID    uuid.UUID                    `json:"endpoint_id"`
+	EventID       uuid.UUID                    `json:"event_id"`
+	EventType     string                       `json:"event_type"`
+	Payload       json.RawMessage              `json:"payload"`
+	RedeliveryOf  *uuid.UUID                   `json:"redelivery_of,omitempty"`
+	Status        db.WebhookDeliveryStatusEnum `json:"status"`
+	Attempts      int32                        `json:"attempts"`
+	ResponseCode  *int32                       `json:"response_code,omitempty"`
+	LastError     string                       `json:"last_error,omitempty"`
+	NextAttemptAt *time.Time                   `json:"next_attempt_at,omitempty"`
+	DeliveredAt   *time.Time                   `json:"delivered_at,omitempty"`
+	CreatedAt     time.Time                    `json:"created_at"`
+	Log           []AttemptResponse            `json:"log,omitempty"`
+}
+
+type AttemptResponse struct {
+	Attempt      int32     `json:"attempt"`
+	ResponseCode *int32    `json:"response_code,omitempty"`
+	ResponseBody string    `json:"response_body,omitempty"`
+	Error        string    `json:"error,omitempty"`
+	DurationMs   int32     `json:"duration_ms"`
+	AttemptedAt  time.Time `json:"attempted_at"`
+}
+
+func toEndpointResponse(e db.WebhookEndpoint, withSecret bool) EndpointResponse {
+	resp := EndpointResponse{
+		ID:                  e.ID,
+		URL:                 e.Url,
+		Description:         e.Description.String,
+		EventTypes:          e.EventTypes,
+		Status:              e.Status,
+		ConsecutiveFailures: e.ConsecutiveFailures,
+		DisabledReason:      e.DisabledReason.String,
+		DisabledAt:          optionalTime(e.DisabledAt.Time, e.DisabledAt.Valid),
+		CreatedAt:           e.CreatedAt.Time,
+		UpdatedAt:           e.UpdatedAt.Time,
+	}
+	if withSecret {
+		resp.Secret = e.Secret
+		if e.PreviousSecret.Valid {
+			resp.PreviousSecretUntil = optionalTime(e.PreviousSecretExpiresAt.Time, e.PreviousSecretExpiresAt.Valid)
+		}
+	}
+	return resp
+}
+
+func toDeliveryResponse(d db.WebhookDelivery, log []db.WebhookDeliveryAttempt) DeliveryResponse {
+	resp := DeliveryResponse{
+		ID:            d.ID,
+		EndpointID:    d.EndpointID,
+		EventID:       d.EventID,
+		EventType:     d.EventType,
+		Payload:       d.Payload,
+		Status:        d.Status,
+		Attempts:      d.Attempts,
+		LastError:     d.LastError.String,
+		NextAttemptAt: optionalTime(d.NextAttemptAt.Time, d.NextAttemptAt.Valid),
+		DeliveredAt:   optionalTime(d.DeliveredAt.Time, d.DeliveredAt.Valid),
+		CreatedAt:     d.CreatedAt.Time,
+	}
+	if d.RedeliveryOf.Valid {
+		id := uuid.UUID(d.RedeliveryOf.Bytes)
+		resp.RedeliveryOf = &id
+	}
+	if d.ResponseCode.Valid {
+		resp.ResponseCode = &d.ResponseCode.Int32
+	}
+	for _, a := range log {
+		attempt := AttemptResponse{
+			Attempt:      a.Attempt,
+			ResponseBody: a.ResponseBody.String,
+			Error:        a.Error.String,
+			DurationMs:   a.DurationMs,
+			AttemptedAt:  a.CreatedAt.Time,
+		}
+		if a.ResponseCode.Valid {
+			code := a.ResponseCode.Int32
+			attempt.ResponseCode = &code
+		}
+		resp.Log = append(resp.Log, attempt)
+	}
+	return resp
+}
+
+func optionalTime(t time.Time, valid bool) *time.Time {
+	if !valid {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,76 @@
+package money
+
+import "strings"
+
+// Currency is an ISO 4217 currency and the number of decimal places its minor unit allows.
+type Currency struct {
+	Code       string `json:"code"`
+	MinorUnits int32  `json:"minor_units"`
+}
+
+// currencies are the ISO 4217 codes the platform knows about; SUPPORTED_CURRENCIES picks the
+// ones wallets can actually be opened in.
+var currencies = map[string]Currency{
+	// zero-decimal currencies
+	"JPY": {"JPY", 0},
+	"KRW": {"KRW", 0},
+	"UGX": {"UGX", 0},
+	"RWF": {"RWF", 0},
+	"XOF": {"XOF", 0},
+	"XAF": {"XAF", 0},
+	"CLP": {"CLP", 0},
+	"VND": {"VND", 0},
+
+	// two-decimal currencies
+	"NGN": {"NGN", 2},
+	"USD": {"USD", 2},
+	"GBP": {"GBP", 2},
+	"EUR": {"EUR", 2},
+	"CAD": {"CAD", 2},
+	"AUD": {"AUD", 2},
+	"CHF": {"CHF", 2},
+	"CNY": {"CNY", 2},
+	"INR": {"INR", 2},
+	"ZAR": {"ZAR", 2},
+	"KES": {"KES", 2},
+	"GHS": {"GHS", 2},
+	"EGP": {"EGP", 2},
+	"MAD": {"MAD", 2},
+	"TZS": {"TZS", 2},
+	"AED": {"AED", 2},
+	"SAR": {"SAR", 2},
+	"BRL": {"BRL", 2},
+	"MXN": {"MXN", 2},
+
+	// three-decimal currencies
+	"BHD": {"BHD", 3},
+	"JOD": {"JOD", 3},
+	"KWD": {"KWD", 3},
+	"OMR": {"OMR", 3},
+	"TND": {"TND", 3},
+}
+
+// LookupCurrency finds a currency by its ISO 4217 code, in any case.
+func LookupCurrency(code string) (Currency, error) {
+	c, ok := currencies[strings.ToUpper(code)]
+	if !ok {
+		return Currency{}, ErrUnknownCurrency
+	}
+	return c, nil
+}
+
+// MinorUnitsOf is the number of decimal places the currency is accounted in. Codes the package
+// does not know are taken to have two, the ISO 4217 default.
+func MinorUnitsOf(code string) int32 {
+	c, err := LookupCurrency(code)
+	if err != nil {
+		return 2
+	}
+	return c.MinorUnits
+}
+
+// IsKnown reports whether code is an ISO 4217 code this package knows the minor units of.
+func IsKnown(code string) bool {
+	_, err := LookupCurrency(code)
+	return err == nil
+}
@@ -0,0 +1,11 @@
+package money
+
+import "errors"
+
+var (
+	ErrUnknownCurrency   = errors.New("unknown currency")
+	ErrInvalidAmount     = errors.New("amount is not a valid number")
+	ErrTooPrecise        = errors.New("amount has more decimal places than the currency allows")
+	ErrCurrencyMismatch  = errors.New("amounts are in different currencies")
+	ErrInvalidAllocation = errors.New("allocation needs at least one non-negative ratio and a positive total")
+)
@@ -0,0 +1,221 @@
+// Package money is an amount of a specific ISO 4217 currency, held at exactly that currency's
+// minor-unit precision. Amounts cross the API as strings and the database as NUMERIC; anything
+// with more decimals than the currency allows is rejected rather than silently rounded.
+package money
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+)
+
+type Money struct {
+	amount   decimal.Decimal
+	currency Currency
+}
+
+// New validates amount against the currency's minor units.
+func New(amount decimal.Decimal, code string) (Money, error) {
+	c, err := LookupCurrency(code)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", err, code)
+	}
+	if !amount.Equal(amount.Truncate(c.MinorUnits)) {
+		return Money{}, fmt.Errorf("%w: %s takes at most %d", ErrTooPrecise, c.Code, c.MinorUnits)
+	}
+	return Money{amount: amount.Round(c.MinorUnits), currency: c}, nil
+}
+
+// Parse reads a decimal string such as "1250.50" as an amount of the currency.
+func Parse(amount string, code string) (Money, error) {
+	d, err := decimal.NewFromString(amount)
+	if err != nil {
+		return Money{}, ErrInvalidAmount
+	}
+	return New(d, code)
+}
+
+// Rounded is amount rounded half away from zero to the currency's minor units.
+func Rounded(amount decimal.Decimal, code string) (Money, error) {
+	c, err := LookupCurrency(code)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", err, code)
+	}
+	return Money{amount: amount.Round(c.MinorUnits), currency: c}, nil
+}
+
+// Truncated is amount cut towards zero to the currency's minor units, for amounts the platform
+// pays out where the sub-unit remainder is kept rather than rounded up.
+func Truncated(amount decimal.Decimal, code string) (Money, error) {
+	c, err := LookupCurrency(code)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", err, code)
+	}
+	return Money{amount: amount.Truncate(c.MinorUnits).Round(c.MinorUnits), currency: c}, nil
+}
+
+// FromNumeric reads a NUMERIC column as an amount of the currency; NULL reads as zero.
+func FromNumeric(n pgtype.Numeric, code string) (Money, error) {
+	if !n.Valid || n.Int == nil {
+		return Zero(code)
+	}
+	return New(decimal.NewFromBigInt(n.Int, n.Exp), code)
+}
+
+// Zero is nothing of the currency with the given code.
+func Zero(code string) (Money, error) {
+	c, err := LookupCurrency(code)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", err, code)
+	}
+	return c.Zero(), nil
+}
+
+// Zero is nothing of the currency.
+func (c Currency) Zero() Money {
+	return Money{amount: decimal.New(0, -c.MinorUnits), currency: c}
+}
+
+func (m Money) Amount() decimal.Decimal { return m.amount }
+func (m Money) Currency() Currency      { return m.currency }
+func (m Money) Code() string            { return m.currency.Code }
+func (m Money) IsZero() bool            { return m.amount.IsZero() }
+func (m Money) IsPositive() bool        { return m.amount.IsPositive() }
+func (m Money) IsNegative() bool        { return m.amount.IsNegative() }
+
+// Neg is the same amount with the opposite sign.
+func (m Money) Neg() Money {
+	return Money{amount: m.amount.Neg(), currency: m.currency}
+}
+
+// Add sums two amounts of the same currency.
+func (m Money) Add(o Money) (Money, error) {
+	if m.currency != o.currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Code(), o.Code())
+	}
+	return Money{amount: m.amount.Add(o.amount), currency: m.currency}, nil
+}
+
+// Sub takes o from m; both must be in the same currency.
+func (m Money) Sub(o Money) (Money, error) {
+	if m.currency != o.currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Code(), o.Code())
+	}
+	return Money{amount: m.amount.Sub(o.amount), currency: m.currency}, nil
+}
+
+// Cmp compares two amounts of the same currency: -1, 0 or 1 as m is less than, equal to or
+// greater than o.
+func (m Money) Cmp(o Money) (int, error) {
+	if m.currency != o.currency {
+		return 0, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Code(), o.Code())
+	}
+	return m.amount.Cmp(o.amount), nil
+}
+
+// Mul scales the amount by factor, rounding half away from zero to the minor unit.
+func (m Money) Mul(factor decimal.Decimal) Money {
+	return Money{amount: m.amount.Mul(factor).Round(m.currency.MinorUnits), currency: m.currency}
+}
+
+// Allocate splits the amount in proportion to ratios without losing or inventing a minor unit:
+// each part gets its share rounded down, and the units left over go one each to the first parts.
+func (m Money) Allocate(ratios ...int64) ([]Money, error) {
+	var total int64
+	for _, r := range ratios {
+		if r < 0 {
+			return nil, ErrInvalidAllocation
+		}
+		total += r
+	}
+	if total <= 0 {
+		return nil, ErrInvalidAllocation
+	}
+
+	// work in whole minor units, on the absolute amount so leftovers always go the same way
+	units := m.amount.Abs().Shift(m.currency.MinorUnits)
+	sum := decimal.NewFromInt(total)
+
+	parts := make([]decimal.Decimal, len(ratios))
+	left := units
+	for i, r := range ratios {
+		parts[i] = units.Mul(decimal.NewFromInt(r)).Div(sum).Floor()
+		left = left.Sub(parts[i])
+	}
+	for i := 0; left.IsPositive(); i = (i + 1) % len(parts) {
+		if ratios[i] == 0 {
+			continue
+		}
+		parts[i] = parts[i].Add(decimal.NewFromInt(1))
+		left = left.Sub(decimal.NewFromInt(1))
+	}
+
+	out := make([]Money, len(parts))
+	for i, p := range parts {
+		amount := p.Shift(-m.currency.MinorUnits)
+		if m.amount.IsNegative() {
+			amount = amount.Neg()
+		}
+		out[i] = Money{amount: amount.Round(m.currency.MinorUnits), currency: m.currency}
+	}
+	return out, nil
+}
+
+// Split divides the amount into n parts as even as the minor unit allows.
+func (m Money) Split(n int) ([]Money, error) {
+	if n <= 0 {
+		return nil, ErrInvalidAllocation
+	}
+	ratios := make([]int64, n)
+	for i := range ratios {
+		ratios[i] = 1
+	}
+	return m.Allocate(ratios...)
+}
+
+// StringFixed is the amount alone with exactly the currency's decimal places, e.g. "1250.50".
+func (m Money) StringFixed() string {
+	return m.amount.StringFixed(m.currency.MinorUnits)
+}
+
+// String is the amount and its currency, e.g. "1250.50 NGN".
+func (m Money) String() string {
+	return m.StringFixed() + " " + m.currency.Code
+}
+
+// Numeric is the amount for a NUMERIC column, at the currency's scale.
+func (m Money) Numeric() pgtype.Numeric {
+	return pgtype.Numeric{Int: m.amount.Coefficient(), Exp: m.amount.Exponent(), Valid: true}
+}
+
+// Value writes the amount to a NUMERIC column. The currency lives in its own column, so there is
+// no Scan counterpart; read amounts back with FromNumeric.
+func (m Money) Value() (driver.Value, error) {
+	return m.StringFixed(), nil
+}
+
+type moneyJSON struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{Amount: m.StringFixed(), Currency: m.currency.Code})
+}
+
+// UnmarshalJSON reads {"amount": "12.50", "currency": "USD"}, applying the same checks as Parse.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var raw moneyJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	parsed, err := Parse(raw.Amount, raw.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
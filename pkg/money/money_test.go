@@ -0,0 +1,95 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_EnforcesMinorUnits(t *testing.T) {
+	m, err := Parse("12.5", "usd")
+	require.NoError(t, err)
+	require.Equal(t, "12.50 USD", m.String())
+	require.Equal(t, int32(-2), m.Numeric().Exp)
+
+	// trailing zeros beyond the minor unit are fine, real digits are not
+	_, err = Parse("12.500", "USD")
+	require.NoError(t, err)
+	_, err = Parse("12.505", "USD")
+	require.ErrorIs(t, err, ErrTooPrecise)
+
+	_, err = Parse("100.5", "JPY")
+	require.ErrorIs(t, err, ErrTooPrecise)
+	m, err = Parse("1.005", "KWD")
+	require.NoError(t, err)
+	require.Equal(t, "1.005", m.StringFixed())
+
+	_, err = Parse("ten", "USD")
+	require.ErrorIs(t, err, ErrInvalidAmount)
+	_, err = Parse("10", "XYZ")
+	require.ErrorIs(t, err, ErrUnknownCurrency)
+}
+
+func TestArithmetic_RefusesMixedCurrencies(t *testing.T) {
+	a, _ := Parse("10.25", "NGN")
+	b, _ := Parse("0.75", "NGN")
+	c, _ := Parse("1", "USD")
+
+	sum, err := a.Add(b)
+	require.NoError(t, err)
+	require.Equal(t, "11.00", sum.StringFixed())
+
+	diff, err := b.Sub(a)
+	require.NoError(t, err)
+	require.True(t, diff.IsNegative())
+
+	_, err = a.Add(c)
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+	_, err = a.Cmp(c)
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	require.Equal(t, "0.15", b.Mul(decimal.RequireFromString("0.2")).StringFixed())
+}
+
+func TestAllocate_KeepsEveryMinorUnit(t *testing.T) {
+	m, _ := Parse("100", "USD")
+	parts, err := m.Split(3)
+	require.NoError(t, err)
+	require.Equal(t, []string{"33.34", "33.33", "33.33"}, fixed(parts))
+
+	m, _ = Parse("-0.05", "USD")
+	parts, err = m.Allocate(70, 30)
+	require.NoError(t, err)
+	require.Equal(t, []string{"-0.04", "-0.01"}, fixed(parts))
+
+	m, _ = Parse("10", "JPY")
+	parts, err = m.Allocate(1, 0, 2)
+	require.NoError(t, err)
+	require.Equal(t, []string{"4", "0", "6"}, fixed(parts))
+
+	_, err = m.Allocate(0, 0)
+	require.ErrorIs(t, err, ErrInvalidAllocation)
+}
+
+func TestJSON_RoundTrips(t *testing.T) {
+	m, _ := Parse("1250.5", "NGN")
+	raw, err := json.Marshal(m)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"amount":"1250.50","currency":"NGN"}`, string(raw))
+
+	var back Money
+	require.NoError(t, json.Unmarshal(raw, &back))
+	require.Equal(t, m, back)
+
+	require.ErrorIs(t, json.Unmarshal([]byte(`{"amount":"1.5","currency":"JPY"}`), &back), ErrTooPrecise)
+}
+
+func fixed(parts []Money) []string {
+	out := make([]string, len(parts))
+	for i, p := range parts {
+		out[i] = p.StringFixed()
+	}
+	return out
+}
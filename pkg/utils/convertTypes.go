@@ -20,3 +20,12 @@ func DecimalToNumeric(d decimal.Decimal) pgtype.Numeric {
 		Valid: true,
 	}
 }
+
+// NumericToDecimal converts a pgtype.Numeric into a decimal.Decimal.
+// NULL or unset numerics are treated as zero.
+func NumericToDecimal(n pgtype.Numeric) decimal.Decimal {
+	if !n.Valid || n.Int == nil {
+		return decimal.Zero
+	}
+	return decimal.NewFromBigInt(n.Int, n.Exp)
+}
@@ -0,0 +1,222 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// KeySet signs tokens with one active key and verifies them against every key it knows,
+// so keys can be rotated without invalidating tokens that are still in flight.
+type KeySet struct {
+	alg        string
+	method     jwt.SigningMethod
+	activeKID  string
+	signingKey crypto.PrivateKey
+	verifyKeys map[string]crypto.PublicKey
+	hmacSecret []byte
+}
+
+// NewHMACKeySet returns an HS256 key set backed by a shared secret.
+func NewHMACKeySet(secret string) *KeySet {
+	return &KeySet{
+		alg:        AlgHS256,
+		method:     jwt.SigningMethodHS256,
+		hmacSecret: []byte(secret),
+	}
+}
+
+// LoadKeySet reads every <kid>.pem file in dir. Private keys (PKCS#8, or PKCS#1 for RSA)
+// can sign and verify; public keys (PKIX) only verify, which is how retired keys are kept
+// around until the tokens they signed have expired. activeKID picks the signing key.
+func LoadKeySet(alg string, dir string, activeKID string) (*KeySet, error) {
+	ks := &KeySet{alg: alg, activeKID: activeKID, verifyKeys: make(map[string]crypto.PublicKey)}
+
+	switch alg {
+	case AlgRS256:
+		ks.method = jwt.SigningMethodRS256
+	case AlgEdDSA:
+		ks.method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", alg)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range paths {
+		kid := strings.TrimSuffix(filepath.Base(path), ".pem")
+
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		private, public, err := parsePEMKey(raw)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", kid, err)
+		}
+
+		if !ks.matchesAlg(public) {
+			return nil, fmt.Errorf("key %s does not match algorithm %s", kid, alg)
+		}
+
+		ks.verifyKeys[kid] = public
+		if kid == activeKID {
+			if private == nil {
+				return nil, fmt.Errorf("active key %s has no private key", kid)
+			}
+			ks.signingKey = private
+		}
+	}
+
+	if ks.signingKey == nil {
+		return nil, fmt.Errorf("active key %q not found in %s", activeKID, dir)
+	}
+
+	return ks, nil
+}
+
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.method, claims)
+	if ks.alg == AlgHS256 {
+		return token.SignedString(ks.hmacSecret)
+	}
+
+	token.Header["kid"] = ks.activeKID
+	return token.SignedString(ks.signingKey)
+}
+
+func (ks *KeySet) Parse(tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenString, claims, ks.keyFunc, jwt.WithValidMethods([]string{ks.method.Alg()}))
+}
+
+func (ks *KeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	if ks.alg == AlgHS256 {
+		return ks.hmacSecret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := ks.verifyKeys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	return key, nil
+}
+
+func (ks *KeySet) matchesAlg(key crypto.PublicKey) bool {
+	switch key.(type) {
+	case *rsa.PublicKey:
+		return ks.alg == AlgRS256
+	case ed25519.PublicKey:
+		return ks.alg == AlgEdDSA
+	default:
+		return false
+	}
+}
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS publishes the public verification keys. HS256 key sets publish nothing.
+func (ks *KeySet) JWKS() JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+
+	kids := make([]string, 0, len(ks.verifyKeys))
+	for kid := range ks.verifyKeys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	for _, kid := range kids {
+		switch key := ks.verifyKeys[kid].(type) {
+		case *rsa.PublicKey:
+			jwks.Keys = append(jwks.Keys, JWK{
+				Kty: "RSA",
+				Kid: kid,
+				Use: "sig",
+				Alg: AlgRS256,
+				N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			jwks.Keys = append(jwks.Keys, JWK{
+				Kty: "OKP",
+				Kid: kid,
+				Use: "sig",
+				Alg: AlgEdDSA,
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(key),
+			})
+		}
+	}
+	return jwks
+}
+
+func parsePEMKey(raw []byte) (crypto.PrivateKey, crypto.PublicKey, error) {
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, nil, errors.New("no PEM block found")
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		switch k := key.(type) {
+		case *rsa.PrivateKey:
+			return k, &k.PublicKey, nil
+		case ed25519.PrivateKey:
+			return k, k.Public(), nil
+		}
+		return nil, nil, errors.New("unsupported private key type")
+	case "RSA PRIVATE KEY":
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		return key, &key.PublicKey, nil
+	case "PUBLIC KEY":
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, key, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+}
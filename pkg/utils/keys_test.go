@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func writePEM(t *testing.T, path string, blockType string, der []byte) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+}
+
+func TestEdDSAKeyRotation(t *testing.T) {
+	dir := t.TempDir()
+
+	oldPub, oldPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, newPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	oldDER, err := x509.MarshalPKCS8PrivateKey(oldPriv)
+	require.NoError(t, err)
+	newDER, err := x509.MarshalPKCS8PrivateKey(newPriv)
+	require.NoError(t, err)
+	writePEM(t, filepath.Join(dir, "2026-01.pem"), "PRIVATE KEY", oldDER)
+	writePEM(t, filepath.Join(dir, "2026-02.pem"), "PRIVATE KEY", newDER)
+
+	oldKeys, err := LoadKeySet(AlgEdDSA, dir, "2026-01")
+	require.NoError(t, err)
+
+	userID := uuid.New()
+	token, err := GenerateSessionToken(userID, "ada", oldKeys, "access", uuid.New(), uuid.Nil)
+	require.NoError(t, err)
+
+	// After rotation the old key only verifies, and it is published as a public key.
+	pubDER, err := x509.MarshalPKIXPublicKey(oldPub)
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(filepath.Join(dir, "2026-01.pem")))
+	writePEM(t, filepath.Join(dir, "2026-01.pem"), "PUBLIC KEY", pubDER)
+
+	rotated, err := LoadKeySet(AlgEdDSA, dir, "2026-02")
+	require.NoError(t, err)
+
+	claims, err := VerifyTokenWithKeys(token, rotated)
+	require.NoError(t, err)
+	require.Equal(t, userID, claims.UserID)
+
+	jwks := rotated.JWKS()
+	require.Len(t, jwks.Keys, 2)
+	require.Equal(t, "OKP", jwks.Keys[0].Kty)
+	require.Equal(t, "2026-01", jwks.Keys[0].Kid)
+
+	_, err = LoadKeySet(AlgEdDSA, dir, "2026-01")
+	require.Error(t, err, "a public-only key cannot be the active signing key")
+}
+
+func TestRS256RejectsHS256Tokens(t *testing.T) {
+	dir := t.TempDir()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	writePEM(t, filepath.Join(dir, "main.pem"), "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key))
+
+	keys, err := LoadKeySet(AlgRS256, dir, "main")
+	require.NoError(t, err)
+
+	token, err := GenerateSessionToken(uuid.New(), "ada", keys, "access", uuid.Nil, uuid.Nil)
+	require.NoError(t, err)
+	_, err = VerifyTokenWithKeys(token, keys)
+	require.NoError(t, err)
+
+	hsToken, err := GenerateToken(uuid.New(), "ada", "secret", "access")
+	require.NoError(t, err)
+	_, err = VerifyTokenWithKeys(hsToken, keys)
+	require.Error(t, err)
+
+	jwks := keys.JWKS()
+	require.Len(t, jwks.Keys, 1)
+	require.Equal(t, "RSA", jwks.Keys[0].Kty)
+	require.Equal(t, "AQAB", jwks.Keys[0].E)
+}
@@ -50,6 +50,18 @@ func IsRetryableError(err error) bool {
 	return false
 }
 
+// Backoff is the wait before retry number attempt+1: base * 2^attempt plus up to as much
+// again in jitter, so callers retrying in step drift apart.
+func Backoff(base time.Duration, attempt int) time.Duration {
+	// Formula for exponential backoff: baseDelay * 2^attempt
+	exponentialDelay := base * time.Duration(math.Pow(2, float64(attempt)))
+	if exponentialDelay <= 0 {
+		return 0
+	}
+	jitter := time.Duration(rand.Int63n(int64(exponentialDelay)))
+	return exponentialDelay + jitter
+}
+
 // Retry executes fn up to 'attempts' times, with exponential backoff and jitter.
 // Only retries if the error is marked as retryable via RetryableError or is a transient DB error.
 // sleepMs is the base sleep duration in milliseconds.
@@ -67,13 +79,7 @@ func Retry[T any](attempts int, sleepMs int, fn func() (T, error)) (T, error) {
 
 		// Only retry if error is retryable AND we have more attempts left
 		if attempt < attempts-1 && IsRetryableError(err) {
-			// Calculate exponential backoff and use jitter to add randomness
-			// Formula for exponential backoff: baseDelay * 2^attempt
-			exponentialDelay := time.Duration(sleepMs) * time.Millisecond * time.Duration(math.Pow(2, float64(attempt)))
-			jitter := time.Duration(rand.Intn(int(exponentialDelay)))
-			totalDelay := exponentialDelay + jitter
-
-			time.Sleep(totalDelay)
+			time.Sleep(Backoff(time.Duration(sleepMs)*time.Millisecond, attempt))
 		} else if !IsRetryableError(err) {
 			// Non-retryable error, stop immediately
 			return Zero, err
@@ -9,44 +9,57 @@ import (
 )
 
 type MyClaims struct {
-	UserID   uuid.UUID `json:"user_id"`
-	Username string    `json:"username"`
+	UserID    uuid.UUID `json:"user_id"`
+	Username  string    `json:"username"`
+	SessionID uuid.UUID `json:"sid,omitempty"`
+	TokenType string    `json:"token_type,omitempty"`
 	jwt.RegisteredClaims
 }
 
 func GenerateToken(userID uuid.UUID, username string, secret string, tokenType string) (string, error) {
+	return GenerateSessionToken(userID, username, NewHMACKeySet(secret), tokenType, uuid.Nil, uuid.Nil)
+}
+
+// GenerateSessionToken issues a token bound to a login session. sessionID is carried in the
+// "sid" claim and tokenID, when set, becomes the "jti" used to look refresh tokens up server-side.
+func GenerateSessionToken(userID uuid.UUID, username string, keys *KeySet, tokenType string, sessionID uuid.UUID, tokenID uuid.UUID) (string, error) {
 	var exp time.Duration
 	if tokenType == "access" {
-		exp = time.Hour * 24
+		exp = time.Minute * 15
 	} else if tokenType == "refresh" {
 		exp = time.Hour * 24 * 7
+	} else if tokenType == "mfa" {
+		exp = time.Minute * 5
 	} else {
 		exp = time.Hour * 1 // Default 1 hour
 	}
 
 	claims := &MyClaims{
-		UserID:   userID,
-		Username: username,
+		UserID:    userID,
+		Username:  username,
+		SessionID: sessionID,
+		TokenType: tokenType,
 		RegisteredClaims: jwt.RegisteredClaims{
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Subject:   username,
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(exp)),
 		},
 	}
+	if tokenID != uuid.Nil {
+		claims.ID = tokenID.String()
+	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	return keys.Sign(claims)
 }
 
 func VerifyToken(tokenString string, secret string) (*MyClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &MyClaims{}, func(token *jwt.Token) (interface{}, error) {
-		// Ensure the signing method is HMAC
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return []byte(secret), nil
-	})
+	return VerifyTokenWithKeys(tokenString, NewHMACKeySet(secret))
+}
 
+// VerifyTokenWithKeys checks the signature against the key set; the signing method must match
+// the key set's algorithm, so an RS256 set never accepts HS256 tokens and vice versa.
+func VerifyTokenWithKeys(tokenString string, keys *KeySet) (*MyClaims, error) {
+	token, err := keys.Parse(tokenString, &MyClaims{})
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// RFC 6238 appendix B vectors for SHA1, truncated to six digits.
+func TestTOTPCodeRFCVectors(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+	cases := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1111111111: "050471",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+
+	for unix, want := range cases {
+		got, err := TOTPCode(secret, TOTPStep(time.Unix(unix, 0)))
+		require.NoError(t, err)
+		require.Equal(t, want, got, "t=%d", unix)
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	require.NoError(t, err)
+
+	now := time.Unix(1_700_000_000, 0)
+	code, err := TOTPCode(secret, TOTPStep(now))
+	require.NoError(t, err)
+
+	step, ok := ValidateTOTP(secret, code, now, 0)
+	require.True(t, ok)
+	require.Equal(t, TOTPStep(now), step)
+
+	// one step of clock drift is tolerated, two are not
+	_, ok = ValidateTOTP(secret, code, now.Add(TOTPPeriod*time.Second), 0)
+	require.True(t, ok)
+	_, ok = ValidateTOTP(secret, code, now.Add(2*TOTPPeriod*time.Second), 0)
+	require.False(t, ok)
+
+	// replaying an already used step is rejected
+	_, ok = ValidateTOTP(secret, code, now, step)
+	require.False(t, ok)
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri := TOTPURI("Paycore", "ada@example.com", "JBSWY3DPEHPK3PXP")
+
+	require.True(t, strings.HasPrefix(uri, "otpauth://totp/Paycore:ada@example.com?"))
+	require.Contains(t, uri, "secret=JBSWY3DPEHPK3PXP")
+	require.Contains(t, uri, "issuer=Paycore")
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	require.NoError(t, err)
+	require.Len(t, codes, 10)
+
+	seen := map[string]bool{}
+	for _, code := range codes {
+		require.Len(t, code, 11)
+		require.Equal(t, byte('-'), code[5])
+		require.False(t, seen[code])
+		seen[code] = true
+	}
+
+	require.Equal(t, HashRecoveryCode(codes[0]), HashRecoveryCode(" "+strings.ToUpper(strings.Replace(codes[0], "-", "", 1))))
+}
+
+func TestSealSecretRoundTrip(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+
+	sealed, err := SealSecret(key, []byte("JBSWY3DPEHPK3PXP"))
+	require.NoError(t, err)
+
+	opened, err := OpenSecret(key, sealed)
+	require.NoError(t, err)
+	require.Equal(t, "JBSWY3DPEHPK3PXP", string(opened))
+
+	sealed[len(sealed)-1] ^= 0xff
+	_, err = OpenSecret(key, sealed)
+	require.Error(t, err)
+}